@@ -1,10 +1,18 @@
 package app
 
 import (
+	"context"
+	"net/url"
+	"sync/atomic"
+
 	"github.com/go-redis/redis"
 	"github.com/jmoiron/sqlx"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/hibp"
+	"github.com/keratin/authn-server/lib/ldap"
 	"github.com/keratin/authn-server/lib/oauth"
+	"github.com/keratin/authn-server/lib/saml"
 	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -14,27 +22,52 @@ import (
 
 type pinger func() bool
 
+// MonthlyActivesRetention is how many monthly records of the number of active accounts are
+// reported. Unlike daily and weekly records, they are not expired.
+const MonthlyActivesRetention = 5 * 12
+
 type App struct {
 	DB                *sqlx.DB
 	DbCheck           pinger
 	RedisCheck        pinger
-	Config            *Config
+	KeyCheck          pinger
+	config            atomic.Value
 	AccountStore      data.AccountStore
+	AuditLog          data.AuditLog
 	RefreshTokenStore data.RefreshTokenStore
 	KeyStore          data.KeyStore
 	Actives           data.Actives
+	TokenDenylist     data.TokenDenylist
+	AccessTokenStore  data.AccessTokenStore
+	OTPStore          data.OTPStore
+	FailedLogins      data.FailedLogins
+	RateLimiter       data.RateLimiter
+	Idempotency       data.IdempotencyStore
+	EventQueue        data.EventQueue
+	Maintenance       data.Maintenance
 	Reporter          ops.ErrorReporter
 	OauthProviders    map[string]oauth.Provider
+	SAMLProviders     map[string]*saml.Provider
+	LDAP              ldap.Authenticator
+	PwnedPasswords    hibp.Checker
 	Logger            logrus.FieldLogger
+	// Version of the server, as injected at build time
+	Version string
 }
 
 func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
 	errorReporter, err := ops.NewErrorReporter(cfg.ErrorReporterCredentials, cfg.ErrorReporterType, logger)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewErrorReporter")
+	}
 
 	db, err := data.NewDB(cfg.DatabaseURL)
 	if err != nil {
 		return nil, errors.Wrap(err, "data.NewDB")
 	}
+	db.SetMaxOpenConns(cfg.DBMaxOpenConns)
+	db.SetMaxIdleConns(cfg.DBMaxIdleConns)
+	db.SetConnMaxLifetime(cfg.DBConnMaxLifetime)
 
 	var redis *redis.Client
 	if cfg.RedisURL != nil {
@@ -49,29 +82,50 @@ func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
 		return nil, errors.Wrap(err, "NewAccountStore")
 	}
 
-	tokenStore, err := data.NewRefreshTokenStore(db, redis, errorReporter, cfg.RefreshTokenTTL)
+	if cfg.ArchivedAccountRetention > 0 {
+		data.CleanArchivedAccounts(accountStore, cfg.ArchivedAccountRetention, errorReporter)
+	}
+
+	auditLog, err := data.NewAuditLog(db)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewAuditLog")
+	}
+	if cfg.AuditLogRetention > 0 {
+		data.CleanAuditLog(auditLog, cfg.AuditLogRetention, errorReporter)
+	}
+
+	tokenStore, err := data.NewRefreshTokenStore(db, redis, errorReporter, cfg.RefreshTokenTTL, cfg.SessionMaxLifetime)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewRefreshTokenStore")
 	}
 
-	blobStore, err := data.NewBlobStore(cfg.AccessTokenTTL, redis, db, errorReporter)
+	if cfg.TracingEnabled {
+		accountStore = &data.TracedAccountStore{AccountStore: accountStore}
+		tokenStore = &data.TracedRefreshTokenStore{RefreshTokenStore: tokenStore}
+	}
+
+	blobStore, err := data.NewBlobStore(cfg.KeyRotationInterval, redis, db, errorReporter)
 	if err != nil {
 		return nil, errors.Wrap(err, "NewBlobStore")
 	}
 
-	keyStore := data.NewRotatingKeyStore()
+	var keyStore data.KeyStore
 	if cfg.IdentitySigningKey == nil {
+		rotatingKeyStore := data.NewRotatingKeyStore()
 		m := data.NewKeyStoreRotater(
 			data.NewEncryptedBlobStore(blobStore, cfg.DBEncryptionKey),
-			cfg.AccessTokenTTL,
+			cfg.KeyRotationInterval,
+			cfg.SigningAlgorithm,
 			logger,
 		)
-		err := m.Maintain(keyStore, errorReporter)
+		err := m.Maintain(rotatingKeyStore, errorReporter)
 		if err != nil {
 			return nil, errors.Wrap(err, "Maintain")
 		}
+		keyStore = rotatingKeyStore
 	} else {
-		keyStore.Rotate(cfg.IdentitySigningKey)
+		keys := append([]*private.Key{}, cfg.PreviousIdentitySigningKeys...)
+		keyStore = data.NewStaticKeyStore(append(keys, cfg.IdentitySigningKey)...)
 	}
 
 	var actives data.Actives
@@ -81,10 +135,29 @@ func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
 			cfg.StatisticsTimeZone,
 			cfg.DailyActivesRetention,
 			cfg.WeeklyActivesRetention,
-			5*12,
+			MonthlyActivesRetention,
 		)
 	}
 
+	var tokenDenylist data.TokenDenylist
+	var accessTokenStore data.AccessTokenStore
+	var otpStore data.OTPStore
+	var failedLogins data.FailedLogins
+	var rateLimiter data.RateLimiter
+	var idempotency data.IdempotencyStore
+	var eventQueue data.EventQueue
+	maintenance := data.NewLocalMaintenance()
+	if redis != nil {
+		tokenDenylist = dataRedis.NewTokenDenylist(redis)
+		accessTokenStore = dataRedis.NewAccessTokenStore(redis)
+		otpStore = dataRedis.NewOTPStore(redis, data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts)
+		failedLogins = dataRedis.NewFailedLogins(redis, cfg.LoginFailureWindow)
+		rateLimiter = dataRedis.NewRateLimiter(redis)
+		idempotency = dataRedis.NewIdempotencyStore(redis)
+		eventQueue = dataRedis.NewEventQueue(redis)
+		maintenance = dataRedis.NewMaintenance(redis)
+	}
+
 	oauthProviders := map[string]oauth.Provider{}
 	if cfg.GoogleOauthCredentials != nil {
 		oauthProviders["google"] = *oauth.NewGoogleProvider(cfg.GoogleOauthCredentials)
@@ -98,19 +171,99 @@ func NewApp(cfg *Config, logger logrus.FieldLogger) (*App, error) {
 	if cfg.DiscordOauthCredentials != nil {
 		oauthProviders["discord"] = *oauth.NewDiscordProvider(cfg.DiscordOauthCredentials)
 	}
+	if cfg.AppleOauthCredentials != nil {
+		provider, err := oauth.NewAppleProvider(cfg.AppleOauthCredentials)
+		if err != nil {
+			return nil, errors.Wrap(err, "NewAppleProvider")
+		}
+		oauthProviders["apple"] = *provider
+	}
+	for _, p := range cfg.OIDCProviders {
+		oauthProviders[p.Name] = *oauth.NewOIDCProvider(p.Issuer, p.Credentials, p.Scopes)
+	}
+
+	samlProviders := map[string]*saml.Provider{}
+	for _, p := range cfg.SAMLProviders {
+		base := cfg.AuthNURL.String() + "/saml/" + p.Name
+		samlProviders[p.Name] = saml.NewProvider(p.MetadataURL, base+"/metadata", base+"/acs", p.UsernameAttribute)
+	}
+
+	var ldapAuthenticator ldap.Authenticator
+	if cfg.LDAPURL != nil {
+		ldapAuthenticator = ldap.NewClient(cfg.LDAPURL, cfg.LDAPBindTemplate, cfg.LDAPBaseDN, cfg.LDAPStartTLS)
+	}
+
+	var pwnedPasswords hibp.Checker
+	if cfg.PasswordBreachCheck {
+		pwnedPasswords = hibp.NewClient(hibp.DefaultURL)
+	}
 
-	return &App{
+	app := &App{
 		// Provide access to root DB - useful when extending AccountStore functionality
 		DB:                db,
 		DbCheck:           func() bool { return db.Ping() == nil },
 		RedisCheck:        func() bool { return redis != nil && redis.Ping().Err() == nil },
-		Config:            cfg,
+		KeyCheck:          func() bool { return keyStore.Key() != nil },
 		AccountStore:      accountStore,
+		AuditLog:          auditLog,
 		RefreshTokenStore: tokenStore,
 		KeyStore:          keyStore,
 		Actives:           actives,
+		TokenDenylist:     tokenDenylist,
+		AccessTokenStore:  accessTokenStore,
+		OTPStore:          otpStore,
+		FailedLogins:      failedLogins,
+		RateLimiter:       rateLimiter,
+		Idempotency:       idempotency,
+		EventQueue:        eventQueue,
+		Maintenance:       maintenance,
 		Reporter:          errorReporter,
 		OauthProviders:    oauthProviders,
+		SAMLProviders:     samlProviders,
+		LDAP:              ldapAuthenticator,
+		PwnedPasswords:    pwnedPasswords,
 		Logger:            logger,
-	}, nil
+	}
+	app.SetConfig(cfg)
+	return app, nil
+}
+
+// Config returns the current settings. Reload may replace them while the server is running, so a
+// request should read them once and use that Config throughout.
+func (app *App) Config() *Config {
+	cfg, _ := app.config.Load().(*Config)
+	return cfg
+}
+
+// SetConfig replaces the settings returned by Config.
+func (app *App) SetConfig(cfg *Config) {
+	app.config.Store(cfg)
+}
+
+type authnURLKey struct{}
+
+// WithAuthNURL marks a request that was addressed to one of the AUTHN_URLs other than the first.
+func WithAuthNURL(ctx context.Context, u *url.URL) context.Context {
+	return context.WithValue(ctx, authnURLKey{}, u)
+}
+
+// ConfigFor returns the current settings as they apply to a request, with the AUTHN_URL that the
+// request was addressed to. Like Config, a request should read them once.
+func (app *App) ConfigFor(ctx context.Context) *Config {
+	cfg := app.Config()
+	if u, ok := ctx.Value(authnURLKey{}).(*url.URL); ok {
+		return cfg.ForAuthNURL(u)
+	}
+	return cfg
+}
+
+// AccountStoreFor returns the AccountStore for a request, so that traced calls join its trace.
+func (app *App) AccountStoreFor(ctx context.Context) data.AccountStore {
+	return data.AccountStoreWithContext(app.AccountStore, ctx)
+}
+
+// RefreshTokenStoreFor returns the RefreshTokenStore for a request, so that traced calls join its
+// trace.
+func (app *App) RefreshTokenStoreFor(ctx context.Context) data.RefreshTokenStore {
+	return data.RefreshTokenStoreWithContext(app.RefreshTokenStore, ctx)
 }
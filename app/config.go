@@ -1,27 +1,40 @@
 package app
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
-	"crypto/sha256"
+	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/keratin/authn-server/app/data/kms"
 	"github.com/keratin/authn-server/app/data/private"
+	dataRedis "github.com/keratin/authn-server/app/data/redis"
 
 	// a .env file is extremely useful during development
 	_ "github.com/joho/godotenv/autoload"
 	"github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
-	"golang.org/x/crypto/pbkdf2"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
 )
 
 // Config is the full list of configuration settings for AuthN. It is typically populated by reading
@@ -30,18 +43,61 @@ type Config struct {
 	AppPasswordlessTokenURL     *url.URL
 	PasswordlessTokenTTL        time.Duration
 	PasswordlessTokenSigningKey []byte
+	HandoffTokenSigningKey      []byte
 	AppPasswordResetURL         *url.URL
 	AppPasswordChangedURL       *url.URL
+	AppAccountDeletedURL        *url.URL
+	AppEmailVerificationURL     *url.URL
+	AppEmailChangeURL           *url.URL
+	AppOTPDeliveryURL           *url.URL
+	OTPDeliveryTTL              time.Duration
+	EmailVerificationTokenTTL   time.Duration
+	EmailVerificationSigningKey []byte
+	InvitationTokenTTL          time.Duration
+	InvitationSigningKey        []byte
+	AppClaimsURL                *url.URL
+	AppBackchannelLogoutURLs    []*url.URL
+	AppEventsURLs               []*url.URL
+	AppEventsSigningKey         []byte
+	IdentityClaims              map[string]interface{}
+	IdentityMetadataClaims      []string
 	ApplicationDomains          []route.Domain
+	DomainSettings              []DomainSettings
+	Audience                    string
 	BcryptCost                  int
 	UsernameIsEmail             bool
 	UsernameMinLength           int
+	UsernameMaxLength           int
 	UsernameDomains             []string
 	PasswordMinComplexity       int
+	PasswordMaxLength           int
+	PasswordBreachCheck         bool
 	RefreshTokenTTL             time.Duration
+	EphemeralRefreshTokenTTL    time.Duration
+	RememberMeDefault           bool
+	SessionMaxLifetime          time.Duration
+	ImpersonationTTL            time.Duration
+	SessionBinding              string
+	MaxSessionsPerAccount       int
+	LoginLockoutThreshold       int
+	LoginIPThreshold            int
+	LoginFailureWindow          time.Duration
+	LoginLockoutDelay           bool
+	RateLimitIP                 int
+	RateLimitAccount            int
+	RateLimitWindow             time.Duration
+	IdempotencyKeyTTL           time.Duration
 	RedisURL                    *url.URL
 	DatabaseURL                 *url.URL
+	DBMaxOpenConns              int
+	DBMaxIdleConns              int
+	DBConnMaxLifetime           time.Duration
+	ArchivedAccountRetention    time.Duration
+	AuditLogRetention           time.Duration
+	MaintenanceMode             bool
 	SessionCookieName           string
+	CookieDomain                string
+	CookiePath                  string
 	OAuthCookieName             string
 	SessionSigningKey           []byte
 	ResetSigningKey             []byte
@@ -49,26 +105,66 @@ type Config struct {
 	OAuthSigningKey             []byte
 	ResetTokenTTL               time.Duration
 	IdentitySigningKey          *private.Key
+	IdentityEncryptionKey       *jose.JSONWebKey
+	SigningAlgorithm            jose.SignatureAlgorithm
+	WatchSecretFiles            bool
+	PreviousIdentitySigningKeys []*private.Key
 	AuthNURL                    *url.URL
+	AuthNURLs                   []*url.URL
 	ForceSSL                    bool
 	SameSite                    http.SameSite
+	CSRFProtection              string
+	CSRFSigningKey              []byte
+	IdempotencySigningKey       []byte
 	MountedPath                 string
 	AccessTokenTTL              time.Duration
+	JWTLeeway                   time.Duration
+	OpaqueAccessTokens          bool
+	KeyRotationInterval         time.Duration
 	AuthUsername                string
 	AuthPassword                string
 	EnableSignup                bool
+	EnablePasswordLogin         bool
+	EnablePasswordReset         bool
 	StatisticsTimeZone          *time.Location
 	DailyActivesRetention       int
 	WeeklyActivesRetention      int
 	ErrorReporterCredentials    string
 	ErrorReporterType           ops.ErrorReporterType
+	TracingEnabled              bool
+	LogLevel                    logrus.Level
+	LogFormat                   string
+	ListenAddress               string
+	ListenSocket                string
 	ServerPort                  int
 	PublicPort                  int
+	HTTPReadTimeout             time.Duration
+	HTTPWriteTimeout            time.Duration
+	HTTPIdleTimeout             time.Duration
+	HTTPMaxHeaderBytes          int
+	GRPCPort                    int
+	TLSCertificate              *tls.Certificate
+	TLSAutocertDir              string
+	TLSAutocertEmail            string
+	GRPCCertificate             *tls.Certificate
+	GRPCClientCAs               *x509.CertPool
 	Proxied                     bool
+	TrustedProxies              []*net.IPNet
+	CORSAllowedHeaders          []string
+	CORSAllowedMethods          []string
+	CORSAllowCredentials        bool
+	FrameAncestors              []string
 	GoogleOauthCredentials      *oauth.Credentials
 	GitHubOauthCredentials      *oauth.Credentials
 	FacebookOauthCredentials    *oauth.Credentials
 	DiscordOauthCredentials     *oauth.Credentials
+	AppleOauthCredentials       *oauth.Credentials
+	OIDCProviders               []OIDCProvider
+	SAMLProviders               []SAMLProvider
+	LDAPURL                     *url.URL
+	LDAPBindTemplate            string
+	LDAPBaseDN                  string
+	LDAPStartTLS                bool
 }
 
 // OAuthEnabled returns true if any provider is configured.
@@ -76,7 +172,112 @@ func (c *Config) OAuthEnabled() bool {
 	return c.GoogleOauthCredentials != nil ||
 		c.GitHubOauthCredentials != nil ||
 		c.FacebookOauthCredentials != nil ||
-		c.DiscordOauthCredentials != nil
+		c.DiscordOauthCredentials != nil ||
+		c.AppleOauthCredentials != nil ||
+		len(c.OIDCProviders) > 0
+}
+
+// SecureCookies reports whether cookies should be restricted to secure connections. Browsers
+// reject SameSite=None cookies unless they are also Secure.
+func (c *Config) SecureCookies() bool {
+	return c.ForceSSL || c.SameSiteComputed() == http.SameSiteNoneMode
+}
+
+// ForAuthNURL returns a copy of the config that serves from one of the AuthNURLs, with its
+// issuer, mounted path, and SSL enforcement.
+func (c *Config) ForAuthNURL(u *url.URL) *Config {
+	copy := *c
+	copy.setAuthNURL(u)
+	return &copy
+}
+
+func (c *Config) setAuthNURL(u *url.URL) {
+	c.AuthNURL = u
+	if u.Path == "" {
+		c.MountedPath = "/"
+	} else {
+		c.MountedPath = u.Path
+	}
+	c.ForceSSL = u.Scheme == "https"
+}
+
+// OIDCProvider is an upstream OpenID Connect issuer that accounts may log in with.
+type OIDCProvider struct {
+	Name        string
+	Issuer      string
+	Credentials *oauth.Credentials
+	Scopes      []string
+}
+
+// SAMLProvider is an upstream SAML identity provider that accounts may log in with.
+type SAMLProvider struct {
+	Name              string
+	MetadataURL       string
+	UsernameAttribute string
+}
+
+// DomainSettings are overrides for requests from a specific application domain.
+type DomainSettings struct {
+	Domain         route.Domain
+	CookieDomain   string
+	CookiePath     string
+	Audience       string
+	AccessTokenTTL time.Duration
+}
+
+// SettingsFor returns the settings that apply to requests from the given domain, with any
+// overrides from DomainSettings. An exact match is preferred over a wildcard match.
+func (c *Config) SettingsFor(domain *route.Domain) DomainSettings {
+	settings := DomainSettings{
+		CookieDomain:   c.CookieDomain,
+		CookiePath:     c.CookiePath,
+		AccessTokenTTL: c.AccessTokenTTL,
+	}
+	if settings.CookiePath == "" {
+		settings.CookiePath = c.MountedPath
+	}
+	if domain == nil {
+		return settings
+	}
+	settings.Domain = *domain
+	settings.Audience = domain.String()
+	if c.Audience != "" {
+		settings.Audience = c.Audience
+	}
+
+	var override *DomainSettings
+	u := domain.URL()
+	for i, s := range c.DomainSettings {
+		if s.Domain == *domain {
+			override = &c.DomainSettings[i]
+			break
+		}
+		if override == nil && s.Domain.IsWildcard() && s.Domain.Matches(&u) {
+			override = &c.DomainSettings[i]
+		}
+	}
+	if override == nil {
+		return settings
+	}
+
+	if override.CookieDomain != "" {
+		settings.CookieDomain = override.CookieDomain
+	}
+	if override.CookiePath != "" {
+		settings.CookiePath = override.CookiePath
+	}
+	if override.Audience != "" {
+		settings.Audience = override.Audience
+	}
+	if override.AccessTokenTTL != 0 {
+		settings.AccessTokenTTL = override.AccessTokenTTL
+	}
+	return settings
+}
+
+// CSRFCookieName names the cookie that carries the CSRF token alongside the session cookie.
+func (c *Config) CSRFCookieName() string {
+	return c.SessionCookieName + "-csrf"
 }
 
 // SameSiteComputed returns either the specified http.SameSite, or a computed one from OAuth config
@@ -90,10 +291,44 @@ func (c *Config) SameSiteComputed() http.SameSite {
 	return http.SameSiteLaxMode
 }
 
+// NonceSameSite returns the http.SameSite of the OAuth nonce cookie. Apple and SAML identity
+// providers return with a cross-site POST, which only carries SameSite=None cookies.
+func (c *Config) NonceSameSite() http.SameSite {
+	if c.AppleOauthCredentials != nil || len(c.SAMLProviders) > 0 {
+		return http.SameSiteNoneMode
+	}
+	return c.SameSiteComputed()
+}
+
+// providerName limits OIDC and SAML provider names to what is safe in a route.
+var providerName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// checkProviderName ensures that an OIDC or SAML provider name is safe in a route and does not
+// collide with another provider, since accounts are linked to identities by provider name.
+func checkProviderName(c *Config, name string) error {
+	if !providerName.MatchString(name) {
+		return fmt.Errorf("%s: name must be lowercase letters, numbers, - or _", name)
+	}
+	switch name {
+	case "google", "github", "facebook", "discord", "apple":
+		return fmt.Errorf("%s: name is reserved", name)
+	}
+	for _, p := range c.OIDCProviders {
+		if p.Name == name {
+			return fmt.Errorf("%s: name is already an OIDC provider", name)
+		}
+	}
+	return nil
+}
+
+// standardClaims are set by AuthN on identity tokens, and may not be configured.
+var standardClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "auth_time", "sid", "email_verified", "impersonated_by"}
+
 var configurers = []configurer{
 	// The APP_DOMAINS are a list of domains that may refer traffic and be valid JWT audiences. If
 	// the domain includes a port, it must match referred traffic. If the domain does not include a
 	// port, it will match any referred traffic port. Ports 80 and 443 are matched against schemes.
+	// A domain beginning with "*." will match any single subdomain label.
 	func(c *Config) error {
 		val, err := requireEnv("APP_DOMAINS")
 		if err == nil {
@@ -105,6 +340,74 @@ var configurers = []configurer{
 		return err
 	},
 
+	// CORS_ALLOWED_HEADERS is a comma separated list of request headers that scripts on the
+	// APP_DOMAINS may send in cross-origin requests, besides the X-Request-ID. The default allows
+	// JSON requests.
+	func(c *Config) error {
+		c.CORSAllowedHeaders = []string{"Content-Type"}
+		if val, ok := lookupEnv("CORS_ALLOWED_HEADERS"); ok {
+			c.CORSAllowedHeaders = []string{}
+			for _, header := range strings.Split(val, ",") {
+				if header = strings.TrimSpace(header); header != "" {
+					c.CORSAllowedHeaders = append(c.CORSAllowedHeaders, http.CanonicalHeaderKey(header))
+				}
+			}
+		}
+		return nil
+	},
+
+	// CORS_ALLOWED_METHODS is a comma separated list of methods that scripts on the APP_DOMAINS may
+	// use in cross-origin requests.
+	func(c *Config) error {
+		c.CORSAllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+		val, ok := lookupEnv("CORS_ALLOWED_METHODS")
+		if !ok {
+			return nil
+		}
+		c.CORSAllowedMethods = []string{}
+		for _, method := range strings.Split(val, ",") {
+			method = strings.ToUpper(strings.TrimSpace(method))
+			switch method {
+			case "":
+				continue
+			case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE":
+				c.CORSAllowedMethods = append(c.CORSAllowedMethods, method)
+			default:
+				return ErrInvalidEnvVar{"CORS_ALLOWED_METHODS", fmt.Errorf("unknown method: %s", method)}
+			}
+		}
+		return nil
+	},
+
+	// CORS_ALLOW_CREDENTIALS is a flag that lets scripts on the APP_DOMAINS send the session cookie
+	// in cross-origin requests. It is necessary for a single-page app to refresh a session without
+	// a proxy.
+	func(c *Config) error {
+		val, err := lookupBool("CORS_ALLOW_CREDENTIALS", true)
+		if err == nil {
+			c.CORSAllowCredentials = val
+		}
+		return err
+	},
+
+	// FRAME_ANCESTORS is a comma separated list of the Content-Security-Policy sources, like
+	// https://app.example.com or 'self', that may embed AuthN's responses in a frame. The default
+	// allows none.
+	func(c *Config) error {
+		if val, ok := lookupEnv("FRAME_ANCESTORS"); ok {
+			for _, source := range strings.Split(val, ",") {
+				source = strings.TrimSpace(source)
+				if strings.ContainsAny(source, "; \t") {
+					return ErrInvalidEnvVar{"FRAME_ANCESTORS", errors.Errorf("invalid source: %s", source)}
+				}
+				if source != "" {
+					c.FrameAncestors = append(c.FrameAncestors, source)
+				}
+			}
+		}
+		return nil
+	},
+
 	// The AUTHN_URL is used as an issuer for ID tokens, and must be a URL that
 	// the application can resolve in order to fetch our public key for JWT
 	// verification.
@@ -112,21 +415,38 @@ var configurers = []configurer{
 	// If the AUTHN_URL includes a path, all API routes will be relative to it.
 	//
 	// example: https://app.domain.com/authn
+	//
+	// The AUTHN_URL may also be a comma separated list, when the server is reached by different
+	// names (e.g. with split-horizon DNS). Each request is served with the issuer, path, and SSL
+	// enforcement of the URL that matches its Host. The first URL is the default.
+	//
+	// example: https://authn.domain.com,http://authn.internal
 	func(c *Config) error {
-		val, err := lookupURL("AUTHN_URL")
-		if err == nil {
-			if val == nil {
-				return ErrMissingEnvVar("AUTHN_URL")
+		val, ok := lookupEnv("AUTHN_URL")
+		if !ok {
+			return ErrMissingEnvVar("AUTHN_URL")
+		}
+		hosts := map[string]bool{}
+		for _, str := range strings.Split(val, ",") {
+			str = strings.TrimSpace(str)
+			if str == "" {
+				continue
 			}
-			c.AuthNURL = val
-			if val.Path == "" {
-				c.MountedPath = "/"
-			} else {
-				c.MountedPath = val.Path
+			u, err := url.Parse(str)
+			if err != nil {
+				return ErrInvalidEnvVar{"AUTHN_URL", err}
+			}
+			if hosts[strings.ToLower(u.Host)] {
+				return ErrInvalidEnvVar{"AUTHN_URL", fmt.Errorf("host %s is listed more than once", u.Host)}
 			}
-			c.ForceSSL = val.Scheme == "https"
+			hosts[strings.ToLower(u.Host)] = true
+			c.AuthNURLs = append(c.AuthNURLs, u)
 		}
-		return err
+		if len(c.AuthNURLs) == 0 {
+			return ErrMissingEnvVar("AUTHN_URL")
+		}
+		c.setAuthNURL(c.AuthNURLs[0])
+		return nil
 	},
 
 	// The SECRET_KEY_BASE is a random seed that AuthN can use to derive keys for
@@ -139,16 +459,44 @@ var configurers = []configurer{
 	// But it does help in case the key base has less entropy than might be ideal,
 	// and it does protect from escalating an attack on one derived key into an
 	// attack on all of the derived keys.
+	//
+	// KEY_DERIVATION chooses the derivation. See parseKeyDerivation.
 	func(c *Config) error {
+		derive := derivePBKDF2(defaultPBKDF2Iterations)
+		if str, ok := lookupEnv("KEY_DERIVATION"); ok {
+			var err error
+			derive, err = parseKeyDerivation(str)
+			if err != nil {
+				return ErrInvalidEnvVar{"KEY_DERIVATION", err}
+			}
+		}
+
 		val, err := requireEnv("SECRET_KEY_BASE")
-		if err == nil {
-			c.SessionSigningKey = derive([]byte(val), "session-key-salt")
-			c.ResetSigningKey = derive([]byte(val), "password-reset-token-key-salt")
-			c.PasswordlessTokenSigningKey = derive([]byte(val), "passwordless-token-key-salt")
-			c.DBEncryptionKey = derive([]byte(val), "db-encryption-key-salt")[:32]
-			c.OAuthSigningKey = derive([]byte(val), "oauth-key-salt")
+		if err != nil {
+			return err
 		}
-		return err
+		keys := []struct {
+			key  *[]byte
+			salt string
+		}{
+			{&c.SessionSigningKey, "session-key-salt"},
+			{&c.ResetSigningKey, "password-reset-token-key-salt"},
+			{&c.PasswordlessTokenSigningKey, "passwordless-token-key-salt"},
+			{&c.HandoffTokenSigningKey, "handoff-token-key-salt"},
+			{&c.EmailVerificationSigningKey, "email-verification-token-key-salt"},
+			{&c.InvitationSigningKey, "invitation-token-key-salt"},
+			{&c.OAuthSigningKey, "oauth-key-salt"},
+			{&c.CSRFSigningKey, "csrf-token-key-salt"},
+			{&c.IdempotencySigningKey, "idempotency-key-salt"},
+		}
+		for _, k := range keys {
+			*k.key, err = derive([]byte(val), k.salt)
+			if err != nil {
+				return ErrInvalidEnvVar{"KEY_DERIVATION", err}
+			}
+		}
+		c.DBEncryptionKey = legacyDBEncryptionKey([]byte(val))
+		return nil
 	},
 
 	// BCRYPT_COST describes how many times a password should be hashed. Costs are
@@ -189,6 +537,30 @@ var configurers = []configurer{
 		return err
 	},
 
+	// PASSWORD_MAX_LENGTH is the longest password that may be set, in bytes after normalization.
+	// It may not exceed 72, since bcrypt ignores anything longer.
+	func(c *Config) error {
+		maxLength, err := lookupInt("PASSWORD_MAX_LENGTH", 72)
+		if err != nil {
+			return err
+		}
+		if maxLength < 1 || maxLength > 72 {
+			return ErrInvalidEnvVar{"PASSWORD_MAX_LENGTH", fmt.Errorf("must be between 1 and 72")}
+		}
+		c.PasswordMaxLength = maxLength
+		return nil
+	},
+
+	// PASSWORD_BREACH_CHECK is a truthy string ("t", "true", "yes") that rejects new passwords found
+	// by the Have I Been Pwned range API. Passwords are allowed when the API can not be reached.
+	func(c *Config) error {
+		check, err := lookupBool("PASSWORD_BREACH_CHECK", false)
+		if err == nil {
+			c.PasswordBreachCheck = check
+		}
+		return err
+	},
+
 	// A DATABASE_URL is a string that can specify the database engine, connection
 	// details, credentials, and other details.
 	//
@@ -204,16 +576,99 @@ var configurers = []configurer{
 		return err
 	},
 
+	// DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, and DB_CONN_MAX_LIFETIME (in seconds) tune the database
+	// connection pool. The defaults match database/sql: unlimited open connections, two idle
+	// connections, and no maximum lifetime.
+	func(c *Config) error {
+		maxOpen, err := lookupInt("DB_MAX_OPEN_CONNS", 0)
+		if err != nil {
+			return err
+		}
+		if maxOpen < 0 {
+			return ErrInvalidEnvVar{"DB_MAX_OPEN_CONNS", fmt.Errorf("must not be negative")}
+		}
+		maxIdle, err := lookupInt("DB_MAX_IDLE_CONNS", 2)
+		if err != nil {
+			return err
+		}
+		if maxIdle < 0 {
+			return ErrInvalidEnvVar{"DB_MAX_IDLE_CONNS", fmt.Errorf("must not be negative")}
+		}
+		lifetime, err := lookupInt("DB_CONN_MAX_LIFETIME", 0)
+		if err != nil {
+			return err
+		}
+		if lifetime < 0 {
+			return ErrInvalidEnvVar{"DB_CONN_MAX_LIFETIME", fmt.Errorf("must not be negative")}
+		}
+		c.DBMaxOpenConns = maxOpen
+		c.DBMaxIdleConns = maxIdle
+		c.DBConnMaxLifetime = time.Duration(lifetime) * time.Second
+		return nil
+	},
+
+	// ARCHIVED_ACCOUNT_RETENTION is how many days to keep an archived account's tombstone before it
+	// is deleted. The default is to keep tombstones, which prevents reuse of account IDs.
+	func(c *Config) error {
+		days, err := lookupInt("ARCHIVED_ACCOUNT_RETENTION", 0)
+		if err != nil {
+			return err
+		}
+		if days < 0 {
+			return ErrInvalidEnvVar{"ARCHIVED_ACCOUNT_RETENTION", fmt.Errorf("must not be negative")}
+		}
+		c.ArchivedAccountRetention = time.Duration(days) * 24 * time.Hour
+		return nil
+	},
+
+	// AUDIT_LOG_RETENTION is how many days to keep entries in the audit log before they are deleted.
+	// The default is to keep every entry.
+	func(c *Config) error {
+		days, err := lookupInt("AUDIT_LOG_RETENTION", 0)
+		if err != nil {
+			return err
+		}
+		if days < 0 {
+			return ErrInvalidEnvVar{"AUDIT_LOG_RETENTION", fmt.Errorf("must not be negative")}
+		}
+		c.AuditLogRetention = time.Duration(days) * 24 * time.Hour
+		return nil
+	},
+
+	// MAINTENANCE_MODE is a flag that starts the server in maintenance, refusing mutating requests
+	// until it is restarted without the flag. Maintenance may also be switched on and off for every
+	// server with the private /maintenance endpoint.
+	func(c *Config) error {
+		val, err := lookupBool("MAINTENANCE_MODE", false)
+		if err == nil {
+			c.MaintenanceMode = val
+		}
+		return err
+	},
+
 	// REDIS_URL is a string format that can specify any option for connecting to
-	// a Redis server.
+	// a Redis server, or to a master discovered through Redis Sentinel. Use rediss:// for TLS.
 	//
 	// Example: redis://127.0.0.1:6379/11
+	// Example: rediss://:password@redis.example.com:6380/0?ca_cert=/etc/ssl/redis-ca.pem
+	// Example: redis+sentinel://sentinel1:26379,sentinel2:26379/mymaster/11
 	func(c *Config) error {
 		val, err := lookupURL("REDIS_URL")
-		if err == nil {
-			c.RedisURL = val
+		if err != nil {
+			return err
 		}
-		return err
+		if val != nil {
+			if val.Scheme == dataRedis.SentinelScheme {
+				_, err = dataRedis.ParseSentinelURL(val)
+			} else {
+				_, err = dataRedis.ParseURL(val)
+			}
+			if err != nil {
+				return ErrInvalidEnvVar{"REDIS_URL", err}
+			}
+		}
+		c.RedisURL = val
+		return nil
 	},
 
 	// USERNAME_IS_EMAIL is a truthy string ("t", "true", "yes") that enables the
@@ -237,13 +692,54 @@ var configurers = []configurer{
 		return err
 	},
 
+	// ENABLE_PASSWORD_LOGIN may be set to a falsy value ("f", "false", "no") to disable the
+	// endpoints that log in with or change a password, as when accounts are created for OAuth or
+	// passwordless logins only. This also disables password resets.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_PASSWORD_LOGIN", true)
+		if err == nil {
+			c.EnablePasswordLogin = enabled
+		}
+		return err
+	},
+
+	// ENABLE_PASSWORD_RESET may be set to a falsy value ("f", "false", "no") to disable the
+	// password reset endpoint.
+	func(c *Config) error {
+		enabled, err := lookupBool("ENABLE_PASSWORD_RESET", true)
+		if err == nil {
+			c.EnablePasswordReset = enabled
+		}
+		return err
+	},
+
+	// USERNAME_MIN_LENGTH and USERNAME_MAX_LENGTH limit the length of usernames that are not email
+	// addresses. The maximum also applies to email addresses, and defaults to the size of the
+	// username column.
+	func(c *Config) error {
+		min, err := lookupInt("USERNAME_MIN_LENGTH", c.UsernameMinLength)
+		if err != nil {
+			return err
+		}
+		max, err := lookupInt("USERNAME_MAX_LENGTH", c.UsernameMaxLength)
+		if err != nil {
+			return err
+		}
+		if max < min {
+			return ErrInvalidEnvVar{"USERNAME_MAX_LENGTH", errors.New("must not be less than USERNAME_MIN_LENGTH")}
+		}
+		c.UsernameMinLength = min
+		c.UsernameMaxLength = max
+		return nil
+	},
+
 	// EMAIL_USERNAME_DOMAINS is a comma-delimited list of domains that an email
 	// username must contain for signup. If missing, then any domain is a valid
 	// signup.
 	//
 	// This setting only has effect if USERNAME_IS_EMAIL has been set.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("EMAIL_USERNAME_DOMAINS"); ok {
+		if val, ok := lookupEnv("EMAIL_USERNAME_DOMAINS"); ok {
 			c.UsernameDomains = strings.Split(val, ",")
 		}
 		return nil
@@ -260,6 +756,85 @@ var configurers = []configurer{
 		return err
 	},
 
+	// EPHEMERAL_REFRESH_TOKEN_TTL determines how long a refresh token will live after its last touch
+	// when the user did not ask to be remembered. It may not exceed REFRESH_TOKEN_TTL, which is also
+	// the default.
+	func(c *Config) error {
+		ttl, err := lookupInt("EPHEMERAL_REFRESH_TOKEN_TTL", 0)
+		if err != nil {
+			return err
+		}
+		c.EphemeralRefreshTokenTTL = time.Duration(ttl) * time.Second
+		if c.EphemeralRefreshTokenTTL > c.RefreshTokenTTL {
+			return ErrInvalidEnvVar{"EPHEMERAL_REFRESH_TOKEN_TTL", fmt.Errorf("must not exceed REFRESH_TOKEN_TTL")}
+		}
+		return nil
+	},
+
+	// REMEMBER_ME_DEFAULT decides whether a session is remembered when the login does not specify
+	// remember_me. Remembered sessions are kept in a persistent cookie, and others in a cookie that
+	// the browser discards when it closes.
+	func(c *Config) error {
+		val, err := lookupBool("REMEMBER_ME_DEFAULT", false)
+		if err == nil {
+			c.RememberMeDefault = val
+		}
+		return err
+	},
+
+	// SESSION_MAX_LIFETIME limits how long (in seconds) a session may stay logged in, regardless of
+	// activity. REFRESH_TOKEN_TTL is extended whenever a session is refreshed, so without a limit an
+	// active session may last forever. The default is no limit.
+	func(c *Config) error {
+		lifetime, err := lookupInt("SESSION_MAX_LIFETIME", 0)
+		if err == nil {
+			c.SessionMaxLifetime = time.Duration(lifetime) * time.Second
+		}
+		return err
+	},
+
+	// IMPERSONATION_TTL limits how long (in seconds) a session issued for support staff to
+	// impersonate an account may stay logged in, regardless of activity.
+	func(c *Config) error {
+		ttl, err := lookupInt("IMPERSONATION_TTL", 900)
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return ErrInvalidEnvVar{"IMPERSONATION_TTL", fmt.Errorf("must be positive")}
+		}
+		c.ImpersonationTTL = time.Duration(ttl) * time.Second
+		return nil
+	},
+
+	// SESSION_BINDING rejects session refreshes from a different client than the one that logged
+	// in, which makes a stolen session cookie harder to use. It may be "lax" (the same user agent)
+	// or "strict" (the same user agent and IP network). The default is no binding.
+	func(c *Config) error {
+		val, ok := lookupEnv("SESSION_BINDING")
+		if !ok {
+			return nil
+		}
+		switch strings.ToLower(val) {
+		case "lax", "strict":
+			c.SessionBinding = strings.ToLower(val)
+		case "none":
+		default:
+			return ErrInvalidEnvVar{"SESSION_BINDING", fmt.Errorf("expected lax, strict, or none")}
+		}
+		return nil
+	},
+
+	// MAX_SESSIONS_PER_ACCOUNT limits how many devices may be logged in to an account at once. When
+	// an account logs in beyond the limit, its oldest session is revoked. The default is no limit.
+	func(c *Config) error {
+		max, err := lookupInt("MAX_SESSIONS_PER_ACCOUNT", 0)
+		if err == nil {
+			c.MaxSessionsPerAccount = max
+		}
+		return err
+	},
+
 	// PASSWORD_RESET_TOKEN_TTL determines how long a password reset token (as JWT)
 	// will be valid from when it is generated. These tokens should not live much
 	// longer than it takes for an attentive user to act in a reasonably expedient
@@ -286,6 +861,28 @@ var configurers = []configurer{
 		return err
 	},
 
+	// EMAIL_VERIFICATION_TOKEN_TTL determines how long an email verification token (as JWT)
+	// will be valid from when it is generated. These tokens only prove control of an email
+	// address, so they may live long enough for a user to find the message later.
+	func(c *Config) error {
+		ttl, err := lookupInt("EMAIL_VERIFICATION_TOKEN_TTL", 86400)
+		if err == nil {
+			c.EmailVerificationTokenTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// INVITATION_TOKEN_TTL determines how long an invitation token (as JWT) may be used to sign
+	// up from when it is generated. Invitations wait for a user to read their message, so the
+	// default is a week.
+	func(c *Config) error {
+		ttl, err := lookupInt("INVITATION_TOKEN_TTL", 86400*7)
+		if err == nil {
+			c.InvitationTokenTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
 	// ACCESS_TOKEN_TTL determines how long an access token (as JWT) will remain
 	// valid. This is a hard limit, to limit the potential damage of an exposed
 	// access token.
@@ -305,35 +902,144 @@ var configurers = []configurer{
 		return err
 	},
 
-	// HTTP_AUTH_USERNAME and HTTP_AUTH_PASSWORD specify the basic auth credentials
-	// that must be provided to access private endpoints.
-	//
-	// This security pattern requires communication with AuthN to use SSL.
+	// JWT_LEEWAY is how many seconds of clock skew are tolerated when checking the nbf, exp, and
+	// iat claims of session, reset, passwordless, and identity tokens.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("HTTP_AUTH_USERNAME"); ok {
-			c.AuthUsername = val
-		} else {
-			i, err := rand.Int(rand.Reader, big.NewInt(99999999))
-			if err != nil {
-				return err
-			}
-			c.AuthUsername = i.String()
+		leeway, err := lookupInt("JWT_LEEWAY", 60)
+		if err != nil {
+			return err
 		}
-		if val, ok := os.LookupEnv("HTTP_AUTH_PASSWORD"); ok {
-			c.AuthPassword = val
-		} else {
-			i, err := rand.Int(rand.Reader, big.NewInt(99999999))
-			if err != nil {
-				return err
-			}
-			c.AuthPassword = i.String()
+		if leeway < 0 {
+			return ErrInvalidEnvVar{"JWT_LEEWAY", fmt.Errorf("may not be negative")}
 		}
+		c.JWTLeeway = time.Duration(leeway) * time.Second
 		return nil
 	},
 
-	// APP_PASSWORD_CHANGED_URL is an endpoint that will be notified when an account
-	// has changed its password. This notification may be used to deliver an email
-	// confirmation.
+	// ACCESS_TOKEN_FORMAT may be "opaque" to issue random reference tokens instead of identity
+	// tokens. Opaque tokens carry no claims, and must be checked by introspection, but may be
+	// revoked instantly. They are stored in Redis, so REDIS_URL is required. The default is "jwt".
+	func(c *Config) error {
+		val, ok := lookupEnv("ACCESS_TOKEN_FORMAT")
+		if !ok {
+			return nil
+		}
+		switch strings.ToLower(val) {
+		case "opaque":
+			if c.RedisURL == nil {
+				return ErrInvalidEnvVar{"ACCESS_TOKEN_FORMAT", fmt.Errorf("opaque tokens require REDIS_URL")}
+			}
+			c.OpaqueAccessTokens = true
+		case "jwt":
+		default:
+			return ErrInvalidEnvVar{"ACCESS_TOKEN_FORMAT", fmt.Errorf("expected jwt or opaque")}
+		}
+		return nil
+	},
+
+	// KEY_ROTATION_INTERVAL is how often (in seconds) AuthN generates a new identity signing key,
+	// when IDENTITY_SIGNING_KEY is not configured. Each key signs tokens for one interval and is
+	// published for one more, so the interval may not be shorter than ACCESS_TOKEN_TTL, which is
+	// the default.
+	func(c *Config) error {
+		interval, err := lookupInt("KEY_ROTATION_INTERVAL", int(c.AccessTokenTTL/time.Second))
+		if err != nil {
+			return err
+		}
+		if time.Duration(interval)*time.Second < c.AccessTokenTTL {
+			return ErrInvalidEnvVar{"KEY_ROTATION_INTERVAL", fmt.Errorf("may not be shorter than ACCESS_TOKEN_TTL")}
+		}
+		c.KeyRotationInterval = time.Duration(interval) * time.Second
+		return nil
+	},
+
+	// AUDIENCE is the `aud` claim of identity tokens. It defaults to the domain that requested the
+	// token, and may be a stable identifier (like "myapp") so that tokens are unaffected when the
+	// app moves between domains. APP_DOMAIN_SETTINGS may override it for specific domains.
+	func(c *Config) error {
+		val, ok := lookupEnv("AUDIENCE")
+		if ok {
+			c.Audience = val
+		}
+		return nil
+	},
+
+	// APP_DOMAIN_SETTINGS is a JSON object of overrides for specific APP_DOMAINS, keyed by domain
+	// (wildcards included). Each domain may specify a `cookie_domain` and `cookie_path` for the
+	// session cookie, an `audience` for access tokens, and an `access_token_ttl` in seconds.
+	//
+	// Since signing keys are rotated according to ACCESS_TOKEN_TTL, a domain's access_token_ttl may
+	// be shorter but not longer.
+	//
+	// example: {"admin.example.com": {"access_token_ttl": 300, "audience": "admin"}}
+	func(c *Config) error {
+		val, ok := lookupEnv("APP_DOMAIN_SETTINGS")
+		if !ok {
+			return nil
+		}
+		overrides := map[string]struct {
+			CookieDomain   string `json:"cookie_domain"`
+			CookiePath     string `json:"cookie_path"`
+			Audience       string `json:"audience"`
+			AccessTokenTTL int    `json:"access_token_ttl"`
+		}{}
+		if err := json.Unmarshal([]byte(val), &overrides); err != nil {
+			return ErrInvalidEnvVar{"APP_DOMAIN_SETTINGS", err}
+		}
+
+		domains := make([]string, 0, len(overrides))
+		for domain := range overrides {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+
+		c.DomainSettings = make([]DomainSettings, 0, len(domains))
+		for _, domain := range domains {
+			o := overrides[domain]
+			ttl := time.Duration(o.AccessTokenTTL) * time.Second
+			if ttl > c.AccessTokenTTL {
+				return ErrInvalidEnvVar{"APP_DOMAIN_SETTINGS", fmt.Errorf("%s: access_token_ttl may not exceed ACCESS_TOKEN_TTL", domain)}
+			}
+			c.DomainSettings = append(c.DomainSettings, DomainSettings{
+				Domain:         route.ParseDomain(domain),
+				CookieDomain:   o.CookieDomain,
+				CookiePath:     o.CookiePath,
+				Audience:       o.Audience,
+				AccessTokenTTL: ttl,
+			})
+		}
+		return nil
+	},
+
+	// HTTP_AUTH_USERNAME and HTTP_AUTH_PASSWORD specify the basic auth credentials
+	// that must be provided to access private endpoints.
+	//
+	// This security pattern requires communication with AuthN to use SSL.
+	func(c *Config) error {
+		if val, ok := lookupEnv("HTTP_AUTH_USERNAME"); ok {
+			c.AuthUsername = val
+		} else {
+			i, err := rand.Int(rand.Reader, big.NewInt(99999999))
+			if err != nil {
+				return err
+			}
+			c.AuthUsername = i.String()
+		}
+		if val, ok := lookupEnv("HTTP_AUTH_PASSWORD"); ok {
+			c.AuthPassword = val
+		} else {
+			i, err := rand.Int(rand.Reader, big.NewInt(99999999))
+			if err != nil {
+				return err
+			}
+			c.AuthPassword = i.String()
+		}
+		return nil
+	},
+
+	// APP_PASSWORD_CHANGED_URL is an endpoint that will be notified when an account
+	// has changed its password. This notification may be used to deliver an email
+	// confirmation.
 	//
 	// For security, this URL should specify https and include a basic auth username
 	// and password.
@@ -345,6 +1051,19 @@ var configurers = []configurer{
 		return err
 	},
 
+	// APP_ACCOUNT_DELETED_URL is an endpoint that will be notified when a user has deleted their
+	// own account, so that the app may delete its own data for the account.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := lookupURL("APP_ACCOUNT_DELETED_URL")
+		if err == nil && val != nil {
+			c.AppAccountDeletedURL = val
+		}
+		return err
+	},
+
 	// APP_PASSWORD_RESET_URL is an endpoint that will be notified when an account
 	// has requested a password reset. The endpoint is expected to deliver an email
 	// with the given password reset token, then respond with a 2xx HTTP status.
@@ -373,32 +1092,457 @@ var configurers = []configurer{
 		return err
 	},
 
-	// RSA_PRIVATE_KEY is a RSA private key in PEM format. If provided as a single
-	// line string, any literal \n sequences will be converted to real linebreaks.
-	// When provided, it will be used for signing identity tokens, and the public
-	// key will be published for audiences to verify. When not provided, AuthN will
-	// generate and manage keys itself, using Redis for coordination and
-	// persistence.
+	// APP_MAGIC_LINK_URL is another name for APP_PASSWORDLESS_TOKEN_URL, for applications that
+	// deliver the passwordless token in an emailed link. The page that the link opens should submit
+	// the token to POST /session/token, which establishes the session. Only one of the two may be
+	// set.
 	func(c *Config) error {
-		if str, ok := os.LookupEnv("RSA_PRIVATE_KEY"); ok {
-			str = strings.Replace(str, `\n`, "\n", -1)
-			block, _ := pem.Decode([]byte(str))
-			key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		val, err := lookupURL("APP_MAGIC_LINK_URL")
+		if err != nil || val == nil {
+			return err
+		}
+		if c.AppPasswordlessTokenURL != nil {
+			return ErrInvalidEnvVar{"APP_MAGIC_LINK_URL", fmt.Errorf("may not be combined with APP_PASSWORDLESS_TOKEN_URL")}
+		}
+		c.AppPasswordlessTokenURL = val
+		return nil
+	},
+
+	// APP_EMAIL_VERIFICATION_URL is an endpoint that will be notified when an account
+	// should verify its email. The endpoint is expected to deliver an email with the given
+	// verification token, then respond with a 2xx HTTP status.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := lookupURL("APP_EMAIL_VERIFICATION_URL")
+		if err == nil && val != nil {
+			c.AppEmailVerificationURL = val
+		}
+		return err
+	},
+
+	// APP_EMAIL_CHANGE_URL is an endpoint that will be notified when an account asks to change
+	// its email. The endpoint is expected to deliver an email to the new address with the given
+	// confirmation token, then respond with a 2xx HTTP status. The username does not change until
+	// the token is confirmed.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := lookupURL("APP_EMAIL_CHANGE_URL")
+		if err == nil && val != nil {
+			c.AppEmailChangeURL = val
+		}
+		return err
+	},
+
+	// APP_OTP_DELIVERY_URL is an endpoint that will be notified when an account needs a one-time
+	// password at login. The endpoint is expected to deliver the given code with its own SMS or
+	// voice provider, then respond with a 2xx HTTP status. AuthN generates, rate limits, and
+	// verifies the codes, which are stored in Redis, so REDIS_URL is required.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := lookupURL("APP_OTP_DELIVERY_URL")
+		if err == nil && val != nil {
+			if c.RedisURL == nil {
+				return ErrInvalidEnvVar{"APP_OTP_DELIVERY_URL", fmt.Errorf("delivered codes require REDIS_URL")}
+			}
+			c.AppOTPDeliveryURL = val
+		}
+		return err
+	},
+
+	// OTP_DELIVERY_TTL determines how long a code from APP_OTP_DELIVERY_URL may be used.
+	func(c *Config) error {
+		ttl, err := lookupInt("OTP_DELIVERY_TTL", 300)
+		if err == nil {
+			c.OTPDeliveryTTL = time.Duration(ttl) * time.Second
+		}
+		return err
+	},
+
+	// LOGIN_LOCKOUT_THRESHOLD slows guessing after that many failed logins for a username within
+	// LOGIN_FAILURE_WINDOW. The default is no limit.
+	//
+	// LOGIN_LOCKOUT_DELAY is a truthy string ("t", "true", "yes") that delays further logins for the
+	// username from the client IP. The delay doubles with each further failure, up to
+	// LOGIN_FAILURE_WINDOW. This is the default. When disabled, the account is locked for
+	// LOGIN_FAILURE_WINDOW instead.
+	//
+	// LOGIN_IP_THRESHOLD refuses logins from a client IP after that many failed logins within
+	// LOGIN_FAILURE_WINDOW, until the window passes. The default is no limit.
+	//
+	// Failures are counted in Redis, so REDIS_URL is required.
+	func(c *Config) error {
+		lockout, err := lookupInt("LOGIN_LOCKOUT_THRESHOLD", 0)
+		if err != nil {
+			return err
+		}
+		ip, err := lookupInt("LOGIN_IP_THRESHOLD", 0)
+		if err != nil {
+			return err
+		}
+		window, err := lookupInt("LOGIN_FAILURE_WINDOW", 900)
+		if err != nil {
+			return err
+		}
+		delay, err := lookupBool("LOGIN_LOCKOUT_DELAY", true)
+		if err != nil {
+			return err
+		}
+		if lockout > 0 && c.RedisURL == nil {
+			return ErrInvalidEnvVar{"LOGIN_LOCKOUT_THRESHOLD", fmt.Errorf("failed logins require REDIS_URL")}
+		}
+		if ip > 0 && c.RedisURL == nil {
+			return ErrInvalidEnvVar{"LOGIN_IP_THRESHOLD", fmt.Errorf("failed logins require REDIS_URL")}
+		}
+		if window <= 0 {
+			return ErrInvalidEnvVar{"LOGIN_FAILURE_WINDOW", fmt.Errorf("must be positive")}
+		}
+		c.LoginLockoutThreshold = lockout
+		c.LoginIPThreshold = ip
+		c.LoginFailureWindow = time.Duration(window) * time.Second
+		c.LoginLockoutDelay = delay
+		return nil
+	},
+
+	// RATE_LIMIT_IP limits how many times a client IP may log in, sign up, or request a password
+	// reset within RATE_LIMIT_WINDOW. The default is no limit.
+	//
+	// RATE_LIMIT_ACCOUNT limits the same requests for any one username.
+	//
+	// Each limit is a token bucket that refills steadily over the window, so that a client may burst
+	// up to the limit but not sustain it. Buckets are kept in Redis, so REDIS_URL is required.
+	func(c *Config) error {
+		ip, err := lookupInt("RATE_LIMIT_IP", 0)
+		if err != nil {
+			return err
+		}
+		account, err := lookupInt("RATE_LIMIT_ACCOUNT", 0)
+		if err != nil {
+			return err
+		}
+		window, err := lookupInt("RATE_LIMIT_WINDOW", 60)
+		if err != nil {
+			return err
+		}
+		if ip > 0 && c.RedisURL == nil {
+			return ErrInvalidEnvVar{"RATE_LIMIT_IP", fmt.Errorf("rate limits require REDIS_URL")}
+		}
+		if account > 0 && c.RedisURL == nil {
+			return ErrInvalidEnvVar{"RATE_LIMIT_ACCOUNT", fmt.Errorf("rate limits require REDIS_URL")}
+		}
+		if window <= 0 {
+			return ErrInvalidEnvVar{"RATE_LIMIT_WINDOW", fmt.Errorf("must be positive")}
+		}
+		c.RateLimitIP = ip
+		c.RateLimitAccount = account
+		c.RateLimitWindow = time.Duration(window) * time.Second
+		return nil
+	},
+
+	// IDEMPOTENCY_KEY_TTL is how long a response is replayed to retries that send the same
+	// Idempotency-Key header. Responses are kept in Redis, so the header is ignored without
+	// REDIS_URL.
+	func(c *Config) error {
+		ttl, err := lookupInt("IDEMPOTENCY_KEY_TTL", 86400)
+		if err != nil {
+			return err
+		}
+		if ttl <= 0 {
+			return ErrInvalidEnvVar{"IDEMPOTENCY_KEY_TTL", fmt.Errorf("must be positive")}
+		}
+		c.IdempotencyKeyTTL = time.Duration(ttl) * time.Second
+		return nil
+	},
+
+	// APP_CLAIMS_URL is an endpoint that will be asked for extra identity token claims whenever a
+	// token is issued. AuthN will POST the account_id, and expects a JSON object of claims.
+	//
+	// For security, this URL should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, err := lookupURL("APP_CLAIMS_URL")
+		if err == nil && val != nil {
+			c.AppClaimsURL = val
+		}
+		return err
+	},
+
+	// APP_BACKCHANNEL_LOGOUT_URLS is a comma separated list of endpoints that will be sent an OIDC
+	// logout token whenever a session is revoked, so that applications may end their own sessions
+	// without waiting for identity tokens to expire.
+	//
+	// For security, these URLs should specify https and include a basic auth username
+	// and password.
+	func(c *Config) error {
+		val, ok := lookupEnv("APP_BACKCHANNEL_LOGOUT_URLS")
+		if !ok {
+			return nil
+		}
+		for _, str := range strings.Split(val, ",") {
+			str = strings.TrimSpace(str)
+			if str == "" {
+				continue
+			}
+			u, err := url.Parse(str)
 			if err != nil {
-				return err
+				return ErrInvalidEnvVar{"APP_BACKCHANNEL_LOGOUT_URLS", err}
 			}
-			c.IdentitySigningKey, err = private.NewKey(key)
+			c.AppBackchannelLogoutURLs = append(c.AppBackchannelLogoutURLs, u)
+		}
+		return nil
+	},
+
+	// APP_EVENTS_URLS is a comma separated list of endpoints that will be sent a JSON event when an
+	// account is created, locked, unlocked, or archived, when a session is created, and when a
+	// password is changed. Events are queued in Redis and retried until delivered, so REDIS_URL is
+	// required, and an endpoint may receive an event more than once.
+	//
+	// APP_EVENTS_SIGNING_KEY is a shared secret used to sign each event, so that endpoints may verify
+	// that it came from AuthN. It is required with APP_EVENTS_URLS.
+	//
+	// For security, these URLs should specify https.
+	func(c *Config) error {
+		val, ok := lookupEnv("APP_EVENTS_URLS")
+		if !ok {
+			return nil
+		}
+		for _, str := range strings.Split(val, ",") {
+			str = strings.TrimSpace(str)
+			if str == "" {
+				continue
+			}
+			u, err := url.Parse(str)
 			if err != nil {
-				return err
+				return ErrInvalidEnvVar{"APP_EVENTS_URLS", err}
+			}
+			c.AppEventsURLs = append(c.AppEventsURLs, u)
+		}
+		if len(c.AppEventsURLs) == 0 {
+			return nil
+		}
+		if c.RedisURL == nil {
+			return ErrInvalidEnvVar{"APP_EVENTS_URLS", fmt.Errorf("events require REDIS_URL")}
+		}
+		key, err := requireEnv("APP_EVENTS_SIGNING_KEY")
+		if err != nil {
+			return err
+		}
+		c.AppEventsSigningKey = []byte(key)
+		return nil
+	},
+
+	// IDENTITY_CLAIMS is a JSON object of static claims to include in every identity token, like
+	// {"tenant": "acme"}. The standard claims (iss, sub, aud, exp, iat, auth_time, sid) may not be
+	// replaced.
+	func(c *Config) error {
+		val, ok := lookupEnv("IDENTITY_CLAIMS")
+		if !ok {
+			return nil
+		}
+		claims := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(val), &claims); err != nil {
+			return ErrInvalidEnvVar{"IDENTITY_CLAIMS", err}
+		}
+		for _, name := range standardClaims {
+			if _, ok := claims[name]; ok {
+				return ErrInvalidEnvVar{"IDENTITY_CLAIMS", fmt.Errorf("%s is a standard claim", name)}
 			}
 		}
+		c.IdentityClaims = claims
+		return nil
+	},
+
+	// IDENTITY_METADATA_CLAIMS is a comma separated list of account metadata keys to include as
+	// claims in identity tokens. Keys that an account does not have are left out. The standard
+	// claims may not be named.
+	func(c *Config) error {
+		val, ok := lookupEnv("IDENTITY_METADATA_CLAIMS")
+		if !ok {
+			return nil
+		}
+		for _, key := range strings.Split(val, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			for _, name := range standardClaims {
+				if key == name {
+					return ErrInvalidEnvVar{"IDENTITY_METADATA_CLAIMS", fmt.Errorf("%s is a standard claim", name)}
+				}
+			}
+			c.IdentityMetadataClaims = append(c.IdentityMetadataClaims, key)
+		}
+		return nil
+	},
+
+	// IDENTITY_SIGNING_KEY is a private key in PEM format: PKCS#1 RSA, SEC 1 EC, or PKCS#8 RSA,
+	// ECDSA, or Ed25519. If provided as a single line string, any literal \n sequences will be
+	// converted to real linebreaks. When provided, it will be used for signing identity tokens
+	// (with RS256, ES256/ES384/ES512, or EdDSA) and the public key will be published for
+	// audiences to verify. When not provided, AuthN will generate and manage RSA keys itself,
+	// using Redis for coordination and persistence.
+	//
+	// Encrypted keys are decrypted with IDENTITY_SIGNING_KEY_PASSPHRASE.
+	//
+	// Multiple keys may be concatenated to support manual rotation. The last key signs new tokens,
+	// and all of the keys are published.
+	//
+	// RSA_PRIVATE_KEY is the original name, and is still accepted.
+	func(c *Config) error {
+		name := "IDENTITY_SIGNING_KEY"
+		str, ok := lookupEnv(name)
+		if !ok {
+			name = "RSA_PRIVATE_KEY"
+			str, ok = lookupEnv(name)
+		}
+		if ok {
+			str = strings.Replace(str, `\n`, "\n", -1)
+			var keys []*private.Key
+			rest := []byte(str)
+			for {
+				var block *pem.Block
+				block, rest = pem.Decode(rest)
+				if block == nil {
+					break
+				}
+				if private.IsEncrypted(block) {
+					passphrase, err := requireEnv("IDENTITY_SIGNING_KEY_PASSPHRASE")
+					if err != nil {
+						return err
+					}
+					block, err = private.DecryptPEM(block, []byte(passphrase))
+					if err != nil {
+						return ErrInvalidEnvVar{name, err}
+					}
+				}
+				key, err := private.ParsePEM(block)
+				if err != nil {
+					return ErrInvalidEnvVar{name, err}
+				}
+				keys = append(keys, key)
+			}
+			if len(keys) == 0 {
+				return ErrInvalidEnvVar{name, errors.New("no PEM data found")}
+			}
+			c.IdentitySigningKey = keys[len(keys)-1]
+			c.PreviousIdentitySigningKeys = keys[:len(keys)-1]
+		}
+		return nil
+	},
+
+	// IDENTITY_SIGNING_KEY_KMS is a comma separated list of asymmetric keys in AWS KMS
+	// (awskms://alias/authn) or Google Cloud KMS (gcpkms://projects/.../cryptoKeyVersions/1). The
+	// private keys never leave the KMS: identity tokens are signed through its API, and the public
+	// keys are fetched at boot for publishing. As with IDENTITY_SIGNING_KEY, the last key signs new
+	// tokens.
+	func(c *Config) error {
+		val, ok := lookupEnv("IDENTITY_SIGNING_KEY_KMS")
+		if !ok {
+			return nil
+		}
+		if c.IdentitySigningKey != nil {
+			return ErrInvalidEnvVar{"IDENTITY_SIGNING_KEY_KMS", errors.New("may not be combined with IDENTITY_SIGNING_KEY")}
+		}
+		var uris []string
+		for _, uri := range strings.Split(val, ",") {
+			uri = strings.TrimSpace(uri)
+			if !kms.IsKeyURI(uri) {
+				return ErrInvalidEnvVar{"IDENTITY_SIGNING_KEY_KMS", fmt.Errorf("unsupported KMS key: %q", uri)}
+			}
+			uris = append(uris, uri)
+		}
+		keys, err := newKMSKeys(uris...)
+		if err != nil {
+			return ErrInvalidEnvVar{"IDENTITY_SIGNING_KEY_KMS", err}
+		}
+		c.IdentitySigningKey = keys[len(keys)-1]
+		c.PreviousIdentitySigningKeys = keys[:len(keys)-1]
+		return nil
+	},
+
+	// JWT_SIGNING_ALGORITHM is RS256 (the default), ES256, or EdDSA (Ed25519), for the keys that
+	// AuthN generates when IDENTITY_SIGNING_KEY is not configured. ES256 and EdDSA tokens are smaller
+	// and faster to sign. When a key is configured, the algorithm is taken from the key, and must
+	// match this setting if given.
+	func(c *Config) error {
+		val, ok := lookupEnv("JWT_SIGNING_ALGORITHM")
+		if !ok {
+			c.SigningAlgorithm = jose.RS256
+			if c.IdentitySigningKey != nil {
+				c.SigningAlgorithm = c.IdentitySigningKey.Algorithm()
+			}
+			return nil
+		}
+		var alg jose.SignatureAlgorithm
+		for _, supported := range []jose.SignatureAlgorithm{jose.RS256, jose.ES256, jose.EdDSA} {
+			if strings.EqualFold(val, string(supported)) {
+				alg = supported
+			}
+		}
+		if alg == "" {
+			return ErrInvalidEnvVar{"JWT_SIGNING_ALGORITHM", fmt.Errorf("unsupported algorithm: %s (expected RS256, ES256, or EdDSA)", val)}
+		}
+		if c.IdentitySigningKey != nil && c.IdentitySigningKey.Algorithm() != alg {
+			return ErrInvalidEnvVar{"JWT_SIGNING_ALGORITHM", fmt.Errorf("IDENTITY_SIGNING_KEY signs with %s", c.IdentitySigningKey.Algorithm())}
+		}
+		c.SigningAlgorithm = alg
+		return nil
+	},
+
+	// IDENTITY_ENCRYPTION_KEY is an application's PEM-encoded RSA or EC (P-256) public key. When
+	// configured, identity tokens are signed and then encrypted to this key as a nested JWT, so that
+	// their claims can not be read by intermediaries.
+	func(c *Config) error {
+		str, ok := lookupEnv("IDENTITY_ENCRYPTION_KEY")
+		if !ok {
+			return nil
+		}
+		block, _ := pem.Decode([]byte(strings.Replace(str, `\n`, "\n", -1)))
+		if block == nil {
+			return ErrInvalidEnvVar{"IDENTITY_ENCRYPTION_KEY", errors.New("expected a PEM public key")}
+		}
+		var key interface{}
+		var err error
+		if block.Type == "RSA PUBLIC KEY" {
+			key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		} else {
+			key, err = x509.ParsePKIXPublicKey(block.Bytes)
+		}
+		if err != nil {
+			return ErrInvalidEnvVar{"IDENTITY_ENCRYPTION_KEY", err}
+		}
+		var alg jose.KeyAlgorithm
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			alg = jose.RSA_OAEP_256
+		case *ecdsa.PublicKey:
+			if k.Curve != elliptic.P256() {
+				return ErrInvalidEnvVar{"IDENTITY_ENCRYPTION_KEY", errors.New("unsupported curve (expected P-256)")}
+			}
+			alg = jose.ECDH_ES_A256KW
+		default:
+			return ErrInvalidEnvVar{"IDENTITY_ENCRYPTION_KEY", fmt.Errorf("unsupported key type: %T", key)}
+		}
+		jwk := &jose.JSONWebKey{Key: key, Algorithm: string(alg), Use: "enc"}
+		// the kid tells the application which of its keys to decrypt with
+		thumbprint, err := jwk.Thumbprint(crypto.SHA256)
+		if err != nil {
+			return ErrInvalidEnvVar{"IDENTITY_ENCRYPTION_KEY", err}
+		}
+		jwk.KeyID = base64.RawURLEncoding.EncodeToString(thumbprint)
+		c.IdentityEncryptionKey = jwk
 		return nil
 	},
 
 	// TIME_ZONE is the IANA name of a location that should be used when calculating
 	// which day it is when tracking key stats. It defaults to UTC.
 	func(c *Config) error {
-		name, ok := os.LookupEnv("TIME_ZONE")
+		name, ok := lookupEnv("TIME_ZONE")
 		if !ok {
 			name = "UTC"
 		}
@@ -434,7 +1578,7 @@ var configurers = []configurer{
 	// SENTRY_DSN is a configuration string for the Sentry error reporting backend. When provided,
 	// errors and panics will be reported asynchronously.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("SENTRY_DSN"); ok {
+		if val, ok := lookupEnv("SENTRY_DSN"); ok {
 			c.ErrorReporterCredentials = val
 			c.ErrorReporterType = ops.Sentry
 		}
@@ -444,17 +1588,86 @@ var configurers = []configurer{
 	// AIRBRAKE_CREDENTIALS is a configuration string for the Airbrake error reporting backend. When
 	// provided, errors and panics will be reported asynchronously.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("AIRBRAKE_CREDENTIALS"); ok {
+		if val, ok := lookupEnv("AIRBRAKE_CREDENTIALS"); ok {
 			c.ErrorReporterCredentials = val
 			c.ErrorReporterType = ops.Airbrake
 		}
 		return nil
 	},
 
+	// OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT enables OpenTelemetry tracing
+	// with an OTLP/HTTP exporter, unless OTEL_SDK_DISABLED is true or OTEL_TRACES_EXPORTER is none.
+	// The exporter, sampler, and resource read the rest of the standard OTEL_* variables, which is
+	// why these are only read from the environment.
+	func(c *Config) error {
+		_, endpoint := os.LookupEnv("OTEL_EXPORTER_OTLP_ENDPOINT")
+		_, tracesEndpoint := os.LookupEnv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+		disabled := strings.EqualFold(os.Getenv("OTEL_SDK_DISABLED"), "true")
+		c.TracingEnabled = (endpoint || tracesEndpoint) && !disabled && os.Getenv("OTEL_TRACES_EXPORTER") != "none"
+		return nil
+	},
+
+	// LOG_LEVEL is the least severe level that will be logged: debug, info, warn, or error. The
+	// default is info.
+	func(c *Config) error {
+		c.LogLevel = logrus.InfoLevel
+		if val, ok := lookupEnv("LOG_LEVEL"); ok {
+			level, err := logrus.ParseLevel(val)
+			if err != nil {
+				return ErrInvalidEnvVar{"LOG_LEVEL", err}
+			}
+			c.LogLevel = level
+		}
+		return nil
+	},
+
+	// LOG_FORMAT may be "json" (the default) for log aggregators, or "text" for humans.
+	func(c *Config) error {
+		c.LogFormat = "json"
+		if val, ok := lookupEnv("LOG_FORMAT"); ok {
+			switch strings.ToLower(val) {
+			case "json", "text":
+				c.LogFormat = strings.ToLower(val)
+			default:
+				return ErrInvalidEnvVar{"LOG_FORMAT", fmt.Errorf("expected json or text")}
+			}
+		}
+		return nil
+	},
+
+	// LISTEN is the local address the AuthN server binds PORT and PUBLIC_PORT to, like 127.0.0.1 or
+	// ::1. The default is every interface.
+	//
+	// LISTEN may instead be a Unix domain socket, like unix:///var/run/authn.sock, for a proxy on the
+	// same host. The socket replaces PORT.
+	func(c *Config) error {
+		val, ok := lookupEnv("LISTEN")
+		if !ok {
+			return nil
+		}
+		if strings.HasPrefix(val, "unix://") {
+			path := strings.TrimPrefix(val, "unix://")
+			if path == "" {
+				return ErrInvalidEnvVar{"LISTEN", fmt.Errorf("must include a socket path")}
+			}
+			c.ListenSocket = path
+			return nil
+		}
+		val = strings.TrimSuffix(strings.TrimPrefix(val, "["), "]")
+		if _, _, err := net.SplitHostPort(val); err == nil {
+			return ErrInvalidEnvVar{"LISTEN", fmt.Errorf("must not include a port (see PORT)")}
+		}
+		c.ListenAddress = val
+		return nil
+	},
+
 	// PORT is the local port the AuthN server listens to. The default is taken from AUTHN_URL, but
 	// may be different for port mapping scenarios as with containers and load balancers.
 	func(c *Config) error {
-		defaultPort, err := strconv.Atoi(c.AuthNURL.Port())
+		var defaultPort int
+		if c.AuthNURL != nil {
+			defaultPort, _ = strconv.Atoi(c.AuthNURL.Port())
+		}
 		val, err := lookupInt("PORT", defaultPort)
 		if err == nil {
 			c.ServerPort = val
@@ -467,12 +1680,150 @@ var configurers = []configurer{
 	// load balancer to forward to only the appropriate port.
 	func(c *Config) error {
 		val, err := lookupInt("PUBLIC_PORT", 0)
+		if err != nil {
+			return err
+		}
+		if val != 0 && c.ListenSocket != "" {
+			return ErrInvalidEnvVar{"PUBLIC_PORT", fmt.Errorf("can not be combined with a LISTEN socket")}
+		}
+		c.PublicPort = val
+		return nil
+	},
+
+	// HTTP_READ_TIMEOUT, HTTP_WRITE_TIMEOUT, and HTTP_IDLE_TIMEOUT are how many seconds the server
+	// waits to read a request, to write a response, and for the next request on a keep-alive
+	// connection. HTTP_MAX_HEADER_BYTES limits the size of request headers. Together they keep slow
+	// or idle clients from holding connections open. Zero means no limit.
+	func(c *Config) error {
+		timeouts := []struct {
+			name  string
+			value *time.Duration
+			def   int
+		}{
+			{"HTTP_READ_TIMEOUT", &c.HTTPReadTimeout, 30},
+			{"HTTP_WRITE_TIMEOUT", &c.HTTPWriteTimeout, 60},
+			{"HTTP_IDLE_TIMEOUT", &c.HTTPIdleTimeout, 120},
+		}
+		for _, t := range timeouts {
+			seconds, err := lookupInt(t.name, t.def)
+			if err != nil {
+				return err
+			}
+			if seconds < 0 {
+				return ErrInvalidEnvVar{t.name, fmt.Errorf("must not be negative")}
+			}
+			*t.value = time.Duration(seconds) * time.Second
+		}
+
+		bytes, err := lookupInt("HTTP_MAX_HEADER_BYTES", 64<<10)
+		if err != nil {
+			return err
+		}
+		if bytes < 0 {
+			return ErrInvalidEnvVar{"HTTP_MAX_HEADER_BYTES", fmt.Errorf("must not be negative")}
+		}
+		c.HTTPMaxHeaderBytes = bytes
+		return nil
+	},
+
+	// TLS_CERT and TLS_KEY are the PEM-encoded certificate (chain) and private key that AuthN
+	// presents on PORT and PUBLIC_PORT, so that it may serve https without a proxy.
+	func(c *Config) error {
+		cert, certOK := lookupEnv("TLS_CERT")
+		key, keyOK := lookupEnv("TLS_KEY")
+		if !certOK && !keyOK {
+			return nil
+		} else if !certOK {
+			return ErrMissingEnvVar("TLS_CERT")
+		} else if !keyOK {
+			return ErrMissingEnvVar("TLS_KEY")
+		}
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return ErrInvalidEnvVar{"TLS_CERT", err}
+		}
+		c.TLSCertificate = &pair
+		return nil
+	},
+
+	// TLS_AUTOCERT is a directory where AuthN keeps certificates that it obtains from Let's Encrypt
+	// for the AUTHN_URL's hostname, as an alternative to TLS_CERT. Certificates are requested with
+	// the TLS-ALPN-01 challenge, so PORT must be reachable on 443. TLS_AUTOCERT_EMAIL is an optional
+	// contact for the account with Let's Encrypt.
+	func(c *Config) error {
+		dir, ok := lookupEnv("TLS_AUTOCERT")
+		if !ok {
+			return nil
+		}
+		if dir == "" {
+			return ErrInvalidEnvVar{"TLS_AUTOCERT", errors.New("must be a directory")}
+		}
+		if c.TLSCertificate != nil {
+			return ErrInvalidEnvVar{"TLS_AUTOCERT", errors.New("must not be combined with TLS_CERT")}
+		}
+		if len(c.AuthNURLs) == 0 {
+			return ErrInvalidEnvVar{"TLS_AUTOCERT", errors.New("requires an https AUTHN_URL")}
+		}
+		for _, u := range c.AuthNURLs {
+			if u.Scheme != "https" {
+				return ErrInvalidEnvVar{"TLS_AUTOCERT", errors.New("requires an https AUTHN_URL")}
+			}
+		}
+		c.TLSAutocertDir = dir
+		c.TLSAutocertEmail, _ = lookupEnv("TLS_AUTOCERT_EMAIL")
+		return nil
+	},
+
+	// GRPC_PORT is an extra local port the AuthN server listens to with a gRPC API for private
+	// operations. Connections require TLS with a client certificate (see GRPC_CLIENT_CA).
+	func(c *Config) error {
+		val, err := lookupInt("GRPC_PORT", 0)
 		if err == nil {
-			c.PublicPort = val
+			c.GRPCPort = val
 		}
 		return err
 	},
 
+	// GRPC_TLS_CERT and GRPC_TLS_KEY are the PEM-encoded certificate (chain) and private key that
+	// the gRPC API presents to clients. They are required with GRPC_PORT.
+	func(c *Config) error {
+		if c.GRPCPort == 0 {
+			return nil
+		}
+		cert, ok := lookupEnv("GRPC_TLS_CERT")
+		if !ok {
+			return ErrMissingEnvVar("GRPC_TLS_CERT")
+		}
+		key, ok := lookupEnv("GRPC_TLS_KEY")
+		if !ok {
+			return ErrMissingEnvVar("GRPC_TLS_KEY")
+		}
+		pair, err := tls.X509KeyPair([]byte(cert), []byte(key))
+		if err != nil {
+			return ErrInvalidEnvVar{"GRPC_TLS_CERT", err}
+		}
+		c.GRPCCertificate = &pair
+		return nil
+	},
+
+	// GRPC_CLIENT_CA is one or more PEM-encoded certificates that sign the client certificates of
+	// services allowed to use the gRPC API. It is required with GRPC_PORT.
+	func(c *Config) error {
+		if c.GRPCPort == 0 {
+			return nil
+		}
+		val, ok := lookupEnv("GRPC_CLIENT_CA")
+		if !ok {
+			return ErrMissingEnvVar("GRPC_CLIENT_CA")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(val)) {
+			return ErrInvalidEnvVar{"GRPC_CLIENT_CA", errors.New("no PEM certificates found")}
+		}
+		c.GRPCClientCAs = pool
+		return nil
+	},
+
 	// PROXIED is a flag that indicates AuthN is behind a proxy. When set, AuthN will read IP
 	// addresses from X-FORWARDED-FOR (and similar).
 	func(c *Config) error {
@@ -483,10 +1834,91 @@ var configurers = []configurer{
 		return err
 	},
 
+	// TRUSTED_PROXIES is a comma separated list of IP addresses and CIDR ranges, like
+	// "10.0.0.0/8,192.168.1.10". When set, AuthN is PROXIED but only reads forwarded headers from
+	// these peers, and X-FORWARDED-FOR is followed back through trusted proxies to the client.
+	func(c *Config) error {
+		val, ok := lookupEnv("TRUSTED_PROXIES")
+		if !ok {
+			return nil
+		}
+		for _, str := range strings.Split(val, ",") {
+			str = strings.TrimSpace(str)
+			if str == "" {
+				continue
+			}
+			if !strings.Contains(str, "/") {
+				ip := net.ParseIP(str)
+				if ip == nil {
+					return ErrInvalidEnvVar{"TRUSTED_PROXIES", fmt.Errorf("invalid IP address: %s", str)}
+				}
+				bits := 8 * net.IPv6len
+				if ip.To4() != nil {
+					ip, bits = ip.To4(), 8*net.IPv4len
+				}
+				c.TrustedProxies = append(c.TrustedProxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+				continue
+			}
+			_, ipNet, err := net.ParseCIDR(str)
+			if err != nil {
+				return ErrInvalidEnvVar{"TRUSTED_PROXIES", err}
+			}
+			c.TrustedProxies = append(c.TrustedProxies, ipNet)
+		}
+		if len(c.TrustedProxies) > 0 {
+			c.Proxied = true
+		}
+		return nil
+	},
+
+	// WATCH_SECRET_FILES is a flag that reloads configuration when a file named by a _FILE
+	// variable changes, as when a mounted Kubernetes secret is updated.
+	func(c *Config) error {
+		val, err := lookupBool("WATCH_SECRET_FILES", false)
+		if err == nil {
+			c.WatchSecretFiles = val
+		}
+		return err
+	},
+
+	// SESSION_COOKIE_NAME is the name of the AuthN session cookie. It defaults to "authn", and may
+	// be changed to avoid collisions between AuthN servers that share a cookie domain.
+	func(c *Config) error {
+		if val, ok := lookupEnv("SESSION_COOKIE_NAME"); ok {
+			if val == "" {
+				return ErrInvalidEnvVar{"SESSION_COOKIE_NAME", errors.New("must not be blank")}
+			}
+			c.SessionCookieName = val
+		}
+		return nil
+	},
+
+	// COOKIE_DOMAIN sets the Domain property of the AuthN session cookie, so that it may be shared
+	// with sibling subdomains. When not specified, the cookie is only sent to the AUTHN_URL host.
+	func(c *Config) error {
+		if val, ok := lookupEnv("COOKIE_DOMAIN"); ok {
+			c.CookieDomain = val
+		}
+		return nil
+	},
+
+	// COOKIE_PATH sets the Path property of the AuthN session cookie. It defaults to the path of
+	// AUTHN_URL, and must still include the AuthN session routes.
+	func(c *Config) error {
+		if val, ok := lookupEnv("COOKIE_PATH"); ok {
+			if !strings.HasPrefix(val, "/") {
+				return ErrInvalidEnvVar{"COOKIE_PATH", errors.New("must begin with /")}
+			}
+			c.CookiePath = val
+		}
+		return nil
+	},
+
 	// SAME_SITE sets the SameSite property of the AuthN session cookie. When not specified, AuthN
-	// will choose between Lax and Strict based on the presence of OAuth providers.
+	// will choose between Lax and Strict based on the presence of OAuth providers. NONE requires
+	// the cookie to be Secure, so AUTHN_URL should use https.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("SAME_SITE"); ok {
+		if val, ok := lookupEnv("SAME_SITE"); ok {
 			switch strings.ToUpper(val) {
 			case "NONE":
 				c.SameSite = http.SameSiteNoneMode
@@ -501,10 +1933,26 @@ var configurers = []configurer{
 		return nil
 	},
 
+	// CSRF_PROTECTION adds a check against cross-site requests to the state-changing endpoints that
+	// rely on the session cookie, for when SameSite cookies are not enough. "origin" requires the
+	// Origin header, rather than accepting a Referer. "double_submit" requires an X-CSRF-Token
+	// header that matches the token AuthN sets in a readable cookie with the session.
+	func(c *Config) error {
+		if val, ok := lookupEnv("CSRF_PROTECTION"); ok {
+			switch strings.ToLower(val) {
+			case "origin", "double_submit":
+				c.CSRFProtection = strings.ToLower(val)
+			default:
+				return ErrInvalidEnvVar{"CSRF_PROTECTION", errors.New("must be origin or double_submit")}
+			}
+		}
+		return nil
+	},
+
 	// GOOGLE_OAUTH_CREDENTIALS is a credential pair in the format `id:secret`. When specified,
 	// AuthN will enable routes for Google OAuth signin.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("GOOGLE_OAUTH_CREDENTIALS"); ok {
+		if val, ok := lookupEnv("GOOGLE_OAUTH_CREDENTIALS"); ok {
 			credentials, err := oauth.NewCredentials(val)
 			if err == nil {
 				c.GoogleOauthCredentials = credentials
@@ -517,7 +1965,7 @@ var configurers = []configurer{
 	// GITHUB_OAUTH_CREDENTIALS is a credential pair in the format `id:secret`. When specified,
 	// AuthN will enable routes for GitHub OAuth signin.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("GITHUB_OAUTH_CREDENTIALS"); ok {
+		if val, ok := lookupEnv("GITHUB_OAUTH_CREDENTIALS"); ok {
 			credentials, err := oauth.NewCredentials(val)
 			if err == nil {
 				c.GitHubOauthCredentials = credentials
@@ -530,7 +1978,7 @@ var configurers = []configurer{
 	// FACEBOOK_OAUTH_CREDENTIALS is a credential pair in the format `id:secret`. When specified,
 	// AuthN will enable routes for Facebook OAuth signin.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("FACEBOOK_OAUTH_CREDENTIALS"); ok {
+		if val, ok := lookupEnv("FACEBOOK_OAUTH_CREDENTIALS"); ok {
 			credentials, err := oauth.NewCredentials(val)
 			if err == nil {
 				c.FacebookOauthCredentials = credentials
@@ -543,7 +1991,7 @@ var configurers = []configurer{
 	// DISCORD_OAUTH_CREDENTIALS is a credential pair in the format `id:secret`. When specified,
 	// AuthN will enable routes for Discord OAuth signin.
 	func(c *Config) error {
-		if val, ok := os.LookupEnv("DISCORD_OAUTH_CREDENTIALS"); ok {
+		if val, ok := lookupEnv("DISCORD_OAUTH_CREDENTIALS"); ok {
 			credentials, err := oauth.NewCredentials(val)
 			if err == nil {
 				c.DiscordOauthCredentials = credentials
@@ -552,15 +2000,234 @@ var configurers = []configurer{
 		}
 		return nil
 	},
+
+	// APPLE_OAUTH_CREDENTIALS is a credential set in the format `id:secret:keyID=...,teamID=...`,
+	// where the secret is the hex-encoded private key from Apple. When specified, AuthN will enable
+	// routes for Sign in with Apple.
+	func(c *Config) error {
+		if val, ok := lookupEnv("APPLE_OAUTH_CREDENTIALS"); ok {
+			credentials, err := oauth.NewCredentials(val)
+			if err != nil {
+				return err
+			}
+			_, err = oauth.NewAppleProvider(credentials)
+			if err != nil {
+				return ErrInvalidEnvVar{"APPLE_OAUTH_CREDENTIALS", err}
+			}
+			c.AppleOauthCredentials = credentials
+		}
+		return nil
+	},
+
+	// OIDC_PROVIDERS is a JSON object of OpenID Connect issuers, keyed by a provider name that is
+	// used in the OAuth routes. Each must specify an `issuer` with a discovery document and the
+	// `credentials` in the format `id:secret`, and may specify `scopes` (default: openid, email).
+	//
+	// example: {"acme": {"issuer": "https://idp.acme.com", "credentials": "id:secret"}}
+	func(c *Config) error {
+		val, ok := lookupEnv("OIDC_PROVIDERS")
+		if !ok {
+			return nil
+		}
+		providers := map[string]struct {
+			Issuer      string   `json:"issuer"`
+			Credentials string   `json:"credentials"`
+			Scopes      []string `json:"scopes"`
+		}{}
+		if err := json.Unmarshal([]byte(val), &providers); err != nil {
+			return ErrInvalidEnvVar{"OIDC_PROVIDERS", err}
+		}
+
+		names := make([]string, 0, len(providers))
+		for name := range providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		c.OIDCProviders = make([]OIDCProvider, 0, len(names))
+		for _, name := range names {
+			p := providers[name]
+			if err := checkProviderName(c, name); err != nil {
+				return ErrInvalidEnvVar{"OIDC_PROVIDERS", err}
+			}
+			issuer, err := url.Parse(p.Issuer)
+			if err != nil || issuer.Scheme == "" || issuer.Host == "" {
+				return ErrInvalidEnvVar{"OIDC_PROVIDERS", fmt.Errorf("%s: issuer must be a URL", name)}
+			}
+			credentials, err := oauth.NewCredentials(p.Credentials)
+			if err != nil {
+				return ErrInvalidEnvVar{"OIDC_PROVIDERS", fmt.Errorf("%s: %v", name, err)}
+			}
+			c.OIDCProviders = append(c.OIDCProviders, OIDCProvider{
+				Name:        name,
+				Issuer:      p.Issuer,
+				Credentials: credentials,
+				Scopes:      p.Scopes,
+			})
+		}
+		return nil
+	},
+
+	// SAML_PROVIDERS is a JSON object of SAML identity providers, keyed by a provider name that is
+	// used in the SAML routes. Each must specify a `metadata_url` for the identity provider, and may
+	// specify a `username_attribute` to use instead of the NameID.
+	//
+	// example: {"acme": {"metadata_url": "https://idp.acme.com/metadata", "username_attribute": "email"}}
+	func(c *Config) error {
+		val, ok := lookupEnv("SAML_PROVIDERS")
+		if !ok {
+			return nil
+		}
+		providers := map[string]struct {
+			MetadataURL       string `json:"metadata_url"`
+			UsernameAttribute string `json:"username_attribute"`
+		}{}
+		if err := json.Unmarshal([]byte(val), &providers); err != nil {
+			return ErrInvalidEnvVar{"SAML_PROVIDERS", err}
+		}
+
+		names := make([]string, 0, len(providers))
+		for name := range providers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		c.SAMLProviders = make([]SAMLProvider, 0, len(names))
+		for _, name := range names {
+			p := providers[name]
+			if err := checkProviderName(c, name); err != nil {
+				return ErrInvalidEnvVar{"SAML_PROVIDERS", err}
+			}
+			metadataURL, err := url.Parse(p.MetadataURL)
+			if err != nil || metadataURL.Scheme == "" || metadataURL.Host == "" {
+				return ErrInvalidEnvVar{"SAML_PROVIDERS", fmt.Errorf("%s: metadata_url must be a URL", name)}
+			}
+			c.SAMLProviders = append(c.SAMLProviders, SAMLProvider{
+				Name:              name,
+				MetadataURL:       p.MetadataURL,
+				UsernameAttribute: p.UsernameAttribute,
+			})
+		}
+		return nil
+	},
+	// LDAP_URL is an ldap:// or ldaps:// server that verifies passwords instead of the local
+	// password hashes. Logins bind to the server as the user, and accounts are created on the first
+	// successful login. AuthN still manages sessions and tokens.
+	//
+	// LDAP_BIND_TEMPLATE is the name that a user binds with, where %s is replaced by the username.
+	// It defaults to "uid=%s". For Active Directory, use a template like "%s@corp.example.com".
+	//
+	// LDAP_BASE_DN is appended to the bind name, as in "ou=people,dc=example,dc=com".
+	//
+	// LDAP_START_TLS is a truthy string ("t", "true", "yes") that upgrades an ldap:// connection
+	// with StartTLS before binding.
+	func(c *Config) error {
+		val, err := lookupURL("LDAP_URL")
+		if err != nil || val == nil {
+			return err
+		}
+		if val.Scheme != "ldap" && val.Scheme != "ldaps" {
+			return ErrInvalidEnvVar{"LDAP_URL", fmt.Errorf("scheme must be ldap or ldaps")}
+		}
+		c.LDAPURL = val
+
+		c.LDAPBindTemplate = "uid=%s"
+		if template, ok := lookupEnv("LDAP_BIND_TEMPLATE"); ok {
+			if strings.Count(template, "%s") != 1 {
+				return ErrInvalidEnvVar{"LDAP_BIND_TEMPLATE", fmt.Errorf("must include %%s once")}
+			}
+			c.LDAPBindTemplate = template
+		}
+
+		if baseDN, ok := lookupEnv("LDAP_BASE_DN"); ok {
+			c.LDAPBaseDN = baseDN
+		}
+
+		startTLS, err := lookupBool("LDAP_START_TLS", false)
+		if err != nil {
+			return err
+		}
+		if startTLS && val.Scheme == "ldaps" {
+			return ErrInvalidEnvVar{"LDAP_START_TLS", fmt.Errorf("ldaps:// is already encrypted")}
+		}
+		c.LDAPStartTLS = startTLS
+		return nil
+	},
 }
 
+// newKMSKeys connects to KMS keys. It may be replaced in tests.
+var newKMSKeys = kms.NewKeys
+
 // ReadEnv returns a Config struct from environment variables. It returns errors when a variable is
-// malformatted or missing but required.
+// malformatted or missing but required, reporting all such variables together as ConfigErrors.
+//
+// Settings may also be read from a config file (CONFIG_FILE), a Vault secret (VAULT_SECRET_PATH),
+// or files named by _FILE variables. Environment variables take precedence over _FILE variables,
+// then Vault, then the config file.
 func ReadEnv() (*Config, error) {
+	err := loadSources()
+	if err != nil {
+		return nil, err
+	}
+
 	return configure(configurers)
 }
 
-// 20k iterations of PBKDF2 HMAC SHA-256
-func derive(base []byte, salt string) []byte {
-	return pbkdf2.Key(base, []byte(salt), 2e4, 128, sha256.New)
+// loadSources prepares the sources of settings beyond the process environment. They are loaded
+// from lowest to highest precedence, so that each may be configured by the ones before it.
+//
+// Any setting may refer to AWS Secrets Manager (awssm://secret-id#key) or SSM Parameter Store
+// (ssm://parameter-name). References are resolved as settings are read, here and by the
+// configurers, so that a missing secret or permission fails at startup rather than on first use.
+func loadSources() (err error) {
+	sources = nil
+	references = nil
+	resolver = nil
+	referenceErrors = nil
+
+	// a reference that fails while loading sources is reported instead of the missing setting
+	defer func() {
+		if len(referenceErrors) > 0 {
+			err = referenceErrors
+			referenceErrors = nil
+		}
+	}()
+
+	// CONFIG_FILE is a path to a YAML or TOML file with settings.
+	if path, ok := lookupEnv("CONFIG_FILE"); ok {
+		file, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("CONFIG_FILE: %v", err)
+		}
+		sources = append([]source{file}, sources...)
+	}
+
+	// VAULT_SECRET_PATH is the API path of a Vault secret with settings, like secret/data/authn.
+	// It requires VAULT_ADDR and VAULT_TOKEN, and accepts VAULT_NAMESPACE for Vault Enterprise.
+	if path, ok := lookupEnv("VAULT_SECRET_PATH"); ok {
+		addr, err := requireEnv("VAULT_ADDR")
+		if err != nil {
+			return err
+		}
+		token, err := requireEnv("VAULT_TOKEN")
+		if err != nil {
+			return err
+		}
+		namespace, _ := lookupEnv("VAULT_NAMESPACE")
+
+		vault, err := newVaultSource(addr, token, namespace, path)
+		if err != nil {
+			return fmt.Errorf("VAULT_SECRET_PATH: %v", err)
+		}
+		sources = append([]source{vault}, sources...)
+	}
+
+	// Secrets may be mounted as files, e.g. SECRET_KEY_BASE_FILE=/run/secrets/secret_key_base.
+	files, err := readSecretFiles()
+	if err != nil {
+		return err
+	}
+	sources = append([]source{files}, sources...)
+
+	return nil
 }
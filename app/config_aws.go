@@ -0,0 +1,97 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/pkg/errors"
+)
+
+const (
+	awsSecretsManagerScheme = "awssm://"
+	awsParameterStoreScheme = "ssm://"
+)
+
+// referenceResolver fetches the value of a setting that refers to an external secret store.
+type referenceResolver interface {
+	Resolve(ref string) (string, error)
+}
+
+var newReferenceResolver = func() (referenceResolver, error) {
+	return newAWSResolver()
+}
+
+func isAWSReference(val string) bool {
+	return strings.HasPrefix(val, awsSecretsManagerScheme) || strings.HasPrefix(val, awsParameterStoreScheme)
+}
+
+// awsResolver fetches the values of settings that refer to AWS Secrets Manager or SSM Parameter
+// Store. Credentials and region are discovered with the standard AWS SDK chain (environment,
+// shared config, ECS task role, EC2 instance profile).
+type awsResolver struct {
+	secrets *secretsmanager.SecretsManager
+	params  *ssm.SSM
+}
+
+func newAWSResolver(cfgs ...*aws.Config) (*awsResolver, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "NewSession")
+	}
+	return &awsResolver{
+		secrets: secretsmanager.New(sess, cfgs...),
+		params:  ssm.New(sess, cfgs...),
+	}, nil
+}
+
+// Resolve fetches the value of a reference:
+//
+// * awssm://secret-id returns the secret string
+// * awssm://secret-id#key returns one key from a secret string that holds a JSON object
+// * ssm://parameter-name returns the (decrypted) parameter value
+func (r *awsResolver) Resolve(ref string) (string, error) {
+	if strings.HasPrefix(ref, awsParameterStoreScheme) {
+		out, err := r.params.GetParameter(&ssm.GetParameterInput{
+			Name:           aws.String(strings.TrimPrefix(ref, awsParameterStoreScheme)),
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return "", errors.Wrap(err, "GetParameter")
+		}
+		return aws.StringValue(out.Parameter.Value), nil
+	}
+
+	id := strings.TrimPrefix(ref, awsSecretsManagerScheme)
+	key := ""
+	if idx := strings.LastIndex(id, "#"); idx != -1 {
+		id, key = id[:idx], id[idx+1:]
+	}
+
+	out, err := r.secrets.GetSecretValue(&secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "GetSecretValue")
+	}
+	secret := aws.StringValue(out.SecretString)
+	if key == "" {
+		return secret, nil
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", errors.Wrap(err, "Unmarshal")
+	}
+	val, ok := values[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %s", id, key)
+	}
+	return settingString(val)
+}
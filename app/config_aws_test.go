@@ -0,0 +1,130 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAWSResolver(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&input)
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		switch r.Header.Get("X-Amz-Target") {
+		case "secretsmanager.GetSecretValue":
+			switch input["SecretId"] {
+			case "prod/authn":
+				w.Write([]byte(`{"SecretString": "{\"SECRET_KEY_BASE\": \"from-json\", \"BCRYPT_COST\": 12}"}`))
+			case "prod/plain":
+				w.Write([]byte(`{"SecretString": "plain"}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type": "ResourceNotFoundException", "message": "not found"}`))
+			}
+		case "AmazonSSM.GetParameter":
+			assert.Equal(t, true, input["WithDecryption"])
+			w.Write([]byte(`{"Parameter": {"Name": "` + input["Name"].(string) + `", "Value": "from-ssm"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	r, err := newAWSResolver(&aws.Config{
+		Endpoint:    aws.String(server.URL),
+		Region:      aws.String("us-east-1"),
+		Credentials: credentials.NewStaticCredentials("id", "secret", ""),
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		ref string
+		val string
+	}{
+		{"awssm://prod/authn#SECRET_KEY_BASE", "from-json"},
+		{"awssm://prod/authn#BCRYPT_COST", "12"},
+		{"awssm://prod/plain", "plain"},
+		{"ssm:///authn/secret_key_base", "from-ssm"},
+	}
+	for _, tc := range testCases {
+		val, err := r.Resolve(tc.ref)
+		if assert.NoError(t, err, tc.ref) {
+			assert.Equal(t, tc.val, val, tc.ref)
+		}
+	}
+
+	_, err = r.Resolve("awssm://prod/authn#UNKNOWN")
+	assert.Error(t, err)
+	_, err = r.Resolve("awssm://prod/missing")
+	assert.Error(t, err)
+}
+
+type fakeResolver map[string]string
+
+func (r fakeResolver) Resolve(ref string) (string, error) {
+	if val, ok := r[ref]; ok {
+		return val, nil
+	}
+	return "", ErrMissingEnvVar(ref)
+}
+
+func TestLookupEnvWithReferences(t *testing.T) {
+	original := newReferenceResolver
+	defer func() { newReferenceResolver = original }()
+	resolved := []string{}
+	newReferenceResolver = func() (referenceResolver, error) {
+		return countingResolver{fakeResolver{"ssm://authn/secret": "resolved"}, &resolved}, nil
+	}
+
+	defer os.Unsetenv("TEST_REFERENCE")
+	defer os.Unsetenv("TEST_PLAIN")
+	defer os.Unsetenv("TEST_UNRELATED")
+
+	os.Setenv("TEST_REFERENCE", "ssm://authn/secret")
+	os.Setenv("TEST_PLAIN", "plain")
+	os.Setenv("TEST_UNRELATED", "ssm://other/missing")
+	require.NoError(t, loadSources())
+
+	val, ok := lookupEnv("TEST_REFERENCE")
+	assert.True(t, ok)
+	assert.Equal(t, "resolved", val)
+	val, _ = lookupEnv("TEST_REFERENCE")
+	assert.Equal(t, "resolved", val)
+	val, _ = lookupEnv("TEST_PLAIN")
+	assert.Equal(t, "plain", val)
+
+	// settings that are not read are not resolved
+	assert.Equal(t, []string{"ssm://authn/secret"}, resolved)
+
+	os.Setenv("TEST_REFERENCE", "ssm://authn/missing")
+	require.NoError(t, loadSources())
+	_, errs := configureAll([]configurer{
+		func(c *Config) error {
+			_, err := requireEnv("TEST_REFERENCE")
+			return err
+		},
+	})
+	if assert.Len(t, errs, 2) {
+		assert.Contains(t, errs[0].Error(), "TEST_REFERENCE: ")
+		assert.Equal(t, ErrMissingEnvVar("TEST_REFERENCE"), errs[1])
+	}
+}
+
+type countingResolver struct {
+	referenceResolver
+	resolved *[]string
+}
+
+func (r countingResolver) Resolve(ref string) (string, error) {
+	*r.resolved = append(*r.resolved, ref)
+	return r.referenceResolver.Resolve(ref)
+}
@@ -0,0 +1,142 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// configFile is a source of settings parsed from a YAML or TOML file. It uses the same names as
+// the environment variables, so that any setting may be moved into the file without translation:
+//
+//	AUTHN_URL: https://authn.example.com
+//	APP_DOMAINS:
+//	  - example.com
+//	  - www.example.com
+//	BCRYPT_COST: 12
+//
+// Lists are joined with commas to match the format of comma-delimited environment variables, and
+// nested objects (e.g. APP_DOMAIN_SETTINGS) are encoded as JSON.
+type configFile map[string]string
+
+// Lookup implements source
+func (f configFile) Lookup(name string) (string, bool) {
+	val, ok := f[name]
+	return val, ok
+}
+
+// Values implements source
+func (f configFile) Values() map[string]string {
+	return f
+}
+
+// readConfigFile parses the file at path according to its extension.
+func readConfigFile(path string) (configFile, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		if err := yaml.Unmarshal(contents, &raw); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(contents), &raw); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported file type: %s (expected .yml, .yaml, or .toml)", path)
+	}
+
+	file := configFile{}
+	for key, val := range raw {
+		str, err := settingString(val)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", key, err)
+		}
+		file[strings.ToUpper(key)] = str
+	}
+	return file, nil
+}
+
+// settingString converts a parsed value into the string format of an environment variable.
+func settingString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			str, err := settingString(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = str
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}, map[interface{}]interface{}:
+		// structured settings are passed along as JSON
+		obj, err := jsonValue(v)
+		if err != nil {
+			return "", err
+		}
+		str, err := json.Marshal(obj)
+		return string(str), err
+	default:
+		return "", fmt.Errorf("unsupported value: %v", val)
+	}
+}
+
+// jsonValue converts the nested maps produced by YAML into maps that may be encoded as JSON.
+func jsonValue(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			str, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("unsupported key: %v", key)
+			}
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			obj[str] = converted
+		}
+		return obj, nil
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			obj[key] = converted
+		}
+		return obj, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = converted
+		}
+		return list, nil
+	default:
+		return val, nil
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "authn-config")
+	require.NoError(t, err)
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestReadConfigFile(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		path := writeConfigFile(t, "authn.yml", `
+AUTHN_URL: https://authn.example.com
+app_domains:
+  - example.com
+  - www.example.com
+BCRYPT_COST: 12
+ENABLE_SIGNUP: false
+`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		file, err := readConfigFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, configFile{
+			"AUTHN_URL":     "https://authn.example.com",
+			"APP_DOMAINS":   "example.com,www.example.com",
+			"BCRYPT_COST":   "12",
+			"ENABLE_SIGNUP": "false",
+		}, file)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		path := writeConfigFile(t, "authn.toml", `
+AUTHN_URL = "https://authn.example.com"
+APP_DOMAINS = ["example.com", "www.example.com"]
+BCRYPT_COST = 12
+`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		file, err := readConfigFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, configFile{
+			"AUTHN_URL":   "https://authn.example.com",
+			"APP_DOMAINS": "example.com,www.example.com",
+			"BCRYPT_COST": "12",
+		}, file)
+	})
+
+	t.Run("nested values", func(t *testing.T) {
+		path := writeConfigFile(t, "authn.yml", `
+APP_DOMAIN_SETTINGS:
+  admin.example.com:
+    access_token_ttl: 300
+`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		file, err := readConfigFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, `{"admin.example.com":{"access_token_ttl":300}}`, file["APP_DOMAIN_SETTINGS"])
+	})
+
+	t.Run("unsupported keys", func(t *testing.T) {
+		path := writeConfigFile(t, "authn.yml", `
+APP_DOMAIN_SETTINGS:
+  1: 300
+`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := readConfigFile(path)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		path := writeConfigFile(t, "authn.ini", `AUTHN_URL=https://authn.example.com`)
+		defer os.RemoveAll(filepath.Dir(path))
+
+		_, err := readConfigFile(path)
+		assert.Error(t, err)
+	})
+}
+
+func TestLookupEnvWithConfigFile(t *testing.T) {
+	sources = []source{configFile{"TEST_SETTING": "from file", "TEST_OVERRIDE": "from file"}}
+	defer func() { sources = nil }()
+	os.Setenv("TEST_OVERRIDE", "from env")
+	defer os.Unsetenv("TEST_OVERRIDE")
+
+	val, ok := lookupEnv("TEST_SETTING")
+	assert.True(t, ok)
+	assert.Equal(t, "from file", val)
+
+	val, ok = lookupEnv("TEST_OVERRIDE")
+	assert.True(t, ok)
+	assert.Equal(t, "from env", val)
+
+	_, ok = lookupEnv("TEST_MISSING")
+	assert.False(t, ok)
+}
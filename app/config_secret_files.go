@@ -0,0 +1,79 @@
+package app
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// secretFileSettings may be read from a file by naming the path in a variable with the _FILE
+// suffix. Other settings are not included, since unrelated _FILE variables are common in the
+// environment (e.g. SSL_CERT_FILE).
+var secretFileSettings = []string{
+	"SECRET_KEY_BASE",
+	"IDENTITY_SIGNING_KEY",
+	"IDENTITY_SIGNING_KEY_PASSPHRASE",
+	"RSA_PRIVATE_KEY",
+	"HTTP_AUTH_PASSWORD",
+	"DATABASE_URL",
+	"REDIS_URL",
+	"GOOGLE_OAUTH_CREDENTIALS",
+	"GITHUB_OAUTH_CREDENTIALS",
+	"FACEBOOK_OAUTH_CREDENTIALS",
+	"DISCORD_OAUTH_CREDENTIALS",
+	"SENTRY_DSN",
+	"AIRBRAKE_CREDENTIALS",
+}
+
+// secretFiles is a source of settings read from files, as with Docker or Kubernetes secrets:
+//
+//	SECRET_KEY_BASE_FILE=/run/secrets/authn_secret_key_base
+//
+// Trailing line breaks are removed from the contents.
+type secretFiles struct {
+	paths  []string
+	values map[string]string
+}
+
+// Lookup implements source
+func (f *secretFiles) Lookup(name string) (string, bool) {
+	val, ok := f.values[name]
+	return val, ok
+}
+
+// Values implements source
+func (f *secretFiles) Values() map[string]string {
+	return f.values
+}
+
+// readSecretFiles reads every file named by a _FILE setting.
+func readSecretFiles() (*secretFiles, error) {
+	f := &secretFiles{values: map[string]string{}}
+	for _, name := range secretFileSettings {
+		path, ok := lookupEnv(name + "_FILE")
+		if !ok {
+			continue
+		}
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrap(err, name+"_FILE")
+		}
+		f.values[name] = strings.TrimRight(string(contents), "\r\n")
+		f.paths = append(f.paths, path)
+	}
+	sort.Strings(f.paths)
+	return f, nil
+}
+
+// SecretFiles returns the paths of files read by the last call to ReadEnv, so that they may be
+// watched for changes.
+func SecretFiles() []string {
+	for _, s := range sources {
+		if f, ok := s.(*secretFiles); ok {
+			return f.paths
+		}
+	}
+	return nil
+}
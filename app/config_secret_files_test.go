@@ -0,0 +1,51 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSecretFiles(t *testing.T) {
+	path := writeConfigFile(t, "secret_key_base", "file-secret\n")
+	defer os.RemoveAll(filepath.Dir(path))
+
+	defer os.Unsetenv("SECRET_KEY_BASE_FILE")
+	defer os.Unsetenv("SSL_CERT_FILE")
+	defer os.Unsetenv("REDIS_URL_FILE")
+	sources = nil
+
+	os.Setenv("SECRET_KEY_BASE_FILE", path)
+	os.Setenv("SSL_CERT_FILE", "/does/not/exist")
+	files, err := readSecretFiles()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"SECRET_KEY_BASE": "file-secret"}, files.Values())
+	assert.Equal(t, []string{path}, files.paths)
+
+	os.Setenv("REDIS_URL_FILE", "/does/not/exist")
+	_, err = readSecretFiles()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "REDIS_URL_FILE")
+	}
+}
+
+func TestLoadSourcesWithSecretFiles(t *testing.T) {
+	path := writeConfigFile(t, "secret_key_base", "file-secret")
+	defer os.RemoveAll(filepath.Dir(path))
+	defer os.Unsetenv("SECRET_KEY_BASE_FILE")
+	defer os.Unsetenv("SECRET_KEY_BASE")
+
+	os.Setenv("SECRET_KEY_BASE_FILE", path)
+	require.NoError(t, loadSources())
+	val, _ := lookupEnv("SECRET_KEY_BASE")
+	assert.Equal(t, "file-secret", val)
+	assert.Equal(t, []string{path}, SecretFiles())
+
+	os.Setenv("SECRET_KEY_BASE", "env-secret")
+	require.NoError(t, loadSources())
+	val, _ = lookupEnv("SECRET_KEY_BASE")
+	assert.Equal(t, "env-secret", val)
+}
@@ -0,0 +1,1136 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestSecureCookies(t *testing.T) {
+	assert.False(t, (&Config{}).SecureCookies())
+	assert.True(t, (&Config{ForceSSL: true}).SecureCookies())
+	assert.True(t, (&Config{SameSite: http.SameSiteNoneMode}).SecureCookies())
+	assert.False(t, (&Config{SameSite: http.SameSiteStrictMode}).SecureCookies())
+}
+
+func TestSettingsFor(t *testing.T) {
+	cfg := &Config{
+		AccessTokenTTL: time.Hour,
+		DomainSettings: []DomainSettings{
+			{Domain: route.ParseDomain("*.example.com"), CookieDomain: ".example.com"},
+			{Domain: route.ParseDomain("admin.example.com"), Audience: "admin", AccessTokenTTL: time.Minute},
+		},
+	}
+
+	testCases := []struct {
+		domain   string
+		expected DomainSettings
+	}{
+		{"admin.example.com", DomainSettings{Domain: route.ParseDomain("admin.example.com"), Audience: "admin", AccessTokenTTL: time.Minute}},
+		{"www.example.com", DomainSettings{Domain: route.ParseDomain("www.example.com"), Audience: "www.example.com", CookieDomain: ".example.com", AccessTokenTTL: time.Hour}},
+		{"other.com:8080", DomainSettings{Domain: route.ParseDomain("other.com:8080"), Audience: "other.com:8080", AccessTokenTTL: time.Hour}},
+	}
+	for _, tc := range testCases {
+		domain := route.ParseDomain(tc.domain)
+		assert.Equal(t, tc.expected, cfg.SettingsFor(&domain), tc.domain)
+	}
+
+	assert.Equal(t, DomainSettings{AccessTokenTTL: time.Hour}, cfg.SettingsFor(nil))
+
+	cfg.Audience = "myapp"
+	for domain, audience := range map[string]string{"admin.example.com": "admin", "www.example.com": "myapp", "other.com": "myapp"} {
+		d := route.ParseDomain(domain)
+		assert.Equal(t, audience, cfg.SettingsFor(&d).Audience, domain)
+	}
+}
+
+func TestCookieSettings(t *testing.T) {
+	cfg := &Config{
+		MountedPath:  "/authn",
+		CookieDomain: ".example.com",
+		DomainSettings: []DomainSettings{
+			{Domain: route.ParseDomain("other.com"), CookieDomain: ".other.com", CookiePath: "/"},
+		},
+	}
+
+	settings := cfg.SettingsFor(&route.Domain{Hostname: "www.example.com"})
+	assert.Equal(t, ".example.com", settings.CookieDomain)
+	assert.Equal(t, "/authn", settings.CookiePath)
+
+	settings = cfg.SettingsFor(&route.Domain{Hostname: "other.com"})
+	assert.Equal(t, ".other.com", settings.CookieDomain)
+	assert.Equal(t, "/", settings.CookiePath)
+
+	cfg.CookiePath = "/authn/session"
+	assert.Equal(t, "/authn/session", cfg.SettingsFor(nil).CookiePath)
+}
+
+func TestAppDomainSettings(t *testing.T) {
+	defer os.Unsetenv("APP_DOMAIN_SETTINGS")
+
+	os.Setenv("APP_DOMAIN_SETTINGS", `{"admin.example.com": {"audience": "admin", "access_token_ttl": 300}, "*.example.com": {"cookie_domain": ".example.com"}}`)
+	cfg, errs := configureAll(configurers)
+	require.NotNil(t, cfg)
+	for _, err := range errs {
+		assert.NotContains(t, err.Error(), "APP_DOMAIN_SETTINGS")
+	}
+	assert.Equal(t, []DomainSettings{
+		{Domain: route.ParseDomain("*.example.com"), CookieDomain: ".example.com"},
+		{Domain: route.ParseDomain("admin.example.com"), Audience: "admin", AccessTokenTTL: 5 * time.Minute},
+	}, cfg.DomainSettings)
+
+	os.Setenv("APP_DOMAIN_SETTINGS", `{"admin.example.com": {"access_token_ttl": 7200}}`)
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_DOMAIN_SETTINGS: admin.example.com: access_token_ttl may not exceed ACCESS_TOKEN_TTL")
+}
+
+func TestSessionCookieName(t *testing.T) {
+	defer os.Unsetenv("SESSION_COOKIE_NAME")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, "authn", cfg.SessionCookieName)
+
+	os.Setenv("SESSION_COOKIE_NAME", "authn-staging")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "authn-staging", cfg.SessionCookieName)
+}
+
+func TestDBPoolSettings(t *testing.T) {
+	defer os.Unsetenv("DB_MAX_OPEN_CONNS")
+	defer os.Unsetenv("DB_MAX_IDLE_CONNS")
+	defer os.Unsetenv("DB_CONN_MAX_LIFETIME")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 0, cfg.DBMaxOpenConns)
+	assert.Equal(t, 2, cfg.DBMaxIdleConns)
+	assert.Equal(t, time.Duration(0), cfg.DBConnMaxLifetime)
+
+	os.Setenv("DB_MAX_OPEN_CONNS", "20")
+	os.Setenv("DB_MAX_IDLE_CONNS", "5")
+	os.Setenv("DB_CONN_MAX_LIFETIME", "300")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 20, cfg.DBMaxOpenConns)
+	assert.Equal(t, 5, cfg.DBMaxIdleConns)
+	assert.Equal(t, 5*time.Minute, cfg.DBConnMaxLifetime)
+
+	os.Setenv("DB_MAX_IDLE_CONNS", "-1")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: DB_MAX_IDLE_CONNS")
+}
+
+func TestKeyRotationInterval(t *testing.T) {
+	defer os.Unsetenv("ACCESS_TOKEN_TTL")
+	defer os.Unsetenv("KEY_ROTATION_INTERVAL")
+
+	os.Setenv("ACCESS_TOKEN_TTL", "600")
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 10*time.Minute, cfg.KeyRotationInterval)
+
+	os.Setenv("KEY_ROTATION_INTERVAL", "604800")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 7*24*time.Hour, cfg.KeyRotationInterval)
+
+	os.Setenv("KEY_ROTATION_INTERVAL", "300")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: KEY_ROTATION_INTERVAL")
+}
+
+func TestTrustedProxies(t *testing.T) {
+	defer os.Unsetenv("TRUSTED_PROXIES")
+
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10,::1")
+	cfg, _ := configureAll(configurers)
+	assert.True(t, cfg.Proxied)
+	if assert.Len(t, cfg.TrustedProxies, 3) {
+		assert.Equal(t, "10.0.0.0/8", cfg.TrustedProxies[0].String())
+		assert.Equal(t, "192.168.1.10/32", cfg.TrustedProxies[1].String())
+		assert.Equal(t, "::1/128", cfg.TrustedProxies[2].String())
+	}
+
+	os.Setenv("TRUSTED_PROXIES", "10.0.0.0/33")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: TRUSTED_PROXIES")
+}
+
+func TestListenAddress(t *testing.T) {
+	defer os.Unsetenv("LISTEN")
+
+	os.Setenv("LISTEN", "127.0.0.1")
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, "127.0.0.1", cfg.ListenAddress)
+
+	os.Setenv("LISTEN", "[::1]")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "::1", cfg.ListenAddress)
+
+	os.Setenv("LISTEN", "127.0.0.1:8080")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LISTEN")
+}
+
+func TestAuthNURLs(t *testing.T) {
+	defer os.Unsetenv("AUTHN_URL")
+
+	os.Setenv("AUTHN_URL", "https://authn.example.com, http://authn.internal/auth")
+	cfg, errs := configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "AUTHN_URL")
+	require.Len(t, cfg.AuthNURLs, 2)
+	assert.Equal(t, "https://authn.example.com", cfg.AuthNURL.String())
+	assert.Equal(t, "/", cfg.MountedPath)
+	assert.True(t, cfg.ForceSSL)
+
+	internal := cfg.ForAuthNURL(cfg.AuthNURLs[1])
+	assert.Equal(t, "http://authn.internal/auth", internal.AuthNURL.String())
+	assert.Equal(t, "/auth", internal.MountedPath)
+	assert.False(t, internal.ForceSSL)
+	assert.Equal(t, "https://authn.example.com", cfg.AuthNURL.String())
+
+	os.Setenv("AUTHN_URL", "https://authn.example.com,http://authn.example.com/other")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: AUTHN_URL")
+
+	os.Setenv("AUTHN_URL", " , ")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "missing environment variable: AUTHN_URL")
+}
+
+func TestListenSocket(t *testing.T) {
+	defer os.Unsetenv("LISTEN")
+	defer os.Unsetenv("PUBLIC_PORT")
+
+	os.Setenv("LISTEN", "unix:///var/run/authn.sock")
+	cfg, errs := configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "LISTEN")
+	assert.Equal(t, "/var/run/authn.sock", cfg.ListenSocket)
+	assert.Equal(t, "", cfg.ListenAddress)
+
+	os.Setenv("PUBLIC_PORT", "8080")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: PUBLIC_PORT")
+
+	os.Unsetenv("PUBLIC_PORT")
+	os.Setenv("LISTEN", "unix://")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LISTEN")
+}
+
+func TestRedisURL(t *testing.T) {
+	defer os.Unsetenv("REDIS_URL")
+
+	os.Setenv("REDIS_URL", "redis+sentinel://sentinel1:26379,sentinel2:26379/mymaster/11")
+	cfg, errs := configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "REDIS_URL")
+	assert.Equal(t, "sentinel1:26379,sentinel2:26379", cfg.RedisURL.Host)
+
+	os.Setenv("REDIS_URL", "redis+sentinel://sentinel1:26379")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: REDIS_URL")
+
+	os.Setenv("REDIS_URL", "rediss://redis.example.com?ca_cert=/does/not/exist")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: REDIS_URL")
+}
+
+func TestIdentitySigningKeys(t *testing.T) {
+	defer os.Unsetenv("IDENTITY_SIGNING_KEY")
+
+	var pems []string
+	for i := 0; i < 2; i++ {
+		key, err := rsa.GenerateKey(rand.Reader, 512)
+		require.NoError(t, err)
+		pems = append(pems, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})))
+	}
+
+	os.Setenv("IDENTITY_SIGNING_KEY", strings.Join(pems, ""))
+	cfg, _ := configureAll(configurers)
+	require.NotNil(t, cfg.IdentitySigningKey)
+	require.Len(t, cfg.PreviousIdentitySigningKeys, 1)
+	assert.NotEqual(t, cfg.IdentitySigningKey.JWK.KeyID, cfg.PreviousIdentitySigningKeys[0].JWK.KeyID)
+
+	// single line format
+	os.Setenv("IDENTITY_SIGNING_KEY", strings.Replace(pems[1], "\n", `\n`, -1))
+	cfg, _ = configureAll(configurers)
+	require.NotNil(t, cfg.IdentitySigningKey)
+	assert.Empty(t, cfg.PreviousIdentitySigningKeys)
+
+	// encrypted
+	block, _ := pem.Decode([]byte(pems[1]))
+	encrypted, err := x509.EncryptPEMBlock(rand.Reader, block.Type, block.Bytes, []byte("hunter2"), x509.PEMCipherAES128)
+	require.NoError(t, err)
+	os.Setenv("IDENTITY_SIGNING_KEY", string(pem.EncodeToMemory(encrypted)))
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs, error(ErrMissingEnvVar("IDENTITY_SIGNING_KEY_PASSPHRASE")))
+
+	defer os.Unsetenv("IDENTITY_SIGNING_KEY_PASSPHRASE")
+	os.Setenv("IDENTITY_SIGNING_KEY_PASSPHRASE", "hunter2")
+	cfg, _ = configureAll(configurers)
+	require.NotNil(t, cfg.IdentitySigningKey)
+
+	os.Setenv("IDENTITY_SIGNING_KEY", "not a key")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: IDENTITY_SIGNING_KEY: no PEM data found")
+}
+
+func TestIdentitySigningKeyKMS(t *testing.T) {
+	original := newKMSKeys
+	defer func() { newKMSKeys = original }()
+	var requested []string
+	newKMSKeys = func(uris ...string) ([]*private.Key, error) {
+		requested = uris
+		var keys []*private.Key
+		for range uris {
+			key, err := private.GenerateKey(512)
+			require.NoError(t, err)
+			keys = append(keys, key)
+		}
+		return keys, nil
+	}
+	defer os.Unsetenv("IDENTITY_SIGNING_KEY_KMS")
+
+	os.Setenv("IDENTITY_SIGNING_KEY_KMS", "awskms://alias/authn-old, awskms://alias/authn")
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, []string{"awskms://alias/authn-old", "awskms://alias/authn"}, requested)
+	require.NotNil(t, cfg.IdentitySigningKey)
+	assert.Len(t, cfg.PreviousIdentitySigningKeys, 1)
+
+	os.Setenv("IDENTITY_SIGNING_KEY_KMS", "alias/authn")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: IDENTITY_SIGNING_KEY_KMS")
+
+	key, err := rsa.GenerateKey(rand.Reader, 512)
+	require.NoError(t, err)
+	defer os.Unsetenv("IDENTITY_SIGNING_KEY")
+	os.Setenv("IDENTITY_SIGNING_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})))
+	os.Setenv("IDENTITY_SIGNING_KEY_KMS", "awskms://alias/authn")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "may not be combined with IDENTITY_SIGNING_KEY")
+}
+
+func TestSigningAlgorithm(t *testing.T) {
+	defer os.Unsetenv("JWT_SIGNING_ALGORITHM")
+	defer os.Unsetenv("IDENTITY_SIGNING_KEY")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, jose.RS256, cfg.SigningAlgorithm)
+
+	os.Setenv("JWT_SIGNING_ALGORITHM", "es256")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, jose.ES256, cfg.SigningAlgorithm)
+
+	os.Setenv("JWT_SIGNING_ALGORITHM", "eddsa")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, jose.EdDSA, cfg.SigningAlgorithm)
+
+	os.Setenv("JWT_SIGNING_ALGORITHM", "HS256")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: JWT_SIGNING_ALGORITHM")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	os.Setenv("IDENTITY_SIGNING_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})))
+	os.Unsetenv("JWT_SIGNING_ALGORITHM")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, jose.ES256, cfg.SigningAlgorithm)
+
+	os.Setenv("JWT_SIGNING_ALGORITHM", "RS256")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "IDENTITY_SIGNING_KEY signs with ES256")
+}
+
+func TestIdentityClaims(t *testing.T) {
+	defer os.Unsetenv("IDENTITY_CLAIMS")
+
+	os.Setenv("IDENTITY_CLAIMS", `{"tenant": "acme", "roles": ["user"]}`)
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, map[string]interface{}{"tenant": "acme", "roles": []interface{}{"user"}}, cfg.IdentityClaims)
+
+	os.Setenv("IDENTITY_CLAIMS", `{"sub": "1"}`)
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "sub is a standard claim")
+
+	os.Setenv("IDENTITY_CLAIMS", `["user"]`)
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: IDENTITY_CLAIMS")
+}
+
+func TestPasswordMaxLength(t *testing.T) {
+	defer os.Unsetenv("PASSWORD_MAX_LENGTH")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 72, cfg.PasswordMaxLength)
+
+	os.Setenv("PASSWORD_MAX_LENGTH", "64")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 64, cfg.PasswordMaxLength)
+
+	os.Setenv("PASSWORD_MAX_LENGTH", "100")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "must be between 1 and 72")
+}
+
+func TestIdentityMetadataClaims(t *testing.T) {
+	defer os.Unsetenv("IDENTITY_METADATA_CLAIMS")
+
+	cfg, _ := configureAll(configurers)
+	assert.Empty(t, cfg.IdentityMetadataClaims)
+
+	os.Setenv("IDENTITY_METADATA_CLAIMS", "tenant_id, plan,")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, []string{"tenant_id", "plan"}, cfg.IdentityMetadataClaims)
+
+	os.Setenv("IDENTITY_METADATA_CLAIMS", "tenant_id,sub")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "sub is a standard claim")
+}
+
+func TestSessionMaxLifetime(t *testing.T) {
+	defer os.Unsetenv("SESSION_MAX_LIFETIME")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, time.Duration(0), cfg.SessionMaxLifetime)
+
+	os.Setenv("SESSION_MAX_LIFETIME", "2592000")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 30*24*time.Hour, cfg.SessionMaxLifetime)
+}
+
+func TestImpersonationTTL(t *testing.T) {
+	defer os.Unsetenv("IMPERSONATION_TTL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 15*time.Minute, cfg.ImpersonationTTL)
+
+	os.Setenv("IMPERSONATION_TTL", "300")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 5*time.Minute, cfg.ImpersonationTTL)
+
+	os.Setenv("IMPERSONATION_TTL", "0")
+	_, err := configureAll(configurers)
+	assert.Error(t, err)
+}
+
+func TestSessionBinding(t *testing.T) {
+	defer os.Unsetenv("SESSION_BINDING")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, "", cfg.SessionBinding)
+
+	os.Setenv("SESSION_BINDING", "STRICT")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "strict", cfg.SessionBinding)
+
+	os.Setenv("SESSION_BINDING", "paranoid")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: SESSION_BINDING")
+}
+
+func TestCSRFProtection(t *testing.T) {
+	defer os.Unsetenv("CSRF_PROTECTION")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, "", cfg.CSRFProtection)
+	assert.Equal(t, "authn-csrf", cfg.CSRFCookieName())
+
+	os.Setenv("CSRF_PROTECTION", "DOUBLE_SUBMIT")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "double_submit", cfg.CSRFProtection)
+
+	os.Setenv("CSRF_PROTECTION", "referer")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: CSRF_PROTECTION")
+}
+
+func TestMaxSessionsPerAccount(t *testing.T) {
+	defer os.Unsetenv("MAX_SESSIONS_PER_ACCOUNT")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 0, cfg.MaxSessionsPerAccount)
+
+	os.Setenv("MAX_SESSIONS_PER_ACCOUNT", "3")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 3, cfg.MaxSessionsPerAccount)
+}
+
+func TestRememberMe(t *testing.T) {
+	defer os.Unsetenv("EPHEMERAL_REFRESH_TOKEN_TTL")
+	defer os.Unsetenv("REMEMBER_ME_DEFAULT")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, time.Duration(0), cfg.EphemeralRefreshTokenTTL)
+	assert.False(t, cfg.RememberMeDefault)
+
+	os.Setenv("EPHEMERAL_REFRESH_TOKEN_TTL", "86400")
+	os.Setenv("REMEMBER_ME_DEFAULT", "true")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 24*time.Hour, cfg.EphemeralRefreshTokenTTL)
+	assert.True(t, cfg.RememberMeDefault)
+
+	os.Setenv("EPHEMERAL_REFRESH_TOKEN_TTL", "31536000")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: EPHEMERAL_REFRESH_TOKEN_TTL")
+}
+
+func TestIdentityEncryptionKey(t *testing.T) {
+	defer os.Unsetenv("IDENTITY_ENCRYPTION_KEY")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.IdentityEncryptionKey)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+	os.Setenv("IDENTITY_ENCRYPTION_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})))
+	cfg, _ = configureAll(configurers)
+	if assert.NotNil(t, cfg.IdentityEncryptionKey) {
+		assert.Equal(t, string(jose.ECDH_ES_A256KW), cfg.IdentityEncryptionKey.Algorithm)
+		assert.NotEmpty(t, cfg.IdentityEncryptionKey.KeyID)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	os.Setenv("IDENTITY_ENCRYPTION_KEY", string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})))
+	cfg, _ = configureAll(configurers)
+	if assert.NotNil(t, cfg.IdentityEncryptionKey) {
+		assert.Equal(t, string(jose.RSA_OAEP_256), cfg.IdentityEncryptionKey.Algorithm)
+	}
+
+	os.Setenv("IDENTITY_ENCRYPTION_KEY", "not a key")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: IDENTITY_ENCRYPTION_KEY")
+}
+
+func TestAppBackchannelLogoutURLs(t *testing.T) {
+	defer os.Unsetenv("APP_BACKCHANNEL_LOGOUT_URLS")
+
+	cfg, _ := configureAll(configurers)
+	assert.Empty(t, cfg.AppBackchannelLogoutURLs)
+
+	os.Setenv("APP_BACKCHANNEL_LOGOUT_URLS", "https://a.example.com/logout, https://b.example.com/logout")
+	cfg, _ = configureAll(configurers)
+	if assert.Len(t, cfg.AppBackchannelLogoutURLs, 2) {
+		assert.Equal(t, "https://a.example.com/logout", cfg.AppBackchannelLogoutURLs[0].String())
+		assert.Equal(t, "https://b.example.com/logout", cfg.AppBackchannelLogoutURLs[1].String())
+	}
+
+	os.Setenv("APP_BACKCHANNEL_LOGOUT_URLS", "https://a.example.com/%zz")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_BACKCHANNEL_LOGOUT_URLS")
+}
+
+func TestJWTLeeway(t *testing.T) {
+	defer os.Unsetenv("JWT_LEEWAY")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, time.Minute, cfg.JWTLeeway)
+
+	os.Setenv("JWT_LEEWAY", "5")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 5*time.Second, cfg.JWTLeeway)
+
+	os.Setenv("JWT_LEEWAY", "-1")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: JWT_LEEWAY")
+}
+
+func TestAccessTokenFormat(t *testing.T) {
+	defer os.Unsetenv("ACCESS_TOKEN_FORMAT")
+	defer os.Unsetenv("REDIS_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.False(t, cfg.OpaqueAccessTokens)
+
+	os.Setenv("ACCESS_TOKEN_FORMAT", "opaque")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: ACCESS_TOKEN_FORMAT")
+
+	os.Setenv("REDIS_URL", "redis://localhost:6379/11")
+	cfg, _ = configureAll(configurers)
+	assert.True(t, cfg.OpaqueAccessTokens)
+
+	os.Setenv("ACCESS_TOKEN_FORMAT", "unknown")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: ACCESS_TOKEN_FORMAT")
+}
+
+func TestEmailVerification(t *testing.T) {
+	defer os.Unsetenv("APP_EMAIL_VERIFICATION_URL")
+	defer os.Unsetenv("EMAIL_VERIFICATION_TOKEN_TTL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.AppEmailVerificationURL)
+	assert.Equal(t, 24*time.Hour, cfg.EmailVerificationTokenTTL)
+
+	os.Setenv("APP_EMAIL_VERIFICATION_URL", "https://app.example.com/verify")
+	os.Setenv("EMAIL_VERIFICATION_TOKEN_TTL", "600")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "https://app.example.com/verify", cfg.AppEmailVerificationURL.String())
+	assert.Equal(t, 10*time.Minute, cfg.EmailVerificationTokenTTL)
+}
+
+func TestInvitationTokenTTL(t *testing.T) {
+	defer os.Unsetenv("INVITATION_TOKEN_TTL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 7*24*time.Hour, cfg.InvitationTokenTTL)
+
+	os.Setenv("INVITATION_TOKEN_TTL", "3600")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, time.Hour, cfg.InvitationTokenTTL)
+}
+
+func TestAppEmailChangeURL(t *testing.T) {
+	defer os.Unsetenv("APP_EMAIL_CHANGE_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.AppEmailChangeURL)
+
+	os.Setenv("APP_EMAIL_CHANGE_URL", "https://app.example.com/email")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "https://app.example.com/email", cfg.AppEmailChangeURL.String())
+}
+
+func TestAppOTPDeliveryURL(t *testing.T) {
+	defer os.Unsetenv("APP_OTP_DELIVERY_URL")
+	defer os.Unsetenv("REDIS_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.AppOTPDeliveryURL)
+	assert.Equal(t, 5*time.Minute, cfg.OTPDeliveryTTL)
+
+	os.Setenv("APP_OTP_DELIVERY_URL", "https://app.example.com/sms")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_OTP_DELIVERY_URL")
+
+	os.Setenv("REDIS_URL", "redis://localhost:6379/11")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "https://app.example.com/sms", cfg.AppOTPDeliveryURL.String())
+}
+
+func TestAppleOauthCredentials(t *testing.T) {
+	defer os.Unsetenv("APPLE_OAUTH_CREDENTIALS")
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	os.Setenv("APPLE_OAUTH_CREDENTIALS", "com.example.app:"+hex.EncodeToString(der))
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APPLE_OAUTH_CREDENTIALS")
+
+	os.Setenv("APPLE_OAUTH_CREDENTIALS", "com.example.app:"+hex.EncodeToString(der)+":keyID=ABC123,teamID=TEAM42")
+	cfg, _ := configureAll(configurers)
+	require.NotNil(t, cfg.AppleOauthCredentials)
+	assert.Equal(t, "TEAM42", cfg.AppleOauthCredentials.Additional["teamID"])
+	assert.Equal(t, http.SameSiteNoneMode, cfg.NonceSameSite())
+	assert.Equal(t, http.SameSiteLaxMode, cfg.SameSiteComputed())
+}
+
+func TestOIDCProviders(t *testing.T) {
+	defer os.Unsetenv("OIDC_PROVIDERS")
+
+	os.Setenv("OIDC_PROVIDERS", `{"acme": {"issuer": "https://idp.acme.com", "credentials": "id:secret", "scopes": ["openid", "email", "profile"]}}`)
+	cfg, _ := configureAll(configurers)
+	require.Len(t, cfg.OIDCProviders, 1)
+	assert.Equal(t, "acme", cfg.OIDCProviders[0].Name)
+	assert.Equal(t, "https://idp.acme.com", cfg.OIDCProviders[0].Issuer)
+	assert.Equal(t, "secret", cfg.OIDCProviders[0].Credentials.Secret)
+	assert.Equal(t, []string{"openid", "email", "profile"}, cfg.OIDCProviders[0].Scopes)
+	assert.True(t, cfg.OAuthEnabled())
+
+	for _, val := range []string{
+		`{"google": {"issuer": "https://accounts.google.com", "credentials": "id:secret"}}`,
+		`{"Acme Inc": {"issuer": "https://idp.acme.com", "credentials": "id:secret"}}`,
+		`{"acme": {"issuer": "idp.acme.com", "credentials": "id:secret"}}`,
+		`{"acme": {"issuer": "https://idp.acme.com", "credentials": "id"}}`,
+	} {
+		os.Setenv("OIDC_PROVIDERS", val)
+		_, errs := configureAll(configurers)
+		assert.Contains(t, errs.Error(), "invalid environment variable: OIDC_PROVIDERS", val)
+	}
+}
+
+func TestSAMLProviders(t *testing.T) {
+	defer os.Unsetenv("SAML_PROVIDERS")
+	defer os.Unsetenv("OIDC_PROVIDERS")
+
+	os.Setenv("SAML_PROVIDERS", `{"acme": {"metadata_url": "https://idp.acme.com/metadata", "username_attribute": "email"}}`)
+	cfg, _ := configureAll(configurers)
+	require.Len(t, cfg.SAMLProviders, 1)
+	assert.Equal(t, "acme", cfg.SAMLProviders[0].Name)
+	assert.Equal(t, "https://idp.acme.com/metadata", cfg.SAMLProviders[0].MetadataURL)
+	assert.Equal(t, "email", cfg.SAMLProviders[0].UsernameAttribute)
+	assert.Equal(t, http.SameSiteNoneMode, cfg.NonceSameSite())
+	assert.Equal(t, http.SameSiteLaxMode, cfg.SameSiteComputed())
+
+	for _, val := range []string{
+		`{"apple": {"metadata_url": "https://idp.acme.com/metadata"}}`,
+		`{"acme": {"metadata_url": "/metadata"}}`,
+	} {
+		os.Setenv("SAML_PROVIDERS", val)
+		_, errs := configureAll(configurers)
+		assert.Contains(t, errs.Error(), "invalid environment variable: SAML_PROVIDERS", val)
+	}
+
+	os.Setenv("OIDC_PROVIDERS", `{"acme": {"issuer": "https://idp.acme.com", "credentials": "id:secret"}}`)
+	os.Setenv("SAML_PROVIDERS", `{"acme": {"metadata_url": "https://idp.acme.com/metadata"}}`)
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: SAML_PROVIDERS")
+}
+
+func TestLDAP(t *testing.T) {
+	defer os.Unsetenv("LDAP_URL")
+	defer os.Unsetenv("LDAP_BIND_TEMPLATE")
+	defer os.Unsetenv("LDAP_BASE_DN")
+	defer os.Unsetenv("LDAP_START_TLS")
+
+	os.Setenv("LDAP_URL", "ldap://ldap.example.com")
+	cfg, _ := configureAll(configurers)
+	require.NotNil(t, cfg.LDAPURL)
+	assert.Equal(t, "ldap.example.com", cfg.LDAPURL.Host)
+	assert.Equal(t, "uid=%s", cfg.LDAPBindTemplate)
+	assert.Equal(t, "", cfg.LDAPBaseDN)
+	assert.False(t, cfg.LDAPStartTLS)
+
+	os.Setenv("LDAP_BIND_TEMPLATE", "cn=%s")
+	os.Setenv("LDAP_BASE_DN", "ou=people,dc=example,dc=com")
+	os.Setenv("LDAP_START_TLS", "true")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, "cn=%s", cfg.LDAPBindTemplate)
+	assert.Equal(t, "ou=people,dc=example,dc=com", cfg.LDAPBaseDN)
+	assert.True(t, cfg.LDAPStartTLS)
+
+	os.Setenv("LDAP_URL", "ldaps://ldap.example.com")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LDAP_START_TLS")
+
+	os.Unsetenv("LDAP_START_TLS")
+	os.Setenv("LDAP_BIND_TEMPLATE", "cn=admin")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LDAP_BIND_TEMPLATE")
+
+	os.Unsetenv("LDAP_BIND_TEMPLATE")
+	os.Setenv("LDAP_URL", "https://ldap.example.com")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LDAP_URL")
+}
+
+func TestLoginFailureThresholds(t *testing.T) {
+	defer os.Unsetenv("LOGIN_LOCKOUT_THRESHOLD")
+	defer os.Unsetenv("LOGIN_IP_THRESHOLD")
+	defer os.Unsetenv("LOGIN_FAILURE_WINDOW")
+	defer os.Unsetenv("LOGIN_LOCKOUT_DELAY")
+	defer os.Unsetenv("REDIS_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 0, cfg.LoginLockoutThreshold)
+	assert.Equal(t, 0, cfg.LoginIPThreshold)
+	assert.Equal(t, 15*time.Minute, cfg.LoginFailureWindow)
+	assert.True(t, cfg.LoginLockoutDelay)
+
+	os.Setenv("LOGIN_LOCKOUT_THRESHOLD", "10")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LOGIN_LOCKOUT_THRESHOLD")
+
+	os.Setenv("REDIS_URL", "redis://127.0.0.1:6379/11")
+	os.Setenv("LOGIN_IP_THRESHOLD", "100")
+	os.Setenv("LOGIN_FAILURE_WINDOW", "3600")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 10, cfg.LoginLockoutThreshold)
+	assert.Equal(t, 100, cfg.LoginIPThreshold)
+	assert.Equal(t, time.Hour, cfg.LoginFailureWindow)
+	assert.True(t, cfg.LoginLockoutDelay)
+
+	os.Setenv("LOGIN_LOCKOUT_DELAY", "false")
+	cfg, _ = configureAll(configurers)
+	assert.False(t, cfg.LoginLockoutDelay)
+
+	os.Setenv("LOGIN_FAILURE_WINDOW", "0")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: LOGIN_FAILURE_WINDOW")
+}
+
+func TestAppMagicLinkURL(t *testing.T) {
+	defer os.Unsetenv("APP_MAGIC_LINK_URL")
+	defer os.Unsetenv("APP_PASSWORDLESS_TOKEN_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.AppPasswordlessTokenURL)
+
+	os.Setenv("APP_MAGIC_LINK_URL", "https://app.example.com/magic")
+	cfg, _ = configureAll(configurers)
+	require.NotNil(t, cfg.AppPasswordlessTokenURL)
+	assert.Equal(t, "https://app.example.com/magic", cfg.AppPasswordlessTokenURL.String())
+
+	os.Setenv("APP_PASSWORDLESS_TOKEN_URL", "https://app.example.com/passwordless")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_MAGIC_LINK_URL")
+
+	os.Setenv("APP_MAGIC_LINK_URL", "://example.com")
+	os.Unsetenv("APP_PASSWORDLESS_TOKEN_URL")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_MAGIC_LINK_URL")
+}
+
+func TestKeyDerivation(t *testing.T) {
+	defer os.Unsetenv("KEY_DERIVATION")
+	defer os.Unsetenv("SECRET_KEY_BASE")
+	os.Setenv("SECRET_KEY_BASE", "secret")
+
+	cfg, _ := configureAll(configurers)
+	original := cfg
+
+	os.Setenv("KEY_DERIVATION", "hkdf")
+	cfg, _ = configureAll(configurers)
+	assert.NotEqual(t, original.SessionSigningKey, cfg.SessionSigningKey)
+	assert.Equal(t, original.DBEncryptionKey, cfg.DBEncryptionKey, "keeps data encrypted at rest readable")
+
+	os.Setenv("KEY_DERIVATION", "scrypt")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: KEY_DERIVATION")
+}
+
+func TestIdempotencyKeyTTL(t *testing.T) {
+	defer os.Unsetenv("IDEMPOTENCY_KEY_TTL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 24*time.Hour, cfg.IdempotencyKeyTTL)
+
+	os.Setenv("IDEMPOTENCY_KEY_TTL", "600")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 10*time.Minute, cfg.IdempotencyKeyTTL)
+
+	os.Setenv("IDEMPOTENCY_KEY_TTL", "0")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: IDEMPOTENCY_KEY_TTL")
+}
+
+func TestRateLimits(t *testing.T) {
+	defer os.Unsetenv("RATE_LIMIT_IP")
+	defer os.Unsetenv("RATE_LIMIT_ACCOUNT")
+	defer os.Unsetenv("RATE_LIMIT_WINDOW")
+	defer os.Unsetenv("REDIS_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 0, cfg.RateLimitIP)
+	assert.Equal(t, 0, cfg.RateLimitAccount)
+	assert.Equal(t, time.Minute, cfg.RateLimitWindow)
+
+	os.Setenv("RATE_LIMIT_IP", "30")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: RATE_LIMIT_IP")
+
+	os.Setenv("REDIS_URL", "redis://127.0.0.1:6379/11")
+	os.Setenv("RATE_LIMIT_ACCOUNT", "5")
+	os.Setenv("RATE_LIMIT_WINDOW", "300")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 30, cfg.RateLimitIP)
+	assert.Equal(t, 5, cfg.RateLimitAccount)
+	assert.Equal(t, 5*time.Minute, cfg.RateLimitWindow)
+
+	os.Setenv("RATE_LIMIT_WINDOW", "0")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: RATE_LIMIT_WINDOW")
+}
+
+func TestAppEvents(t *testing.T) {
+	defer os.Unsetenv("APP_EVENTS_URLS")
+	defer os.Unsetenv("APP_EVENTS_SIGNING_KEY")
+	defer os.Unsetenv("REDIS_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Empty(t, cfg.AppEventsURLs)
+
+	os.Setenv("APP_EVENTS_URLS", "https://a.example.com/events, https://b.example.com/events")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: APP_EVENTS_URLS")
+
+	os.Setenv("REDIS_URL", "redis://127.0.0.1:6379/11")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "missing environment variable: APP_EVENTS_SIGNING_KEY")
+
+	os.Setenv("APP_EVENTS_SIGNING_KEY", "shh")
+	cfg, _ = configureAll(configurers)
+	require.Len(t, cfg.AppEventsURLs, 2)
+	assert.Equal(t, "https://b.example.com/events", cfg.AppEventsURLs[1].String())
+	assert.Equal(t, []byte("shh"), cfg.AppEventsSigningKey)
+}
+
+func TestArchivedAccountRetention(t *testing.T) {
+	defer os.Unsetenv("ARCHIVED_ACCOUNT_RETENTION")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, time.Duration(0), cfg.ArchivedAccountRetention)
+
+	os.Setenv("ARCHIVED_ACCOUNT_RETENTION", "30")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 30*24*time.Hour, cfg.ArchivedAccountRetention)
+
+	os.Setenv("ARCHIVED_ACCOUNT_RETENTION", "-1")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: ARCHIVED_ACCOUNT_RETENTION")
+}
+
+func TestAuditLogRetention(t *testing.T) {
+	defer os.Unsetenv("AUDIT_LOG_RETENTION")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, time.Duration(0), cfg.AuditLogRetention)
+
+	os.Setenv("AUDIT_LOG_RETENTION", "365")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 365*24*time.Hour, cfg.AuditLogRetention)
+
+	os.Setenv("AUDIT_LOG_RETENTION", "-1")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: AUDIT_LOG_RETENTION")
+}
+
+func TestMaintenanceMode(t *testing.T) {
+	defer os.Unsetenv("MAINTENANCE_MODE")
+
+	cfg, _ := configureAll(configurers)
+	assert.False(t, cfg.MaintenanceMode)
+
+	os.Setenv("MAINTENANCE_MODE", "true")
+	cfg, _ = configureAll(configurers)
+	assert.True(t, cfg.MaintenanceMode)
+}
+
+func TestTracingEnabled(t *testing.T) {
+	defer os.Unsetenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	defer os.Unsetenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	defer os.Unsetenv("OTEL_SDK_DISABLED")
+	defer os.Unsetenv("OTEL_TRACES_EXPORTER")
+
+	cfg, _ := configureAll(configurers)
+	assert.False(t, cfg.TracingEnabled)
+
+	os.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+	cfg, _ = configureAll(configurers)
+	assert.True(t, cfg.TracingEnabled)
+
+	os.Unsetenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	os.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+	cfg, _ = configureAll(configurers)
+	assert.True(t, cfg.TracingEnabled)
+
+	os.Setenv("OTEL_TRACES_EXPORTER", "none")
+	cfg, _ = configureAll(configurers)
+	assert.False(t, cfg.TracingEnabled)
+
+	os.Unsetenv("OTEL_TRACES_EXPORTER")
+	os.Setenv("OTEL_SDK_DISABLED", "TRUE")
+	cfg, _ = configureAll(configurers)
+	assert.False(t, cfg.TracingEnabled)
+}
+
+func TestLogging(t *testing.T) {
+	defer os.Unsetenv("LOG_LEVEL")
+	defer os.Unsetenv("LOG_FORMAT")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, logrus.InfoLevel, cfg.LogLevel)
+	assert.Equal(t, "json", cfg.LogFormat)
+
+	os.Setenv("LOG_LEVEL", "debug")
+	os.Setenv("LOG_FORMAT", "TEXT")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, logrus.DebugLevel, cfg.LogLevel)
+	assert.Equal(t, "text", cfg.LogFormat)
+
+	os.Setenv("LOG_LEVEL", "loud")
+	_, err := configureAll(configurers)
+	assert.Contains(t, err.Error(), "invalid environment variable: LOG_LEVEL")
+
+	os.Unsetenv("LOG_LEVEL")
+	os.Setenv("LOG_FORMAT", "xml")
+	_, err = configureAll(configurers)
+	assert.Contains(t, err.Error(), "invalid environment variable: LOG_FORMAT")
+}
+
+// generateCertificate creates a self-signed certificate and key, in PEM
+func generateCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+	return certPEM, keyPEM
+}
+
+func TestHTTPTimeouts(t *testing.T) {
+	defer os.Unsetenv("HTTP_READ_TIMEOUT")
+	defer os.Unsetenv("HTTP_WRITE_TIMEOUT")
+	defer os.Unsetenv("HTTP_IDLE_TIMEOUT")
+	defer os.Unsetenv("HTTP_MAX_HEADER_BYTES")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 30*time.Second, cfg.HTTPReadTimeout)
+	assert.Equal(t, time.Minute, cfg.HTTPWriteTimeout)
+	assert.Equal(t, 2*time.Minute, cfg.HTTPIdleTimeout)
+	assert.Equal(t, 65536, cfg.HTTPMaxHeaderBytes)
+
+	os.Setenv("HTTP_READ_TIMEOUT", "5")
+	os.Setenv("HTTP_WRITE_TIMEOUT", "0")
+	os.Setenv("HTTP_IDLE_TIMEOUT", "10")
+	os.Setenv("HTTP_MAX_HEADER_BYTES", "8192")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, 5*time.Second, cfg.HTTPReadTimeout)
+	assert.Equal(t, time.Duration(0), cfg.HTTPWriteTimeout)
+	assert.Equal(t, 10*time.Second, cfg.HTTPIdleTimeout)
+	assert.Equal(t, 8192, cfg.HTTPMaxHeaderBytes)
+
+	os.Setenv("HTTP_IDLE_TIMEOUT", "-1")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: HTTP_IDLE_TIMEOUT")
+}
+
+func TestTLS(t *testing.T) {
+	defer os.Unsetenv("TLS_CERT")
+	defer os.Unsetenv("TLS_KEY")
+	defer os.Unsetenv("TLS_AUTOCERT")
+	defer os.Unsetenv("TLS_AUTOCERT_EMAIL")
+	defer os.Unsetenv("AUTHN_URL")
+
+	cfg, _ := configureAll(configurers)
+	assert.Nil(t, cfg.TLSCertificate)
+	assert.Equal(t, "", cfg.TLSAutocertDir)
+
+	certPEM, keyPEM := generateCertificate(t)
+	os.Setenv("TLS_CERT", certPEM)
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "missing environment variable: TLS_KEY")
+
+	os.Setenv("TLS_KEY", "not a key")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: TLS_CERT")
+
+	os.Setenv("TLS_KEY", keyPEM)
+	cfg, errs = configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "TLS")
+	assert.NotNil(t, cfg.TLSCertificate)
+
+	os.Setenv("TLS_AUTOCERT", "/var/lib/authn/certs")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: TLS_AUTOCERT")
+
+	os.Unsetenv("TLS_CERT")
+	os.Unsetenv("TLS_KEY")
+	os.Setenv("AUTHN_URL", "http://authn.example.com")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: TLS_AUTOCERT")
+
+	os.Setenv("AUTHN_URL", "https://authn.example.com")
+	os.Setenv("TLS_AUTOCERT_EMAIL", "ops@example.com")
+	cfg, errs = configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "TLS")
+	assert.Equal(t, "/var/lib/authn/certs", cfg.TLSAutocertDir)
+	assert.Equal(t, "ops@example.com", cfg.TLSAutocertEmail)
+}
+
+func TestGRPC(t *testing.T) {
+	defer os.Unsetenv("GRPC_PORT")
+	defer os.Unsetenv("GRPC_TLS_CERT")
+	defer os.Unsetenv("GRPC_TLS_KEY")
+	defer os.Unsetenv("GRPC_CLIENT_CA")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, 0, cfg.GRPCPort)
+	assert.Nil(t, cfg.GRPCCertificate)
+
+	os.Setenv("GRPC_PORT", "9443")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "missing environment variable: GRPC_TLS_CERT")
+
+	certPEM, keyPEM := generateCertificate(t)
+
+	os.Setenv("GRPC_TLS_CERT", certPEM)
+	os.Setenv("GRPC_TLS_KEY", "not a key")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: GRPC_TLS_CERT")
+
+	os.Setenv("GRPC_TLS_KEY", keyPEM)
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "missing environment variable: GRPC_CLIENT_CA")
+
+	os.Setenv("GRPC_CLIENT_CA", "not a certificate")
+	_, errs = configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: GRPC_CLIENT_CA")
+
+	os.Setenv("GRPC_CLIENT_CA", certPEM)
+	cfg, errs = configureAll(configurers)
+	assert.NotContains(t, errs.Error(), "GRPC")
+	assert.Equal(t, 9443, cfg.GRPCPort)
+	assert.NotNil(t, cfg.GRPCCertificate)
+	assert.NotNil(t, cfg.GRPCClientCAs)
+}
+
+func TestCORS(t *testing.T) {
+	defer os.Unsetenv("CORS_ALLOWED_HEADERS")
+	defer os.Unsetenv("CORS_ALLOWED_METHODS")
+	defer os.Unsetenv("CORS_ALLOW_CREDENTIALS")
+
+	cfg, _ := configureAll(configurers)
+	assert.Equal(t, []string{"Content-Type"}, cfg.CORSAllowedHeaders)
+	assert.Equal(t, []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, cfg.CORSAllowedMethods)
+	assert.True(t, cfg.CORSAllowCredentials)
+
+	os.Setenv("CORS_ALLOWED_HEADERS", "content-type, authorization,")
+	os.Setenv("CORS_ALLOWED_METHODS", "get,post")
+	os.Setenv("CORS_ALLOW_CREDENTIALS", "false")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, []string{"Content-Type", "Authorization"}, cfg.CORSAllowedHeaders)
+	assert.Equal(t, []string{"GET", "POST"}, cfg.CORSAllowedMethods)
+	assert.False(t, cfg.CORSAllowCredentials)
+
+	os.Setenv("CORS_ALLOWED_METHODS", "GET,TRACE")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: CORS_ALLOWED_METHODS")
+}
+
+func TestFrameAncestors(t *testing.T) {
+	defer os.Unsetenv("FRAME_ANCESTORS")
+
+	cfg, _ := configureAll(configurers)
+	assert.Empty(t, cfg.FrameAncestors)
+
+	os.Setenv("FRAME_ANCESTORS", "'self', https://app.example.com")
+	cfg, _ = configureAll(configurers)
+	assert.Equal(t, []string{"'self'", "https://app.example.com"}, cfg.FrameAncestors)
+
+	os.Setenv("FRAME_ANCESTORS", "'self'; script-src *")
+	_, errs := configureAll(configurers)
+	assert.Contains(t, errs.Error(), "invalid environment variable: FRAME_ANCESTORS")
+}
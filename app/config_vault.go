@@ -0,0 +1,154 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// vaultRetryMin and vaultRetryMax bound the backoff between attempts to fetch an expired secret
+// again while Vault can't be reached.
+const (
+	vaultRetryMin = time.Second
+	vaultRetryMax = 5 * time.Minute
+)
+
+// vaultSource is a source of settings read from a HashiCorp Vault secret. The secret's keys use the
+// same names as environment variables (e.g. SECRET_KEY_BASE, RSA_PRIVATE_KEY, HTTP_AUTH_PASSWORD).
+//
+// Both KV version 1 and version 2 secret engines are supported. When Vault reports a lease on the
+// secret, the values are fetched again the next time configuration is read after the lease expires.
+// If that fails, the previous values are kept, the error is reported, and the fetch is retried with
+// a backoff so that lookups don't wait on an unreachable Vault.
+type vaultSource struct {
+	addr      string
+	token     string
+	namespace string
+	path      string
+	client    *http.Client
+	report    func(error)
+
+	mutex     sync.Mutex
+	values    map[string]string
+	expiresAt time.Time
+	retry     time.Duration
+}
+
+func newVaultSource(addr string, token string, namespace string, path string) (*vaultSource, error) {
+	s := &vaultSource{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		path:      strings.Trim(path, "/"),
+		client:    &http.Client{Timeout: 10 * time.Second},
+		report: func(err error) {
+			logrus.WithError(err).Error("refreshing VAULT_SECRET_PATH failed")
+		},
+	}
+	if err := s.fetch(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Lookup implements source
+func (s *vaultSource) Lookup(name string) (string, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.expiresAt.IsZero() && time.Now().After(s.expiresAt) {
+		if err := s.fetchLocked(); err != nil {
+			// the previous values remain the best available until Vault can be reached
+			if s.retry == 0 {
+				s.retry = vaultRetryMin
+			} else if s.retry < vaultRetryMax {
+				s.retry *= 2
+				if s.retry > vaultRetryMax {
+					s.retry = vaultRetryMax
+				}
+			}
+			s.expiresAt = time.Now().Add(s.retry)
+			s.report(err)
+		}
+	}
+	val, ok := s.values[name]
+	return val, ok
+}
+
+// Values implements source
+func (s *vaultSource) Values() map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	values := make(map[string]string, len(s.values))
+	for name, val := range s.values {
+		values[name] = val
+	}
+	return values
+}
+
+func (s *vaultSource) fetch() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.fetchLocked()
+}
+
+func (s *vaultSource) fetchLocked() error {
+	req, err := http.NewRequest("GET", s.addr+"/v1/"+s.path, nil)
+	if err != nil {
+		return errors.Wrap(err, "NewRequest")
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "Do")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault responded with %d for %s", res.StatusCode, s.path)
+	}
+
+	var secret struct {
+		LeaseDuration int                    `json:"lease_duration"`
+		Data          map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&secret); err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+
+	// KV version 2 nests the secret with its metadata
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nested
+		}
+	}
+
+	values := map[string]string{}
+	for key, val := range data {
+		str, err := settingString(val)
+		if err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+		values[strings.ToUpper(key)] = str
+	}
+
+	s.values = values
+	s.retry = 0
+	if secret.LeaseDuration > 0 {
+		s.expiresAt = time.Now().Add(time.Duration(secret.LeaseDuration) * time.Second)
+	} else {
+		s.expiresAt = time.Time{}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultSource(t *testing.T) {
+	var response string
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/authn" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	defer vault.Close()
+
+	t.Run("kv version 2", func(t *testing.T) {
+		response = `{"lease_duration": 0, "data": {"data": {"SECRET_KEY_BASE": "kv2", "BCRYPT_COST": 12}, "metadata": {"version": 3}}}`
+		s, err := newVaultSource(vault.URL, "s.token", "", "/secret/data/authn")
+		require.NoError(t, err)
+
+		val, ok := s.Lookup("SECRET_KEY_BASE")
+		assert.True(t, ok)
+		assert.Equal(t, "kv2", val)
+		val, ok = s.Lookup("BCRYPT_COST")
+		assert.True(t, ok)
+		assert.Equal(t, "12", val)
+		_, ok = s.Lookup("HTTP_AUTH_PASSWORD")
+		assert.False(t, ok)
+	})
+
+	t.Run("kv version 1", func(t *testing.T) {
+		response = `{"lease_duration": 2764800, "data": {"http_auth_password": "kv1"}}`
+		s, err := newVaultSource(vault.URL, "s.token", "", "secret/data/authn")
+		require.NoError(t, err)
+
+		val, ok := s.Lookup("HTTP_AUTH_PASSWORD")
+		assert.True(t, ok)
+		assert.Equal(t, "kv1", val)
+	})
+
+	t.Run("expired lease", func(t *testing.T) {
+		response = `{"lease_duration": 60, "data": {"SECRET_KEY_BASE": "first"}}`
+		s, err := newVaultSource(vault.URL, "s.token", "", "secret/data/authn")
+		require.NoError(t, err)
+
+		response = `{"lease_duration": 60, "data": {"SECRET_KEY_BASE": "second"}}`
+		val, _ := s.Lookup("SECRET_KEY_BASE")
+		assert.Equal(t, "first", val)
+
+		s.expiresAt = time.Now().Add(-time.Second)
+		val, _ = s.Lookup("SECRET_KEY_BASE")
+		assert.Equal(t, "second", val)
+	})
+
+	t.Run("unreachable after lease expires", func(t *testing.T) {
+		response = `{"lease_duration": 60, "data": {"SECRET_KEY_BASE": "cached"}}`
+		s, err := newVaultSource(vault.URL, "s.token", "", "secret/data/authn")
+		require.NoError(t, err)
+		reported := []error{}
+		s.report = func(err error) { reported = append(reported, err) }
+
+		s.path = "secret/data/missing"
+		s.expiresAt = time.Now().Add(-time.Second)
+		val, _ := s.Lookup("SECRET_KEY_BASE")
+		assert.Equal(t, "cached", val)
+		assert.Len(t, reported, 1)
+		assert.True(t, s.expiresAt.After(time.Now()), "retries after a backoff")
+
+		val, _ = s.Lookup("SECRET_KEY_BASE")
+		assert.Equal(t, "cached", val)
+		assert.Len(t, reported, 1, "does not retry before the backoff")
+
+		s.expiresAt = time.Now().Add(-time.Second)
+		s.Lookup("SECRET_KEY_BASE")
+		assert.Len(t, reported, 2)
+		assert.Equal(t, 2*vaultRetryMin, s.retry)
+
+		s.path = "secret/data/authn"
+		s.expiresAt = time.Now().Add(-time.Second)
+		s.Lookup("SECRET_KEY_BASE")
+		assert.Len(t, reported, 2)
+		assert.Equal(t, time.Duration(0), s.retry)
+	})
+
+	t.Run("bad token", func(t *testing.T) {
+		_, err := newVaultSource(vault.URL, "wrong", "", "secret/data/authn")
+		assert.Error(t, err)
+	})
+}
@@ -1,22 +1,51 @@
 package app
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 type configurer func(c *Config) error
 
+// ConfigErrors is every problem found while reading configuration, so that they may be fixed
+// together instead of one boot at a time.
+type ConfigErrors []error
+
+func (errs ConfigErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
 func configure(fns []configurer) (*Config, error) {
-	var err error
+	c, errs := configureAll(fns)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return c, nil
+}
+
+// configureAll runs every configurer, collecting errors instead of stopping at the first.
+func configureAll(fns []configurer) (*Config, ConfigErrors) {
 	c := Config{
 		UsernameMinLength: 3,
+		UsernameMaxLength: 255,
 		SessionCookieName: "authn",
 		OAuthCookieName:   "authn-oauth-nonce",
 		SameSite:          http.SameSiteDefaultMode,
 	}
+	var errs ConfigErrors
 	for _, fn := range fns {
-		err = fn(&c)
-		if err != nil {
-			return nil, err
+		if err := fn(&c); err != nil {
+			errs = append(errs, err)
 		}
 	}
-	return &c, nil
+	// a setting whose reference could not be resolved reads as missing, so its cause comes first
+	if len(referenceErrors) > 0 {
+		errs = append(referenceErrors, errs...)
+		referenceErrors = nil
+	}
+	return &c, errs
 }
@@ -0,0 +1,31 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		cfg, err := configure([]configurer{
+			func(c *Config) error { c.BcryptCost = 4; return nil },
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, cfg.BcryptCost)
+		assert.Equal(t, "authn", cfg.SessionCookieName)
+	})
+
+	t.Run("multiple errors", func(t *testing.T) {
+		_, err := configure([]configurer{
+			func(c *Config) error { return ErrMissingEnvVar("AUTHN_URL") },
+			func(c *Config) error { return nil },
+			func(c *Config) error { return errors.New("bad value") },
+		})
+		if assert.IsType(t, ConfigErrors{}, err) {
+			assert.Len(t, err, 2)
+			assert.Equal(t, "missing environment variable: AUTHN_URL\nbad value", err.Error())
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package data
+
+import "time"
+
+// AccessTokenStore keeps the identity tokens that are referenced by opaque access tokens, so that
+// access may be checked by introspection and revoked instantly.
+type AccessTokenStore interface {
+	// Create generates an opaque token that references the identity token until the expiry.
+	Create(identityToken string, expiry time.Time) (string, error)
+	// Find returns the identity token for an opaque token, if it is known and unexpired. An empty
+	// value indicates that no active token was found.
+	Find(token string) (string, error)
+	// Revoke forgets the opaque token. Doesn't error if the token is unknown or already revoked.
+	Revoke(token string) error
+}
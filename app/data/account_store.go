@@ -2,6 +2,9 @@ package data
 
 import (
 	"fmt"
+	"math/rand"
+	"sync"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/postgres"
 
@@ -9,25 +12,80 @@ import (
 	"github.com/keratin/authn-server/app/data/mysql"
 	"github.com/keratin/authn-server/app/data/sqlite3"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
 )
 
 type AccountStore interface {
 	Create(u string, p []byte) (*models.Account, error)
 	Find(id int) (*models.Account, error)
 	FindByUsername(u string) (*models.Account, error)
+	// List returns unarchived accounts with usernames that contain the given string, ordered by ID.
+	List(u string, limit int, offset int) ([]*models.Account, error)
+	// Count returns the number of accounts that List would find without a limit.
+	Count(u string) (int, error)
 	FindByOauthAccount(p string, pid string) (*models.Account, error)
 	AddOauthAccount(id int, p string, pid string, tok string) error
 	GetOauthAccounts(id int) ([]*models.OauthAccount, error)
+	// AddAlias allows an account to also log in with another username. Aliases are unique among
+	// themselves, but not against primary usernames, which callers must check.
+	AddAlias(id int, u string) error
+	GetAliases(id int) ([]*models.AccountAlias, error)
+	DeleteAlias(id int, u string) (bool, error)
+	// PromoteAlias swaps an alias with the account's username in one step. Each username keeps its
+	// own verification, so the account is verified when the promoted alias was.
+	PromoteAlias(id int, u string) (bool, error)
 	Archive(id int) (bool, error)
+	PurgeArchived(before time.Time) (int, error)
 	Lock(id int) (bool, error)
 	Unlock(id int) (bool, error)
 	RequireNewPassword(id int) (bool, error)
 	SetPassword(id int, p []byte) (bool, error)
+	// RehashPassword replaces the hash of the current password, without counting as a change.
+	RehashPassword(id int, p []byte) (bool, error)
 	UpdateUsername(id int, u string) (bool, error)
+	// UpdateVerifiedUsername replaces the username with a confirmed email in one step. The email
+	// is no longer an alias, and the account is verified.
+	UpdateVerifiedUsername(id int, u string) (bool, error)
 	SetLastLogin(id int) (bool, error)
+	SetRoles(id int, roles []string) (bool, error)
+	SetMetadata(id int, metadata map[string]interface{}) (bool, error)
+	SetVerified(id int) (bool, error)
+	SetTOTPSecret(id int, secret []byte) (bool, error)
+	EnableTOTP(id int) (bool, error)
+	DeleteTOTP(id int) (bool, error)
+	SetOTPDelivery(id int, enabled bool) (bool, error)
+}
+
+// AccountStoreFactory builds an AccountStore for a database connection.
+type AccountStoreFactory func(db sqlx.Ext) (AccountStore, error)
+
+var (
+	accountStoresMu sync.RWMutex
+	accountStores   = map[string]AccountStoreFactory{}
+)
+
+// RegisterAccountStore makes NewAccountStore use the factory for connections with the given driver
+// name (e.g. "postgres"), in place of the built-in store. This allows an embedding application to
+// supply its own AccountStore, such as one backed by an existing users table. Register stores from
+// an init function, before calling app.NewApp.
+func RegisterAccountStore(driver string, factory AccountStoreFactory) {
+	accountStoresMu.Lock()
+	defer accountStoresMu.Unlock()
+	if factory == nil {
+		panic("data: RegisterAccountStore factory is nil")
+	}
+	accountStores[driver] = factory
 }
 
 func NewAccountStore(db sqlx.Ext) (AccountStore, error) {
+	accountStoresMu.RLock()
+	factory, ok := accountStores[db.DriverName()]
+	accountStoresMu.RUnlock()
+	if ok {
+		return factory(db)
+	}
+
 	switch db.DriverName() {
 	case "sqlite3":
 		return &sqlite3.AccountStore{Ext: db}, nil
@@ -39,3 +97,16 @@ func NewAccountStore(db sqlx.Ext) (AccountStore, error) {
 		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
 	}
 }
+
+// CleanArchivedAccounts periodically deletes accounts that were archived more than the retention
+// ago. Until then, an archived account remains as a tombstone without a username or password.
+func CleanArchivedAccounts(store AccountStore, retention time.Duration, reporter ops.ErrorReporter) {
+	go func() {
+		for range time.Tick(time.Hour + time.Duration(rand.Intn(300))*time.Second) {
+			_, err := store.PurgeArchived(time.Now().Add(-retention))
+			if err != nil {
+				reporter.ReportError(errors.Wrap(err, "CleanArchivedAccounts"))
+			}
+		}
+	}()
+}
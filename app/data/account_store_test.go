@@ -0,0 +1,31 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterAccountStore(t *testing.T) {
+	sqlite, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	db := sqlx.NewDb(sqlite.DB, "embedded-accounts")
+
+	_, err = data.NewAccountStore(db)
+	assert.Error(t, err, "unsupported driver")
+
+	store := mock.NewAccountStore()
+	data.RegisterAccountStore("embedded-accounts", func(ext sqlx.Ext) (data.AccountStore, error) {
+		assert.Equal(t, db, ext)
+		return store, nil
+	})
+
+	found, err := data.NewAccountStore(db)
+	require.NoError(t, err)
+	assert.Equal(t, store, found)
+}
@@ -1,8 +1,21 @@
 package data
 
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
 type Actives interface {
 	Track(int) error
 	ActivesByDay() (map[string]int, error)
 	ActivesByWeek() (map[string]int, error)
 	ActivesByMonth() (map[string]int, error)
+
+	// TrackSignup counts a new account
+	TrackSignup(int) error
+	// ActivesBetween and SignupsBetween count accounts in every period from the one containing
+	// `from` through the one containing `to`, including periods with none.
+	ActivesBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error)
+	SignupsBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error)
 }
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+type AuditLog interface {
+	Record(entry *models.AuditEntry) error
+	// List returns entries that match the filter, newest first.
+	List(filter models.AuditFilter, limit int, offset int) ([]*models.AuditEntry, error)
+	// Count returns the number of entries that List would find without a limit.
+	Count(filter models.AuditFilter) (int, error)
+	Purge(before time.Time) (int, error)
+}
+
+func NewAuditLog(db sqlx.Ext) (AuditLog, error) {
+	switch db.DriverName() {
+	case "sqlite3":
+		return &sqlite3.AuditLog{Ext: db}, nil
+	case "mysql":
+		return &mysql.AuditLog{Ext: db}, nil
+	case "postgres":
+		return &postgres.AuditLog{Ext: db}, nil
+	default:
+		return nil, fmt.Errorf("unsupported driver: %v", db.DriverName())
+	}
+}
+
+// CleanAuditLog periodically deletes entries that were recorded more than the retention ago.
+func CleanAuditLog(log AuditLog, retention time.Duration, reporter ops.ErrorReporter) {
+	go func() {
+		for range time.Tick(time.Hour + time.Duration(rand.Intn(300))*time.Second) {
+			_, err := log.Purge(time.Now().Add(-retention))
+			if err != nil {
+				reporter.ReportError(errors.Wrap(err, "CleanAuditLog"))
+			}
+		}
+	}()
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/mysql"
 	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/schema"
 	"github.com/keratin/authn-server/app/data/sqlite3"
 	sq3 "github.com/mattn/go-sqlite3"
 )
@@ -28,39 +29,47 @@ func NewDB(url *url.URL) (*sqlx.DB, error) {
 	}
 }
 
+// MigrateDB applies any pending migrations to the configured database.
 func MigrateDB(url *url.URL) error {
+	db, err := NewDB(url)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
 	switch url.Scheme {
 	case "sqlite3":
-		db, err := sqlite3.NewDB(url.Path)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-
-		sqlite3.MigrateDB(db)
-		return nil
+		return sqlite3.MigrateDB(db)
 	case "mysql":
-		db, err := mysql.NewDB(url)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-
-		mysql.MigrateDB(db)
-		return nil
+		return mysql.MigrateDB(db)
 	case "postgresql", "postgres":
-		db, err := postgres.NewDB(url)
-		if err != nil {
-			return err
-		}
-		defer db.Close()
-		postgres.MigrateDB(db)
-		return nil
+		return postgres.MigrateDB(db)
 	default:
 		return fmt.Errorf("Unsupported database")
 	}
 }
 
+// MigrationStatus reports which migrations have been applied to the configured database and which
+// are pending.
+func MigrationStatus(url *url.URL) ([]schema.Status, error) {
+	db, err := NewDB(url)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	switch url.Scheme {
+	case "sqlite3":
+		return sqlite3.MigrationStatus(db)
+	case "mysql":
+		return mysql.MigrationStatus(db)
+	case "postgresql", "postgres":
+		return postgres.MigrationStatus(db)
+	default:
+		return nil, fmt.Errorf("Unsupported database")
+	}
+}
+
 func IsUniquenessError(err error) bool {
 	switch i := err.(type) {
 	case sq3.Error:
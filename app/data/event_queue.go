@@ -0,0 +1,18 @@
+package data
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+// EventQueue holds event deliveries until they have succeeded. A claimed delivery is hidden until
+// its lease expires, so that it will be claimed again if it is not acknowledged in time.
+type EventQueue interface {
+	// Schedule adds or replaces a delivery, to be claimed at the given time.
+	Schedule(delivery *models.EventDelivery, at time.Time) error
+	// Claim returns a delivery that is due, if any, and hides it for the lease.
+	Claim(lease time.Duration) (*models.EventDelivery, error)
+	// Ack removes a delivery.
+	Ack(id string) error
+}
@@ -0,0 +1,24 @@
+package data
+
+import "time"
+
+// FailedLogins counts failed logins by username and by client IP, within a window.
+type FailedLogins interface {
+	// Add counts a failed login, and returns the failures for the username and for the IP within
+	// the window.
+	Add(username string, ip string) (int, int, error)
+	// CountIP returns the failures for the IP within the window, and how long until the window
+	// forgets them.
+	CountIP(ip string) (int, time.Duration, error)
+	// CountPair returns the failures for the username from the IP, and when the last one happened.
+	// They are counted until the window passes without a failure for the username.
+	CountPair(username string, ip string) (int, time.Time, error)
+	// Reset forgets the failures for the username, as after a successful login or an unlock.
+	Reset(username string) error
+	// Lock refuses logins to the account until the ttl passes.
+	Lock(accountID int, ttl time.Duration) error
+	// LockedFor returns how long the account remains locked, or zero.
+	LockedFor(accountID int) (time.Duration, error)
+	// Unlock forgets the account's lock.
+	Unlock(accountID int) error
+}
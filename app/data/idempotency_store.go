@@ -0,0 +1,18 @@
+package data
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+// IdempotencyStore keeps responses for replay to retries with the same Idempotency-Key.
+type IdempotencyStore interface {
+	// Claim reserves the key for a request in progress, unless a response was saved for it. It
+	// returns false when the key is already reserved by a request in progress.
+	Claim(key string) (*models.IdempotentResponse, bool, error)
+	// Save keeps the response for the key until the ttl passes.
+	Save(key string, res *models.IdempotentResponse, ttl time.Duration) error
+	// Release forgets a reservation without a response, so that the request may be retried.
+	Release(key string) error
+}
@@ -1,6 +1,9 @@
 package data
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
@@ -11,17 +14,22 @@ import (
 	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ed25519"
+	"gopkg.in/square/go-jose.v2"
 )
 
 // NewKeyStoreRotater creates a KeyStoreRotater.
 //
-// The rotation interval should match the lifetime of an access token. This means a key can be used
-// to sign tokens for one time period, remain available to verify tokens for another time period,
-// and be discarded during the third.
-func NewKeyStoreRotater(blobStore *EncryptedBlobStore, interval time.Duration, logger logrus.FieldLogger) *KeyStoreRotater {
+// The rotation interval should be at least the lifetime of an access token. This means a key can be
+// used to sign tokens for one time period, remain available to verify tokens for another time
+// period, and be discarded during the third.
+//
+// Keys are generated for the given algorithm: RS256, ES256, or EdDSA.
+func NewKeyStoreRotater(blobStore *EncryptedBlobStore, interval time.Duration, alg jose.SignatureAlgorithm, logger logrus.FieldLogger) *KeyStoreRotater {
 	return &KeyStoreRotater{
 		store:       blobStore,
 		interval:    interval,
+		algorithm:   alg,
 		keyStrength: 2048,
 		logger:      logger.WithField("scope", "NewKeyStoreRotater"),
 	}
@@ -31,6 +39,7 @@ func NewKeyStoreRotater(blobStore *EncryptedBlobStore, interval time.Duration, l
 // persisted into an EncryptedBlobStore, shared with other processes, and read back on startup.
 type KeyStoreRotater struct {
 	interval    time.Duration
+	algorithm   jose.SignatureAlgorithm
 	keyStrength int
 	store       *EncryptedBlobStore
 	logger      logrus.FieldLogger
@@ -113,8 +122,17 @@ func (m *KeyStoreRotater) restore() ([]*private.Key, error) {
 // generate will create a new key and store it as an encrypted blob. It relies on a write lock to
 // coordinate with other AuthN servers.
 func (m *KeyStoreRotater) generate() (*private.Key, error) {
-	keyName := fmt.Sprintf("rsa:%d", m.currentBucket())
-	key, err := private.GenerateKey(m.keyStrength)
+	keyName := m.keyName(m.currentBucket())
+	var key *private.Key
+	var err error
+	switch m.algorithm {
+	case jose.ES256:
+		key, err = private.GenerateECDSAKey(elliptic.P256())
+	case jose.EdDSA:
+		key, err = private.GenerateEd25519Key()
+	default:
+		key, err = private.GenerateKey(m.keyStrength)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -142,7 +160,7 @@ func (m *KeyStoreRotater) generate() (*private.Key, error) {
 
 // find will retrieve and deserialize/decrypt from the blob store
 func (m *KeyStoreRotater) find(bucket int64) (*private.Key, error) {
-	blob, err := m.store.Read(fmt.Sprintf("rsa:%d", bucket))
+	blob, err := m.store.Read(m.keyName(bucket))
 	if err != nil {
 		return nil, errors.Wrap(err, "Get")
 	}
@@ -153,14 +171,41 @@ func (m *KeyStoreRotater) find(bucket int64) (*private.Key, error) {
 	return bytesToKey(blob), nil
 }
 
+// keyName is distinct for each algorithm, so that servers switching algorithms do not restore keys
+// of the previous algorithm. RS256 keys keep their original name.
+func (m *KeyStoreRotater) keyName(bucket int64) string {
+	switch m.algorithm {
+	case jose.ES256:
+		return fmt.Sprintf("es256:%d", bucket)
+	case jose.EdDSA:
+		return fmt.Sprintf("ed25519:%d", bucket)
+	}
+	return fmt.Sprintf("rsa:%d", bucket)
+}
+
 func (m *KeyStoreRotater) currentBucket() int64 {
 	return time.Now().Unix() / int64(m.interval/time.Second)
 }
 
+// keyToBytes serializes a generated key. The rotater only generates RSA, ECDSA, and Ed25519 keys.
 func keyToBytes(key *private.Key) []byte {
+	switch k := key.PrivateKey.(type) {
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(k)
+		if err != nil {
+			panic(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	case ed25519.PrivateKey:
+		der, err := x509.MarshalPKCS8PrivateKey(k)
+		if err != nil {
+			panic(err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
 	return pem.EncodeToMemory(&pem.Block{
 		Type:  "RSA PRIVATE KEY",
-		Bytes: x509.MarshalPKCS1PrivateKey(key.PrivateKey),
+		Bytes: x509.MarshalPKCS1PrivateKey(key.PrivateKey.(*rsa.PrivateKey)),
 	})
 }
 
@@ -169,7 +214,6 @@ func bytesToKey(b []byte) *private.Key {
 	if block == nil {
 		return nil
 	}
-	key, _ := x509.ParsePKCS1PrivateKey(block.Bytes)
-	privateKey, _ := private.NewKey(key)
+	privateKey, _ := private.ParsePEM(block)
 	return privateKey
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
 )
 
 func TestKeyStoreRotater(t *testing.T) {
@@ -22,7 +23,7 @@ func TestKeyStoreRotater(t *testing.T) {
 	t.Run("empty remote storage", func(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 		store := data.NewRotatingKeyStore()
-		rotater := data.NewKeyStoreRotater(blobStore, interval, logger)
+		rotater := data.NewKeyStoreRotater(blobStore, interval, jose.RS256, logger)
 		err := rotater.Maintain(store, reporter)
 		require.NoError(t, err)
 
@@ -35,13 +36,13 @@ func TestKeyStoreRotater(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 
 		store1 := data.NewRotatingKeyStore()
-		err := data.NewKeyStoreRotater(blobStore, interval, logger).Maintain(store1, reporter)
+		err := data.NewKeyStoreRotater(blobStore, interval, jose.RS256, logger).Maintain(store1, reporter)
 		require.NoError(t, err)
 		key1 := store1.Key()
 		assert.NotEmpty(t, key1)
 
 		store2 := data.NewRotatingKeyStore()
-		err = data.NewKeyStoreRotater(blobStore, interval, logger).Maintain(store2, reporter)
+		err = data.NewKeyStoreRotater(blobStore, interval, jose.RS256, logger).Maintain(store2, reporter)
 		require.NoError(t, err)
 		assert.Len(t, store2.Keys(), 1)
 		assert.Equal(t, key1, store2.Key())
@@ -51,7 +52,7 @@ func TestKeyStoreRotater(t *testing.T) {
 	t.Run("rotation", func(t *testing.T) {
 		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
 		store := data.NewRotatingKeyStore()
-		rotater := data.NewKeyStoreRotater(blobStore, interval, logger)
+		rotater := data.NewKeyStoreRotater(blobStore, interval, jose.RS256, logger)
 		err := rotater.Maintain(store, reporter)
 		require.NoError(t, err)
 
@@ -67,4 +68,40 @@ func TestKeyStoreRotater(t *testing.T) {
 		store.Rotate(thirdKey)
 		assert.Equal(t, []*private.Key{secondKey, thirdKey}, store.Keys())
 	})
+
+	t.Run("ES256", func(t *testing.T) {
+		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
+
+		store1 := data.NewRotatingKeyStore()
+		err := data.NewKeyStoreRotater(blobStore, interval, jose.ES256, logger).Maintain(store1, reporter)
+		require.NoError(t, err)
+		assert.Equal(t, jose.ES256, store1.Key().Algorithm())
+
+		// restored by another server
+		store2 := data.NewRotatingKeyStore()
+		err = data.NewKeyStoreRotater(blobStore, interval, jose.ES256, logger).Maintain(store2, reporter)
+		require.NoError(t, err)
+		assert.Equal(t, store1.Key().JWK.KeyID, store2.Key().JWK.KeyID)
+
+		// switching algorithms generates a new key
+		store3 := data.NewRotatingKeyStore()
+		err = data.NewKeyStoreRotater(blobStore, interval, jose.RS256, logger).Maintain(store3, reporter)
+		require.NoError(t, err)
+		assert.Equal(t, jose.RS256, store3.Key().Algorithm())
+	})
+
+	t.Run("EdDSA", func(t *testing.T) {
+		blobStore := data.NewEncryptedBlobStore(mock.NewBlobStore(interval*2+time.Second, time.Second), secret)
+
+		store1 := data.NewRotatingKeyStore()
+		err := data.NewKeyStoreRotater(blobStore, interval, jose.EdDSA, logger).Maintain(store1, reporter)
+		require.NoError(t, err)
+		assert.Equal(t, jose.EdDSA, store1.Key().Algorithm())
+
+		// restored by another server
+		store2 := data.NewRotatingKeyStore()
+		err = data.NewKeyStoreRotater(blobStore, interval, jose.EdDSA, logger).Maintain(store2, reporter)
+		require.NoError(t, err)
+		assert.Equal(t, store1.Key().JWK.KeyID, store2.Key().JWK.KeyID)
+	})
 }
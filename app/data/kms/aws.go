@@ -0,0 +1,84 @@
+package kms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/pkg/errors"
+)
+
+// awsSigner signs with an AWS KMS asymmetric key. Credentials and region are found with the
+// standard AWS SDK chain.
+type awsSigner struct {
+	client kmsiface.KMSAPI
+	keyID  string
+	public crypto.PublicKey
+}
+
+func newAWSClient() (kmsiface.KMSAPI, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "session")
+	}
+	return kms.New(sess), nil
+}
+
+func newAWSSigner(client kmsiface.KMSAPI, keyID string) (*awsSigner, error) {
+	out, err := client.GetPublicKey(&kms.GetPublicKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return nil, errors.Wrap(err, "GetPublicKey")
+	}
+	if aws.StringValue(out.KeyUsage) != kms.KeyUsageTypeSignVerify {
+		return nil, fmt.Errorf("key usage is %s (expected %s)", aws.StringValue(out.KeyUsage), kms.KeyUsageTypeSignVerify)
+	}
+	public, err := x509.ParsePKIXPublicKey(out.PublicKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParsePKIXPublicKey")
+	}
+	return &awsSigner{client: client, keyID: keyID, public: public}, nil
+}
+
+// Public implements crypto.Signer
+func (s *awsSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer
+func (s *awsSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkHash(s.public, opts.HashFunc()); err != nil {
+		return nil, err
+	}
+
+	var alg string
+	switch s.public.(type) {
+	case *rsa.PublicKey:
+		alg = kms.SigningAlgorithmSpecRsassaPkcs1V15Sha256
+	case *ecdsa.PublicKey:
+		alg = map[crypto.Hash]string{
+			crypto.SHA256: kms.SigningAlgorithmSpecEcdsaSha256,
+			crypto.SHA384: kms.SigningAlgorithmSpecEcdsaSha384,
+			crypto.SHA512: kms.SigningAlgorithmSpecEcdsaSha512,
+		}[opts.HashFunc()]
+	}
+
+	out, err := s.client.Sign(&kms.SignInput{
+		KeyId:            aws.String(s.keyID),
+		Message:          digest,
+		MessageType:      aws.String(kms.MessageTypeDigest),
+		SigningAlgorithm: aws.String(alg),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Sign")
+	}
+	return out.Signature, nil
+}
@@ -0,0 +1,118 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+)
+
+const gcpEndpoint = "https://cloudkms.googleapis.com/v1/"
+
+// gcpSigner signs with a Google Cloud KMS asymmetric key version through the REST API. Credentials
+// are found with Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS or the metadata
+// server).
+type gcpSigner struct {
+	client   *http.Client
+	endpoint string
+	name     string
+	public   crypto.PublicKey
+}
+
+func newGCPClient() (*http.Client, error) {
+	client, err := google.DefaultClient(context.Background(), "https://www.googleapis.com/auth/cloudkms")
+	if err != nil {
+		return nil, errors.Wrap(err, "google.DefaultClient")
+	}
+	return client, nil
+}
+
+func newGCPSigner(client *http.Client, endpoint string, name string) (*gcpSigner, error) {
+	s := &gcpSigner{client: client, endpoint: endpoint, name: name}
+
+	var key struct {
+		Pem       string `json:"pem"`
+		Algorithm string `json:"algorithm"`
+	}
+	if err := s.call("GET", name+"/publicKey", nil, &key); err != nil {
+		return nil, errors.Wrap(err, "publicKey")
+	}
+	// PSS keys would produce signatures that RS256 verifiers reject
+	if !strings.HasPrefix(key.Algorithm, "RSA_SIGN_PKCS1_") && !strings.HasPrefix(key.Algorithm, "EC_SIGN_") {
+		return nil, fmt.Errorf("unsupported algorithm: %s", key.Algorithm)
+	}
+	block, _ := pem.Decode([]byte(key.Pem))
+	if block == nil {
+		return nil, fmt.Errorf("publicKey: no PEM block found")
+	}
+	public, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParsePKIXPublicKey")
+	}
+	s.public = public
+	return s, nil
+}
+
+// Public implements crypto.Signer
+func (s *gcpSigner) Public() crypto.PublicKey {
+	return s.public
+}
+
+// Sign implements crypto.Signer
+func (s *gcpSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if err := checkHash(s.public, opts.HashFunc()); err != nil {
+		return nil, err
+	}
+	field := map[crypto.Hash]string{
+		crypto.SHA256: "sha256",
+		crypto.SHA384: "sha384",
+		crypto.SHA512: "sha512",
+	}[opts.HashFunc()]
+
+	req := map[string]interface{}{
+		"digest": map[string]string{field: base64.StdEncoding.EncodeToString(digest)},
+	}
+	var res struct {
+		Signature string `json:"signature"`
+	}
+	if err := s.call("POST", s.name+":asymmetricSign", req, &res); err != nil {
+		return nil, errors.Wrap(err, "asymmetricSign")
+	}
+	return base64.StdEncoding.DecodeString(res.Signature)
+}
+
+func (s *gcpSigner) call(method string, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequest(method, s.endpoint+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %s", res.Status)
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
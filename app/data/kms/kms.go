@@ -0,0 +1,88 @@
+// Package kms signs identity tokens with keys that are held by a key management service and never
+// leave it. Keys are named by URI:
+//
+//	awskms://alias/authn
+//	awskms://arn:aws:kms:us-east-1:111122223333:key/1234abcd-12ab-34cd-56ef-1234567890ab
+//	gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1
+package kms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/pkg/errors"
+)
+
+const (
+	awsScheme = "awskms://"
+	gcpScheme = "gcpkms://"
+)
+
+// IsKeyURI reports whether a string names a KMS key.
+func IsKeyURI(uri string) bool {
+	return strings.HasPrefix(uri, awsScheme) || strings.HasPrefix(uri, gcpScheme)
+}
+
+// NewKeys connects to the KMS keys named by uris, for a KeyStore sorted with the newest key last.
+func NewKeys(uris ...string) ([]*private.Key, error) {
+	keys := make([]*private.Key, 0, len(uris))
+	for _, uri := range uris {
+		signer, err := NewSigner(uri)
+		if err != nil {
+			return nil, errors.Wrap(err, uri)
+		}
+		key, err := private.NewKey(signer)
+		if err != nil {
+			return nil, errors.Wrap(err, uri)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// NewSigner connects to the KMS key named by uri and fetches its public key.
+func NewSigner(uri string) (crypto.Signer, error) {
+	switch {
+	case strings.HasPrefix(uri, awsScheme):
+		client, err := newAWSClient()
+		if err != nil {
+			return nil, err
+		}
+		return newAWSSigner(client, strings.TrimPrefix(uri, awsScheme))
+	case strings.HasPrefix(uri, gcpScheme):
+		client, err := newGCPClient()
+		if err != nil {
+			return nil, err
+		}
+		return newGCPSigner(client, gcpEndpoint, strings.TrimPrefix(uri, gcpScheme))
+	}
+	return nil, fmt.Errorf("unsupported KMS key: %s", uri)
+}
+
+// checkHash ensures that a signature uses the hash that identity tokens are signed with, since a
+// KMS key will only sign with the hash it was created for.
+func checkHash(public crypto.PublicKey, hash crypto.Hash) error {
+	var expected crypto.Hash
+	switch k := public.(type) {
+	case *rsa.PublicKey:
+		expected = crypto.SHA256
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			expected = crypto.SHA256
+		case elliptic.P384():
+			expected = crypto.SHA384
+		case elliptic.P521():
+			expected = crypto.SHA512
+		}
+	}
+	if hash != expected {
+		return fmt.Errorf("unsupported hash for %T: %v", public, hash)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package kms
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// fakeAWS signs with a local key in place of AWS KMS.
+type fakeAWS struct {
+	kmsiface.KMSAPI
+	key crypto.Signer
+}
+
+func (f *fakeAWS) GetPublicKey(in *kms.GetPublicKeyInput) (*kms.GetPublicKeyOutput, error) {
+	der, err := x509.MarshalPKIXPublicKey(f.key.Public())
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GetPublicKeyOutput{
+		KeyId:     in.KeyId,
+		KeyUsage:  aws.String(kms.KeyUsageTypeSignVerify),
+		PublicKey: der,
+	}, nil
+}
+
+func (f *fakeAWS) Sign(in *kms.SignInput) (*kms.SignOutput, error) {
+	hash := crypto.SHA256
+	if aws.StringValue(in.SigningAlgorithm) == kms.SigningAlgorithmSpecEcdsaSha384 {
+		hash = crypto.SHA384
+	}
+	sig, err := f.key.Sign(rand.Reader, in.Message, hash)
+	return &kms.SignOutput{Signature: sig}, err
+}
+
+// verifySigner signs an identity token with the signer and verifies it with the public key.
+func verifySigner(t *testing.T, signer crypto.Signer) *private.Key {
+	key, err := private.NewKey(signer)
+	require.NoError(t, err)
+
+	jwsSigner, err := jose.NewSigner(key.SigningKey(), nil)
+	require.NoError(t, err)
+	str, err := jwt.Signed(jwsSigner).Claims(jwt.Claims{Subject: "1"}).CompactSerialize()
+	require.NoError(t, err)
+	tok, err := jwt.ParseSigned(str)
+	require.NoError(t, err)
+	claims := jwt.Claims{}
+	require.NoError(t, tok.Claims(key.Public(), &claims))
+	assert.Equal(t, "1", claims.Subject)
+	return key
+}
+
+func TestAWSSigner(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+
+	for _, local := range []crypto.Signer{rsaKey, ecKey} {
+		signer, err := newAWSSigner(&fakeAWS{key: local}, "alias/authn")
+		require.NoError(t, err)
+		assert.Equal(t, local.Public(), signer.Public())
+		verifySigner(t, signer)
+	}
+
+	t.Run("with the wrong hash", func(t *testing.T) {
+		signer, err := newAWSSigner(&fakeAWS{key: ecKey}, "alias/authn")
+		require.NoError(t, err)
+		digest := sha256.Sum256([]byte("payload"))
+		_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		assert.Error(t, err)
+	})
+}
+
+func TestGCPSigner(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	name := "projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"
+
+	algorithm := "EC_SIGN_P256_SHA256"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/" + name + "/publicKey":
+			der, _ := x509.MarshalPKIXPublicKey(ecKey.Public())
+			json.NewEncoder(w).Encode(map[string]string{
+				"pem":       string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+				"algorithm": algorithm,
+			})
+		case "/v1/" + name + ":asymmetricSign":
+			var req struct {
+				Digest struct {
+					SHA256 []byte `json:"sha256"`
+				} `json:"digest"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			sig, err := ecKey.Sign(rand.Reader, req.Digest.SHA256, crypto.SHA256)
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string]string{"signature": base64.StdEncoding.EncodeToString(sig)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	signer, err := newGCPSigner(server.Client(), server.URL+"/v1/", name)
+	require.NoError(t, err)
+	key := verifySigner(t, signer)
+	assert.Equal(t, "ES256", key.JWK.Algorithm)
+
+	_, err = newGCPSigner(server.Client(), server.URL+"/v1/", "projects/p/missing")
+	assert.Error(t, err)
+
+	algorithm = "RSA_SIGN_PSS_2048_SHA256"
+	_, err = newGCPSigner(server.Client(), server.URL+"/v1/", name)
+	assert.Error(t, err)
+}
+
+func TestIsKeyURI(t *testing.T) {
+	assert.True(t, IsKeyURI("awskms://alias/authn"))
+	assert.True(t, IsKeyURI("gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k/cryptoKeyVersions/1"))
+	assert.False(t, IsKeyURI("alias/authn"))
+}
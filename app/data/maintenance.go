@@ -0,0 +1,48 @@
+package data
+
+import (
+	"sync"
+	"time"
+)
+
+// Maintenance is a switch that refuses mutating requests while the database is maintained.
+type Maintenance interface {
+	// Enable begins maintenance, which is expected to end at the given time.
+	Enable(until time.Time) error
+	// Disable ends maintenance.
+	Disable() error
+	// Until returns when maintenance is expected to end, or a zero time when it is not enabled. The
+	// time may have passed if maintenance is running long.
+	Until() (time.Time, error)
+}
+
+type localMaintenance struct {
+	until time.Time
+	mu    sync.RWMutex
+}
+
+// NewLocalMaintenance creates a Maintenance switch in memory, for a server without Redis. It only
+// affects the server that receives the request.
+func NewLocalMaintenance() Maintenance {
+	return &localMaintenance{}
+}
+
+func (m *localMaintenance) Enable(until time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.until = until
+	return nil
+}
+
+func (m *localMaintenance) Disable() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.until = time.Time{}
+	return nil
+}
+
+func (m *localMaintenance) Until() (time.Time, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.until, nil
+}
@@ -0,0 +1,14 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestLocalMaintenance(t *testing.T) {
+	for _, tester := range testers.MaintenanceTesters {
+		tester(t, data.NewLocalMaintenance())
+	}
+}
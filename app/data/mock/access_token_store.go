@@ -0,0 +1,53 @@
+package mock
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/keratin/authn-server/lib"
+)
+
+type accessToken struct {
+	identityToken string
+	expiry        time.Time
+}
+
+type accessTokenStore struct {
+	tokens map[string]accessToken
+	mu     sync.RWMutex
+}
+
+func NewAccessTokenStore() *accessTokenStore {
+	return &accessTokenStore{tokens: make(map[string]accessToken)}
+}
+
+func (s *accessTokenStore) Create(identityToken string, expiry time.Time) (string, error) {
+	binToken, err := lib.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(binToken)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token] = accessToken{identityToken, expiry}
+	return token, nil
+}
+
+func (s *accessTokenStore) Find(token string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	found, ok := s.tokens[token]
+	if !ok || !found.expiry.After(time.Now()) {
+		return "", nil
+	}
+	return found.identityToken, nil
+}
+
+func (s *accessTokenStore) Revoke(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, token)
+	return nil
+}
@@ -1,7 +1,10 @@
 package mock
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/keratin/authn-server/app/models"
@@ -22,6 +25,8 @@ type accountStore struct {
 	idByUsername      map[string]int
 	oauthAccountsByID map[int][]*models.OauthAccount
 	idByOauthID       map[string]int
+	aliasesByID       map[int][]*models.AccountAlias
+	idByAlias         map[string]int
 }
 
 func NewAccountStore() *accountStore {
@@ -30,6 +35,8 @@ func NewAccountStore() *accountStore {
 		oauthAccountsByID: make(map[int][]*models.OauthAccount),
 		idByUsername:      make(map[string]int),
 		idByOauthID:       make(map[string]int),
+		aliasesByID:       make(map[int][]*models.AccountAlias),
+		idByAlias:         make(map[string]int),
 	}
 }
 
@@ -43,6 +50,9 @@ func (s *accountStore) Find(id int) (*models.Account, error) {
 
 func (s *accountStore) FindByUsername(u string) (*models.Account, error) {
 	id := s.idByUsername[u]
+	if id == 0 {
+		id = s.idByAlias[u]
+	}
 	if id == 0 {
 		return nil, nil
 	}
@@ -50,6 +60,39 @@ func (s *accountStore) FindByUsername(u string) (*models.Account, error) {
 	return dupAccount(*s.accountsByID[id]), nil
 }
 
+func (s *accountStore) List(u string, limit int, offset int) ([]*models.Account, error) {
+	found := s.search(u)
+	if offset >= len(found) {
+		return []*models.Account{}, nil
+	}
+	found = found[offset:]
+	if limit < len(found) {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+func (s *accountStore) Count(u string) (int, error) {
+	return len(s.search(u)), nil
+}
+
+// search finds unarchived accounts with usernames that contain u, ordered by ID
+func (s *accountStore) search(u string) []*models.Account {
+	ids := []int{}
+	for id, account := range s.accountsByID {
+		if account.DeletedAt == nil && strings.Contains(strings.ToLower(account.Username), strings.ToLower(u)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	found := []*models.Account{}
+	for _, id := range ids {
+		found = append(found, dupAccount(*s.accountsByID[id]))
+	}
+	return found
+}
+
 func (s *accountStore) FindByOauthAccount(provider string, providerID string) (*models.Account, error) {
 	id := s.idByOauthID[provider+"|"+providerID]
 	if id == 0 {
@@ -108,6 +151,62 @@ func (s *accountStore) GetOauthAccounts(accountID int) ([]*models.OauthAccount,
 	return s.oauthAccountsByID[accountID], nil
 }
 
+func (s *accountStore) AddAlias(accountID int, username string) error {
+	if s.idByAlias[username] != 0 {
+		return Error{ErrNotUnique}
+	}
+
+	s.idByAlias[username] = accountID
+	s.aliasesByID[accountID] = append(s.aliasesByID[accountID], &models.AccountAlias{
+		ID:        len(s.idByAlias),
+		AccountID: accountID,
+		Username:  username,
+		CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (s *accountStore) GetAliases(accountID int) ([]*models.AccountAlias, error) {
+	aliases := []*models.AccountAlias{}
+	for _, alias := range s.aliasesByID[accountID] {
+		dup := *alias
+		aliases = append(aliases, &dup)
+	}
+	return aliases, nil
+}
+
+func (s *accountStore) DeleteAlias(accountID int, username string) (bool, error) {
+	aliases := s.aliasesByID[accountID]
+	for i, alias := range aliases {
+		if alias.Username == username {
+			s.aliasesByID[accountID] = append(aliases[:i:i], aliases[i+1:]...)
+			delete(s.idByAlias, username)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *accountStore) PromoteAlias(accountID int, username string) (bool, error) {
+	account := s.accountsByID[accountID]
+	if account == nil {
+		return false, nil
+	}
+	for _, alias := range s.aliasesByID[accountID] {
+		if alias.Username == username {
+			delete(s.idByAlias, alias.Username)
+			delete(s.idByUsername, account.Username)
+			alias.Username, account.Username = account.Username, alias.Username
+			alias.VerifiedAt, account.EmailVerifiedAt = account.EmailVerifiedAt, alias.VerifiedAt
+			s.idByAlias[alias.Username] = accountID
+			s.idByUsername[account.Username] = accountID
+			account.UpdatedAt = time.Now()
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (s *accountStore) Archive(id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
@@ -118,6 +217,11 @@ func (s *accountStore) Archive(id int) (bool, error) {
 	now := time.Now()
 	account.Username = ""
 	account.Password = []byte("")
+	account.TOTPSecret = nil
+	account.TOTPEnabledAt = nil
+	account.OTPDeliveryEnabledAt = nil
+	account.Roles = nil
+	account.Metadata = nil
 	account.DeletedAt = &now
 
 	for _, oauthAccount := range s.oauthAccountsByID[account.ID] {
@@ -125,9 +229,25 @@ func (s *accountStore) Archive(id int) (bool, error) {
 	}
 	delete(s.oauthAccountsByID, account.ID)
 
+	for _, alias := range s.aliasesByID[account.ID] {
+		delete(s.idByAlias, alias.Username)
+	}
+	delete(s.aliasesByID, account.ID)
+
 	return true, nil
 }
 
+func (s *accountStore) PurgeArchived(before time.Time) (int, error) {
+	count := 0
+	for id, account := range s.accountsByID {
+		if account.DeletedAt != nil && account.DeletedAt.Before(before) {
+			delete(s.accountsByID, id)
+			count++
+		}
+	}
+	return count, nil
+}
+
 func (s *accountStore) Lock(id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
@@ -175,6 +295,16 @@ func (s *accountStore) SetPassword(id int, p []byte) (bool, error) {
 	return true, nil
 }
 
+func (s *accountStore) RehashPassword(id int, p []byte) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	account.Password = p
+	return true, nil
+}
+
 func (s *accountStore) UpdateUsername(id int, u string) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
@@ -186,11 +316,32 @@ func (s *accountStore) UpdateUsername(id int, u string) (bool, error) {
 	}
 
 	account.Username = u
+	account.EmailVerifiedAt = nil
 	account.UpdatedAt = time.Now()
 	s.idByUsername[u] = account.ID
 	return true, nil
 }
 
+func (s *accountStore) UpdateVerifiedUsername(id int, u string) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	if s.idByUsername[u] != 0 && s.idByUsername[u] != id {
+		return false, Error{ErrNotUnique}
+	}
+
+	s.DeleteAlias(id, u)
+	delete(s.idByUsername, account.Username)
+	now := time.Now()
+	account.Username = u
+	account.EmailVerifiedAt = &now
+	account.UpdatedAt = now
+	s.idByUsername[u] = account.ID
+	return true, nil
+}
+
 func (s *accountStore) SetLastLogin(id int) (bool, error) {
 	account := s.accountsByID[id]
 	if account == nil {
@@ -202,9 +353,106 @@ func (s *accountStore) SetLastLogin(id int) (bool, error) {
 	return true, nil
 }
 
+func (s *accountStore) SetRoles(id int, roles []string) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	account.Roles = append(models.Roles(nil), roles...)
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *accountStore) SetMetadata(id int, metadata map[string]interface{}) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	// round trip through JSON, as the SQL stores do, so that callers can not share the map
+	var copied models.Metadata
+	if len(metadata) > 0 {
+		j, err := json.Marshal(metadata)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(j, &copied); err != nil {
+			return false, err
+		}
+	}
+	account.Metadata = copied
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
 // i think this works? i want to avoid accidentally giving callers the ability
 // to reach into the memory map and modify things or see changes without relying
 // on the store api.
 func dupAccount(acct models.Account) *models.Account {
 	return &acct
 }
+
+func (s *accountStore) SetVerified(id int) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	account.EmailVerifiedAt = &now
+	account.UpdatedAt = now
+	return true, nil
+}
+
+func (s *accountStore) SetTOTPSecret(id int, secret []byte) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	account.TOTPSecret = append([]byte(nil), secret...)
+	account.TOTPEnabledAt = nil
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *accountStore) EnableTOTP(id int) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil || account.TOTPSecret == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	account.TOTPEnabledAt = &now
+	account.UpdatedAt = now
+	return true, nil
+}
+
+func (s *accountStore) DeleteTOTP(id int) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	account.TOTPSecret = nil
+	account.TOTPEnabledAt = nil
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (s *accountStore) SetOTPDelivery(id int, enabled bool) (bool, error) {
+	account := s.accountsByID[id]
+	if account == nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	if enabled {
+		account.OTPDeliveryEnabledAt = &now
+	} else {
+		account.OTPDeliveryEnabledAt = nil
+	}
+	account.UpdatedAt = now
+	return true, nil
+}
@@ -3,19 +3,27 @@ package mock
 import (
 	"strconv"
 	"time"
+
+	"github.com/keratin/authn-server/app/models"
 )
 
 type actives struct {
-	byDay   map[string][]int
-	byWeek  map[string][]int
-	byMonth map[string][]int
+	byDay          map[string][]int
+	byWeek         map[string][]int
+	byMonth        map[string][]int
+	signupsByDay   map[string][]int
+	signupsByWeek  map[string][]int
+	signupsByMonth map[string][]int
 }
 
 func NewActives() *actives {
 	return &actives{
-		byDay:   make(map[string][]int, 0),
-		byWeek:  make(map[string][]int, 0),
-		byMonth: make(map[string][]int, 0),
+		byDay:          make(map[string][]int, 0),
+		byWeek:         make(map[string][]int, 0),
+		byMonth:        make(map[string][]int, 0),
+		signupsByDay:   make(map[string][]int, 0),
+		signupsByWeek:  make(map[string][]int, 0),
+		signupsByMonth: make(map[string][]int, 0),
 	}
 }
 
@@ -28,6 +36,15 @@ func (a *actives) Track(accountID int) error {
 	return nil
 }
 
+func (a *actives) TrackSignup(accountID int) error {
+	t := time.Now().In(time.UTC)
+	a.signupsByDay = appendUniq(a.signupsByDay, dayKey(t), accountID)
+	a.signupsByWeek = appendUniq(a.signupsByWeek, weekKey(t), accountID)
+	a.signupsByMonth = appendUniq(a.signupsByMonth, monthKey(t), accountID)
+
+	return nil
+}
+
 func (a *actives) ActivesByDay() (map[string]int, error) {
 	return countUniqs(a.byDay), nil
 }
@@ -40,8 +57,40 @@ func (a *actives) ActivesByMonth() (map[string]int, error) {
 	return countUniqs(a.byMonth), nil
 }
 
+func (a *actives) ActivesBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error) {
+	switch period {
+	case models.Weekly:
+		return between(a.byWeek, weekKey, period, from, to), nil
+	case models.Monthly:
+		return between(a.byMonth, monthKey, period, from, to), nil
+	default:
+		return between(a.byDay, dayKey, period, from, to), nil
+	}
+}
+
+func (a *actives) SignupsBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error) {
+	switch period {
+	case models.Weekly:
+		return between(a.signupsByWeek, weekKey, period, from, to), nil
+	case models.Monthly:
+		return between(a.signupsByMonth, monthKey, period, from, to), nil
+	default:
+		return between(a.signupsByDay, dayKey, period, from, to), nil
+	}
+}
+
 //-- UTIL
 
+func between(data map[string][]int, label func(time.Time) string, period models.Period, from time.Time, to time.Time) map[string]int {
+	counts := make(map[string]int, 0)
+
+	for _, t := range models.PeriodStarts(period, from.In(time.UTC), to.In(time.UTC)) {
+		counts[label(t)] = len(data[label(t)])
+	}
+
+	return counts
+}
+
 func countUniqs(data map[string][]int) map[string]int {
 	counts := make(map[string]int, 0)
 
@@ -0,0 +1,81 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type auditLog struct {
+	entries []models.AuditEntry
+	mu      sync.Mutex
+}
+
+func NewAuditLog() *auditLog {
+	return &auditLog{}
+}
+
+func (l *auditLog) Record(entry *models.AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	entry.ID = len(l.entries) + 1
+	l.entries = append(l.entries, *entry)
+	return nil
+}
+
+func (l *auditLog) List(filter models.AuditFilter, limit int, offset int) ([]*models.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries := []*models.AuditEntry{}
+	for i := len(l.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if !matchesAudit(l.entries[i], filter) {
+			continue
+		}
+		if offset > 0 {
+			offset--
+			continue
+		}
+		entry := l.entries[i]
+		entries = append(entries, &entry)
+	}
+	return entries, nil
+}
+
+func (l *auditLog) Count(filter models.AuditFilter) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count := 0
+	for _, entry := range l.entries {
+		if matchesAudit(entry, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (l *auditLog) Purge(before time.Time) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	kept := []models.AuditEntry{}
+	for _, entry := range l.entries {
+		if !entry.CreatedAt.Before(before) {
+			kept = append(kept, entry)
+		}
+	}
+	purged := len(l.entries) - len(kept)
+	l.entries = kept
+	return purged, nil
+}
+
+func matchesAudit(entry models.AuditEntry, filter models.AuditFilter) bool {
+	return (filter.AccountID == 0 || entry.AccountID == filter.AccountID) &&
+		(filter.Action == "" || entry.Action == filter.Action)
+}
@@ -0,0 +1,14 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestAuditLog(t *testing.T) {
+	for _, tester := range testers.AuditLogTesters {
+		tester(t, mock.NewAuditLog())
+	}
+}
@@ -0,0 +1,57 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type scheduledDelivery struct {
+	delivery models.EventDelivery
+	at       time.Time
+}
+
+type eventQueue struct {
+	deliveries map[string]*scheduledDelivery
+	mu         sync.Mutex
+}
+
+func NewEventQueue() *eventQueue {
+	return &eventQueue{deliveries: make(map[string]*scheduledDelivery)}
+}
+
+func (q *eventQueue) Schedule(delivery *models.EventDelivery, at time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.deliveries[delivery.ID] = &scheduledDelivery{*delivery, at}
+	return nil
+}
+
+func (q *eventQueue) Claim(lease time.Duration) (*models.EventDelivery, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	var due *scheduledDelivery
+	for _, sd := range q.deliveries {
+		if !sd.at.After(now) && (due == nil || sd.at.Before(due.at)) {
+			due = sd
+		}
+	}
+	if due == nil {
+		return nil, nil
+	}
+	due.at = now.Add(lease)
+	delivery := due.delivery
+	return &delivery, nil
+}
+
+func (q *eventQueue) Ack(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.deliveries, id)
+	return nil
+}
@@ -0,0 +1,106 @@
+package mock
+
+import (
+	"sync"
+	"time"
+)
+
+type failedLogins struct {
+	usernames map[string][]time.Time
+	ips       map[string][]time.Time
+	pairs     map[string]map[string][]time.Time
+	locks     map[int]time.Time
+	window    time.Duration
+	mu        sync.Mutex
+}
+
+func NewFailedLogins(window time.Duration) *failedLogins {
+	return &failedLogins{
+		usernames: make(map[string][]time.Time),
+		ips:       make(map[string][]time.Time),
+		pairs:     make(map[string]map[string][]time.Time),
+		locks:     make(map[int]time.Time),
+		window:    window,
+	}
+}
+
+func (s *failedLogins) Add(username string, ip string) (int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.usernames[username] = append(s.recent(s.usernames[username]), time.Now())
+	s.ips[ip] = append(s.recent(s.ips[ip]), time.Now())
+	if s.pairs[username] == nil {
+		s.pairs[username] = make(map[string][]time.Time)
+	}
+	s.pairs[username][ip] = append(s.recent(s.pairs[username][ip]), time.Now())
+	return len(s.usernames[username]), len(s.ips[ip]), nil
+}
+
+func (s *failedLogins) CountIP(ip string) (int, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.recent(s.ips[ip])
+	if len(failures) == 0 {
+		return 0, 0, nil
+	}
+	return len(failures), s.window - time.Since(failures[0]), nil
+}
+
+func (s *failedLogins) CountPair(username string, ip string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	failures := s.recent(s.pairs[username][ip])
+	if len(failures) == 0 {
+		return 0, time.Time{}, nil
+	}
+	return len(failures), failures[len(failures)-1], nil
+}
+
+func (s *failedLogins) Reset(username string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.usernames, username)
+	delete(s.pairs, username)
+	return nil
+}
+
+func (s *failedLogins) Lock(accountID int, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[accountID] = time.Now().Add(ttl)
+	return nil
+}
+
+func (s *failedLogins) LockedFor(accountID int) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining := time.Until(s.locks[accountID])
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+func (s *failedLogins) Unlock(accountID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.locks, accountID)
+	return nil
+}
+
+func (s *failedLogins) recent(failures []time.Time) []time.Time {
+	recent := []time.Time{}
+	for _, failedAt := range failures {
+		if time.Since(failedAt) < s.window {
+			recent = append(recent, failedAt)
+		}
+	}
+	return recent
+}
@@ -0,0 +1,15 @@
+package mock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestFailedLogins(t *testing.T) {
+	for _, tester := range testers.FailedLoginsTesters {
+		tester(t, mock.NewFailedLogins(time.Minute))
+	}
+}
@@ -0,0 +1,50 @@
+package mock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type idempotentEntry struct {
+	res       *models.IdempotentResponse
+	expiresAt time.Time
+}
+
+type idempotencyStore struct {
+	entries map[string]idempotentEntry
+	mu      sync.Mutex
+}
+
+func NewIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{entries: make(map[string]idempotentEntry)}
+}
+
+func (s *idempotencyStore) Claim(key string) (*models.IdempotentResponse, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.res, entry.res != nil, nil
+	}
+	s.entries[key] = idempotentEntry{expiresAt: time.Now().Add(time.Minute)}
+	return nil, true, nil
+}
+
+func (s *idempotencyStore) Save(key string, res *models.IdempotentResponse, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = idempotentEntry{res: res, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *idempotencyStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
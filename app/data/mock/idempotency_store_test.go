@@ -0,0 +1,14 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	for _, tester := range testers.IdempotencyStoreTesters {
+		tester(t, mock.NewIdempotencyStore())
+	}
+}
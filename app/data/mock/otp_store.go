@@ -0,0 +1,71 @@
+package mock
+
+import (
+	"sync"
+	"time"
+)
+
+type otp struct {
+	code     string
+	expiry   time.Time
+	attempts int
+}
+
+type otpStore struct {
+	codes       map[int]*otp
+	sends       map[int][]time.Time
+	maxSends    int
+	sendWindow  time.Duration
+	maxAttempts int
+	mu          sync.Mutex
+}
+
+func NewOTPStore(maxSends int, sendWindow time.Duration, maxAttempts int) *otpStore {
+	return &otpStore{
+		codes:       make(map[int]*otp),
+		sends:       make(map[int][]time.Time),
+		maxSends:    maxSends,
+		sendWindow:  sendWindow,
+		maxAttempts: maxAttempts,
+	}
+}
+
+func (s *otpStore) Create(accountID int, code string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recent := []time.Time{}
+	for _, sentAt := range s.sends[accountID] {
+		if time.Since(sentAt) < s.sendWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+	if len(recent) >= s.maxSends {
+		s.sends[accountID] = recent
+		return false, nil
+	}
+	s.sends[accountID] = append(recent, time.Now())
+
+	s.codes[accountID] = &otp{code: code, expiry: time.Now().Add(ttl)}
+	return true, nil
+}
+
+func (s *otpStore) Verify(accountID int, code string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	found := s.codes[accountID]
+	if found == nil || !found.expiry.After(time.Now()) {
+		delete(s.codes, accountID)
+		return false, nil
+	}
+	found.attempts++
+	if found.code == code {
+		delete(s.codes, accountID)
+		return true, nil
+	}
+	if found.attempts >= s.maxAttempts {
+		delete(s.codes, accountID)
+	}
+	return false, nil
+}
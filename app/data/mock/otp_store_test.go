@@ -0,0 +1,15 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestOTPStore(t *testing.T) {
+	for _, tester := range testers.OTPStoreTesters {
+		tester(t, mock.NewOTPStore(data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts))
+	}
+}
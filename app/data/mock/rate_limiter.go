@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+type rateLimiter struct {
+	buckets map[string]*bucket
+	mu      sync.Mutex
+}
+
+func NewRateLimiter() *rateLimiter {
+	return &rateLimiter{buckets: make(map[string]*bucket)}
+}
+
+func (s *rateLimiter) Take(key string, limit int, window time.Duration) (*models.RateLimit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit), at: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(limit), b.tokens+float64(now.Sub(b.at))*float64(limit)/float64(window))
+	b.at = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	return models.NewRateLimit(allowed, b.tokens, limit, window), nil
+}
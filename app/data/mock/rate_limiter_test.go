@@ -0,0 +1,14 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestRateLimiter(t *testing.T) {
+	for _, tester := range testers.RateLimiterTesters {
+		tester(t, mock.NewRateLimiter())
+	}
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"encoding/hex"
+	"time"
 
 	"github.com/keratin/authn-server/lib"
 	"github.com/keratin/authn-server/app/models"
@@ -10,12 +11,14 @@ import (
 type refreshTokenStore struct {
 	tokensByAccount map[int][]models.RefreshToken
 	accountByToken  map[models.RefreshToken]int
+	sessionByToken  map[models.RefreshToken]*models.Session
 }
 
 func NewRefreshTokenStore() *refreshTokenStore {
 	return &refreshTokenStore{
 		tokensByAccount: make(map[int][]models.RefreshToken),
 		accountByToken:  make(map[models.RefreshToken]int),
+		sessionByToken:  make(map[models.RefreshToken]*models.Session),
 	}
 }
 
@@ -27,6 +30,8 @@ func (s *refreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	token := models.RefreshToken(hex.EncodeToString(binToken))
 	s.tokensByAccount[accountID] = append(s.tokensByAccount[accountID], token)
 	s.accountByToken[token] = accountID
+	now := time.Now()
+	s.sessionByToken[token] = &models.Session{Token: token, CreatedAt: &now, LastSeenAt: &now}
 	return token, nil
 }
 
@@ -35,17 +40,42 @@ func (s *refreshTokenStore) Find(t models.RefreshToken) (int, error) {
 }
 
 func (s *refreshTokenStore) Touch(t models.RefreshToken, accountID int) error {
+	if session, ok := s.sessionByToken[t]; ok {
+		now := time.Now()
+		session.LastSeenAt = &now
+	}
+	return nil
+}
+
+func (s *refreshTokenStore) TouchFor(t models.RefreshToken, accountID int, ttl time.Duration) error {
+	return s.Touch(t, accountID)
+}
+
+func (s *refreshTokenStore) SetUserAgent(t models.RefreshToken, userAgent string) error {
+	if session, ok := s.sessionByToken[t]; ok {
+		session.UserAgent = userAgent
+	}
 	return nil
 }
 
+func (s *refreshTokenStore) FindSessions(accountID int) ([]models.Session, error) {
+	sessions := []models.Session{}
+	for _, t := range s.tokensByAccount[accountID] {
+		sessions = append(sessions, *s.sessionByToken[t])
+	}
+	return sessions, nil
+}
+
 func (s *refreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
-	return s.tokensByAccount[accountID], nil
+	// copied, so that callers may revoke while iterating
+	return append([]models.RefreshToken{}, s.tokensByAccount[accountID]...), nil
 }
 
 func (s *refreshTokenStore) Revoke(t models.RefreshToken) error {
 	accountID := s.accountByToken[t]
 	if accountID != 0 {
 		delete(s.accountByToken, t)
+		delete(s.sessionByToken, t)
 		s.tokensByAccount[accountID] = without(t, s.tokensByAccount[accountID])
 	}
 	return nil
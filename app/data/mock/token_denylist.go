@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"sync"
+	"time"
+)
+
+type tokenDenylist struct {
+	denied map[string]time.Time
+	mu     sync.RWMutex
+}
+
+func NewTokenDenylist() *tokenDenylist {
+	return &tokenDenylist{denied: make(map[string]time.Time)}
+}
+
+func (d *tokenDenylist) Deny(jti string, expiry time.Time) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if expiry.After(time.Now()) {
+		d.denied[jti] = expiry
+	}
+	return nil
+}
+
+func (d *tokenDenylist) Spend(jti string, expiry time.Time) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !expiry.After(time.Now()) {
+		return false, nil
+	}
+	if denied, ok := d.denied[jti]; ok && denied.After(time.Now()) {
+		return false, nil
+	}
+	d.denied[jti] = expiry
+	return true, nil
+}
+
+func (d *tokenDenylist) Allow(jti string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.denied, jti)
+	return nil
+}
+
+func (d *tokenDenylist) IsDenied(jti string) (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	expiry, ok := d.denied[jti]
+	return ok && expiry.After(time.Now()), nil
+}
@@ -0,0 +1,14 @@
+package mock_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestTokenDenylist(t *testing.T) {
+	for _, tester := range testers.TokenDenylistTesters {
+		tester(t, mock.NewTokenDenylist())
+	}
+}
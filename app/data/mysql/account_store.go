@@ -2,6 +2,7 @@ package mysql
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -29,6 +30,9 @@ func (db *AccountStore) Find(id int) (*models.Account, error) {
 func (db *AccountStore) FindByUsername(u string) (*models.Account, error) {
 	account := models.Account{}
 	err := sqlx.Get(db, &account, "SELECT * FROM accounts WHERE username = ? AND deleted_at IS NULL", u)
+	if err == sql.ErrNoRows {
+		err = sqlx.Get(db, &account, "SELECT accounts.* FROM accounts INNER JOIN account_aliases ON account_aliases.account_id = accounts.id WHERE account_aliases.username = ? AND accounts.deleted_at IS NULL", u)
+	}
 	if err == sql.ErrNoRows {
 		return nil, nil
 	} else if err != nil {
@@ -37,6 +41,23 @@ func (db *AccountStore) FindByUsername(u string) (*models.Account, error) {
 	return &account, nil
 }
 
+func (db *AccountStore) List(username string, limit int, offset int) ([]*models.Account, error) {
+	accounts := []*models.Account{}
+	err := sqlx.Select(db, &accounts, "SELECT * FROM accounts WHERE username LIKE ? ESCAPE '!' AND deleted_at IS NULL ORDER BY id LIMIT ? OFFSET ?", likePattern(username), limit, offset)
+	return accounts, err
+}
+
+func (db *AccountStore) Count(username string) (int, error) {
+	var count int
+	err := sqlx.Get(db, &count, "SELECT COUNT(*) FROM accounts WHERE username LIKE ? ESCAPE '!' AND deleted_at IS NULL", likePattern(username))
+	return count, err
+}
+
+// likePattern matches usernames that contain the given string
+func likePattern(s string) string {
+	return "%" + strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s) + "%"
+}
+
 func (db *AccountStore) FindByOauthAccount(provider string, providerID string) (*models.Account, error) {
 	account := models.Account{}
 	err := sqlx.Get(db, &account, "SELECT a.* FROM accounts a INNER JOIN oauth_accounts oa ON a.id = oa.account_id WHERE oa.provider = ? AND oa.provider_id = ?", provider, providerID)
@@ -99,15 +120,82 @@ func (db *AccountStore) GetOauthAccounts(accountID int) ([]*models.OauthAccount,
 	return accounts, err
 }
 
+func (db *AccountStore) AddAlias(accountID int, username string) error {
+	_, err := db.Exec("INSERT INTO account_aliases (account_id, username, created_at) VALUES (?, ?, ?)", accountID, username, time.Now())
+	return err
+}
+
+func (db *AccountStore) GetAliases(accountID int) ([]*models.AccountAlias, error) {
+	aliases := []*models.AccountAlias{}
+	err := sqlx.Select(db, &aliases, "SELECT * FROM account_aliases WHERE account_id = ? ORDER BY id", accountID)
+	return aliases, err
+}
+
+func (db *AccountStore) DeleteAlias(accountID int, username string) (bool, error) {
+	result, err := db.Exec("DELETE FROM account_aliases WHERE account_id = ? AND username = ?", accountID, username)
+	return ok(result, err)
+}
+
+func (db *AccountStore) PromoteAlias(accountID int, username string) (bool, error) {
+	promoted := false
+	err := transact(db.Ext, func(tx sqlx.Ext) error {
+		alias := models.AccountAlias{}
+		err := sqlx.Get(tx, &alias, "SELECT * FROM account_aliases WHERE account_id = ? AND username = ?", accountID, username)
+		if err == sql.ErrNoRows {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM account_aliases WHERE id = ?", alias.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("INSERT INTO account_aliases (account_id, username, verified_at, created_at) SELECT id, username, email_verified_at, ? FROM accounts WHERE id = ?", time.Now(), accountID)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec("UPDATE accounts SET username = ?, email_verified_at = ?, updated_at = ? WHERE id = ?", username, alias.VerifiedAt, time.Now(), accountID)
+		promoted, err = ok(result, err)
+		return err
+	})
+	return promoted, err
+}
+
 func (db *AccountStore) Archive(id int) (bool, error) {
 	_, err := db.Exec("DELETE FROM oauth_accounts WHERE account_id = ?", id)
 	if err != nil {
 		return false, err
 	}
-	result, err := db.Exec("UPDATE accounts SET username = CONCAT('@', MD5(RAND())), password = ?, deleted_at = ? WHERE id = ?", "", time.Now(), id)
+	_, err = db.Exec("DELETE FROM account_aliases WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	_, err = db.Exec(anonymizeAuditLog+" WHERE account_id = ?", id)
+	if err != nil {
+		return false, err
+	}
+	result, err := db.Exec("UPDATE accounts SET username = CONCAT('@', MD5(RAND())), password = ?, totp_secret = NULL, totp_enabled_at = NULL, otp_delivery_enabled_at = NULL, roles = NULL, metadata = NULL, deleted_at = ? WHERE id = ?", "", time.Now(), id)
 	return ok(result, err)
 }
 
+// anonymizeAuditLog removes what identifies the client from audit_log entries, so that an archived
+// account can not be traced through them
+const anonymizeAuditLog = "UPDATE audit_log SET username = '', ip = '', user_agent = ''"
+
+func (db *AccountStore) PurgeArchived(before time.Time) (int, error) {
+	// entries recorded after the account was archived are anonymized as well
+	_, err := db.Exec(anonymizeAuditLog+" WHERE account_id IN (SELECT id FROM accounts WHERE deleted_at < ?)", before)
+	if err != nil {
+		return 0, err
+	}
+	result, err := db.Exec("DELETE FROM accounts WHERE deleted_at < ?", before)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
 func (db *AccountStore) Lock(id int) (bool, error) {
 	result, err := db.Exec("UPDATE accounts SET locked = ?, updated_at = ? WHERE id = ?", true, time.Now(), id)
 	return ok(result, err)
@@ -128,16 +216,45 @@ func (db *AccountStore) SetPassword(id int, p []byte) (bool, error) {
 	return ok(result, err)
 }
 
+func (db *AccountStore) RehashPassword(id int, p []byte) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET password = ? WHERE id = ?", p, id)
+	return ok(result, err)
+}
+
 func (db *AccountStore) UpdateUsername(id int, u string) (bool, error) {
-	result, err := db.Exec("UPDATE accounts SET username = ?, updated_at = ? WHERE id = ?", u, time.Now(), id)
+	result, err := db.Exec("UPDATE accounts SET username = ?, email_verified_at = NULL, updated_at = ? WHERE id = ?", u, time.Now(), id)
 	return ok(result, err)
 }
 
+func (db *AccountStore) UpdateVerifiedUsername(id int, u string) (bool, error) {
+	updated := false
+	err := transact(db.Ext, func(tx sqlx.Ext) error {
+		_, err := tx.Exec("DELETE FROM account_aliases WHERE account_id = ? AND username = ?", id, u)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec("UPDATE accounts SET username = ?, email_verified_at = ?, updated_at = ? WHERE id = ?", u, time.Now(), time.Now(), id)
+		updated, err = ok(result, err)
+		return err
+	})
+	return updated, err
+}
+
 func (db *AccountStore) SetLastLogin(id int) (bool, error) {
 	result, err := db.Exec("UPDATE accounts SET last_login_at = ? WHERE id = ?", time.Now(), id)
 	return ok(result, err)
 }
 
+func (db *AccountStore) SetRoles(id int, roles []string) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET roles = ?, updated_at = ? WHERE id = ?", models.Roles(roles), time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) SetMetadata(id int, metadata map[string]interface{}) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET metadata = ?, updated_at = ? WHERE id = ?", models.Metadata(metadata), time.Now(), id)
+	return ok(result, err)
+}
+
 func ok(result sql.Result, err error) (bool, error) {
 	if err != nil {
 		return false, err
@@ -145,3 +262,33 @@ func ok(result sql.Result, err error) (bool, error) {
 	count, err := result.RowsAffected()
 	return count > 0, err
 }
+
+func (db *AccountStore) SetVerified(id int) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET email_verified_at = ?, updated_at = ? WHERE id = ?", time.Now(), time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) SetTOTPSecret(id int, secret []byte) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET totp_secret = ?, totp_enabled_at = NULL, updated_at = ? WHERE id = ?", string(secret), time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) EnableTOTP(id int) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET totp_enabled_at = ?, updated_at = ? WHERE id = ? AND totp_secret IS NOT NULL", time.Now(), time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) DeleteTOTP(id int) (bool, error) {
+	result, err := db.Exec("UPDATE accounts SET totp_secret = NULL, totp_enabled_at = NULL, updated_at = ? WHERE id = ?", time.Now(), id)
+	return ok(result, err)
+}
+
+func (db *AccountStore) SetOTPDelivery(id int, enabled bool) (bool, error) {
+	var enabledAt *time.Time
+	if enabled {
+		now := time.Now()
+		enabledAt = &now
+	}
+	result, err := db.Exec("UPDATE accounts SET otp_delivery_enabled_at = ?, updated_at = ? WHERE id = ?", enabledAt, time.Now(), id)
+	return ok(result, err)
+}
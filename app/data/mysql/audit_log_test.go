@@ -0,0 +1,19 @@
+package mysql_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mysql"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	db, err := mysql.TestDB()
+	require.NoError(t, err)
+	log := &mysql.AuditLog{db}
+	for _, tester := range testers.AuditLogTesters {
+		db.MustExec("TRUNCATE audit_log")
+		tester(t, log)
+	}
+}
@@ -2,23 +2,34 @@ package mysql
 
 import "github.com/jmoiron/sqlx"
 import "github.com/go-sql-driver/mysql"
+import "github.com/keratin/authn-server/app/data/schema"
 
-// MigrateDB is committed to doing the work necessary to converge the database
-// in a safe, production-grade fashion. This will mean conditional logic as it
-// determines which steps have run and which steps must still be run. Given the
-// expected final complexity of this project, this is acceptable.
+// migrations converge the database in a safe, production-grade fashion. Each step is still written
+// with conditional logic so that databases converged before versions were tracked may safely run it
+// again. Append new steps with the next version; never renumber or remove a released one.
+var migrations = []schema.Migration{
+	{Version: 1, Name: "createAccounts", Up: createAccounts},
+	{Version: 2, Name: "createOauthAccounts", Up: createOauthAccounts},
+	{Version: 3, Name: "createAccountLastLoginAtField", Up: createAccountLastLoginAtField},
+	{Version: 4, Name: "widenOauthAccessToken", Up: widenOauthAccessToken},
+	{Version: 5, Name: "createAccountRolesField", Up: createAccountRolesField},
+	{Version: 6, Name: "createAccountEmailVerifiedAtField", Up: createAccountEmailVerifiedAtField},
+	{Version: 7, Name: "createAccountTOTPFields", Up: createAccountTOTPFields},
+	{Version: 8, Name: "createAccountOTPDeliveryField", Up: createAccountOTPDeliveryField},
+	{Version: 9, Name: "createAccountMetadataField", Up: createAccountMetadataField},
+	{Version: 10, Name: "createAccountAliases", Up: createAccountAliases},
+	{Version: 11, Name: "createAuditLog", Up: createAuditLog},
+	{Version: 12, Name: "createAccountAliasVerifiedAtField", Up: createAccountAliasVerifiedAtField},
+}
+
+// MigrateDB applies any pending migrations.
 func MigrateDB(db *sqlx.DB) error {
-	migrations := []func(db *sqlx.DB) error{
-		createAccounts,
-		createOauthAccounts,
-		createAccountLastLoginAtField,
-	}
-	for _, m := range migrations {
-		if err := m(db); err != nil {
-			return err
-		}
-	}
-	return nil
+	return schema.Migrate(db, migrations)
+}
+
+// MigrationStatus reports which migrations have been applied and which are pending.
+func MigrationStatus(db *sqlx.DB) ([]schema.Status, error) {
+	return schema.Statuses(db, migrations)
 }
 
 func createAccounts(db *sqlx.DB) error {
@@ -69,3 +80,121 @@ func createAccountLastLoginAtField(db *sqlx.DB) error {
 	}
 	return err
 }
+
+// widenOauthAccessToken allows access tokens longer than 255 characters, as with JWT access tokens,
+// matching the TEXT column of the other stores.
+func widenOauthAccessToken(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE oauth_accounts MODIFY access_token TEXT NOT NULL
+    `)
+	return err
+}
+
+func createAccountRolesField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD roles TEXT DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createAccountEmailVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD email_verified_at DATETIME DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createAccountTOTPFields(db *sqlx.DB) error {
+	for _, field := range []string{"totp_secret TEXT DEFAULT NULL", "totp_enabled_at DATETIME DEFAULT NULL"} {
+		_, err := db.Exec("ALTER TABLE accounts ADD " + field)
+		if mysqlError, ok := err.(*mysql.MySQLError); ok {
+			if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createAccountOTPDeliveryField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD otp_delivery_enabled_at DATETIME DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createAccountMetadataField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD metadata TEXT DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
+
+func createAccountAliases(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS account_aliases (
+            id INT(11) NOT NULL AUTO_INCREMENT,
+            account_id INT(11) NOT NULL,
+            username VARCHAR(255) NOT NULL,
+            created_at DATETIME NOT NULL,
+            PRIMARY KEY (id),
+            UNIQUE KEY index_account_aliases_on_username (username),
+            KEY index_account_aliases_by_account_id (account_id)
+        )
+    `)
+	return err
+}
+
+func createAuditLog(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS audit_log (
+            id INT(11) NOT NULL AUTO_INCREMENT,
+            action VARCHAR(64) NOT NULL,
+            account_id INT(11) NOT NULL,
+            username VARCHAR(255) NOT NULL,
+            actor VARCHAR(64) NOT NULL,
+            ip VARCHAR(64) NOT NULL,
+            user_agent VARCHAR(255) NOT NULL,
+            created_at DATETIME NOT NULL,
+            PRIMARY KEY (id),
+            KEY index_audit_log_by_account_id (account_id),
+            KEY index_audit_log_by_created_at (created_at)
+        )
+    `)
+	return err
+}
+
+func createAccountAliasVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE account_aliases ADD verified_at DATETIME DEFAULT NULL
+    `)
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		if mysqlError.Number == 1060 { // 1060 = Duplicate column name
+			err = nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+package data
+
+import "time"
+
+// MaxOTPSends limits how many codes may be delivered to an account within OTPSendWindow, since
+// each one may cost the app an SMS.
+const MaxOTPSends = 5
+
+// OTPSendWindow is the period for MaxOTPSends.
+const OTPSendWindow = 15 * time.Minute
+
+// MaxOTPAttempts limits how many times a delivered code may be guessed before it is forgotten.
+const MaxOTPAttempts = 5
+
+// OTPStore keeps one-time passwords that the app delivered for AuthN, as by SMS or voice.
+type OTPStore interface {
+	// Create stores a code for the account until the TTL, replacing any previous code. It returns
+	// false without storing the code when the account has reached its limit of sends.
+	Create(accountID int, code string, ttl time.Duration) (bool, error)
+	// Verify returns true and forgets the code when it matches. The code is also forgotten after
+	// its limit of attempts.
+	Verify(accountID int, code string) (bool, error)
+}
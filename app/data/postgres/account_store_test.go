@@ -42,6 +42,7 @@ func TestAccountStore(t *testing.T) {
 	for _, tester := range testers.AccountStoreTesters {
 		db.MustExec("TRUNCATE accounts")
 		db.MustExec("TRUNCATE oauth_accounts")
+		db.MustExec("TRUNCATE account_aliases")
 		tester(t, store)
 	}
 }
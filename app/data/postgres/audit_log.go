@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type AuditLog struct {
+	sqlx.Ext
+}
+
+func (db *AuditLog) Record(entry *models.AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	result, err := sqlx.NamedQuery(db,
+		"INSERT INTO audit_log (action, account_id, username, actor, ip, user_agent, created_at) VALUES (:action, :account_id, :username, :actor, :ip, :user_agent, :created_at) RETURNING id",
+		entry,
+	)
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	result.Next()
+	return result.Scan(&entry.ID)
+}
+
+func (db *AuditLog) List(filter models.AuditFilter, limit int, offset int) ([]*models.AuditEntry, error) {
+	where, args := auditWhere(filter)
+	entries := []*models.AuditEntry{}
+	err := sqlx.Select(db, &entries, "SELECT * FROM audit_log"+where+fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)+1, len(args)+2), append(args, limit, offset)...)
+	return entries, err
+}
+
+func (db *AuditLog) Count(filter models.AuditFilter) (int, error) {
+	where, args := auditWhere(filter)
+	var count int
+	err := sqlx.Get(db, &count, "SELECT COUNT(*) FROM audit_log"+where, args...)
+	return count, err
+}
+
+func (db *AuditLog) Purge(before time.Time) (int, error) {
+	result, err := db.Exec("DELETE FROM audit_log WHERE created_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	return int(count), err
+}
+
+// auditWhere builds a WHERE clause for the filter
+func auditWhere(filter models.AuditFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+	if filter.AccountID != 0 {
+		conditions = append(conditions, fmt.Sprintf("account_id = $%d", len(args)+1))
+		args = append(args, filter.AccountID)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)+1))
+		args = append(args, filter.Action)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,19 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/postgres"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	db, err := newTestDB()
+	require.NoError(t, err)
+	log := &postgres.AuditLog{db}
+	for _, tester := range testers.AuditLogTesters {
+		db.MustExec("TRUNCATE audit_log")
+		tester(t, log)
+	}
+}
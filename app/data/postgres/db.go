@@ -11,3 +11,22 @@ import (
 func NewDB(url *url.URL) (*sqlx.DB, error) {
 	return sqlx.Connect("postgres", url.String())
 }
+
+// transact runs fn in a transaction, so that its statements apply together or not at all. A store
+// that was given a transaction runs fn in it directly.
+func transact(ext sqlx.Ext, fn func(tx sqlx.Ext) error) error {
+	db, ok := ext.(*sqlx.DB)
+	if !ok {
+		return fn(ext)
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	err = fn(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
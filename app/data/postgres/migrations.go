@@ -1,24 +1,37 @@
 package postgres
 
-import "github.com/jmoiron/sqlx"
+import (
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/schema"
+)
 
-// MigrateDB is committed to doing the work necessary to converge the database
-// in a safe, production-grade fashion. This will mean conditional logic as it
-// determines which steps have run and which steps must still be run. Given the
-// expected final complexity of this project, this is acceptable.
+// migrations converge the database in a safe, production-grade fashion. Each step is still written
+// with conditional logic so that databases converged before versions were tracked may safely run it
+// again. Append new steps with the next version; never renumber or remove a released one.
+var migrations = []schema.Migration{
+	{Version: 1, Name: "migrateAccounts", Up: migrateAccounts},
+	{Version: 2, Name: "createOauthAccounts", Up: createOauthAccounts},
+	{Version: 3, Name: "createAccountLastLoginAtField", Up: createAccountLastLoginAtField},
+	{Version: 4, Name: "createAccountRolesField", Up: createAccountRolesField},
+	{Version: 5, Name: "createAccountEmailVerifiedAtField", Up: createAccountEmailVerifiedAtField},
+	{Version: 6, Name: "createAccountTOTPFields", Up: createAccountTOTPFields},
+	{Version: 7, Name: "createAccountOTPDeliveryField", Up: createAccountOTPDeliveryField},
+	{Version: 8, Name: "createAccountMetadataField", Up: createAccountMetadataField},
+	{Version: 9, Name: "createAccountAliases", Up: createAccountAliases},
+	{Version: 10, Name: "createAuditLog", Up: createAuditLog},
+	{Version: 11, Name: "createAccountAliasVerifiedAtField", Up: createAccountAliasVerifiedAtField},
+}
+
+// MigrateDB applies any pending migrations.
 func MigrateDB(db *sqlx.DB) error {
-	migrations := []func(db *sqlx.DB) error{
-		migrateAccounts,
-		createOauthAccounts,
-		createAccountLastLoginAtField,
-	}
-	for _, m := range migrations {
-		if err := m(db); err != nil {
-			return err
-		}
-	}
-	return nil
+	return schema.Migrate(db, migrations)
+}
+
+// MigrationStatus reports which migrations have been applied and which are pending.
+func MigrationStatus(db *sqlx.DB) ([]schema.Status, error) {
+	return schema.Statuses(db, migrations)
 }
+
 func migrateAccounts(db *sqlx.DB) error {
 	_, err := db.Exec(`
         CREATE TABLE IF NOT EXISTS accounts (
@@ -59,3 +72,92 @@ func createAccountLastLoginAtField(db *sqlx.DB) error {
     `)
 	return err
 }
+
+func createAccountRolesField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS roles TEXT DEFAULT NULL
+    `)
+	return err
+}
+
+func createAccountEmailVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS email_verified_at timestamptz DEFAULT NULL
+    `)
+	return err
+}
+
+func createAccountTOTPFields(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts
+            ADD COLUMN IF NOT EXISTS totp_secret TEXT DEFAULT NULL,
+            ADD COLUMN IF NOT EXISTS totp_enabled_at timestamptz DEFAULT NULL
+    `)
+	return err
+}
+
+func createAccountOTPDeliveryField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS otp_delivery_enabled_at timestamptz DEFAULT NULL
+    `)
+	return err
+}
+
+func createAccountMetadataField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD COLUMN IF NOT EXISTS metadata TEXT DEFAULT NULL
+    `)
+	return err
+}
+
+func createAccountAliases(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS account_aliases (
+            id SERIAL PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            username TEXT NOT NULL UNIQUE,
+            created_at timestamptz NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS account_aliases_by_account_id ON account_aliases (account_id)
+    `)
+	return err
+}
+
+func createAuditLog(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS audit_log (
+            id SERIAL PRIMARY KEY,
+            action TEXT NOT NULL,
+            account_id INTEGER NOT NULL,
+            username TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            ip TEXT NOT NULL,
+            user_agent TEXT NOT NULL,
+            created_at timestamptz NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	for _, index := range []string{
+		"CREATE INDEX IF NOT EXISTS audit_log_by_account_id ON audit_log (account_id)",
+		"CREATE INDEX IF NOT EXISTS audit_log_by_created_at ON audit_log (created_at)",
+	} {
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createAccountAliasVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE account_aliases ADD COLUMN IF NOT EXISTS verified_at timestamptz DEFAULT NULL
+    `)
+	return err
+}
@@ -0,0 +1,79 @@
+package private
+
+import (
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/pem"
+
+	"github.com/pkg/errors"
+	"github.com/youmark/pkcs8"
+)
+
+// IsEncrypted reports whether a PEM block requires a passphrase.
+func IsEncrypted(block *pem.Block) bool {
+	return block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block)
+}
+
+// DecryptPEM decrypts a passphrase protected PEM block, either as encrypted PKCS#8 (the default of
+// `openssl genpkey` and `openssl pkcs8`) or as a legacy OpenSSL encrypted key with a Proc-Type
+// header. The result may be given to ParsePEM.
+func DecryptPEM(block *pem.Block, passphrase []byte) (*pem.Block, error) {
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		if len(passphrase) == 0 {
+			return nil, x509.IncorrectPasswordError
+		}
+		key, _, err := pkcs8.ParsePrivateKey(block.Bytes, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, errors.Wrap(err, "MarshalPKCS8PrivateKey")
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+	}
+
+	der, err := x509.DecryptPEMBlock(block, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return &pem.Block{Type: block.Type, Bytes: der}, nil
+}
+
+// pkcs8 decrypts CBC ciphers without removing their PKCS#7 padding, which is then ignored when the
+// key is parsed. Checking the padding rejects corrupt ciphertexts, and is the first check of the
+// passphrase.
+func init() {
+	for _, c := range []pkcs8.Cipher{pkcs8.AES128CBC, pkcs8.AES192CBC, pkcs8.AES256CBC, pkcs8.TripleDESCBC} {
+		c := c
+		pkcs8.RegisterCipher(c.OID(), func() pkcs8.Cipher { return paddedCipher{c} })
+	}
+}
+
+type paddedCipher struct {
+	pkcs8.Cipher
+}
+
+func (c paddedCipher) Decrypt(key, iv, ciphertext []byte) ([]byte, error) {
+	// the block size of a CBC cipher is its IV size
+	blockSize := c.IVSize()
+	if len(iv) != blockSize || len(ciphertext) == 0 || len(ciphertext)%blockSize != 0 {
+		return nil, x509.IncorrectPasswordError
+	}
+	plaintext, err := c.Cipher.Decrypt(key, iv, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > blockSize {
+		return nil, x509.IncorrectPasswordError
+	}
+	expected := make([]byte, padding)
+	for i := range expected {
+		expected[i] = byte(padding)
+	}
+	if subtle.ConstantTimeCompare(plaintext[len(plaintext)-padding:], expected) != 1 {
+		return nil, x509.IncorrectPasswordError
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
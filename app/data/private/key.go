@@ -2,22 +2,34 @@ package private
 
 import (
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 
 	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
 	"gopkg.in/square/go-jose.v2"
 )
 
+// Key is a private signing key with its public JWK. RSA, ECDSA (P-256, P-384, P-521), and Ed25519
+// keys are supported.
 type Key struct {
-	JWK jose.JSONWebKey
-	*rsa.PrivateKey
+	JWK        jose.JSONWebKey
+	PrivateKey crypto.Signer
 }
 
-// Wrap the provided RSA private key as our internal key with canonical ID
-func NewKey(key *rsa.PrivateKey) (*Key, error) {
-	id, err := keyID(&key.PublicKey)
+// Wrap the provided private key as our internal key with canonical ID
+func NewKey(key crypto.Signer) (*Key, error) {
+	alg, err := algorithm(key)
+	if err != nil {
+		return nil, err
+	}
+	id, err := keyID(key.Public())
 	if err != nil {
 		return nil, errors.Wrap(err, "private.keyID")
 	}
@@ -26,7 +38,7 @@ func NewKey(key *rsa.PrivateKey) (*Key, error) {
 		JWK: jose.JSONWebKey{
 			Key:       key.Public(),
 			Use:       "sig",
-			Algorithm: "RS256",
+			Algorithm: string(alg),
 			KeyID:     id,
 		},
 	}, nil
@@ -41,8 +53,100 @@ func GenerateKey(bits int) (*Key, error) {
 	return NewKey(key)
 }
 
-// KeyID uses square/go-jose to extract the JWK thumbprint for a RSA public key.
-func keyID(key *rsa.PublicKey) (string, error) {
+// GenerateECDSAKey generates an ECDSA private key on the given curve
+func GenerateECDSAKey(curve elliptic.Curve) (*Key, error) {
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(key)
+}
+
+// GenerateEd25519Key generates an Ed25519 private key
+func GenerateEd25519Key() (*Key, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return NewKey(key)
+}
+
+// Public returns the public half of the key.
+func (k *Key) Public() crypto.PublicKey {
+	return k.PrivateKey.Public()
+}
+
+// Algorithm returns the JWS algorithm for signing with the key.
+func (k *Key) Algorithm() jose.SignatureAlgorithm {
+	return jose.SignatureAlgorithm(k.JWK.Algorithm)
+}
+
+// SigningKey returns the key for a jose.Signer. Keys that go-jose can not sign with directly, like
+// keys that never leave a KMS, are signed through crypto.Signer.
+func (k *Key) SigningKey() jose.SigningKey {
+	switch k.PrivateKey.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return jose.SigningKey{
+			Algorithm: k.Algorithm(),
+			Key:       jose.JSONWebKey{Key: k.PrivateKey, KeyID: k.JWK.KeyID},
+		}
+	}
+	return jose.SigningKey{Algorithm: k.Algorithm(), Key: opaqueSigner{k}}
+}
+
+// ParsePEM parses a PEM block with a PKCS#1 RSA key, a SEC 1 EC key, or a PKCS#8 key of any
+// supported type.
+func ParsePEM(block *pem.Block) (*Key, error) {
+	if IsEncrypted(block) {
+		return nil, errors.New("key is encrypted and requires a passphrase")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block: %s", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type: %T", key)
+	}
+	return NewKey(signer)
+}
+
+// algorithm is chosen by the public key, so that keys held elsewhere (e.g. in a KMS) are supported.
+func algorithm(key crypto.Signer) (jose.SignatureAlgorithm, error) {
+	switch k := key.Public().(type) {
+	case *rsa.PublicKey:
+		return jose.RS256, nil
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return jose.ES256, nil
+		case elliptic.P384():
+			return jose.ES384, nil
+		case elliptic.P521():
+			return jose.ES512, nil
+		}
+		return "", fmt.Errorf("unsupported curve: %s", k.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return jose.EdDSA, nil
+	}
+	return "", fmt.Errorf("unsupported key type: %T", key.Public())
+}
+
+// KeyID uses square/go-jose to extract the JWK thumbprint for a public key.
+func keyID(key crypto.PublicKey) (string, error) {
 	jwk := jose.JSONWebKey{Key: key}
 	kid, err := jwk.Thumbprint(crypto.SHA256)
 	if err != nil {
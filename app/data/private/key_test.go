@@ -1,6 +1,9 @@
 package private_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/pem"
@@ -8,6 +11,8 @@ import (
 	"math/rand"
 	"testing"
 
+	"golang.org/x/crypto/ed25519"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,7 +31,7 @@ func TestGenerate(t *testing.T) {
 	bitLen := 512
 	key, err := private.GenerateKey(bitLen)
 	require.NoError(t, err)
-	assert.Equal(t, key.PrivateKey.N.BitLen(), bitLen, "generated key should have requested bit length")
+	assert.Equal(t, key.PrivateKey.(*rsa.PrivateKey).N.BitLen(), bitLen, "generated key should have requested bit length")
 }
 
 func TestKeyID(t *testing.T) {
@@ -38,3 +43,40 @@ func TestKeyID(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, expected, key.JWK.KeyID)
 }
+
+func TestParsePEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(crand.Reader, 512)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	require.NoError(t, err)
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(crand.Reader)
+	require.NoError(t, err)
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+	rsaPKCS8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		block *pem.Block
+		alg   string
+	}{
+		{&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, "RS256"},
+		{&pem.Block{Type: "PRIVATE KEY", Bytes: rsaPKCS8}, "RS256"},
+		{&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}, "ES256"},
+		{&pem.Block{Type: "PRIVATE KEY", Bytes: edDER}, "EdDSA"},
+	}
+	for _, tc := range testCases {
+		key, err := private.ParsePEM(tc.block)
+		if assert.NoError(t, err, tc.alg) {
+			assert.Equal(t, tc.alg, key.JWK.Algorithm)
+			assert.Len(t, key.JWK.KeyID, 43)
+		}
+	}
+
+	_, err = private.ParsePEM(&pem.Block{Type: "CERTIFICATE", Bytes: []byte{}})
+	assert.Error(t, err)
+	_, err = private.ParsePEM(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")})
+	assert.Error(t, err)
+}
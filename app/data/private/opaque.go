@@ -0,0 +1,73 @@
+package private
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+
+	"gopkg.in/square/go-jose.v2"
+)
+
+// opaqueSigner adapts a crypto.Signer to jose.OpaqueSigner.
+type opaqueSigner struct {
+	key *Key
+}
+
+func (o opaqueSigner) Public() *jose.JSONWebKey {
+	return &o.key.JWK
+}
+
+func (o opaqueSigner) Algs() []jose.SignatureAlgorithm {
+	return []jose.SignatureAlgorithm{o.key.Algorithm()}
+}
+
+func (o opaqueSigner) SignPayload(payload []byte, alg jose.SignatureAlgorithm) ([]byte, error) {
+	if alg == jose.EdDSA {
+		return o.key.PrivateKey.Sign(rand.Reader, payload, crypto.Hash(0))
+	}
+
+	var hash crypto.Hash
+	switch alg {
+	case jose.RS256, jose.ES256:
+		hash = crypto.SHA256
+	case jose.ES384:
+		hash = crypto.SHA384
+	case jose.ES512:
+		hash = crypto.SHA512
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", alg)
+	}
+	h := hash.New()
+	h.Write(payload)
+	sig, err := o.key.PrivateKey.Sign(rand.Reader, h.Sum(nil), hash)
+	if err != nil {
+		return nil, err
+	}
+
+	if pub, ok := o.key.Public().(*ecdsa.PublicKey); ok {
+		return jwsECDSASignature(sig, (pub.Curve.Params().BitSize+7)/8)
+	}
+	return sig, nil
+}
+
+// jwsECDSASignature converts an ASN.1 ECDSA signature, as from crypto.Signer, into the fixed size
+// r || s format of JWS (RFC 7518 section 3.4).
+func jwsECDSASignature(der []byte, size int) ([]byte, error) {
+	var sig struct {
+		R, S *big.Int
+	}
+	if _, err := asn1.Unmarshal(der, &sig); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 2*size)
+	rBytes, sBytes := sig.R.Bytes(), sig.S.Bytes()
+	if len(rBytes) > size || len(sBytes) > size {
+		return nil, fmt.Errorf("invalid ECDSA signature")
+	}
+	copy(out[size-len(rBytes):size], rBytes)
+	copy(out[2*size-len(sBytes):], sBytes)
+	return out, nil
+}
@@ -0,0 +1,14 @@
+package data
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+// RateLimiter limits actions with token buckets. A bucket holds up to limit tokens, and refills
+// at limit tokens per window.
+type RateLimiter interface {
+	// Take spends a token from the bucket for the key, if one is available.
+	Take(key string, limit int, window time.Duration) (*models.RateLimit, error)
+}
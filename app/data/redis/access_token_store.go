@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/keratin/authn-server/lib"
+)
+
+type AccessTokenStore struct {
+	*redis.Client
+}
+
+// NewAccessTokenStore creates an AccessTokenStore with expiring keys
+func NewAccessTokenStore(client *redis.Client) *AccessTokenStore {
+	return &AccessTokenStore{client}
+}
+
+// Redis key for an opaque access token
+func keyForAccessToken(token string) string {
+	return fmt.Sprintf("at:%s", token)
+}
+
+func (s *AccessTokenStore) Create(identityToken string, expiry time.Time) (string, error) {
+	binToken, err := lib.GenerateToken()
+	if err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(binToken)
+
+	// a token that has already expired is never stored, since redis would keep it forever
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return token, nil
+	}
+	err = s.Client.Set(keyForAccessToken(token), identityToken, ttl).Err()
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *AccessTokenStore) Find(token string) (string, error) {
+	identityToken, err := s.Client.Get(keyForAccessToken(token)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return identityToken, err
+}
+
+func (s *AccessTokenStore) Revoke(token string) error {
+	return s.Client.Del(keyForAccessToken(token)).Err()
+}
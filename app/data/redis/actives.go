@@ -5,10 +5,13 @@ import (
 	"time"
 
 	"github.com/go-redis/redis"
+	"github.com/keratin/authn-server/app/models"
 )
 
 var redisPrefix = "actives:"
 
+var signupsPrefix = "signups:"
+
 type actives struct {
 	client  *redis.Client
 	tz      *time.Location
@@ -32,21 +35,29 @@ func NewActives(client *redis.Client, tz *time.Location, days int, weeks int, mo
 }
 
 func (a *actives) Track(accountID int) error {
+	return a.add(redisPrefix, accountID)
+}
+
+func (a *actives) TrackSignup(accountID int) error {
+	return a.add(signupsPrefix, accountID)
+}
+
+func (a *actives) add(prefix string, accountID int) error {
 	t := time.Now().In(a.tz)
 	pipe := a.client.Pipeline()
 
 	// increment daily
-	dayKey := redisPrefix + dayKey(t)
+	dayKey := prefix + dayKey(t)
 	pipe.PFAdd(dayKey, accountID)
 	pipe.Expire(dayKey, a.dayTTL)
 
 	// increment weekly
-	weekKey := redisPrefix + weekKey(t)
+	weekKey := prefix + weekKey(t)
 	pipe.PFAdd(weekKey, accountID)
 	pipe.Expire(weekKey, a.weekTTL)
 
 	// increment monthly
-	monthKey := redisPrefix + monthKey(t)
+	monthKey := prefix + monthKey(t)
 	pipe.PFAdd(monthKey, accountID)
 
 	_, err := pipe.Exec()
@@ -86,17 +97,38 @@ func (a *actives) ActivesByMonth() (map[string]int, error) {
 	return a.report(months)
 }
 
-func (a *actives) report(keys []string) (map[string]int, error) {
-	pipe := a.client.Pipeline()
+func (a *actives) ActivesBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error) {
+	return a.between(redisPrefix, period, from, to)
+}
 
-	// construct requests
-	metrics := make([]metric, len(keys))
-	for i := range metrics {
-		metrics[i] = newMetric(pipe, keys[i])
+func (a *actives) SignupsBetween(period models.Period, from time.Time, to time.Time) (map[string]int, error) {
+	return a.between(signupsPrefix, period, from, to)
+}
+
+func (a *actives) between(prefix string, period models.Period, from time.Time, to time.Time) (map[string]int, error) {
+	label := periodKey(period)
+	starts := models.PeriodStarts(period, from.In(a.tz), to.In(a.tz))
+
+	keys := make([]string, len(starts))
+	for i, t := range starts {
+		keys[i] = label(t)
 	}
 
-	// to redis
-	_, err := pipe.Exec()
+	metrics, err := a.count(prefix, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	report := make(map[string]int, len(keys))
+	for _, m := range metrics {
+		report[m.label] = m.val()
+	}
+
+	return report, nil
+}
+
+func (a *actives) report(keys []string) (map[string]int, error) {
+	metrics, err := a.count(redisPrefix, keys)
 	if err != nil {
 		return nil, err
 	}
@@ -110,6 +142,24 @@ func (a *actives) report(keys []string) (map[string]int, error) {
 	return report, nil
 }
 
+func (a *actives) count(prefix string, keys []string) ([]metric, error) {
+	pipe := a.client.Pipeline()
+
+	// construct requests
+	metrics := make([]metric, len(keys))
+	for i := range metrics {
+		metrics[i] = newMetric(pipe, prefix, keys[i])
+	}
+
+	// to redis
+	_, err := pipe.Exec()
+	if err != nil {
+		return nil, err
+	}
+
+	return metrics, nil
+}
+
 //-- METRIC
 
 type metric struct {
@@ -117,8 +167,8 @@ type metric struct {
 	future *redis.IntCmd
 }
 
-func newMetric(pipe redis.Pipeliner, key string) metric {
-	return metric{key, pipe.PFCount(redisPrefix + key)}
+func newMetric(pipe redis.Pipeliner, prefix string, key string) metric {
+	return metric{key, pipe.PFCount(prefix + key)}
 }
 
 func (m metric) val() int {
@@ -140,6 +190,17 @@ func monthKey(t time.Time) string {
 	return t.Format("2006-01") // %Y-%m
 }
 
+func periodKey(period models.Period) func(time.Time) string {
+	switch period {
+	case models.Weekly:
+		return weekKey
+	case models.Monthly:
+		return monthKey
+	default:
+		return dayKey
+	}
+}
+
 // takes an ordered list of metrics and slices out the oldest entries that are zeroes
 func trim(metrics []metric) []metric {
 	lastNonZeroIndex := -1
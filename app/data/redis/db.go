@@ -1,15 +1,144 @@
 package redis
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
 )
 
+// SentinelScheme identifies a URL for a Redis master discovered through Sentinel:
+//
+//	redis+sentinel://:password@sentinel1:26379,sentinel2:26379/mymaster/11
+//
+// The path names the master and optionally the database. The password is for the master.
+const SentinelScheme = "redis+sentinel"
+
 func New(url *url.URL) (*redis.Client, error) {
-	return newFromString(url.String())
+	if url.Scheme == SentinelScheme {
+		opts, err := ParseSentinelURL(url)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(opts), nil
+	}
+	opts, err := ParseURL(url)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(opts), nil
+}
+
+// ParseURL builds client options from a redis:// or rediss:// URL. A rediss:// URL connects with
+// TLS and may specify certificate files in the query:
+//
+//	rediss://:password@redis.example.com:6380/0?ca_cert=/etc/ssl/redis-ca.pem
+//
+// * ca_cert: PEM file of certificate authorities that sign the server certificate
+// * client_cert and client_key: PEM files of a client certificate and its key
+func ParseURL(u *url.URL) (*redis.Options, error) {
+	query := u.Query()
+	bare := *u
+	bare.RawQuery = ""
+	opts, err := redis.ParseURL(bare.String())
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		if opts.TLSConfig == nil {
+			return nil, fmt.Errorf("certificate options require a rediss:// URL")
+		}
+		if err := configureTLS(opts.TLSConfig, query); err != nil {
+			return nil, err
+		}
+	}
+	return opts, nil
+}
+
+func configureTLS(cfg *tls.Config, query url.Values) error {
+	for name := range query {
+		if name != "ca_cert" && name != "client_cert" && name != "client_key" {
+			return fmt.Errorf("unsupported redis URL option: %s", name)
+		}
+	}
+
+	if path := query.Get("ca_cert"); path != "" {
+		pem, err := ioutil.ReadFile(path)
+		if err != nil {
+			return errors.Wrap(err, "ca_cert")
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("ca_cert: no certificates found in %s", path)
+		}
+	}
+
+	certPath, keyPath := query.Get("client_cert"), query.Get("client_key")
+	if certPath != "" || keyPath != "" {
+		if certPath == "" || keyPath == "" {
+			return fmt.Errorf("client_cert and client_key must be specified together")
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return errors.Wrap(err, "client_cert")
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return nil
+}
+
+// ParseSentinelURL builds failover options from a redis+sentinel:// URL.
+func ParseSentinelURL(u *url.URL) (*redis.FailoverOptions, error) {
+	if u.Scheme != SentinelScheme {
+		return nil, fmt.Errorf("invalid redis sentinel URL scheme: %s", u.Scheme)
+	}
+
+	opts := &redis.FailoverOptions{}
+	for _, addr := range strings.Split(u.Host, ",") {
+		if addr == "" {
+			continue
+		}
+		if !strings.Contains(addr, ":") {
+			addr = addr + ":26379"
+		}
+		opts.SentinelAddrs = append(opts.SentinelAddrs, addr)
+	}
+	if len(opts.SentinelAddrs) == 0 {
+		return nil, fmt.Errorf("missing sentinel hosts in redis sentinel URL")
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if parts[0] == "" {
+		return nil, fmt.Errorf("missing master name in redis sentinel URL")
+	}
+	opts.MasterName = parts[0]
+	switch len(parts) {
+	case 1:
+	case 2:
+		db, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis database number: %q", parts[1])
+		}
+		opts.DB = db
+	default:
+		return nil, fmt.Errorf("invalid redis sentinel URL path: %s", u.Path)
+	}
+
+	if u.User != nil {
+		if p, ok := u.User.Password(); ok {
+			opts.Password = p
+		}
+	}
+
+	return opts, nil
 }
 
 // TODO: move to _test
@@ -21,10 +150,10 @@ func TestDB() (*redis.Client, error) {
 	return newFromString(str)
 }
 
-func newFromString(url string) (*redis.Client, error) {
-	cfg, err := redis.ParseURL(url)
+func newFromString(str string) (*redis.Client, error) {
+	u, err := url.Parse(str)
 	if err != nil {
 		return nil, err
 	}
-	return redis.NewClient(cfg), nil
+	return New(u)
 }
@@ -0,0 +1,116 @@
+package redis_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSentinelURL(t *testing.T) {
+	u, err := url.Parse("redis+sentinel://:secret@sentinel1:26380,sentinel2:26381/mymaster/11")
+	require.NoError(t, err)
+	opts, err := redis.ParseSentinelURL(u)
+	require.NoError(t, err)
+	assert.Equal(t, "mymaster", opts.MasterName)
+	assert.Equal(t, []string{"sentinel1:26380", "sentinel2:26381"}, opts.SentinelAddrs)
+	assert.Equal(t, 11, opts.DB)
+	assert.Equal(t, "secret", opts.Password)
+
+	u, err = url.Parse("redis+sentinel://sentinel1/mymaster")
+	require.NoError(t, err)
+	opts, err = redis.ParseSentinelURL(u)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sentinel1:26379"}, opts.SentinelAddrs)
+	assert.Equal(t, 0, opts.DB)
+	assert.Equal(t, "", opts.Password)
+
+	for _, str := range []string{
+		"redis+sentinel://sentinel1",
+		"redis+sentinel://sentinel1/",
+		"redis+sentinel:///mymaster",
+		"redis+sentinel://sentinel1/mymaster/db",
+		"redis+sentinel://sentinel1/mymaster/1/2",
+		"redis://localhost/mymaster",
+	} {
+		u, err := url.Parse(str)
+		require.NoError(t, err)
+		_, err = redis.ParseSentinelURL(u)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestParseURL(t *testing.T) {
+	certPath, keyPath := writeCert(t)
+	defer os.RemoveAll(filepath.Dir(certPath))
+
+	u, err := url.Parse("rediss://:secret@redis.example.com:6380/2?ca_cert=" + certPath + "&client_cert=" + certPath + "&client_key=" + keyPath)
+	require.NoError(t, err)
+	opts, err := redis.ParseURL(u)
+	require.NoError(t, err)
+	assert.Equal(t, "redis.example.com:6380", opts.Addr)
+	assert.Equal(t, "secret", opts.Password)
+	assert.Equal(t, 2, opts.DB)
+	require.NotNil(t, opts.TLSConfig)
+	assert.Equal(t, "redis.example.com", opts.TLSConfig.ServerName)
+	assert.NotNil(t, opts.TLSConfig.RootCAs)
+	assert.Len(t, opts.TLSConfig.Certificates, 1)
+
+	u, err = url.Parse("rediss://redis.example.com")
+	require.NoError(t, err)
+	opts, err = redis.ParseURL(u)
+	require.NoError(t, err)
+	assert.Nil(t, opts.TLSConfig.RootCAs)
+
+	for _, str := range []string{
+		"redis://redis.example.com?ca_cert=" + certPath,
+		"rediss://redis.example.com?ca_cert=/does/not/exist",
+		"rediss://redis.example.com?ca_cert=" + keyPath,
+		"rediss://redis.example.com?client_cert=" + certPath,
+		"rediss://redis.example.com?timeout=5",
+		"mysql://redis.example.com",
+	} {
+		u, err := url.Parse(str)
+		require.NoError(t, err)
+		_, err = redis.ParseURL(u)
+		assert.Error(t, err, str)
+	}
+}
+
+// writeCert writes a self-signed certificate and its key to a temporary directory.
+func writeCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "redis.example.com"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "authn-redis")
+	require.NoError(t, err)
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certPath, keyPath
+}
@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// Redis keys for the schedule (a sorted set of delivery IDs, by time due) and the deliveries (a
+// hash of JSON, by ID)
+const (
+	eventScheduleKey   = "events:schedule"
+	eventDeliveriesKey = "events:deliveries"
+)
+
+type EventQueue struct {
+	*redis.Client
+}
+
+// NewEventQueue creates an EventQueue that is shared by every AuthN server using the Redis.
+func NewEventQueue(client *redis.Client) *EventQueue {
+	return &EventQueue{client}
+}
+
+// claimEvent finds a delivery that is due and reschedules it for when the lease expires.
+//
+// KEYS[1]: schedule, KEYS[2]: deliveries, ARGV[1]: now in ms, ARGV[2]: lease in ms
+var claimEvent = redis.NewScript(`
+local due = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)
+if #due == 0 then
+	return false
+end
+redis.call("ZADD", KEYS[1], tonumber(ARGV[1]) + tonumber(ARGV[2]), due[1])
+return redis.call("HGET", KEYS[2], due[1])
+`)
+
+func (q *EventQueue) Schedule(delivery *models.EventDelivery, at time.Time) error {
+	val, err := json.Marshal(delivery)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	_, err = q.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(eventDeliveriesKey, delivery.ID, val)
+		pipe.ZAdd(eventScheduleKey, redis.Z{Score: float64(toMillis(at)), Member: delivery.ID})
+		return nil
+	})
+	return err
+}
+
+func (q *EventQueue) Claim(lease time.Duration) (*models.EventDelivery, error) {
+	now := toMillis(time.Now())
+	res, err := claimEvent.Run(q.Client, []string{eventScheduleKey, eventDeliveriesKey}, now, int64(lease/time.Millisecond)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	delivery := models.EventDelivery{}
+	err = json.Unmarshal([]byte(res.(string)), &delivery)
+	if err != nil {
+		return nil, errors.Wrap(err, "Unmarshal")
+	}
+	return &delivery, nil
+}
+
+func (q *EventQueue) Ack(id string) error {
+	_, err := q.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.ZRem(eventScheduleKey, id)
+		pipe.HDel(eventDeliveriesKey, id)
+		return nil
+	})
+	return err
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
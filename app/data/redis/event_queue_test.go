@@ -0,0 +1,19 @@
+package redis_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventQueue(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	q := redis.NewEventQueue(client)
+	for _, tester := range testers.EventQueueTesters {
+		client.FlushDB()
+		tester(t, q)
+	}
+}
@@ -0,0 +1,139 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+type FailedLogins struct {
+	*redis.Client
+	window time.Duration
+}
+
+// NewFailedLogins creates a FailedLogins with keys that expire after the window since the first
+// failure.
+func NewFailedLogins(client *redis.Client, window time.Duration) *FailedLogins {
+	return &FailedLogins{client, window}
+}
+
+// Redis key for counting a username's failures
+func keyForUsernameFailures(username string) string {
+	return "failed-logins:username:" + username
+}
+
+// Redis key for a hash that counts a username's failures from each IP, with the time of the last
+// failure from each IP
+func keyForPairFailures(username string) string {
+	return "failed-logins:pairs:" + username
+}
+
+// Redis key for counting an IP's failures
+func keyForIPFailures(ip string) string {
+	return "failed-logins:ip:" + ip
+}
+
+// Redis key for an account's lock
+func keyForLock(accountID int) string {
+	return "failed-logins:lock:" + strconv.Itoa(accountID)
+}
+
+func (s *FailedLogins) Add(username string, ip string) (int, int, error) {
+	usernameFailures, err := s.incr(keyForUsernameFailures(username))
+	if err != nil {
+		return 0, 0, err
+	}
+	ipFailures, err := s.incr(keyForIPFailures(ip))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pipe := s.Client.TxPipeline()
+	pipe.HIncrBy(keyForPairFailures(username), ip, 1)
+	pipe.HSet(keyForPairFailures(username), ip+":at", time.Now().UnixNano())
+	pipe.Expire(keyForPairFailures(username), s.window)
+	_, err = pipe.Exec()
+	if err != nil {
+		return 0, 0, err
+	}
+	return usernameFailures, ipFailures, nil
+}
+
+func (s *FailedLogins) CountIP(ip string) (int, time.Duration, error) {
+	failures, err := s.Client.Get(keyForIPFailures(ip)).Int64()
+	if err == redis.Nil {
+		return 0, 0, nil
+	} else if err != nil {
+		return 0, 0, err
+	}
+	ttl, err := s.ttl(keyForIPFailures(ip))
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(failures), ttl, nil
+}
+
+func (s *FailedLogins) CountPair(username string, ip string) (int, time.Time, error) {
+	vals, err := s.Client.HMGet(keyForPairFailures(username), ip, ip+":at").Result()
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	failures, _ := vals[0].(string)
+	at, _ := vals[1].(string)
+	if failures == "" || at == "" {
+		return 0, time.Time{}, nil
+	}
+
+	count, err := strconv.Atoi(failures)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	nanos, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return count, time.Unix(0, nanos), nil
+}
+
+func (s *FailedLogins) Reset(username string) error {
+	return s.Client.Del(keyForUsernameFailures(username), keyForPairFailures(username)).Err()
+}
+
+func (s *FailedLogins) Lock(accountID int, ttl time.Duration) error {
+	return s.Client.Set(keyForLock(accountID), 1, ttl).Err()
+}
+
+func (s *FailedLogins) LockedFor(accountID int) (time.Duration, error) {
+	return s.ttl(keyForLock(accountID))
+}
+
+func (s *FailedLogins) Unlock(accountID int) error {
+	return s.Client.Del(keyForLock(accountID)).Err()
+}
+
+// ttl returns how long until the key expires, or zero if it does not exist
+func (s *FailedLogins) ttl(key string) (time.Duration, error) {
+	ttl, err := s.Client.PTTL(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+func (s *FailedLogins) incr(key string) (int, error) {
+	failures, err := s.Client.Incr(key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if failures == 1 {
+		err = s.Client.Expire(key, s.window).Err()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return int(failures), nil
+}
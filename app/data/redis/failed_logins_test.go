@@ -0,0 +1,20 @@
+package redis_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedLogins(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	store := redis.NewFailedLogins(client, time.Minute)
+	for _, tester := range testers.FailedLoginsTesters {
+		client.FlushDB()
+		tester(t, store)
+	}
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/keratin/authn-server/app/models"
+)
+
+// claimTTL forgets a reservation that was never saved or released, as when a server stops
+// during a request
+const claimTTL = time.Minute
+
+// claimed is the value of a key that is reserved by a request in progress
+const claimed = "claimed"
+
+type IdempotencyStore struct {
+	*redis.Client
+}
+
+// NewIdempotencyStore creates an IdempotencyStore that keeps each response as JSON.
+func NewIdempotencyStore(client *redis.Client) *IdempotencyStore {
+	return &IdempotencyStore{client}
+}
+
+// Redis key for an Idempotency-Key
+func keyForIdempotency(key string) string {
+	return "idempotency:" + key
+}
+
+func (s *IdempotencyStore) Claim(key string) (*models.IdempotentResponse, bool, error) {
+	ok, err := s.Client.SetNX(keyForIdempotency(key), claimed, claimTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	val, err := s.Client.Get(keyForIdempotency(key)).Result()
+	if err == redis.Nil {
+		// released or expired since the SETNX
+		return s.Claim(key)
+	} else if err != nil {
+		return nil, false, err
+	}
+	if val == claimed {
+		return nil, false, nil
+	}
+
+	res := models.IdempotentResponse{}
+	err = json.Unmarshal([]byte(val), &res)
+	if err != nil {
+		return nil, false, err
+	}
+	return &res, true, nil
+}
+
+func (s *IdempotencyStore) Save(key string, res *models.IdempotentResponse, ttl time.Duration) error {
+	val, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	return s.Client.Set(keyForIdempotency(key), val, ttl).Err()
+}
+
+func (s *IdempotencyStore) Release(key string) error {
+	return s.Client.Del(keyForIdempotency(key)).Err()
+}
@@ -0,0 +1,19 @@
+package redis_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	store := redis.NewIdempotencyStore(client)
+	for _, tester := range testers.IdempotencyStoreTesters {
+		client.FlushDB()
+		tester(t, store)
+	}
+}
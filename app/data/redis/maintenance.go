@@ -0,0 +1,44 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Redis key for the maintenance switch
+const keyForMaintenance = "maintenance"
+
+type Maintenance struct {
+	*redis.Client
+}
+
+// NewMaintenance creates a Maintenance switch that is shared by every server using the Redis. The
+// key does not expire, so that maintenance is not ended early when it runs long.
+func NewMaintenance(client *redis.Client) *Maintenance {
+	return &Maintenance{client}
+}
+
+func (m *Maintenance) Enable(until time.Time) error {
+	return m.Client.Set(keyForMaintenance, until.Unix(), 0).Err()
+}
+
+func (m *Maintenance) Disable() error {
+	return m.Client.Del(keyForMaintenance).Err()
+}
+
+func (m *Maintenance) Until() (time.Time, error) {
+	val, err := m.Client.Get(keyForMaintenance).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+
+	unix, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
@@ -0,0 +1,80 @@
+package redis
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+type OTPStore struct {
+	*redis.Client
+	maxSends    int
+	sendWindow  time.Duration
+	maxAttempts int
+}
+
+// NewOTPStore creates an OTPStore with expiring keys. Accounts may be sent maxSends codes per
+// sendWindow, and each code may be attempted maxAttempts times.
+func NewOTPStore(client *redis.Client, maxSends int, sendWindow time.Duration, maxAttempts int) *OTPStore {
+	return &OTPStore{client, maxSends, sendWindow, maxAttempts}
+}
+
+// Redis key for an account's delivered code and failed attempts
+func keyForOTP(accountID int) string {
+	return fmt.Sprintf("otp:%d", accountID)
+}
+
+// Redis key for counting an account's deliveries
+func keyForOTPSends(accountID int) string {
+	return fmt.Sprintf("otp-sends:%d", accountID)
+}
+
+func (s *OTPStore) Create(accountID int, code string, ttl time.Duration) (bool, error) {
+	sends, err := s.Client.Incr(keyForOTPSends(accountID)).Result()
+	if err != nil {
+		return false, err
+	}
+	if sends == 1 {
+		err = s.Client.Expire(keyForOTPSends(accountID), s.sendWindow).Err()
+		if err != nil {
+			return false, err
+		}
+	}
+	if sends > int64(s.maxSends) {
+		return false, nil
+	}
+
+	_, err = s.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(keyForOTP(accountID))
+		pipe.HMSet(keyForOTP(accountID), map[string]interface{}{"code": code, "attempts": 0})
+		pipe.Expire(keyForOTP(accountID), ttl)
+		return nil
+	})
+	return err == nil, err
+}
+
+func (s *OTPStore) Verify(accountID int, code string) (bool, error) {
+	var attempts *redis.IntCmd
+	var found *redis.StringCmd
+	_, err := s.Client.TxPipelined(func(pipe redis.Pipeliner) error {
+		attempts = pipe.HIncrBy(keyForOTP(accountID), "attempts", 1)
+		found = pipe.HGet(keyForOTP(accountID), "code")
+		return nil
+	})
+	if err == redis.Nil {
+		// counting attempts on an expired code recreates the key, which must not be kept
+		return false, s.Client.Del(keyForOTP(accountID)).Err()
+	} else if err != nil {
+		return false, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(found.Val()), []byte(code)) == 1 {
+		return true, s.Client.Del(keyForOTP(accountID)).Err()
+	}
+	if attempts.Val() >= int64(s.maxAttempts) {
+		return false, s.Client.Del(keyForOTP(accountID)).Err()
+	}
+	return false, nil
+}
@@ -0,0 +1,20 @@
+package redis_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPStore(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	store := redis.NewOTPStore(client, data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts)
+	for _, tester := range testers.OTPStoreTesters {
+		client.FlushDB()
+		tester(t, store)
+	}
+}
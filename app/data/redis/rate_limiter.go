@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/keratin/authn-server/app/models"
+)
+
+type RateLimiter struct {
+	*redis.Client
+}
+
+// NewRateLimiter creates a RateLimiter that keeps each bucket in a Redis hash, which expires once
+// the bucket would be full again.
+func NewRateLimiter(client *redis.Client) *RateLimiter {
+	return &RateLimiter{client}
+}
+
+// Redis key for a bucket
+func keyForRateLimit(key string) string {
+	return "rate-limit:" + key
+}
+
+// takeToken refills a bucket for the time since it was last seen, then spends a token if one is
+// available. It returns 1 if a token was spent, and the tokens that remain (as a string, since Lua
+// numbers are truncated in replies).
+//
+// KEYS[1]: bucket, ARGV[1]: limit, ARGV[2]: window in ms, ARGV[3]: now in ms
+var takeToken = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "at")
+local tokens = tonumber(bucket[1])
+local at = tonumber(bucket[2])
+if tokens == nil or at == nil then
+	tokens = limit
+	at = now
+end
+tokens = math.min(limit, tokens + math.max(0, now - at) * limit / window)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HMSET", KEYS[1], "tokens", tostring(tokens), "at", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.max(1, math.ceil((limit - tokens) * window / limit)))
+return {allowed, tostring(tokens)}
+`)
+
+func (s *RateLimiter) Take(key string, limit int, window time.Duration) (*models.RateLimit, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	res, err := takeToken.Run(s.Client, []string{keyForRateLimit(key)}, limit, int64(window/time.Millisecond), now).Result()
+	if err != nil {
+		return nil, err
+	}
+	vals := res.([]interface{})
+	tokens, err := strconv.ParseFloat(vals[1].(string), 64)
+	if err != nil {
+		return nil, err
+	}
+	return models.NewRateLimit(vals[0].(int64) == 1, tokens, limit, window), nil
+}
@@ -14,6 +14,8 @@ import (
 type RefreshTokenStore struct {
 	*redis.Client
 	TTL time.Duration
+	// MaxLifetime limits how long a token may be touched after it is created. Zero is unlimited.
+	MaxLifetime time.Duration
 }
 
 // Redis key for token => accountID lookup
@@ -22,12 +24,30 @@ func keyForToken(t []byte) string {
 	return str
 }
 
+// Redis key that expires when the token may no longer be touched
+func keyForDeadline(t []byte) string {
+	str := fmt.Sprintf("s:d.%s", t)
+	return str
+}
+
+// Redis key for token => session description
+func keyForSession(t []byte) string {
+	str := fmt.Sprintf("s:m.%s", t)
+	return str
+}
+
 // Redis key for accountID => tokens lookup
 func keyForAccount(id int) string {
 	str := fmt.Sprintf("s:a.%d", id)
 	return str
 }
 
+// Redis key for accountID => tokens sorted by creation
+func keyForAccountOrder(id int) string {
+	str := fmt.Sprintf("s:o.%d", id)
+	return str
+}
+
 func (s *RefreshTokenStore) Find(hexToken models.RefreshToken) (int, error) {
 	binToken, err := hex.DecodeString(string(hexToken))
 	if err != nil {
@@ -43,46 +63,197 @@ func (s *RefreshTokenStore) Find(hexToken models.RefreshToken) (int, error) {
 }
 
 func (s *RefreshTokenStore) Touch(hexToken models.RefreshToken, accountID int) error {
+	return s.TouchFor(hexToken, accountID, s.TTL)
+}
+
+func (s *RefreshTokenStore) TouchFor(hexToken models.RefreshToken, accountID int, ttl time.Duration) error {
 	binToken, err := hex.DecodeString(string(hexToken))
 	if err != nil {
 		return err
 	}
 
+	if s.MaxLifetime > 0 {
+		// tokens created without a deadline are given one, in case MaxLifetime has since been
+		// configured
+		var remaining *redis.DurationCmd
+		_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
+			pipe.SetNX(keyForDeadline(binToken), 1, s.MaxLifetime)
+			remaining = pipe.PTTL(keyForDeadline(binToken))
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if remaining.Val() < ttl {
+			ttl = remaining.Val()
+		}
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
 	_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
-		pipe.Expire(keyForToken(binToken), s.TTL)
+		pipe.Expire(keyForToken(binToken), ttl)
+		pipe.HSet(keyForSession(binToken), "last_seen_at", time.Now().Unix())
+		pipe.Expire(keyForSession(binToken), ttl)
 		pipe.Expire(keyForAccount(accountID), s.TTL)
+		pipe.Expire(keyForAccountOrder(accountID), s.TTL)
 		return nil
 	})
 	return err
 }
 
+// FindAll sorts tokens by creation. Tokens that were created before the sorted set existed are
+// the oldest. The set of tokens may include expired tokens, which are skipped.
 func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
-	bins, err := s.Client.SMembers(keyForAccount(accountID)).Result()
+	var members *redis.StringSliceCmd
+	var ordered *redis.StringSliceCmd
+	_, err := s.Client.Pipelined(func(pipe redis.Pipeliner) error {
+		members = pipe.SMembers(keyForAccount(accountID))
+		ordered = pipe.ZRange(keyForAccountOrder(accountID), 0, -1)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	isMember := map[string]bool{}
+	for _, t := range members.Val() {
+		isMember[t] = true
+	}
+	isOrdered := map[string]bool{}
+	for _, t := range ordered.Val() {
+		if isMember[t] {
+			isOrdered[t] = true
+		}
+	}
+	var bins []string
+	for _, t := range members.Val() {
+		if !isOrdered[t] {
+			bins = append(bins, t)
+		}
+	}
+	for _, t := range ordered.Val() {
+		if isOrdered[t] {
+			bins = append(bins, t)
+		}
+	}
+
+	exists := make([]*redis.IntCmd, len(bins))
+	_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, t := range bins {
+			exists[i] = pipe.Exists(keyForToken([]byte(t)))
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	tokens := make([]models.RefreshToken, 0)
-	for _, t := range bins {
-		tokens = append(tokens, models.RefreshToken(hex.EncodeToString([]byte(t))))
+	for i, t := range bins {
+		if exists[i].Val() > 0 {
+			tokens = append(tokens, models.RefreshToken(hex.EncodeToString([]byte(t))))
+		}
 	}
 
 	return tokens, nil
 }
 
+// FindSessions describes the tokens from FindAll. Times are missing for tokens that were created
+// before sessions were described.
+func (s *RefreshTokenStore) FindSessions(accountID int) ([]models.Session, error) {
+	tokens, err := s.FindAll(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*redis.StringStringMapCmd, len(tokens))
+	_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, t := range tokens {
+			binToken, err := hex.DecodeString(string(t))
+			if err != nil {
+				return err
+			}
+			fields[i] = pipe.HGetAll(keyForSession(binToken))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.Session, len(tokens))
+	for i, t := range tokens {
+		vals := fields[i].Val()
+		sessions[i] = models.Session{
+			Token:      t,
+			UserAgent:  vals["user_agent"],
+			CreatedAt:  parseUnix(vals["created_at"]),
+			LastSeenAt: parseUnix(vals["last_seen_at"]),
+		}
+	}
+	return sessions, nil
+}
+
+// parseUnix returns nil for missing or invalid times
+func parseUnix(str string) *time.Time {
+	sec, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
+
+// SetUserAgent describes the session for as long as the token lives.
+func (s *RefreshTokenStore) SetUserAgent(hexToken models.RefreshToken, userAgent string) error {
+	binToken, err := hex.DecodeString(string(hexToken))
+	if err != nil {
+		return err
+	}
+	ttl, err := s.Client.PTTL(keyForToken(binToken)).Result()
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		return nil
+	}
+
+	_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
+		pipe.HSet(keyForSession(binToken), "user_agent", userAgent)
+		pipe.PExpire(keyForSession(binToken), ttl)
+		return nil
+	})
+	return err
+}
+
 func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	binToken, err := lib.GenerateToken()
 	if err != nil {
 		return "", err
 	}
 
+	ttl := s.TTL
+	if s.MaxLifetime > 0 && s.MaxLifetime < ttl {
+		ttl = s.MaxLifetime
+	}
+
 	_, err = s.Client.Pipelined(func(pipe redis.Pipeliner) error {
 		// persist the token
-		pipe.Set(keyForToken(binToken), accountID, s.TTL)
+		pipe.Set(keyForToken(binToken), accountID, ttl)
+		if s.MaxLifetime > 0 {
+			pipe.Set(keyForDeadline(binToken), 1, s.MaxLifetime)
+		}
+		now := time.Now().Unix()
+		pipe.HMSet(keyForSession(binToken), map[string]interface{}{"created_at": now, "last_seen_at": now})
+		pipe.Expire(keyForSession(binToken), ttl)
 
-		// maintain a list of tokens per accountID
+		// maintain a list of tokens per accountID, and their order
 		pipe.SAdd(keyForAccount(accountID), binToken)
 		pipe.Expire(keyForAccount(accountID), s.TTL)
+		pipe.ZAdd(keyForAccountOrder(accountID), redis.Z{Score: float64(time.Now().UnixNano() / 1000), Member: binToken})
+		pipe.Expire(keyForAccountOrder(accountID), s.TTL)
 
 		return nil
 	})
@@ -108,8 +279,9 @@ func (s *RefreshTokenStore) Revoke(hexToken models.RefreshToken) error {
 			return err
 		}
 
-		pipe.Del(keyForToken(binToken))
+		pipe.Del(keyForToken(binToken), keyForDeadline(binToken), keyForSession(binToken))
 		pipe.SRem(keyForAccount(accountID), binToken)
+		pipe.ZRem(keyForAccountOrder(accountID), binToken)
 
 		return nil
 	})
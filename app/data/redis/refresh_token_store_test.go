@@ -1,11 +1,14 @@
 package redis_test
 
 import (
+	"encoding/hex"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/keratin/authn-server/app/data/redis"
 	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -18,3 +21,51 @@ func TestRefreshTokenStore(t *testing.T) {
 		store.FlushDB()
 	}
 }
+
+func TestRefreshTokenStoreMaxLifetime(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	defer client.FlushDB()
+	store := &redis.RefreshTokenStore{Client: client, TTL: time.Hour, MaxLifetime: time.Minute}
+
+	token, err := store.Create(1)
+	require.NoError(t, err)
+	binToken, err := hex.DecodeString(string(token))
+	require.NoError(t, err)
+	key := fmt.Sprintf("s:t.%s", binToken)
+	assert.True(t, client.PTTL(key).Val() <= time.Minute)
+
+	err = store.Touch(token, 1)
+	require.NoError(t, err)
+	assert.True(t, client.PTTL(key).Val() <= time.Minute)
+
+	// tokens created without a max lifetime are limited once touched
+	unlimited := &redis.RefreshTokenStore{Client: client, TTL: time.Hour}
+	token, err = unlimited.Create(1)
+	require.NoError(t, err)
+	binToken, err = hex.DecodeString(string(token))
+	require.NoError(t, err)
+	key = fmt.Sprintf("s:t.%s", binToken)
+	assert.True(t, client.PTTL(key).Val() > time.Minute)
+
+	err = store.Touch(token, 1)
+	require.NoError(t, err)
+	assert.True(t, client.PTTL(key).Val() <= time.Minute)
+}
+
+func TestRefreshTokenStoreTouchFor(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	defer client.FlushDB()
+	store := &redis.RefreshTokenStore{Client: client, TTL: time.Hour}
+
+	token, err := store.Create(1)
+	require.NoError(t, err)
+	binToken, err := hex.DecodeString(string(token))
+	require.NoError(t, err)
+	key := fmt.Sprintf("s:t.%s", binToken)
+
+	err = store.TouchFor(token, 1, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, client.PTTL(key).Val() <= time.Minute)
+}
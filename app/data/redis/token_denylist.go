@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+type TokenDenylist struct {
+	*redis.Client
+}
+
+// NewTokenDenylist creates a TokenDenylist with expiring keys
+func NewTokenDenylist(client *redis.Client) *TokenDenylist {
+	return &TokenDenylist{client}
+}
+
+// Redis key for a denied jti
+func keyForDenied(jti string) string {
+	return fmt.Sprintf("denied:%s", jti)
+}
+
+func (d *TokenDenylist) Deny(jti string, expiry time.Time) error {
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return nil
+	}
+	return d.Client.Set(keyForDenied(jti), "1", ttl).Err()
+}
+
+func (d *TokenDenylist) Spend(jti string, expiry time.Time) (bool, error) {
+	ttl := time.Until(expiry)
+	if ttl <= 0 {
+		return false, nil
+	}
+	return d.Client.SetNX(keyForDenied(jti), "1", ttl).Result()
+}
+
+func (d *TokenDenylist) Allow(jti string) error {
+	return d.Client.Del(keyForDenied(jti)).Err()
+}
+
+func (d *TokenDenylist) IsDenied(jti string) (bool, error) {
+	n, err := d.Client.Exists(keyForDenied(jti)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
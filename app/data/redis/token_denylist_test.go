@@ -0,0 +1,19 @@
+package redis_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/redis"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenDenylist(t *testing.T) {
+	client, err := redis.TestDB()
+	require.NoError(t, err)
+	denylist := redis.NewTokenDenylist(client)
+	for _, tester := range testers.TokenDenylistTesters {
+		client.FlushDB()
+		tester(t, denylist)
+	}
+}
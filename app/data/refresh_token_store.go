@@ -2,6 +2,7 @@ package data
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/keratin/authn-server/ops"
@@ -28,27 +29,69 @@ type RefreshTokenStore interface {
 	// important since touching can be a high traffic activity.
 	Touch(t models.RefreshToken, accountID int) error
 
-	// Returns all tokens that are active for the specified account.
+	// Refreshes the lifetime of the token like Touch, but for a ttl other than the store's. This may
+	// also shorten the token's lifetime.
+	TouchFor(t models.RefreshToken, accountID int, ttl time.Duration) error
+
+	// Returns all tokens that are active for the specified account, oldest first.
 	FindAll(accountID int) ([]models.RefreshToken, error)
 
+	// Records the user agent of the client that the token was created for.
+	SetUserAgent(t models.RefreshToken, userAgent string) error
+
+	// Returns descriptions of all tokens that are active for the specified account, oldest first.
+	FindSessions(accountID int) ([]models.Session, error)
+
 	// Revokes the token and removes it from the set of active tokens for the account. Doesn't error
 	// if the token is unknown or already revoked.
 	Revoke(t models.RefreshToken) error
 }
 
-func NewRefreshTokenStore(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorReporter, ttl time.Duration) (RefreshTokenStore, error) {
+// RefreshTokenStoreFactory builds a RefreshTokenStore with the arguments given to
+// NewRefreshTokenStore. redis is nil unless REDIS_URL is configured.
+type RefreshTokenStoreFactory func(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorReporter, ttl time.Duration, maxLifetime time.Duration) (RefreshTokenStore, error)
+
+var (
+	refreshTokenStoresMu sync.RWMutex
+	refreshTokenStores   = map[string]RefreshTokenStoreFactory{}
+)
+
+// RegisterRefreshTokenStore makes NewRefreshTokenStore use the factory for connections with the
+// given driver name (e.g. "postgres"), in place of the built-in Redis or SQL store. Register stores
+// from an init function, before calling app.NewApp.
+func RegisterRefreshTokenStore(driver string, factory RefreshTokenStoreFactory) {
+	refreshTokenStoresMu.Lock()
+	defer refreshTokenStoresMu.Unlock()
+	if factory == nil {
+		panic("data: RegisterRefreshTokenStore factory is nil")
+	}
+	refreshTokenStores[driver] = factory
+}
+
+// NewRefreshTokenStore creates a store for tokens that expire after ttl without activity, and
+// after maxLifetime (when nonzero) regardless of activity.
+func NewRefreshTokenStore(db *sqlx.DB, redis *redis.Client, reporter ops.ErrorReporter, ttl time.Duration, maxLifetime time.Duration) (RefreshTokenStore, error) {
+	refreshTokenStoresMu.RLock()
+	factory, ok := refreshTokenStores[db.DriverName()]
+	refreshTokenStoresMu.RUnlock()
+	if ok {
+		return factory(db, redis, reporter, ttl, maxLifetime)
+	}
+
 	if redis != nil {
 		return &dataRedis.RefreshTokenStore{
-			Client: redis,
-			TTL:    ttl,
+			Client:      redis,
+			TTL:         ttl,
+			MaxLifetime: maxLifetime,
 		}, nil
 	}
 
 	switch db.DriverName() {
 	case "sqlite3":
 		store := &sqlite3.RefreshTokenStore{
-			Ext:  db,
-			TTL: ttl,
+			Ext:         db,
+			TTL:         ttl,
+			MaxLifetime: maxLifetime,
 		}
 		store.Clean(reporter)
 		return store, nil
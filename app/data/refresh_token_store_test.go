@@ -0,0 +1,36 @@
+package data_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/ops"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterRefreshTokenStore(t *testing.T) {
+	sqlite, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	db := sqlx.NewDb(sqlite.DB, "embedded-tokens")
+	reporter := &ops.LogReporter{}
+
+	_, err = data.NewRefreshTokenStore(db, nil, reporter, time.Hour, 0)
+	assert.Error(t, err, "unsupported driver")
+
+	store := mock.NewRefreshTokenStore()
+	data.RegisterRefreshTokenStore("embedded-tokens", func(d *sqlx.DB, r *redis.Client, rep ops.ErrorReporter, ttl time.Duration, maxLifetime time.Duration) (data.RefreshTokenStore, error) {
+		assert.Equal(t, db, d)
+		assert.Equal(t, time.Hour, ttl)
+		return store, nil
+	})
+
+	found, err := data.NewRefreshTokenStore(db, nil, reporter, time.Hour, 0)
+	require.NoError(t, err)
+	assert.Equal(t, store, found)
+}
@@ -0,0 +1,92 @@
+// Package schema runs versioned migrations against a SQL database and records which versions have
+// been applied in a schema_migrations table.
+package schema
+
+import (
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Migration is one step in converging a database. Versions must be unique and increasing, and a
+// released version must never be renumbered or removed.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(db *sqlx.DB) error
+}
+
+// Status reports whether a Migration has been applied.
+type Status struct {
+	Version int
+	Name    string
+	Applied bool
+}
+
+const createTable = `
+    CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        applied_at TIMESTAMP NOT NULL
+    )
+`
+
+// Migrate applies every pending migration in order, recording each version as it succeeds.
+//
+// Migrations are expected to be idempotent. A database that was converged before versions were
+// tracked will run every migration once more and then record them all.
+func Migrate(db *sqlx.DB, migrations []Migration) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		if err := m.Up(db); err != nil {
+			return errors.Wrapf(err, "migration %d (%s)", m.Version, m.Name)
+		}
+		_, err := db.Exec(
+			db.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+			m.Version, m.Name, time.Now().UTC(),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "recording migration %d (%s)", m.Version, m.Name)
+		}
+	}
+	return nil
+}
+
+// Statuses reports which migrations have been applied and which are pending, in order.
+func Statuses(db *sqlx.DB, migrations []Migration) ([]Status, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(migrations))
+	for _, m := range migrations {
+		statuses = append(statuses, Status{Version: m.Version, Name: m.Name, Applied: applied[m.Version]})
+	}
+	return statuses, nil
+}
+
+func appliedVersions(db *sqlx.DB) (map[int]bool, error) {
+	if _, err := db.Exec(createTable); err != nil {
+		return nil, errors.Wrap(err, "schema_migrations")
+	}
+
+	versions := []int{}
+	if err := db.Select(&versions, "SELECT version FROM schema_migrations"); err != nil {
+		return nil, errors.Wrap(err, "schema_migrations")
+	}
+
+	applied := make(map[int]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
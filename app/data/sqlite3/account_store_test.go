@@ -2,9 +2,12 @@ package sqlite3_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/sqlite3"
 	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,3 +20,42 @@ func TestAccountStore(t *testing.T) {
 		db.Close()
 	}
 }
+
+func TestAccountStoreAnonymizesAuditLog(t *testing.T) {
+	db, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	store := &sqlite3.AccountStore{db}
+	auditLog := &sqlite3.AuditLog{db}
+
+	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	record := func() {
+		err := auditLog.Record(&models.AuditEntry{Action: "login", AccountID: account.ID, Username: account.Username, Actor: models.ActorAccount, IP: "127.0.0.1", UserAgent: "Test"})
+		require.NoError(t, err)
+	}
+	anonymized := func() bool {
+		entries, err := auditLog.List(models.AuditFilter{AccountID: account.ID}, 10, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		for _, entry := range entries {
+			if entry.Username != "" || entry.IP != "" || entry.UserAgent != "" {
+				return false
+			}
+		}
+		return true
+	}
+
+	record()
+	ok, err := store.Archive(account.ID)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, anonymized())
+
+	// e.g. the account's own request to delete it
+	record()
+	assert.False(t, anonymized())
+	_, err = store.PurgeArchived(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.True(t, anonymized())
+}
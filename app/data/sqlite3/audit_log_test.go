@@ -0,0 +1,20 @@
+package sqlite3_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLog(t *testing.T) {
+	db, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	log := &sqlite3.AuditLog{db}
+	for _, tester := range testers.AuditLogTesters {
+		db.MustExec("DELETE FROM audit_log")
+		tester(t, log)
+	}
+}
@@ -1,25 +1,41 @@
 package sqlite3
 
-import "github.com/jmoiron/sqlx"
+import (
+	"strings"
 
-// MigrateDB is committed to doing the work necessary to converge the database
-// in a safe, production-grade fashion. This will mean conditional logic as it
-// determines which steps have run and which steps must still be run. Given the
-// expected final complexity of this project, this is acceptable.
+	"github.com/jmoiron/sqlx"
+	"github.com/keratin/authn-server/app/data/schema"
+)
+
+// migrations converge the database in a safe, production-grade fashion. Each step is still written
+// with conditional logic so that databases converged before versions were tracked may safely run it
+// again. Append new steps with the next version; never renumber or remove a released one.
+var migrations = []schema.Migration{
+	{Version: 1, Name: "createAccounts", Up: createAccounts},
+	{Version: 2, Name: "createRefreshTokens", Up: createRefreshTokens},
+	{Version: 3, Name: "createBlobs", Up: createBlobs},
+	{Version: 4, Name: "createOauthAccounts", Up: createOauthAccounts},
+	{Version: 5, Name: "createAccountLastLoginAtField", Up: createAccountLastLoginAtField},
+	{Version: 6, Name: "createRefreshTokenMaxExpiresAtField", Up: createRefreshTokenMaxExpiresAtField},
+	{Version: 7, Name: "createRefreshTokenSessionFields", Up: createRefreshTokenSessionFields},
+	{Version: 8, Name: "createAccountRolesField", Up: createAccountRolesField},
+	{Version: 9, Name: "createAccountEmailVerifiedAtField", Up: createAccountEmailVerifiedAtField},
+	{Version: 10, Name: "createAccountTOTPFields", Up: createAccountTOTPFields},
+	{Version: 11, Name: "createAccountOTPDeliveryField", Up: createAccountOTPDeliveryField},
+	{Version: 12, Name: "createAccountMetadataField", Up: createAccountMetadataField},
+	{Version: 13, Name: "createAccountAliases", Up: createAccountAliases},
+	{Version: 14, Name: "createAuditLog", Up: createAuditLog},
+	{Version: 15, Name: "createAccountAliasVerifiedAtField", Up: createAccountAliasVerifiedAtField},
+}
+
+// MigrateDB applies any pending migrations.
 func MigrateDB(db *sqlx.DB) error {
-	migrations := []func(db *sqlx.DB) error{
-		createAccounts,
-		createRefreshTokens,
-		createBlobs,
-		createOauthAccounts,
-		createAccountLastLoginAtField,
-	}
-	for _, m := range migrations {
-		if err := m(db); err != nil {
-			return err
-		}
-	}
-	return nil
+	return schema.Migrate(db, migrations)
+}
+
+// MigrationStatus reports which migrations have been applied and which are pending.
+func MigrationStatus(db *sqlx.DB) ([]schema.Status, error) {
+	return schema.Statuses(db, migrations)
 }
 
 func createAccounts(db *sqlx.DB) error {
@@ -88,5 +104,123 @@ func createAccountLastLoginAtField(db *sqlx.DB) error {
 	_, err := db.Exec(`
         ALTER TABLE accounts ADD last_login_at DATETIME
     `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createRefreshTokenMaxExpiresAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE refresh_tokens ADD max_expires_at DATETIME
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createRefreshTokenSessionFields(db *sqlx.DB) error {
+	for _, field := range []string{"created_at DATETIME", "last_seen_at DATETIME", "user_agent TEXT"} {
+		_, err := db.Exec("ALTER TABLE refresh_tokens ADD " + field)
+		err = ignoreDuplicateColumn(err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createAccountRolesField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD roles TEXT
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createAccountEmailVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD email_verified_at DATETIME
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createAccountTOTPFields(db *sqlx.DB) error {
+	for _, field := range []string{"totp_secret TEXT", "totp_enabled_at DATETIME"} {
+		_, err := db.Exec("ALTER TABLE accounts ADD " + field)
+		err = ignoreDuplicateColumn(err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createAccountOTPDeliveryField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD otp_delivery_enabled_at DATETIME
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createAccountMetadataField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE accounts ADD metadata TEXT
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+func createAccountAliases(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS account_aliases (
+            id INTEGER PRIMARY KEY,
+            account_id INTEGER NOT NULL,
+            username TEXT NOT NULL CONSTRAINT uniq UNIQUE,
+            created_at DATETIME NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+        CREATE INDEX IF NOT EXISTS account_aliases_by_account_id ON account_aliases (account_id)
+    `)
+	return err
+}
+
+func createAuditLog(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        CREATE TABLE IF NOT EXISTS audit_log (
+            id INTEGER PRIMARY KEY,
+            action TEXT NOT NULL,
+            account_id INTEGER NOT NULL,
+            username TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            ip TEXT NOT NULL,
+            user_agent TEXT NOT NULL,
+            created_at DATETIME NOT NULL
+        )
+    `)
+	if err != nil {
+		return err
+	}
+	for _, index := range []string{
+		"CREATE INDEX IF NOT EXISTS audit_log_by_account_id ON audit_log (account_id)",
+		"CREATE INDEX IF NOT EXISTS audit_log_by_created_at ON audit_log (created_at)",
+	} {
+		if _, err := db.Exec(index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createAccountAliasVerifiedAtField(db *sqlx.DB) error {
+	_, err := db.Exec(`
+        ALTER TABLE account_aliases ADD verified_at DATETIME
+    `)
+	return ignoreDuplicateColumn(err)
+}
+
+// ignoreDuplicateColumn allows ALTER TABLE ADD to run again, since SQLite does not support
+// ADD COLUMN IF NOT EXISTS.
+func ignoreDuplicateColumn(err error) error {
+	if err != nil && strings.HasPrefix(err.Error(), "duplicate column name") {
+		return nil
+	}
 	return err
 }
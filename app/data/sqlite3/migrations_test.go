@@ -0,0 +1,60 @@
+package sqlite3_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := sqlite3.NewDB(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	assert.NoError(t, sqlite3.MigrateDB(db))
+	assert.NoError(t, sqlite3.MigrateDB(db), "migrates again on restart")
+}
+
+func TestMigrationStatus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "authn")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := sqlite3.NewDB(filepath.Join(dir, "test.db"))
+	require.NoError(t, err)
+	defer db.Close()
+
+	statuses, err := sqlite3.MigrationStatus(db)
+	require.NoError(t, err)
+	require.NotEmpty(t, statuses)
+	for i, status := range statuses {
+		assert.Equal(t, i+1, status.Version)
+		assert.False(t, status.Applied, status.Name)
+	}
+
+	require.NoError(t, sqlite3.MigrateDB(db))
+	statuses, err = sqlite3.MigrationStatus(db)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied, status.Name)
+	}
+
+	t.Run("database converged before versions were tracked", func(t *testing.T) {
+		_, err := db.Exec("DELETE FROM schema_migrations")
+		require.NoError(t, err)
+
+		require.NoError(t, sqlite3.MigrateDB(db))
+		statuses, err := sqlite3.MigrationStatus(db)
+		require.NoError(t, err)
+		for _, status := range statuses {
+			assert.True(t, status.Applied, status.Name)
+		}
+	})
+}
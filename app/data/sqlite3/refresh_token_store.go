@@ -17,6 +17,17 @@ import (
 type RefreshTokenStore struct {
 	sqlx.Ext
 	TTL time.Duration
+	// MaxLifetime limits how long a token may be touched after it is created. Zero is unlimited.
+	MaxLifetime time.Duration
+}
+
+// maxExpiresAt is NULL when the lifetime is unlimited
+func (s *RefreshTokenStore) maxExpiresAt() *time.Time {
+	if s.MaxLifetime == 0 {
+		return nil
+	}
+	t := time.Now().Add(s.MaxLifetime)
+	return &t
 }
 
 func (s *RefreshTokenStore) Clean(reporter ops.ErrorReporter) {
@@ -38,11 +49,21 @@ func (s *RefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
 	}
 	token := hex.EncodeToString(binToken)
 
+	now := time.Now()
+	expiresAt := now.Add(s.TTL)
+	maxExpiresAt := s.maxExpiresAt()
+	if maxExpiresAt != nil && maxExpiresAt.Before(expiresAt) {
+		expiresAt = *maxExpiresAt
+	}
+
 	_, err = s.Exec(
-		"INSERT INTO refresh_tokens (account_id, token, expires_at) VALUES (?, ?, ?)",
+		"INSERT INTO refresh_tokens (account_id, token, expires_at, max_expires_at, created_at, last_seen_at) VALUES (?, ?, ?, ?, ?, ?)",
 		accountID,
 		token,
-		time.Now().Add(s.TTL),
+		expiresAt,
+		maxExpiresAt,
+		now,
+		now,
 	)
 	if err != nil {
 		return "", err
@@ -67,12 +88,30 @@ func (s *RefreshTokenStore) Find(token models.RefreshToken) (int, error) {
 	return accountID, nil
 }
 
+// Touch will not extend a token past its max_expires_at. Tokens created without a maximum are
+// given one, in case MaxLifetime has since been configured.
 func (s *RefreshTokenStore) Touch(token models.RefreshToken, accountID int) error {
+	return s.TouchFor(token, accountID, s.TTL)
+}
+
+func (s *RefreshTokenStore) TouchFor(token models.RefreshToken, accountID int, ttl time.Duration) error {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	maxExpiresAt := s.maxExpiresAt()
 	_, err := s.Exec(
-		"UPDATE refresh_tokens SET expires_at = ? WHERE token = ? AND expires_at > ?",
-		time.Now().Add(s.TTL),
+		`UPDATE refresh_tokens SET
+			expires_at = CASE WHEN COALESCE(max_expires_at, ?) < ? THEN COALESCE(max_expires_at, ?) ELSE ? END,
+			max_expires_at = COALESCE(max_expires_at, ?),
+			last_seen_at = ?
+		WHERE token = ? AND expires_at > ?`,
+		maxExpiresAt,
+		expiresAt,
+		maxExpiresAt,
+		expiresAt,
+		maxExpiresAt,
+		now,
 		token,
-		time.Now(),
+		now,
 	)
 	return err
 }
@@ -80,7 +119,7 @@ func (s *RefreshTokenStore) Touch(token models.RefreshToken, accountID int) erro
 func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
 	var tokens []models.RefreshToken
 	rows, err := s.Query(
-		"SELECT token FROM refresh_tokens WHERE account_id = ? AND expires_at > ?",
+		"SELECT token FROM refresh_tokens WHERE account_id = ? AND expires_at > ? ORDER BY rowid",
 		accountID,
 		time.Now(),
 	)
@@ -100,6 +139,21 @@ func (s *RefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error
 	return tokens, nil
 }
 
+func (s *RefreshTokenStore) SetUserAgent(token models.RefreshToken, userAgent string) error {
+	_, err := s.Exec("UPDATE refresh_tokens SET user_agent = ? WHERE token = ?", userAgent, token)
+	return err
+}
+
+func (s *RefreshTokenStore) FindSessions(accountID int) ([]models.Session, error) {
+	sessions := []models.Session{}
+	err := sqlx.Select(s, &sessions,
+		"SELECT token, COALESCE(user_agent, '') AS user_agent, created_at, last_seen_at FROM refresh_tokens WHERE account_id = ? AND expires_at > ? ORDER BY rowid",
+		accountID,
+		time.Now(),
+	)
+	return sessions, err
+}
+
 func (s *RefreshTokenStore) Revoke(token models.RefreshToken) error {
 	_, err := s.Exec("DELETE FROM refresh_tokens WHERE token = ?", token)
 	return err
@@ -6,6 +6,8 @@ import (
 
 	"github.com/keratin/authn-server/app/data/sqlite3"
 	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,8 +15,57 @@ func TestRefreshTokenStore(t *testing.T) {
 	for _, tester := range testers.RefreshTokenStoreTesters {
 		db, err := sqlite3.TestDB()
 		require.NoError(t, err)
-		store := &sqlite3.RefreshTokenStore{db, time.Second}
+		store := &sqlite3.RefreshTokenStore{Ext: db, TTL: time.Second}
 		tester(t, store)
 		db.Close()
 	}
 }
+
+func TestRefreshTokenStoreMaxLifetime(t *testing.T) {
+	db, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	store := &sqlite3.RefreshTokenStore{Ext: db, TTL: time.Hour, MaxLifetime: time.Minute}
+
+	expiresAt := func(token models.RefreshToken) time.Time {
+		var at time.Time
+		err := db.Get(&at, "SELECT expires_at FROM refresh_tokens WHERE token = ?", token)
+		require.NoError(t, err)
+		return at
+	}
+
+	token, err := store.Create(1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt(token), time.Second)
+
+	err = store.Touch(token, 1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt(token), time.Second)
+
+	// tokens created without a max lifetime are limited once touched
+	unlimited := &sqlite3.RefreshTokenStore{Ext: db, TTL: time.Hour}
+	token, err = unlimited.Create(1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expiresAt(token), time.Second)
+
+	err = store.Touch(token, 1)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt(token), time.Second)
+}
+
+func TestRefreshTokenStoreTouchFor(t *testing.T) {
+	db, err := sqlite3.TestDB()
+	require.NoError(t, err)
+	defer db.Close()
+	store := &sqlite3.RefreshTokenStore{Ext: db, TTL: time.Hour}
+
+	token, err := store.Create(1)
+	require.NoError(t, err)
+
+	err = store.TouchFor(token, 1, time.Minute)
+	require.NoError(t, err)
+	var expiresAt time.Time
+	err = db.Get(&expiresAt, "SELECT expires_at FROM refresh_tokens WHERE token = ?", token)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+}
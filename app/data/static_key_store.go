@@ -0,0 +1,43 @@
+package data
+
+import (
+	"sync"
+
+	"github.com/keratin/authn-server/app/data/private"
+)
+
+// StaticKeyStore is a KeyStore with a fixed list of keys, as when keys are provided by
+// configuration. The list may be replaced when configuration is reloaded.
+type StaticKeyStore struct {
+	mu   sync.RWMutex
+	keys []*private.Key
+}
+
+// NewStaticKeyStore builds a StaticKeyStore from keys sorted with the newest key last.
+func NewStaticKeyStore(keys ...*private.Key) *StaticKeyStore {
+	return &StaticKeyStore{keys: keys}
+}
+
+// Key returns the newest key.
+func (ks *StaticKeyStore) Key() *private.Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if len(ks.keys) > 0 {
+		return ks.keys[len(ks.keys)-1]
+	}
+	return nil
+}
+
+// Keys returns all of the keys, from oldest to newest.
+func (ks *StaticKeyStore) Keys() []*private.Key {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.keys
+}
+
+// Replace swaps in a new list of keys, sorted with the newest key last.
+func (ks *StaticKeyStore) Replace(keys ...*private.Key) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	ks.keys = keys
+}
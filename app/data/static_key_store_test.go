@@ -0,0 +1,35 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticKeyStore(t *testing.T) {
+	assert.Empty(t, data.NewStaticKeyStore().Key())
+
+	k1, err := private.GenerateKey(256)
+	require.NoError(t, err)
+	k2, err := private.GenerateKey(256)
+	require.NoError(t, err)
+
+	ks := data.NewStaticKeyStore(k1, k2)
+	assert.Equal(t, []*private.Key{k1, k2}, ks.Keys())
+	assert.Equal(t, k2, ks.Key())
+}
+
+func TestStaticKeyStoreReplace(t *testing.T) {
+	k1, err := private.GenerateKey(256)
+	require.NoError(t, err)
+	k2, err := private.GenerateKey(256)
+	require.NoError(t, err)
+
+	ks := data.NewStaticKeyStore(k1)
+	ks.Replace(k1, k2)
+	assert.Equal(t, []*private.Key{k1, k2}, ks.Keys())
+	assert.Equal(t, k2, ks.Key())
+}
@@ -0,0 +1,56 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var AccessTokenStoreTesters = []func(*testing.T, data.AccessTokenStore){
+	testAccessTokenStoreCreate,
+	testAccessTokenStoreExpired,
+	testAccessTokenStoreRevoke,
+}
+
+func testAccessTokenStoreCreate(t *testing.T, store data.AccessTokenStore) {
+	token1, err := store.Create("identity.token.1", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.NotEmpty(t, token1)
+	token2, err := store.Create("identity.token.2", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.NotEqual(t, token1, token2)
+
+	found, err := store.Find(token1)
+	require.NoError(t, err)
+	assert.Equal(t, "identity.token.1", found)
+
+	found, err = store.Find("unknown")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testAccessTokenStoreExpired(t *testing.T, store data.AccessTokenStore) {
+	token, err := store.Create("identity.token", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	found, err := store.Find(token)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func testAccessTokenStoreRevoke(t *testing.T, store data.AccessTokenStore) {
+	token, err := store.Create("identity.token", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	err = store.Revoke(token)
+	require.NoError(t, err)
+	found, err := store.Find(token)
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	err = store.Revoke("unknown")
+	assert.NoError(t, err)
+}
@@ -2,10 +2,12 @@ package testers
 
 import (
 	"testing"
+	"time"
 
 	"database/sql"
 
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,15 +15,28 @@ import (
 var AccountStoreTesters = []func(*testing.T, data.AccountStore){
 	testCreate,
 	testFindByUsername,
+	testList,
 	testLockAndUnlock,
 	testArchive,
 	testArchiveWithOauth,
+	testArchiveWithAliases,
+	testArchiveWithRolesAndMetadata,
+	testPurgeArchived,
 	testRequireNewPassword,
 	testSetPassword,
+	testRehashPassword,
 	testUpdateUsername,
+	testUpdateVerifiedUsername,
 	testAddOauthAccount,
 	testFindByOauthAccount,
+	testAliases,
+	testPromoteAlias,
 	testSetLastLogin,
+	testSetRoles,
+	testSetMetadata,
+	testSetVerified,
+	testTOTP,
+	testSetOTPDelivery,
 }
 
 type hasStats interface {
@@ -73,6 +88,55 @@ func testFindByUsername(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testList(t *testing.T, store data.AccountStore) {
+	for _, username := range []string{"alice@keratin.tech", "bob@keratin.tech", "alice@example.com", "100%_off@example.com"} {
+		_, err := store.Create(username, []byte("password"))
+		require.NoError(t, err)
+	}
+	archived, err := store.Create("archived@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.Archive(archived.ID)
+	require.NoError(t, err)
+
+	usernames := func(accounts []*models.Account) []string {
+		found := []string{}
+		for _, account := range accounts {
+			found = append(found, account.Username)
+		}
+		return found
+	}
+
+	accounts, err := store.List("", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@keratin.tech", "bob@keratin.tech", "alice@example.com", "100%_off@example.com"}, usernames(accounts))
+	count, err := store.Count("")
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	accounts, err = store.List("", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob@keratin.tech", "alice@example.com"}, usernames(accounts))
+
+	accounts, err = store.List("alice", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice@keratin.tech", "alice@example.com"}, usernames(accounts))
+	count, err = store.Count("alice")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// wildcards are matched literally
+	accounts, err = store.List("%_", 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100%_off@example.com"}, usernames(accounts))
+
+	accounts, err = store.List("", 10, 10)
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
 func testLockAndUnlock(t *testing.T, store data.AccountStore) {
 	account, err := store.Create("authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
@@ -126,6 +190,38 @@ func testArchive(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testPurgeArchived(t *testing.T, store data.AccountStore) {
+	archived, err := store.Create("archived@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.SetTOTPSecret(archived.ID, []byte("secret"))
+	require.NoError(t, err)
+	_, err = store.Archive(archived.ID)
+	require.NoError(t, err)
+
+	active, err := store.Create("active@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+
+	after, err := store.Find(archived.ID)
+	require.NoError(t, err)
+	assert.Empty(t, after.TOTPSecret)
+
+	// tombstones are kept until the retention has passed
+	count, err := store.PurgeArchived(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	count, err = store.PurgeArchived(time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	after, err = store.Find(archived.ID)
+	require.NoError(t, err)
+	assert.Nil(t, after)
+	after, err = store.Find(active.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, after)
+}
+
 func testArchiveWithOauth(t *testing.T, store data.AccountStore) {
 	account, err := store.Create("authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
@@ -144,6 +240,49 @@ func testArchiveWithOauth(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testArchiveWithAliases(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "alias@keratin.tech")
+	require.NoError(t, err)
+
+	ok, err := store.Archive(account.ID)
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	found, err := store.FindByUsername("alias@keratin.tech")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	aliases, err := store.GetAliases(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, aliases, 0)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testArchiveWithRolesAndMetadata(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.SetRoles(account.ID, []string{"admin"})
+	require.NoError(t, err)
+	_, err = store.SetMetadata(account.ID, map[string]interface{}{"tenant": "acme"})
+	require.NoError(t, err)
+
+	ok, err := store.Archive(account.ID)
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, after.Roles)
+	assert.Empty(t, after.Metadata)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
 func testRequireNewPassword(t *testing.T, store data.AccountStore) {
 	account, err := store.Create("authn@keratin.tech", []byte("password"))
 	require.NoError(t, err)
@@ -182,6 +321,31 @@ func testSetPassword(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testRehashPassword(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("authn@keratin.tech", []byte("old"))
+	require.NoError(t, err)
+	ok, err := store.RequireNewPassword(account.ID)
+	require.True(t, ok)
+	require.NoError(t, err)
+
+	ok, err = store.RehashPassword(account.ID, []byte("rehashed"))
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("rehashed"), after.Password)
+	assert.True(t, after.RequireNewPassword)
+	assert.Equal(t, account.PasswordChangedAt.Unix(), after.PasswordChangedAt.Unix())
+
+	ok, err = store.RehashPassword(0, []byte("rehashed"))
+	assert.False(t, ok)
+	require.NoError(t, err)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
 func testUpdateUsername(t *testing.T, store data.AccountStore) {
 	other, err := store.Create("other", []byte("other"))
 	require.NoError(t, err)
@@ -211,6 +375,43 @@ func testUpdateUsername(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testUpdateVerifiedUsername(t *testing.T, store data.AccountStore) {
+	other, err := store.Create("other", []byte("other"))
+	require.NoError(t, err)
+
+	account, err := store.Create("old", []byte("old"))
+	require.NoError(t, err)
+	require.NoError(t, store.AddAlias(account.ID, "new"))
+	require.NoError(t, store.AddAlias(account.ID, other.Username))
+
+	ok, err := store.UpdateVerifiedUsername(account.ID, "new")
+	assert.True(t, ok)
+	require.NoError(t, err)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "new", after.Username)
+	assert.NotNil(t, after.EmailVerifiedAt)
+	aliases, err := store.GetAliases(account.ID)
+	require.NoError(t, err)
+	if assert.Len(t, aliases, 1) {
+		assert.Equal(t, other.Username, aliases[0].Username)
+	}
+
+	// the alias is kept when the username is taken
+	ok, err = store.UpdateVerifiedUsername(account.ID, other.Username)
+	assert.False(t, ok)
+	if err == nil || !data.IsUniquenessError(err) {
+		t.Errorf("expected uniqueness error, got %T %v", err, err)
+	}
+	aliases, err = store.GetAliases(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, aliases, 1)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
 func testAddOauthAccount(t *testing.T, store data.AccountStore) {
 	found, err := store.GetOauthAccounts(1)
 	require.NoError(t, err)
@@ -266,6 +467,106 @@ func testFindByOauthAccount(t *testing.T, store data.AccountStore) {
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
 
+func testAliases(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	other, err := store.Create("other@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+
+	err = store.AddAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "home@keratin.tech")
+	require.NoError(t, err)
+
+	err = store.AddAlias(other.ID, "work@keratin.tech")
+	if assert.Error(t, err) {
+		assert.True(t, data.IsUniquenessError(err))
+	}
+
+	aliases, err := store.GetAliases(account.ID)
+	require.NoError(t, err)
+	if assert.Len(t, aliases, 2) {
+		assert.Equal(t, account.ID, aliases[0].AccountID)
+		assert.Equal(t, "work@keratin.tech", aliases[0].Username)
+		assert.NotEmpty(t, aliases[0].CreatedAt)
+		assert.Equal(t, "home@keratin.tech", aliases[1].Username)
+	}
+
+	found, err := store.FindByUsername("work@keratin.tech")
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, account.ID, found.ID)
+		assert.Equal(t, "authn@keratin.tech", found.Username)
+	}
+
+	ok, err := store.DeleteAlias(other.ID, "work@keratin.tech")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.DeleteAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err = store.FindByUsername("work@keratin.tech")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	aliases, err = store.GetAliases(account.ID)
+	require.NoError(t, err)
+	assert.Len(t, aliases, 1)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testPromoteAlias(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("authn@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.SetVerified(account.ID)
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+
+	ok, err := store.PromoteAlias(account.ID, "unknown@keratin.tech")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.PromoteAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "work@keratin.tech", found.Username)
+	assert.False(t, found.Verified())
+
+	aliases, err := store.GetAliases(account.ID)
+	require.NoError(t, err)
+	if assert.Len(t, aliases, 1) {
+		assert.Equal(t, "authn@keratin.tech", aliases[0].Username)
+		assert.NotNil(t, aliases[0].VerifiedAt)
+	}
+
+	found, err = store.FindByUsername("authn@keratin.tech")
+	require.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, account.ID, found.ID)
+	}
+
+	// promoting the original username back restores its verification
+	ok, err = store.PromoteAlias(account.ID, "authn@keratin.tech")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	found, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "authn@keratin.tech", found.Username)
+	assert.True(t, found.Verified())
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
 func testSetLastLogin(t *testing.T, store data.AccountStore) {
 	account, err := store.Create("old", []byte("old"))
 	require.NoError(t, err)
@@ -281,3 +582,149 @@ func testSetLastLogin(t *testing.T, store data.AccountStore) {
 	// Assert that db connections are released to pool
 	assert.Equal(t, 1, getOpenConnectionCount(store))
 }
+
+func testSetRoles(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("old", []byte("old"))
+	require.NoError(t, err)
+	assert.Empty(t, account.Roles)
+
+	rowsIsAffected, err := store.SetRoles(account.ID, []string{"admin", "billing"})
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Roles{"admin", "billing"}, after.Roles)
+
+	_, err = store.SetRoles(account.ID, nil)
+	require.NoError(t, err)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, after.Roles)
+
+	rowsIsAffected, err = store.SetRoles(0, []string{"admin"})
+	require.NoError(t, err)
+	assert.Equal(t, false, rowsIsAffected)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testSetVerified(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("unverified", []byte("old"))
+	require.NoError(t, err)
+	assert.False(t, account.Verified())
+
+	rowsIsAffected, err := store.SetVerified(account.ID)
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.True(t, after.Verified())
+
+	// a new username has not been verified
+	_, err = store.UpdateUsername(account.ID, "changed")
+	require.NoError(t, err)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.False(t, after.Verified())
+
+	rowsIsAffected, err = store.SetVerified(0)
+	require.NoError(t, err)
+	assert.Equal(t, false, rowsIsAffected)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testTOTP(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("totp", []byte("old"))
+	require.NoError(t, err)
+	assert.False(t, account.TOTPEnabled())
+
+	// can not enable without a secret
+	rowsIsAffected, err := store.EnableTOTP(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, false, rowsIsAffected)
+
+	rowsIsAffected, err = store.SetTOTPSecret(account.ID, []byte("encrypted"))
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("encrypted"), after.TOTPSecret)
+	assert.False(t, after.TOTPEnabled())
+
+	rowsIsAffected, err = store.EnableTOTP(account.ID)
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.True(t, after.TOTPEnabled())
+
+	rowsIsAffected, err = store.DeleteTOTP(account.ID)
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, after.TOTPSecret)
+	assert.False(t, after.TOTPEnabled())
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testSetOTPDelivery(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("otp", []byte("old"))
+	require.NoError(t, err)
+	assert.False(t, account.OTPDeliveryEnabled())
+
+	rowsIsAffected, err := store.SetOTPDelivery(account.ID, true)
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.True(t, after.OTPDeliveryEnabled())
+	assert.True(t, after.RequiresOTP())
+
+	_, err = store.SetOTPDelivery(account.ID, false)
+	require.NoError(t, err)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.False(t, after.OTPDeliveryEnabled())
+
+	rowsIsAffected, err = store.SetOTPDelivery(0, true)
+	require.NoError(t, err)
+	assert.Equal(t, false, rowsIsAffected)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
+
+func testSetMetadata(t *testing.T, store data.AccountStore) {
+	account, err := store.Create("old", []byte("old"))
+	require.NoError(t, err)
+	assert.Empty(t, account.Metadata)
+
+	rowsIsAffected, err := store.SetMetadata(account.ID, map[string]interface{}{"tenant": "acme", "beta": true})
+	require.NoError(t, err)
+	require.Equal(t, true, rowsIsAffected)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.Metadata{"tenant": "acme", "beta": true}, after.Metadata)
+
+	_, err = store.SetMetadata(account.ID, nil)
+	require.NoError(t, err)
+	after, err = store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Empty(t, after.Metadata)
+
+	rowsIsAffected, err = store.SetMetadata(0, map[string]interface{}{"tenant": "acme"})
+	require.NoError(t, err)
+	assert.Equal(t, false, rowsIsAffected)
+
+	// Assert that db connections are released to pool
+	assert.Equal(t, 1, getOpenConnectionCount(store))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,6 +16,8 @@ var ActivesTesters = []func(*testing.T, data.Actives){
 	testActivesActivesByDay,
 	testActivesActivesByWeek,
 	testActivesActivesByMonth,
+	testActivesActivesBetween,
+	testActivesSignupsBetween,
 }
 
 func testActivesTrack(t *testing.T, actives data.Actives) {
@@ -61,6 +64,40 @@ func testActivesActivesByMonth(t *testing.T, actives data.Actives) {
 	}
 }
 
+func testActivesActivesBetween(t *testing.T, actives data.Actives) {
+	actives.Track(1)
+	actives.Track(2)
+
+	now := time.Now().In(time.UTC)
+	yesterday := now.AddDate(0, 0, -1)
+	report, err := actives.ActivesBetween(models.Daily, yesterday, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		yesterday.Format("2006-01-02"): 0,
+		now.Format("2006-01-02"):       2,
+	}, report)
+
+	report, err = actives.ActivesBetween(models.Monthly, now, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{now.Format("2006-01"): 2}, report)
+}
+
+func testActivesSignupsBetween(t *testing.T, actives data.Actives) {
+	actives.Track(1)
+	actives.TrackSignup(2)
+
+	now := time.Now().In(time.UTC)
+	lastWeek := now.AddDate(0, 0, -7)
+	report, err := actives.SignupsBetween(models.Weekly, lastWeek, now)
+	require.NoError(t, err)
+	if assert.Len(t, report, 2) {
+		y, w := now.ISOWeek()
+		assert.Equal(t, 1, report[strconv.Itoa(y)+"-W"+strconv.Itoa(w)])
+		y, w = lastWeek.ISOWeek()
+		assert.Equal(t, 0, report[strconv.Itoa(y)+"-W"+strconv.Itoa(w)])
+	}
+}
+
 func mapVals(m map[string]int) []int {
 	vals := make([]int, 0, len(m))
 	for _, x := range m {
@@ -0,0 +1,97 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var AuditLogTesters = []func(*testing.T, data.AuditLog){
+	testAuditLogRecord,
+	testAuditLogFilter,
+	testAuditLogPurge,
+}
+
+func testAuditLogRecord(t *testing.T, log data.AuditLog) {
+	entry := &models.AuditEntry{
+		Action:    "login",
+		AccountID: 1,
+		Username:  "alice@example.com",
+		Actor:     models.ActorAccount,
+		IP:        "127.0.0.1",
+		UserAgent: "Mozilla/5.0",
+	}
+	require.NoError(t, log.Record(entry))
+	assert.NotZero(t, entry.ID)
+	assert.NotZero(t, entry.CreatedAt)
+
+	entries, err := log.List(models.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.ID, entries[0].ID)
+	assert.Equal(t, "login", entries[0].Action)
+	assert.Equal(t, 1, entries[0].AccountID)
+	assert.Equal(t, "alice@example.com", entries[0].Username)
+	assert.Equal(t, models.ActorAccount, entries[0].Actor)
+	assert.Equal(t, "127.0.0.1", entries[0].IP)
+	assert.Equal(t, "Mozilla/5.0", entries[0].UserAgent)
+	assert.WithinDuration(t, entry.CreatedAt, entries[0].CreatedAt, time.Second)
+}
+
+func testAuditLogFilter(t *testing.T, log data.AuditLog) {
+	for _, entry := range []*models.AuditEntry{
+		{Action: "login", AccountID: 1, Actor: models.ActorAccount},
+		{Action: "login.failed", AccountID: 1, Actor: models.ActorAnonymous},
+		{Action: "login", AccountID: 2, Actor: models.ActorAccount},
+		{Action: "account.locked", AccountID: 1, Actor: models.ActorAdmin},
+	} {
+		require.NoError(t, log.Record(entry))
+	}
+
+	entries, err := log.List(models.AuditFilter{AccountID: 1}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "account.locked", entries[0].Action, "newest first")
+	assert.Equal(t, "login", entries[2].Action)
+
+	entries, err = log.List(models.AuditFilter{AccountID: 1}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "login.failed", entries[0].Action)
+
+	count, err := log.Count(models.AuditFilter{AccountID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = log.Count(models.AuditFilter{Action: "login"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	entries, err = log.List(models.AuditFilter{AccountID: 2, Action: "login"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	count, err = log.Count(models.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func testAuditLogPurge(t *testing.T, log data.AuditLog) {
+	old := &models.AuditEntry{Action: "login", AccountID: 1, Actor: models.ActorAccount, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	require.NoError(t, log.Record(old))
+	recent := &models.AuditEntry{Action: "login", AccountID: 1, Actor: models.ActorAccount}
+	require.NoError(t, log.Record(recent))
+
+	purged, err := log.Purge(time.Now().Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	entries, err := log.List(models.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, recent.ID, entries[0].ID)
+}
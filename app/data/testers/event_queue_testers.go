@@ -0,0 +1,88 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var EventQueueTesters = []func(*testing.T, data.EventQueue){
+	testEventQueueClaim,
+	testEventQueueLease,
+	testEventQueueReschedule,
+}
+
+func newEventDelivery(id string) *models.EventDelivery {
+	return &models.EventDelivery{
+		ID:  id,
+		URL: "https://app.example.com/events",
+		Event: models.Event{
+			ID:         "event-" + id,
+			Type:       "account.created",
+			AccountID:  1,
+			OccurredAt: time.Now().UTC().Truncate(time.Second),
+		},
+	}
+}
+
+func testEventQueueClaim(t *testing.T, q data.EventQueue) {
+	delivery, err := q.Claim(time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+
+	require.NoError(t, q.Schedule(newEventDelivery("later"), time.Now().Add(time.Hour)))
+	require.NoError(t, q.Schedule(newEventDelivery("now"), time.Now()))
+
+	delivery, err = q.Claim(time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, *newEventDelivery("now"), *delivery)
+
+	delivery, err = q.Claim(time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+}
+
+func testEventQueueLease(t *testing.T, q data.EventQueue) {
+	require.NoError(t, q.Schedule(newEventDelivery("leased"), time.Now()))
+
+	delivery, err := q.Claim(100 * time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+
+	time.Sleep(150 * time.Millisecond)
+	delivery, err = q.Claim(time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, "leased", delivery.ID)
+
+	require.NoError(t, q.Schedule(newEventDelivery("acked"), time.Now()))
+	delivery, err = q.Claim(100 * time.Millisecond)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	require.NoError(t, q.Ack(delivery.ID))
+
+	time.Sleep(150 * time.Millisecond)
+	delivery, err = q.Claim(time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, delivery)
+}
+
+func testEventQueueReschedule(t *testing.T, q data.EventQueue) {
+	require.NoError(t, q.Schedule(newEventDelivery("retried"), time.Now()))
+	delivery, err := q.Claim(time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+
+	delivery.Attempts++
+	require.NoError(t, q.Schedule(delivery, time.Now()))
+
+	delivery, err = q.Claim(time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, delivery)
+	assert.Equal(t, 1, delivery.Attempts)
+}
@@ -0,0 +1,106 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var FailedLoginsTesters = []func(*testing.T, data.FailedLogins){
+	testFailedLoginsAdd,
+	testFailedLoginsCountPair,
+	testFailedLoginsReset,
+	testFailedLoginsLock,
+}
+
+func testFailedLoginsAdd(t *testing.T, store data.FailedLogins) {
+	count, ttl, err := store.CountIP("127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Zero(t, ttl)
+
+	usernameFailures, ipFailures, err := store.Add("alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, usernameFailures)
+	assert.Equal(t, 1, ipFailures)
+
+	usernameFailures, ipFailures, err = store.Add("bob", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, usernameFailures)
+	assert.Equal(t, 2, ipFailures)
+
+	usernameFailures, ipFailures, err = store.Add("alice", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, usernameFailures)
+	assert.Equal(t, 1, ipFailures)
+
+	count, ttl, err = store.CountIP("127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, ttl > 0)
+}
+
+func testFailedLoginsCountPair(t *testing.T, store data.FailedLogins) {
+	count, at, err := store.CountPair("alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.True(t, at.IsZero())
+
+	store.Add("alice", "127.0.0.1")
+	store.Add("alice", "10.0.0.1")
+	store.Add("bob", "127.0.0.1")
+	store.Add("alice", "127.0.0.1")
+
+	count, at, err = store.CountPair("alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.WithinDuration(t, time.Now(), at, time.Second)
+
+	count, _, err = store.CountPair("alice", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testFailedLoginsReset(t *testing.T, store data.FailedLogins) {
+	store.Add("alice", "127.0.0.1")
+	store.Add("alice", "127.0.0.1")
+
+	err := store.Reset("alice")
+	require.NoError(t, err)
+
+	usernameFailures, ipFailures, err := store.Add("alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, usernameFailures)
+	// failures from the IP are not forgiven
+	assert.Equal(t, 3, ipFailures)
+
+	count, _, err := store.CountPair("alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testFailedLoginsLock(t *testing.T, store data.FailedLogins) {
+	ttl, err := store.LockedFor(1)
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+
+	err = store.Lock(1, time.Minute)
+	require.NoError(t, err)
+	ttl, err = store.LockedFor(1)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, ttl, float64(time.Second))
+
+	// other accounts are not locked
+	ttl, err = store.LockedFor(2)
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+
+	err = store.Unlock(1)
+	require.NoError(t, err)
+	ttl, err = store.LockedFor(1)
+	require.NoError(t, err)
+	assert.Zero(t, ttl)
+}
@@ -0,0 +1,63 @@
+package testers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var IdempotencyStoreTesters = []func(*testing.T, data.IdempotencyStore){
+	testIdempotencyStoreClaim,
+	testIdempotencyStoreRelease,
+}
+
+func testIdempotencyStoreClaim(t *testing.T, store data.IdempotencyStore) {
+	res, ok, err := store.Claim("signup:abc")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, res)
+
+	// in progress
+	res, ok, err = store.Claim("signup:abc")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.Nil(t, res)
+
+	saved := &models.IdempotentResponse{
+		Fingerprint: "xyz",
+		Status:      http.StatusCreated,
+		Header:      http.Header{"Content-Type": []string{"application/json"}},
+		Body:        []byte(`{"result":{}}`),
+	}
+	err = store.Save("signup:abc", saved, time.Minute)
+	require.NoError(t, err)
+
+	res, ok, err = store.Claim("signup:abc")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, saved, res)
+
+	res, ok, err = store.Claim("signup:def")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, res)
+}
+
+func testIdempotencyStoreRelease(t *testing.T, store data.IdempotencyStore) {
+	_, ok, err := store.Claim("signup:abc")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	err = store.Release("signup:abc")
+	require.NoError(t, err)
+
+	res, ok, err := store.Claim("signup:abc")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Nil(t, res)
+}
@@ -0,0 +1,33 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var MaintenanceTesters = []func(*testing.T, data.Maintenance){
+	testMaintenanceSwitch,
+}
+
+func testMaintenanceSwitch(t *testing.T, m data.Maintenance) {
+	until, err := m.Until()
+	require.NoError(t, err)
+	assert.True(t, until.IsZero())
+
+	end := time.Now().Add(time.Hour).Truncate(time.Second)
+	err = m.Enable(end)
+	require.NoError(t, err)
+	until, err = m.Until()
+	require.NoError(t, err)
+	assert.True(t, end.Equal(until))
+
+	err = m.Disable()
+	require.NoError(t, err)
+	until, err = m.Until()
+	require.NoError(t, err)
+	assert.True(t, until.IsZero())
+}
@@ -0,0 +1,86 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var OTPStoreTesters = []func(*testing.T, data.OTPStore){
+	testOTPStoreVerify,
+	testOTPStoreReplace,
+	testOTPStoreAttempts,
+	testOTPStoreSends,
+}
+
+func testOTPStoreVerify(t *testing.T, store data.OTPStore) {
+	ok, err := store.Verify(1, "123456")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.Create(1, "123456", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = store.Verify(2, "123456")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = store.Verify(1, "123456")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// codes are single use
+	ok, err = store.Verify(1, "123456")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func testOTPStoreReplace(t *testing.T, store data.OTPStore) {
+	_, err := store.Create(1, "111111", time.Minute)
+	require.NoError(t, err)
+	_, err = store.Create(1, "222222", time.Minute)
+	require.NoError(t, err)
+
+	ok, err := store.Verify(1, "111111")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = store.Verify(1, "222222")
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func testOTPStoreAttempts(t *testing.T, store data.OTPStore) {
+	_, err := store.Create(1, "123456", time.Minute)
+	require.NoError(t, err)
+
+	for i := 0; i < data.MaxOTPAttempts; i++ {
+		ok, err := store.Verify(1, "000000")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	ok, err := store.Verify(1, "123456")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func testOTPStoreSends(t *testing.T, store data.OTPStore) {
+	for i := 0; i < data.MaxOTPSends; i++ {
+		ok, err := store.Create(1, "123456", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+
+	ok, err := store.Create(1, "654321", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// other accounts are not limited
+	ok, err = store.Create(2, "654321", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
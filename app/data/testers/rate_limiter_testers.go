@@ -0,0 +1,54 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var RateLimiterTesters = []func(*testing.T, data.RateLimiter){
+	testRateLimiterTake,
+	testRateLimiterRefill,
+}
+
+func testRateLimiterTake(t *testing.T, limiter data.RateLimiter) {
+	for i := 2; i >= 0; i-- {
+		rl, err := limiter.Take("login:ip:127.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+		assert.True(t, rl.Allowed)
+		assert.Equal(t, i, rl.Remaining)
+		assert.Equal(t, time.Duration(0), rl.RetryAfter)
+		assert.InDelta(t, float64(time.Duration(3-i)*20*time.Second), float64(rl.ResetAfter), float64(time.Second))
+	}
+
+	rl, err := limiter.Take("login:ip:127.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, rl.Allowed)
+	assert.Equal(t, 0, rl.Remaining)
+	assert.InDelta(t, float64(20*time.Second), float64(rl.RetryAfter), float64(time.Second))
+
+	rl, err = limiter.Take("login:ip:10.0.0.1", 3, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, rl.Allowed)
+	assert.Equal(t, 2, rl.Remaining)
+}
+
+func testRateLimiterRefill(t *testing.T, limiter data.RateLimiter) {
+	for i := 0; i < 2; i++ {
+		rl, err := limiter.Take("signup:ip:127.0.0.1", 2, 200*time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, rl.Allowed)
+	}
+	rl, err := limiter.Take("signup:ip:127.0.0.1", 2, 200*time.Millisecond)
+	require.NoError(t, err)
+	assert.False(t, rl.Allowed)
+
+	time.Sleep(150 * time.Millisecond)
+	rl, err = limiter.Take("signup:ip:127.0.0.1", 2, 200*time.Millisecond)
+	require.NoError(t, err)
+	assert.True(t, rl.Allowed)
+	assert.Equal(t, 0, rl.Remaining)
+}
@@ -2,6 +2,7 @@ package testers
 
 import (
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
@@ -13,6 +14,8 @@ var RefreshTokenStoreTesters = []func(*testing.T, data.RefreshTokenStore){
 	testRefreshTokenFind,
 	testRefreshTokenTouch,
 	testRefreshTokenFindAll,
+	testRefreshTokenFindAllOrder,
+	testRefreshTokenFindSessions,
 	testRefreshTokenCreate,
 	testRefreshTokenRevoke,
 }
@@ -58,6 +61,63 @@ func testRefreshTokenFindAll(t *testing.T, store data.RefreshTokenStore) {
 	assert.Equal(t, []models.RefreshToken{token}, tokens2)
 }
 
+func testRefreshTokenFindAllOrder(t *testing.T, store data.RefreshTokenStore) {
+	id := 123
+
+	var created []models.RefreshToken
+	for i := 0; i < 3; i++ {
+		token, err := store.Create(id)
+		require.NoError(t, err)
+		created = append(created, token)
+	}
+	err := store.Revoke(created[1])
+	require.NoError(t, err)
+
+	tokens, err := store.FindAll(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.RefreshToken{created[0], created[2]}, tokens)
+}
+
+func testRefreshTokenFindSessions(t *testing.T, store data.RefreshTokenStore) {
+	id := 123
+
+	first, err := store.Create(id)
+	require.NoError(t, err)
+	second, err := store.Create(id)
+	require.NoError(t, err)
+	err = store.SetUserAgent(second, "Mozilla/5.0")
+	require.NoError(t, err)
+	err = store.Touch(second, id)
+	require.NoError(t, err)
+
+	sessions, err := store.FindSessions(id)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, first, sessions[0].Token)
+	assert.Equal(t, "", sessions[0].UserAgent)
+	assert.Equal(t, second, sessions[1].Token)
+	assert.Equal(t, "Mozilla/5.0", sessions[1].UserAgent)
+	for _, session := range sessions {
+		if assert.NotNil(t, session.CreatedAt) {
+			assert.WithinDuration(t, time.Now(), *session.CreatedAt, time.Minute)
+		}
+		if assert.NotNil(t, session.LastSeenAt) {
+			assert.WithinDuration(t, time.Now(), *session.LastSeenAt, time.Minute)
+		}
+	}
+
+	err = store.Revoke(first)
+	require.NoError(t, err)
+	sessions, err = store.FindSessions(id)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, second, sessions[0].Token)
+
+	sessions, err = store.FindSessions(456)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
 func testRefreshTokenCreate(t *testing.T, store data.RefreshTokenStore) {
 	id := 123
 
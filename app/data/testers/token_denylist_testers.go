@@ -0,0 +1,62 @@
+package testers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var TokenDenylistTesters = []func(*testing.T, data.TokenDenylist){
+	testTokenDenylistDeny,
+	testTokenDenylistExpired,
+	testTokenDenylistSpend,
+}
+
+func testTokenDenylistDeny(t *testing.T, denylist data.TokenDenylist) {
+	denied, err := denylist.IsDenied("abc")
+	require.NoError(t, err)
+	assert.False(t, denied)
+
+	err = denylist.Deny("abc", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	denied, err = denylist.IsDenied("abc")
+	require.NoError(t, err)
+	assert.True(t, denied)
+
+	denied, err = denylist.IsDenied("def")
+	require.NoError(t, err)
+	assert.False(t, denied)
+}
+
+func testTokenDenylistExpired(t *testing.T, denylist data.TokenDenylist) {
+	err := denylist.Deny("abc", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	denied, err := denylist.IsDenied("abc")
+	require.NoError(t, err)
+	assert.False(t, denied)
+}
+
+func testTokenDenylistSpend(t *testing.T, denylist data.TokenDenylist) {
+	spent, err := denylist.Spend("abc", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, spent)
+
+	spent, err = denylist.Spend("abc", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, spent)
+
+	err = denylist.Allow("abc")
+	require.NoError(t, err)
+	spent, err = denylist.Spend("abc", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, spent)
+
+	spent, err = denylist.Spend("def", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+	assert.False(t, spent)
+}
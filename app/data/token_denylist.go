@@ -0,0 +1,16 @@
+package data
+
+import "time"
+
+// TokenDenylist remembers revoked access tokens and spent single-use tokens by their jti claim.
+// Entries only need to last until the token would have expired anyway.
+type TokenDenylist interface {
+	// Deny revokes the token until the given expiry
+	Deny(jti string, expiry time.Time) error
+	// IsDenied checks whether the token has been revoked
+	IsDenied(jti string) (bool, error)
+	// Spend denies the token until the given expiry, and reports false if it was already denied
+	Spend(jti string, expiry time.Time) (bool, error)
+	// Allow forgets that the token was denied
+	Allow(jti string) error
+}
@@ -0,0 +1,218 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+// TracedAccountStore records an OpenTelemetry span for each call to the AccountStore it wraps.
+type TracedAccountStore struct {
+	AccountStore
+	// Context is the request whose trace the spans join, if any
+	Context context.Context
+}
+
+func (s *TracedAccountStore) Create(u string, p []byte) (*models.Account, error) {
+	span := startSpan(s.Context, "AccountStore.Create")
+	result, err := s.AccountStore.Create(u, p)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) Find(id int) (*models.Account, error) {
+	span := startSpan(s.Context, "AccountStore.Find")
+	result, err := s.AccountStore.Find(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) FindByUsername(u string) (*models.Account, error) {
+	span := startSpan(s.Context, "AccountStore.FindByUsername")
+	result, err := s.AccountStore.FindByUsername(u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) List(u string, limit int, offset int) ([]*models.Account, error) {
+	span := startSpan(s.Context, "AccountStore.List")
+	result, err := s.AccountStore.List(u, limit, offset)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) Count(u string) (int, error) {
+	span := startSpan(s.Context, "AccountStore.Count")
+	result, err := s.AccountStore.Count(u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) FindByOauthAccount(p string, pid string) (*models.Account, error) {
+	span := startSpan(s.Context, "AccountStore.FindByOauthAccount")
+	result, err := s.AccountStore.FindByOauthAccount(p, pid)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) AddOauthAccount(id int, p string, pid string, tok string) error {
+	span := startSpan(s.Context, "AccountStore.AddOauthAccount")
+	err := s.AccountStore.AddOauthAccount(id, p, pid, tok)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAccountStore) GetOauthAccounts(id int) ([]*models.OauthAccount, error) {
+	span := startSpan(s.Context, "AccountStore.GetOauthAccounts")
+	result, err := s.AccountStore.GetOauthAccounts(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) AddAlias(id int, u string) error {
+	span := startSpan(s.Context, "AccountStore.AddAlias")
+	err := s.AccountStore.AddAlias(id, u)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedAccountStore) GetAliases(id int) ([]*models.AccountAlias, error) {
+	span := startSpan(s.Context, "AccountStore.GetAliases")
+	result, err := s.AccountStore.GetAliases(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) DeleteAlias(id int, u string) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.DeleteAlias")
+	result, err := s.AccountStore.DeleteAlias(id, u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) PromoteAlias(id int, u string) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.PromoteAlias")
+	result, err := s.AccountStore.PromoteAlias(id, u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) Archive(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.Archive")
+	result, err := s.AccountStore.Archive(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) PurgeArchived(before time.Time) (int, error) {
+	span := startSpan(s.Context, "AccountStore.PurgeArchived")
+	result, err := s.AccountStore.PurgeArchived(before)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) Lock(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.Lock")
+	result, err := s.AccountStore.Lock(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) Unlock(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.Unlock")
+	result, err := s.AccountStore.Unlock(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) RequireNewPassword(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.RequireNewPassword")
+	result, err := s.AccountStore.RequireNewPassword(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetPassword(id int, p []byte) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetPassword")
+	result, err := s.AccountStore.SetPassword(id, p)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) RehashPassword(id int, p []byte) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.RehashPassword")
+	result, err := s.AccountStore.RehashPassword(id, p)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) UpdateUsername(id int, u string) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.UpdateUsername")
+	result, err := s.AccountStore.UpdateUsername(id, u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) UpdateVerifiedUsername(id int, u string) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.UpdateVerifiedUsername")
+	result, err := s.AccountStore.UpdateVerifiedUsername(id, u)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetLastLogin(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetLastLogin")
+	result, err := s.AccountStore.SetLastLogin(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetRoles(id int, roles []string) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetRoles")
+	result, err := s.AccountStore.SetRoles(id, roles)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetMetadata(id int, metadata map[string]interface{}) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetMetadata")
+	result, err := s.AccountStore.SetMetadata(id, metadata)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetVerified(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetVerified")
+	result, err := s.AccountStore.SetVerified(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetTOTPSecret(id int, secret []byte) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetTOTPSecret")
+	result, err := s.AccountStore.SetTOTPSecret(id, secret)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) EnableTOTP(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.EnableTOTP")
+	result, err := s.AccountStore.EnableTOTP(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) DeleteTOTP(id int) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.DeleteTOTP")
+	result, err := s.AccountStore.DeleteTOTP(id)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedAccountStore) SetOTPDelivery(id int, enabled bool) (bool, error) {
+	span := startSpan(s.Context, "AccountStore.SetOTPDelivery")
+	result, err := s.AccountStore.SetOTPDelivery(id, enabled)
+	endSpan(span, err)
+	return result, err
+}
@@ -0,0 +1,54 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedAccountStore(t *testing.T) {
+	for _, tester := range testers.AccountStoreTesters {
+		tester(t, &data.TracedAccountStore{AccountStore: mock.NewAccountStore()})
+	}
+
+	// the global provider delegates only to the first one set, so the cases share a recorder
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	t.Run("records spans", func(t *testing.T) {
+		store := &data.TracedAccountStore{AccountStore: mock.NewAccountStore()}
+		_, err := store.Create("traced", []byte("password"))
+		require.NoError(t, err)
+		_, err = store.Create("traced", []byte("password"))
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "AccountStore.Create", spans[0].Name())
+		assert.Empty(t, spans[0].Events())
+		assert.NotEmpty(t, spans[1].Events())
+	})
+
+	t.Run("joins the request's trace", func(t *testing.T) {
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		store := data.AccountStoreWithContext(&data.TracedAccountStore{AccountStore: mock.NewAccountStore()}, ctx)
+		_, err := store.Create("joined", []byte("password"))
+		require.NoError(t, err)
+		parent.End()
+
+		spans := recorder.Ended()[2:]
+		require.Len(t, spans, 2)
+		assert.Equal(t, "AccountStore.Create", spans[0].Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	})
+}
@@ -0,0 +1,72 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+)
+
+// TracedRefreshTokenStore records an OpenTelemetry span for each call to the RefreshTokenStore it
+// wraps.
+type TracedRefreshTokenStore struct {
+	RefreshTokenStore
+	// Context is the request whose trace the spans join, if any
+	Context context.Context
+}
+
+func (s *TracedRefreshTokenStore) Create(accountID int) (models.RefreshToken, error) {
+	span := startSpan(s.Context, "RefreshTokenStore.Create")
+	result, err := s.RefreshTokenStore.Create(accountID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedRefreshTokenStore) Find(t models.RefreshToken) (int, error) {
+	span := startSpan(s.Context, "RefreshTokenStore.Find")
+	result, err := s.RefreshTokenStore.Find(t)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedRefreshTokenStore) Touch(t models.RefreshToken, accountID int) error {
+	span := startSpan(s.Context, "RefreshTokenStore.Touch")
+	err := s.RefreshTokenStore.Touch(t, accountID)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedRefreshTokenStore) TouchFor(t models.RefreshToken, accountID int, ttl time.Duration) error {
+	span := startSpan(s.Context, "RefreshTokenStore.TouchFor")
+	err := s.RefreshTokenStore.TouchFor(t, accountID, ttl)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedRefreshTokenStore) FindAll(accountID int) ([]models.RefreshToken, error) {
+	span := startSpan(s.Context, "RefreshTokenStore.FindAll")
+	result, err := s.RefreshTokenStore.FindAll(accountID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedRefreshTokenStore) SetUserAgent(t models.RefreshToken, userAgent string) error {
+	span := startSpan(s.Context, "RefreshTokenStore.SetUserAgent")
+	err := s.RefreshTokenStore.SetUserAgent(t, userAgent)
+	endSpan(span, err)
+	return err
+}
+
+func (s *TracedRefreshTokenStore) FindSessions(accountID int) ([]models.Session, error) {
+	span := startSpan(s.Context, "RefreshTokenStore.FindSessions")
+	result, err := s.RefreshTokenStore.FindSessions(accountID)
+	endSpan(span, err)
+	return result, err
+}
+
+func (s *TracedRefreshTokenStore) Revoke(t models.RefreshToken) error {
+	span := startSpan(s.Context, "RefreshTokenStore.Revoke")
+	err := s.RefreshTokenStore.Revoke(t)
+	endSpan(span, err)
+	return err
+}
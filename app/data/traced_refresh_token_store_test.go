@@ -0,0 +1,15 @@
+package data_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/testers"
+)
+
+func TestTracedRefreshTokenStore(t *testing.T) {
+	for _, tester := range testers.RefreshTokenStoreTesters {
+		tester(t, &data.TracedRefreshTokenStore{RefreshTokenStore: mock.NewRefreshTokenStore()})
+	}
+}
@@ -0,0 +1,47 @@
+package data
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/keratin/authn-server/app/data")
+
+// startSpan begins a span for a store operation. The span joins the trace in ctx, or begins its
+// own when the store was not given a request's context.
+func startSpan(ctx context.Context, name string) trace.Span {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	_, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+	return span
+}
+
+// AccountStoreWithContext returns an AccountStore whose spans join the trace in ctx, when the
+// store is traced.
+func AccountStoreWithContext(store AccountStore, ctx context.Context) AccountStore {
+	if traced, ok := store.(*TracedAccountStore); ok {
+		return &TracedAccountStore{AccountStore: traced.AccountStore, Context: ctx}
+	}
+	return store
+}
+
+// RefreshTokenStoreWithContext returns a RefreshTokenStore whose spans join the trace in ctx,
+// when the store is traced.
+func RefreshTokenStoreWithContext(store RefreshTokenStore, ctx context.Context) RefreshTokenStore {
+	if traced, ok := store.(*TracedRefreshTokenStore); ok {
+		return &TracedRefreshTokenStore{RefreshTokenStore: traced.RefreshTokenStore, Context: ctx}
+	}
+	return store
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	dataRedis "github.com/keratin/authn-server/app/data/redis"
+	"github.com/pkg/errors"
+)
+
+// Check is the outcome of one step in a Diagnose report. A nil Err means the check passed.
+type Check struct {
+	Name string
+	Err  error
+}
+
+// Diagnose validates configuration and connectivity without starting a server. Unlike ReadEnv, it
+// runs every configurer and reports every problem it finds, so that a misconfigured deployment can
+// be fixed in one pass.
+func Diagnose() []Check {
+	err := loadSources()
+	if err != nil {
+		return []Check{{"sources", err}}
+	}
+
+	checks := []Check{}
+	cfg, errs := configureAll(configurers)
+	for _, err := range errs {
+		checks = append(checks, Check{"config", err})
+	}
+	if len(errs) == 0 {
+		checks = append(checks, Check{"config", nil})
+	}
+
+	if cfg.IdentitySigningKey != nil {
+		checks = append(checks, Check{"IDENTITY_SIGNING_KEY", nil})
+	}
+
+	if cfg.DatabaseURL != nil {
+		checks = append(checks, Check{"DATABASE_URL", pingDB(cfg)})
+	}
+
+	if cfg.RedisURL != nil {
+		checks = append(checks, Check{"REDIS_URL", pingRedis(cfg)})
+	}
+
+	return checks
+}
+
+func pingDB(cfg *Config) error {
+	db, err := data.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		return errors.Wrap(err, "NewDB")
+	}
+	defer db.Close()
+	return errors.Wrap(db.Ping(), "Ping")
+}
+
+func pingRedis(cfg *Config) error {
+	client, err := dataRedis.New(cfg.RedisURL)
+	if err != nil {
+		return errors.Wrap(err, "redis.New")
+	}
+	defer client.Close()
+	return errors.Wrap(client.Ping().Err(), "Ping")
+}
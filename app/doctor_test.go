@@ -0,0 +1,30 @@
+package app
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnose(t *testing.T) {
+	for _, name := range []string{"AUTHN_URL", "APP_DOMAINS", "SECRET_KEY_BASE", "DATABASE_URL", "REDIS_URL", "CONFIG_FILE", "VAULT_SECRET_PATH"} {
+		if val, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, val)
+			os.Unsetenv(name)
+		}
+	}
+	os.Setenv("BCRYPT_COST", "high")
+	defer os.Unsetenv("BCRYPT_COST")
+
+	errs := []string{}
+	for _, check := range Diagnose() {
+		if check.Err != nil {
+			errs = append(errs, check.Err.Error())
+		}
+	}
+	assert.Contains(t, errs, "missing environment variable: AUTHN_URL")
+	assert.Contains(t, errs, "missing environment variable: APP_DOMAINS")
+	assert.Contains(t, errs, "missing environment variable: SECRET_KEY_BASE")
+	assert.Contains(t, errs, `invalid environment variable: BCRYPT_COST: strconv.Atoi: parsing "high": invalid syntax`)
+}
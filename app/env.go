@@ -1,6 +1,7 @@
 package app
 
 import (
+	"fmt"
 	"net/url"
 	"os"
 	"regexp"
@@ -13,8 +14,84 @@ func (name ErrMissingEnvVar) Error() string {
 	return "missing environment variable: " + string(name)
 }
 
-func requireEnv(name string) (string, error) {
+// ErrInvalidEnvVar describes a variable that was set but could not be parsed.
+type ErrInvalidEnvVar struct {
+	Name string
+	Err  error
+}
+
+func (e ErrInvalidEnvVar) Error() string {
+	return "invalid environment variable: " + e.Name + ": " + e.Err.Error()
+}
+
+// source is a provider of settings other than the process environment, like a config file.
+type source interface {
+	Lookup(name string) (string, bool)
+	Values() map[string]string
+}
+
+// sources are consulted in order for any setting that is missing from the process environment.
+// The environment always takes precedence so that a single value may be overridden at boot.
+var sources []source
+
+// references maps settings that refer to an external secret store (e.g. ssm://authn/secret) to
+// the values they were resolved to. Only settings that AuthN reads are resolved, so unrelated
+// variables in the environment are never fetched.
+var references map[string]string
+
+// resolver fetches references. It is connected when the first reference is read.
+var resolver referenceResolver
+
+// referenceErrors collects the settings whose references could not be resolved, until they are
+// reported by configureAll.
+var referenceErrors ConfigErrors
+
+func lookupEnv(name string) (string, bool) {
+	val, ok := lookupRaw(name)
+	if !ok || !isAWSReference(val) {
+		return val, ok
+	}
+	if resolved, found := references[val]; found {
+		return resolved, true
+	}
+
+	resolved, err := resolveReference(val)
+	if err != nil {
+		referenceErrors = append(referenceErrors, fmt.Errorf("%s: %v", name, err))
+		return "", false
+	}
+	if references == nil {
+		references = map[string]string{}
+	}
+	references[val] = resolved
+	return resolved, true
+}
+
+func resolveReference(ref string) (string, error) {
+	if resolver == nil {
+		r, err := newReferenceResolver()
+		if err != nil {
+			return "", err
+		}
+		resolver = r
+	}
+	return resolver.Resolve(ref)
+}
+
+func lookupRaw(name string) (string, bool) {
 	if val, ok := os.LookupEnv(name); ok {
+		return val, true
+	}
+	for _, s := range sources {
+		if val, ok := s.Lookup(name); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+func requireEnv(name string) (string, error) {
+	if val, ok := lookupEnv(name); ok {
 		return val, nil
 	}
 
@@ -22,15 +99,19 @@ func requireEnv(name string) (string, error) {
 }
 
 func lookupInt(name string, def int) (int, error) {
-	if val, ok := os.LookupEnv(name); ok {
-		return strconv.Atoi(val)
+	if val, ok := lookupEnv(name); ok {
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			return 0, ErrInvalidEnvVar{name, err}
+		}
+		return i, nil
 	}
 
 	return def, nil
 }
 
 func lookupBool(name string, def bool) (bool, error) {
-	if val, ok := os.LookupEnv(name); ok {
+	if val, ok := lookupEnv(name); ok {
 		return regexp.MatchString("^(?i:t|true|yes)$", val)
 	}
 
@@ -38,8 +119,12 @@ func lookupBool(name string, def bool) (bool, error) {
 }
 
 func lookupURL(name string) (*url.URL, error) {
-	if val, ok := os.LookupEnv(name); ok {
-		return url.Parse(val)
+	if val, ok := lookupEnv(name); ok {
+		u, err := url.Parse(val)
+		if err != nil {
+			return nil, ErrInvalidEnvVar{name, err}
+		}
+		return u, nil
 	}
 	return nil, nil
 }
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// keyDeriver derives a key for a single purpose (identified by salt) from SECRET_KEY_BASE.
+type keyDeriver func(base []byte, salt string) ([]byte, error)
+
+const (
+	derivedKeyLength        = 128
+	defaultPBKDF2Iterations = 2e4
+)
+
+// parseKeyDerivation reads a KEY_DERIVATION setting:
+//
+// * pbkdf2 is PBKDF2 HMAC SHA-256 with 20k iterations, the original derivation
+// * pbkdf2:N is PBKDF2 HMAC SHA-256 with N iterations
+// * hkdf is HKDF SHA-256, which is fast and appropriate when SECRET_KEY_BASE is already random
+//
+// Each setting derives different signing keys, so changing it will end existing sessions and
+// pending password reset or passwordless tokens. The DB encryption key is not affected: it always
+// uses the original derivation (see legacyDBEncryptionKey) so that data encrypted at rest remains
+// readable.
+func parseKeyDerivation(str string) (keyDeriver, error) {
+	pieces := strings.SplitN(strings.ToLower(str), ":", 2)
+	switch pieces[0] {
+	case "pbkdf2":
+		if len(pieces) == 1 {
+			return derivePBKDF2(defaultPBKDF2Iterations), nil
+		}
+		iterations, err := strconv.Atoi(pieces[1])
+		if err != nil || iterations < 1 {
+			return nil, fmt.Errorf("invalid iteration count: %s", pieces[1])
+		}
+		return derivePBKDF2(iterations), nil
+	case "hkdf":
+		if len(pieces) > 1 {
+			return nil, fmt.Errorf("hkdf does not accept parameters")
+		}
+		return deriveHKDF, nil
+	default:
+		return nil, fmt.Errorf("unsupported key derivation: %s (expected pbkdf2 or hkdf)", pieces[0])
+	}
+}
+
+func derivePBKDF2(iterations int) keyDeriver {
+	return func(base []byte, salt string) ([]byte, error) {
+		return pbkdf2.Key(base, []byte(salt), iterations, derivedKeyLength, sha256.New), nil
+	}
+}
+
+func deriveHKDF(base []byte, salt string) ([]byte, error) {
+	key := make([]byte, derivedKeyLength)
+	// the salt names the purpose of the key, which is what HKDF calls info
+	_, err := io.ReadFull(hkdf.New(sha256.New, base, nil, []byte(salt)), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "hkdf")
+	}
+	return key, nil
+}
+
+// legacyDBEncryptionKey derives the key that encrypts generated signing keys and TOTP secrets. It
+// ignores KEY_DERIVATION, since changing this key would leave those secrets unreadable.
+func legacyDBEncryptionKey(base []byte) []byte {
+	return pbkdf2.Key(base, []byte("db-encryption-key-salt"), defaultPBKDF2Iterations, derivedKeyLength, sha256.New)[:32]
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDerive(t *testing.T, derive keyDeriver, base []byte, salt string) []byte {
+	key, err := derive(base, salt)
+	require.NoError(t, err)
+	return key
+}
+
+func TestParseKeyDerivation(t *testing.T) {
+	base := []byte("secret")
+
+	t.Run("pbkdf2 defaults to the original derivation", func(t *testing.T) {
+		derive, err := parseKeyDerivation("pbkdf2")
+		require.NoError(t, err)
+		assert.Equal(t, mustDerive(t, derivePBKDF2(20000), base, "salt"), mustDerive(t, derive, base, "salt"))
+		assert.Len(t, mustDerive(t, derive, base, "salt"), 128)
+	})
+
+	t.Run("pbkdf2 with iterations", func(t *testing.T) {
+		derive, err := parseKeyDerivation("PBKDF2:1000")
+		require.NoError(t, err)
+		assert.NotEqual(t, mustDerive(t, derivePBKDF2(20000), base, "salt"), mustDerive(t, derive, base, "salt"))
+		assert.Equal(t, mustDerive(t, derivePBKDF2(1000), base, "salt"), mustDerive(t, derive, base, "salt"))
+	})
+
+	t.Run("hkdf", func(t *testing.T) {
+		derive, err := parseKeyDerivation("hkdf")
+		require.NoError(t, err)
+		assert.Len(t, mustDerive(t, derive, base, "salt"), 128)
+		assert.Equal(t, mustDerive(t, derive, base, "salt"), mustDerive(t, derive, base, "salt"))
+		assert.NotEqual(t, mustDerive(t, derive, base, "salt"), mustDerive(t, derive, base, "other"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, str := range []string{"scrypt", "pbkdf2:many", "pbkdf2:0", "hkdf:1"} {
+			_, err := parseKeyDerivation(str)
+			assert.Error(t, err, str)
+		}
+	})
+}
+
+func TestLegacyDBEncryptionKey(t *testing.T) {
+	base := []byte("secret")
+	key := legacyDBEncryptionKey(base)
+	assert.Len(t, key, 32)
+	assert.Equal(t, mustDerive(t, derivePBKDF2(20000), base, "db-encryption-key-salt")[:32], key)
+}
@@ -1,20 +1,103 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type Account struct {
-	ID                 int
-	Username           string
-	Password           []byte
-	Locked             bool
-	RequireNewPassword bool       `db:"require_new_password"`
-	PasswordChangedAt  time.Time  `db:"password_changed_at"`
-	LastLoginAt        *time.Time `db:"last_login_at"`
-	CreatedAt          time.Time  `db:"created_at"`
-	UpdatedAt          time.Time  `db:"updated_at"`
-	DeletedAt          *time.Time `db:"deleted_at"`
+	ID                   int
+	Username             string
+	Password             []byte
+	Locked               bool
+	RequireNewPassword   bool       `db:"require_new_password"`
+	PasswordChangedAt    time.Time  `db:"password_changed_at"`
+	LastLoginAt          *time.Time `db:"last_login_at"`
+	Roles                Roles      `db:"roles"`
+	Metadata             Metadata   `db:"metadata"`
+	EmailVerifiedAt      *time.Time `db:"email_verified_at"`
+	TOTPSecret           []byte     `db:"totp_secret"`
+	TOTPEnabledAt        *time.Time `db:"totp_enabled_at"`
+	OTPDeliveryEnabledAt *time.Time `db:"otp_delivery_enabled_at"`
+	CreatedAt            time.Time  `db:"created_at"`
+	UpdatedAt            time.Time  `db:"updated_at"`
+	DeletedAt            *time.Time `db:"deleted_at"`
 }
 
 func (a Account) Archived() bool {
 	return a.DeletedAt != nil
 }
+
+// Verified is true once the account has proven control of its username (as an email).
+func (a Account) Verified() bool {
+	return a.EmailVerifiedAt != nil
+}
+
+// TOTPEnabled is true once an enrolled authenticator has been confirmed with a valid code.
+func (a Account) TOTPEnabled() bool {
+	return a.TOTPSecret != nil && a.TOTPEnabledAt != nil
+}
+
+// OTPDeliveryEnabled is true when the app has enabled codes delivered by APP_OTP_DELIVERY_URL.
+func (a Account) OTPDeliveryEnabled() bool {
+	return a.OTPDeliveryEnabledAt != nil
+}
+
+// RequiresOTP is true when a second factor is required at login.
+func (a Account) RequiresOTP() bool {
+	return a.TOTPEnabled() || a.OTPDeliveryEnabled()
+}
+
+// Roles are coarse permissions that the application assigns to an account. They are stored as a
+// JSON array, and are NULL when the account has no roles.
+type Roles []string
+
+func (r *Roles) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case nil:
+		*r = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(val, r)
+	case string:
+		return json.Unmarshal([]byte(val), r)
+	default:
+		return fmt.Errorf("unsupported roles: %T", src)
+	}
+}
+
+func (r Roles) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+	j, err := json.Marshal([]string(r))
+	return string(j), err
+}
+
+// Metadata is a JSON object that the application stores with an account, like a tenant ID or
+// feature flags. It is NULL when the account has no metadata.
+type Metadata map[string]interface{}
+
+func (m *Metadata) Scan(src interface{}) error {
+	switch val := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(val, m)
+	case string:
+		return json.Unmarshal([]byte(val), m)
+	default:
+		return fmt.Errorf("unsupported metadata: %T", src)
+	}
+}
+
+func (m Metadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	j, err := json.Marshal(map[string]interface{}(m))
+	return string(j), err
+}
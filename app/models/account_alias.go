@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// AccountAlias is an additional username that logs in to an account. The account's own username
+// remains the primary identifier.
+type AccountAlias struct {
+	ID        int
+	AccountID int `db:"account_id"`
+	Username  string
+	// VerifiedAt is carried over from the account when a verified username becomes an alias
+	VerifiedAt *time.Time `db:"verified_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Actors in the audit log
+const (
+	// ActorAccount is the account itself, as authenticated by a session or credentials
+	ActorAccount = "account"
+	// ActorAdmin is a client of the private API
+	ActorAdmin = "admin"
+	// ActorRPC is a client of the gRPC API
+	ActorRPC = "rpc"
+	// ActorAnonymous is a client that did not authenticate, e.g. with a failed login
+	ActorAnonymous = "anonymous"
+)
+
+// AuditEntry records a security-relevant action. The AccountID is 0 when no account was
+// identified, and the Username is the one given for a login, if any.
+type AuditEntry struct {
+	ID        int       `db:"id"`
+	Action    string    `db:"action"`
+	AccountID int       `db:"account_id"`
+	Username  string    `db:"username"`
+	Actor     string    `db:"actor"`
+	IP        string    `db:"ip"`
+	UserAgent string    `db:"user_agent"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// AuditFilter narrows a query of the audit log. Zero values match every entry.
+type AuditFilter struct {
+	AccountID int
+	Action    string
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Event is a change to an account that is delivered to the APP_EVENTS_URLS. The ID is the same
+// for every delivery of the event, so that endpoints may ignore duplicates.
+type Event struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	AccountID  int       `json:"account_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventDelivery is an Event on its way to one endpoint.
+type EventDelivery struct {
+	ID       string `json:"id"`
+	URL      string `json:"url"`
+	Attempts int    `json:"attempts"`
+	Event    Event  `json:"event"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "net/http"
+
+// IdempotentResponse is a response that is replayed to retries of a request with the same
+// Idempotency-Key. The Fingerprint identifies the request, so that the key is not reused for a
+// different one.
+type IdempotentResponse struct {
+	Fingerprint string      `json:"fingerprint"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header"`
+	Body        []byte      `json:"body"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Period is the length of time over which accounts are counted
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Weekly  Period = "weekly"
+	Monthly Period = "monthly"
+)
+
+// PeriodStarts returns the start of every period from the one containing `from` through the one
+// containing `to`. Weeks start on Monday, as in ISO 8601.
+func PeriodStarts(period Period, from time.Time, to time.Time) []time.Time {
+	var next func(time.Time) time.Time
+	switch period {
+	case Weekly:
+		from = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case Monthly:
+		from = from.AddDate(0, 0, 1-from.Day())
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	}
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	var starts []time.Time
+	for t := from; !t.After(to); t = next(t) {
+		starts = append(starts, t)
+	}
+	return starts
+}
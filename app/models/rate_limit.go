@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// RateLimit describes a bucket after a Take.
+type RateLimit struct {
+	// Allowed is true when a token was spent.
+	Allowed bool
+	// Remaining is how many whole tokens are left.
+	Remaining int
+	// RetryAfter is how long until a token is available, when none were left.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full.
+	ResetAfter time.Duration
+}
+
+// NewRateLimit describes a bucket with the given (fractional) tokens after a Take.
+func NewRateLimit(allowed bool, tokens float64, limit int, window time.Duration) *RateLimit {
+	perToken := float64(window) / float64(limit)
+	rl := &RateLimit{
+		Allowed:    allowed,
+		Remaining:  int(tokens),
+		ResetAfter: time.Duration((float64(limit) - tokens) * perToken),
+	}
+	if !allowed {
+		rl.RetryAfter = time.Duration((1 - tokens) * perToken)
+	}
+	return rl
+}
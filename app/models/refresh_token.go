@@ -1,3 +1,15 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
 type RefreshToken string
+
+// ID identifies the token without revealing it, so that a session may be referenced by clients
+// that do not hold it.
+func (t RefreshToken) ID() string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Session describes the client and activity of a refresh token. Times are nil for tokens that were
+// created before they were recorded.
+type Session struct {
+	Token      RefreshToken `db:"token"`
+	UserAgent  string       `db:"user_agent"`
+	CreatedAt  *time.Time   `db:"created_at"`
+	LastSeenAt *time.Time   `db:"last_seen_at"`
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
+)
+
+// Reload applies the settings from cfg that may change while the server is running: password
+// policy, username domains, and the lifetimes of password reset, passwordless, and access tokens,
+// and of sessions that are not remembered.
+//
+// With generated signing keys, ACCESS_TOKEN_TTL may not outlive the KEY_ROTATION_INTERVAL that the
+// server started with, since tokens must expire before their signing key is retired. A longer value
+// is ignored until restart.
+//
+// Configured signing keys are also replaced, so that a rotated IDENTITY_SIGNING_KEY is used (and
+// published) without a restart. Changing between configured and generated keys still requires a
+// restart.
+//
+// Settings that shaped the App's connections and stores (databases, SECRET_KEY_BASE, ports) are
+// ignored and still require a restart. This includes REFRESH_TOKEN_TTL, which the refresh token
+// store was created with.
+//
+// The new settings are copied into a fresh Config and swapped in atomically, so that any request
+// already holding the previous Config sees a consistent set of values.
+func (app *App) Reload(cfg *Config) {
+	next := *app.Config()
+	next.BcryptCost = cfg.BcryptCost
+	next.PasswordMinComplexity = cfg.PasswordMinComplexity
+	next.PasswordMaxLength = cfg.PasswordMaxLength
+	next.UsernameDomains = cfg.UsernameDomains
+	next.ResetTokenTTL = cfg.ResetTokenTTL
+	next.PasswordlessTokenTTL = cfg.PasswordlessTokenTTL
+	if cfg.EphemeralRefreshTokenTTL <= next.RefreshTokenTTL {
+		next.EphemeralRefreshTokenTTL = cfg.EphemeralRefreshTokenTTL
+	}
+	if _, rotating := app.KeyStore.(*data.RotatingKeyStore); !rotating || cfg.AccessTokenTTL <= next.KeyRotationInterval {
+		next.AccessTokenTTL = cfg.AccessTokenTTL
+	}
+
+	if ks, ok := app.KeyStore.(*data.StaticKeyStore); ok && cfg.IdentitySigningKey != nil {
+		next.IdentitySigningKey = cfg.IdentitySigningKey
+		next.PreviousIdentitySigningKeys = cfg.PreviousIdentitySigningKeys
+		ks.Replace(append(append([]*private.Key{}, cfg.PreviousIdentitySigningKeys...), cfg.IdentitySigningKey)...)
+	}
+
+	app.SetConfig(&next)
+}
@@ -0,0 +1,97 @@
+package app_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	redisURL, _ := url.Parse("redis://localhost/0")
+	original := &app.Config{
+		BcryptCost:            10,
+		PasswordMinComplexity: 2,
+		ResetTokenTTL:         time.Hour,
+		RefreshTokenTTL:       time.Hour,
+		AccessTokenTTL:        time.Hour,
+		RedisURL:              redisURL,
+	}
+	a := &app.App{}
+	a.SetConfig(original)
+
+	a.Reload(&app.Config{
+		BcryptCost:               12,
+		PasswordMinComplexity:    3,
+		UsernameDomains:          []string{"example.com"},
+		ResetTokenTTL:            time.Minute,
+		PasswordlessTokenTTL:     time.Minute,
+		RefreshTokenTTL:          time.Minute,
+		AccessTokenTTL:           time.Minute,
+		EphemeralRefreshTokenTTL: time.Minute,
+	})
+
+	assert.Equal(t, 12, a.Config().BcryptCost)
+	assert.Equal(t, 3, a.Config().PasswordMinComplexity)
+	assert.Equal(t, []string{"example.com"}, a.Config().UsernameDomains)
+	assert.Equal(t, time.Minute, a.Config().ResetTokenTTL)
+	assert.Equal(t, time.Minute, a.Config().PasswordlessTokenTTL)
+	assert.Equal(t, time.Minute, a.Config().AccessTokenTTL)
+	assert.Equal(t, time.Minute, a.Config().EphemeralRefreshTokenTTL)
+
+	// structural settings are unchanged
+	assert.Equal(t, time.Hour, a.Config().RefreshTokenTTL)
+	assert.Equal(t, redisURL, a.Config().RedisURL)
+
+	// the previous config is untouched
+	assert.Equal(t, 10, original.BcryptCost)
+}
+
+func TestReloadAccessTokenTTL(t *testing.T) {
+	a := &app.App{KeyStore: data.NewRotatingKeyStore()}
+	a.SetConfig(&app.Config{AccessTokenTTL: time.Hour, KeyRotationInterval: 2 * time.Hour})
+
+	a.Reload(&app.Config{AccessTokenTTL: 3 * time.Hour, KeyRotationInterval: 3 * time.Hour})
+	assert.Equal(t, time.Hour, a.Config().AccessTokenTTL, "may not outlive generated keys")
+
+	a.Reload(&app.Config{AccessTokenTTL: 2 * time.Hour})
+	assert.Equal(t, 2*time.Hour, a.Config().AccessTokenTTL)
+}
+
+func TestReloadConcurrently(t *testing.T) {
+	a := &app.App{}
+	a.SetConfig(&app.Config{BcryptCost: 10})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			a.Reload(&app.Config{BcryptCost: 10 + i%3})
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		assert.True(t, a.Config().BcryptCost >= 10)
+	}
+	<-done
+}
+
+func TestReloadSigningKeys(t *testing.T) {
+	k1, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	k2, err := private.GenerateKey(512)
+	require.NoError(t, err)
+
+	ks := data.NewStaticKeyStore(k1)
+	a := &app.App{KeyStore: ks}
+	a.SetConfig(&app.Config{IdentitySigningKey: k1})
+
+	a.Reload(&app.Config{IdentitySigningKey: k2, PreviousIdentitySigningKeys: []*private.Key{k1}})
+	assert.Equal(t, k2, a.Config().IdentitySigningKey)
+	assert.Equal(t, k2, ks.Key())
+	assert.Equal(t, []*private.Key{k1, k2}, ks.Keys())
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/pkg/errors"
+)
+
+// AccessTokenRevoker adds an identity token to the denylist until it expires. When only the jti is
+// known, it is denied for the longest possible lifetime of a token. Opaque tokens are forgotten, if
+// a store is given.
+func AccessTokenRevoker(denylist data.TokenDenylist, keyStore data.KeyStore, accessTokenStore data.AccessTokenStore, cfg *app.Config, token string, jti string) error {
+	expiry := time.Now().Add(cfg.AccessTokenTTL)
+	if token != "" && accessTokenStore != nil {
+		identityToken, err := accessTokenStore.Find(token)
+		if err != nil {
+			return errors.Wrap(err, "Find")
+		}
+		if identityToken != "" {
+			return errors.Wrap(accessTokenStore.Revoke(token), "Revoke")
+		}
+	}
+	if token != "" {
+		identity, err := identities.Parse(token, cfg, keyStore.Keys())
+		if err != nil {
+			return FieldErrors{{"token", ErrInvalidOrExpired}}
+		}
+		jti = identity.ID
+		expiry = identity.Expiry.Time()
+	}
+	if jti == "" {
+		return FieldErrors{{"jti", ErrMissing}}
+	}
+
+	err := denylist.Deny(jti, expiry)
+	if err != nil {
+		return errors.Wrap(err, "Deny")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessTokenRevoker(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	session, err := sessions.New(mock.NewRefreshTokenStore(), cfg, 42, "example.com")
+	require.NoError(t, err)
+	identity := identities.New(cfg, session, 42, "example.com")
+	identityToken, err := identity.Sign(key)
+	require.NoError(t, err)
+
+	t.Run("revoking a token", func(t *testing.T) {
+		denylist := mock.NewTokenDenylist()
+		err := services.AccessTokenRevoker(denylist, keyStore, nil, cfg, identityToken, "")
+		require.NoError(t, err)
+		denied, err := denylist.IsDenied(identity.ID)
+		require.NoError(t, err)
+		assert.True(t, denied)
+	})
+
+	t.Run("revoking a jti", func(t *testing.T) {
+		denylist := mock.NewTokenDenylist()
+		err := services.AccessTokenRevoker(denylist, keyStore, nil, cfg, "", "abc")
+		require.NoError(t, err)
+		denied, err := denylist.IsDenied("abc")
+		require.NoError(t, err)
+		assert.True(t, denied)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		err := services.AccessTokenRevoker(mock.NewTokenDenylist(), keyStore, nil, cfg, "invalid", "")
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("missing token and jti", func(t *testing.T) {
+		err := services.AccessTokenRevoker(mock.NewTokenDenylist(), keyStore, nil, cfg, "", "")
+		assert.Equal(t, services.FieldErrors{{"jti", services.ErrMissing}}, err)
+	})
+}
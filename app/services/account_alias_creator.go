@@ -0,0 +1,57 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// AccountAliasCreator adds another username that logs in to the account, like a second email.
+// The alias must be valid and unused by any account, whether as a username or an alias.
+func AccountAliasCreator(store data.AccountStore, cfg *app.Config, accountID int, username string) error {
+	username = strings.TrimSpace(username)
+
+	fieldError := UsernameValidator(cfg, username)
+	if fieldError != nil {
+		return FieldErrors{*fieldError}
+	}
+
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	existing, err := store.FindByUsername(username)
+	if err != nil {
+		return errors.Wrap(err, "FindByUsername")
+	}
+	if existing != nil {
+		return FieldErrors{{"username", ErrTaken}}
+	}
+
+	err = store.AddAlias(accountID, username)
+	if err != nil {
+		if data.IsUniquenessError(err) {
+			return FieldErrors{{"username", ErrTaken}}
+		}
+
+		return errors.Wrap(err, "AddAlias")
+	}
+
+	return nil
+}
+
+// usernameAvailable checks that a username is not an alias of another account. Usernames are
+// unique among accounts, but aliases are stored apart and would not collide on their own.
+func usernameAvailable(store data.AccountStore, accountID int, username string) (bool, error) {
+	existing, err := store.FindByUsername(username)
+	if err != nil {
+		return false, errors.Wrap(err, "FindByUsername")
+	}
+	return existing == nil || existing.ID == accountID, nil
+}
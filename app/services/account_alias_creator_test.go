@@ -0,0 +1,57 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAliasCreator(t *testing.T) {
+	store := mock.NewAccountStore()
+	cfg := &app.Config{UsernameIsEmail: true}
+
+	account, err := store.Create("home@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+	other, err := store.Create("other@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+	err = store.AddAlias(other.ID, "alias@keratin.tech")
+	require.NoError(t, err)
+	archived, err := store.Create("archived@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+	store.Archive(archived.ID)
+
+	t.Run("with a new username", func(t *testing.T) {
+		err := services.AccountAliasCreator(store, cfg, account.ID, " work@keratin.tech ")
+		require.NoError(t, err)
+
+		found, err := store.FindByUsername("work@keratin.tech")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, account.ID, found.ID)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		testCases := []struct {
+			accountID int
+			username  string
+			errors    services.FieldErrors
+		}{
+			{account.ID, "invalid", services.FieldErrors{{"username", services.ErrFormatInvalid}}},
+			{account.ID, "home@keratin.tech", services.FieldErrors{{"username", services.ErrTaken}}},
+			{account.ID, "work@keratin.tech", services.FieldErrors{{"username", services.ErrTaken}}},
+			{account.ID, "other@keratin.tech", services.FieldErrors{{"username", services.ErrTaken}}},
+			{account.ID, "alias@keratin.tech", services.FieldErrors{{"username", services.ErrTaken}}},
+			{archived.ID, "unused@keratin.tech", services.FieldErrors{{"account", services.ErrNotFound}}},
+			{0, "unused@keratin.tech", services.FieldErrors{{"account", services.ErrNotFound}}},
+		}
+
+		for _, tc := range testCases {
+			err := services.AccountAliasCreator(store, cfg, tc.accountID, tc.username)
+			assert.Equal(t, tc.errors, err, tc.username)
+		}
+	})
+}
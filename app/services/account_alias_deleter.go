@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// AccountAliasDeleter removes an alias, so that it no longer logs in to the account.
+func AccountAliasDeleter(store data.AccountStore, accountID int, username string) error {
+	ok, err := store.DeleteAlias(accountID, username)
+	if err != nil {
+		return errors.Wrap(err, "DeleteAlias")
+	}
+	if !ok {
+		return FieldErrors{{"alias", ErrNotFound}}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAliasDeleter(t *testing.T) {
+	store := mock.NewAccountStore()
+	account, err := store.Create("home@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+
+	t.Run("unknown alias", func(t *testing.T) {
+		err := services.AccountAliasDeleter(store, account.ID, "home@keratin.tech")
+		assert.Equal(t, services.FieldErrors{{"alias", services.ErrNotFound}}, err)
+	})
+
+	t.Run("known alias", func(t *testing.T) {
+		err := services.AccountAliasDeleter(store, account.ID, "work@keratin.tech")
+		require.NoError(t, err)
+
+		found, err := store.FindByUsername("work@keratin.tech")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
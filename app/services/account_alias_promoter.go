@@ -0,0 +1,29 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// AccountAliasPromoter makes an alias the primary username of its account. The old username
+// becomes an alias in its place, so that it still logs in, and the account is verified only when
+// the promoted alias was verified as its username before.
+func AccountAliasPromoter(store data.AccountStore, accountID int, username string) error {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	ok, err := store.PromoteAlias(accountID, username)
+	if err != nil {
+		return errors.Wrap(err, "PromoteAlias")
+	}
+	if !ok {
+		return FieldErrors{{"alias", ErrNotFound}}
+	}
+
+	return nil
+}
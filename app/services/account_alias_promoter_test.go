@@ -0,0 +1,68 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAliasPromoter(t *testing.T) {
+	store := mock.NewAccountStore()
+	account, err := store.Create("home@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+	_, err = store.SetVerified(account.ID)
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "work@keratin.tech")
+	require.NoError(t, err)
+
+	t.Run("known alias", func(t *testing.T) {
+		err := services.AccountAliasPromoter(store, account.ID, "work@keratin.tech")
+		require.NoError(t, err)
+
+		found, err := store.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "work@keratin.tech", found.Username)
+		assert.False(t, found.Verified())
+
+		aliases, err := store.GetAliases(account.ID)
+		require.NoError(t, err)
+		if assert.Len(t, aliases, 1) {
+			assert.Equal(t, "home@keratin.tech", aliases[0].Username)
+		}
+
+		found, err = store.FindByUsername("home@keratin.tech")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, account.ID, found.ID)
+	})
+
+	t.Run("formerly verified username", func(t *testing.T) {
+		err := services.AccountAliasPromoter(store, account.ID, "home@keratin.tech")
+		require.NoError(t, err)
+
+		found, err := store.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "home@keratin.tech", found.Username)
+		assert.True(t, found.Verified())
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		testCases := []struct {
+			accountID int
+			username  string
+			errors    services.FieldErrors
+		}{
+			{account.ID, "home@keratin.tech", services.FieldErrors{{"alias", services.ErrNotFound}}},
+			{account.ID, "unknown@keratin.tech", services.FieldErrors{{"alias", services.ErrNotFound}}},
+			{0, "home@keratin.tech", services.FieldErrors{{"account", services.ErrNotFound}}},
+		}
+
+		for _, tc := range testCases {
+			err := services.AccountAliasPromoter(store, tc.accountID, tc.username)
+			assert.Equal(t, tc.errors, err, tc.username)
+		}
+	})
+}
@@ -1,11 +1,15 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 )
 
-func AccountArchiver(store data.AccountStore, tokenStore data.RefreshTokenStore, accountID int) error {
+func AccountArchiver(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int) error {
 	affected, err := store.Archive(accountID)
 	if err != nil {
 		return errors.Wrap(err, "Archive")
@@ -14,5 +18,5 @@ func AccountArchiver(store data.AccountStore, tokenStore data.RefreshTokenStore,
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	return SessionBatchEnder(tokenStore, accountID)
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, reporter, accountID)
 }
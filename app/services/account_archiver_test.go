@@ -1,8 +1,10 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +19,7 @@ func TestAccountArchiver(t *testing.T) {
 		account, err := accountStore.Create("test@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		errs := services.AccountArchiver(accountStore, refreshStore, account.ID)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errs)
 
 		acct, err := accountStore.Find(account.ID)
@@ -33,7 +35,7 @@ func TestAccountArchiver(t *testing.T) {
 		token1, err := refreshStore.Create(account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountArchiver(accountStore, refreshStore, account.ID)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errs)
 
 		id, err := refreshStore.Find(token1)
@@ -42,7 +44,7 @@ func TestAccountArchiver(t *testing.T) {
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errs := services.AccountArchiver(accountStore, refreshStore, 123456789)
+		errs := services.AccountArchiver(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, 123456789)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errs)
 	})
 }
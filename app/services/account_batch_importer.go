@@ -0,0 +1,70 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// ImportResult describes the outcome of one record in a batch import.
+type ImportResult struct {
+	Line     int         `json:"line"`
+	Username string      `json:"username"`
+	ID       int         `json:"id,omitempty"`
+	Errors   FieldErrors `json:"errors,omitempty"`
+}
+
+// AccountBatchImporter imports accounts from newline-delimited JSON records, as from a legacy
+// system. Each record has a username and a password, which may be a bcrypt hash, and may be
+// locked:
+//
+// {"username": "alice@example.com", "password": "$2a$10$...", "locked": false}
+//
+// Records are imported one at a time, so that a failure does not prevent the rest. Blank lines are
+// skipped.
+func AccountBatchImporter(store data.AccountStore, cfg *app.Config, r io.Reader) ([]ImportResult, error) {
+	results := []ImportResult{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var record struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+			Locked   bool   `json:"locked"`
+		}
+		result := ImportResult{Line: line}
+		err := json.Unmarshal(scanner.Bytes(), &record)
+		if err != nil {
+			result.Errors = FieldErrors{{"record", ErrFormatInvalid}}
+			results = append(results, result)
+			continue
+		}
+		result.Username = record.Username
+
+		account, err := AccountImporter(store, cfg, record.Username, record.Password, record.Locked)
+		if fe, ok := err.(FieldErrors); ok {
+			result.Errors = fe
+		} else if err != nil {
+			return results, errors.Wrap(err, "AccountImporter")
+		} else {
+			result.ID = account.ID
+		}
+		results = append(results, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return results, errors.Wrap(err, "Scan")
+	}
+
+	return results, nil
+}
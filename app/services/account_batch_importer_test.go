@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountBatchImporter(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{BcryptCost: 4}
+
+	_, err := accountStore.Create("existing", []byte("secret"))
+	require.NoError(t, err)
+
+	records := strings.Join([]string{
+		`{"username": "alice", "password": "` + string(bcrypted) + `"}`,
+		``,
+		`{"username": "bob", "password": "` + string(bcrypted) + `", "locked": true}`,
+		`{"username": "existing", "password": "` + string(bcrypted) + `"}`,
+		`{"username": "carol"}`,
+		`not json`,
+	}, "\n")
+
+	results, err := services.AccountBatchImporter(accountStore, cfg, strings.NewReader(records))
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+
+	assert.Equal(t, 1, results[0].Line)
+	assert.Equal(t, "alice", results[0].Username)
+	assert.NotEqual(t, 0, results[0].ID)
+	assert.Empty(t, results[0].Errors)
+
+	assert.Equal(t, 3, results[1].Line)
+	bob, err := accountStore.Find(results[1].ID)
+	require.NoError(t, err)
+	assert.True(t, bob.Locked)
+	assert.Equal(t, bcrypted, bob.Password)
+
+	assert.Equal(t, services.FieldErrors{{"username", "TAKEN"}}, results[2].Errors)
+	assert.Equal(t, services.FieldErrors{{"password", "MISSING"}}, results[3].Errors)
+	assert.Equal(t, 6, results[4].Line)
+	assert.Equal(t, services.FieldErrors{{"record", "FORMAT_INVALID"}}, results[4].Errors)
+}
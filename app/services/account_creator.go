@@ -12,6 +12,7 @@ import (
 
 func AccountCreator(store data.AccountStore, cfg *app.Config, username string, password string) (*models.Account, error) {
 	username = strings.TrimSpace(username)
+	password = normalizePassword(password)
 
 	errs := FieldErrors{}
 
@@ -20,7 +21,7 @@ func AccountCreator(store data.AccountStore, cfg *app.Config, username string, p
 		errs = append(errs, *fieldError)
 	}
 
-	fieldError = PasswordValidator(cfg, password)
+	fieldError = PasswordValidator(cfg, password, passwordUserInputs(username)...)
 	if fieldError != nil {
 		errs = append(errs, *fieldError)
 	}
@@ -29,6 +30,14 @@ func AccountCreator(store data.AccountStore, cfg *app.Config, username string, p
 		return nil, errs
 	}
 
+	available, err := usernameAvailable(store, 0, username)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, FieldErrors{{"username", ErrTaken}}
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
 	if err != nil {
 		return nil, errors.Wrap(err, "bcrypt")
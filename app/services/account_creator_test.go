@@ -35,7 +35,8 @@ var pw = []byte("$2a$04$ZOBA8E3nT68/ArE6NDnzfezGWEgM6YrE17PrOtSjT5.U/ZGoxyh7e")
 
 func TestAccountCreatorFailure(t *testing.T) {
 	store := mock.NewAccountStore()
-	store.Create("existing@test.com", pw)
+	existing, _ := store.Create("existing@test.com", pw)
+	store.AddAlias(existing.ID, "alias@test.com")
 
 	var testCases = []struct {
 		config   app.Config
@@ -47,6 +48,7 @@ func TestAccountCreatorFailure(t *testing.T) {
 		{app.Config{}, "", "PASSword", services.FieldErrors{{"username", "MISSING"}}},
 		{app.Config{}, "  ", "PASSword", services.FieldErrors{{"username", "MISSING"}}},
 		{app.Config{}, "existing@test.com", "PASSword", services.FieldErrors{{"username", "TAKEN"}}},
+		{app.Config{}, "alias@test.com", "PASSword", services.FieldErrors{{"username", "TAKEN"}}},
 		{app.Config{UsernameIsEmail: true}, "notanemail", "PASSword", services.FieldErrors{{"username", "FORMAT_INVALID"}}},
 		{app.Config{UsernameIsEmail: true}, "@wrong.com", "PASSword", services.FieldErrors{{"username", "FORMAT_INVALID"}}},
 		{app.Config{UsernameIsEmail: true}, "wrong@wrong", "PASSword", services.FieldErrors{{"username", "FORMAT_INVALID"}}},
@@ -56,6 +58,8 @@ func TestAccountCreatorFailure(t *testing.T) {
 		// password validations
 		{app.Config{}, "username", "", services.FieldErrors{{"password", "MISSING"}}},
 		{app.Config{PasswordMinComplexity: 2}, "username", "qwerty", services.FieldErrors{{"password", "INSECURE"}}},
+		{app.Config{}, "username", "0a0b0c\x000d0e0f", services.FieldErrors{{"password", "FORMAT_INVALID"}}},
+		{app.Config{PasswordMinComplexity: 3}, "myemail@keratin.tech", "myemail2024!", services.FieldErrors{{"password", "INSECURE"}}},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// AccountDeleter archives an account on behalf of its user, who must confirm their password. All
+// of its sessions are revoked, and the app is notified at APP_ACCOUNT_DELETED_URL so that it may
+// delete its own data.
+func AccountDeleter(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, r ops.ErrorReporter, accountID int, password string) error {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return FieldErrors{{"account", ErrLocked}}
+	}
+
+	if matched, _ := passwordMatches(account.Password, password); !matched {
+		return FieldErrors{{"credentials", ErrFailed}}
+	}
+
+	err = AccountArchiver(ctx, store, tokenStore, keyStore, cfg, r, accountID)
+	if err != nil {
+		return err
+	}
+
+	if cfg.AppAccountDeletedURL != nil {
+		go func() {
+			err := WebhookSender(ctx, cfg.AppAccountDeletedURL, &url.Values{
+				"account_id": []string{strconv.Itoa(accountID)},
+			}, timeSensitiveDelivery)
+			if err != nil {
+				r.ReportError(err)
+			}
+		}()
+	}
+
+	return nil
+}
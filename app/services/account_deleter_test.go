@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAccountDeleter(t *testing.T) {
+	deleted := make(chan string, 1)
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deleted <- r.FormValue("account_id")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+	deletedURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	accountStore := mock.NewAccountStore()
+	refreshStore := mock.NewRefreshTokenStore()
+	reporter := &ops.LogReporter{logrus.New()}
+	cfg := &app.Config{AppAccountDeletedURL: deletedURL}
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), 4)
+	require.NoError(t, err)
+
+	t.Run("with the password", func(t *testing.T) {
+		account, err := accountStore.Create("deleted@keratin.tech", hash)
+		require.NoError(t, err)
+		token, err := refreshStore.Create(account.ID)
+		require.NoError(t, err)
+
+		err = services.AccountDeleter(context.Background(), accountStore, refreshStore, nil, cfg, reporter, account.ID, "secret")
+		require.NoError(t, err)
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.Archived())
+		id, err := refreshStore.Find(token)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+
+		select {
+		case id := <-deleted:
+			assert.Equal(t, "1", id)
+		case <-time.After(time.Second):
+			assert.Fail(t, "webhook was not sent")
+		}
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		account, err := accountStore.Create("kept@keratin.tech", hash)
+		require.NoError(t, err)
+		locked, err := accountStore.Create("locked@keratin.tech", hash)
+		require.NoError(t, err)
+		accountStore.Lock(locked.ID)
+
+		testCases := []struct {
+			accountID int
+			password  string
+			errors    services.FieldErrors
+		}{
+			{account.ID, "", services.FieldErrors{{"credentials", services.ErrFailed}}},
+			{account.ID, "wrong", services.FieldErrors{{"credentials", services.ErrFailed}}},
+			{locked.ID, "secret", services.FieldErrors{{"account", services.ErrLocked}}},
+			{0, "secret", services.FieldErrors{{"account", services.ErrNotFound}}},
+		}
+
+		for _, tc := range testCases {
+			err := services.AccountDeleter(context.Background(), accountStore, refreshStore, nil, cfg, reporter, tc.accountID, tc.password)
+			assert.Equal(t, tc.errors, err)
+		}
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Archived())
+		assert.Empty(t, deleted)
+	})
+}
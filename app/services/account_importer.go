@@ -21,11 +21,22 @@ func AccountImporter(store data.AccountStore, cfg *app.Config, username string,
 		return nil, FieldErrors{{"password", ErrMissing}}
 	}
 
+	available, err := usernameAvailable(store, 0, username)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, FieldErrors{{"username", ErrTaken}}
+	}
+
 	var hash []byte
-	var err error
 	if bcryptPattern.Match([]byte(password)) {
 		hash = []byte(password)
 	} else {
+		password = normalizePassword(password)
+		if fieldError := passwordFormatValidator(cfg, password); fieldError != nil {
+			return nil, FieldErrors{*fieldError}
+		}
 		hash, err = bcrypt.GenerateFromPassword([]byte(password), cfg.BcryptCost)
 		if err != nil {
 			return nil, errors.Wrap(err, "bcrypt")
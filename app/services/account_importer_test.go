@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"strings"
 	"testing"
 
 	"golang.org/x/crypto/bcrypt"
@@ -36,6 +37,7 @@ func TestAccountImporter(t *testing.T) {
 		{"", bcrypted, false, &services.FieldErrors{{"username", services.ErrMissing}}},
 		{"invalid", []byte(""), false, &services.FieldErrors{{"password", services.ErrMissing}}},
 		{"existing", bcrypted, false, &services.FieldErrors{{"username", services.ErrTaken}}},
+		{"toolong", []byte(strings.Repeat("secret", 13)), false, &services.FieldErrors{{"password", services.ErrFormatInvalid}}},
 	}
 
 	for _, tc := range testCases {
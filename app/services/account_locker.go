@@ -1,11 +1,15 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 )
 
-func AccountLocker(store data.AccountStore, tokenStore data.RefreshTokenStore, accountID int) error {
+func AccountLocker(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int) error {
 	affected, err := store.Lock(accountID)
 	if err != nil {
 		return errors.Wrap(err, "Lock")
@@ -14,5 +18,5 @@ func AccountLocker(store data.AccountStore, tokenStore data.RefreshTokenStore, a
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	return SessionBatchEnder(tokenStore, accountID)
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, reporter, accountID)
 }
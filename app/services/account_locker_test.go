@@ -1,8 +1,10 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -19,7 +21,7 @@ func TestAccountLocker(t *testing.T) {
 		token1, err := refreshStore.Create(account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errs)
 
 		id, err := refreshStore.Find(token1)
@@ -33,7 +35,7 @@ func TestAccountLocker(t *testing.T) {
 		_, err = accountStore.Lock(account.ID)
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errs)
 
 		acct, err := accountStore.Find(account.ID)
@@ -45,7 +47,7 @@ func TestAccountLocker(t *testing.T) {
 		account, err := accountStore.Create("unlocked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 
-		errs := services.AccountLocker(accountStore, refreshStore, account.ID)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errs)
 
 		acct, err := accountStore.Find(account.ID)
@@ -54,7 +56,7 @@ func TestAccountLocker(t *testing.T) {
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errs := services.AccountLocker(accountStore, refreshStore, 123456789)
+		errs := services.AccountLocker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, 123456789)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errs)
 	})
 }
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"encoding/json"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// metadata is for small values like tenant IDs and flags, and may be copied into identity tokens
+const maxMetadataSize = 4096
+
+// AccountMetadataUpdater replaces the metadata of an account. The metadata must encode to no more
+// than 4KB of JSON.
+func AccountMetadataUpdater(store data.AccountStore, accountID int, metadata map[string]interface{}) error {
+	j, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+	if len(j) > maxMetadataSize {
+		return FieldErrors{{"metadata", ErrFormatInvalid}}
+	}
+
+	affected, err := store.SetMetadata(accountID, metadata)
+	if err != nil {
+		return errors.Wrap(err, "SetMetadata")
+	}
+	if !affected {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	return nil
+}
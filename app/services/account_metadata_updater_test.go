@@ -0,0 +1,37 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMetadataUpdater(t *testing.T) {
+	store := mock.NewAccountStore()
+	account, err := store.Create("existing@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+
+	t.Run("with valid metadata", func(t *testing.T) {
+		err := services.AccountMetadataUpdater(store, account.ID, map[string]interface{}{"tenant": "acme"})
+		require.NoError(t, err)
+
+		found, err := store.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Metadata{"tenant": "acme"}, found.Metadata)
+	})
+
+	t.Run("with oversized metadata", func(t *testing.T) {
+		err := services.AccountMetadataUpdater(store, account.ID, map[string]interface{}{"notes": strings.Repeat("a", 5000)})
+		assert.Equal(t, services.FieldErrors{{"metadata", services.ErrFormatInvalid}}, err)
+	})
+
+	t.Run("with an unknown account", func(t *testing.T) {
+		err := services.AccountMetadataUpdater(store, 0, map[string]interface{}{"tenant": "acme"})
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
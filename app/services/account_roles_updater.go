@@ -0,0 +1,29 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// AccountRolesUpdater replaces the roles of an account. Roles may not be blank or contain spaces,
+// so that they can be compared simply by consumers of identity tokens.
+func AccountRolesUpdater(store data.AccountStore, accountID int, roles []string) error {
+	for _, role := range roles {
+		if role == "" || strings.IndexFunc(role, unicode.IsSpace) >= 0 {
+			return FieldErrors{{"roles", ErrFormatInvalid}}
+		}
+	}
+
+	affected, err := store.SetRoles(accountID, roles)
+	if err != nil {
+		return errors.Wrap(err, "SetRoles")
+	}
+	if !affected {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	return nil
+}
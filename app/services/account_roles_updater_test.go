@@ -0,0 +1,38 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRolesUpdater(t *testing.T) {
+	store := mock.NewAccountStore()
+	account, err := store.Create("existing@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+
+	t.Run("with valid roles", func(t *testing.T) {
+		err := services.AccountRolesUpdater(store, account.ID, []string{"admin", "billing"})
+		require.NoError(t, err)
+
+		found, err := store.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Roles{"admin", "billing"}, found.Roles)
+	})
+
+	t.Run("with invalid roles", func(t *testing.T) {
+		for _, role := range []string{"", "super admin"} {
+			err := services.AccountRolesUpdater(store, account.ID, []string{"admin", role})
+			assert.Equal(t, services.FieldErrors{{"roles", services.ErrFormatInvalid}}, err)
+		}
+	})
+
+	t.Run("with an unknown account", func(t *testing.T) {
+		err := services.AccountRolesUpdater(store, 0, []string{"admin"})
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// AccountSessionsRevoker revokes every session of the account, as when its credentials have been
+// compromised.
+func AccountSessionsRevoker(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int) error {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, reporter, accountID)
+}
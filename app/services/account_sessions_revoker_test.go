@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountSessionsRevoker(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	refreshStore := mock.NewRefreshTokenStore()
+
+	t.Run("logged in account", func(t *testing.T) {
+		account, err := accountStore.Create("loggedin@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		token1, err := refreshStore.Create(account.ID)
+		require.NoError(t, err)
+		token2, err := refreshStore.Create(account.ID)
+		require.NoError(t, err)
+
+		err = services.AccountSessionsRevoker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
+		assert.NoError(t, err)
+
+		id, err := refreshStore.Find(token1)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+		id, err = refreshStore.Find(token2)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+
+		acct, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, acct.Locked)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		err := services.AccountSessionsRevoker(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, 9999)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
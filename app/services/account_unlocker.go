@@ -5,7 +5,7 @@ import (
 	"github.com/pkg/errors"
 )
 
-func AccountUnlocker(store data.AccountStore, accountID int) error {
+func AccountUnlocker(store data.AccountStore, failedLogins data.FailedLogins, accountID int) error {
 	affected, err := store.Unlock(accountID)
 	if err != nil {
 		return errors.Wrap(err, "Unlock")
@@ -14,5 +14,21 @@ func AccountUnlocker(store data.AccountStore, accountID int) error {
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
+	// forget the failed logins that may have locked the account
+	if failedLogins != nil {
+		account, err := store.Find(accountID)
+		if err != nil {
+			return errors.Wrap(err, "Find")
+		}
+		err = failedLogins.Reset(account.Username)
+		if err != nil {
+			return errors.Wrap(err, "Reset")
+		}
+		err = failedLogins.Unlock(accountID)
+		if err != nil {
+			return errors.Wrap(err, "Unlock")
+		}
+	}
+
 	return nil
 }
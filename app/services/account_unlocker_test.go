@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
@@ -30,7 +31,7 @@ func TestAccountUnlocker(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		errs := services.AccountUnlocker(store, tc.accountID)
+		errs := services.AccountUnlocker(store, nil, tc.accountID)
 		if tc.errors == nil {
 			assert.Empty(t, errs)
 			acct, err := store.Find(tc.accountID)
@@ -41,3 +42,26 @@ func TestAccountUnlocker(t *testing.T) {
 		}
 	}
 }
+
+func TestAccountUnlockerResetsFailedLogins(t *testing.T) {
+	store := mock.NewAccountStore()
+	failedLogins := mock.NewFailedLogins(time.Minute)
+
+	account, err := store.Create("locked@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	_, err = store.Lock(account.ID)
+	require.NoError(t, err)
+	failedLogins.Add("locked@keratin.tech", "127.0.0.1")
+	failedLogins.Lock(account.ID, time.Minute)
+
+	err = services.AccountUnlocker(store, failedLogins, account.ID)
+	require.NoError(t, err)
+
+	failures, _, err := failedLogins.Add("locked@keratin.tech", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	locked, err := failedLogins.LockedFor(account.ID)
+	require.NoError(t, err)
+	assert.Zero(t, locked)
+}
@@ -16,6 +16,14 @@ func AccountUpdater(store data.AccountStore, cfg *app.Config, accountID int, use
 		return FieldErrors{*fieldError}
 	}
 
+	available, err := usernameAvailable(store, accountID, username)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return FieldErrors{{"username", ErrTaken}}
+	}
+
 	affected, err := store.UpdateUsername(accountID, username)
 	if err != nil {
 		if data.IsUniquenessError(err) {
@@ -28,5 +36,11 @@ func AccountUpdater(store data.AccountStore, cfg *app.Config, accountID int, use
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
+	// an alias that becomes the username is no longer needed
+	_, err = store.DeleteAlias(accountID, username)
+	if err != nil {
+		return errors.Wrap(err, "DeleteAlias")
+	}
+
 	return nil
 }
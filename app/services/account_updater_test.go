@@ -48,6 +48,27 @@ func TestAccountUpdater(t *testing.T) {
 
 		err = services.AccountUpdater(accountStore, cfg, existing.ID, other.Username)
 		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+
+		err = accountStore.AddAlias(other.ID, "alias")
+		require.NoError(t, err)
+		err = services.AccountUpdater(accountStore, cfg, existing.ID, "alias")
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+	})
+
+	t.Run("username is an alias of the account", func(t *testing.T) {
+		cfg := &app.Config{
+			UsernameIsEmail:   false,
+			UsernameMinLength: 3,
+		}
+
+		err := accountStore.AddAlias(existing.ID, "mine")
+		require.NoError(t, err)
+		err = services.AccountUpdater(accountStore, cfg, existing.ID, "mine")
+		require.NoError(t, err)
+
+		aliases, err := accountStore.GetAliases(existing.ID)
+		require.NoError(t, err)
+		assert.Len(t, aliases, 0)
 	})
 
 	t.Run("string usernames", func(t *testing.T) {
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// Actions that accounts take, as recorded in the audit log. Admin actions are named where the
+// private routes are declared.
+const (
+	AuditLogin                  = "login"
+	AuditLoginFailed            = "login.failed"
+	AuditLogout                 = "logout"
+	AuditSignup                 = "signup"
+	AuditSessionRevoked         = "session.revoked"
+	AuditPasswordResetRequested = "password.reset_requested"
+	AuditPasswordReset          = "password.reset"
+	AuditPasswordChanged        = "password.changed"
+	AuditAccountDeleted         = "account.deleted"
+)
+
+// auditFieldLength fits client-provided fields into every database's columns
+const auditFieldLength = 255
+
+// AuditRecorder adds an entry to the audit log. The action has already happened, so failures
+// are reported rather than returned.
+func AuditRecorder(log data.AuditLog, r ops.ErrorReporter, entry *models.AuditEntry) {
+	if log == nil {
+		return
+	}
+	entry.Username = truncate(entry.Username, auditFieldLength)
+	entry.UserAgent = truncate(entry.UserAgent, auditFieldLength)
+
+	err := log.Record(entry)
+	if err != nil {
+		r.ReportError(errors.Wrap(err, "Record"))
+	}
+}
+
+// truncate shortens a string to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
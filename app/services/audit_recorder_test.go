@@ -0,0 +1,34 @@
+package services_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRecorder(t *testing.T) {
+	log := mock.NewAuditLog()
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
+
+	services.AuditRecorder(log, reporter, &models.AuditEntry{
+		Action:    services.AuditLoginFailed,
+		Username:  strings.Repeat("é", 200),
+		Actor:     models.ActorAnonymous,
+		IP:        "127.0.0.1",
+		UserAgent: strings.Repeat("a", 300),
+	})
+
+	entries, err := log.List(models.AuditFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, services.AuditLoginFailed, entries[0].Action)
+	assert.Equal(t, strings.Repeat("é", 127), entries[0].Username)
+	assert.Len(t, entries[0].UserAgent, 255)
+}
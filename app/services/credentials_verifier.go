@@ -1,6 +1,8 @@
 package services
 
 import (
+	"strings"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
@@ -34,8 +36,8 @@ func CredentialsVerifier(store data.AccountStore, cfg *app.Config, username stri
 		passwordHash = []byte(account.Password)
 	}
 
-	err = bcrypt.CompareHashAndPassword(passwordHash, []byte(password))
-	if account == nil || err != nil {
+	matched, legacy := passwordMatches(passwordHash, password)
+	if !matched || account == nil {
 		return nil, FieldErrors{{"credentials", ErrFailed}}
 	}
 	if account.Locked {
@@ -45,5 +47,39 @@ func CredentialsVerifier(store data.AccountStore, cfg *app.Config, username stri
 		return nil, FieldErrors{{"credentials", ErrExpired}}
 	}
 
+	// upgrade the hash while the password is known, if BCRYPT_COST was raised or the password was
+	// set before normalization
+	cost, err := bcrypt.Cost(account.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cost")
+	}
+	if legacy || cost < cfg.BcryptCost {
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizePassword(password)), cfg.BcryptCost)
+		if err != nil {
+			return nil, errors.Wrap(err, "GenerateFromPassword")
+		}
+		_, err = store.RehashPassword(account.ID, hash)
+		if err != nil {
+			return nil, errors.Wrap(err, "RehashPassword")
+		}
+	}
+
 	return account, nil
 }
+
+// passwordMatches compares a password with a hash after normalization. Passwords that were set
+// before normalization are also compared as given, so that their accounts may still log in, and
+// are reported as legacy.
+func passwordMatches(hash []byte, password string) (matched bool, legacy bool) {
+	if strings.ContainsRune(password, 0) {
+		return false, false
+	}
+	normalized := normalizePassword(password)
+	if bcrypt.CompareHashAndPassword(hash, []byte(normalized)) == nil {
+		return true, false
+	}
+	if normalized != password && bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil {
+		return true, true
+	}
+	return false, false
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestCredentialsVerifierSuccess(t *testing.T) {
@@ -25,6 +26,80 @@ func TestCredentialsVerifierSuccess(t *testing.T) {
 	assert.Equal(t, username, acc.Username)
 }
 
+func TestCredentialsVerifierAlias(t *testing.T) {
+	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
+
+	cfg := app.Config{BcryptCost: 4}
+	store := mock.NewAccountStore()
+	account, err := store.Create("myname", bcrypted)
+	require.NoError(t, err)
+	err = store.AddAlias(account.ID, "myalias")
+	require.NoError(t, err)
+
+	acc, err := services.CredentialsVerifier(store, &cfg, "myalias", "mysecret")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, acc.ID)
+	assert.Equal(t, "myname", acc.Username)
+}
+
+func TestCredentialsVerifierCostUpgrade(t *testing.T) {
+	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
+
+	store := mock.NewAccountStore()
+	account, err := store.Create("myname", bcrypted)
+	require.NoError(t, err)
+
+	cfg := app.Config{BcryptCost: 5}
+	_, err = services.CredentialsVerifier(store, &cfg, "myname", "mysecret")
+	require.NoError(t, err)
+
+	after, err := store.Find(account.ID)
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost(after.Password)
+	require.NoError(t, err)
+	assert.Equal(t, 5, cost)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(after.Password, []byte("mysecret")))
+	assert.Equal(t, account.PasswordChangedAt, after.PasswordChangedAt)
+
+	// a lower cost is not a downgrade
+	cfg.BcryptCost = 4
+	_, err = services.CredentialsVerifier(store, &cfg, "myname", "mysecret")
+	require.NoError(t, err)
+	unchanged, err := store.Find(account.ID)
+	require.NoError(t, err)
+	assert.Equal(t, after.Password, unchanged.Password)
+}
+
+func TestCredentialsVerifierNormalization(t *testing.T) {
+	composed := "caf\u00e9-secret"
+	decomposed := "cafe\u0301-secret"
+
+	cfg := app.Config{BcryptCost: 4}
+	store := mock.NewAccountStore()
+	normalized, err := bcrypt.GenerateFromPassword([]byte(composed), cfg.BcryptCost)
+	require.NoError(t, err)
+	store.Create("normalized", normalized)
+	legacy, err := bcrypt.GenerateFromPassword([]byte(decomposed), cfg.BcryptCost)
+	require.NoError(t, err)
+	store.Create("legacy", legacy)
+
+	// either form matches a normalized password
+	_, err = services.CredentialsVerifier(store, &cfg, "normalized", decomposed)
+	assert.NoError(t, err)
+	_, err = services.CredentialsVerifier(store, &cfg, "normalized", composed)
+	assert.NoError(t, err)
+
+	// a password set before normalization still matches as given, and is rehashed normalized
+	acc, err := services.CredentialsVerifier(store, &cfg, "legacy", decomposed)
+	assert.NoError(t, err)
+	after, err := store.Find(acc.ID)
+	require.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(after.Password, []byte(composed)))
+
+	_, err = services.CredentialsVerifier(store, &cfg, "normalized", composed+"\x00")
+	assert.Equal(t, services.FieldErrors{{"credentials", "FAILED"}}, err)
+}
+
 func TestCredentialsVerifierFailure(t *testing.T) {
 	password := "mysecret"
 	bcrypted := []byte("$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C")
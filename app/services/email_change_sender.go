@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// EmailChangeSender sends a confirmation token for a new email to APP_EMAIL_CHANGE_URL. The
+// account keeps its current username until the token is confirmed with EmailChanger. The user must
+// confirm their current password, so that a stolen session can not take over the account.
+func EmailChangeSender(ctx context.Context, store data.AccountStore, cfg *app.Config, accountID int, email string, password string, logger logrus.FieldLogger) error {
+	email = strings.TrimSpace(email)
+
+	fieldError := UsernameValidator(cfg, email)
+	if fieldError != nil {
+		return FieldErrors{*fieldError}
+	}
+
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return FieldErrors{{"account", ErrLocked}}
+	}
+
+	if matched, _ := passwordMatches(account.Password, password); !matched {
+		return FieldErrors{{"credentials", ErrFailed}}
+	}
+
+	existing, err := store.FindByUsername(email)
+	if err != nil {
+		return errors.Wrap(err, "FindByUsername")
+	}
+	if existing != nil {
+		return FieldErrors{{"username", ErrTaken}}
+	}
+
+	change, err := verifications.NewChange(cfg, account.ID, account.Username, email)
+	if err != nil {
+		return errors.Wrap(err, "NewChange")
+	}
+	changeStr, err := change.Sign(cfg.EmailVerificationSigningKey)
+	if err != nil {
+		return errors.Wrap(err, "Sign")
+	}
+
+	err = WebhookSender(ctx, cfg.AppEmailChangeURL, &url.Values{
+		"account_id": []string{strconv.Itoa(account.ID)},
+		"email":      []string{email},
+		"token":      []string{changeStr},
+	}, timeSensitiveDelivery)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	logger.WithField("accountID", account.ID).Info("sent email change token")
+
+	return nil
+}
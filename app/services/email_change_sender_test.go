@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestEmailChangeSender(t *testing.T) {
+	var sent url.Values
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sent = r.PostForm
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{
+		AuthNURL:                    &url.URL{Scheme: "https", Host: "authn.example.com"},
+		AppEmailChangeURL:           &url.URL{Scheme: "http", Host: serverURL.Host, Path: "/email"},
+		EmailVerificationSigningKey: []byte("verifications"),
+		EmailVerificationTokenTTL:   time.Minute,
+		UsernameIsEmail:             true,
+		JWTLeeway:                   time.Minute,
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), 4)
+	require.NoError(t, err)
+	account, err := accountStore.Create("old@keratin.tech", hash)
+	require.NoError(t, err)
+
+	t.Run("posting to remote app", func(t *testing.T) {
+		err = services.EmailChangeSender(context.Background(), accountStore, cfg, account.ID, " new@keratin.tech ", "secret", logrus.New())
+		require.NoError(t, err)
+		assert.Equal(t, "new@keratin.tech", sent.Get("email"))
+
+		claims, err := verifications.ParseChange(sent.Get("token"), cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "old@keratin.tech", claims.Username)
+		assert.Equal(t, "new@keratin.tech", claims.Email)
+
+		// the old address remains active until confirmed
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "old@keratin.tech", found.Username)
+	})
+
+	t.Run("with an invalid email", func(t *testing.T) {
+		err := services.EmailChangeSender(context.Background(), accountStore, cfg, account.ID, "invalid", "secret", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrFormatInvalid}}, err)
+	})
+
+	t.Run("with a taken email", func(t *testing.T) {
+		other, err := accountStore.Create("taken@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+
+		err = services.EmailChangeSender(context.Background(), accountStore, cfg, account.ID, other.Username, "secret", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+	})
+
+	t.Run("with the wrong password", func(t *testing.T) {
+		sent = nil
+		err := services.EmailChangeSender(context.Background(), accountStore, cfg, account.ID, "wrong@keratin.tech", "wrong", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"credentials", services.ErrFailed}}, err)
+		assert.Nil(t, sent)
+	})
+
+	t.Run("with an unknown account", func(t *testing.T) {
+		err := services.EmailChangeSender(context.Background(), accountStore, cfg, 9999, "unknown@keratin.tech", "secret", logrus.New())
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/pkg/errors"
+)
+
+// EmailChanger replaces the username with the email confirmed by a token from EmailChangeSender.
+// Tokens expire when the username changes, so only one change may be confirmed. The new email is
+// also verified.
+func EmailChanger(store data.AccountStore, cfg *app.Config, token string) (int, error) {
+	claims, err := verifications.ParseChange(token, cfg)
+	if err != nil {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, errors.Wrap(err, "Atoi")
+	}
+
+	account, err := store.Find(id)
+	if err != nil {
+		return 0, errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return 0, FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return 0, FieldErrors{{"account", ErrLocked}}
+	} else if account.Username != claims.Username {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	available, err := usernameAvailable(store, account.ID, claims.Email)
+	if err != nil {
+		return 0, err
+	}
+	if !available {
+		return 0, FieldErrors{{"username", ErrTaken}}
+	}
+
+	_, err = store.UpdateVerifiedUsername(account.ID, claims.Email)
+	if err != nil {
+		if data.IsUniquenessError(err) {
+			return 0, FieldErrors{{"username", ErrTaken}}
+		}
+
+		return 0, errors.Wrap(err, "UpdateVerifiedUsername")
+	}
+
+	return account.ID, nil
+}
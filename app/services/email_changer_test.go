@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailChanger(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{
+		AuthNURL:                    &url.URL{Scheme: "http", Host: "authn.example.com"},
+		EmailVerificationSigningKey: []byte("verify-a-reno"),
+		EmailVerificationTokenTTL:   time.Hour,
+		JWTLeeway:                   time.Minute,
+	}
+
+	newToken := func(id int, username string, email string) string {
+		claims, err := verifications.NewChange(cfg, id, username, email)
+		require.NoError(t, err)
+		token, err := claims.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("when token is invalid", func(t *testing.T) {
+		_, err := services.EmailChanger(accountStore, cfg, "not.valid.jwt")
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("with a verification token", func(t *testing.T) {
+		account, err := accountStore.Create("verifying@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		claims, err := verifications.New(cfg, account.ID, account.Username)
+		require.NoError(t, err)
+		token, err := claims.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+
+		_, err = services.EmailChanger(accountStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("on a valid token", func(t *testing.T) {
+		account, err := accountStore.Create("old@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		token := newToken(account.ID, account.Username, "new@keratin.tech")
+
+		id, err := services.EmailChanger(accountStore, cfg, token)
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, id)
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new@keratin.tech", found.Username)
+		assert.True(t, found.Verified())
+
+		// a token may only be used once
+		_, err = services.EmailChanger(accountStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("when the email was taken after sending", func(t *testing.T) {
+		account, err := accountStore.Create("slow@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		token := newToken(account.ID, account.Username, "fast@keratin.tech")
+		_, err = accountStore.Create("fast@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+
+		_, err = services.EmailChanger(accountStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+	})
+
+	t.Run("when the email is an alias of another account", func(t *testing.T) {
+		account, err := accountStore.Create("first@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		other, err := accountStore.Create("second@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		err = accountStore.AddAlias(other.ID, "shared@keratin.tech")
+		require.NoError(t, err)
+		token := newToken(account.ID, account.Username, "shared@keratin.tech")
+
+		_, err = services.EmailChanger(accountStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"username", services.ErrTaken}}, err)
+	})
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// EmailVerificationSender sends a verification token for the account's username to
+// APP_EMAIL_VERIFICATION_URL. Accounts that are locked or already verified are skipped.
+func EmailVerificationSender(ctx context.Context, cfg *app.Config, account *models.Account, logger logrus.FieldLogger) error {
+	if account == nil || account.Locked || account.Verified() {
+		return nil
+	}
+
+	verification, err := verifications.New(cfg, account.ID, account.Username)
+	if err != nil {
+		return errors.Wrap(err, "New Verification")
+	}
+	verificationStr, err := verification.Sign(cfg.EmailVerificationSigningKey)
+	if err != nil {
+		return errors.Wrap(err, "Sign")
+	}
+
+	err = WebhookSender(ctx, cfg.AppEmailVerificationURL, &url.Values{
+		"account_id": []string{strconv.Itoa(account.ID)},
+		"token":      []string{verificationStr},
+	}, timeSensitiveDelivery)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	logger.WithField("accountID", account.ID).Info("sent email verification token")
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationSender(t *testing.T) {
+	requests := 0
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/verify" && r.FormValue("token") != "" {
+			w.WriteHeader(http.StatusOK)
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	invoke := func(account *models.Account) error {
+		cfg := &app.Config{
+			AuthNURL:                    &url.URL{Scheme: "https", Host: "authn.example.com"},
+			AppEmailVerificationURL:     &url.URL{Scheme: "http", Host: serverURL.Host, Path: "/verify"},
+			EmailVerificationSigningKey: []byte("verifications"),
+			EmailVerificationTokenTTL:   time.Minute,
+		}
+		return services.EmailVerificationSender(context.Background(), cfg, account, logrus.New())
+	}
+
+	t.Run("posting to remote app", func(t *testing.T) {
+		requests = 0
+		err := invoke(&models.Account{ID: 1234, Username: "user@keratin.tech"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("with verified account", func(t *testing.T) {
+		requests = 0
+		now := time.Now()
+		err := invoke(&models.Account{ID: 1234, Username: "user@keratin.tech", EmailVerifiedAt: &now})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("with locked account", func(t *testing.T) {
+		requests = 0
+		err := invoke(&models.Account{ID: 1234, Username: "user@keratin.tech", Locked: true})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("with no account", func(t *testing.T) {
+		err := invoke(nil)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/pkg/errors"
+)
+
+// EmailVerifier marks an account as verified with a token from EmailVerificationSender. Tokens
+// expire with the username they were issued for.
+func EmailVerifier(store data.AccountStore, cfg *app.Config, token string) (int, error) {
+	claims, err := verifications.Parse(token, cfg)
+	if err != nil {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, errors.Wrap(err, "Atoi")
+	}
+
+	account, err := store.Find(id)
+	if err != nil {
+		return 0, errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return 0, FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked || account.Archived() {
+		return 0, FieldErrors{{"account", ErrLocked}}
+	} else if account.Username != claims.Email {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	_, err = store.SetVerified(account.ID)
+	if err != nil {
+		return 0, errors.Wrap(err, "SetVerified")
+	}
+
+	return account.ID, nil
+}
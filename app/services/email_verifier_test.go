@@ -0,0 +1,71 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerifier(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{
+		AuthNURL:                    &url.URL{Scheme: "http", Host: "authn.example.com"},
+		EmailVerificationSigningKey: []byte("verify-a-reno"),
+		EmailVerificationTokenTTL:   time.Hour,
+		JWTLeeway:                   time.Minute,
+	}
+
+	newToken := func(id int, email string) string {
+		claims, err := verifications.New(cfg, id, email)
+		require.NoError(t, err)
+		token, err := claims.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("when token is invalid", func(t *testing.T) {
+		_, err := services.EmailVerifier(accountStore, cfg, "not.valid.jwt")
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("on a locked account", func(t *testing.T) {
+		locked, err := accountStore.Create("locked@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		_, err = accountStore.Lock(locked.ID)
+		require.NoError(t, err)
+
+		_, err = services.EmailVerifier(accountStore, cfg, newToken(locked.ID, locked.Username))
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+
+	t.Run("after the username changed", func(t *testing.T) {
+		account, err := accountStore.Create("before@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		token := newToken(account.ID, account.Username)
+		_, err = accountStore.UpdateUsername(account.ID, "after@keratin.tech")
+		require.NoError(t, err)
+
+		_, err = services.EmailVerifier(accountStore, cfg, token)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("on a valid token", func(t *testing.T) {
+		account, err := accountStore.Create("valid@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+
+		id, err := services.EmailVerifier(accountStore, cfg, newToken(account.ID, account.Username))
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, id)
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.Verified())
+	})
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// EventSignatureHeader carries t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">, signed
+// with the APP_EVENTS_SIGNING_KEY.
+const EventSignatureHeader = "X-AuthN-Signature"
+
+// eventDeliveryTimeout limits how long an endpoint may take to respond. The lease on a claimed
+// delivery must be longer, so that it is not claimed again while still in flight.
+const (
+	eventDeliveryTimeout = 10 * time.Second
+	eventDeliveryLease   = time.Minute
+)
+
+// eventRetrySchedule spaces out the retries of a failing delivery over about 16 hours, after which
+// it is dropped.
+var eventRetrySchedule = []time.Duration{
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+	5 * time.Minute,
+	15 * time.Minute,
+	30 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	4 * time.Hour,
+	8 * time.Hour,
+}
+
+// EventDeliverer claims a due delivery, if any, and sends it. A failed delivery is scheduled to be
+// retried. It returns false when nothing was due, and an error when the queue fails or a delivery
+// is dropped.
+func EventDeliverer(queue data.EventQueue, cfg *app.Config) (bool, error) {
+	delivery, err := queue.Claim(eventDeliveryLease)
+	if err != nil {
+		return false, errors.Wrap(err, "Claim")
+	}
+	if delivery == nil {
+		return false, nil
+	}
+
+	// the endpoint is no longer configured
+	if !isEventsURL(cfg, delivery.URL) {
+		return true, errors.Wrap(queue.Ack(delivery.ID), "Ack")
+	}
+
+	sendErr := sendEvent(cfg, delivery)
+	if sendErr == nil {
+		return true, errors.Wrap(queue.Ack(delivery.ID), "Ack")
+	}
+
+	if delivery.Attempts < len(eventRetrySchedule) {
+		delay := eventRetrySchedule[delivery.Attempts]
+		delivery.Attempts++
+		return true, errors.Wrap(queue.Schedule(delivery, time.Now().Add(delay)), "Schedule")
+	}
+
+	if err := queue.Ack(delivery.ID); err != nil {
+		return true, errors.Wrap(err, "Ack")
+	}
+	return true, errors.Wrapf(sendErr, "dropped %s event %s", delivery.Event.Type, delivery.Event.ID)
+}
+
+// DeliverEvents starts a goroutine that sends deliveries as they become due. Every AuthN server
+// may run one, since a delivery can only be claimed by one server at a time.
+func DeliverEvents(queue data.EventQueue, cfg *app.Config, r ops.ErrorReporter) {
+	go func() {
+		for range time.Tick(time.Second) {
+			for {
+				ok, err := EventDeliverer(queue, cfg)
+				if err != nil {
+					r.ReportError(err)
+				}
+				if !ok {
+					break
+				}
+			}
+		}
+	}()
+}
+
+// SignEvent computes the EventSignatureHeader for a body sent at the given time.
+func SignEvent(key []byte, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%s,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+func sendEvent(cfg *app.Config, delivery *models.EventDelivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return errors.Wrap(err, "Marshal")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), eventDeliveryTimeout)
+	defer cancel()
+	req, err := http.NewRequest("POST", delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return stripURL(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-AuthN-Event", delivery.Event.Type)
+	req.Header.Set(EventSignatureHeader, SignEvent(cfg.AppEventsSigningKey, time.Now(), body))
+
+	res, err := webhookClient.Do(req)
+	if err != nil {
+		return stripURL(err)
+	}
+	res.Body.Close()
+	if res.StatusCode > 299 {
+		return fmt.Errorf("Status Code: %v", res.StatusCode)
+	}
+	return nil
+}
+
+// stripURL avoids reporting the URL with potential HTTP auth credentials
+func stripURL(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return errors.Wrap(urlErr.Err, "Post")
+	}
+	return errors.Wrap(err, "Post")
+}
+
+func isEventsURL(cfg *app.Config, str string) bool {
+	for _, u := range cfg.AppEventsURLs {
+		if u.String() == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventDeliverer(t *testing.T) {
+	status := http.StatusOK
+	var received *http.Request
+	var body []byte
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer remoteApp.Close()
+	eventsURL, err := url.Parse(remoteApp.URL + "/events")
+	require.NoError(t, err)
+
+	cfg := &app.Config{
+		AppEventsURLs:       []*url.URL{eventsURL},
+		AppEventsSigningKey: []byte("shh"),
+	}
+	event := models.Event{
+		ID:         "abc",
+		Type:       services.EventAccountCreated,
+		AccountID:  123,
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	t.Run("nothing due", func(t *testing.T) {
+		ok, err := services.EventDeliverer(mock.NewEventQueue(), cfg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		status = http.StatusOK
+		queue := mock.NewEventQueue()
+		require.NoError(t, queue.Schedule(&models.EventDelivery{ID: "1", URL: eventsURL.String(), Event: event}, time.Now()))
+
+		ok, err := services.EventDeliverer(queue, cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		require.NotNil(t, received)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, services.EventAccountCreated, received.Header.Get("X-AuthN-Event"))
+		signature := received.Header.Get(services.EventSignatureHeader)
+		var timestamp int64
+		_, err = fmt.Sscanf(signature, "t=%d,", &timestamp)
+		require.NoError(t, err)
+		assert.InDelta(t, time.Now().Unix(), timestamp, 5)
+		assert.Equal(t, services.SignEvent([]byte("shh"), time.Unix(timestamp, 0), body), signature)
+
+		sent := models.Event{}
+		require.NoError(t, json.Unmarshal(body, &sent))
+		assert.Equal(t, event, sent)
+
+		ok, err = services.EventDeliverer(queue, cfg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		queue := mock.NewEventQueue()
+		require.NoError(t, queue.Schedule(&models.EventDelivery{ID: "1", URL: eventsURL.String(), Event: event}, time.Now()))
+
+		ok, err := services.EventDeliverer(queue, cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// rescheduled for later
+		ok, err = services.EventDeliverer(queue, cfg)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("after the last retry", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		queue := mock.NewEventQueue()
+		require.NoError(t, queue.Schedule(&models.EventDelivery{ID: "1", URL: eventsURL.String(), Attempts: 10, Event: event}, time.Now()))
+
+		ok, err := services.EventDeliverer(queue, cfg)
+		assert.True(t, ok)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "dropped account.created event abc")
+		}
+
+		delivery, err := queue.Claim(0)
+		require.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+
+	t.Run("unconfigured URL", func(t *testing.T) {
+		received = nil
+		queue := mock.NewEventQueue()
+		require.NoError(t, queue.Schedule(&models.EventDelivery{ID: "1", URL: "https://old.example.com/events", Event: event}, time.Now()))
+
+		ok, err := services.EventDeliverer(queue, cfg)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Nil(t, received)
+	})
+}
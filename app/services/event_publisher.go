@@ -0,0 +1,68 @@
+package services
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// Events delivered to the APP_EVENTS_URLS
+const (
+	EventAccountCreated  = "account.created"
+	EventAccountLocked   = "account.locked"
+	EventAccountUnlocked = "account.unlocked"
+	EventAccountArchived = "account.archived"
+	EventSessionCreated  = "session.created"
+	EventPasswordChanged = "password.changed"
+)
+
+// EventPublisher queues an event for every APP_EVENTS_URLS endpoint. The change has already
+// happened, so failures are reported rather than returned.
+func EventPublisher(queue data.EventQueue, cfg *app.Config, r ops.ErrorReporter, eventType string, accountID int) {
+	if len(cfg.AppEventsURLs) == 0 || queue == nil {
+		return
+	}
+
+	id, err := randomID()
+	if err != nil {
+		r.ReportError(err)
+		return
+	}
+	event := models.Event{
+		ID:         id,
+		Type:       eventType,
+		AccountID:  accountID,
+		OccurredAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	now := time.Now()
+	for _, destination := range cfg.AppEventsURLs {
+		id, err := randomID()
+		if err != nil {
+			r.ReportError(err)
+			return
+		}
+		err = queue.Schedule(&models.EventDelivery{
+			ID:    id,
+			URL:   destination.String(),
+			Event: event,
+		}, now)
+		if err != nil {
+			r.ReportError(errors.Wrap(err, "Schedule"))
+		}
+	}
+}
+
+func randomID() (string, error) {
+	token, err := lib.GenerateToken()
+	if err != nil {
+		return "", errors.Wrap(err, "GenerateToken")
+	}
+	return hex.EncodeToString(token), nil
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventPublisher(t *testing.T) {
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
+
+	t.Run("without APP_EVENTS_URLS", func(t *testing.T) {
+		queue := mock.NewEventQueue()
+		services.EventPublisher(queue, &app.Config{}, reporter, services.EventAccountCreated, 123)
+
+		delivery, err := queue.Claim(time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, delivery)
+	})
+
+	t.Run("with APP_EVENTS_URLS", func(t *testing.T) {
+		queue := mock.NewEventQueue()
+		cfg := &app.Config{
+			AppEventsURLs: []*url.URL{
+				{Scheme: "https", Host: "a.example.com", Path: "/events"},
+				{Scheme: "https", Host: "b.example.com", Path: "/events"},
+			},
+		}
+		services.EventPublisher(queue, cfg, reporter, services.EventAccountLocked, 123)
+
+		first, err := queue.Claim(time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, first)
+		second, err := queue.Claim(time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, second)
+
+		assert.ElementsMatch(t, []string{"https://a.example.com/events", "https://b.example.com/events"}, []string{first.URL, second.URL})
+		assert.NotEqual(t, first.ID, second.ID)
+		assert.Equal(t, first.Event, second.Event)
+		assert.Equal(t, services.EventAccountLocked, first.Event.Type)
+		assert.Equal(t, 123, first.Event.AccountID)
+		assert.NotEmpty(t, first.Event.ID)
+	})
+}
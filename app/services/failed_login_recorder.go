@@ -0,0 +1,38 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// FailedLoginRecorder counts a failed login for the username and client IP. An account that
+// reaches LOGIN_LOCKOUT_THRESHOLD failures is locked until LOGIN_FAILURE_WINDOW passes, unless
+// LOGIN_LOCKOUT_DELAY delays its logins instead.
+//
+// The account's sessions are not ended, since they were not created by the failed logins.
+func FailedLoginRecorder(failedLogins data.FailedLogins, accountStore data.AccountStore, cfg *app.Config, username string, ip string) error {
+	if failedLogins == nil || (cfg.LoginLockoutThreshold <= 0 && cfg.LoginIPThreshold <= 0) {
+		return nil
+	}
+
+	failures, _, err := failedLogins.Add(username, ip)
+	if err != nil {
+		return errors.Wrap(err, "Add")
+	}
+	if cfg.LoginLockoutThreshold <= 0 || cfg.LoginLockoutDelay || failures < cfg.LoginLockoutThreshold {
+		return nil
+	}
+
+	account, err := accountStore.FindByUsername(username)
+	if err != nil {
+		return errors.Wrap(err, "FindByUsername")
+	}
+	if account != nil {
+		err = failedLogins.Lock(account.ID, cfg.LoginFailureWindow)
+		if err != nil {
+			return errors.Wrap(err, "Lock")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailedLoginRecorder(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	account, err := accountStore.Create("alice", []byte("password"))
+	require.NoError(t, err)
+
+	t.Run("without lockout", func(t *testing.T) {
+		failedLogins := mock.NewFailedLogins(time.Minute)
+		for i := 0; i < 5; i++ {
+			err := services.FailedLoginRecorder(failedLogins, accountStore, &app.Config{}, "alice", "127.0.0.1")
+			require.NoError(t, err)
+		}
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Locked)
+	})
+
+	t.Run("with lockout delay", func(t *testing.T) {
+		failedLogins := mock.NewFailedLogins(time.Minute)
+		cfg := &app.Config{LoginLockoutThreshold: 1, LoginLockoutDelay: true}
+		err := services.FailedLoginRecorder(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+		require.NoError(t, err)
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Locked)
+	})
+
+	t.Run("with lockout", func(t *testing.T) {
+		failedLogins := mock.NewFailedLogins(time.Minute)
+		cfg := &app.Config{LoginLockoutThreshold: 3, LoginFailureWindow: time.Minute}
+		for i := 0; i < 2; i++ {
+			err := services.FailedLoginRecorder(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+			require.NoError(t, err)
+		}
+		locked, err := failedLogins.LockedFor(account.ID)
+		require.NoError(t, err)
+		assert.Zero(t, locked)
+
+		err = services.FailedLoginRecorder(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+		require.NoError(t, err)
+		locked, err = failedLogins.LockedFor(account.ID)
+		require.NoError(t, err)
+		assert.InDelta(t, time.Minute, locked, float64(time.Second))
+
+		// the lock expires instead of locking the account until it is unlocked
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Locked)
+	})
+
+	t.Run("unknown username", func(t *testing.T) {
+		failedLogins := mock.NewFailedLogins(time.Minute)
+		cfg := &app.Config{LoginLockoutThreshold: 1}
+		err := services.FailedLoginRecorder(failedLogins, accountStore, cfg, "unknown", "127.0.0.1")
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pkg/errors"
+)
+
+// HandoffTokenCreator mints a token that the application at origin may redeem for its own session.
+// The origin must match one of the APP_DOMAINS.
+func HandoffTokenCreator(cfg *app.Config, accountID int, origin string, ttl time.Duration) (string, error) {
+	domain := route.FindDomain(origin, cfg.ApplicationDomains)
+	if domain == nil {
+		return "", FieldErrors{{"origin", ErrNotFound}}
+	}
+
+	handoff, err := handoffs.New(cfg, accountID, domain.String(), ttl)
+	if err != nil {
+		return "", errors.Wrap(err, "New Handoff Token")
+	}
+	handoffStr, err := handoff.Sign(cfg.HandoffTokenSigningKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Sign")
+	}
+
+	return handoffStr, nil
+}
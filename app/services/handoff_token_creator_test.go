@@ -0,0 +1,35 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoffTokenCreator(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:               &url.URL{Scheme: "http", Host: "authn.example.com"},
+		ApplicationDomains:     []route.Domain{{Hostname: "app1.example.com"}, {Hostname: "*.example.org"}},
+		HandoffTokenSigningKey: []byte("handoff-a-reno"),
+	}
+
+	t.Run("for an application domain", func(t *testing.T) {
+		token, err := services.HandoffTokenCreator(cfg, 123, "https://shop.example.org", handoffs.TTL)
+		require.NoError(t, err)
+
+		claims, err := handoffs.Parse(token, cfg, "shop.example.org")
+		require.NoError(t, err)
+		assert.Equal(t, "123", claims.Subject)
+	})
+
+	t.Run("for an unknown domain", func(t *testing.T) {
+		_, err := services.HandoffTokenCreator(cfg, 123, "https://evil.com", handoffs.TTL)
+		assert.Equal(t, services.FieldErrors{{"origin", services.ErrNotFound}}, err)
+	})
+}
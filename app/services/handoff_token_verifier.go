@@ -0,0 +1,41 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pkg/errors"
+)
+
+// HandoffTokenVerifier returns the account of a handoff token minted for the audience domain. Like a
+// passwordless token, it may only be redeemed until the account logs in again.
+func HandoffTokenVerifier(store data.AccountStore, cfg *app.Config, token string, audience *route.Domain) (int, error) {
+	claims, err := handoffs.Parse(token, cfg, audience.String())
+	if err != nil {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	id, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return 0, errors.Wrap(err, "Atoi")
+	}
+
+	account, err := store.Find(id)
+	if err != nil {
+		return 0, errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return 0, FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return 0, FieldErrors{{"account", ErrLocked}}
+	} else if account.Archived() {
+		return 0, FieldErrors{{"account", ErrLocked}}
+	} else if account.LastLoginAt != nil && account.LastLoginAt.After(claims.IssuedAt.Time()) {
+		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
+	}
+
+	return account.ID, nil
+}
@@ -0,0 +1,69 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoffTokenVerifier(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{
+		AuthNURL:               &url.URL{Scheme: "http", Host: "authn.example.com"},
+		HandoffTokenSigningKey: []byte("handoff-a-reno"),
+	}
+	audience := &route.Domain{Hostname: "app2.example.com"}
+
+	newToken := func(id int, audience string) string {
+		claims, err := handoffs.New(cfg, id, audience, handoffs.TTL)
+		require.NoError(t, err)
+		token, err := claims.Sign(cfg.HandoffTokenSigningKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("for the audience", func(t *testing.T) {
+		account, err := accountStore.Create("first@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+
+		id, err := services.HandoffTokenVerifier(accountStore, cfg, newToken(account.ID, "app2.example.com"), audience)
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, id)
+	})
+
+	t.Run("for another audience", func(t *testing.T) {
+		account, err := accountStore.Create("second@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+
+		_, err = services.HandoffTokenVerifier(accountStore, cfg, newToken(account.ID, "app3.example.com"), audience)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("on a locked account", func(t *testing.T) {
+		locked, err := accountStore.Create("locked@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		_, err = accountStore.Lock(locked.ID)
+		require.NoError(t, err)
+
+		_, err = services.HandoffTokenVerifier(accountStore, cfg, newToken(locked.ID, "app2.example.com"), audience)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrLocked}}, err)
+	})
+
+	t.Run("when account has logged in again", func(t *testing.T) {
+		account, err := accountStore.Create("account@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		token := newToken(account.ID, "app2.example.com")
+		_, err = accountStore.SetLastLogin(account.ID)
+		require.NoError(t, err)
+
+		_, err = services.HandoffTokenVerifier(accountStore, cfg, token, audience)
+		assert.Equal(t, services.FieldErrors{{"token", services.ErrInvalidOrExpired}}, err)
+	})
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/pkg/errors"
+)
+
+// claimsClient is used while a user waits for a token, so it will not wait indefinitely
+var claimsClient = &http.Client{Timeout: 5 * time.Second}
+
+// IdentityClaimsFetcher asks the app for extra identity token claims. The app responds to a POST
+// with the account_id by returning a JSON object of claims.
+func IdentityClaimsFetcher(destination *url.URL, accountID int) (map[string]interface{}, error) {
+	res, err := claimsClient.PostForm(destination.String(), url.Values{
+		"account_id": []string{strconv.Itoa(accountID)},
+	})
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			// avoid reporting the URL with potential HTTP auth credentials
+			return nil, errors.Wrap(urlErr.Err, "PostForm")
+		}
+		return nil, errors.Wrap(err, "PostForm")
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		return nil, fmt.Errorf("PostForm: Status Code: %v", res.StatusCode)
+	}
+
+	claims := map[string]interface{}{}
+	err = json.NewDecoder(res.Body).Decode(&claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decode")
+	}
+	return claims, nil
+}
+
+// signIdentity creates and signs an identity token, with any claims from the app. Tokens are not
+// issued when the app can not be reached, since consumers may rely on its claims. Account metadata
+// named by IDENTITY_METADATA_CLAIMS is merged over IDENTITY_CLAIMS, and claims from the app are
+// merged over both. The account's roles and email_verified (when verification is configured)
+// replace any such claims.
+// Tokens are also encrypted when IDENTITY_ENCRYPTION_KEY is configured, or stored behind an opaque
+// token when ACCESS_TOKEN_FORMAT is opaque.
+func signIdentity(cfg *app.Config, accountStore data.AccountStore, keyStore data.KeyStore, accessTokenStore data.AccessTokenStore, session *sessions.Claims, accountID int, audience string, nonce string) (string, error) {
+	identity := identities.New(cfg, session, accountID, audience)
+	identity.Nonce = nonce
+
+	// roles and metadata are read on every refresh, so that changes apply without logging in again
+	account, err := accountStore.Find(accountID)
+	if err != nil {
+		return "", errors.Wrap(err, "Find")
+	}
+	if account != nil && len(account.Roles) > 0 {
+		identity.Roles = account.Roles
+	}
+	if account != nil {
+		for _, key := range cfg.IdentityMetadataClaims {
+			if val, ok := account.Metadata[key]; ok {
+				identity.Extra[key] = val
+			}
+		}
+	}
+	if account != nil && cfg.AppEmailVerificationURL != nil {
+		verified := account.Verified()
+		identity.EmailVerified = &verified
+	}
+
+	if cfg.AppClaimsURL != nil {
+		claims, err := IdentityClaimsFetcher(cfg.AppClaimsURL, accountID)
+		if err != nil {
+			return "", errors.Wrap(err, "IdentityClaimsFetcher")
+		}
+		for k, v := range claims {
+			identity.Extra[k] = v
+		}
+	}
+	if cfg.OpaqueAccessTokens {
+		identityToken, err := identity.Sign(keyStore.Key())
+		if err != nil {
+			return "", errors.Wrap(err, "Sign")
+		}
+		return accessTokenStore.Create(identityToken, identity.Expiry.Time())
+	}
+	if cfg.IdentityEncryptionKey != nil {
+		return identity.SignAndEncrypt(keyStore.Key(), cfg.IdentityEncryptionKey)
+	}
+	return identity.Sign(keyStore.Key())
+}
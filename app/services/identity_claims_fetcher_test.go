@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityClaimsFetcher(t *testing.T) {
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/success" && r.FormValue("account_id") == "42" {
+			w.Write([]byte(`{"roles": ["admin"], "plan": "pro"}`))
+		} else if r.URL.Path == "/invalid" {
+			w.Write([]byte(`["admin"]`))
+		} else {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	t.Run("fetching claims", func(t *testing.T) {
+		claims, err := services.IdentityClaimsFetcher(&url.URL{Scheme: "http", Host: serverURL.Host, Path: "/success"}, 42)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"roles": []interface{}{"admin"}, "plan": "pro"}, claims)
+	})
+
+	t.Run("with invalid response", func(t *testing.T) {
+		_, err := services.IdentityClaimsFetcher(&url.URL{Scheme: "http", Host: serverURL.Host, Path: "/invalid"}, 42)
+		assert.Error(t, err)
+	})
+
+	t.Run("with remote app failure", func(t *testing.T) {
+		_, err := services.IdentityClaimsFetcher(&url.URL{Scheme: "http", Host: serverURL.Host, Path: "/success"}, 7)
+		if assert.Error(t, err) {
+			assert.Equal(t, "PostForm: Status Code: 500", err.Error())
+		}
+	})
+}
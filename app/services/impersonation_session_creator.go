@@ -0,0 +1,75 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+// ImpersonationSessionCreator issues a session for the account on behalf of support staff, so
+// that they may reproduce what the user sees. The session and its identity tokens carry an
+// impersonated_by claim, and expire after IMPERSONATION_TTL. Unlike a login, it does not count as
+// activity of the account. Each session is logged for auditing.
+func ImpersonationSessionCreator(
+	accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, accessTokenStore data.AccessTokenStore, cfg *app.Config, logger logrus.FieldLogger,
+	accountID int, impersonatedBy string, origin string,
+) (string, string, error) {
+	impersonatedBy = strings.TrimSpace(impersonatedBy)
+	if impersonatedBy == "" {
+		return "", "", FieldErrors{{"impersonated_by", ErrMissing}}
+	}
+
+	account, err := accountStore.Find(accountID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return "", "", FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return "", "", FieldErrors{{"account", ErrLocked}}
+	}
+
+	domain := route.FindDomain(origin, cfg.ApplicationDomains)
+	if domain == nil {
+		return "", "", FieldErrors{{"origin", ErrNotFound}}
+	}
+
+	session, err := sessions.New(refreshTokenStore, cfg, accountID, domain.String())
+	if err != nil {
+		return "", "", errors.Wrap(err, "sessions.New")
+	}
+	session.ImpersonatedBy = impersonatedBy
+	session.Expiry = jwt.NewNumericDate(time.Now().Add(cfg.ImpersonationTTL))
+
+	err = refreshTokenStore.TouchFor(models.RefreshToken(session.Subject), accountID, cfg.ImpersonationTTL)
+	if err != nil {
+		return "", "", errors.Wrap(err, "TouchFor")
+	}
+
+	sessionToken, err := session.Sign(cfg.SessionSigningKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "session.Sign")
+	}
+
+	identityToken, err := signIdentity(cfg, accountStore, keyStore, accessTokenStore, session, accountID, domain.String(), "")
+	if err != nil {
+		return "", "", errors.Wrap(err, "signIdentity")
+	}
+
+	logger.WithFields(logrus.Fields{
+		"accountID":      accountID,
+		"impersonatedBy": impersonatedBy,
+		"sessionID":      models.RefreshToken(session.Subject).ID(),
+		"audience":       domain.String(),
+	}).Info("impersonation session issued")
+
+	return sessionToken, identityToken, nil
+}
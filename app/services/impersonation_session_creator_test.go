@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonationSessionCreator(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:           &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey:  []byte("key-a-reno"),
+		ApplicationDomains: []route.Domain{{Hostname: "app.example.com"}},
+		AccessTokenTTL:     time.Hour,
+		ImpersonationTTL:   15 * time.Minute,
+	}
+	rsaKey, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(rsaKey)
+	refreshStore := mock.NewRefreshTokenStore()
+	accountStore := mock.NewAccountStore()
+	logger, hook := logtest.NewNullLogger()
+
+	account, err := accountStore.Create("existing", []byte("secret"))
+	require.NoError(t, err)
+	locked, err := accountStore.Create("locked", []byte("secret"))
+	require.NoError(t, err)
+	accountStore.Lock(locked.ID)
+
+	t.Run("marks and audits the session", func(t *testing.T) {
+		sessionToken, identityToken, err := services.ImpersonationSessionCreator(
+			accountStore, refreshStore, keyStore, nil, cfg, logger,
+			account.ID, "support@example.com", "https://app.example.com",
+		)
+		require.NoError(t, err)
+
+		session, err := sessions.Parse(sessionToken, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "support@example.com", session.ImpersonatedBy)
+		assert.WithinDuration(t, time.Now().Add(15*time.Minute), session.Expiry.Time(), time.Minute)
+
+		identity, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "support@example.com", identity.ImpersonatedBy)
+		assert.Equal(t, session.Expiry, identity.Expiry)
+
+		entry := hook.LastEntry()
+		require.NotNil(t, entry)
+		assert.Equal(t, "impersonation session issued", entry.Message)
+		assert.Equal(t, account.ID, entry.Data["accountID"])
+		assert.Equal(t, "support@example.com", entry.Data["impersonatedBy"])
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found.LastLoginAt)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		testCases := []struct {
+			accountID      int
+			impersonatedBy string
+			origin         string
+			errors         services.FieldErrors
+		}{
+			{account.ID, " ", "https://app.example.com", services.FieldErrors{{"impersonated_by", services.ErrMissing}}},
+			{0, "support@example.com", "https://app.example.com", services.FieldErrors{{"account", services.ErrNotFound}}},
+			{locked.ID, "support@example.com", "https://app.example.com", services.FieldErrors{{"account", services.ErrLocked}}},
+			{account.ID, "support@example.com", "https://evil.example.com", services.FieldErrors{{"origin", services.ErrNotFound}}},
+		}
+
+		for _, tc := range testCases {
+			hook.Reset()
+			_, _, err := services.ImpersonationSessionCreator(
+				accountStore, refreshStore, keyStore, nil, cfg, logger,
+				tc.accountID, tc.impersonatedBy, tc.origin,
+			)
+			assert.Equal(t, tc.errors, err)
+			assert.Empty(t, hook.Entries)
+		}
+	})
+}
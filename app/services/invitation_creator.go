@@ -0,0 +1,31 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/pkg/errors"
+)
+
+// InvitationCreator mints a token that allows signing up while ENABLE_SIGNUP is disabled. When an
+// email is given, only that username may sign up with the token.
+func InvitationCreator(cfg *app.Config, email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email != "" {
+		if fieldError := UsernameValidator(cfg, email); fieldError != nil {
+			return "", FieldErrors{{"email", fieldError.Message}}
+		}
+	}
+
+	invitation, err := invitations.New(cfg, email)
+	if err != nil {
+		return "", errors.Wrap(err, "New Invitation Token")
+	}
+	invitationStr, err := invitation.Sign(cfg.InvitationSigningKey)
+	if err != nil {
+		return "", errors.Wrap(err, "Sign")
+	}
+
+	return invitationStr, nil
+}
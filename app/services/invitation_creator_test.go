@@ -0,0 +1,45 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationCreator(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:             &url.URL{Scheme: "http", Host: "authn.example.com"},
+		UsernameIsEmail:      true,
+		InvitationSigningKey: []byte("invite-a-reno"),
+		InvitationTokenTTL:   time.Hour,
+	}
+
+	t.Run("for anyone", func(t *testing.T) {
+		token, err := services.InvitationCreator(cfg, "")
+		require.NoError(t, err)
+
+		claims, err := invitations.Parse(token, cfg)
+		require.NoError(t, err)
+		assert.Empty(t, claims.Email)
+	})
+
+	t.Run("for an email", func(t *testing.T) {
+		token, err := services.InvitationCreator(cfg, " invited@example.com ")
+		require.NoError(t, err)
+
+		claims, err := invitations.Parse(token, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "invited@example.com", claims.Email)
+	})
+
+	t.Run("for an invalid email", func(t *testing.T) {
+		_, err := services.InvitationCreator(cfg, "invalid")
+		assert.Equal(t, services.FieldErrors{{"email", services.ErrFormatInvalid}}, err)
+	})
+}
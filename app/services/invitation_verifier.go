@@ -0,0 +1,39 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/pkg/errors"
+)
+
+// InvitationVerifier checks that a token from InvitationCreator allows the username to sign up.
+// With a denylist, the invitation is then spent until it expires, so that it only signs up one
+// account. If the signup fails, the caller may allow the invitation again.
+func InvitationVerifier(denylist data.TokenDenylist, cfg *app.Config, token string, username string) (*invitations.Claims, error) {
+	if token == "" {
+		return nil, FieldErrors{{"invitation", ErrMissing}}
+	}
+
+	claims, err := invitations.Parse(token, cfg)
+	if err != nil {
+		return nil, FieldErrors{{"invitation", ErrInvalidOrExpired}}
+	}
+	if claims.Email != "" && !strings.EqualFold(claims.Email, strings.TrimSpace(username)) {
+		return nil, FieldErrors{{"invitation", ErrInvalidOrExpired}}
+	}
+
+	if denylist != nil {
+		spent, err := denylist.Spend(claims.ID, claims.Expiry.Time())
+		if err != nil {
+			return nil, errors.Wrap(err, "Spend")
+		}
+		if !spent {
+			return nil, FieldErrors{{"invitation", ErrInvalidOrExpired}}
+		}
+	}
+
+	return claims, nil
+}
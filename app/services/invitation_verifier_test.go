@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationVerifier(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:             &url.URL{Scheme: "http", Host: "authn.example.com"},
+		InvitationSigningKey: []byte("invite-a-reno"),
+		InvitationTokenTTL:   time.Hour,
+	}
+
+	newToken := func(cfg *app.Config, email string) string {
+		claims, err := invitations.New(cfg, email)
+		require.NoError(t, err)
+		token, err := claims.Sign(cfg.InvitationSigningKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("for anyone", func(t *testing.T) {
+		_, err := services.InvitationVerifier(nil, cfg, newToken(cfg, ""), "anyone@example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("for the bound email", func(t *testing.T) {
+		_, err := services.InvitationVerifier(nil, cfg, newToken(cfg, "invited@example.com"), " Invited@Example.com ")
+		assert.NoError(t, err)
+	})
+
+	t.Run("a second time", func(t *testing.T) {
+		denylist := mock.NewTokenDenylist()
+		token := newToken(cfg, "")
+		claims, err := services.InvitationVerifier(denylist, cfg, token, "anyone@example.com")
+		require.NoError(t, err)
+
+		_, err = services.InvitationVerifier(denylist, cfg, token, "someone@example.com")
+		assert.Equal(t, services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}, err)
+
+		// until it is allowed again
+		require.NoError(t, denylist.Allow(claims.ID))
+		_, err = services.InvitationVerifier(denylist, cfg, token, "someone@example.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		expired := *cfg
+		expired.InvitationTokenTTL = -time.Hour
+
+		testCases := []struct {
+			token    string
+			username string
+			errors   services.FieldErrors
+		}{
+			{"", "anyone@example.com", services.FieldErrors{{"invitation", services.ErrMissing}}},
+			{"not.valid.jwt", "anyone@example.com", services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}},
+			{newToken(&expired, ""), "anyone@example.com", services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}},
+			{newToken(cfg, "invited@example.com"), "other@example.com", services.FieldErrors{{"invitation", services.ErrInvalidOrExpired}}},
+		}
+
+		for _, tc := range testCases {
+			_, err := services.InvitationVerifier(nil, cfg, tc.token, tc.username)
+			assert.Equal(t, tc.errors, err)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/hex"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib"
+	"github.com/keratin/authn-server/lib/ldap"
+	"github.com/pkg/errors"
+)
+
+// LDAPCredentialsVerifier verifies a username and password with an LDAP directory instead of the
+// local password hash. An account is created for the username on its first successful login.
+func LDAPCredentialsVerifier(store data.AccountStore, cfg *app.Config, authenticator ldap.Authenticator, username string, password string) (*models.Account, error) {
+	username = strings.TrimSpace(username)
+	if username == "" || password == "" {
+		return nil, FieldErrors{{"credentials", ErrFailed}}
+	}
+
+	err := authenticator.Authenticate(username, password)
+	if err == ldap.ErrInvalidCredentials {
+		return nil, FieldErrors{{"credentials", ErrFailed}}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "Authenticate")
+	}
+
+	account, err := store.FindByUsername(username)
+	if err != nil {
+		return nil, errors.Wrap(err, "FindByUsername")
+	}
+	if account == nil {
+		rand, err := lib.GenerateToken()
+		if err != nil {
+			return nil, errors.Wrap(err, "GenerateToken")
+		}
+		// the local password is never used, since the directory verifies every login
+		account, err = AccountCreator(store, cfg, username, hex.EncodeToString(rand))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if account.Locked {
+		return nil, FieldErrors{{"account", ErrLocked}}
+	}
+
+	return account, nil
+}
@@ -0,0 +1,56 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/ldap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLDAPCredentialsVerifier(t *testing.T) {
+	cfg := app.Config{BcryptCost: 4, UsernameMinLength: 3, UsernameMaxLength: 255}
+	directory := ldap.TestAuthenticator{"known": "secret", "locked": "secret", "new": "secret"}
+	store := mock.NewAccountStore()
+	known, _ := store.Create("known", []byte("unused"))
+	locked, _ := store.Create("locked", []byte("unused"))
+	store.Lock(locked.ID)
+
+	t.Run("existing account", func(t *testing.T) {
+		acc, err := services.LDAPCredentialsVerifier(store, &cfg, directory, "known", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, known.ID, acc.ID)
+	})
+
+	t.Run("new account", func(t *testing.T) {
+		acc, err := services.LDAPCredentialsVerifier(store, &cfg, directory, "new", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, "new", acc.Username)
+
+		again, err := services.LDAPCredentialsVerifier(store, &cfg, directory, "new", "secret")
+		require.NoError(t, err)
+		assert.Equal(t, acc.ID, again.ID)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		testCases := []struct {
+			username string
+			password string
+			errors   services.FieldErrors
+		}{
+			{"", "", services.FieldErrors{{"credentials", "FAILED"}}},
+			{"known", "", services.FieldErrors{{"credentials", "FAILED"}}},
+			{"known", "unknown", services.FieldErrors{{"credentials", "FAILED"}}},
+			{"unknown", "secret", services.FieldErrors{{"credentials", "FAILED"}}},
+			{"locked", "secret", services.FieldErrors{{"account", "LOCKED"}}},
+		}
+
+		for _, tc := range testCases {
+			_, errs := services.LDAPCredentialsVerifier(store, &cfg, directory, tc.username, tc.password)
+			assert.Equal(t, tc.errors, errs)
+		}
+	})
+}
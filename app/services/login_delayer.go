@@ -0,0 +1,60 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// firstLoginDelay is the delay once a username and client IP reach LOGIN_LOCKOUT_THRESHOLD.
+const firstLoginDelay = time.Second
+
+// LoginDelayer returns how long a client IP must wait before trying the username again.
+//
+// With LOGIN_LOCKOUT_DELAY, once the pair has reached LOGIN_LOCKOUT_THRESHOLD failures the delay
+// starts at one second and doubles with each further failure, up to LOGIN_FAILURE_WINDOW.
+// Otherwise the username's account waits out its lock from any client IP.
+func LoginDelayer(failedLogins data.FailedLogins, accountStore data.AccountStore, cfg *app.Config, username string, ip string) (time.Duration, error) {
+	if failedLogins == nil || cfg.LoginLockoutThreshold <= 0 {
+		return 0, nil
+	}
+
+	if !cfg.LoginLockoutDelay {
+		account, err := accountStore.FindByUsername(username)
+		if err != nil {
+			return 0, errors.Wrap(err, "FindByUsername")
+		}
+		if account == nil {
+			return 0, nil
+		}
+		locked, err := failedLogins.LockedFor(account.ID)
+		if err != nil {
+			return 0, errors.Wrap(err, "LockedFor")
+		}
+		return locked, nil
+	}
+
+	failures, lastFailure, err := failedLogins.CountPair(username, ip)
+	if err != nil {
+		return 0, errors.Wrap(err, "CountPair")
+	}
+	if failures < cfg.LoginLockoutThreshold {
+		return 0, nil
+	}
+
+	delay := firstLoginDelay
+	for i := cfg.LoginLockoutThreshold; i < failures && delay < cfg.LoginFailureWindow; i++ {
+		delay *= 2
+	}
+	if delay > cfg.LoginFailureWindow {
+		delay = cfg.LoginFailureWindow
+	}
+
+	wait := time.Until(lastFailure.Add(delay))
+	if wait < 0 {
+		return 0, nil
+	}
+	return wait, nil
+}
@@ -0,0 +1,64 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginDelayer(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	account, err := accountStore.Create("alice", []byte("password"))
+	require.NoError(t, err)
+
+	failedLogins := mock.NewFailedLogins(time.Hour)
+	failedLogins.Add("alice", "127.0.0.1")
+	failedLogins.Add("alice", "127.0.0.1")
+
+	cfg := &app.Config{LoginLockoutThreshold: 3, LoginFailureWindow: 3 * time.Second}
+	delay, err := services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	// without the delay, a locked account waits out its lock from any client IP
+	failedLogins.Lock(account.ID, time.Minute)
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Minute, delay, float64(time.Second))
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "unknown", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	cfg.LoginLockoutDelay = true
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	failedLogins.Add("alice", "127.0.0.1")
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.InDelta(t, time.Second, delay, float64(100*time.Millisecond))
+
+	failedLogins.Add("alice", "127.0.0.1")
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.InDelta(t, 2*time.Second, delay, float64(100*time.Millisecond))
+
+	failedLogins.Add("alice", "127.0.0.1")
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.InDelta(t, 3*time.Second, delay, float64(100*time.Millisecond))
+
+	delay, err = services.LoginDelayer(failedLogins, accountStore, cfg, "alice", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	delay, err = services.LoginDelayer(nil, accountStore, cfg, "alice", "127.0.0.1")
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+}
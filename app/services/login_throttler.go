@@ -0,0 +1,26 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// LoginThrottler returns how long a client IP that has reached LOGIN_IP_THRESHOLD failures must
+// wait before logging in again, until LOGIN_FAILURE_WINDOW forgets them.
+func LoginThrottler(failedLogins data.FailedLogins, cfg *app.Config, ip string) (time.Duration, error) {
+	if failedLogins == nil || cfg.LoginIPThreshold <= 0 {
+		return 0, nil
+	}
+
+	failures, ttl, err := failedLogins.CountIP(ip)
+	if err != nil {
+		return 0, errors.Wrap(err, "CountIP")
+	}
+	if failures >= cfg.LoginIPThreshold {
+		return ttl, nil
+	}
+	return 0, nil
+}
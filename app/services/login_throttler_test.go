@@ -0,0 +1,36 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginThrottler(t *testing.T) {
+	failedLogins := mock.NewFailedLogins(time.Minute)
+	failedLogins.Add("alice", "127.0.0.1")
+	failedLogins.Add("bob", "127.0.0.1")
+
+	throttle := func(failedLogins data.FailedLogins, cfg *app.Config, ip string) time.Duration {
+		wait, err := services.LoginThrottler(failedLogins, cfg, ip)
+		require.NoError(t, err)
+		return wait
+	}
+
+	cfg := &app.Config{}
+	assert.Zero(t, throttle(failedLogins, cfg, "127.0.0.1"))
+
+	cfg.LoginIPThreshold = 3
+	assert.Zero(t, throttle(failedLogins, cfg, "127.0.0.1"))
+
+	failedLogins.Add("carol", "127.0.0.1")
+	assert.InDelta(t, time.Minute, throttle(failedLogins, cfg, "127.0.0.1"), float64(time.Second))
+	assert.Zero(t, throttle(failedLogins, cfg, "10.0.0.1"))
+	assert.Zero(t, throttle(nil, cfg, "127.0.0.1"))
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// LogoutNotifier sends a back-channel logout token to every APP_BACKCHANNEL_LOGOUT_URLS endpoint,
+// so that applications may end their own sessions. An empty sessionID notifies that all of the
+// account's sessions have ended.
+func LogoutNotifier(ctx context.Context, cfg *app.Config, keyStore data.KeyStore, r ops.ErrorReporter, accountID int, sessionID string) {
+	for _, destination := range cfg.AppBackchannelLogoutURLs {
+		domain := route.ParseDomain(destination.Host)
+		claims, err := logouts.New(cfg, accountID, sessionID, cfg.SettingsFor(&domain).Audience)
+		if err != nil {
+			r.ReportError(errors.Wrap(err, "New"))
+			continue
+		}
+		token, err := claims.Sign(keyStore.Key())
+		if err != nil {
+			r.ReportError(errors.Wrap(err, "Sign"))
+			continue
+		}
+
+		go func(destination *url.URL) {
+			err := WebhookSender(ctx, destination, &url.Values{
+				"logout_token": []string{token},
+			}, timeSensitiveDelivery)
+			if err != nil {
+				r.ReportError(err)
+			}
+		}(destination)
+	}
+}
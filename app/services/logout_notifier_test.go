@@ -0,0 +1,84 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogoutNotifier(t *testing.T) {
+	received := make(chan string, 1)
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.FormValue("logout_token")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+	logoutURL, err := url.Parse(remoteApp.URL + "/logout")
+	require.NoError(t, err)
+
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	reporter := &ops.LogReporter{FieldLogger: logrus.New()}
+	cfg := &app.Config{
+		AuthNURL:                 &url.URL{Scheme: "https", Host: "authn.example.com"},
+		AppBackchannelLogoutURLs: []*url.URL{logoutURL},
+	}
+
+	receive := func(t *testing.T) *logouts.Claims {
+		select {
+		case tokenStr := <-received:
+			claims, err := logouts.Parse(tokenStr, cfg, key)
+			require.NoError(t, err)
+			return claims
+		case <-time.After(time.Second):
+			t.Fatal("no logout token received")
+			return nil
+		}
+	}
+
+	t.Run("for a session", func(t *testing.T) {
+		services.LogoutNotifier(context.Background(), cfg, keyStore, reporter, 123, "abc")
+		claims := receive(t)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Equal(t, "abc", claims.SessionID)
+		assert.True(t, claims.Audience.Contains(logoutURL.Host))
+	})
+
+	t.Run("when revoking all sessions", func(t *testing.T) {
+		store := mock.NewRefreshTokenStore()
+		_, err := store.Create(123)
+		require.NoError(t, err)
+
+		err = services.SessionBatchEnder(context.Background(), store, keyStore, cfg, reporter, 123)
+		require.NoError(t, err)
+		claims := receive(t)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Empty(t, claims.SessionID)
+	})
+
+	t.Run("when ending a session", func(t *testing.T) {
+		store := mock.NewRefreshTokenStore()
+		token, err := store.Create(123)
+		require.NoError(t, err)
+
+		err = services.SessionEnder(context.Background(), store, keyStore, cfg, reporter, &token)
+		require.NoError(t, err)
+		claims := receive(t)
+		assert.Equal(t, "123", claims.Subject)
+		assert.Equal(t, token.ID(), claims.SessionID)
+	})
+}
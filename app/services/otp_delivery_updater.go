@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// OTPDeliveryUpdater enables or disables codes from APP_OTP_DELIVERY_URL at login. The app should
+// only enable them once it can deliver to the account, as with a verified phone number.
+func OTPDeliveryUpdater(store data.AccountStore, accountID int, enabled bool) error {
+	affected, err := store.SetOTPDelivery(accountID, enabled)
+	if err != nil {
+		return errors.Wrap(err, "SetOTPDelivery")
+	}
+	if !affected {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+	return nil
+}
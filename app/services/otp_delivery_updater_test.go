@@ -0,0 +1,34 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPDeliveryUpdater(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+
+	t.Run("unknown account", func(t *testing.T) {
+		err := services.OTPDeliveryUpdater(accountStore, 9999, true)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+
+	t.Run("enabling and disabling", func(t *testing.T) {
+		account, err := accountStore.Create("otp@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+
+		require.NoError(t, services.OTPDeliveryUpdater(accountStore, account.ID, true))
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.OTPDeliveryEnabled())
+
+		require.NoError(t, services.OTPDeliveryUpdater(accountStore, account.ID, false))
+		found, err = accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.OTPDeliveryEnabled())
+	})
+}
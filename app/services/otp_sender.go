@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// OTPSender generates a one-time password for the account and sends it to APP_OTP_DELIVERY_URL,
+// so that the app may deliver it by SMS or voice. Codes are not sent once the account has reached
+// its limit, so that logins can not be used to run up the app's bill.
+func OTPSender(ctx context.Context, otpStore data.OTPStore, cfg *app.Config, account *models.Account, logger logrus.FieldLogger) error {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return errors.Wrap(err, "Int")
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	ok, err := otpStore.Create(account.ID, code, cfg.OTPDeliveryTTL)
+	if err != nil {
+		return errors.Wrap(err, "Create")
+	}
+	if !ok {
+		logger.WithField("accountID", account.ID).Info("skipped one-time password over limit")
+		return nil
+	}
+
+	err = WebhookSender(ctx, cfg.AppOTPDeliveryURL, &url.Values{
+		"account_id": []string{strconv.Itoa(account.ID)},
+		"otp":        []string{code},
+	}, timeSensitiveDelivery)
+	if err != nil {
+		return errors.Wrap(err, "Webhook")
+	}
+
+	logger.WithField("accountID", account.ID).Info("sent one-time password")
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// OTPVerifier requires a second factor at login for accounts that have one. A code may come from
+// the account's authenticator or from APP_OTP_DELIVERY_URL. When the code is missing and delivery
+// is enabled, a new code is sent, so the client only needs to submit the login again.
+func OTPVerifier(ctx context.Context, otpStore data.OTPStore, cfg *app.Config, account *models.Account, otp string, logger logrus.FieldLogger) error {
+	if !SecondFactorRequired(otpStore, cfg, account) {
+		return nil
+	}
+	delivery := otpDelivery(otpStore, cfg, account)
+
+	if otp == "" {
+		if delivery {
+			err := OTPSender(ctx, otpStore, cfg, account, logger)
+			if err != nil {
+				return errors.Wrap(err, "OTPSender")
+			}
+		}
+		return FieldErrors{{"otp", ErrMissing}}
+	}
+
+	if delivery {
+		ok, err := otpStore.Verify(account.ID, otp)
+		if err != nil {
+			return errors.Wrap(err, "Verify")
+		}
+		if ok {
+			return nil
+		}
+	}
+	if account.TOTPEnabled() {
+		return TOTPVerifier(cfg, account, otp)
+	}
+	return FieldErrors{{"otp", ErrInvalidOrExpired}}
+}
+
+// SecondFactorRequired reports whether logging in to the account requires a code, either from an
+// authenticator or from APP_OTP_DELIVERY_URL.
+func SecondFactorRequired(otpStore data.OTPStore, cfg *app.Config, account *models.Account) bool {
+	return account.TOTPEnabled() || otpDelivery(otpStore, cfg, account)
+}
+
+func otpDelivery(otpStore data.OTPStore, cfg *app.Config, account *models.Account) bool {
+	return account.OTPDeliveryEnabled() && cfg.AppOTPDeliveryURL != nil && otpStore != nil
+}
@@ -0,0 +1,100 @@
+package services_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTPVerifier(t *testing.T) {
+	var delivered []string
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = append(delivered, r.FormValue("otp"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+	deliveryURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{
+		AppOTPDeliveryURL: deliveryURL,
+		OTPDeliveryTTL:    time.Minute,
+		DBEncryptionKey:   []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
+	}
+	logger := logrus.New()
+
+	t.Run("without a second factor", func(t *testing.T) {
+		account, err := accountStore.Create("none@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		err = services.OTPVerifier(context.Background(), mock.NewOTPStore(1, time.Minute, 1), cfg, account, "", logger)
+		assert.NoError(t, err)
+	})
+
+	t.Run("with delivered codes", func(t *testing.T) {
+		otpStore := mock.NewOTPStore(data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts)
+		account, err := accountStore.Create("sms@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		_, err = accountStore.SetOTPDelivery(account.ID, true)
+		require.NoError(t, err)
+		account, err = accountStore.Find(account.ID)
+		require.NoError(t, err)
+
+		delivered = nil
+		err = services.OTPVerifier(context.Background(), otpStore, cfg, account, "", logger)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+		require.Len(t, delivered, 1)
+		assert.Len(t, delivered[0], 6)
+
+		err = services.OTPVerifier(context.Background(), otpStore, cfg, account, "not-it", logger)
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+
+		err = services.OTPVerifier(context.Background(), otpStore, cfg, account, delivered[0], logger)
+		assert.NoError(t, err)
+	})
+
+	t.Run("over the delivery limit", func(t *testing.T) {
+		otpStore := mock.NewOTPStore(1, time.Minute, 1)
+		account, err := accountStore.Create("limited@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		_, err = accountStore.SetOTPDelivery(account.ID, true)
+		require.NoError(t, err)
+		account, err = accountStore.Find(account.ID)
+		require.NoError(t, err)
+
+		delivered = nil
+		services.OTPVerifier(context.Background(), otpStore, cfg, account, "", logger)
+		services.OTPVerifier(context.Background(), otpStore, cfg, account, "", logger)
+		assert.Len(t, delivered, 1)
+	})
+
+	t.Run("with an authenticator and delivered codes", func(t *testing.T) {
+		otpStore := mock.NewOTPStore(data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts)
+		account, err := accountStore.Create("both@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		_, err = accountStore.SetOTPDelivery(account.ID, true)
+		require.NoError(t, err)
+		secret, _, err := services.TOTPEnroller(accountStore, cfg, account.ID, "example.com")
+		require.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, services.TOTPConfirmer(accountStore, cfg, account.ID, code))
+		account, err = accountStore.Find(account.ID)
+		require.NoError(t, err)
+
+		err = services.OTPVerifier(context.Background(), otpStore, cfg, account, code, logger)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/lib/hibp"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// PasswordBreachValidator rejects a new password that has been found in a breach. A password is
+// allowed when the breach list is unavailable, so that an outage does not block signups.
+func PasswordBreachValidator(checker hibp.Checker, r ops.ErrorReporter, password string) *FieldError {
+	if checker == nil || password == "" {
+		return nil
+	}
+
+	breached, err := checker.Breached(password)
+	if err != nil {
+		if err != hibp.ErrUnavailable {
+			r.ReportError(errors.Wrap(err, "Breached"))
+		}
+		return nil
+	}
+	if breached {
+		return &FieldError{"password", ErrInsecure}
+	}
+
+	return nil
+}
@@ -0,0 +1,27 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/hibp"
+	"github.com/keratin/authn-server/ops"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type unavailableChecker struct{}
+
+func (unavailableChecker) Breached(password string) (bool, error) {
+	return false, hibp.ErrUnavailable
+}
+
+func TestPasswordBreachValidator(t *testing.T) {
+	checker := hibp.TestChecker{"password123"}
+	reporter := &ops.LogReporter{logrus.New()}
+
+	assert.Equal(t, &services.FieldError{"password", services.ErrInsecure}, services.PasswordBreachValidator(checker, reporter, "password123"))
+	assert.Nil(t, services.PasswordBreachValidator(checker, reporter, "0a0b0c0d0e0f"))
+	assert.Nil(t, services.PasswordBreachValidator(nil, reporter, "password123"))
+	assert.Nil(t, services.PasswordBreachValidator(unavailableChecker{}, reporter, "password123"))
+}
@@ -1,14 +1,16 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/lib/hibp"
 	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
-	"golang.org/x/crypto/bcrypt"
 )
 
-func PasswordChanger(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, id int, currentPassword string, password string) error {
+func PasswordChanger(ctx context.Context, store data.AccountStore, checker hibp.Checker, r ops.ErrorReporter, cfg *app.Config, id int, currentPassword string, password string) error {
 	account, err := store.Find(id)
 	if err != nil {
 		return errors.Wrap(err, "Find")
@@ -19,10 +21,9 @@ func PasswordChanger(store data.AccountStore, r ops.ErrorReporter, cfg *app.Conf
 		return FieldErrors{{"account", ErrLocked}}
 	}
 
-	err = bcrypt.CompareHashAndPassword(account.Password, []byte(currentPassword))
-	if err != nil {
+	if matched, _ := passwordMatches(account.Password, currentPassword); !matched {
 		return FieldErrors{{"credentials", ErrFailed}}
 	}
 
-	return PasswordSetter(store, r, cfg, id, password)
+	return PasswordSetter(ctx, store, checker, r, cfg, id, password)
 }
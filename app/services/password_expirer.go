@@ -1,11 +1,15 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 )
 
-func PasswordExpirer(store data.AccountStore, tokenStore data.RefreshTokenStore, accountID int) error {
+func PasswordExpirer(ctx context.Context, store data.AccountStore, tokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int) error {
 	affected, err := store.RequireNewPassword(accountID)
 	if err != nil {
 		return errors.Wrap(err, "RequireNewPassword")
@@ -14,5 +18,5 @@ func PasswordExpirer(store data.AccountStore, tokenStore data.RefreshTokenStore,
 		return FieldErrors{{"account", ErrNotFound}}
 	}
 
-	return SessionBatchEnder(tokenStore, accountID)
+	return SessionBatchEnder(ctx, tokenStore, keyStore, cfg, reporter, accountID)
 }
@@ -1,8 +1,10 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -21,7 +23,7 @@ func TestPasswordExpirer(t *testing.T) {
 		token2, err := refreshStore.Create(account.ID)
 		require.NoError(t, err)
 
-		errors := services.PasswordExpirer(accountStore, refreshStore, account.ID)
+		errors := services.PasswordExpirer(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, account.ID)
 		assert.Empty(t, errors)
 
 		account, err = accountStore.Find(account.ID)
@@ -37,7 +39,7 @@ func TestPasswordExpirer(t *testing.T) {
 	})
 
 	t.Run("unknown account", func(t *testing.T) {
-		errors := services.PasswordExpirer(accountStore, refreshStore, 0)
+		errors := services.PasswordExpirer(context.Background(), accountStore, refreshStore, nil, &app.Config{}, nil, 0)
 		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, errors)
 	})
 }
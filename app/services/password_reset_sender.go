@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 
@@ -11,7 +12,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func PasswordResetSender(cfg *app.Config, account *models.Account, logger logrus.FieldLogger) error {
+// PasswordResetSender delivers a password reset token to the app, forwarding the ID of the request
+// that asked for it.
+func PasswordResetSender(ctx context.Context, cfg *app.Config, account *models.Account, logger logrus.FieldLogger, requestID string) error {
 	if account == nil || account.Locked {
 		return nil
 	}
@@ -25,10 +28,10 @@ func PasswordResetSender(cfg *app.Config, account *models.Account, logger logrus
 		return errors.Wrap(err, "Sign")
 	}
 
-	err = WebhookSender(cfg.AppPasswordResetURL, &url.Values{
+	err = requestWebhookSender(ctx, cfg.AppPasswordResetURL, &url.Values{
 		"account_id": []string{strconv.Itoa(account.ID)},
 		"token":      []string{resetStr},
-	}, timeSensitiveDelivery)
+	}, timeSensitiveDelivery, requestID)
 	if err != nil {
 		return errors.Wrap(err, "Webhook")
 	}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -16,7 +17,9 @@ import (
 )
 
 func TestPasswordResetSender(t *testing.T) {
+	var requestID string
 	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID = r.Header.Get("X-Request-ID")
 		u, p, ok := r.BasicAuth()
 
 		if !ok || u != "user" || p != "pass" {
@@ -33,14 +36,17 @@ func TestPasswordResetSender(t *testing.T) {
 	authNURL := &url.URL{Scheme: "https", Host: "authn.example.com"}
 	resetURL := &url.URL{Scheme: "http", Host: serverURL.Host, Path: "/reset", User: url.UserPassword("user", "pass")}
 
-	invoke := func(account *models.Account) error {
+	invokeWithRequestID := func(account *models.Account, id string) error {
 		cfg := &app.Config{
 			AuthNURL:            authNURL,
 			AppPasswordResetURL: resetURL,
 			ResetSigningKey:     []byte("resets"),
 			ResetTokenTTL:       time.Minute,
 		}
-		return services.PasswordResetSender(cfg, account, logrus.New())
+		return services.PasswordResetSender(context.Background(), cfg, account, logrus.New(), id)
+	}
+	invoke := func(account *models.Account) error {
+		return invokeWithRequestID(account, "")
 	}
 
 	t.Run("posting to remote app", func(t *testing.T) {
@@ -51,6 +57,15 @@ func TestPasswordResetSender(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("forwarding the request ID", func(t *testing.T) {
+		err := invokeWithRequestID(&models.Account{
+			ID:                1234,
+			PasswordChangedAt: time.Now(),
+		}, "abc123")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", requestID)
+	})
+
 	t.Run("with locked account", func(t *testing.T) {
 		err := invoke(&models.Account{
 			ID:                1234,
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"strconv"
 
 	"github.com/keratin/authn-server/ops"
@@ -8,10 +9,17 @@ import (
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/tokens/resets"
+	"github.com/keratin/authn-server/lib/hibp"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
-func PasswordResetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, token string, password string) (int, error) {
+// PasswordResetter sets a new password with a token from the reset email. An account with a second
+// factor must also provide a code, since the email alone should not be enough to take it over.
+func PasswordResetter(
+	ctx context.Context, store data.AccountStore, otpStore data.OTPStore, checker hibp.Checker, r ops.ErrorReporter, cfg *app.Config, logger logrus.FieldLogger,
+	token string, password string, otp string,
+) (int, error) {
 	claims, err := resets.Parse(token, cfg)
 	if err != nil {
 		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
@@ -38,5 +46,10 @@ func PasswordResetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Con
 		return 0, FieldErrors{{"token", ErrInvalidOrExpired}}
 	}
 
-	return account.ID, PasswordSetter(store, r, cfg, id, password)
+	err = OTPVerifier(ctx, otpStore, cfg, account, otp, logger)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.ID, PasswordSetter(ctx, store, checker, r, cfg, id, password)
 }
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/url"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/app/tokens/resets"
+	"github.com/keratin/authn-server/lib/totp"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +24,8 @@ func TestPasswordResetter(t *testing.T) {
 		BcryptCost:            4,
 		PasswordMinComplexity: 1,
 		ResetSigningKey:       []byte("reset-a-reno"),
+		JWTLeeway:             time.Minute,
+		DBEncryptionKey:       []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
 	}
 
 	newToken := func(id int, lock time.Time) string {
@@ -32,10 +36,13 @@ func TestPasswordResetter(t *testing.T) {
 		return token
 	}
 
-	invoke := func(token string, password string) error {
-		_, err := services.PasswordResetter(accountStore, &ops.LogReporter{logrus.New()}, cfg, token, password)
+	invokeWithOTP := func(token string, password string, otp string) error {
+		_, err := services.PasswordResetter(context.Background(), accountStore, nil, nil, &ops.LogReporter{logrus.New()}, cfg, logrus.New(), token, password, otp)
 		return err
 	}
+	invoke := func(token string, password string) error {
+		return invokeWithOTP(token, password, "")
+	}
 
 	account, err := accountStore.Create("existing@keratin.tech", []byte("old"))
 	require.NoError(t, err)
@@ -111,4 +118,27 @@ func TestPasswordResetter(t *testing.T) {
 		err := invoke(token, "0a0b0c0d0e0f")
 		assert.Equal(t, services.FieldErrors{{"account", "NOT_FOUND"}}, err)
 	})
+
+	t.Run("with an authenticator", func(t *testing.T) {
+		enrolled, err := accountStore.Create("totp@keratin.tech", []byte("old"))
+		require.NoError(t, err)
+		secret, _, err := services.TOTPEnroller(accountStore, cfg, enrolled.ID, "example.com")
+		require.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, services.TOTPConfirmer(accountStore, cfg, enrolled.ID, code))
+		token := newToken(enrolled.ID, enrolled.PasswordChangedAt)
+
+		err = invoke(token, "0a0b0c0d0e0f")
+		assert.Equal(t, services.FieldErrors{{"otp", "MISSING"}}, err)
+		found, err := accountStore.Find(enrolled.ID)
+		require.NoError(t, err)
+		assert.Equal(t, enrolled.Password, found.Password)
+
+		err = invokeWithOTP(token, "0a0b0c0d0e0f", "000000")
+		assert.Equal(t, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}}, err)
+
+		err = invokeWithOTP(token, "0a0b0c0d0e0f", code)
+		assert.NoError(t, err)
+	})
 }
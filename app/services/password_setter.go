@@ -1,18 +1,36 @@
 package services
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/lib/hibp"
 	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func PasswordSetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Config, accountID int, password string) error {
-	fieldError := PasswordValidator(cfg, password)
+// PasswordSetter validates and sets a new password. A password that passes the local checks is also
+// checked against the breach list, after normalization.
+func PasswordSetter(ctx context.Context, store data.AccountStore, checker hibp.Checker, r ops.ErrorReporter, cfg *app.Config, accountID int, password string) error {
+	password = normalizePassword(password)
+
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+
+	fieldError := PasswordValidator(cfg, password, passwordUserInputs(account.Username)...)
+	if fieldError != nil {
+		return FieldErrors{*fieldError}
+	}
+	fieldError = PasswordBreachValidator(checker, r, password)
 	if fieldError != nil {
 		return FieldErrors{*fieldError}
 	}
@@ -32,7 +50,7 @@ func PasswordSetter(store data.AccountStore, r ops.ErrorReporter, cfg *app.Confi
 
 	if cfg.AppPasswordChangedURL != nil {
 		go func() {
-			err := WebhookSender(cfg.AppPasswordChangedURL, &url.Values{
+			err := WebhookSender(ctx, cfg.AppPasswordChangedURL, &url.Values{
 				"account_id": []string{strconv.Itoa(accountID)},
 			}, timeSensitiveDelivery)
 			if err != nil {
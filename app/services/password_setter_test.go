@@ -1,15 +1,18 @@
 package services_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/hibp"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPasswordSetter(t *testing.T) {
@@ -20,7 +23,7 @@ func TestPasswordSetter(t *testing.T) {
 	}
 
 	invoke := func(id int, password string) error {
-		return services.PasswordSetter(accountStore, &ops.LogReporter{logrus.New()}, cfg, id, password)
+		return services.PasswordSetter(context.Background(), accountStore, nil, &ops.LogReporter{logrus.New()}, cfg, id, password)
 	}
 
 	account, err := accountStore.Create("existing@keratin.tech", []byte("old"))
@@ -48,4 +51,30 @@ func TestPasswordSetter(t *testing.T) {
 		err := invoke(account.ID, "abc")
 		assert.Equal(t, services.FieldErrors{{"password", "INSECURE"}}, err)
 	})
+
+	t.Run("password from username", func(t *testing.T) {
+		strictCfg := *cfg
+		strictCfg.PasswordMinComplexity = 3
+		err := services.PasswordSetter(context.Background(), accountStore, nil, &ops.LogReporter{logrus.New()}, &strictCfg, account.ID, "existing2024!")
+		assert.Equal(t, services.FieldErrors{{"password", "INSECURE"}}, err)
+	})
+
+	t.Run("normalizes password", func(t *testing.T) {
+		err := invoke(account.ID, "cafe\u0301-0a0b0c0d")
+		require.NoError(t, err)
+
+		after, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword(after.Password, []byte("caf\u00e9-0a0b0c0d")))
+	})
+
+	t.Run("breached password", func(t *testing.T) {
+		err := services.PasswordSetter(context.Background(), accountStore, hibp.TestChecker{"caf\u00e9-0a0b0c0d"}, &ops.LogReporter{logrus.New()}, cfg, account.ID, "cafe\u0301-0a0b0c0d")
+		assert.Equal(t, services.FieldErrors{{"password", "INSECURE"}}, err)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		err := invoke(0, "0a0b0c0d0e0f0")
+		assert.Equal(t, services.FieldErrors{{"account", "NOT_FOUND"}}, err)
+	})
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"net/url"
 	"strconv"
 
@@ -11,7 +12,7 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func PasswordlessTokenSender(cfg *app.Config, account *models.Account, logger logrus.FieldLogger) error {
+func PasswordlessTokenSender(ctx context.Context, cfg *app.Config, account *models.Account, logger logrus.FieldLogger) error {
 	if account == nil || account.Locked {
 		return nil
 	}
@@ -25,7 +26,7 @@ func PasswordlessTokenSender(cfg *app.Config, account *models.Account, logger lo
 		return errors.Wrap(err, "Sign")
 	}
 
-	err = WebhookSender(cfg.AppPasswordlessTokenURL, &url.Values{
+	err = WebhookSender(ctx, cfg.AppPasswordlessTokenURL, &url.Values{
 		"account_id": []string{strconv.Itoa(account.ID)},
 		"token":      []string{passwordlessStr},
 	}, timeSensitiveDelivery)
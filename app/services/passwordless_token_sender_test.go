@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -40,7 +41,7 @@ func TestPasswordlessTokenSender(t *testing.T) {
 			PasswordlessTokenSigningKey: []byte("passwordless"),
 			PasswordlessTokenTTL:        time.Minute,
 		}
-		return services.PasswordlessTokenSender(cfg, account, logrus.New())
+		return services.PasswordlessTokenSender(context.Background(), cfg, account, logrus.New())
 	}
 
 	t.Run("posting to remote app", func(t *testing.T) {
@@ -3,6 +3,7 @@ package services_test
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
@@ -21,6 +22,7 @@ func TestPasswordlessTokenVerifier(t *testing.T) {
 		BcryptCost:                  4,
 		PasswordMinComplexity:       1,
 		PasswordlessTokenSigningKey: []byte("reset-a-reno"),
+		JWTLeeway:                   time.Minute,
 	}
 
 	newToken := func(id int) string {
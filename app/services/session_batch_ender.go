@@ -1,10 +1,14 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
 )
 
-func SessionBatchEnder(store data.RefreshTokenStore, accountID int) error {
+func SessionBatchEnder(ctx context.Context, store data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int) error {
 	tokens, err := store.FindAll(accountID)
 	if err != nil {
 		return err
@@ -15,5 +19,8 @@ func SessionBatchEnder(store data.RefreshTokenStore, accountID int) error {
 			return err
 		}
 	}
+	if len(tokens) > 0 {
+		LogoutNotifier(ctx, cfg, keyStore, reporter, accountID, "")
+	}
 	return nil
 }
@@ -1,6 +1,9 @@
 package services_test
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -13,7 +16,7 @@ func TestSessionBatchEnder(t *testing.T) {
 
 	t.Run("revoking nothing", func(t *testing.T) {
 		id := 123
-		err := services.SessionBatchEnder(store, id)
+		err := services.SessionBatchEnder(context.Background(), store, nil, &app.Config{}, nil, id)
 		assert.NoError(t, err)
 	})
 
@@ -26,7 +29,7 @@ func TestSessionBatchEnder(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, found, 1)
 
-		err = services.SessionBatchEnder(store, id)
+		err = services.SessionBatchEnder(context.Background(), store, nil, &app.Config{}, nil, id)
 		assert.NoError(t, err)
 
 		found, err = store.FindAll(id)
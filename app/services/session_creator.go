@@ -1,54 +1,92 @@
 package services
 
 import (
+	"context"
+
 	"github.com/keratin/authn-server/app"
-	"github.com/keratin/authn-server/app/data"
-	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/app/models"
-	"github.com/keratin/authn-server/ops"
-	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/pkg/errors"
 )
 
-func SessionCreator(
-	accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
-	accountID int, audience *route.Domain, existingToken *models.RefreshToken,
-) (string, string, error) {
+// SessionParams describe the session that SessionCreator issues.
+type SessionParams struct {
+	AccountID int
+	// Audience is the application domain that the session is for
+	Audience *route.Domain
+	// ExistingToken is a session that the new session replaces, if any
+	ExistingToken *models.RefreshToken
+	Client        *sessions.Fingerprint
+	UserAgent     string
+	Remember      bool
+	Nonce         string
+}
+
+// SessionCreator issues a session and identity token for the account, using the App's stores and
+// the Config for the request. Traced store calls join the trace in ctx.
+func SessionCreator(ctx context.Context, app *app.App, cfg *app.Config, params SessionParams) (string, string, error) {
+	accountStore := app.AccountStoreFor(ctx)
+	tokenStore := app.RefreshTokenStoreFor(ctx)
+
 	var err error
-	err = SessionEnder(refreshTokenStore, existingToken)
+	err = SessionEnder(ctx, tokenStore, app.KeyStore, cfg, app.Reporter, params.ExistingToken)
 	if err != nil {
-		reporter.ReportError(errors.Wrap(err, "SessionEnder"))
+		app.Reporter.ReportError(errors.Wrap(err, "SessionEnder"))
 	}
 
 	// track actives
-	if actives != nil {
-		err = actives.Track(accountID)
+	if app.Actives != nil {
+		err = app.Actives.Track(params.AccountID)
 		if err != nil {
-			reporter.ReportError(errors.Wrap(err, "Track"))
+			app.Reporter.ReportError(errors.Wrap(err, "Track"))
 		}
 	}
 
 	// track last activity
-	_, err = accountStore.SetLastLogin(accountID)
+	_, err = accountStore.SetLastLogin(params.AccountID)
 	if err != nil {
-		reporter.ReportError(errors.Wrap(err, "SetLastLogin"))
+		app.Reporter.ReportError(errors.Wrap(err, "SetLastLogin"))
 	}
 
 	// create new session token
-	session, err := sessions.New(refreshTokenStore, cfg, accountID, audience.String())
+	session, err := sessions.New(tokenStore, cfg, params.AccountID, params.Audience.String())
 	if err != nil {
 		return "", "", errors.Wrap(err, "sessions.New")
 	}
+	session.Client = params.Client
+	session.Remember = params.Remember
+
+	// shorten the lifetime of sessions that will not be remembered
+	if !params.Remember && cfg.EphemeralRefreshTokenTTL > 0 {
+		err = tokenStore.TouchFor(models.RefreshToken(session.Subject), params.AccountID, cfg.EphemeralRefreshTokenTTL)
+		if err != nil {
+			return "", "", errors.Wrap(err, "TouchFor")
+		}
+	}
+
+	// describe the session for the account's list of sessions
+	err = tokenStore.SetUserAgent(models.RefreshToken(session.Subject), params.UserAgent)
+	if err != nil {
+		app.Reporter.ReportError(errors.Wrap(err, "SetUserAgent"))
+	}
+
+	// revoke the oldest sessions beyond the limit, including the new one
+	if cfg.MaxSessionsPerAccount > 0 {
+		err = SessionLimiter(ctx, tokenStore, app.KeyStore, cfg, app.Reporter, params.AccountID, cfg.MaxSessionsPerAccount)
+		if err != nil {
+			app.Reporter.ReportError(errors.Wrap(err, "SessionLimiter"))
+		}
+	}
 	sessionToken, err := session.Sign(cfg.SessionSigningKey)
 	if err != nil {
 		return "", "", errors.Wrap(err, "session.Sign")
 	}
 
 	// create new identity token
-	identityToken, err := identities.New(cfg, session, accountID, audience.String()).Sign(keyStore.Key())
+	identityToken, err := signIdentity(cfg, accountStore, app.KeyStore, app.AccessTokenStore, session, params.AccountID, params.Audience.String(), params.Nonce)
 	if err != nil {
-		return "", "", errors.Wrap(err, "identities.New")
+		return "", "", errors.Wrap(err, "signIdentity")
 	}
 
 	return sessionToken, identityToken, nil
@@ -1,18 +1,26 @@
 package services_test
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2/jwt"
 )
 
 func TestSessionCreator(t *testing.T) {
@@ -26,15 +34,19 @@ func TestSessionCreator(t *testing.T) {
 	accountStore := mock.NewAccountStore()
 	reporter := &ops.LogReporter{logrus.New()}
 
+	stores := &app.App{
+		AccountStore:      accountStore,
+		RefreshTokenStore: refreshStore,
+		KeyStore:          keyStore,
+		Reporter:          reporter,
+	}
+
 	audience := &route.Domain{"authn.example.com", "8080"}
 	account, err := accountStore.Create("existing", []byte("secret"))
 	require.NoError(t, err)
 
 	t.Run("tracks last login while generating tokens", func(t *testing.T) {
-		identityToken, refreshToken, err := services.SessionCreator(
-			accountStore, refreshStore, keyStore, nil, cfg, reporter,
-			account.ID, audience, nil,
-		)
+		identityToken, refreshToken, err := services.SessionCreator(context.Background(), stores, cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
 		assert.NoError(t, err)
 		assert.NotEmpty(t, identityToken)
 		assert.NotEmpty(t, refreshToken)
@@ -46,10 +58,14 @@ func TestSessionCreator(t *testing.T) {
 
 	t.Run("tracks actives", func(t *testing.T) {
 		activesStore := mock.NewActives()
-		_, _, err := services.SessionCreator(
-			accountStore, refreshStore, keyStore, activesStore, cfg, reporter,
-			account.ID, audience, nil,
-		)
+		stores := &app.App{
+			AccountStore:      accountStore,
+			RefreshTokenStore: refreshStore,
+			KeyStore:          keyStore,
+			Actives:           activesStore,
+			Reporter:          reporter,
+		}
+		_, _, err := services.SessionCreator(context.Background(), stores, cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
 
 		report, err := activesStore.ActivesByDay()
 		require.NoError(t, err)
@@ -60,14 +76,89 @@ func TestSessionCreator(t *testing.T) {
 		token, err := refreshStore.Create(account.ID)
 		require.NoError(t, err)
 
-		_, _, err = services.SessionCreator(
-			accountStore, refreshStore, keyStore, nil, cfg, reporter,
-			account.ID, audience, &token,
-		)
+		_, _, err = services.SessionCreator(context.Background(), stores, cfg, services.SessionParams{AccountID: account.ID, Audience: audience, ExistingToken: &token})
 		assert.NoError(t, err)
 
 		foundID, err := refreshStore.Find(token)
 		assert.Empty(t, foundID)
 		assert.NoError(t, err)
 	})
+	t.Run("includes app claims", func(t *testing.T) {
+		remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"plan": "pro"}`))
+		}))
+		defer remoteApp.Close()
+		claimsURL, err := url.Parse(remoteApp.URL)
+		require.NoError(t, err)
+		cfg := *cfg
+		cfg.AppClaimsURL = claimsURL
+
+		_, identityToken, err := services.SessionCreator(context.Background(), stores, &cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
+		require.NoError(t, err)
+
+		tok, err := jwt.ParseSigned(identityToken)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		require.NoError(t, tok.Claims(rsaKey.Public(), &claims))
+		assert.Equal(t, "pro", claims["plan"])
+
+		remoteApp.Close()
+		_, _, err = services.SessionCreator(context.Background(), stores, &cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
+		assert.Error(t, err)
+	})
+
+	t.Run("includes whitelisted metadata", func(t *testing.T) {
+		_, err := accountStore.SetMetadata(account.ID, map[string]interface{}{"tenant_id": "acme", "notes": "private"})
+		require.NoError(t, err)
+		defer accountStore.SetMetadata(account.ID, nil)
+		cfg := *cfg
+		cfg.IdentityMetadataClaims = []string{"tenant_id", "plan"}
+
+		_, identityToken, err := services.SessionCreator(context.Background(), stores, &cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
+		require.NoError(t, err)
+
+		tok, err := jwt.ParseSigned(identityToken)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		require.NoError(t, tok.Claims(rsaKey.Public(), &claims))
+		assert.Equal(t, "acme", claims["tenant_id"])
+		assert.NotContains(t, claims, "plan")
+		assert.NotContains(t, claims, "notes")
+	})
+
+	t.Run("issuing opaque access tokens", func(t *testing.T) {
+		cfg := *cfg
+		cfg.OpaqueAccessTokens = true
+		cfg.AccessTokenTTL = time.Hour
+		accessTokenStore := mock.NewAccessTokenStore()
+
+		stores := &app.App{
+			AccountStore:      accountStore,
+			RefreshTokenStore: refreshStore,
+			KeyStore:          keyStore,
+			AccessTokenStore:  accessTokenStore,
+			Reporter:          reporter,
+		}
+		_, accessToken, err := services.SessionCreator(context.Background(), stores, &cfg, services.SessionParams{AccountID: account.ID, Audience: audience})
+		require.NoError(t, err)
+		_, err = jwt.ParseSigned(accessToken)
+		assert.Error(t, err)
+
+		identityToken, err := accessTokenStore.Find(accessToken)
+		require.NoError(t, err)
+		claims, err := identities.Parse(identityToken, &cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, strconv.Itoa(account.ID), claims.Subject)
+	})
+
+	t.Run("remembering the session", func(t *testing.T) {
+		for _, remember := range []bool{true, false} {
+			sessionToken, _, err := services.SessionCreator(context.Background(), stores, cfg, services.SessionParams{AccountID: account.ID, Audience: audience, Remember: remember})
+			require.NoError(t, err)
+
+			claims, err := sessions.Parse(sessionToken, cfg)
+			require.NoError(t, err)
+			assert.Equal(t, remember, claims.Remember)
+		}
+	})
 }
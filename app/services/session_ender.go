@@ -1,16 +1,33 @@
 package services
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
 )
 
 func SessionEnder(
-	refreshTokenStore data.RefreshTokenStore,
+	ctx context.Context, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter,
 	existingToken *models.RefreshToken,
 ) (err error) {
-	if existingToken != nil {
-		return refreshTokenStore.Revoke(*existingToken)
+	if existingToken == nil {
+		return nil
+	}
+
+	accountID, err := refreshTokenStore.Find(*existingToken)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	err = refreshTokenStore.Revoke(*existingToken)
+	if err != nil {
+		return err
+	}
+	if accountID != 0 {
+		LogoutNotifier(ctx, cfg, keyStore, reporter, accountID, existingToken.ID())
 	}
 	return nil
 }
@@ -1,6 +1,9 @@
 package services_test
 
 import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/stretchr/testify/assert"
@@ -16,7 +19,7 @@ func TestSessionEnder(t *testing.T) {
 		token, err := refreshStore.Create(accountID)
 		require.NoError(t, err)
 
-		err = services.SessionEnder(refreshStore, &token)
+		err = services.SessionEnder(context.Background(), refreshStore, nil, &app.Config{}, nil, &token)
 		assert.NoError(t, err)
 
 		foundID, err := refreshStore.Find(token)
@@ -25,7 +28,7 @@ func TestSessionEnder(t *testing.T) {
 	})
 
 	t.Run("ignores missing token", func(t *testing.T) {
-		err := services.SessionEnder(refreshStore, nil)
+		err := services.SessionEnder(context.Background(), refreshStore, nil, &app.Config{}, nil, nil)
 		assert.NoError(t, err)
 	})
 }
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// SessionLimiter revokes the oldest sessions of an account beyond the maximum.
+func SessionLimiter(ctx context.Context, store data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int, max int) error {
+	tokens, err := store.FindAll(accountID)
+	if err != nil {
+		return errors.Wrap(err, "FindAll")
+	}
+	for len(tokens) > max {
+		err = store.Revoke(tokens[0])
+		if err != nil {
+			return errors.Wrap(err, "Revoke")
+		}
+		LogoutNotifier(ctx, cfg, keyStore, reporter, accountID, tokens[0].ID())
+		tokens = tokens[1:]
+	}
+	return nil
+}
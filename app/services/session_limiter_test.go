@@ -0,0 +1,41 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLimiter(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+	id := 123
+
+	var created []models.RefreshToken
+	for i := 0; i < 4; i++ {
+		token, err := store.Create(id)
+		require.NoError(t, err)
+		created = append(created, token)
+	}
+
+	t.Run("within limit", func(t *testing.T) {
+		err := services.SessionLimiter(context.Background(), store, nil, &app.Config{}, nil, id, 4)
+		require.NoError(t, err)
+		found, err := store.FindAll(id)
+		require.NoError(t, err)
+		assert.Len(t, found, 4)
+	})
+
+	t.Run("revoking the oldest", func(t *testing.T) {
+		err := services.SessionLimiter(context.Background(), store, nil, &app.Config{}, nil, id, 2)
+		require.NoError(t, err)
+		found, err := store.FindAll(id)
+		require.NoError(t, err)
+		assert.Equal(t, created[2:], found)
+	})
+}
@@ -6,14 +6,13 @@ import (
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/ops"
-	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/pkg/errors"
 )
 
 func SessionRefresher(
-	refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
-	session *sessions.Claims, accountID int, audience *route.Domain,
+	accountStore data.AccountStore, refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, accessTokenStore data.AccessTokenStore, actives data.Actives, cfg *app.Config, reporter ops.ErrorReporter,
+	session *sessions.Claims, accountID int, audience *route.Domain, nonce string,
 ) (string, error) {
 	// track actives
 	if actives != nil {
@@ -24,15 +23,20 @@ func SessionRefresher(
 	}
 
 	// extend refresh token expiration
-	err := refreshTokenStore.Touch(models.RefreshToken(session.Subject), accountID)
+	var err error
+	if !session.Remember && cfg.EphemeralRefreshTokenTTL > 0 {
+		err = refreshTokenStore.TouchFor(models.RefreshToken(session.Subject), accountID, cfg.EphemeralRefreshTokenTTL)
+	} else {
+		err = refreshTokenStore.Touch(models.RefreshToken(session.Subject), accountID)
+	}
 	if err != nil {
 		return "", errors.Wrap(err, "Touch")
 	}
 
 	// create new identity token
-	identityToken, err := identities.New(cfg, session, accountID, audience.String()).Sign(keyStore.Key())
+	identityToken, err := signIdentity(cfg, accountStore, keyStore, accessTokenStore, session, accountID, audience.String(), nonce)
 	if err != nil {
-		return "", errors.Wrap(err, "New")
+		return "", errors.Wrap(err, "signIdentity")
 	}
 
 	return identityToken, nil
@@ -3,11 +3,13 @@ package services_test
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
@@ -21,8 +23,10 @@ func TestSessionRefresher(t *testing.T) {
 	require.NoError(t, err)
 	keyStore := mock.NewKeyStore(rsaKey)
 	cfg := &app.Config{
-		AuthNURL: &url.URL{Scheme: "http", Host: "authn.example.com"},
+		AuthNURL:  &url.URL{Scheme: "http", Host: "authn.example.com"},
+		JWTLeeway: time.Minute,
 	}
+	accountStore := mock.NewAccountStore()
 	refreshStore := mock.NewRefreshTokenStore()
 	reporter := &ops.LogReporter{logrus.New()}
 
@@ -35,8 +39,8 @@ func TestSessionRefresher(t *testing.T) {
 		activesStore := mock.NewActives()
 
 		identityToken, err := services.SessionRefresher(
-			refreshStore, keyStore, activesStore, cfg, reporter,
-			session, accountID, audience,
+			accountStore, refreshStore, keyStore, nil, activesStore, cfg, reporter,
+			session, accountID, audience, "",
 		)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, identityToken)
@@ -48,10 +52,59 @@ func TestSessionRefresher(t *testing.T) {
 
 	t.Run("ignores actives when not configured", func(t *testing.T) {
 		identityToken, err := services.SessionRefresher(
-			refreshStore, keyStore, nil, cfg, reporter,
-			session, accountID, audience,
+			accountStore, refreshStore, keyStore, nil, nil, cfg, reporter,
+			session, accountID, audience, "",
 		)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, identityToken)
 	})
+
+	t.Run("echoes nonce", func(t *testing.T) {
+		identityToken, err := services.SessionRefresher(
+			accountStore, refreshStore, keyStore, nil, nil, cfg, reporter,
+			session, accountID, audience, "n-0S6_WzA2Mj",
+		)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+	})
+
+	t.Run("includes roles", func(t *testing.T) {
+		account, err := accountStore.Create("roles@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		_, err = accountStore.SetRoles(account.ID, []string{"admin"})
+		require.NoError(t, err)
+
+		identityToken, err := services.SessionRefresher(
+			accountStore, refreshStore, keyStore, nil, nil, cfg, reporter,
+			session, account.ID, audience, "",
+		)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, claims.Roles)
+	})
+
+	t.Run("includes email verification", func(t *testing.T) {
+		account, err := accountStore.Create("verified@keratin.tech", []byte("secret"))
+		require.NoError(t, err)
+		_, err = accountStore.SetVerified(account.ID)
+		require.NoError(t, err)
+
+		verifyingCfg := *cfg
+		verifyingCfg.AppEmailVerificationURL = &url.URL{Scheme: "https", Host: "app.example.com"}
+		identityToken, err := services.SessionRefresher(
+			accountStore, refreshStore, keyStore, nil, nil, &verifyingCfg, reporter,
+			session, account.ID, audience, "",
+		)
+		require.NoError(t, err)
+
+		claims, err := identities.Parse(identityToken, cfg, keyStore.Keys())
+		require.NoError(t, err)
+		require.NotNil(t, claims.EmailVerified)
+		assert.True(t, *claims.EmailVerified)
+	})
 }
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/ops"
+	"github.com/pkg/errors"
+)
+
+// SessionRevoker revokes one of the account's sessions by its ID, so that a session may be ended
+// from another device.
+func SessionRevoker(ctx context.Context, store data.RefreshTokenStore, keyStore data.KeyStore, cfg *app.Config, reporter ops.ErrorReporter, accountID int, sessionID string) error {
+	tokens, err := store.FindAll(accountID)
+	if err != nil {
+		return errors.Wrap(err, "FindAll")
+	}
+	for _, token := range tokens {
+		if token.ID() == sessionID {
+			err = store.Revoke(token)
+			if err != nil {
+				return errors.Wrap(err, "Revoke")
+			}
+			LogoutNotifier(ctx, cfg, keyStore, reporter, accountID, sessionID)
+			return nil
+		}
+	}
+
+	return FieldErrors{{"session", ErrNotFound}}
+}
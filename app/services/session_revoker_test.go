@@ -0,0 +1,44 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRevoker(t *testing.T) {
+	store := mock.NewRefreshTokenStore()
+
+	token, err := store.Create(123)
+	require.NoError(t, err)
+	other, err := store.Create(456)
+	require.NoError(t, err)
+
+	t.Run("unknown session", func(t *testing.T) {
+		err := services.SessionRevoker(context.Background(), store, nil, &app.Config{}, nil, 123, "unknown")
+		assert.Equal(t, services.FieldErrors{{"session", services.ErrNotFound}}, err)
+	})
+
+	t.Run("session of another account", func(t *testing.T) {
+		err := services.SessionRevoker(context.Background(), store, nil, &app.Config{}, nil, 123, other.ID())
+		assert.Equal(t, services.FieldErrors{{"session", services.ErrNotFound}}, err)
+
+		id, err := store.Find(other)
+		require.NoError(t, err)
+		assert.Equal(t, 456, id)
+	})
+
+	t.Run("session of account", func(t *testing.T) {
+		err := services.SessionRevoker(context.Background(), store, nil, &app.Config{}, nil, 123, token.ID())
+		require.NoError(t, err)
+
+		id, err := store.Find(token)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+	})
+}
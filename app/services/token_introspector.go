@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/pkg/errors"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// Introspection describes a token in the format of RFC 7662. Inactive tokens are described only by
+// Active.
+type Introspection struct {
+	Active    bool             `json:"active"`
+	TokenType string           `json:"token_type,omitempty"`
+	Subject   string           `json:"sub,omitempty"`
+	Audience  jwt.Audience     `json:"aud,omitempty"`
+	Issuer    string           `json:"iss,omitempty"`
+	IssuedAt  *jwt.NumericDate `json:"iat,omitempty"`
+	Expiry    *jwt.NumericDate `json:"exp,omitempty"`
+}
+
+// TokenIntrospector describes an access token (an identity token, or an opaque token if a store is
+// given) or a refresh token (a session token). An access token is not active once revoked with the
+// denylist, if one is given. A refresh token is active until the session is ended or expires. The
+// hint ("access_token" or "refresh_token") chooses which kind of token is tried first.
+func TokenIntrospector(
+	refreshTokenStore data.RefreshTokenStore, keyStore data.KeyStore, denylist data.TokenDenylist, accessTokenStore data.AccessTokenStore, cfg *app.Config,
+	token string, hint string,
+) (*Introspection, error) {
+	introspectors := []func() (*Introspection, error){
+		func() (*Introspection, error) { return introspectIdentity(keyStore, denylist, cfg, token) },
+		func() (*Introspection, error) {
+			return introspectOpaque(accessTokenStore, keyStore, denylist, cfg, token)
+		},
+		func() (*Introspection, error) { return introspectSession(refreshTokenStore, cfg, token) },
+	}
+	if hint == "refresh_token" {
+		introspectors = []func() (*Introspection, error){introspectors[2], introspectors[0], introspectors[1]}
+	}
+
+	for _, introspect := range introspectors {
+		found, err := introspect()
+		if err != nil {
+			return nil, err
+		}
+		if found != nil {
+			return found, nil
+		}
+	}
+	return &Introspection{Active: false}, nil
+}
+
+func introspectIdentity(keyStore data.KeyStore, denylist data.TokenDenylist, cfg *app.Config, token string) (*Introspection, error) {
+	identity, err := identities.Parse(token, cfg, keyStore.Keys())
+	if err != nil {
+		return nil, nil
+	}
+	if denylist != nil && identity.ID != "" {
+		denied, err := denylist.IsDenied(identity.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "IsDenied")
+		}
+		if denied {
+			return &Introspection{Active: false}, nil
+		}
+	}
+	return &Introspection{
+		Active:    true,
+		TokenType: "access_token",
+		Subject:   identity.Subject,
+		Audience:  identity.Audience,
+		Issuer:    identity.Issuer,
+		IssuedAt:  identity.IssuedAt,
+		Expiry:    identity.Expiry,
+	}, nil
+}
+
+func introspectOpaque(accessTokenStore data.AccessTokenStore, keyStore data.KeyStore, denylist data.TokenDenylist, cfg *app.Config, token string) (*Introspection, error) {
+	if accessTokenStore == nil {
+		return nil, nil
+	}
+	identityToken, err := accessTokenStore.Find(token)
+	if err != nil {
+		return nil, errors.Wrap(err, "Find")
+	}
+	if identityToken == "" {
+		return nil, nil
+	}
+	return introspectIdentity(keyStore, denylist, cfg, identityToken)
+}
+
+func introspectSession(refreshTokenStore data.RefreshTokenStore, cfg *app.Config, token string) (*Introspection, error) {
+	session, err := sessions.Parse(token, cfg)
+	if err != nil {
+		return nil, nil
+	}
+	accountID, err := refreshTokenStore.Find(models.RefreshToken(session.Subject))
+	if err != nil {
+		return nil, errors.Wrap(err, "Find")
+	}
+	if accountID == 0 {
+		return nil, nil
+	}
+	return &Introspection{
+		Active:    true,
+		TokenType: "refresh_token",
+		Subject:   strconv.Itoa(accountID),
+		Audience:  session.Audience,
+		Issuer:    session.Issuer,
+		IssuedAt:  session.IssuedAt,
+	}, nil
+}
@@ -0,0 +1,105 @@
+package services_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenIntrospector(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:          &url.URL{Scheme: "http", Host: "authn.example.com"},
+		SessionSigningKey: []byte("key-a-reno"),
+		AccessTokenTTL:    time.Hour,
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	keyStore := mock.NewKeyStore(key)
+	refreshStore := mock.NewRefreshTokenStore()
+
+	session, err := sessions.New(refreshStore, cfg, 42, "example.com")
+	require.NoError(t, err)
+	sessionToken, err := session.Sign(cfg.SessionSigningKey)
+	require.NoError(t, err)
+	identityToken, err := identities.New(cfg, session, 42, "example.com").Sign(key)
+	require.NoError(t, err)
+
+	t.Run("access token", func(t *testing.T) {
+		found, err := services.TokenIntrospector(refreshStore, keyStore, nil, nil, cfg, identityToken, "")
+		require.NoError(t, err)
+		assert.True(t, found.Active)
+		assert.Equal(t, "access_token", found.TokenType)
+		assert.Equal(t, "42", found.Subject)
+		assert.Equal(t, "example.com", found.Audience[0])
+		assert.NotNil(t, found.IssuedAt)
+		assert.NotNil(t, found.Expiry)
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		found, err := services.TokenIntrospector(refreshStore, keyStore, nil, nil, cfg, sessionToken, "refresh_token")
+		require.NoError(t, err)
+		assert.True(t, found.Active)
+		assert.Equal(t, "refresh_token", found.TokenType)
+		assert.Equal(t, "42", found.Subject)
+		assert.Nil(t, found.Expiry)
+	})
+
+	t.Run("access token with unknown key", func(t *testing.T) {
+		otherKey, err := private.GenerateKey(512)
+		require.NoError(t, err)
+		found, err := services.TokenIntrospector(refreshStore, mock.NewKeyStore(otherKey), nil, nil, cfg, identityToken, "")
+		require.NoError(t, err)
+		assert.Equal(t, &services.Introspection{Active: false}, found)
+	})
+
+	t.Run("revoked access token", func(t *testing.T) {
+		denylist := mock.NewTokenDenylist()
+		err := services.AccessTokenRevoker(denylist, keyStore, nil, cfg, identityToken, "")
+		require.NoError(t, err)
+		found, err := services.TokenIntrospector(refreshStore, keyStore, denylist, nil, cfg, identityToken, "")
+		require.NoError(t, err)
+		assert.Equal(t, &services.Introspection{Active: false}, found)
+	})
+
+	t.Run("revoked refresh token", func(t *testing.T) {
+		err := refreshStore.Revoke(models.RefreshToken(session.Subject))
+		require.NoError(t, err)
+		found, err := services.TokenIntrospector(refreshStore, keyStore, nil, nil, cfg, sessionToken, "")
+		require.NoError(t, err)
+		assert.False(t, found.Active)
+	})
+
+	t.Run("opaque access token", func(t *testing.T) {
+		accessTokenStore := mock.NewAccessTokenStore()
+		opaqueToken, err := accessTokenStore.Create(identityToken, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		found, err := services.TokenIntrospector(refreshStore, keyStore, nil, accessTokenStore, cfg, opaqueToken, "")
+		require.NoError(t, err)
+		assert.True(t, found.Active)
+		assert.Equal(t, "access_token", found.TokenType)
+		assert.Equal(t, "42", found.Subject)
+
+		err = services.AccessTokenRevoker(mock.NewTokenDenylist(), keyStore, accessTokenStore, cfg, opaqueToken, "")
+		require.NoError(t, err)
+		found, err = services.TokenIntrospector(refreshStore, keyStore, nil, accessTokenStore, cfg, opaqueToken, "")
+		require.NoError(t, err)
+		assert.False(t, found.Active)
+	})
+
+	t.Run("garbage", func(t *testing.T) {
+		found, err := services.TokenIntrospector(refreshStore, keyStore, nil, nil, cfg, "abc.def.ghi", "")
+		require.NoError(t, err)
+		assert.False(t, found.Active)
+	})
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// TOTPConfirmer enables the account's enrolled secret once a valid code proves that the
+// authenticator was set up.
+func TOTPConfirmer(store data.AccountStore, cfg *app.Config, accountID int, otp string) error {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	} else if account.TOTPSecret == nil {
+		return FieldErrors{{"totp", ErrNotFound}}
+	}
+
+	ok, err := validateTOTP(cfg, account.TOTPSecret, otp)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+
+	_, err = store.EnableTOTP(account.ID)
+	if err != nil {
+		return errors.Wrap(err, "EnableTOTP")
+	}
+	return nil
+}
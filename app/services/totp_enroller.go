@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/pkg/errors"
+)
+
+// TOTPEnroller generates a new authenticator secret for the account and stores it encrypted. The
+// secret is not required at login until it is confirmed with TOTPConfirmer. An enabled secret must
+// be reset before enrolling again, so that a stolen session can not replace it.
+func TOTPEnroller(store data.AccountStore, cfg *app.Config, accountID int, issuer string) (string, string, error) {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return "", "", FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return "", "", FieldErrors{{"account", ErrLocked}}
+	} else if account.TOTPEnabled() {
+		return "", "", FieldErrors{{"totp", ErrTaken}}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", errors.Wrap(err, "GenerateSecret")
+	}
+	encrypted, err := compat.Encrypt([]byte(secret), cfg.DBEncryptionKey)
+	if err != nil {
+		return "", "", errors.Wrap(err, "Encrypt")
+	}
+	_, err = store.SetTOTPSecret(account.ID, encrypted)
+	if err != nil {
+		return "", "", errors.Wrap(err, "SetTOTPSecret")
+	}
+
+	return secret, totp.URL(issuer, account.Username, secret), nil
+}
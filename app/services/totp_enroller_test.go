@@ -0,0 +1,87 @@
+package services_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTPEnrollment(t *testing.T) {
+	accountStore := mock.NewAccountStore()
+	cfg := &app.Config{DBEncryptionKey: []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB")}
+
+	account, err := accountStore.Create("totp@keratin.tech", []byte("secret"))
+	require.NoError(t, err)
+
+	t.Run("confirming before enrolling", func(t *testing.T) {
+		err := services.TOTPConfirmer(accountStore, cfg, account.ID, "123456")
+		assert.Equal(t, services.FieldErrors{{"totp", services.ErrNotFound}}, err)
+	})
+
+	secret, url, err := services.TOTPEnroller(accountStore, cfg, account.ID, "example.com")
+	require.NoError(t, err)
+	assert.NotEmpty(t, secret)
+	assert.Contains(t, url, "otpauth://totp/example.com:totp@keratin.tech")
+
+	t.Run("stores the secret encrypted", func(t *testing.T) {
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, found.TOTPSecret)
+		assert.NotContains(t, string(found.TOTPSecret), secret)
+		assert.False(t, found.TOTPEnabled())
+	})
+
+	t.Run("confirming with an invalid code", func(t *testing.T) {
+		err := services.TOTPConfirmer(accountStore, cfg, account.ID, "000000")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+	})
+
+	t.Run("confirming with a valid code", func(t *testing.T) {
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		err = services.TOTPConfirmer(accountStore, cfg, account.ID, code)
+		require.NoError(t, err)
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.TOTPEnabled())
+	})
+
+	t.Run("enrolling again", func(t *testing.T) {
+		_, _, err := services.TOTPEnroller(accountStore, cfg, account.ID, "example.com")
+		assert.Equal(t, services.FieldErrors{{"totp", services.ErrTaken}}, err)
+	})
+
+	t.Run("verifying at login", func(t *testing.T) {
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+
+		err = services.TOTPVerifier(cfg, found, "")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrMissing}}, err)
+		err = services.TOTPVerifier(cfg, found, "000000")
+		assert.Equal(t, services.FieldErrors{{"otp", services.ErrInvalidOrExpired}}, err)
+
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		assert.NoError(t, services.TOTPVerifier(cfg, found, code))
+	})
+
+	t.Run("resetting", func(t *testing.T) {
+		err := services.TOTPResetter(accountStore, account.ID)
+		require.NoError(t, err)
+
+		found, err := accountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.TOTPEnabled())
+		assert.NoError(t, services.TOTPVerifier(cfg, found, ""))
+
+		err = services.TOTPResetter(accountStore, 9999)
+		assert.Equal(t, services.FieldErrors{{"account", services.ErrNotFound}}, err)
+	})
+}
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// TOTPResetter removes the account's authenticator, so that it may log in without a code and
+// enroll again.
+func TOTPResetter(store data.AccountStore, accountID int) error {
+	affected, err := store.DeleteTOTP(accountID)
+	if err != nil {
+		return errors.Wrap(err, "DeleteTOTP")
+	}
+	if !affected {
+		return FieldErrors{{"account", ErrNotFound}}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/compat"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/pkg/errors"
+)
+
+// TOTPVerifier requires a valid code at login for accounts that have enabled an authenticator.
+func TOTPVerifier(cfg *app.Config, account *models.Account, otp string) error {
+	if !account.TOTPEnabled() {
+		return nil
+	}
+	if otp == "" {
+		return FieldErrors{{"otp", ErrMissing}}
+	}
+
+	ok, err := validateTOTP(cfg, account.TOTPSecret, otp)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return FieldErrors{{"otp", ErrInvalidOrExpired}}
+	}
+	return nil
+}
+
+func validateTOTP(cfg *app.Config, encryptedSecret []byte, otp string) (bool, error) {
+	secret, err := compat.Decrypt(encryptedSecret, cfg.DBEncryptionKey)
+	if err != nil {
+		return false, errors.Wrap(err, "Decrypt")
+	}
+	return totp.Validate(otp, secret, time.Now()), nil
+}
@@ -0,0 +1,23 @@
+package services
+
+import (
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
+	"github.com/pkg/errors"
+)
+
+// UsernameChanger renames an account on behalf of its user. Unlike AccountUpdater, it refuses
+// archived and locked accounts.
+func UsernameChanger(store data.AccountStore, cfg *app.Config, accountID int, username string) error {
+	account, err := store.Find(accountID)
+	if err != nil {
+		return errors.Wrap(err, "Find")
+	}
+	if account == nil || account.Archived() {
+		return FieldErrors{{"account", ErrNotFound}}
+	} else if account.Locked {
+		return FieldErrors{{"account", ErrLocked}}
+	}
+
+	return AccountUpdater(store, cfg, accountID, username)
+}
@@ -0,0 +1,52 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsernameChanger(t *testing.T) {
+	store := mock.NewAccountStore()
+	cfg := &app.Config{UsernameMinLength: 3}
+
+	account, err := store.Create("original", []byte("secret"))
+	require.NoError(t, err)
+	_, err = store.Create("taken", []byte("secret"))
+	require.NoError(t, err)
+	locked, err := store.Create("locked", []byte("secret"))
+	require.NoError(t, err)
+	store.Lock(locked.ID)
+
+	t.Run("with a new username", func(t *testing.T) {
+		err := services.UsernameChanger(store, cfg, account.ID, " renamed ")
+		require.NoError(t, err)
+
+		found, err := store.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", found.Username)
+	})
+
+	t.Run("failures", func(t *testing.T) {
+		testCases := []struct {
+			accountID int
+			username  string
+			errors    services.FieldErrors
+		}{
+			{account.ID, "", services.FieldErrors{{"username", services.ErrMissing}}},
+			{account.ID, "ab", services.FieldErrors{{"username", services.ErrFormatInvalid}}},
+			{account.ID, "taken", services.FieldErrors{{"username", services.ErrTaken}}},
+			{locked.ID, "unlocked", services.FieldErrors{{"account", services.ErrLocked}}},
+			{0, "unknown", services.FieldErrors{{"account", services.ErrNotFound}}},
+		}
+
+		for _, tc := range testCases {
+			err := services.UsernameChanger(store, cfg, tc.accountID, tc.username)
+			assert.Equal(t, tc.errors, err, tc.username)
+		}
+	})
+}
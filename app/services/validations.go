@@ -6,6 +6,7 @@ import (
 
 	"github.com/keratin/authn-server/app"
 	"github.com/trustelem/zxcvbn"
+	"golang.org/x/text/unicode/norm"
 )
 
 var ErrMissing = "MISSING"
@@ -17,6 +18,9 @@ var ErrLocked = "LOCKED"
 var ErrExpired = "EXPIRED"
 var ErrNotFound = "NOT_FOUND"
 var ErrInvalidOrExpired = "INVALID_OR_EXPIRED"
+var ErrThrottled = "THROTTLED"
+var ErrMaintenance = "MAINTENANCE"
+var ErrInProgress = "IN_PROGRESS"
 
 type FieldError struct {
 	Field   string `json:"field"`
@@ -41,10 +45,38 @@ func (es FieldErrors) Error() string {
 	return strings.Join(buf, ", ")
 }
 
-func PasswordValidator(cfg *app.Config, password string) *FieldError {
+// bcrypt ignores any bytes after the first 72
+const bcryptMaxLength = 72
+
+// normalizePassword applies NFKC normalization, so that a password typed on one device matches the
+// same password typed on another with different code points (e.g. composed and decomposed accents).
+func normalizePassword(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// passwordFormatValidator rejects a normalized password that is too long for PASSWORD_MAX_LENGTH
+// or has a null byte, which some bcrypt implementations treat as the end of the password.
+func passwordFormatValidator(cfg *app.Config, password string) *FieldError {
+	maxLength := cfg.PasswordMaxLength
+	if maxLength == 0 || maxLength > bcryptMaxLength {
+		maxLength = bcryptMaxLength
+	}
+	if len(password) > maxLength || strings.ContainsRune(password, 0) {
+		return &FieldError{"password", ErrFormatInvalid}
+	}
+	return nil
+}
+
+// PasswordValidator scores a password with zxcvbn. The userInputs are words that an attacker would
+// guess first, like the username (see passwordUserInputs). The password should already be
+// normalized.
+func PasswordValidator(cfg *app.Config, password string, userInputs ...string) *FieldError {
 	if password == "" {
 		return &FieldError{"password", ErrMissing}
 	}
+	if fieldError := passwordFormatValidator(cfg, password); fieldError != nil {
+		return fieldError
+	}
 
 	// SECURITY: only score the first 100 characters of a password. cheap benchmarks on my current
 	//           laptop show that latency for 1e3 characters approaches 180ms, and 1e4 characters
@@ -53,7 +85,7 @@ func PasswordValidator(cfg *app.Config, password string) *FieldError {
 		password = password[:100]
 	}
 
-	strength := zxcvbn.PasswordStrength(password, []string{})
+	strength := zxcvbn.PasswordStrength(password, userInputs)
 	if strength.Score < cfg.PasswordMinComplexity {
 		return &FieldError{"password", ErrInsecure}
 	}
@@ -61,7 +93,36 @@ func PasswordValidator(cfg *app.Config, password string) *FieldError {
 	return nil
 }
 
+// passwordUserInputs returns the username and, for an email, the parts of its local part and
+// domain, so that passwords like "jane.doe2024!" are scored as guessable for jane.doe@example.com.
+func passwordUserInputs(username string) []string {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if username == "" {
+		return nil
+	}
+
+	inputs := []string{username}
+	if at := strings.LastIndex(username, "@"); at > 0 {
+		local, domain := username[:at], username[at+1:]
+		inputs = append(inputs, local, domain)
+		inputs = append(inputs, strings.FieldsFunc(local, isUsernameSeparator)...)
+		labels := strings.Split(domain, ".")
+		if len(labels) > 1 {
+			// the top-level domain is too short to be a useful word
+			inputs = append(inputs, labels[:len(labels)-1]...)
+		}
+	}
+	return inputs
+}
+
+func isUsernameSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-' || r == '+'
+}
+
 func UsernameValidator(cfg *app.Config, username string) *FieldError {
+	if cfg.UsernameMaxLength > 0 && len(username) > cfg.UsernameMaxLength {
+		return &FieldError{"username", ErrFormatInvalid}
+	}
 	if cfg.UsernameIsEmail {
 		if !isEmail(username) {
 			return &FieldError{"username", ErrFormatInvalid}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/keratin/authn-server/app"
@@ -47,4 +48,35 @@ func TestUsernameValidator(t *testing.T) {
 		})
 
 	})
+
+	t.Run("length", func(t *testing.T) {
+		cfg := &app.Config{UsernameMinLength: 3, UsernameMaxLength: 8}
+
+		assert.Nil(t, services.UsernameValidator(cfg, "abc"))
+		assert.Nil(t, services.UsernameValidator(cfg, "abcdefgh"))
+		assert.Equal(t, &services.FieldError{"username", services.ErrFormatInvalid}, services.UsernameValidator(cfg, "ab"))
+		assert.Equal(t, &services.FieldError{"username", services.ErrFormatInvalid}, services.UsernameValidator(cfg, "abcdefghi"))
+
+		cfg = &app.Config{UsernameIsEmail: true, UsernameMaxLength: 12}
+		assert.Nil(t, services.UsernameValidator(cfg, "foo@bar.tld"))
+		assert.NotNil(t, services.UsernameValidator(cfg, "foobar@bar.tld"))
+	})
+}
+
+func TestPasswordValidator(t *testing.T) {
+	cfg := &app.Config{PasswordMinComplexity: 3}
+
+	assert.Nil(t, services.PasswordValidator(cfg, "jane.doe2024!"))
+	assert.Equal(t, &services.FieldError{"password", services.ErrInsecure}, services.PasswordValidator(cfg, "jane.doe2024!", "jane.doe@example.com", "jane.doe"))
+	assert.Equal(t, &services.FieldError{"password", services.ErrMissing}, services.PasswordValidator(cfg, ""))
+
+	t.Run("format", func(t *testing.T) {
+		cfg := &app.Config{PasswordMaxLength: 20}
+
+		assert.Nil(t, services.PasswordValidator(cfg, strings.Repeat("0a", 10)))
+		assert.Equal(t, &services.FieldError{"password", services.ErrFormatInvalid}, services.PasswordValidator(cfg, strings.Repeat("0a", 11)))
+		assert.Equal(t, &services.FieldError{"password", services.ErrFormatInvalid}, services.PasswordValidator(cfg, "0a0b0c\x000d0e0f"))
+		// bcrypt's limit applies without PASSWORD_MAX_LENGTH
+		assert.Equal(t, &services.FieldError{"password", services.ErrFormatInvalid}, services.PasswordValidator(&app.Config{}, strings.Repeat("0a", 37)))
+	})
 }
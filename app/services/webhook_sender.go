@@ -1,14 +1,22 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// webhookClient traces deliveries and propagates the trace context to the app
+var webhookClient = &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
 var timeSensitiveDelivery = []time.Duration{
 	time.Duration(1) * time.Second,
 	time.Duration(3) * time.Second,
@@ -33,13 +41,30 @@ func retry(schedule []time.Duration, fn func() error) error {
 	return err
 }
 
-func WebhookSender(destination *url.URL, values *url.Values, schedule []time.Duration) error {
+func WebhookSender(ctx context.Context, destination *url.URL, values *url.Values, schedule []time.Duration) error {
+	return requestWebhookSender(ctx, destination, values, schedule, "")
+}
+
+// requestWebhookSender is a WebhookSender that forwards the ID of the request that caused it, when
+// known.
+func requestWebhookSender(ctx context.Context, destination *url.URL, values *url.Values, schedule []time.Duration, requestID string) error {
 	if destination == nil {
 		return fmt.Errorf("URL unconfigured")
 	}
+	// deliveries join the request's trace but may outlive it, so they must not share its cancellation
+	ctx = trace.ContextWithSpanContext(context.Background(), trace.SpanContextFromContext(ctx))
 
 	err := retry(schedule, func() error {
-		res, err := http.PostForm(destination.String(), *values)
+		req, err := http.NewRequestWithContext(ctx, "POST", destination.String(), strings.NewReader(values.Encode()))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if requestID != "" {
+			req.Header.Set(ops.RequestIDHeader, requestID)
+		}
+
+		res, err := webhookClient.Do(req)
 		if err == nil && res.StatusCode > 299 {
 			return fmt.Errorf("Status Code: %v", res.StatusCode)
 		}
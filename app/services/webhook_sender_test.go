@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -38,26 +39,26 @@ func TestWebhookSender(t *testing.T) {
 	failureURL := &url.URL{Scheme: "http", Host: serverURL.Host, Path: "/failure", User: url.UserPassword("user", "pass")}
 
 	t.Run("posting to remote app", func(t *testing.T) {
-		err := services.WebhookSender(successURL, &url.Values{}, noRetry)
+		err := services.WebhookSender(context.Background(), successURL, &url.Values{}, noRetry)
 		assert.NoError(t, err)
 	})
 
 	t.Run("without auth", func(t *testing.T) {
-		err := services.WebhookSender(unauthedURL, &url.Values{}, noRetry)
+		err := services.WebhookSender(context.Background(), unauthedURL, &url.Values{}, noRetry)
 		if assert.Error(t, err) {
 			assert.Equal(t, "PostForm: Status Code: 401", err.Error())
 		}
 	})
 
 	t.Run("without configured url", func(t *testing.T) {
-		err := services.WebhookSender(nil, &url.Values{}, noRetry)
+		err := services.WebhookSender(context.Background(), nil, &url.Values{}, noRetry)
 		if assert.Error(t, err) {
 			assert.Equal(t, "URL unconfigured", err.Error())
 		}
 	})
 
 	t.Run("with remote app failure", func(t *testing.T) {
-		err := services.WebhookSender(failureURL, &url.Values{}, noRetry)
+		err := services.WebhookSender(context.Background(), failureURL, &url.Values{}, noRetry)
 		if assert.Error(t, err) {
 			assert.Equal(t, "PostForm: Status Code: 500", err.Error())
 		}
@@ -77,6 +78,20 @@ func TestWebhookSenderRetries(t *testing.T) {
 	serverURL, err := url.Parse(remoteApp.URL)
 	require.NoError(t, err)
 
-	err = services.WebhookSender(serverURL, &url.Values{}, fastRetry)
+	err = services.WebhookSender(context.Background(), serverURL, &url.Values{}, fastRetry)
+	assert.NoError(t, err)
+}
+
+func TestWebhookSenderOutlivesRequest(t *testing.T) {
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+	serverURL, err := url.Parse(remoteApp.URL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = services.WebhookSender(ctx, serverURL, &url.Values{}, noRetry)
 	assert.NoError(t, err)
 }
@@ -0,0 +1,80 @@
+package handoffs
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+const scope = "handoff"
+
+// TTL is short, since a handoff token is redeemed immediately by a redirect to the other domain.
+const TTL = time.Minute
+
+// SecondFactorTTL leaves time to read a code from an authenticator or a text message, for a
+// handoff that finishes a login to an account with a second factor.
+const SecondFactorTTL = 5 * time.Minute
+
+type Claims struct {
+	Scope string `json:"scope"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+// Parse verifies a handoff token that may be redeemed by the audience domain.
+func Parse(tokenStr string, cfg *app.Config, audience string) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.HandoffTokenSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
+		Audience: jwt.Audience{audience},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	}, cfg.JWTLeeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != scope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+
+	return &claims, nil
+}
+
+// New creates a handoff token for the account that may be redeemed by the audience domain until
+// the ttl passes.
+func New(cfg *app.Config, accountID int, audience string, ttl time.Duration) (*Claims, error) {
+	return &Claims{
+		Scope: scope,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  strconv.Itoa(accountID),
+			Audience: jwt.Audience{audience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}, nil
+}
@@ -0,0 +1,55 @@
+package handoffs_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoffToken(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:               &url.URL{Scheme: "https", Host: "authn.example.com"},
+		HandoffTokenSigningKey: []byte("key-a-reno"),
+	}
+
+	accountID := 52167
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := handoffs.New(cfg, accountID, "app2.example.com", handoffs.TTL)
+		require.NoError(t, err)
+		assert.Equal(t, "handoff", token.Scope)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "52167", token.Subject)
+		assert.True(t, token.Audience.Contains("app2.example.com"))
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.IssuedAt)
+
+		tokenStr, err := token.Sign(cfg.HandoffTokenSigningKey)
+		require.NoError(t, err)
+
+		_, err = handoffs.Parse(tokenStr, cfg, "app2.example.com")
+		require.NoError(t, err)
+	})
+
+	t.Run("parsing for a different audience", func(t *testing.T) {
+		token, err := handoffs.New(cfg, accountID, "app2.example.com", handoffs.TTL)
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.HandoffTokenSigningKey)
+		require.NoError(t, err)
+		_, err = handoffs.Parse(tokenStr, cfg, "app3.example.com")
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		token, err := handoffs.New(cfg, accountID, "app2.example.com", handoffs.TTL)
+		require.NoError(t, err)
+		tokenStr, err := token.Sign([]byte("old-a-reno"))
+		require.NoError(t, err)
+		_, err = handoffs.Parse(tokenStr, cfg, "app2.example.com")
+		assert.Error(t, err)
+	})
+}
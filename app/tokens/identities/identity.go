@@ -1,13 +1,17 @@
 package identities
 
 import (
+	"encoding/hex"
 	"strconv"
 	"time"
 
 	"github.com/keratin/authn-server/app/data/private"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/pkg/errors"
 	"gopkg.in/square/go-jose.v2"
 	"gopkg.in/square/go-jose.v2/jwt"
@@ -15,34 +19,117 @@ import (
 
 type Claims struct {
 	AuthTime *jwt.NumericDate `json:"auth_time"`
+	// Nonce is echoed from the request, so that clients may bind the token to it (as in OIDC)
+	Nonce string `json:"nonce,omitempty"`
+	// SessionID identifies the session that issued the token, as in back-channel logout tokens
+	SessionID string `json:"sid,omitempty"`
+	// Roles are the account's roles, if any
+	Roles []string `json:"roles,omitempty"`
+	// EmailVerified is set when APP_EMAIL_VERIFICATION_URL is configured
+	EmailVerified *bool `json:"email_verified,omitempty"`
+	// ImpersonatedBy is copied from an impersonated session
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	jwt.Claims
+	// Extra claims from IDENTITY_CLAIMS and APP_CLAIMS_URL. They may not replace standard claims.
+	Extra map[string]interface{} `json:"-"`
 }
 
 func (c *Claims) Sign(key *private.Key) (string, error) {
-	jwk := jose.JSONWebKey{
-		Key:   key.PrivateKey,
-		KeyID: key.JWK.KeyID,
+	signer, err := jose.NewSigner(
+		key.SigningKey(),
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
 	}
+	return jwt.Signed(signer).Claims(c.Extra).Claims(c).CompactSerialize()
+}
 
+// SignAndEncrypt signs the claims like Sign, and nests the signed token in a JWE for the recipient's
+// public key.
+func (c *Claims) SignAndEncrypt(key *private.Key, recipient *jose.JSONWebKey) (string, error) {
 	signer, err := jose.NewSigner(
-		jose.SigningKey{Algorithm: jose.RS256, Key: jwk},
+		key.SigningKey(),
 		(&jose.SignerOptions{}).WithType("JWT"),
 	)
 	if err != nil {
 		return "", errors.Wrap(err, "NewSigner")
 	}
-	return jwt.Signed(signer).Claims(c).CompactSerialize()
+	encrypter, err := jose.NewEncrypter(
+		jose.A256GCM,
+		jose.Recipient{Algorithm: jose.KeyAlgorithm(recipient.Algorithm), Key: recipient},
+		(&jose.EncrypterOptions{}).WithType("JWT").WithContentType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewEncrypter")
+	}
+	return jwt.SignedAndEncrypted(signer, encrypter).Claims(c.Extra).Claims(c).CompactSerialize()
+}
+
+// Parse verifies an identity token with any of the given keys, as published in the JWKS. Tokens for
+// any audience are accepted.
+func Parse(tokenStr string, cfg *app.Config, keys []*private.Key) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	var key *private.Key
+	for _, k := range keys {
+		if k.JWK.KeyID == token.Headers[0].KeyID {
+			key = k
+		}
+	}
+	if key == nil {
+		return nil, errors.New("unknown key")
+	}
+	if token.Headers[0].Algorithm != string(key.Algorithm()) {
+		return nil, errors.New("unexpected algorithm")
+	}
+
+	claims := Claims{}
+	err = token.Claims(key.Public(), &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
+		Issuer: cfg.AuthNURL.String(),
+		Time:   time.Now(),
+	}, cfg.JWTLeeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+
+	return &claims, nil
 }
 
+// New creates identity claims for the audience domain, applying any audience or TTL overrides
+// configured for that domain. Tokens do not outlive a session with an expiry.
 func New(cfg *app.Config, session *sessions.Claims, accountID int, audience string) *Claims {
+	domain := route.ParseDomain(audience)
+	settings := cfg.SettingsFor(&domain)
+	jti, _ := lib.GenerateToken()
+	extra := make(map[string]interface{}, len(cfg.IdentityClaims))
+	for k, v := range cfg.IdentityClaims {
+		extra[k] = v
+	}
+	expiry := time.Now().Add(settings.AccessTokenTTL)
+	if session.Expiry != nil && session.Expiry.Time().Before(expiry) {
+		expiry = session.Expiry.Time()
+	}
 	return &Claims{
-		AuthTime: session.IssuedAt,
+		AuthTime:       session.IssuedAt,
+		SessionID:      models.RefreshToken(session.Subject).ID(),
+		ImpersonatedBy: session.ImpersonatedBy,
 		Claims: jwt.Claims{
 			Issuer:   session.Issuer,
 			Subject:  strconv.Itoa(accountID),
-			Audience: jwt.Audience{audience},
-			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
+			Audience: jwt.Audience{settings.Audience},
+			Expiry:   jwt.NewNumericDate(expiry),
 			IssuedAt: jwt.NewNumericDate(time.Now()),
+			ID:       hex.EncodeToString(jti),
 		},
+		Extra: extra,
 	}
 }
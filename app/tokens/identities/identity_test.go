@@ -1,17 +1,26 @@
 package identities_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app/data/private"
 
+	"golang.org/x/crypto/ed25519"
 	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/identities"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,6 +36,25 @@ func TestIdentityClaims(t *testing.T) {
 	session, err := sessions.New(store, &cfg, 1, "example.com")
 	require.NoError(t, err)
 
+	t.Run("includes session ID", func(t *testing.T) {
+		identity := identities.New(&cfg, session, 1, "example.com")
+		assert.NotEmpty(t, identity.SessionID)
+		assert.Equal(t, models.RefreshToken(session.Subject).ID(), identity.SessionID)
+	})
+
+	t.Run("from an impersonated session", func(t *testing.T) {
+		impersonated, err := sessions.New(store, &cfg, 1, "example.com")
+		require.NoError(t, err)
+		impersonated.ImpersonatedBy = "support@example.com"
+		impersonated.Expiry = jwt.NewNumericDate(time.Now().Add(time.Minute))
+
+		longer := cfg
+		longer.AccessTokenTTL = time.Hour
+		identity := identities.New(&longer, impersonated, 1, "example.com")
+		assert.Equal(t, "support@example.com", identity.ImpersonatedBy)
+		assert.Equal(t, impersonated.Expiry, identity.Expiry)
+	})
+
 	t.Run("includes KID", func(t *testing.T) {
 		identity := identities.New(&cfg, session, 1, "example.com")
 		identityStr, err := identity.Sign(key)
@@ -38,4 +66,114 @@ func TestIdentityClaims(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, key.JWK.KeyID, parsed.Signatures[0].Header.KeyID)
 	})
+
+	t.Run("encrypts for the recipient", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		rsaKey, err := private.GenerateKey(1024)
+		require.NoError(t, err)
+
+		for alg, decryptionKey := range map[jose.KeyAlgorithm]crypto.Signer{
+			jose.ECDH_ES_A256KW: ecKey,
+			jose.RSA_OAEP_256:   rsaKey.PrivateKey,
+		} {
+			recipient := &jose.JSONWebKey{Key: decryptionKey.Public(), Algorithm: string(alg), Use: "enc", KeyID: "app-key"}
+			identityStr, err := identities.New(&cfg, session, 1, "example.com").SignAndEncrypt(key, recipient)
+			require.NoError(t, err)
+
+			nested, err := jwt.ParseSignedAndEncrypted(identityStr)
+			require.NoError(t, err)
+			assert.Equal(t, "app-key", nested.Headers[0].KeyID)
+			tok, err := nested.Decrypt(decryptionKey)
+			require.NoError(t, err)
+			assert.Equal(t, key.JWK.KeyID, tok.Headers[0].KeyID)
+
+			claims := jwt.Claims{}
+			require.NoError(t, tok.Claims(key.Public(), &claims))
+			assert.Equal(t, "1", claims.Subject)
+		}
+	})
+
+	t.Run("signs with ECDSA and Ed25519 keys", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		_, edKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		for _, signer := range []crypto.Signer{ecKey, edKey} {
+			key, err := private.NewKey(signer)
+			require.NoError(t, err)
+
+			identityStr, err := identities.New(&cfg, session, 1, "example.com").Sign(key)
+			require.NoError(t, err)
+
+			tok, err := jwt.ParseSigned(identityStr)
+			require.NoError(t, err)
+			assert.Equal(t, key.JWK.Algorithm, tok.Headers[0].Algorithm)
+
+			claims := jwt.Claims{}
+			require.NoError(t, tok.Claims(key.Public(), &claims))
+			assert.Equal(t, "1", claims.Subject)
+		}
+	})
+
+	t.Run("signs with opaque signers", func(t *testing.T) {
+		rsaKey, err := private.GenerateKey(512)
+		require.NoError(t, err)
+		ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		for _, signer := range []crypto.Signer{rsaKey.PrivateKey, ecKey} {
+			// hides the concrete type, as with a KMS
+			key, err := private.NewKey(struct{ crypto.Signer }{signer})
+			require.NoError(t, err)
+
+			identityStr, err := identities.New(&cfg, session, 1, "example.com").Sign(key)
+			require.NoError(t, err)
+
+			tok, err := jwt.ParseSigned(identityStr)
+			require.NoError(t, err)
+			assert.Equal(t, key.JWK.Algorithm, tok.Headers[0].Algorithm)
+			assert.Equal(t, key.JWK.KeyID, tok.Headers[0].KeyID)
+
+			claims := jwt.Claims{}
+			require.NoError(t, tok.Claims(key.Public(), &claims))
+			assert.Equal(t, "1", claims.Subject)
+		}
+	})
+
+	t.Run("applies domain settings", func(t *testing.T) {
+		cfg := cfg
+		cfg.AccessTokenTTL = time.Hour
+		cfg.DomainSettings = []app.DomainSettings{
+			{Domain: route.Domain{Hostname: "admin.example.com"}, Audience: "admin", AccessTokenTTL: time.Minute},
+		}
+
+		identity := identities.New(&cfg, session, 1, "admin.example.com")
+		assert.Equal(t, jwt.Audience{"admin"}, identity.Audience)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), identity.Expiry.Time(), time.Second)
+
+		identity = identities.New(&cfg, session, 1, "example.com")
+		assert.Equal(t, jwt.Audience{"example.com"}, identity.Audience)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), identity.Expiry.Time(), time.Second)
+	})
+	t.Run("includes extra claims", func(t *testing.T) {
+		cfg := cfg
+		cfg.IdentityClaims = map[string]interface{}{"tenant": "acme"}
+
+		identity := identities.New(&cfg, session, 1, "example.com")
+		identity.Extra["roles"] = []string{"admin"}
+		identity.Extra["sub"] = "2"
+		identityStr, err := identity.Sign(key)
+		require.NoError(t, err)
+
+		tok, err := jwt.ParseSigned(identityStr)
+		require.NoError(t, err)
+		claims := map[string]interface{}{}
+		require.NoError(t, tok.Claims(key.Public(), &claims))
+		assert.Equal(t, "acme", claims["tenant"])
+		assert.Equal(t, []interface{}{"admin"}, claims["roles"])
+		assert.Equal(t, "1", claims["sub"])
+		assert.Empty(t, cfg.IdentityClaims["roles"])
+	})
 }
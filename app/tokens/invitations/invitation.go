@@ -0,0 +1,81 @@
+package invitations
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+const scope = "invitation"
+
+type Claims struct {
+	Scope string `json:"scope"`
+	// Email is the only username that may sign up with the invitation, when set
+	Email string `json:"email,omitempty"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+// Parse verifies a token from New.
+func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.InvitationSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
+		Audience: jwt.Audience{cfg.AuthNURL.String()},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	}, cfg.JWTLeeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != scope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+
+	return &claims, nil
+}
+
+// New creates an invitation to sign up, optionally for a single email.
+func New(cfg *app.Config, email string) (*Claims, error) {
+	jti, err := lib.GenerateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateToken")
+	}
+
+	return &Claims{
+		Scope: scope,
+		Email: email,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Audience: jwt.Audience{cfg.AuthNURL.String()},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.InvitationTokenTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			ID:       hex.EncodeToString(jti),
+		},
+	}, nil
+}
@@ -0,0 +1,59 @@
+package invitations_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitationToken(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:             &url.URL{Scheme: "https", Host: "authn.example.com"},
+		InvitationSigningKey: []byte("key-a-reno"),
+		InvitationTokenTTL:   time.Hour,
+	}
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := invitations.New(cfg, "invited@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "invitation", token.Scope)
+		assert.Equal(t, "invited@example.com", token.Email)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.True(t, token.Audience.Contains("https://authn.example.com"))
+		assert.NotEmpty(t, token.ID)
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.IssuedAt)
+
+		tokenStr, err := token.Sign(cfg.InvitationSigningKey)
+		require.NoError(t, err)
+
+		parsed, err := invitations.Parse(tokenStr, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "invited@example.com", parsed.Email)
+	})
+
+	t.Run("parsing an expired token", func(t *testing.T) {
+		expired := *cfg
+		expired.InvitationTokenTTL = -time.Hour
+		token, err := invitations.New(&expired, "")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.InvitationSigningKey)
+		require.NoError(t, err)
+		_, err = invitations.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		token, err := invitations.New(cfg, "")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign([]byte("old-a-reno"))
+		require.NoError(t, err)
+		_, err = invitations.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,88 @@
+package logouts
+
+import (
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib"
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+// Event identifies a logout token, as described by OpenID Connect Back-Channel Logout.
+const Event = "http://schemas.openid.net/event/backchannel-logout"
+
+// TTL is short, since a logout token is delivered immediately to each back-channel endpoint.
+const TTL = 2 * time.Minute
+
+type Claims struct {
+	Events map[string]struct{} `json:"events"`
+	// SessionID matches the sid claim of the session's identity tokens. When empty, all of the
+	// account's sessions have ended.
+	SessionID string `json:"sid,omitempty"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(key *private.Key) (string, error) {
+	signer, err := jose.NewSigner(
+		key.SigningKey(),
+		(&jose.SignerOptions{}).WithType("logout+jwt"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+// Parse verifies a logout token with the given key.
+func Parse(tokenStr string, cfg *app.Config, key *private.Key) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(key.Public(), &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
+		Issuer: cfg.AuthNURL.String(),
+		Time:   time.Now(),
+	}, cfg.JWTLeeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if _, ok := claims.Events[Event]; !ok {
+		return nil, errors.New("not a logout token")
+	}
+
+	return &claims, nil
+}
+
+// New creates a logout token for one of the account's sessions, or for all of them when sessionID
+// is empty.
+func New(cfg *app.Config, accountID int, sessionID string, audience string) (*Claims, error) {
+	jti, err := lib.GenerateToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "GenerateToken")
+	}
+
+	return &Claims{
+		Events:    map[string]struct{}{Event: {}},
+		SessionID: sessionID,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  strconv.Itoa(accountID),
+			Audience: jwt.Audience{audience},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(TTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			ID:       hex.EncodeToString(jti),
+		},
+	}, nil
+}
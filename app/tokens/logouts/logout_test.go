@@ -0,0 +1,69 @@
+package logouts_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/app/tokens/logouts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+)
+
+func TestLogoutToken(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL: &url.URL{Scheme: "https", Host: "authn.example.com"},
+	}
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := logouts.New(cfg, 52167, "abc123", "app.example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "52167", token.Subject)
+		assert.Equal(t, "abc123", token.SessionID)
+		assert.True(t, token.Audience.Contains("app.example.com"))
+		assert.NotEmpty(t, token.IssuedAt)
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.ID)
+		assert.Contains(t, token.Events, logouts.Event)
+
+		tokenStr, err := token.Sign(key)
+		require.NoError(t, err)
+
+		parsed, err := jose.ParseSigned(tokenStr)
+		require.NoError(t, err)
+		assert.Equal(t, "logout+jwt", parsed.Signatures[0].Header.ExtraHeaders["typ"])
+		assert.Equal(t, key.JWK.KeyID, parsed.Signatures[0].Header.KeyID)
+
+		claims, err := logouts.Parse(tokenStr, cfg, key)
+		require.NoError(t, err)
+		assert.Equal(t, "abc123", claims.SessionID)
+	})
+
+	t.Run("for all sessions", func(t *testing.T) {
+		token, err := logouts.New(cfg, 52167, "", "app.example.com")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(key)
+		require.NoError(t, err)
+
+		claims, err := logouts.Parse(tokenStr, cfg, key)
+		require.NoError(t, err)
+		assert.Empty(t, claims.SessionID)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		other, err := private.GenerateKey(512)
+		require.NoError(t, err)
+		token, err := logouts.New(cfg, 52167, "", "app.example.com")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(other)
+		require.NoError(t, err)
+
+		_, err = logouts.Parse(tokenStr, cfg, key)
+		assert.Error(t, err)
+	})
+}
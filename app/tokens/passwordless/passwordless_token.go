@@ -41,11 +41,11 @@ func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
 		return nil, errors.Wrap(err, "Claims")
 	}
 
-	err = claims.Claims.Validate(jwt.Expected{
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
 		Audience: jwt.Audience{cfg.AuthNURL.String()},
 		Issuer:   cfg.AuthNURL.String(),
 		Time:     time.Now(),
-	})
+	}, cfg.JWTLeeway)
 	if err != nil {
 		return nil, errors.Wrap(err, "Validate")
 	}
@@ -3,6 +3,7 @@ package passwordless_test
 import (
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/tokens/passwordless"
@@ -15,6 +16,7 @@ func TestPasswordlessToken(t *testing.T) {
 		AuthNURL:                    &url.URL{Scheme: "https", Host: "authn.example.com"},
 		PasswordlessTokenSigningKey: []byte("key-a-reno"),
 		PasswordlessTokenTTL:        3600,
+		JWTLeeway:                   time.Minute,
 	}
 
 	accountID := 52167
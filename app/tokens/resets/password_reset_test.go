@@ -18,6 +18,7 @@ func TestPasswordResetToken(t *testing.T) {
 		AuthNURL:        &url.URL{Scheme: "https", Host: "authn.example.com"},
 		ResetSigningKey: []byte("key-a-reno"),
 		RefreshTokenTTL: 3600,
+		JWTLeeway:       time.Minute,
 	}
 
 	then := time.Now().Add(time.Duration(-1) * time.Second).Truncate(time.Second) // 1 second ago
@@ -69,6 +70,22 @@ func TestPasswordResetToken(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("parsing with clock skew", func(t *testing.T) {
+		token, err := resets.New(cfg, accountID, then)
+		require.NoError(t, err)
+		token.Expiry = jwt.NewNumericDate(time.Now().Add(-30 * time.Second))
+		tokenStr, err := token.Sign(cfg.ResetSigningKey)
+		require.NoError(t, err)
+
+		_, err = resets.Parse(tokenStr, cfg)
+		assert.NoError(t, err)
+
+		strictCfg := *cfg
+		strictCfg.JWTLeeway = 0
+		_, err = resets.Parse(tokenStr, &strictCfg)
+		assert.Error(t, err)
+	})
+
 	t.Run("checking lock expiration", func(t *testing.T) {
 		claims := resets.Claims{Lock: jwt.NewNumericDate(then)}
 		assert.False(t, claims.LockExpired(then))
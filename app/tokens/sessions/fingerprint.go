@@ -0,0 +1,46 @@
+package sessions
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+)
+
+// Fingerprint describes the client that created a session, so that a stolen session is harder to
+// refresh from elsewhere. Values are hashed, since the session cookie is readable by the client.
+type Fingerprint struct {
+	UserAgent string `json:"uah,omitempty"`
+	IPPrefix  string `json:"iph,omitempty"`
+}
+
+// NewFingerprint hashes the user agent, and the network prefix of the IP when given (a /24 for
+// IPv4 or a /48 for IPv6), so that clients moving within a network still match.
+func NewFingerprint(userAgent string, ip net.IP) *Fingerprint {
+	f := &Fingerprint{UserAgent: fingerprintHash([]byte(userAgent))}
+	if ip4 := ip.To4(); ip4 != nil {
+		f.IPPrefix = fingerprintHash(ip4.Mask(net.CIDRMask(24, 32)))
+	} else if ip != nil {
+		f.IPPrefix = fingerprintHash(ip.Mask(net.CIDRMask(48, 128)))
+	}
+	return f
+}
+
+// Matches compares the values that were recorded in both fingerprints. Sessions created without a
+// fingerprint, or before the binding was made stricter, are not rejected for missing values.
+func (f *Fingerprint) Matches(other *Fingerprint) bool {
+	if f == nil || other == nil {
+		return true
+	}
+	if f.UserAgent != "" && other.UserAgent != "" && f.UserAgent != other.UserAgent {
+		return false
+	}
+	if f.IPPrefix != "" && other.IPPrefix != "" && f.IPPrefix != other.IPPrefix {
+		return false
+	}
+	return true
+}
+
+func fingerprintHash(b []byte) string {
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:16])
+}
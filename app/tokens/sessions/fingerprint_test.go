@@ -0,0 +1,37 @@
+package sessions_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprint(t *testing.T) {
+	browser := "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)"
+
+	testCases := []struct {
+		recorded *sessions.Fingerprint
+		current  *sessions.Fingerprint
+		matches  bool
+	}{
+		{sessions.NewFingerprint(browser, net.ParseIP("192.0.2.1")), sessions.NewFingerprint(browser, net.ParseIP("192.0.2.200")), true},
+		{sessions.NewFingerprint(browser, net.ParseIP("192.0.2.1")), sessions.NewFingerprint(browser, net.ParseIP("198.51.100.1")), false},
+		{sessions.NewFingerprint(browser, net.ParseIP("2001:db8:1::1")), sessions.NewFingerprint(browser, net.ParseIP("2001:db8:1:ffff::1")), true},
+		{sessions.NewFingerprint(browser, net.ParseIP("2001:db8:1::1")), sessions.NewFingerprint(browser, net.ParseIP("2001:db8:2::1")), false},
+		{sessions.NewFingerprint(browser, nil), sessions.NewFingerprint("curl/7.64.1", nil), false},
+		// bindings that were loosened or tightened
+		{sessions.NewFingerprint(browser, net.ParseIP("192.0.2.1")), sessions.NewFingerprint(browser, nil), true},
+		{sessions.NewFingerprint(browser, nil), sessions.NewFingerprint(browser, net.ParseIP("192.0.2.1")), true},
+		// sessions without binding
+		{nil, sessions.NewFingerprint(browser, nil), true},
+		{sessions.NewFingerprint(browser, nil), nil, true},
+	}
+	for idx, tc := range testCases {
+		assert.Equal(t, tc.matches, tc.recorded.Matches(tc.current), idx)
+	}
+
+	f := sessions.NewFingerprint(browser, net.ParseIP("192.0.2.1"))
+	assert.NotContains(t, f.UserAgent, "Mozilla")
+}
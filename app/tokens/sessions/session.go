@@ -14,8 +14,13 @@ import (
 const scope = "refresh"
 
 type Claims struct {
-	Scope string `json:"scope"`
-	Azp   string `json:"azp"`
+	Scope  string       `json:"scope"`
+	Azp    string       `json:"azp"`
+	Client *Fingerprint `json:"cli,omitempty"`
+	// Remember is set when the session is kept in a persistent cookie
+	Remember bool `json:"rem,omitempty"`
+	// ImpersonatedBy names the support staff who were issued the session for someone else's account
+	ImpersonatedBy string `json:"impersonated_by,omitempty"`
 	jwt.Claims
 }
 
@@ -42,10 +47,11 @@ func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
 		return nil, errors.Wrap(err, "Claims")
 	}
 
-	err = claims.Claims.Validate(jwt.Expected{
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
 		Audience: jwt.Audience{cfg.AuthNURL.String()},
 		Issuer:   cfg.AuthNURL.String(),
-	})
+		Time:     time.Now(),
+	}, cfg.JWTLeeway)
 	if err != nil {
 		return nil, errors.Wrap(err, "Validate")
 	}
@@ -0,0 +1,97 @@
+package verifications
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	jwt "gopkg.in/square/go-jose.v2/jwt"
+)
+
+const scope = "verification"
+const changeScope = "email_change"
+
+type Claims struct {
+	Scope string `json:"scope"`
+	// Email is the username being verified, so that a token expires when the username changes
+	Email string `json:"email"`
+	// Username is the current username when changing to Email, so that only one change may succeed
+	Username string `json:"username,omitempty"`
+	jwt.Claims
+}
+
+func (c *Claims) Sign(hmacKey []byte) (string, error) {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.HS256, Key: hmacKey},
+		(&jose.SignerOptions{}).WithType("JWT"),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "NewSigner")
+	}
+	return jwt.Signed(signer).Claims(c).CompactSerialize()
+}
+
+// Parse verifies a token from New.
+func Parse(tokenStr string, cfg *app.Config) (*Claims, error) {
+	return parse(tokenStr, cfg, scope)
+}
+
+// ParseChange verifies a token from NewChange.
+func ParseChange(tokenStr string, cfg *app.Config) (*Claims, error) {
+	return parse(tokenStr, cfg, changeScope)
+}
+
+func parse(tokenStr string, cfg *app.Config, expectedScope string) (*Claims, error) {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	claims := Claims{}
+	err = token.Claims(cfg.EmailVerificationSigningKey, &claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+
+	err = claims.Claims.ValidateWithLeeway(jwt.Expected{
+		Audience: jwt.Audience{cfg.AuthNURL.String()},
+		Issuer:   cfg.AuthNURL.String(),
+		Time:     time.Now(),
+	}, cfg.JWTLeeway)
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+	if claims.Scope != expectedScope {
+		return nil, fmt.Errorf("token scope not valid")
+	}
+
+	return &claims, nil
+}
+
+func New(cfg *app.Config, accountID int, email string) (*Claims, error) {
+	return &Claims{
+		Scope: scope,
+		Email: email,
+		Claims: jwt.Claims{
+			Issuer:   cfg.AuthNURL.String(),
+			Subject:  strconv.Itoa(accountID),
+			Audience: jwt.Audience{cfg.AuthNURL.String()},
+			Expiry:   jwt.NewNumericDate(time.Now().Add(cfg.EmailVerificationTokenTTL)),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+		},
+	}, nil
+}
+
+// NewChange creates a token that confirms control of a new email before it replaces the username.
+func NewChange(cfg *app.Config, accountID int, username string, email string) (*Claims, error) {
+	claims, err := New(cfg, accountID, email)
+	if err != nil {
+		return nil, err
+	}
+	claims.Scope = changeScope
+	claims.Username = username
+	return claims, nil
+}
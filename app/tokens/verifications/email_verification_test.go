@@ -0,0 +1,79 @@
+package verifications_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmailVerificationToken(t *testing.T) {
+	cfg := &app.Config{
+		AuthNURL:                    &url.URL{Scheme: "https", Host: "authn.example.com"},
+		EmailVerificationSigningKey: []byte("key-a-reno"),
+		EmailVerificationTokenTTL:   time.Hour,
+		JWTLeeway:                   time.Minute,
+	}
+
+	accountID := 52167
+
+	t.Run("creating signing and parsing", func(t *testing.T) {
+		token, err := verifications.New(cfg, accountID, "user@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "verification", token.Scope)
+		assert.Equal(t, "user@example.com", token.Email)
+		assert.Equal(t, "https://authn.example.com", token.Issuer)
+		assert.Equal(t, "52167", token.Subject)
+		assert.True(t, token.Audience.Contains("https://authn.example.com"))
+		assert.NotEmpty(t, token.Expiry)
+		assert.NotEmpty(t, token.IssuedAt)
+
+		tokenStr, err := token.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+
+		claims, err := verifications.Parse(tokenStr, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "user@example.com", claims.Email)
+	})
+
+	t.Run("parsing with a different key", func(t *testing.T) {
+		token, err := verifications.New(cfg, accountID, "user@example.com")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign([]byte("old-a-reno"))
+		require.NoError(t, err)
+		_, err = verifications.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("parsing an expired token", func(t *testing.T) {
+		expiredCfg := *cfg
+		expiredCfg.EmailVerificationTokenTTL = -time.Hour
+		token, err := verifications.New(&expiredCfg, accountID, "user@example.com")
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+		_, err = verifications.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("creating and parsing a change", func(t *testing.T) {
+		token, err := verifications.NewChange(cfg, accountID, "old@example.com", "new@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "email_change", token.Scope)
+		tokenStr, err := token.Sign(cfg.EmailVerificationSigningKey)
+		require.NoError(t, err)
+
+		claims, err := verifications.ParseChange(tokenStr, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, "old@example.com", claims.Username)
+		assert.Equal(t, "new@example.com", claims.Email)
+
+		// scopes are not interchangeable
+		_, err = verifications.Parse(tokenStr, cfg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,311 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: lib/authnpb/authn.proto
+
+// The AuthN service exposes private endpoints over gRPC for backend services. Connections require a
+// client certificate signed by GRPC_CLIENT_CA. Regenerate the Go package with `make proto`.
+
+package authnpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AuthN_GetAccount_FullMethodName      = "/keratin.authn.v1.AuthN/GetAccount"
+	AuthN_LockAccount_FullMethodName     = "/keratin.authn.v1.AuthN/LockAccount"
+	AuthN_UnlockAccount_FullMethodName   = "/keratin.authn.v1.AuthN/UnlockAccount"
+	AuthN_ArchiveAccount_FullMethodName  = "/keratin.authn.v1.AuthN/ArchiveAccount"
+	AuthN_RevokeSession_FullMethodName   = "/keratin.authn.v1.AuthN/RevokeSession"
+	AuthN_IntrospectToken_FullMethodName = "/keratin.authn.v1.AuthN/IntrospectToken"
+)
+
+// AuthNClient is the client API for AuthN service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AuthNClient interface {
+	// GetAccount returns an account, including archived accounts. See GET /accounts/:id.
+	GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error)
+	// LockAccount locks an account and revokes its sessions. See PATCH /accounts/:id/lock.
+	LockAccount(ctx context.Context, in *LockAccountRequest, opts ...grpc.CallOption) (*LockAccountResponse, error)
+	// UnlockAccount unlocks an account. See PATCH /accounts/:id/unlock.
+	UnlockAccount(ctx context.Context, in *UnlockAccountRequest, opts ...grpc.CallOption) (*UnlockAccountResponse, error)
+	// ArchiveAccount wipes an account's personal data and revokes its sessions. See
+	// DELETE /accounts/:id.
+	ArchiveAccount(ctx context.Context, in *ArchiveAccountRequest, opts ...grpc.CallOption) (*ArchiveAccountResponse, error)
+	// RevokeSession ends one of an account's sessions. See DELETE /sessions/:id.
+	RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error)
+	// IntrospectToken describes an access token or refresh token. See POST /introspect.
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*Introspection, error)
+}
+
+type authNClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuthNClient(cc grpc.ClientConnInterface) AuthNClient {
+	return &authNClient{cc}
+}
+
+func (c *authNClient) GetAccount(ctx context.Context, in *GetAccountRequest, opts ...grpc.CallOption) (*Account, error) {
+	out := new(Account)
+	err := c.cc.Invoke(ctx, AuthN_GetAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authNClient) LockAccount(ctx context.Context, in *LockAccountRequest, opts ...grpc.CallOption) (*LockAccountResponse, error) {
+	out := new(LockAccountResponse)
+	err := c.cc.Invoke(ctx, AuthN_LockAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authNClient) UnlockAccount(ctx context.Context, in *UnlockAccountRequest, opts ...grpc.CallOption) (*UnlockAccountResponse, error) {
+	out := new(UnlockAccountResponse)
+	err := c.cc.Invoke(ctx, AuthN_UnlockAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authNClient) ArchiveAccount(ctx context.Context, in *ArchiveAccountRequest, opts ...grpc.CallOption) (*ArchiveAccountResponse, error) {
+	out := new(ArchiveAccountResponse)
+	err := c.cc.Invoke(ctx, AuthN_ArchiveAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authNClient) RevokeSession(ctx context.Context, in *RevokeSessionRequest, opts ...grpc.CallOption) (*RevokeSessionResponse, error) {
+	out := new(RevokeSessionResponse)
+	err := c.cc.Invoke(ctx, AuthN_RevokeSession_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authNClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*Introspection, error) {
+	out := new(Introspection)
+	err := c.cc.Invoke(ctx, AuthN_IntrospectToken_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthNServer is the server API for AuthN service.
+// All implementations must embed UnimplementedAuthNServer
+// for forward compatibility
+type AuthNServer interface {
+	// GetAccount returns an account, including archived accounts. See GET /accounts/:id.
+	GetAccount(context.Context, *GetAccountRequest) (*Account, error)
+	// LockAccount locks an account and revokes its sessions. See PATCH /accounts/:id/lock.
+	LockAccount(context.Context, *LockAccountRequest) (*LockAccountResponse, error)
+	// UnlockAccount unlocks an account. See PATCH /accounts/:id/unlock.
+	UnlockAccount(context.Context, *UnlockAccountRequest) (*UnlockAccountResponse, error)
+	// ArchiveAccount wipes an account's personal data and revokes its sessions. See
+	// DELETE /accounts/:id.
+	ArchiveAccount(context.Context, *ArchiveAccountRequest) (*ArchiveAccountResponse, error)
+	// RevokeSession ends one of an account's sessions. See DELETE /sessions/:id.
+	RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error)
+	// IntrospectToken describes an access token or refresh token. See POST /introspect.
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*Introspection, error)
+	mustEmbedUnimplementedAuthNServer()
+}
+
+// UnimplementedAuthNServer must be embedded to have forward compatible implementations.
+type UnimplementedAuthNServer struct {
+}
+
+func (UnimplementedAuthNServer) GetAccount(context.Context, *GetAccountRequest) (*Account, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAccount not implemented")
+}
+func (UnimplementedAuthNServer) LockAccount(context.Context, *LockAccountRequest) (*LockAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LockAccount not implemented")
+}
+func (UnimplementedAuthNServer) UnlockAccount(context.Context, *UnlockAccountRequest) (*UnlockAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnlockAccount not implemented")
+}
+func (UnimplementedAuthNServer) ArchiveAccount(context.Context, *ArchiveAccountRequest) (*ArchiveAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ArchiveAccount not implemented")
+}
+func (UnimplementedAuthNServer) RevokeSession(context.Context, *RevokeSessionRequest) (*RevokeSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSession not implemented")
+}
+func (UnimplementedAuthNServer) IntrospectToken(context.Context, *IntrospectTokenRequest) (*Introspection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IntrospectToken not implemented")
+}
+func (UnimplementedAuthNServer) mustEmbedUnimplementedAuthNServer() {}
+
+// UnsafeAuthNServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuthNServer will
+// result in compilation errors.
+type UnsafeAuthNServer interface {
+	mustEmbedUnimplementedAuthNServer()
+}
+
+func RegisterAuthNServer(s grpc.ServiceRegistrar, srv AuthNServer) {
+	s.RegisterService(&AuthN_ServiceDesc, srv)
+}
+
+func _AuthN_GetAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).GetAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_GetAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).GetAccount(ctx, req.(*GetAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthN_LockAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LockAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).LockAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_LockAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).LockAccount(ctx, req.(*LockAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthN_UnlockAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnlockAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).UnlockAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_UnlockAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).UnlockAccount(ctx, req.(*UnlockAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthN_ArchiveAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArchiveAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).ArchiveAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_ArchiveAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).ArchiveAccount(ctx, req.(*ArchiveAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthN_RevokeSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).RevokeSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_RevokeSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).RevokeSession(ctx, req.(*RevokeSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthN_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthNServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthN_IntrospectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthNServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuthN_ServiceDesc is the grpc.ServiceDesc for AuthN service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuthN_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keratin.authn.v1.AuthN",
+	HandlerType: (*AuthNServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAccount",
+			Handler:    _AuthN_GetAccount_Handler,
+		},
+		{
+			MethodName: "LockAccount",
+			Handler:    _AuthN_LockAccount_Handler,
+		},
+		{
+			MethodName: "UnlockAccount",
+			Handler:    _AuthN_UnlockAccount_Handler,
+		},
+		{
+			MethodName: "ArchiveAccount",
+			Handler:    _AuthN_ArchiveAccount_Handler,
+		},
+		{
+			MethodName: "RevokeSession",
+			Handler:    _AuthN_RevokeSession_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _AuthN_IntrospectToken_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "lib/authnpb/authn.proto",
+}
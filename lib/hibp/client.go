@@ -0,0 +1,117 @@
+// Package hibp checks passwords against the Have I Been Pwned range API. Only the first five
+// characters of a password's SHA-1 hash are sent, so the service never learns the password.
+package hibp
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultURL is the public Pwned Passwords range API.
+const DefaultURL = "https://api.pwnedpasswords.com/range/"
+
+// ErrUnavailable is returned without making a request while the circuit breaker is open.
+var ErrUnavailable = errors.New("pwned passwords unavailable")
+
+// Checker reports whether a password has been found in a breach.
+type Checker interface {
+	Breached(password string) (bool, error)
+}
+
+// Client is a Checker for the range API. After maxFailures consecutive failures, it stops making
+// requests for the cooldown, so that an outage does not slow down every signup.
+type Client struct {
+	URL         string
+	client      *http.Client
+	maxFailures int
+	cooldown    time.Duration
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// NewClient returns a Client for the range API at the URL, which ends with the path that a hash
+// prefix is appended to.
+func NewClient(url string) *Client {
+	return &Client{
+		URL:         url,
+		client:      &http.Client{Timeout: 2 * time.Second},
+		maxFailures: 3,
+		cooldown:    time.Minute,
+	}
+}
+
+// Breached returns true when the password appears in the range API's results.
+func (c *Client) Breached(password string) (bool, error) {
+	if !c.closed() {
+		return false, ErrUnavailable
+	}
+
+	breached, err := c.query(password)
+	c.record(err)
+	return breached, err
+}
+
+func (c *Client) query(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequest("GET", c.URL+prefix, nil)
+	if err != nil {
+		return false, errors.Wrap(err, "NewRequest")
+	}
+	// padding hides the number of results for the prefix from observers
+	req.Header.Set("Add-Padding", "true")
+	res, err := c.client.Do(req)
+	if err != nil {
+		return false, errors.Wrap(err, "Get")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("Get: Status Code: %v", res.StatusCode)
+	}
+
+	// each line is a hash suffix and a count, as in "0018A45C4D1DEF81644B54AB7F969B88D65:21"
+	scanner := bufio.NewScanner(res.Body)
+	for scanner.Scan() {
+		parts := strings.SplitN(strings.TrimSpace(scanner.Text()), ":", 2)
+		// padding entries have a count of 0
+		if len(parts) == 2 && parts[0] == suffix && parts[1] != "0" {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, errors.Wrap(err, "Scan")
+	}
+	return false, nil
+}
+
+func (c *Client) closed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().After(c.openUntil)
+}
+
+func (c *Client) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		c.failures = 0
+		return
+	}
+	c.failures++
+	if c.failures >= c.maxFailures {
+		c.failures = 0
+		c.openUntil = time.Now().Add(c.cooldown)
+	}
+}
@@ -0,0 +1,55 @@
+package hibp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/hibp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	var requested []string
+	down := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		if down {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		// SHA-1 of "password" is 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+		w.Write([]byte("1E4C9B93F3F0682250B6CF8331B7EE68FD8:3861493\r\n" +
+			"011053FD0102E94D6AE2F8B83D76FAF94F6:0\r\n"))
+	}))
+	defer server.Close()
+	client := hibp.NewClient(server.URL + "/range/")
+
+	t.Run("breached password", func(t *testing.T) {
+		breached, err := client.Breached("password")
+		require.NoError(t, err)
+		assert.True(t, breached)
+		assert.Equal(t, "/range/5BAA6", requested[len(requested)-1])
+	})
+
+	t.Run("unknown password", func(t *testing.T) {
+		breached, err := client.Breached("correct horse battery staple")
+		require.NoError(t, err)
+		assert.False(t, breached)
+	})
+
+	t.Run("circuit breaker", func(t *testing.T) {
+		down = true
+		for i := 0; i < 3; i++ {
+			_, err := client.Breached("password")
+			assert.Error(t, err)
+			assert.NotEqual(t, hibp.ErrUnavailable, err)
+		}
+
+		count := len(requested)
+		_, err := client.Breached("password")
+		assert.Equal(t, hibp.ErrUnavailable, err)
+		assert.Equal(t, count, len(requested))
+	})
+}
@@ -0,0 +1,14 @@
+package hibp
+
+// TestChecker is a fake breach list for tests.
+type TestChecker []string
+
+// Breached returns true when the password is in the list.
+func (c TestChecker) Breached(password string) (bool, error) {
+	for _, p := range c {
+		if p == password {
+			return true, nil
+		}
+	}
+	return false, nil
+}
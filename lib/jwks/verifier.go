@@ -0,0 +1,180 @@
+// Package jwks helps services that consume AuthN tokens to verify them with the keys that AuthN
+// publishes, without fetching keys for every token.
+package jwks
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// DefaultRefreshInterval limits how often a token with an unknown kid may trigger a refetch.
+const DefaultRefreshInterval = time.Minute
+
+// ErrUnknownKey is returned for tokens that were not signed by a published (and pinned) key.
+var ErrUnknownKey = errors.New("unknown key")
+
+// Verifier verifies tokens with keys fetched from a JWKS URL, like AuthN's /jwks. Keys are cached
+// by kid and refetched when a token names a kid that has not been seen, so that keys are picked up
+// after rotation.
+//
+// AuthN key IDs are the RFC 7638 SHA-256 thumbprint of the key. Published keys whose kid does not
+// match their thumbprint are ignored, so that a kid can not be reused for a different key.
+type Verifier struct {
+	URL    string
+	Client *http.Client
+	// Pins are thumbprints of the only keys that will be trusted, if any. A compromised JWKS
+	// endpoint can not introduce new keys, but keys must be pinned before they are rotated in.
+	Pins []string
+	// RefreshInterval limits how often the keys may be refetched. Defaults to
+	// DefaultRefreshInterval.
+	RefreshInterval time.Duration
+
+	mutex     sync.RWMutex
+	keys      map[string]jose.JSONWebKey
+	fetchedAt time.Time
+}
+
+// NewVerifier creates a Verifier for keys published at url, optionally pinned.
+func NewVerifier(url string, pins ...string) *Verifier {
+	return &Verifier{
+		URL:             url,
+		Client:          http.DefaultClient,
+		Pins:            pins,
+		RefreshInterval: DefaultRefreshInterval,
+	}
+}
+
+// Verify checks the signature of a compact JWT and the expected claims, then decodes its claims
+// into the given values. The token must have a kid header and an alg that matches the key.
+func (v *Verifier) Verify(tokenStr string, expected jwt.Expected, claims ...interface{}) error {
+	token, err := jwt.ParseSigned(tokenStr)
+	if err != nil {
+		return errors.Wrap(err, "ParseSigned")
+	}
+	header := token.Headers[0]
+	if header.KeyID == "" {
+		return errors.New("missing kid")
+	}
+
+	key, err := v.Key(header.KeyID)
+	if err != nil {
+		return err
+	}
+	if key.Algorithm != "" && header.Algorithm != key.Algorithm {
+		return errors.New("unexpected algorithm")
+	}
+
+	std := jwt.Claims{}
+	err = token.Claims(key.Key, append([]interface{}{&std}, claims...)...)
+	if err != nil {
+		return errors.Wrap(err, "Claims")
+	}
+	if expected.Time.IsZero() {
+		expected.Time = time.Now()
+	}
+	return errors.Wrap(std.Validate(expected), "Validate")
+}
+
+// Key returns the cached key for the kid, refetching the keys if it is unknown.
+func (v *Verifier) Key(kid string) (*jose.JSONWebKey, error) {
+	v.mutex.RLock()
+	key, ok := v.keys[kid]
+	v.mutex.RUnlock()
+	if ok {
+		return &key, nil
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	// another caller may have refreshed while waiting for the lock
+	if key, ok := v.keys[kid]; ok {
+		return &key, nil
+	}
+	if time.Since(v.fetchedAt) < v.refreshInterval() {
+		return nil, ErrUnknownKey
+	}
+	err := v.refresh()
+	if err != nil {
+		return nil, err
+	}
+	if key, ok := v.keys[kid]; ok {
+		return &key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (v *Verifier) refreshInterval() time.Duration {
+	if v.RefreshInterval == 0 {
+		return DefaultRefreshInterval
+	}
+	return v.RefreshInterval
+}
+
+// refresh replaces the cached keys, so that keys which are no longer published stop verifying.
+func (v *Verifier) refresh() error {
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	v.fetchedAt = time.Now()
+
+	res, err := client.Get(v.URL)
+	if err != nil {
+		return errors.Wrap(err, "Get")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Status Code: %v", res.StatusCode)
+	}
+
+	set := jose.JSONWebKeySet{}
+	err = json.NewDecoder(res.Body).Decode(&set)
+	if err != nil {
+		return errors.Wrap(err, "Decode")
+	}
+
+	keys := map[string]jose.JSONWebKey{}
+	for _, key := range set.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		thumbprint, err := Thumbprint(&key)
+		if err != nil || thumbprint != key.KeyID || !v.pinned(thumbprint) {
+			continue
+		}
+		keys[key.KeyID] = key
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *Verifier) pinned(thumbprint string) bool {
+	if len(v.Pins) == 0 {
+		return true
+	}
+	for _, pin := range v.Pins {
+		if pin == thumbprint {
+			return true
+		}
+	}
+	return false
+}
+
+// Thumbprint returns the RFC 7638 SHA-256 thumbprint of a key, as used for AuthN key IDs and pins.
+func Thumbprint(key *jose.JSONWebKey) (string, error) {
+	public := key.Public()
+	thumbprint, err := public.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", errors.Wrap(err, "Thumbprint")
+	}
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
@@ -0,0 +1,122 @@
+package jwks_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/data/private"
+	"github.com/keratin/authn-server/lib/jwks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func sign(t *testing.T, key *private.Key, claims interface{}) string {
+	signer, err := jose.NewSigner(key.SigningKey(), (&jose.SignerOptions{}).WithType("JWT"))
+	require.NoError(t, err)
+	tokenStr, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return tokenStr
+}
+
+func TestVerifier(t *testing.T) {
+	key, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	other, err := private.GenerateKey(512)
+	require.NoError(t, err)
+
+	published := []jose.JSONWebKey{key.JWK}
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: published})
+	}))
+	defer server.Close()
+
+	claims := jwt.Claims{
+		Issuer:   "https://authn.example.com",
+		Subject:  "123",
+		Audience: jwt.Audience{"app.example.com"},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+	expected := jwt.Expected{Issuer: "https://authn.example.com", Audience: jwt.Audience{"app.example.com"}}
+
+	t.Run("verifies and caches keys", func(t *testing.T) {
+		verifier := jwks.NewVerifier(server.URL)
+		fetches = 0
+
+		for i := 0; i < 3; i++ {
+			found := jwt.Claims{}
+			err := verifier.Verify(sign(t, key, claims), expected, &found)
+			require.NoError(t, err)
+			assert.Equal(t, "123", found.Subject)
+		}
+		assert.Equal(t, 1, fetches)
+	})
+
+	t.Run("refetches for a rotated key", func(t *testing.T) {
+		verifier := jwks.NewVerifier(server.URL)
+		verifier.RefreshInterval = time.Nanosecond
+		require.NoError(t, verifier.Verify(sign(t, key, claims), expected))
+
+		published = []jose.JSONWebKey{key.JWK, other.JWK}
+		defer func() { published = []jose.JSONWebKey{key.JWK} }()
+		assert.NoError(t, verifier.Verify(sign(t, other, claims), expected))
+	})
+
+	t.Run("limits refetches for unknown keys", func(t *testing.T) {
+		verifier := jwks.NewVerifier(server.URL)
+		fetches = 0
+
+		assert.Equal(t, jwks.ErrUnknownKey, verifier.Verify(sign(t, other, claims), expected))
+		assert.Equal(t, jwks.ErrUnknownKey, verifier.Verify(sign(t, other, claims), expected))
+		assert.Equal(t, 1, fetches)
+	})
+
+	t.Run("rejects keys that are not pinned", func(t *testing.T) {
+		pin, err := jwks.Thumbprint(&other.JWK)
+		require.NoError(t, err)
+		verifier := jwks.NewVerifier(server.URL, pin)
+
+		assert.Equal(t, jwks.ErrUnknownKey, verifier.Verify(sign(t, key, claims), expected))
+	})
+
+	t.Run("accepts pinned keys", func(t *testing.T) {
+		pin, err := jwks.Thumbprint(&key.JWK)
+		require.NoError(t, err)
+		verifier := jwks.NewVerifier(server.URL, pin)
+
+		assert.NoError(t, verifier.Verify(sign(t, key, claims), expected))
+	})
+
+	t.Run("rejects keys with a kid that is not their thumbprint", func(t *testing.T) {
+		impostor := other.JWK
+		impostor.KeyID = key.JWK.KeyID
+		published = []jose.JSONWebKey{impostor}
+		defer func() { published = []jose.JSONWebKey{key.JWK} }()
+		verifier := jwks.NewVerifier(server.URL)
+
+		forged := &private.Key{JWK: impostor, PrivateKey: other.PrivateKey}
+		assert.Equal(t, jwks.ErrUnknownKey, verifier.Verify(sign(t, forged, claims), expected))
+	})
+
+	t.Run("rejects tokens without a kid", func(t *testing.T) {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key.PrivateKey}, nil)
+		require.NoError(t, err)
+		tokenStr, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+
+		verifier := jwks.NewVerifier(server.URL)
+		assert.Error(t, verifier.Verify(tokenStr, expected))
+	})
+
+	t.Run("rejects unexpected claims", func(t *testing.T) {
+		verifier := jwks.NewVerifier(server.URL)
+		err := verifier.Verify(sign(t, key, claims), jwt.Expected{Audience: jwt.Audience{"other.example.com"}})
+		assert.Error(t, err)
+	})
+}
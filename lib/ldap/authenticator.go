@@ -0,0 +1,82 @@
+// Package ldap verifies passwords by binding to an LDAP directory, such as Active Directory.
+package ldap
+
+import (
+	"crypto/tls"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidCredentials is returned when the directory rejects a username and password.
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// Authenticator verifies a username and password.
+type Authenticator interface {
+	Authenticate(username string, password string) error
+}
+
+// Client is an Authenticator that binds to an LDAP server as the user.
+type Client struct {
+	url          *url.URL
+	bindTemplate string
+	baseDN       string
+	startTLS     bool
+	timeout      time.Duration
+}
+
+// NewClient returns a Client for the server at an ldap:// or ldaps:// URL. A user binds with the
+// bindTemplate, where %s is replaced by the escaped username, followed by the baseDN if present.
+// With startTLS, an ldap:// connection is upgraded before binding.
+func NewClient(u *url.URL, bindTemplate string, baseDN string, startTLS bool) *Client {
+	return &Client{
+		url:          u,
+		bindTemplate: bindTemplate,
+		baseDN:       baseDN,
+		startTLS:     startTLS,
+		timeout:      10 * time.Second,
+	}
+}
+
+// BindDN returns the name that a user binds with.
+func (c *Client) BindDN(username string) string {
+	dn := strings.Replace(c.bindTemplate, "%s", ldap.EscapeDN(username), 1)
+	if c.baseDN != "" {
+		dn += "," + c.baseDN
+	}
+	return dn
+}
+
+// Authenticate binds as the user. It returns ErrInvalidCredentials when the server refuses.
+func (c *Client) Authenticate(username string, password string) error {
+	// an empty password would be an unauthenticated bind, which many servers allow
+	if username == "" || password == "" {
+		return ErrInvalidCredentials
+	}
+
+	conn, err := ldap.DialURL(c.url.String(), ldap.DialWithDialer(&net.Dialer{Timeout: c.timeout}))
+	if err != nil {
+		return errors.Wrap(err, "DialURL")
+	}
+	defer conn.Close()
+	conn.SetTimeout(c.timeout)
+
+	if c.startTLS {
+		err = conn.StartTLS(&tls.Config{ServerName: c.url.Hostname()})
+		if err != nil {
+			return errors.Wrap(err, "StartTLS")
+		}
+	}
+
+	err = conn.Bind(c.BindDN(username), password)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+		return ErrInvalidCredentials
+	} else if err != nil {
+		return errors.Wrap(err, "Bind")
+	}
+	return nil
+}
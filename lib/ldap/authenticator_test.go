@@ -0,0 +1,44 @@
+package ldap_test
+
+import (
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/ldap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient(t *testing.T) {
+	server, err := url.Parse("ldap://127.0.0.1:389")
+	require.NoError(t, err)
+
+	t.Run("bind names", func(t *testing.T) {
+		client := ldap.NewClient(server, "uid=%s", "ou=people,dc=example,dc=com", false)
+		assert.Equal(t, "uid=alice,ou=people,dc=example,dc=com", client.BindDN("alice"))
+		assert.Equal(t, `uid=alice\,ou=admins,ou=people,dc=example,dc=com`, client.BindDN("alice,ou=admins"))
+
+		client = ldap.NewClient(server, "%s@corp.example.com", "", false)
+		assert.Equal(t, "alice@corp.example.com", client.BindDN("alice"))
+	})
+
+	t.Run("empty password", func(t *testing.T) {
+		client := ldap.NewClient(server, "uid=%s", "", false)
+		assert.Equal(t, ldap.ErrInvalidCredentials, client.Authenticate("alice", ""))
+	})
+
+	t.Run("unreachable server", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		listener.Close()
+
+		unreachable, err := url.Parse("ldap://" + addr)
+		require.NoError(t, err)
+		client := ldap.NewClient(unreachable, "uid=%s", "", false)
+		err = client.Authenticate("alice", "secret")
+		assert.Error(t, err)
+		assert.NotEqual(t, ldap.ErrInvalidCredentials, err)
+	})
+}
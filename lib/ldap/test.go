@@ -0,0 +1,13 @@
+package ldap
+
+// TestAuthenticator is a fake directory for tests, mapping usernames to passwords.
+type TestAuthenticator map[string]string
+
+// Authenticate returns ErrInvalidCredentials unless the password matches.
+func (a TestAuthenticator) Authenticate(username string, password string) error {
+	expected, ok := a[username]
+	if !ok || password == "" || password != expected {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
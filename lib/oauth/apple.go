@@ -0,0 +1,101 @@
+package oauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const appleIssuer = "https://appleid.apple.com"
+
+// AppleEndpoint is Apple's OAuth 2.0 endpoint. It may be replaced in tests.
+var AppleEndpoint = oauth2.Endpoint{
+	AuthURL:  appleIssuer + "/auth/authorize",
+	TokenURL: appleIssuer + "/auth/token",
+}
+
+func init() {
+	// Apple expects the client secret in the request body
+	oauth2.RegisterBrokenAuthHeaderProvider(appleIssuer)
+}
+
+// NewAppleProvider returns a AuthN integration for Sign in with Apple. The credentials' ID is the
+// Services ID, the Secret is the hex-encoded private key (.p8) that Apple generated, and the
+// Additional settings must include the keyID and teamID.
+//
+// Apple does not publish a user info endpoint, so the account's ID and email are read from the
+// id_token that is returned with the access token.
+func NewAppleProvider(credentials *Credentials) (*Provider, error) {
+	key, err := parseAppleKey(credentials.Secret)
+	if err != nil {
+		return nil, err
+	}
+	keyID := credentials.Additional["keyID"]
+	teamID := credentials.Additional["teamID"]
+	if keyID == "" || teamID == "" {
+		return nil, errors.New("Apple credentials must include keyID and teamID")
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewSigner")
+	}
+
+	config := &oauth2.Config{
+		ClientID: credentials.ID,
+		Scopes:   []string{"email"},
+		Endpoint: AppleEndpoint,
+	}
+
+	return &Provider{
+		config: config,
+		// the client secret is a short-lived JWT signed by the private key
+		secret: func() (string, error) {
+			now := time.Now()
+			return jwt.Signed(signer).Claims(jwt.Claims{
+				Issuer:   teamID,
+				Subject:  credentials.ID,
+				Audience: jwt.Audience{appleIssuer},
+				IssuedAt: jwt.NewNumericDate(now),
+				Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+			}).CompactSerialize()
+		},
+		// Apple requires a POST to the return URL when requesting the email scope
+		AuthCodeOptions: []oauth2.AuthCodeOption{
+			oauth2.SetAuthURLParam("response_mode", "form_post"),
+		},
+		UserInfo: func(t *oauth2.Token) (*UserInfo, error) {
+			return idTokenUserInfo(t, appleIssuer, credentials.ID)
+		},
+	}, nil
+}
+
+// parseAppleKey decodes a hex-encoded .p8 file, or the DER key within it.
+func parseAppleKey(secret string) (*ecdsa.PrivateKey, error) {
+	der, err := hex.DecodeString(secret)
+	if err != nil {
+		return nil, errors.Wrap(err, "DecodeString")
+	}
+	if block, _ := pem.Decode(der); block != nil {
+		der = block.Bytes
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParsePKCS8PrivateKey")
+	}
+	ecKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("Apple key must be an ECDSA key")
+	}
+	return ecKey, nil
+}
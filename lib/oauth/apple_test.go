@@ -0,0 +1,80 @@
+package oauth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/oauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestAppleProvider(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	p8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	credentials, err := oauth.NewCredentials("com.example.app:" + hex.EncodeToString(p8) + ":keyID=ABC123,teamID=TEAM42")
+	require.NoError(t, err)
+	provider, err := oauth.NewAppleProvider(credentials)
+	require.NoError(t, err)
+
+	t.Run("requires keyID and teamID", func(t *testing.T) {
+		credentials, err := oauth.NewCredentials("com.example.app:" + hex.EncodeToString(p8))
+		require.NoError(t, err)
+		_, err = oauth.NewAppleProvider(credentials)
+		assert.Error(t, err)
+	})
+
+	t.Run("signs a client secret", func(t *testing.T) {
+		config, err := provider.Config("https://authn.example.com/oauth/apple/return")
+		require.NoError(t, err)
+
+		token, err := jwt.ParseSigned(config.ClientSecret)
+		require.NoError(t, err)
+		assert.Equal(t, "ABC123", token.Headers[0].KeyID)
+		claims := jwt.Claims{}
+		require.NoError(t, token.Claims(&key.PublicKey, &claims))
+		assert.Equal(t, "TEAM42", claims.Issuer)
+		assert.Equal(t, "com.example.app", claims.Subject)
+		assert.True(t, claims.Audience.Contains("https://appleid.apple.com"))
+	})
+
+	t.Run("reads user info from the id_token", func(t *testing.T) {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, nil)
+		require.NoError(t, err)
+		sign := func(audience string) *oauth2.Token {
+			idToken, err := jwt.Signed(signer).Claims(map[string]interface{}{
+				"iss":   "https://appleid.apple.com",
+				"aud":   audience,
+				"sub":   "001234.abcdef",
+				"email": "user@privaterelay.appleid.com",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+			}).CompactSerialize()
+			require.NoError(t, err)
+			return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"id_token": idToken})
+		}
+
+		user, err := provider.UserInfo(sign("com.example.app"))
+		require.NoError(t, err)
+		assert.Equal(t, "001234.abcdef", user.ID)
+		assert.Equal(t, "user@privaterelay.appleid.com", user.Email)
+
+		_, err = provider.UserInfo(sign("com.example.other"))
+		assert.Error(t, err)
+
+		_, err = provider.UserInfo(&oauth2.Token{AccessToken: "access"})
+		assert.Error(t, err)
+	})
+}
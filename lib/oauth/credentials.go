@@ -7,19 +7,32 @@ import (
 
 // Credentials is a configuration struct for OAuth Providers
 type Credentials struct {
-	ID     string
-	Secret string
+	ID         string
+	Secret     string
+	Additional map[string]string
 }
 
 // NewCredentials parses a credential string in the format `id:string` and returns a Credentials
-// suitable for OAuth Provider configuration.
+// suitable for OAuth Provider configuration. Providers that need more settings may accept a third
+// section in the format `id:string:key=value,key=value`.
 func NewCredentials(credentials string) (*Credentials, error) {
-	if strings.Count(credentials, ":") != 1 {
+	strs := strings.Split(credentials, ":")
+	if len(strs) != 2 && len(strs) != 3 {
 		return nil, errors.New("Credentials must be in the format `id:string`")
 	}
-	strs := strings.SplitN(credentials, ":", 2)
-	return &Credentials{
+	c := &Credentials{
 		ID:     strs[0],
 		Secret: strs[1],
-	}, nil
+	}
+	if len(strs) == 3 {
+		c.Additional = map[string]string{}
+		for _, pair := range strings.Split(strs[2], ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return nil, errors.New("Additional credentials must be in the format `key=value,key=value`")
+			}
+			c.Additional[kv[0]] = kv[1]
+		}
+	}
+	return c, nil
 }
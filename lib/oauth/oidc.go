@@ -0,0 +1,167 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// NewOIDCProvider returns a AuthN integration for an OpenID Connect issuer. The endpoints are
+// found in the issuer's discovery document, which is fetched when the provider is first used and
+// again after a failure.
+//
+// The account's ID and email are read from the id_token, or from the userinfo endpoint when the
+// id_token does not include an email.
+func NewOIDCProvider(issuer string, credentials *Credentials, scopes []string) *Provider {
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email"}
+	}
+
+	config := &oauth2.Config{
+		ClientID:     credentials.ID,
+		ClientSecret: credentials.Secret,
+		Scopes:       scopes,
+	}
+	discovery := &oidcDiscovery{issuer: issuer}
+
+	return &Provider{
+		config: config,
+		endpoint: func() (oauth2.Endpoint, error) {
+			doc, err := discovery.get()
+			if err != nil {
+				return oauth2.Endpoint{}, err
+			}
+			return oauth2.Endpoint{
+				AuthURL:  doc.AuthorizationEndpoint,
+				TokenURL: doc.TokenEndpoint,
+			}, nil
+		},
+		UserInfo: func(t *oauth2.Token) (*UserInfo, error) {
+			user, err := idTokenUserInfo(t, issuer, credentials.ID)
+			if err != nil {
+				return nil, err
+			}
+			if user.Email != "" {
+				return user, nil
+			}
+
+			doc, err := discovery.get()
+			if err != nil {
+				return nil, err
+			}
+			if doc.UserinfoEndpoint == "" {
+				return nil, errors.New("missing email")
+			}
+			resp, err := config.Client(context.TODO(), t).Get(doc.UserinfoEndpoint)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			var info struct {
+				Subject string `json:"sub"`
+				Email   string `json:"email"`
+			}
+			err = json.NewDecoder(resp.Body).Decode(&info)
+			if err != nil {
+				return nil, errors.Wrap(err, "Decode")
+			}
+			// the userinfo response must describe the same user as the id_token
+			if info.Subject != user.ID {
+				return nil, errors.New("userinfo sub does not match id_token")
+			}
+			user.Email = info.Email
+			return user, nil
+		},
+	}
+}
+
+// oidcDiscovery caches an issuer's discovery document.
+type oidcDiscovery struct {
+	issuer string
+	mutex  sync.Mutex
+	doc    *oidcDocument
+}
+
+type oidcDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+func (d *oidcDiscovery) get() (*oidcDocument, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.doc != nil {
+		return d.doc, nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(strings.TrimSuffix(d.issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return nil, errors.Wrap(err, "Get")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Status Code: %v", resp.StatusCode)
+	}
+
+	doc := oidcDocument{}
+	err = json.NewDecoder(resp.Body).Decode(&doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decode")
+	}
+	if doc.Issuer != d.issuer {
+		return nil, fmt.Errorf("discovered issuer %s does not match", doc.Issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, errors.New("discovery document is missing endpoints")
+	}
+	d.doc = &doc
+	return d.doc, nil
+}
+
+// idTokenUserInfo reads the user from the id_token that was returned with the access token.
+func idTokenUserInfo(t *oauth2.Token, issuer string, clientID string) (*UserInfo, error) {
+	idToken, ok := t.Extra("id_token").(string)
+	if !ok {
+		return nil, errors.New("missing id_token")
+	}
+	token, err := jwt.ParseSigned(idToken)
+	if err != nil {
+		return nil, errors.Wrap(err, "ParseSigned")
+	}
+
+	// The id_token was received directly from the provider's token endpoint, so TLS has already
+	// verified where it came from.
+	claims := struct {
+		jwt.Claims
+		Email string `json:"email"`
+	}{}
+	err = token.UnsafeClaimsWithoutVerification(&claims)
+	if err != nil {
+		return nil, errors.Wrap(err, "Claims")
+	}
+	err = claims.Claims.Validate(jwt.Expected{
+		Issuer:   issuer,
+		Audience: jwt.Audience{clientID},
+		Time:     time.Now(),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "Validate")
+	}
+
+	return &UserInfo{
+		ID:    claims.Subject,
+		Email: claims.Email,
+	}, nil
+}
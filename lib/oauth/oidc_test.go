@@ -0,0 +1,100 @@
+package oauth_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/oauth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func TestOIDCProvider(t *testing.T) {
+	discoveries := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			discoveries++
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 server.URL,
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+				"userinfo_endpoint":      server.URL + "/userinfo",
+			})
+		case "/userinfo":
+			json.NewEncoder(w).Encode(map[string]string{
+				"sub":   "user-1",
+				"email": "user@acme.com",
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	credentials, err := oauth.NewCredentials("client:secret")
+	require.NoError(t, err)
+	provider := oauth.NewOIDCProvider(server.URL, credentials, nil)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("key-a-reno")}, nil)
+	require.NoError(t, err)
+	sign := func(claims map[string]interface{}) *oauth2.Token {
+		idToken, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+		require.NoError(t, err)
+		return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]interface{}{"id_token": idToken})
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+
+	t.Run("discovers and caches endpoints", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			config, err := provider.Config("https://authn.example.com/oauth/acme/return")
+			require.NoError(t, err)
+			assert.Equal(t, server.URL+"/authorize", config.Endpoint.AuthURL)
+			assert.Equal(t, server.URL+"/token", config.Endpoint.TokenURL)
+			assert.Equal(t, []string{"openid", "email"}, config.Scopes)
+		}
+		assert.Equal(t, 1, discoveries)
+	})
+
+	t.Run("rejects a mismatched issuer", func(t *testing.T) {
+		provider := oauth.NewOIDCProvider(server.URL+"/", credentials, nil)
+		_, err := provider.Config("https://authn.example.com/oauth/acme/return")
+		assert.Error(t, err)
+	})
+
+	t.Run("reads user info from the id_token", func(t *testing.T) {
+		user, err := provider.UserInfo(sign(map[string]interface{}{
+			"iss": server.URL, "aud": "client", "sub": "user-1", "email": "id@acme.com", "exp": exp,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.ID)
+		assert.Equal(t, "id@acme.com", user.Email)
+	})
+
+	t.Run("reads email from the userinfo endpoint", func(t *testing.T) {
+		user, err := provider.UserInfo(sign(map[string]interface{}{
+			"iss": server.URL, "aud": "client", "sub": "user-1", "exp": exp,
+		}))
+		require.NoError(t, err)
+		assert.Equal(t, "user@acme.com", user.Email)
+
+		_, err = provider.UserInfo(sign(map[string]interface{}{
+			"iss": server.URL, "aud": "client", "sub": "user-2", "exp": exp,
+		}))
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects an id_token for another client", func(t *testing.T) {
+		_, err := provider.UserInfo(sign(map[string]interface{}{
+			"iss": server.URL, "aud": "other", "sub": "user-1", "email": "id@acme.com", "exp": exp,
+		}))
+		assert.Error(t, err)
+	})
+}
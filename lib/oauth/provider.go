@@ -8,6 +8,12 @@ import (
 type Provider struct {
 	config   *oauth2.Config
 	UserInfo UserInfoFetcher
+	// AuthCodeOptions are added to the URL that begins the OAuth flow.
+	AuthCodeOptions []oauth2.AuthCodeOption
+	// secret generates the client secret, for providers that do not issue a static one.
+	secret func() (string, error)
+	// endpoint finds the endpoint, for providers that are discovered.
+	endpoint func() (oauth2.Endpoint, error)
 }
 
 // UserInfo is the minimum necessary needed from an OAuth Provider to connect with AuthN accounts
@@ -21,16 +27,32 @@ type UserInfoFetcher = func(t *oauth2.Token) (*UserInfo, error)
 
 // NewProvider returns a properly configured Provider
 func NewProvider(config *oauth2.Config, userInfo UserInfoFetcher) *Provider {
-	return &Provider{config, userInfo}
+	return &Provider{config: config, UserInfo: userInfo}
 }
 
 // Config returns a complete oauth2.Config after injecting the RedirectURL
-func (p *Provider) Config(redirectURL string) *oauth2.Config {
+func (p *Provider) Config(redirectURL string) (*oauth2.Config, error) {
+	secret := p.config.ClientSecret
+	if p.secret != nil {
+		var err error
+		secret, err = p.secret()
+		if err != nil {
+			return nil, err
+		}
+	}
+	endpoint := p.config.Endpoint
+	if p.endpoint != nil {
+		var err error
+		endpoint, err = p.endpoint()
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &oauth2.Config{
 		ClientID:     p.config.ClientID,
-		ClientSecret: p.config.ClientSecret,
+		ClientSecret: secret,
 		Scopes:       p.config.Scopes,
-		Endpoint:     p.config.Endpoint,
+		Endpoint:     endpoint,
 		RedirectURL:  redirectURL,
-	}
+	}, nil
 }
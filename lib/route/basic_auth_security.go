@@ -3,11 +3,17 @@ package route
 import (
 	"crypto/subtle"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
 // BasicAuthSecurity is a SecurityHandler that relies on HTTP Basic Auth. It takes precaution to
 // ensure that verifying credentials is a constant time operation, and will not even allow a timing
 // attack to confirm if the guessed username is correct without a correct password.
+//
+// Browsers remember Basic Auth credentials and send them with cross-site requests, e.g. a form that
+// another site posts to AuthN. BasicAuthSecurity refuses requests that Origin or Sec-Fetch-Site
+// show to be cross-site. Clients that send neither header, like backend servers, are unaffected.
 func BasicAuthSecurity(username string, password string, realm string) SecurityHandler {
 
 	// SECURITY: ensure that both ConstantTimeCompare operations are run, so that a
@@ -21,6 +27,12 @@ func BasicAuthSecurity(username string, password string, realm string) SecurityH
 
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isCrossSite(r) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte("Cross-site requests are not allowed.\n"))
+				return
+			}
+
 			user, pass, ok := r.BasicAuth()
 
 			if !ok || !match(user, pass) {
@@ -34,3 +46,24 @@ func BasicAuthSecurity(username string, password string, realm string) SecurityH
 		})
 	}
 }
+
+// isCrossSite is true when a browser reports that the request came from another origin
+func isCrossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+	case "same-origin", "none":
+		return false
+	default:
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return true
+	}
+	return !strings.EqualFold(u.Host, r.Host)
+}
@@ -53,3 +53,41 @@ func TestBasicAuthSecurity(t *testing.T) {
 		}
 	}
 }
+
+func TestBasicAuthSecurityCrossSite(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("success"))
+	})
+	adapter := route.BasicAuthSecurity("user", "pass", "authn-server tests")
+
+	testCases := []struct {
+		header  string
+		value   string
+		success bool
+	}{
+		{"", "", true},
+		{"Sec-Fetch-Site", "same-origin", true},
+		{"Sec-Fetch-Site", "none", true},
+		{"Sec-Fetch-Site", "same-site", false},
+		{"Sec-Fetch-Site", "cross-site", false},
+		{"Origin", "http://authn.example.com", true},
+		{"Origin", "http://evil.example.com", false},
+		{"Origin", "null", false},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest("POST", "http://authn.example.com/accounts/import", nil)
+		req.SetBasicAuth("user", "pass")
+		if tc.header != "" {
+			req.Header.Set(tc.header, tc.value)
+		}
+		res := httptest.NewRecorder()
+		adapter(nextHandler).ServeHTTP(res, req)
+
+		if tc.success {
+			assert.Equal(t, http.StatusOK, res.Code, tc.value)
+		} else {
+			assert.Equal(t, http.StatusForbidden, res.Code, tc.value)
+		}
+	}
+}
@@ -100,6 +100,12 @@ func (c *Client) Delete(path string) (*http.Response, error) {
 	return c.do(delete, contentTypeFormURLEncoded, path, nil)
 }
 
+// DeleteJSON issues a DELETE to the specified path with a JSON content string, and with any
+// modifications configured for the current client.
+func (c *Client) DeleteJSON(path string, content string) (*http.Response, error) {
+	return c.do(delete, contentTypeJSON, path, strings.NewReader(content))
+}
+
 // PostForm issues a POST to the specified path like net/http's PostForm, but with any modifications
 // configured for the current client.
 func (c *Client) PostForm(path string, form url.Values) (*http.Response, error) {
@@ -124,6 +130,12 @@ func (c *Client) PatchJSON(path string, content string) (*http.Response, error)
 	return c.do(patch, contentTypeJSON, path, strings.NewReader(content))
 }
 
+// Put issues a PUT to the specified path like net/http's PostForm, but with any modifications
+// configured for the current client.
+func (c *Client) Put(path string, form url.Values) (*http.Response, error) {
+	return c.do(put, contentTypeFormURLEncoded, path, strings.NewReader(form.Encode()))
+}
+
 // Preflight issues a CORS OPTIONS request
 func (c *Client) Preflight(domain *Domain, verb string, path string) (*http.Response, error) {
 	cPreflight := c.Referred(domain).With(func(req *http.Request) *http.Request {
@@ -139,7 +151,7 @@ func (c *Client) do(verb string, contentType string, path string, body io.Reader
 		return nil, err
 	}
 
-	if verb == post || verb == patch || verb == put {
+	if verb == post || verb == patch || verb == put || body != nil {
 		req.Header.Add("Content-Type", contentType)
 	}
 
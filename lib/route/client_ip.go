@@ -0,0 +1,16 @@
+package route
+
+import (
+	"net"
+	"net/http"
+)
+
+// ClientIP is the request's remote address without a port. Behind a proxy, this is only the
+// client's IP once the proxy headers have been read.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -7,6 +7,9 @@ import (
 
 // Domain is subset of url.URL that enables a fuzzy match. A Domain must always have a Hostname, and
 // may also have a Port.
+//
+// A Hostname beginning with "*." is a wildcard that matches any single subdomain label, so that
+// "*.example.com" matches "foo.example.com" but neither "example.com" nor "foo.bar.example.com".
 type Domain struct {
 	Hostname string
 	Port     string
@@ -30,18 +33,27 @@ func FindDomain(str string, domains []Domain) *Domain {
 
 	for _, d := range domains {
 		if d.Matches(originURL) {
+			if d.IsWildcard() {
+				// report the concrete subdomain, since it may become a JWT audience
+				return &Domain{Hostname: originURL.Hostname(), Port: d.Port}
+			}
 			return &d
 		}
 	}
 	return nil
 }
 
-// Matches will compare the Domain against a given URL. The Hostname must always be a perfect match,
-// and if Port is specified (non-blank) then it must also match. The common ports 80 and 443 will be
-// satisfied by http and https schemes, respectively.
+// IsWildcard reports whether the Domain's Hostname matches subdomains.
+func (d *Domain) IsWildcard() bool {
+	return strings.HasPrefix(d.Hostname, "*.")
+}
+
+// Matches will compare the Domain against a given URL. The Hostname must always be a perfect match
+// (or a wildcard match), and if Port is specified (non-blank) then it must also match. The common
+// ports 80 and 443 will be satisfied by http and https schemes, respectively.
 func (d *Domain) Matches(origin *url.URL) bool {
 	// hostname must always match.
-	if d.Hostname != origin.Hostname() {
+	if !d.matchesHostname(origin.Hostname()) {
 		return false
 	}
 
@@ -62,6 +74,18 @@ func (d *Domain) Matches(origin *url.URL) bool {
 	return false
 }
 
+func (d *Domain) matchesHostname(hostname string) bool {
+	if !d.IsWildcard() {
+		return d.Hostname == hostname
+	}
+	suffix := d.Hostname[1:]
+	if !strings.HasSuffix(hostname, suffix) {
+		return false
+	}
+	label := strings.TrimSuffix(hostname, suffix)
+	return label != "" && !strings.Contains(label, ".")
+}
+
 // String converts a Domain back into a host or host:port string.
 func (d *Domain) String() string {
 	if d.Port == "" {
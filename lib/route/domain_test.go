@@ -41,6 +41,13 @@ func TestDomain(t *testing.T) {
 			{"example.com:443", "https://example.com", true},
 			{"example.com:443", "http://example.com", false},
 			{"example.com:443", "https://example.com:3000", false},
+			{"*.example.com", "http://foo.example.com", true},
+			{"*.example.com", "http://foo.example.com:3000", true},
+			{"*.example.com", "http://example.com", false},
+			{"*.example.com", "http://foo.bar.example.com", false},
+			{"*.example.com", "http://fooexample.com", false},
+			{"*.example.com:443", "https://foo.example.com", true},
+			{"*.example.com:443", "http://foo.example.com", false},
 		}
 
 		for _, tc := range testCases {
@@ -82,5 +89,10 @@ func TestDomain(t *testing.T) {
 		assert.Nil(t, route.FindDomain("http://example.com", domains))
 		assert.Nil(t, route.FindDomain("https://example.com:9100", domains))
 		assert.Nil(t, route.FindDomain("https://www.example.com", domains))
+
+		wildcard := route.ParseDomain("*.example.com:443")
+		domains = []route.Domain{wildcard}
+		assert.Equal(t, route.Domain{Hostname: "foo.example.com", Port: "443"}, *route.FindDomain("https://foo.example.com", domains))
+		assert.Nil(t, route.FindDomain("http://foo.example.com", domains))
 	})
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/felixge/httpsnoop"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -32,10 +33,12 @@ func init() {
 	prometheus.MustRegister(httpTimings)
 }
 
+// InstrumentRoute records metrics for the named route, and traces requests as spans that continue
+// any trace propagated by the client. Spans are not exported until a tracer provider is registered.
 func InstrumentRoute(name string, next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return otelhttp.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		metrics := httpsnoop.CaptureMetrics(next, w, r)
 		httpRequests.WithLabelValues(name, strconv.Itoa(metrics.Code)).Inc()
 		httpTimings.WithLabelValues(name).Observe(float64(metrics.Duration.Seconds()))
-	})
+	}), name)
 }
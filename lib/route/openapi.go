@@ -0,0 +1,172 @@
+package route
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Param describes a request parameter for the OpenAPI document. The Type is an OpenAPI type:
+// string, integer, boolean, object, or array (of strings).
+type Param struct {
+	Name     string
+	Type     string
+	Required bool
+}
+
+// Required describes a parameter that a route needs.
+func Required(name string, typ string) Param {
+	return Param{Name: name, Type: typ, Required: true}
+}
+
+// Optional describes a parameter that a route accepts.
+func Optional(name string, typ string) Param {
+	return Param{Name: name, Type: typ}
+}
+
+// matches variables in a gorilla/mux template, like {id} or {id:[0-9]+}
+var templateVar = regexp.MustCompile(`\{(\w+)(?::[^}]*)?\}`)
+
+var nonWord = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// OpenAPI builds an OpenAPI 3 document for the routes, served from baseURL (when known). The private routes
+// require HTTP Basic Auth unless they are Unsecured.
+func OpenAPI(title string, version string, baseURL string, private []*HandledRoute, public []*HandledRoute) map[string]interface{} {
+	paths := map[string]map[string]interface{}{}
+	add := func(hr *HandledRoute, authenticated bool) {
+		path := templateVar.ReplaceAllString(hr.Tpl, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(hr.Verb)] = operation(hr, authenticated)
+	}
+	for _, hr := range private {
+		add(hr, !hr.unsecured())
+	}
+	for _, hr := range public {
+		add(hr, false)
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths": paths,
+	}
+	if baseURL != "" {
+		doc["servers"] = []map[string]interface{}{{"url": baseURL}}
+	}
+	if len(private) > 0 {
+		doc["components"] = map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"basicAuth": map[string]interface{}{"type": "http", "scheme": "basic"},
+			},
+		}
+	}
+	return doc
+}
+
+func operation(hr *HandledRoute, authenticated bool) map[string]interface{} {
+	described := map[string]Param{}
+	for _, p := range hr.Params {
+		described[p.Name] = p
+	}
+
+	parameters := []map[string]interface{}{}
+	inPath := map[string]bool{}
+	for _, match := range templateVar.FindAllStringSubmatch(hr.Tpl, -1) {
+		name := match[1]
+		inPath[name] = true
+		p, ok := described[name]
+		if !ok {
+			p = Param{Name: name, Type: "string"}
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   schema(p.Type),
+		})
+	}
+
+	var others []Param
+	for _, p := range hr.Params {
+		if !inPath[p.Name] {
+			others = append(others, p)
+		}
+	}
+
+	op := map[string]interface{}{
+		"operationId": operationID(hr),
+		"responses": map[string]interface{}{
+			"default": map[string]interface{}{"description": "See the AuthN API documentation"},
+		},
+	}
+	if hr.Summary != "" {
+		op["summary"] = hr.Summary
+	}
+	if authenticated {
+		op["security"] = []map[string][]string{{"basicAuth": {}}}
+	}
+
+	if hr.Verb == "GET" || hr.Verb == "DELETE" {
+		for _, p := range others {
+			parameters = append(parameters, map[string]interface{}{
+				"name":     p.Name,
+				"in":       "query",
+				"required": p.Required,
+				"schema":   schema(p.Type),
+			})
+		}
+	} else if len(others) > 0 {
+		properties := map[string]interface{}{}
+		required := []string{}
+		for _, p := range others {
+			properties[p.Name] = schema(p.Type)
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+		body := map[string]interface{}{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			body["required"] = required
+		}
+		op["requestBody"] = map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json":                  map[string]interface{}{"schema": body},
+				"application/x-www-form-urlencoded": map[string]interface{}{"schema": body},
+			},
+		}
+	}
+
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+	return op
+}
+
+func schema(typ string) map[string]interface{} {
+	if typ == "array" {
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	}
+	return map[string]interface{}{"type": typ}
+}
+
+// operationID names an operation by its verb and path, e.g. patchAccountsIdLock
+func operationID(hr *HandledRoute) string {
+	id := strings.ToLower(hr.Verb)
+	words := nonWord.Split(templateVar.ReplaceAllString(hr.Tpl, "$1"), -1)
+	for _, word := range words {
+		if word != "" {
+			id += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+	return id
+}
+
+// unsecured is true when the route was SecuredWith(Unsecured())
+func (r *SecuredRoute) unsecured() bool {
+	return reflect.ValueOf(r.security).Pointer() == reflect.ValueOf(unsecured).Pointer()
+}
@@ -39,11 +39,21 @@ func Put(tpl string) *Route {
 	return &Route{Verb: "PUT", Tpl: tpl}
 }
 
-// Route is an incomplete Route comprising only verb and path (as a gorilla/mux template). It must
-// next be `SecuredWith`.
+// Route is an incomplete Route comprising only verb and path (as a gorilla/mux template). It may be
+// `Describe`d, and must next be `SecuredWith`.
 type Route struct {
-	Verb string
-	Tpl  string
+	Verb    string
+	Tpl     string
+	Summary string
+	Params  []Param
+}
+
+// Describe documents a route's purpose and parameters for the OpenAPI document. Parameters in the
+// path template are documented automatically, but may be described here to give them a type.
+func (r Route) Describe(summary string, params ...Param) Route {
+	r.Summary = summary
+	r.Params = params
+	return r
 }
 
 // SecuredWith registers a security handler for a route. A handler must be registered next.
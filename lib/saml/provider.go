@@ -0,0 +1,189 @@
+// Package saml integrates AuthN with SAML 2.0 identity providers, as a service provider.
+package saml
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	saml2 "github.com/russellhaering/gosaml2"
+	"github.com/russellhaering/gosaml2/types"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// Provider is a struct wrapping the necessary bits to integrate a SAML identity provider with AuthN
+type Provider struct {
+	metadataURL       string
+	entityID          string
+	acsURL            string
+	usernameAttribute string
+
+	mutex sync.Mutex
+	sp    *saml2.SAMLServiceProvider
+}
+
+// User is the minimum necessary needed from an assertion to connect with AuthN accounts
+type User struct {
+	// ID is the assertion's NameID
+	ID string
+	// Username is the configured attribute, or else the NameID
+	Username string
+}
+
+// NewProvider returns a Provider for the identity provider with metadata at metadataURL. AuthN is
+// identified by entityID, and must receive assertions at acsURL. The metadata is fetched when the
+// provider is first used and again after a failure.
+func NewProvider(metadataURL string, entityID string, acsURL string, usernameAttribute string) *Provider {
+	return &Provider{
+		metadataURL:       metadataURL,
+		entityID:          entityID,
+		acsURL:            acsURL,
+		usernameAttribute: usernameAttribute,
+	}
+}
+
+// Metadata returns AuthN's service provider metadata, for registration with the identity provider.
+func (p *Provider) Metadata() ([]byte, error) {
+	sp := &saml2.SAMLServiceProvider{
+		ServiceProviderIssuer:       p.entityID,
+		AssertionConsumerServiceURL: p.acsURL,
+	}
+	descriptor, err := sp.Metadata()
+	if err != nil {
+		return nil, errors.Wrap(err, "Metadata")
+	}
+	return xml.MarshalIndent(descriptor, "", "  ")
+}
+
+// AuthURL returns the identity provider's URL for an AuthnRequest with the given ID. The ID must
+// be an XML ID, and is later expected in the response.
+func (p *Provider) AuthURL(requestID string) (string, error) {
+	sp, err := p.serviceProvider()
+	if err != nil {
+		return "", err
+	}
+	doc, err := sp.BuildAuthRequestDocumentNoSig()
+	if err != nil {
+		return "", errors.Wrap(err, "BuildAuthRequestDocumentNoSig")
+	}
+	doc.Root().CreateAttr("ID", requestID)
+	return sp.BuildAuthURLRedirect("", doc)
+}
+
+// InResponseTo returns the AuthnRequest ID that a response claims to answer, before it is
+// verified. The User must still be verified with the same ID.
+func InResponseTo(encodedResponse string) (string, error) {
+	response, err := saml2.DecodeUnverifiedBaseResponse(encodedResponse)
+	if err != nil {
+		return "", errors.Wrap(err, "DecodeUnverifiedBaseResponse")
+	}
+	return response.InResponseTo, nil
+}
+
+// User verifies a response to the AuthnRequest with the given ID and returns the asserted user.
+func (p *Provider) User(encodedResponse string, requestID string) (*User, error) {
+	sp, err := p.serviceProvider()
+	if err != nil {
+		return nil, err
+	}
+	info, err := sp.RetrieveAssertionInfo(encodedResponse)
+	if err != nil {
+		return nil, err
+	}
+	if info.WarningInfo.InvalidTime {
+		return nil, errors.New("assertion is expired or not yet valid")
+	}
+	if info.WarningInfo.NotInAudience || len(info.Assertions[0].Conditions.AudienceRestrictions) == 0 {
+		return nil, errors.New("assertion is not for this audience")
+	}
+	// the signed assertion must answer this request, so that it can't be replayed to another
+	confirmation := info.Assertions[0].Subject.SubjectConfirmation.SubjectConfirmationData
+	if confirmation == nil || confirmation.InResponseTo != requestID {
+		return nil, errors.New("assertion is not in response to this request")
+	}
+
+	user := &User{ID: info.NameID, Username: info.NameID}
+	if p.usernameAttribute != "" {
+		user.Username = info.Values.Get(p.usernameAttribute)
+		if user.Username == "" {
+			return nil, fmt.Errorf("missing %s attribute", p.usernameAttribute)
+		}
+	}
+	return user, nil
+}
+
+// serviceProvider configures a service provider from the identity provider's metadata.
+func (p *Provider) serviceProvider() (*saml2.SAMLServiceProvider, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.sp != nil {
+		return p.sp, nil
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(p.metadataURL)
+	if err != nil {
+		return nil, errors.Wrap(err, "Get")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Status Code: %v", resp.StatusCode)
+	}
+
+	metadata := types.EntityDescriptor{}
+	err = xml.NewDecoder(resp.Body).Decode(&metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decode")
+	}
+	idp := metadata.IDPSSODescriptor
+	if idp == nil {
+		return nil, errors.New("metadata is missing IDPSSODescriptor")
+	}
+
+	certs := dsig.MemoryX509CertificateStore{Roots: []*x509.Certificate{}}
+	for _, kd := range idp.KeyDescriptors {
+		if kd.Use != "" && kd.Use != "signing" {
+			continue
+		}
+		for _, xcert := range kd.KeyInfo.X509Data.X509Certificates {
+			der, err := base64.StdEncoding.DecodeString(xcert.Data)
+			if err != nil {
+				return nil, errors.Wrap(err, "DecodeString")
+			}
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return nil, errors.Wrap(err, "ParseCertificate")
+			}
+			certs.Roots = append(certs.Roots, cert)
+		}
+	}
+	if len(certs.Roots) == 0 {
+		return nil, errors.New("metadata is missing a signing certificate")
+	}
+
+	var ssoURL string
+	for _, sso := range idp.SingleSignOnServices {
+		if sso.Binding == saml2.BindingHttpRedirect {
+			ssoURL = sso.Location
+		}
+	}
+	if ssoURL == "" {
+		return nil, errors.New("metadata is missing an HTTP-Redirect SingleSignOnService")
+	}
+
+	p.sp = &saml2.SAMLServiceProvider{
+		IdentityProviderSSOURL:      ssoURL,
+		IdentityProviderIssuer:      metadata.EntityID,
+		ServiceProviderIssuer:       p.entityID,
+		AssertionConsumerServiceURL: p.acsURL,
+		AudienceURI:                 p.entityID,
+		IDPCertificateStore:         &certs,
+		AllowMissingAttributes:      p.usernameAttribute == "",
+	}
+	return p.sp, nil
+}
@@ -0,0 +1,97 @@
+package saml_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/saml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider(t *testing.T) {
+	idp := saml.NewTestIdP()
+	defer idp.Close()
+
+	entityID := "https://authn.example.com/saml/acme/metadata"
+	acsURL := "https://authn.example.com/saml/acme/acs"
+	provider := saml.NewProvider(idp.MetadataURL(), entityID, acsURL, "")
+
+	t.Run("publishing metadata", func(t *testing.T) {
+		metadata, err := provider.Metadata()
+		require.NoError(t, err)
+		assert.Contains(t, string(metadata), `entityID="`+entityID+`"`)
+		assert.Contains(t, string(metadata), `Location="`+acsURL+`"`)
+	})
+
+	t.Run("building an AuthnRequest", func(t *testing.T) {
+		authURL, err := provider.AuthURL("_request1")
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, idp.Server.URL+"/sso", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+		assert.NotEmpty(t, parsed.Query().Get("SAMLRequest"))
+	})
+
+	t.Run("verifying a response", func(t *testing.T) {
+		response, err := idp.Response("_request2", acsURL, entityID, "user-1", nil)
+		require.NoError(t, err)
+
+		inResponseTo, err := saml.InResponseTo(response)
+		require.NoError(t, err)
+		assert.Equal(t, "_request2", inResponseTo)
+
+		user, err := provider.User(response, "_request2")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.ID)
+		assert.Equal(t, "user-1", user.Username)
+	})
+
+	t.Run("mapping an attribute to the username", func(t *testing.T) {
+		provider := saml.NewProvider(idp.MetadataURL(), entityID, acsURL, "email")
+		response, err := idp.Response("_request3", acsURL, entityID, "user-1", map[string]string{"email": "user@acme.com"})
+		require.NoError(t, err)
+
+		user, err := provider.User(response, "_request3")
+		require.NoError(t, err)
+		assert.Equal(t, "user-1", user.ID)
+		assert.Equal(t, "user@acme.com", user.Username)
+
+		response, err = idp.Response("_request4", acsURL, entityID, "user-1", map[string]string{"name": "User"})
+		require.NoError(t, err)
+		_, err = provider.User(response, "_request4")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejecting a response to another request", func(t *testing.T) {
+		response, err := idp.Response("_request5", acsURL, entityID, "user-1", nil)
+		require.NoError(t, err)
+		_, err = provider.User(response, "_other")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejecting a response for another audience", func(t *testing.T) {
+		response, err := idp.Response("_request6", acsURL, "https://other.example.com", "user-1", nil)
+		require.NoError(t, err)
+		_, err = provider.User(response, "_request6")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejecting a response from another identity provider", func(t *testing.T) {
+		other := saml.NewTestIdP()
+		defer other.Close()
+		response, err := other.Response("_request7", acsURL, entityID, "user-1", nil)
+		require.NoError(t, err)
+		_, err = provider.User(response, "_request7")
+		assert.Error(t, err)
+	})
+
+	t.Run("rejecting a tampered response", func(t *testing.T) {
+		response, err := idp.Response("_request8", acsURL, entityID, "user-1", nil)
+		require.NoError(t, err)
+		tampered := strings.Replace(response, response[len(response)/2:len(response)/2+4], "AAAA", 1)
+		_, err = provider.User(tampered, "_request8")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,121 @@
+package saml
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/beevik/etree"
+	saml2 "github.com/russellhaering/gosaml2"
+	dsig "github.com/russellhaering/goxmldsig"
+)
+
+// TestIdP is a fake identity provider for tests. It publishes metadata and signs responses.
+type TestIdP struct {
+	Server *httptest.Server
+	keys   dsig.X509KeyStore
+}
+
+// NewTestIdP starts a TestIdP. Its metadata is at /metadata and its SSO URL is /sso.
+func NewTestIdP() *TestIdP {
+	idp := &TestIdP{keys: dsig.RandomKeyStoreForTest()}
+	idp.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, cert, err := idp.keys.GetKeyPair()
+		if err != nil {
+			panic(err)
+		}
+
+		doc := etree.NewDocument()
+		entity := doc.CreateElement("md:EntityDescriptor")
+		entity.CreateAttr("xmlns:md", "urn:oasis:names:tc:SAML:2.0:metadata")
+		entity.CreateAttr("xmlns:ds", "http://www.w3.org/2000/09/xmldsig#")
+		entity.CreateAttr("entityID", idp.Server.URL)
+		descriptor := entity.CreateElement("md:IDPSSODescriptor")
+		descriptor.CreateAttr("protocolSupportEnumeration", saml2.SAMLProtocolNamespace)
+		key := descriptor.CreateElement("md:KeyDescriptor")
+		key.CreateAttr("use", "signing")
+		key.CreateElement("ds:KeyInfo").CreateElement("ds:X509Data").CreateElement("ds:X509Certificate").
+			SetText(base64.StdEncoding.EncodeToString(cert))
+		sso := descriptor.CreateElement("md:SingleSignOnService")
+		sso.CreateAttr("Binding", saml2.BindingHttpRedirect)
+		sso.CreateAttr("Location", idp.Server.URL+"/sso")
+
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		doc.WriteTo(w)
+	}))
+	return idp
+}
+
+// MetadataURL returns the URL of the TestIdP's metadata.
+func (idp *TestIdP) MetadataURL() string {
+	return idp.Server.URL + "/metadata"
+}
+
+// Close stops the TestIdP's server.
+func (idp *TestIdP) Close() {
+	idp.Server.Close()
+}
+
+// Response returns an encoded response to requestID with a signed assertion for nameID, as sent to
+// the service provider's acsURL and audience.
+func (idp *TestIdP) Response(requestID string, acsURL string, audience string, nameID string, attributes map[string]string) (string, error) {
+	now := time.Now().UTC()
+	instant := now.Format(time.RFC3339)
+	expiry := now.Add(5 * time.Minute).Format(time.RFC3339)
+
+	assertion := etree.NewElement("saml:Assertion")
+	assertion.CreateAttr("xmlns:saml", "urn:oasis:names:tc:SAML:2.0:assertion")
+	assertion.CreateAttr("ID", "_assertion"+requestID)
+	assertion.CreateAttr("Version", "2.0")
+	assertion.CreateAttr("IssueInstant", instant)
+	assertion.CreateElement("saml:Issuer").SetText(idp.Server.URL)
+	subject := assertion.CreateElement("saml:Subject")
+	subject.CreateElement("saml:NameID").SetText(nameID)
+	confirmation := subject.CreateElement("saml:SubjectConfirmation")
+	confirmation.CreateAttr("Method", saml2.SubjMethodBearer)
+	data := confirmation.CreateElement("saml:SubjectConfirmationData")
+	data.CreateAttr("InResponseTo", requestID)
+	data.CreateAttr("Recipient", acsURL)
+	data.CreateAttr("NotOnOrAfter", expiry)
+	conditions := assertion.CreateElement("saml:Conditions")
+	conditions.CreateAttr("NotBefore", instant)
+	conditions.CreateAttr("NotOnOrAfter", expiry)
+	conditions.CreateElement("saml:AudienceRestriction").CreateElement("saml:Audience").SetText(audience)
+	statement := assertion.CreateElement("saml:AttributeStatement")
+	for name, value := range attributes {
+		attribute := statement.CreateElement("saml:Attribute")
+		attribute.CreateAttr("Name", name)
+		attribute.CreateElement("saml:AttributeValue").SetText(value)
+	}
+
+	ctx := dsig.NewDefaultSigningContext(idp.keys)
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := ctx.SignEnveloped(assertion)
+	if err != nil {
+		return "", err
+	}
+
+	doc := etree.NewDocument()
+	response := doc.CreateElement("samlp:Response")
+	response.CreateAttr("xmlns:samlp", "urn:oasis:names:tc:SAML:2.0:protocol")
+	response.CreateAttr("xmlns:saml", "urn:oasis:names:tc:SAML:2.0:assertion")
+	response.CreateAttr("ID", "_response"+requestID)
+	response.CreateAttr("Version", "2.0")
+	response.CreateAttr("IssueInstant", instant)
+	response.CreateAttr("Destination", acsURL)
+	response.CreateAttr("InResponseTo", requestID)
+	response.CreateElement("saml:Issuer").SetText(idp.Server.URL)
+	response.CreateElement("samlp:Status").CreateElement("samlp:StatusCode").CreateAttr("Value", saml2.StatusCodeSuccess)
+	response.AddChild(signed)
+
+	xml, err := doc.WriteToString()
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString([]byte(xml)), nil
+}
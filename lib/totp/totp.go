@@ -0,0 +1,94 @@
+// Package totp implements RFC 6238 time-based one-time passwords, as used by authenticator apps.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Period is how long each code is valid.
+const Period = 30 * time.Second
+
+// Digits is the length of each code.
+const Digits = 6
+
+// Skew is how many periods before and after the current one are also accepted, to allow for clock
+// drift and the time it takes to type a code.
+const Skew = 1
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random 160-bit secret, base32 encoded as expected by authenticator apps.
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", errors.Wrap(err, "Read")
+	}
+	return encoding.EncodeToString(secret), nil
+}
+
+// URL returns the otpauth:// provisioning URL for a secret, usually displayed as a QR code.
+func URL(issuer string, account string, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(Digits))
+	params.Set("period", fmt.Sprint(int(Period.Seconds())))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// Code returns the code for a secret at the given time.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", errors.Wrap(err, "DecodeString")
+	}
+	return code(key, uint64(t.Unix()/int64(Period.Seconds()))), nil
+}
+
+// Validate checks a code for a secret at the given time, within Skew periods.
+func Validate(passcode string, secret string, t time.Time) bool {
+	if len(passcode) != Digits {
+		return false
+	}
+	for i := -Skew; i <= Skew; i++ {
+		expected, err := Code(secret, t.Add(time.Duration(i)*Period))
+		if err != nil {
+			return false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(passcode)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// code is the HOTP value (RFC 4226) for the counter
+func code(key []byte, counter uint64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, counter)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	truncated := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", Digits, truncated%1000000)
+}
@@ -0,0 +1,64 @@
+package totp_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// RFC 6238 test vectors, truncated to six digits
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := totp.Code(rfcSecret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.code, code, tc.unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	assert.True(t, totp.Validate("081804", rfcSecret, now))
+	assert.True(t, totp.Validate("081804", rfcSecret, now.Add(totp.Period)))
+	assert.False(t, totp.Validate("081804", rfcSecret, now.Add(3*totp.Period)))
+	assert.False(t, totp.Validate("000000", rfcSecret, now))
+	assert.False(t, totp.Validate("81804", rfcSecret, now))
+	assert.False(t, totp.Validate("081804", "not base32!", now))
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	assert.True(t, totp.Validate(code, secret, time.Now()))
+}
+
+func TestURL(t *testing.T) {
+	u, err := url.Parse(totp.URL("example.com", "user@example.com", rfcSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/example.com:user@example.com", u.Path)
+	assert.Equal(t, rfcSecret, u.Query().Get("secret"))
+	assert.Equal(t, "example.com", u.Query().Get("issuer"))
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server"
 	"github.com/sirupsen/logrus"
 
 	"os"
+	"os/signal"
 	"path"
+	"path/filepath"
+	"sync"
+	"syscall"
 )
 
 // VERSION is a value injected at build time with ldflags
@@ -23,6 +31,11 @@ func main() {
 		cmd = os.Args[1]
 	}
 
+	if cmd == "doctor" {
+		doctor()
+		return
+	}
+
 	cfg, err := app.ReadEnv()
 	if err != nil {
 		fmt.Println(err)
@@ -32,8 +45,12 @@ func main() {
 
 	if cmd == "server" {
 		serve(cfg)
-	} else if cmd == "migrate" {
+	} else if cmd == "migrate" && len(os.Args) == 2 {
 		migrate(cfg)
+	} else if cmd == "migrate" && len(os.Args) == 3 && os.Args[2] == "--status" {
+		migrationStatus(cfg)
+	} else if cmd == "import" && len(os.Args) == 3 {
+		importAccounts(cfg, os.Args[2])
 	} else {
 		os.Stderr.WriteString(fmt.Sprintf("unexpected invocation\n"))
 		usage()
@@ -42,36 +59,199 @@ func main() {
 }
 
 func serve(cfg *app.Config) {
-	fmt.Println(fmt.Sprintf("~*~ Keratin AuthN v%s ~*~", VERSION))
-
-	// Default logger
 	logger := logrus.New()
-	logger.Formatter = &logrus.JSONFormatter{}
-	logger.Level = logrus.DebugLevel
+	if cfg.LogFormat == "text" {
+		logger.Formatter = &logrus.TextFormatter{FullTimestamp: true}
+	} else {
+		logger.Formatter = &logrus.JSONFormatter{}
+	}
+	logger.Level = cfg.LogLevel
 	logger.Out = os.Stdout
 
+	if cfg.TracingEnabled {
+		shutdown, err := ops.NewTracerProvider(VERSION)
+		if err != nil {
+			logger.WithError(err).Error("configuring tracing failed")
+			return
+		}
+		defer shutdown(context.Background())
+	}
+
 	app, err := app.NewApp(cfg, logger)
 	if err != nil {
-		fmt.Println(err)
+		logger.WithError(err).Error("starting server failed")
 		return
 	}
+	app.Version = VERSION
+
+	fields := logrus.Fields{"version": VERSION, "authnURL": cfg.AuthNURL.String(), "port": cfg.ServerPort}
+	if app.Config().PublicPort != 0 {
+		fields["publicPort"] = app.Config().PublicPort
+	}
+	if app.Config().GRPCPort != 0 {
+		fields["grpcPort"] = app.Config().GRPCPort
+	}
+	logger.WithFields(fields).Info("starting server")
 
-	fmt.Println(fmt.Sprintf("AUTHN_URL: %s", cfg.AuthNURL))
-	fmt.Println(fmt.Sprintf("PORT: %d", cfg.ServerPort))
-	if app.Config.PublicPort != 0 {
-		fmt.Println(fmt.Sprintf("PUBLIC_PORT: %d", app.Config.PublicPort))
+	go reloadOnHangup(app, logger)
+	if cfg.WatchSecretFiles {
+		go reloadOnChange(app, logger)
 	}
 
 	server.Server(app)
 }
 
+var reloading sync.Mutex
+
+// reload re-reads configuration and applies the settings that may change without a restart.
+func reload(authn *app.App, logger logrus.FieldLogger) {
+	reloading.Lock()
+	defer reloading.Unlock()
+
+	cfg, err := app.ReadEnv()
+	if err != nil {
+		logger.WithError(err).Error("reload failed")
+		return
+	}
+	authn.Reload(cfg)
+	logger.Info("reloaded configuration")
+}
+
+// reloadOnHangup reloads configuration on SIGHUP.
+func reloadOnHangup(authn *app.App, logger logrus.FieldLogger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		reload(authn, logger)
+	}
+}
+
+// reloadOnChange reloads configuration when a file named by a _FILE variable changes. The parent
+// directories are watched, since Kubernetes updates mounted secrets by swapping a symlink.
+func reloadOnChange(authn *app.App, logger logrus.FieldLogger) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.WithError(err).Error("watching secret files failed")
+		return
+	}
+	defer watcher.Close()
+
+	dirs := map[string]bool{}
+	for _, file := range app.SecretFiles() {
+		dir := filepath.Dir(file)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := watcher.Add(dir); err != nil {
+			logger.WithError(err).WithField("dir", dir).Error("watching secret files failed")
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				reload(authn, logger)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.WithError(err).Error("watching secret files failed")
+		}
+	}
+}
+
 func migrate(cfg *app.Config) {
 	fmt.Println("Running migrations.")
 	err := data.MigrateDB(cfg.DatabaseURL)
 	if err != nil {
 		fmt.Println(err)
-	} else {
-		fmt.Println("Migrations complete.")
+		os.Exit(1)
+	}
+	fmt.Println("Migrations complete.")
+}
+
+func migrationStatus(cfg *app.Config) {
+	statuses, err := data.MigrationStatus(cfg.DatabaseURL)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	pending := 0
+	for _, status := range statuses {
+		if status.Applied {
+			fmt.Println(fmt.Sprintf("applied %03d %s", status.Version, status.Name))
+		} else {
+			pending++
+			fmt.Println(fmt.Sprintf("pending %03d %s", status.Version, status.Name))
+		}
+	}
+	fmt.Println(fmt.Sprintf("%d applied, %d pending.", len(statuses)-pending, pending))
+	if pending > 0 {
+		os.Exit(1)
+	}
+}
+
+func importAccounts(cfg *app.Config, filename string) {
+	file := os.Stdin
+	if filename != "-" {
+		var err error
+		file, err = os.Open(filename)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		defer file.Close()
+	}
+
+	db, err := data.NewDB(cfg.DatabaseURL)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	accountStore, err := data.NewAccountStore(db)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	results, err := services.AccountBatchImporter(accountStore, cfg, file)
+	failed := 0
+	for _, result := range results {
+		if result.Errors != nil {
+			failed++
+			fmt.Println(fmt.Sprintf("FAIL line %d (%s): %v", result.Line, result.Username, result.Errors))
+		}
+	}
+	fmt.Println(fmt.Sprintf("Imported %d accounts, %d failed.", len(results)-failed, failed))
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func doctor() {
+	failed := false
+	for _, check := range app.Diagnose() {
+		if check.Err == nil {
+			fmt.Println(fmt.Sprintf("PASS %s", check.Name))
+		} else {
+			failed = true
+			fmt.Println(fmt.Sprintf("FAIL %s: %v", check.Name, check.Err))
+		}
+	}
+	if failed {
+		fmt.Println("\nsee: https://github.com/keratin/authn-server/blob/master/docs/config.md")
+		os.Exit(1)
 	}
 }
 
@@ -81,5 +261,8 @@ func usage() {
 Usage:
 %s server  - run the server (default)
 %s migrate - run migrations
-`, exe, exe))
+%s migrate --status - list applied and pending migrations (exits 1 if any are pending)
+%s doctor  - check configuration and connectivity
+%s import <file> - import accounts from newline-delimited JSON (- for stdin)
+`, exe, exe, exe, exe, exe))
 }
@@ -1,6 +1,7 @@
 package ops
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -17,6 +18,9 @@ type AirbrakeReporter struct {
 // should be in the pattern $PROJECT_ID:$PROJECT_KEY (aka username:password).
 func NewAirbrakeReporter(credentials string) (*AirbrakeReporter, error) {
 	bits := strings.SplitN(credentials, ":", 2)
+	if len(bits) != 2 {
+		return nil, errors.New("expected projectID:projectKey")
+	}
 	projectID, err := strconv.Atoi(bits[0])
 	if err != nil {
 		return nil, err
@@ -31,7 +35,7 @@ func (r *AirbrakeReporter) ReportError(err error) {
 	r.Notify(err, nil)
 }
 
-// ReportRequestError will deliver the given error to Sentry in a background routine along with
+// ReportRequestError will deliver the given error to Airbrake in a background routine along with
 // data relevant to the current http.Request.
 //
 // NOTE: POST data is never reported to Airbrake, so passwords remain private.
@@ -18,5 +18,5 @@ func (r *LogReporter) ReportError(err error) {
 
 // ReportRequestError logs error information. The printed details are not robust.
 func (r *LogReporter) ReportRequestError(err error, req *http.Request) {
-	r.WithFields(logrus.Fields{"method": req.Method, "URL": req.URL}).Error(err)
+	r.WithFields(logrus.Fields{"method": req.Method, "URL": req.URL, "requestID": RequestID(req)}).Error(err)
 }
@@ -0,0 +1,43 @@
+package ops
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"github.com/keratin/authn-server/lib"
+)
+
+// RequestIDHeader is read from requests and written to responses and webhooks to correlate them.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey int
+
+// a client's ID is accepted when it is printable and short enough to log
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]{1,128}$`)
+
+// RequestIDHandler returns a http.Handler that accepts the client's X-Request-ID or generates one,
+// attaches it to the request's context, and echoes it in the response.
+func RequestIDHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			token, err := lib.GenerateToken()
+			if err != nil {
+				panic(err)
+			}
+			id = hex.EncodeToString(token)
+			r.Header.Set(RequestIDHeader, id)
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey(0), id)))
+	})
+}
+
+// RequestID returns the ID attached to the request, if any.
+func RequestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey(0)).(string)
+	return id
+}
@@ -31,6 +31,7 @@ func (r *SentryReporter) ReportError(err error) {
 func (r *SentryReporter) ReportRequestError(err error, req *http.Request) {
 	sentry.WithScope(func(scope *sentry.Scope) {
 		scope.SetRequest(sentry.Request{}.FromHTTPRequest(req))
+		scope.SetTag("request_id", RequestID(req))
 		sentry.CaptureException(err)
 	})
 }
@@ -0,0 +1,51 @@
+package ops
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// ServiceName identifies AuthN in traces unless OTEL_SERVICE_NAME is set.
+const ServiceName = "authn-server"
+
+// NewTracerProvider registers a global OpenTelemetry tracer provider that exports spans with
+// OTLP/HTTP, and a propagator for W3C trace context and baggage headers. The exporter, sampler, and
+// resource are configured by the standard OTEL_* environment variables.
+//
+// The returned function flushes any buffered spans and should be called before exiting.
+func NewTracerProvider(version string) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(context.Background())
+	if err != nil {
+		return nil, errors.Wrap(err, "otlptracehttp.New")
+	}
+
+	res, err := resource.Merge(
+		resource.NewSchemaless(
+			semconv.ServiceName(ServiceName),
+			semconv.ServiceVersion(version),
+		),
+		resource.Environment(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "resource.Merge")
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation.
+const listenFDsStart = 3
+
+// activatedListeners returns the sockets passed by systemd socket activation (see sd_listen_fds).
+// The first socket serves every route, and an optional second socket serves only public routes,
+// as with PUBLIC_PORT. When the server was not activated by systemd, it returns nothing.
+func activatedListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count == 0 {
+		return nil, nil
+	}
+
+	// the sockets are for this process only
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]net.Listener, count)
+	for i := 0; i < count; i++ {
+		f := os.NewFile(uintptr(listenFDsStart+i), "LISTEN_FD_"+strconv.Itoa(listenFDsStart+i))
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "LISTEN_FDS: fd %d", listenFDsStart+i)
+		}
+		listeners[i] = l
+	}
+	return listeners, nil
+}
@@ -0,0 +1,47 @@
+// Package audit records admin actions on the private routes in the audit log.
+package audit
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+)
+
+// Middleware records an admin action, like "account.locked", in the audit log when the handler
+// succeeds. The account is identified by the route's {id}, if any.
+func Middleware(app *app.App, action string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(rec, r)
+			if rec.status > 299 {
+				return
+			}
+
+			accountID, _ := strconv.Atoi(mux.Vars(r)["id"])
+			services.AuditRecorder(app.AuditLog, app.Reporter, &models.AuditEntry{
+				Action:    action,
+				AccountID: accountID,
+				Actor:     models.ActorAdmin,
+				IP:        route.ClientIP(r),
+				UserAgent: r.UserAgent(),
+			})
+		})
+	}
+}
+
+// statusRecorder remembers the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
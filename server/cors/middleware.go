@@ -3,16 +3,25 @@ package cors
 import (
 	"github.com/gorilla/handlers"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/ops"
+	"github.com/keratin/authn-server/server/idempotency"
+	"github.com/keratin/authn-server/server/ratelimit"
+	"github.com/keratin/authn-server/server/sessions"
 	"net/http"
 )
 
 func Middleware(app *app.App) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
-		return handlers.CORS(
-			handlers.AllowedMethods([]string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
-			handlers.AllowCredentials(),
+		options := []handlers.CORSOption{
+			handlers.AllowedMethods(app.Config().CORSAllowedMethods),
+			handlers.AllowedHeaders(append([]string{ops.RequestIDHeader, sessions.CSRFHeader, idempotency.Header}, app.Config().CORSAllowedHeaders...)),
+			handlers.ExposedHeaders(append([]string{ops.RequestIDHeader, sessions.CSRFHeader, idempotency.ReplayedHeader}, ratelimit.Headers...)),
 			handlers.AllowedOrigins([]string{}), // see: https://github.com/gorilla/handlers/issues/117
-			handlers.AllowedOriginValidator(OriginValidator(app.Config.ApplicationDomains)),
-		)(h)
+			handlers.AllowedOriginValidator(OriginValidator(app.Config().ApplicationDomains)),
+		}
+		if app.Config().CORSAllowCredentials {
+			options = append(options, handlers.AllowCredentials())
+		}
+		return handlers.CORS(options...)(h)
 	}
 }
@@ -0,0 +1,60 @@
+package cors_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/cors"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	preflight := func(app *app.App, origin string, method string, headers string) http.Header {
+		h := cors.Middleware(app)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		req := httptest.NewRequest("OPTIONS", "/session", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if headers != "" {
+			req.Header.Set("Access-Control-Request-Headers", headers)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		return res.Header()
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		app := test.App()
+		headers := preflight(app, "http://test.com", "PATCH", "Content-Type, X-Request-ID")
+		assert.Equal(t, "http://test.com", headers.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "PATCH", headers.Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Content-Type,X-Request-Id", headers.Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "true", headers.Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("unknown origin", func(t *testing.T) {
+		app := test.App()
+		headers := preflight(app, "http://evil.com", "POST", "")
+		assert.Empty(t, headers.Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		app := test.App()
+		app.Config().CORSAllowedHeaders = []string{"Authorization"}
+		app.Config().CORSAllowedMethods = []string{"GET", "POST"}
+		app.Config().CORSAllowCredentials = false
+
+		headers := preflight(app, "http://test.com", "POST", "Authorization")
+		assert.Equal(t, "http://test.com", headers.Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Authorization", headers.Get("Access-Control-Allow-Headers"))
+		assert.Empty(t, headers.Get("Access-Control-Allow-Credentials"))
+
+		headers = preflight(app, "http://test.com", "POST", "Content-Type")
+		assert.Empty(t, headers.Get("Access-Control-Allow-Origin"))
+
+		headers = preflight(app, "http://test.com", "DELETE", "")
+		assert.Empty(t, headers.Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -0,0 +1,57 @@
+package csrf
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+// Middleware protects a route that relies on the session cookie as CSRF_PROTECTION describes.
+// Requests without a session cookie have nothing to forge, and are left to the handler. Forged
+// requests are refused with 403 Forbidden.
+func Middleware(app *app.App) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if app.Config().CSRFProtection == "" {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
+			session, err := r.Cookie(cfg.SessionCookieName)
+			if err != nil || session.Value == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			var errs services.FieldErrors
+			switch cfg.CSRFProtection {
+			case "origin":
+				// unlike OriginSecurity, a Referer is not accepted in place of the Origin
+				origin := r.Header.Get("Origin")
+				if origin == "" {
+					errs = services.FieldErrors{{"origin", services.ErrMissing}}
+				} else if route.FindDomain(origin, cfg.ApplicationDomains) == nil {
+					errs = services.FieldErrors{{"origin", services.ErrFailed}}
+				}
+			case "double_submit":
+				token := r.Header.Get(sessions.CSRFHeader)
+				if token == "" {
+					errs = services.FieldErrors{{"csrf", services.ErrMissing}}
+				} else if subtle.ConstantTimeCompare([]byte(token), []byte(sessions.CSRFToken(cfg, session.Value))) != 1 {
+					errs = services.FieldErrors{{"csrf", services.ErrFailed}}
+				}
+			}
+			if errs != nil {
+				handlers.WriteFieldErrors(w, r, http.StatusForbidden, errs)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
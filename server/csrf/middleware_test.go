@@ -0,0 +1,89 @@
+package csrf_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/csrf"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	send := func(app *app.App, session string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("DELETE", "/session", nil)
+		if session != "" {
+			req.AddCookie(&http.Cookie{Name: app.Config().SessionCookieName, Value: session})
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		res := httptest.NewRecorder()
+		csrf.Middleware(app)(ok).ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("without protection", func(t *testing.T) {
+		app := test.App()
+		res := send(app, "session", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("origin", func(t *testing.T) {
+		app := test.App()
+		app.Config().CSRFProtection = "origin"
+
+		res := send(app, "", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "session", map[string]string{"Origin": "http://test.com"})
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "session", map[string]string{"Referer": "http://test.com/account"})
+		assert.Equal(t, http.StatusForbidden, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"origin","message":"MISSING"}]}`, res.Body.String())
+
+		res = send(app, "session", map[string]string{"Origin": "http://evil.com"})
+		assert.Equal(t, http.StatusForbidden, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"origin","message":"FAILED"}]}`, res.Body.String())
+	})
+
+	t.Run("double submit", func(t *testing.T) {
+		app := test.App()
+		app.Config().CSRFProtection = "double_submit"
+
+		login := httptest.NewRecorder()
+		sessions.Set(app.Config(), login, "session", &app.Config().ApplicationDomains[0], false)
+		cookies := login.Result().Cookies()
+		csrfCookie := test.ReadCookie(cookies, "authn-csrf")
+		require.NotNil(t, csrfCookie)
+		assert.False(t, csrfCookie.HttpOnly)
+		assert.Equal(t, csrfCookie.Value, login.Header().Get(sessions.CSRFHeader))
+		token := csrfCookie.Value
+
+		res := send(app, "", nil)
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "session", map[string]string{sessions.CSRFHeader: token})
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "session", nil)
+		assert.Equal(t, http.StatusForbidden, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"csrf","message":"MISSING"}]}`, res.Body.String())
+
+		res = send(app, "other", map[string]string{sessions.CSRFHeader: token})
+		assert.Equal(t, http.StatusForbidden, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"csrf","message":"FAILED"}]}`, res.Body.String())
+
+		logout := httptest.NewRecorder()
+		sessions.Set(app.Config(), logout, "", &app.Config().ApplicationDomains[0], false)
+		csrfCookie = test.ReadCookie(logout.Result().Cookies(), "authn-csrf")
+		require.NotNil(t, csrfCookie)
+		assert.Equal(t, -1, csrfCookie.MaxAge)
+	})
+}
@@ -0,0 +1,385 @@
+// Package graph answers GraphQL queries from admin tooling about accounts, sessions, stats, and the
+// audit log.
+package graph
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
+)
+
+const schema = `
+schema {
+	query: Query
+}
+
+scalar Time
+
+type Query {
+	# An account by ID, including archived accounts. Null when the account does not exist.
+	account(id: Int!): Account
+	# A page of accounts, optionally searching by username.
+	accounts(page: Int = 1, perPage: Int = 25, username: String = ""): AccountPage!
+	# Estimated active accounts. Null when REDIS_URL is not configured.
+	stats: Stats
+	# A page of the audit log, newest first, optionally for one account or action.
+	audit(page: Int = 1, perPage: Int = 25, accountId: Int = 0, action: String = ""): AuditPage!
+}
+
+type Account {
+	id: Int!
+	username: String!
+	aliases: [String!]!
+	locked: Boolean!
+	deleted: Boolean!
+	roles: [String!]!
+	verified: Boolean!
+	totp: Boolean!
+	otpDelivery: Boolean!
+	createdAt: Time!
+	lastLoginAt: Time
+	sessions: [Session!]!
+}
+
+type AccountPage {
+	accounts: [Account!]!
+	page: Int!
+	perPage: Int!
+	total: Int!
+}
+
+type Session {
+	id: String!
+	userAgent: String!
+	createdAt: Time
+	lastSeenAt: Time
+}
+
+type AuditEntry {
+	id: Int!
+	action: String!
+	# 0 when no account was identified
+	accountId: Int!
+	username: String!
+	actor: String!
+	ip: String!
+	userAgent: String!
+	createdAt: Time!
+}
+
+type AuditPage {
+	entries: [AuditEntry!]!
+	page: Int!
+	perPage: Int!
+	total: Int!
+}
+
+type Stats {
+	daily: [Actives!]!
+	weekly: [Actives!]!
+	monthly: [Actives!]!
+}
+
+type Actives {
+	period: String!
+	count: Int!
+}
+`
+
+// maxPerPage limits the size of a page of accounts or audit entries
+const maxPerPage = 100
+
+// maxDepth limits how deeply a query may nest, e.g. accounts → accounts → sessions → id
+const maxDepth = 6
+
+// NewSchema parses the schema with resolvers for the app. Unexpected errors are reported and
+// returned as an internal error.
+func NewSchema(app *app.App) *graphql.Schema {
+	return graphql.MustParseSchema(schema, &query{app: app},
+		graphql.MaxDepth(maxDepth),
+		graphql.PanicHandler(&reporter{app: app}),
+	)
+}
+
+type reporter struct {
+	app *app.App
+}
+
+func (r *reporter) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	err, ok := value.(error)
+	if !ok {
+		err = errors.Errorf("%v", value)
+	}
+	r.app.Reporter.ReportError(err)
+	return gqlerrors.Errorf("internal error")
+}
+
+type query struct {
+	app *app.App
+}
+
+func (q *query) Account(ctx context.Context, args struct{ ID int32 }) *account {
+	found, err := services.AccountGetter(q.app.AccountStoreFor(ctx), int(args.ID))
+	if err != nil {
+		if _, ok := err.(services.FieldErrors); ok {
+			return nil
+		}
+		panic(err)
+	}
+	return &account{app: q.app, Account: found}
+}
+
+func (q *query) Accounts(ctx context.Context, args struct {
+	Page     int32
+	PerPage  int32
+	Username string
+}) *accountPage {
+	page, perPage := paginate(args.Page, args.PerPage)
+
+	found, err := q.app.AccountStoreFor(ctx).List(args.Username, int(perPage), int((page-1)*perPage))
+	if err != nil {
+		panic(errors.Wrap(err, "List"))
+	}
+	total, err := q.app.AccountStoreFor(ctx).Count(args.Username)
+	if err != nil {
+		panic(errors.Wrap(err, "Count"))
+	}
+
+	accounts := []*account{}
+	for _, a := range found {
+		accounts = append(accounts, &account{app: q.app, Account: a})
+	}
+	return &accountPage{accounts: accounts, page: page, perPage: perPage, total: int32(total)}
+}
+
+func (q *query) Audit(args struct {
+	Page      int32
+	PerPage   int32
+	AccountID int32
+	Action    string
+}) *auditPage {
+	page, perPage := paginate(args.Page, args.PerPage)
+	filter := models.AuditFilter{AccountID: int(args.AccountID), Action: args.Action}
+
+	found, err := q.app.AuditLog.List(filter, int(perPage), int((page-1)*perPage))
+	if err != nil {
+		panic(errors.Wrap(err, "List"))
+	}
+	total, err := q.app.AuditLog.Count(filter)
+	if err != nil {
+		panic(errors.Wrap(err, "Count"))
+	}
+
+	entries := []*auditEntry{}
+	for _, e := range found {
+		entries = append(entries, &auditEntry{e})
+	}
+	return &auditPage{entries: entries, page: page, perPage: perPage, total: int32(total)}
+}
+
+func (q *query) Stats() *stats {
+	if q.app.Actives == nil {
+		return nil
+	}
+	return &stats{app: q.app}
+}
+
+type account struct {
+	app *app.App
+	*models.Account
+}
+
+func (a *account) ID() int32 {
+	return int32(a.Account.ID)
+}
+
+func (a *account) Username() string {
+	return a.Account.Username
+}
+
+func (a *account) Aliases(ctx context.Context) []string {
+	found, err := a.app.AccountStoreFor(ctx).GetAliases(a.Account.ID)
+	if err != nil {
+		panic(errors.Wrap(err, "GetAliases"))
+	}
+	aliases := []string{}
+	for _, alias := range found {
+		aliases = append(aliases, alias.Username)
+	}
+	return aliases
+}
+
+func (a *account) Locked() bool {
+	return a.Account.Locked
+}
+
+func (a *account) Deleted() bool {
+	return a.Account.Archived()
+}
+
+func (a *account) Roles() []string {
+	if a.Account.Roles == nil {
+		return []string{}
+	}
+	return a.Account.Roles
+}
+
+func (a *account) Totp() bool {
+	return a.Account.TOTPEnabled()
+}
+
+func (a *account) OtpDelivery() bool {
+	return a.Account.OTPDeliveryEnabled()
+}
+
+func (a *account) CreatedAt() graphql.Time {
+	return graphql.Time{Time: a.Account.CreatedAt}
+}
+
+func (a *account) LastLoginAt() *graphql.Time {
+	return timeOf(a.Account.LastLoginAt)
+}
+
+func (a *account) Sessions(ctx context.Context) []*session {
+	found, err := a.app.RefreshTokenStoreFor(ctx).FindSessions(a.Account.ID)
+	if err != nil {
+		panic(errors.Wrap(err, "FindSessions"))
+	}
+	sessions := []*session{}
+	for _, s := range found {
+		sessions = append(sessions, &session{s})
+	}
+	return sessions
+}
+
+type accountPage struct {
+	accounts []*account
+	page     int32
+	perPage  int32
+	total    int32
+}
+
+func (p *accountPage) Accounts() []*account { return p.accounts }
+func (p *accountPage) Page() int32          { return p.page }
+func (p *accountPage) PerPage() int32       { return p.perPage }
+func (p *accountPage) Total() int32         { return p.total }
+
+type session struct {
+	models.Session
+}
+
+func (s *session) ID() string {
+	return s.Token.ID()
+}
+
+func (s *session) UserAgent() string {
+	return s.Session.UserAgent
+}
+
+func (s *session) CreatedAt() *graphql.Time {
+	return timeOf(s.Session.CreatedAt)
+}
+
+func (s *session) LastSeenAt() *graphql.Time {
+	return timeOf(s.Session.LastSeenAt)
+}
+
+type auditEntry struct {
+	*models.AuditEntry
+}
+
+func (e *auditEntry) ID() int32         { return int32(e.AuditEntry.ID) }
+func (e *auditEntry) Action() string    { return e.AuditEntry.Action }
+func (e *auditEntry) AccountID() int32  { return int32(e.AuditEntry.AccountID) }
+func (e *auditEntry) Username() string  { return e.AuditEntry.Username }
+func (e *auditEntry) Actor() string     { return e.AuditEntry.Actor }
+func (e *auditEntry) IP() string        { return e.AuditEntry.IP }
+func (e *auditEntry) UserAgent() string { return e.AuditEntry.UserAgent }
+func (e *auditEntry) CreatedAt() graphql.Time {
+	return graphql.Time{Time: e.AuditEntry.CreatedAt}
+}
+
+type auditPage struct {
+	entries []*auditEntry
+	page    int32
+	perPage int32
+	total   int32
+}
+
+func (p *auditPage) Entries() []*auditEntry { return p.entries }
+func (p *auditPage) Page() int32            { return p.page }
+func (p *auditPage) PerPage() int32         { return p.perPage }
+func (p *auditPage) Total() int32           { return p.total }
+
+type stats struct {
+	app *app.App
+}
+
+func (s *stats) Daily() []*actives {
+	found, err := s.app.Actives.ActivesByDay()
+	if err != nil {
+		panic(errors.Wrap(err, "ActivesByDay"))
+	}
+	return activesOf(found)
+}
+
+func (s *stats) Weekly() []*actives {
+	found, err := s.app.Actives.ActivesByWeek()
+	if err != nil {
+		panic(errors.Wrap(err, "ActivesByWeek"))
+	}
+	return activesOf(found)
+}
+
+func (s *stats) Monthly() []*actives {
+	found, err := s.app.Actives.ActivesByMonth()
+	if err != nil {
+		panic(errors.Wrap(err, "ActivesByMonth"))
+	}
+	return activesOf(found)
+}
+
+type actives struct {
+	period string
+	count  int32
+}
+
+func (a *actives) Period() string { return a.period }
+func (a *actives) Count() int32   { return a.count }
+
+// activesOf lists counts in order of their period, which sorts as a string
+func activesOf(counts map[string]int) []*actives {
+	list := []*actives{}
+	for period, count := range counts {
+		list = append(list, &actives{period: period, count: int32(count)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].period < list[j].period })
+	return list
+}
+
+// paginate defaults and limits the page arguments
+func paginate(page int32, perPage int32) (int32, int32) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 25
+	} else if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage
+}
+
+func timeOf(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
@@ -11,21 +11,23 @@ import (
 
 func DeleteAccount(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
-			WriteNotFound(w, "account")
+			WriteNotFound(w, r, "account")
 			return
 		}
 
-		err = services.AccountArchiver(app.AccountStore, app.RefreshTokenStore, id)
+		err = services.AccountArchiver(r.Context(), app.AccountStoreFor(r.Context()), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, cfg, app.Reporter, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
-				WriteNotFound(w, "account")
+				WriteNotFound(w, r, "account")
 				return
 			}
 
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountArchived, id)
 
 		w.WriteHeader(http.StatusOK)
 	}
@@ -0,0 +1,44 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccountAlias(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	account, err := app.AccountStore.Create("primary@test.com", []byte("bar"))
+	require.NoError(t, err)
+	err = app.AccountStore.AddAlias(account.ID, "alias@test.com")
+	require.NoError(t, err)
+
+	t.Run("unknown alias", func(t *testing.T) {
+		res, err := client.Delete(fmt.Sprintf("/accounts/%v/aliases?%v", account.ID, url.Values{"username": []string{"unknown@test.com"}}.Encode()))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"alias", services.ErrNotFound}})
+	})
+
+	t.Run("known alias", func(t *testing.T) {
+		res, err := client.Delete(fmt.Sprintf("/accounts/%v/aliases?%v", account.ID, url.Values{"username": []string{"alias@test.com"}}.Encode()))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.FindByUsername("alias@test.com")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
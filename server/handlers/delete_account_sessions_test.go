@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccountSessions(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Delete("/accounts/999999/sessions")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("logged in account", func(t *testing.T) {
+		account, err := app.AccountStore.Create("compromised@test.com", []byte("bar"))
+		require.NoError(t, err)
+		_, err = app.RefreshTokenStore.Create(account.ID)
+		require.NoError(t, err)
+		_, err = app.RefreshTokenStore.Create(account.ID)
+		require.NoError(t, err)
+
+		res, err := client.Delete(fmt.Sprintf("/accounts/%v/sessions", account.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		tokens, err := app.RefreshTokenStore.FindAll(account.ID)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
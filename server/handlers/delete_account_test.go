@@ -16,7 +16,7 @@ func TestDeleteAccount(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("unknown account", func(t *testing.T) {
 		res, err := client.Delete("/accounts/999999")
@@ -0,0 +1,43 @@
+package handlers_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccountTOTP(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Delete("/accounts/999999/totp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("enrolled account", func(t *testing.T) {
+		account, err := app.AccountStore.Create("enrolled@keratin.tech", []byte("bar"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetTOTPSecret(account.ID, []byte("encrypted"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.EnableTOTP(account.ID)
+		require.NoError(t, err)
+
+		res, err := client.Delete("/accounts/" + strconv.Itoa(account.ID) + "/totp")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.TOTPEnabled())
+	})
+}
@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+)
+
+func DeleteMaintenance(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := app.Maintenance.Disable()
+		if err != nil {
+			panic(errors.Wrap(err, "Disable"))
+		}
+
+		writeMaintenance(app, app.ConfigFor(r.Context()), w)
+	}
+}
@@ -0,0 +1,33 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteMaintenance(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	require.NoError(t, app.Maintenance.Enable(time.Now().Add(time.Hour)))
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+	res, err := client.Delete("/maintenance")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	status := maintenanceStatus{}
+	require.NoError(t, test.ExtractResult(res, &status))
+	assert.False(t, status.Enabled)
+
+	until, err := app.Maintenance.Until()
+	require.NoError(t, err)
+	assert.True(t, until.IsZero())
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func DeleteOwnAccount(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		// check for valid session with live token
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var params struct{ Password string }
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+
+		err := services.AccountDeleter(r.Context(), app.AccountStoreFor(r.Context()), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, cfg, app.Reporter, accountID, params.Password)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, r, fe)
+				return
+			}
+
+			panic(err)
+		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountArchived, accountID)
+		audit(app, r, models.AuditEntry{Action: services.AuditAccountDeleted, AccountID: accountID, Actor: models.ActorAccount})
+
+		sessions.Set(cfg, w, "", route.MatchedDomain(r), false)
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
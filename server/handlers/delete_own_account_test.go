@@ -0,0 +1,63 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestDeleteOwnAccount(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), 4)
+	require.NoError(t, err)
+	account, err := app.AccountStore.Create("deleted", hash)
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.DeleteJSON("/account", `{"password":"secret"}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("with the wrong password", func(t *testing.T) {
+		res, err := client.WithCookie(session).DeleteJSON("/account", `{"password":"wrong"}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"credentials", services.ErrFailed}})
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.Archived())
+	})
+
+	t.Run("with the password", func(t *testing.T) {
+		res, err := client.WithCookie(session).DeleteJSON("/account", `{"password":"secret"}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.Archived())
+
+		cookie := test.ReadCookie(res.Cookies(), app.Config().SessionCookieName)
+		require.NotNil(t, cookie)
+		assert.Empty(t, cookie.Value)
+	})
+
+	t.Run("after deletion", func(t *testing.T) {
+		res, err := client.WithCookie(session).DeleteJSON("/account", `{"password":"secret"}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
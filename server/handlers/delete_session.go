@@ -5,17 +5,23 @@ import (
 
 	"github.com/keratin/authn-server/server/sessions"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
 )
 
 func DeleteSession(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		err := services.SessionEnder(app.RefreshTokenStore, sessions.GetRefreshToken(r))
+		cfg := app.ConfigFor(r.Context())
+		if accountID := sessions.GetAccountID(r); accountID != 0 {
+			audit(app, r, models.AuditEntry{Action: services.AuditLogout, AccountID: accountID, Actor: models.ActorAccount})
+		}
+		err := services.SessionEnder(r.Context(), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, cfg, app.Reporter, sessions.GetRefreshToken(r))
 		if err != nil {
 			app.Reporter.ReportRequestError(err, r)
 		}
 
-		sessions.Set(app.Config, w, "")
+		sessions.Set(cfg, w, "", route.MatchedDomain(r), false)
 
 		w.WriteHeader(http.StatusOK)
 	}
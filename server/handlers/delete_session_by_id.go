@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func DeleteSessionByID(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		// check for valid session with live token
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		err := services.SessionRevoker(r.Context(), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, cfg, app.Reporter, accountID, mux.Vars(r)["id"])
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, r, "session")
+				return
+			}
+
+			panic(err)
+		}
+		audit(app, r, models.AuditEntry{Action: services.AuditSessionRevoked, AccountID: accountID, Actor: models.ActorAccount})
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,53 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteSessionByID(t *testing.T) {
+	testApp := test.App()
+	server := test.Server(testApp)
+	defer server.Close()
+
+	accountID := 8642
+	session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
+	other := refreshTokenOf(t, testApp.Config(), test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID))
+	stranger := refreshTokenOf(t, testApp.Config(), test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 9753))
+
+	t.Run("session of account", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete("/sessions/" + other.ID())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		id, err := testApp.RefreshTokenStore.Find(other)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+	})
+
+	t.Run("session of another account", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Delete("/sessions/" + stranger.ID())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"session", services.ErrNotFound}})
+
+		id, err := testApp.RefreshTokenStore.Find(stranger)
+		require.NoError(t, err)
+		assert.Equal(t, 9753, id)
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0])
+		res, err := client.Delete("/sessions/" + other.ID())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
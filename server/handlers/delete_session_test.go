@@ -19,16 +19,16 @@ func TestDeleteSessionSuccess(t *testing.T) {
 	defer server.Close()
 
 	accountID := 514628
-	session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+	session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
 
 	// token exists
-	claims, err := sessions.Parse(session.Value, testApp.Config)
+	claims, err := sessions.Parse(session.Value, testApp.Config())
 	require.NoError(t, err)
 	id, err := testApp.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
 	require.NoError(t, err)
 	assert.NotEmpty(t, id)
 
-	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0]).WithCookie(session)
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(session)
 	res, err := client.Delete("/session")
 	require.NoError(t, err)
 
@@ -47,14 +47,14 @@ func TestDeleteSessionFailure(t *testing.T) {
 	defer server.Close()
 
 	badCfg := &app.Config{
-		AuthNURL:           testApp.Config.AuthNURL,
-		SessionCookieName:  testApp.Config.SessionCookieName,
+		AuthNURL:           testApp.Config().AuthNURL,
+		SessionCookieName:  testApp.Config().SessionCookieName,
 		SessionSigningKey:  []byte("wrong"),
-		ApplicationDomains: testApp.Config.ApplicationDomains,
+		ApplicationDomains: testApp.Config().ApplicationDomains,
 	}
 	session := test.CreateSession(testApp.RefreshTokenStore, badCfg, 123)
 
-	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0]).WithCookie(session)
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(session)
 	res, err := client.Delete("/session")
 	require.NoError(t, err)
 
@@ -67,7 +67,7 @@ func TestDeleteSessionWithoutSession(t *testing.T) {
 	server := test.Server(testApp)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0])
 	res, err := client.Delete("/session")
 	require.NoError(t, err)
 
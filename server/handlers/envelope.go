@@ -2,12 +2,21 @@ package handlers
 
 import (
 	"encoding/json"
-	"github.com/keratin/authn-server/lib/parse"
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
 )
 
+// MediaTypeV2 may be sent in the Accept header to receive version 2 of the response format.
+// Clients that do not ask for it receive version 1, which existing client libraries understand.
+const MediaTypeV2 = "application/vnd.keratin.authn.v2+json"
+
+// problemMediaType is the Content-Type of RFC 7807 problem details
+const problemMediaType = "application/problem+json"
+
 type ServiceData struct {
 	Result interface{} `json:"result"`
 }
@@ -20,41 +29,91 @@ type RequestError struct {
 	Error string `json:"error"`
 }
 
+// Problem describes a failed action in version 2 of the response format, as RFC 7807 problem
+// details. Errors keeps the field errors of version 1.
+type Problem struct {
+	Type   string               `json:"type"`
+	Title  string               `json:"title"`
+	Status int                  `json:"status"`
+	Detail string               `json:"detail,omitempty"`
+	Errors services.FieldErrors `json:"errors,omitempty"`
+}
+
+// APIVersion returns the version of the response format that the request accepts
+func APIVersion(r *http.Request) int {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, t := range strings.Split(accept, ",") {
+			mediaType, _, err := mime.ParseMediaType(t)
+			if err == nil && mediaType == MediaTypeV2 {
+				return 2
+			}
+		}
+	}
+	return 1
+}
+
 func WriteData(w http.ResponseWriter, httpCode int, d interface{}) {
 	WriteJSON(w, httpCode, ServiceData{Result: d})
 }
 
-func WriteErrors(w http.ResponseWriter, err error) {
+func WriteErrors(w http.ResponseWriter, r *http.Request, err error) {
 	switch err.(type) {
 	case services.FieldErrors:
-		WriteJSON(w, http.StatusUnprocessableEntity, ServiceErrors{Errors: err.(services.FieldErrors)})
+		WriteFieldErrors(w, r, http.StatusUnprocessableEntity, err.(services.FieldErrors))
 	case parse.Error:
-		writeParseErrors(w, err.(parse.Error))
+		writeParseErrors(w, r, err.(parse.Error))
 	default:
-		WriteJSON(w, http.StatusInternalServerError, RequestError{Error:err.Error()})
+		// unexpected errors are reported by the PanicHandler, instead of being described to the client
+		panic(err)
 	}
 }
 
-func writeParseErrors(w http.ResponseWriter, err parse.Error) {
-	switch err.Code {
-	case parse.UnsupportedMediaType:
-		WriteJSON(w, http.StatusUnsupportedMediaType, RequestError{Error:err.Message})
-	default:
-		WriteJSON(w, http.StatusBadRequest, RequestError{Error:err.Message})
+// WriteFieldErrors describes field errors in the response format that the request accepts
+func WriteFieldErrors(w http.ResponseWriter, r *http.Request, httpCode int, errs services.FieldErrors) {
+	w.Header().Add("Vary", "Accept")
+	if APIVersion(r) < 2 {
+		WriteJSON(w, httpCode, ServiceErrors{Errors: errs})
+		return
+	}
+	writeProblem(w, Problem{Status: httpCode, Errors: errs})
+}
+
+func writeParseErrors(w http.ResponseWriter, r *http.Request, err parse.Error) {
+	httpCode := http.StatusBadRequest
+	if err.Code == parse.UnsupportedMediaType {
+		httpCode = http.StatusUnsupportedMediaType
+	}
+
+	w.Header().Add("Vary", "Accept")
+	if APIVersion(r) < 2 {
+		WriteJSON(w, httpCode, RequestError{Error: err.Message})
+		return
 	}
+	writeProblem(w, Problem{Status: httpCode, Detail: err.Message})
 }
 
-func WriteNotFound(w http.ResponseWriter, resource string) {
-	WriteJSON(w, http.StatusNotFound, ServiceErrors{Errors: services.FieldErrors{{resource, services.ErrNotFound}}})
+func WriteNotFound(w http.ResponseWriter, r *http.Request, resource string) {
+	WriteFieldErrors(w, r, http.StatusNotFound, services.FieldErrors{{resource, services.ErrNotFound}})
 }
 
 func WriteJSON(w http.ResponseWriter, httpCode int, d interface{}) {
+	writeJSON(w, "application/json", httpCode, d)
+}
+
+// writeProblem fills in the problem's type and title from its status
+func writeProblem(w http.ResponseWriter, p Problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	writeJSON(w, problemMediaType, p.Status, p)
+}
+
+func writeJSON(w http.ResponseWriter, contentType string, httpCode int, d interface{}) {
 	j, err := json.Marshal(d)
 	if err != nil {
 		panic(err)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(httpCode)
 	w.Write(j)
 }
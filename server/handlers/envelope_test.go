@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/ops"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingReporter struct {
+	errors []error
+}
+
+func (r *recordingReporter) ReportError(err error) {
+	r.errors = append(r.errors, err)
+}
+
+func (r *recordingReporter) ReportRequestError(err error, req *http.Request) {
+	r.errors = append(r.errors, err)
+}
+
+func TestWriteErrors(t *testing.T) {
+	serve := func(err error, accept ...string) (*httptest.ResponseRecorder, *recordingReporter) {
+		reporter := &recordingReporter{}
+		h := ops.PanicHandler(reporter, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlers.WriteErrors(w, r, err)
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, a := range accept {
+			req.Header.Add("Accept", a)
+		}
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+		return res, reporter
+	}
+
+	t.Run("field errors", func(t *testing.T) {
+		res, reporter := serve(services.FieldErrors{{"account", services.ErrNotFound}})
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		assert.Empty(t, reporter.errors)
+		assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"errors":[{"field":"account","message":"NOT_FOUND"}]}`, res.Body.String())
+	})
+
+	t.Run("field errors as problem details", func(t *testing.T) {
+		res, _ := serve(services.FieldErrors{{"account", services.ErrNotFound}}, "text/html, application/vnd.keratin.authn.v2+json;q=0.9")
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		assert.Equal(t, "application/problem+json", res.Header().Get("Content-Type"))
+		assert.Equal(t, "Accept", res.Header().Get("Vary"))
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Unprocessable Entity",
+			"status": 422,
+			"errors": [{"field":"account","message":"NOT_FOUND"}]
+		}`, res.Body.String())
+	})
+
+	t.Run("parse errors as problem details", func(t *testing.T) {
+		res, _ := serve(parse.Error{Code: parse.UnsupportedMediaType, Message: "unsupported"}, handlers.MediaTypeV2)
+		assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+		assert.JSONEq(t, `{
+			"type": "about:blank",
+			"title": "Unsupported Media Type",
+			"status": 415,
+			"detail": "unsupported"
+		}`, res.Body.String())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		err := errors.New("connection refused")
+		res, reporter := serve(err)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+		assert.NotContains(t, res.Body.String(), "connection refused")
+		assert.Equal(t, []error{err}, reporter.errors)
+	})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
+)
+
+// GetAccessTokenRevoked checks whether an identity token's jti has been revoked.
+func GetAccessTokenRevoked(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jti := r.FormValue("jti")
+		if jti == "" {
+			WriteErrors(w, r, services.FieldErrors{{"jti", services.ErrMissing}})
+			return
+		}
+
+		denied, err := app.TokenDenylist.IsDenied(jti)
+		if err != nil {
+			panic(errors.Wrap(err, "IsDenied"))
+		}
+
+		WriteData(w, http.StatusOK, map[string]bool{
+			"revoked": denied,
+		})
+	}
+}
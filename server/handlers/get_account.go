@@ -6,32 +6,65 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
 )
 
 func GetAccount(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
-			WriteNotFound(w, "account")
+			WriteNotFound(w, r, "account")
 			return
 		}
 
-		account, err := services.AccountGetter(app.AccountStore, id)
+		account, err := services.AccountGetter(app.AccountStoreFor(r.Context()), id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
-				WriteNotFound(w, "account")
+				WriteNotFound(w, r, "account")
 				return
 			}
 
 			panic(err)
 		}
 
+		found, err := app.AccountStoreFor(r.Context()).GetAliases(account.ID)
+		if err != nil {
+			panic(errors.Wrap(err, "GetAliases"))
+		}
+		aliases := []string{}
+		for _, alias := range found {
+			aliases = append(aliases, alias.Username)
+		}
+
 		WriteData(w, http.StatusOK, map[string]interface{}{
-			"id":       account.ID,
-			"username": account.Username,
-			"locked":   account.Locked,
-			"deleted":  account.DeletedAt != nil,
+			"id":           account.ID,
+			"username":     account.Username,
+			"aliases":      aliases,
+			"locked":       account.Locked,
+			"deleted":      account.DeletedAt != nil,
+			"roles":        roles(account.Roles),
+			"metadata":     metadata(account.Metadata),
+			"verified":     account.Verified(),
+			"totp":         account.TOTPEnabled(),
+			"otp_delivery": account.OTPDeliveryEnabled(),
 		})
 	}
 }
+
+// roles are rendered as an empty list when the account has none
+func roles(r models.Roles) []string {
+	if r == nil {
+		return []string{}
+	}
+	return r
+}
+
+// metadata is rendered as an empty object when the account has none
+func metadata(m models.Metadata) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
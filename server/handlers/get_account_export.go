@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
+)
+
+// GetAccountExport returns the data kept for an account, as for a data subject's access request.
+// Credentials like the password hash, TOTP secret, and OAuth access tokens are not included.
+func GetAccountExport(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, r, "account")
+			return
+		}
+
+		account, err := services.AccountGetter(app.AccountStoreFor(r.Context()), id)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, r, "account")
+				return
+			}
+
+			panic(err)
+		}
+
+		oauthAccounts, err := app.AccountStoreFor(r.Context()).GetOauthAccounts(account.ID)
+		if err != nil {
+			panic(errors.Wrap(err, "GetOauthAccounts"))
+		}
+		identities := []map[string]interface{}{}
+		for _, oauthAccount := range oauthAccounts {
+			identities = append(identities, map[string]interface{}{
+				"provider":    oauthAccount.Provider,
+				"provider_id": oauthAccount.ProviderID,
+				"created_at":  oauthAccount.CreatedAt,
+				"updated_at":  oauthAccount.UpdatedAt,
+			})
+		}
+
+		foundAliases, err := app.AccountStoreFor(r.Context()).GetAliases(account.ID)
+		if err != nil {
+			panic(errors.Wrap(err, "GetAliases"))
+		}
+		aliases := []map[string]interface{}{}
+		for _, alias := range foundAliases {
+			aliases = append(aliases, map[string]interface{}{
+				"username":    alias.Username,
+				"verified_at": alias.VerifiedAt,
+				"created_at":  alias.CreatedAt,
+			})
+		}
+
+		found, err := app.RefreshTokenStoreFor(r.Context()).FindSessions(account.ID)
+		if err != nil {
+			panic(errors.Wrap(err, "FindSessions"))
+		}
+		sessions := []map[string]interface{}{}
+		for _, session := range found {
+			sessions = append(sessions, map[string]interface{}{
+				"id":           session.Token.ID(),
+				"created_at":   session.CreatedAt,
+				"last_seen_at": session.LastSeenAt,
+				"user_agent":   session.UserAgent,
+			})
+		}
+
+		filter := models.AuditFilter{AccountID: account.ID}
+		total, err := app.AuditLog.Count(filter)
+		if err != nil {
+			panic(errors.Wrap(err, "Count"))
+		}
+		foundEntries, err := app.AuditLog.List(filter, total, 0)
+		if err != nil {
+			panic(errors.Wrap(err, "List"))
+		}
+		audit := []map[string]interface{}{}
+		for _, entry := range foundEntries {
+			audit = append(audit, map[string]interface{}{
+				"action":     entry.Action,
+				"username":   entry.Username,
+				"actor":      entry.Actor,
+				"ip":         entry.IP,
+				"user_agent": entry.UserAgent,
+				"created_at": entry.CreatedAt,
+			})
+		}
+
+		WriteData(w, http.StatusOK, map[string]interface{}{
+			"id":                      account.ID,
+			"username":                account.Username,
+			"locked":                  account.Locked,
+			"require_new_password":    account.RequireNewPassword,
+			"roles":                   roles(account.Roles),
+			"metadata":                metadata(account.Metadata),
+			"password_changed_at":     account.PasswordChangedAt,
+			"last_login_at":           account.LastLoginAt,
+			"email_verified_at":       account.EmailVerifiedAt,
+			"totp_enabled_at":         account.TOTPEnabledAt,
+			"otp_delivery_enabled_at": account.OTPDeliveryEnabledAt,
+			"created_at":              account.CreatedAt,
+			"updated_at":              account.UpdatedAt,
+			"deleted_at":              account.DeletedAt,
+			"aliases":                 aliases,
+			"oauth_accounts":          identities,
+			"sessions":                sessions,
+			"audit":                   audit,
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountExport(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Get("/accounts/999999/export")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid account", func(t *testing.T) {
+		account, err := app.AccountStore.Create("export@test.com", []byte("bar"))
+		require.NoError(t, err)
+		err = app.AccountStore.AddOauthAccount(account.ID, "github", "12345", "SECRET_TOKEN")
+		require.NoError(t, err)
+		err = app.AccountStore.AddAlias(account.ID, "alias@test.com")
+		require.NoError(t, err)
+		token, err := app.RefreshTokenStore.Create(account.ID)
+		require.NoError(t, err)
+		err = app.RefreshTokenStore.SetUserAgent(token, "Test Browser")
+		require.NoError(t, err)
+		err = app.AuditLog.Record(&models.AuditEntry{Action: "login", AccountID: account.ID, Actor: models.ActorAccount, IP: "127.0.0.1"})
+		require.NoError(t, err)
+
+		res, err := client.Get(fmt.Sprintf("/accounts/%v/export", account.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		body := test.ReadBody(res)
+		assert.NotContains(t, string(body), "SECRET_TOKEN")
+		assert.NotContains(t, string(body), `"password"`)
+
+		export := struct {
+			ID       int    `json:"id"`
+			Username string `json:"username"`
+			Aliases  []struct {
+				Username string `json:"username"`
+			} `json:"aliases"`
+			OauthAccounts []struct {
+				Provider   string `json:"provider"`
+				ProviderID string `json:"provider_id"`
+			} `json:"oauth_accounts"`
+			Sessions []struct {
+				ID        string `json:"id"`
+				UserAgent string `json:"user_agent"`
+			} `json:"sessions"`
+			Audit []struct {
+				Action string `json:"action"`
+				IP     string `json:"ip"`
+			} `json:"audit"`
+		}{}
+		err = json.Unmarshal(body, &handlers.ServiceData{&export})
+		require.NoError(t, err)
+		assert.Equal(t, account.ID, export.ID)
+		assert.Equal(t, "export@test.com", export.Username)
+		if assert.Len(t, export.Aliases, 1) {
+			assert.Equal(t, "alias@test.com", export.Aliases[0].Username)
+		}
+		if assert.Len(t, export.OauthAccounts, 1) {
+			assert.Equal(t, "github", export.OauthAccounts[0].Provider)
+			assert.Equal(t, "12345", export.OauthAccounts[0].ProviderID)
+		}
+		if assert.Len(t, export.Sessions, 1) {
+			assert.Equal(t, token.ID(), export.Sessions[0].ID)
+			assert.Equal(t, "Test Browser", export.Sessions[0].UserAgent)
+		}
+		if assert.Len(t, export.Audit, 1) {
+			assert.Equal(t, "login", export.Audit[0].Action)
+			assert.Equal(t, "127.0.0.1", export.Audit[0].IP)
+		}
+	})
+}
@@ -17,7 +17,7 @@ func TestGetAccount(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("unknown account", func(t *testing.T) {
 		res, err := client.Get("/accounts/999999")
@@ -46,16 +46,24 @@ func assertGetAccountResponse(t *testing.T, res *http.Response, acc *models.Acco
 	// check that the response contains the expected json
 	assert.Equal(t, []string{"application/json"}, res.Header["Content-Type"])
 	responseData := struct {
-		ID       int    `json:"id"`
-		Username string `json:"username"`
-		Locked   bool   `json:"locked"`
-		Deleted  bool   `json:"deleted_at"`
+		ID       int                    `json:"id"`
+		Username string                 `json:"username"`
+		Aliases  []string               `json:"aliases"`
+		Locked   bool                   `json:"locked"`
+		Deleted  bool                   `json:"deleted_at"`
+		Roles    []string               `json:"roles"`
+		Metadata map[string]interface{} `json:"metadata"`
+		Verified bool                   `json:"verified"`
 	}{}
 	err := test.ExtractResult(res, &responseData)
 	assert.NoError(t, err)
 
 	assert.Equal(t, acc.Username, responseData.Username)
 	assert.Equal(t, acc.ID, responseData.ID)
+	assert.Equal(t, []string{}, responseData.Aliases)
 	assert.Equal(t, false, responseData.Locked)
 	assert.Equal(t, false, responseData.Deleted)
+	assert.Equal(t, []string{}, responseData.Roles)
+	assert.Equal(t, map[string]interface{}{}, responseData.Metadata)
+	assert.Equal(t, false, responseData.Verified)
 }
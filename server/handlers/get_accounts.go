@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+)
+
+// maxPerPage limits the size of a page of accounts
+const maxPerPage = 100
+
+// GetAccounts lists account summaries a page at a time, optionally searching by username.
+func GetAccounts(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.FormValue("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		perPage, err := strconv.Atoi(r.FormValue("per_page"))
+		if err != nil || perPage < 1 {
+			perPage = 25
+		} else if perPage > maxPerPage {
+			perPage = maxPerPage
+		}
+		username := r.FormValue("username")
+
+		found, err := app.AccountStoreFor(r.Context()).List(username, perPage, (page-1)*perPage)
+		if err != nil {
+			panic(errors.Wrap(err, "List"))
+		}
+		total, err := app.AccountStoreFor(r.Context()).Count(username)
+		if err != nil {
+			panic(errors.Wrap(err, "Count"))
+		}
+
+		accounts := []map[string]interface{}{}
+		for _, account := range found {
+			accounts = append(accounts, map[string]interface{}{
+				"id":            account.ID,
+				"username":      account.Username,
+				"locked":        account.Locked,
+				"roles":         roles(account.Roles),
+				"verified":      account.Verified(),
+				"created_at":    account.CreatedAt,
+				"last_login_at": account.LastLoginAt,
+			})
+		}
+
+		WriteData(w, http.StatusOK, map[string]interface{}{
+			"accounts": accounts,
+			"page":     page,
+			"per_page": perPage,
+			"total":    total,
+		})
+	}
+}
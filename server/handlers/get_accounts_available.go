@@ -9,7 +9,7 @@ import (
 
 func GetAccountsAvailable(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		account, err := app.AccountStore.FindByUsername(r.FormValue("username"))
+		account, err := app.AccountStoreFor(r.Context()).FindByUsername(r.FormValue("username"))
 		if err != nil {
 			panic(err)
 		}
@@ -17,7 +17,7 @@ func GetAccountsAvailable(app *app.App) http.HandlerFunc {
 		if account == nil {
 			WriteData(w, http.StatusOK, true)
 		} else {
-			WriteErrors(w, services.FieldErrors{{"username", services.ErrTaken}})
+			WriteErrors(w, r, services.FieldErrors{{"username", services.ErrTaken}})
 		}
 	}
 }
@@ -18,7 +18,7 @@ func TestGetAccountsAvailable(t *testing.T) {
 	account, err := app.AccountStore.Create("existing@test.com", []byte("bar"))
 	require.NoError(t, err)
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 
 	t.Run("known username", func(t *testing.T) {
 		res, err := client.Get("/accounts/available?username=" + account.Username)
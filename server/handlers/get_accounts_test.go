@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccounts(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	for _, username := range []string{"alice@test.com", "bob@test.com", "carol@test.com"} {
+		_, err := app.AccountStore.Create(username, []byte("bar"))
+		require.NoError(t, err)
+	}
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	type listing struct {
+		Accounts []struct {
+			ID       int    `json:"id"`
+			Username string `json:"username"`
+		} `json:"accounts"`
+		Page    int `json:"page"`
+		PerPage int `json:"per_page"`
+		Total   int `json:"total"`
+	}
+	list := func(query string) listing {
+		res, err := client.Get("/accounts" + query)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		found := listing{}
+		err = test.ExtractResult(res, &found)
+		require.NoError(t, err)
+		return found
+	}
+
+	t.Run("first page", func(t *testing.T) {
+		found := list("")
+		assert.Equal(t, 1, found.Page)
+		assert.Equal(t, 25, found.PerPage)
+		assert.Equal(t, 3, found.Total)
+		assert.Len(t, found.Accounts, 3)
+	})
+
+	t.Run("later page", func(t *testing.T) {
+		found := list("?page=2&per_page=2")
+		assert.Equal(t, 3, found.Total)
+		if assert.Len(t, found.Accounts, 1) {
+			assert.Equal(t, "carol@test.com", found.Accounts[0].Username)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		found := list("?username=bob")
+		assert.Equal(t, 1, found.Total)
+		if assert.Len(t, found.Accounts, 1) {
+			assert.Equal(t, "bob@test.com", found.Accounts[0].Username)
+		}
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).Get("/accounts")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
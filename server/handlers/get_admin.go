@@ -0,0 +1,24 @@
+package handlers
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/views"
+)
+
+// GetAdmin serves the dashboard, which reads and updates accounts through the private API with the
+// browser's Basic Auth credentials.
+func GetAdmin(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		views.Dashboard(&buf)
+
+		w.Header().Set("Content-Type", "text/html")
+		// replaces the default policy, and is never framed
+		w.Header().Set("Content-Security-Policy", "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'; frame-ancestors 'none'")
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+	}
+}
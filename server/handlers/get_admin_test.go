@@ -0,0 +1,37 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAdmin(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	t.Run("without credentials", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).Get("/admin")
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("with credentials", func(t *testing.T) {
+		client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+		res, err := client.Get("/admin")
+		require.NoError(t, err)
+		body := test.ReadBody(res)
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"text/html"}, res.Header["Content-Type"])
+		assert.Equal(t, "DENY", res.Header.Get("X-Frame-Options"))
+		assert.Contains(t, string(body), "AuthN Dashboard")
+	})
+}
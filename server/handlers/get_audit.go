@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/pkg/errors"
+)
+
+// GetAudit lists audit log entries a page at a time, newest first, optionally for one account or
+// action.
+func GetAudit(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := strconv.Atoi(r.FormValue("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		perPage, err := strconv.Atoi(r.FormValue("per_page"))
+		if err != nil || perPage < 1 {
+			perPage = 25
+		} else if perPage > maxPerPage {
+			perPage = maxPerPage
+		}
+		filter := models.AuditFilter{Action: r.FormValue("action")}
+		filter.AccountID, _ = strconv.Atoi(r.FormValue("account_id"))
+
+		found, err := app.AuditLog.List(filter, perPage, (page-1)*perPage)
+		if err != nil {
+			panic(errors.Wrap(err, "List"))
+		}
+		total, err := app.AuditLog.Count(filter)
+		if err != nil {
+			panic(errors.Wrap(err, "Count"))
+		}
+
+		entries := []map[string]interface{}{}
+		for _, entry := range found {
+			entries = append(entries, map[string]interface{}{
+				"id":         entry.ID,
+				"action":     entry.Action,
+				"account_id": entry.AccountID,
+				"username":   entry.Username,
+				"actor":      entry.Actor,
+				"ip":         entry.IP,
+				"user_agent": entry.UserAgent,
+				"created_at": entry.CreatedAt,
+			})
+		}
+
+		WriteData(w, http.StatusOK, map[string]interface{}{
+			"entries":  entries,
+			"page":     page,
+			"per_page": perPage,
+			"total":    total,
+		})
+	}
+}
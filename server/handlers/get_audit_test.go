@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAudit(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("alice@test.com", []byte("bar"))
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	type listing struct {
+		Entries []struct {
+			Action    string `json:"action"`
+			AccountID int    `json:"account_id"`
+			Actor     string `json:"actor"`
+			IP        string `json:"ip"`
+		} `json:"entries"`
+		Page    int `json:"page"`
+		PerPage int `json:"per_page"`
+		Total   int `json:"total"`
+	}
+	list := func(query string) listing {
+		res, err := client.Get("/audit" + query)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		found := listing{}
+		err = test.ExtractResult(res, &found)
+		require.NoError(t, err)
+		return found
+	}
+
+	res, err := client.Patch(fmt.Sprintf("/accounts/%v/lock", account.ID), url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = client.Patch(fmt.Sprintf("/accounts/%v/unlock", account.ID), url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	res, err = client.Patch("/accounts/999999/lock", url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	t.Run("newest first", func(t *testing.T) {
+		found := list("")
+		assert.Equal(t, 1, found.Page)
+		assert.Equal(t, 25, found.PerPage)
+		assert.Equal(t, 2, found.Total, "failed actions are not recorded")
+		if assert.Len(t, found.Entries, 2) {
+			assert.Equal(t, "account.unlocked", found.Entries[0].Action)
+			assert.Equal(t, account.ID, found.Entries[0].AccountID)
+			assert.Equal(t, "admin", found.Entries[0].Actor)
+			assert.Equal(t, "127.0.0.1", found.Entries[0].IP)
+			assert.Equal(t, "account.locked", found.Entries[1].Action)
+		}
+	})
+
+	t.Run("by action", func(t *testing.T) {
+		found := list("?action=account.locked")
+		assert.Equal(t, 1, found.Total)
+	})
+
+	t.Run("by account", func(t *testing.T) {
+		found := list("?account_id=999999")
+		assert.Equal(t, 0, found.Total)
+		assert.Empty(t, found.Entries)
+	})
+
+	t.Run("later page", func(t *testing.T) {
+		found := list("?page=2&per_page=1")
+		if assert.Len(t, found.Entries, 1) {
+			assert.Equal(t, "account.locked", found.Entries[0].Action)
+		}
+	})
+}
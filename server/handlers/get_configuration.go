@@ -8,13 +8,27 @@ import (
 
 func GetConfiguration(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		algs := []string{}
+		seen := map[string]bool{}
+		for _, key := range app.KeyStore.Keys() {
+			if !seen[key.JWK.Algorithm] {
+				seen[key.JWK.Algorithm] = true
+				algs = append(algs, key.JWK.Algorithm)
+			}
+		}
+
+		backchannelLogout := len(cfg.AppBackchannelLogoutURLs) > 0
+
 		WriteJSON(w, http.StatusOK, map[string]interface{}{
-			"issuer":                                app.Config.AuthNURL.String(),
+			"issuer":                                cfg.AuthNURL.String(),
 			"response_types_supported":              []string{"id_token"},
 			"subject_types_supported":               []string{"public"},
-			"id_token_signing_alg_values_supported": []string{"RS256"},
-			"claims_supported":                      []string{"iss", "sub", "aud", "exp", "iat", "auth_time"},
-			"jwks_uri":                              app.Config.AuthNURL.String() + "/jwks",
+			"id_token_signing_alg_values_supported": algs,
+			"claims_supported":                      []string{"iss", "sub", "aud", "exp", "iat", "auth_time", "sid", "email_verified"},
+			"jwks_uri":                              cfg.AuthNURL.String() + "/jwks",
+			"backchannel_logout_supported":          backchannelLogout,
+			"backchannel_logout_session_supported":  backchannelLogout,
 		})
 	}
 }
@@ -1,6 +1,9 @@
 package handlers_test
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data/mock"
+	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -15,12 +20,18 @@ import (
 )
 
 func TestGetConfiguration(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	key, err := private.NewKey(ecKey)
+	require.NoError(t, err)
+	cfg := &app.Config{
+		AuthNURL: &url.URL{Scheme: "https", Host: "authn.example.com", Path: "/foo"},
+	}
 	app := &app.App{
-		Config: &app.Config{
-			AuthNURL: &url.URL{Scheme: "https", Host: "authn.example.com", Path: "/foo"},
-		},
-		Logger: logrus.New(),
+		KeyStore: mock.NewKeyStore(key),
+		Logger:   logrus.New(),
 	}
+	app.SetConfig(cfg)
 	server := test.Server(app)
 	defer server.Close()
 
@@ -32,8 +43,12 @@ func TestGetConfiguration(t *testing.T) {
 	assert.Equal(t, []string{"application/json"}, res.Header["Content-Type"])
 
 	data := struct {
-		JWKSURI string `json:"jwks_uri"`
+		JWKSURI           string   `json:"jwks_uri"`
+		Algs              []string `json:"id_token_signing_alg_values_supported"`
+		BackchannelLogout bool     `json:"backchannel_logout_supported"`
 	}{}
 	json.Unmarshal(body, &data)
 	assert.Equal(t, "https://authn.example.com/foo/jwks", data.JWKSURI)
+	assert.Equal(t, []string{"ES256"}, data.Algs)
+	assert.False(t, data.BackchannelLogout)
 }
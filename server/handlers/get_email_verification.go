@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+func GetEmailVerification(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		account, err := app.AccountStoreFor(r.Context()).FindByUsername(r.FormValue("username"))
+		if err != nil {
+			panic(err)
+		}
+
+		// run in the background so that a timing attack can't enumerate usernames
+		go func() {
+			err := services.EmailVerificationSender(r.Context(), cfg, account, requestLogger(app, r))
+			if err != nil {
+				app.Reporter.ReportRequestError(err, r)
+			}
+		}()
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
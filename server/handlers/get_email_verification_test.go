@@ -0,0 +1,34 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEmailVerification(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("known account", func(t *testing.T) {
+		_, err := app.AccountStore.Create("known@keratin.tech", []byte("pwd"))
+		require.NoError(t, err)
+
+		res, err := client.Get("/email/verification?username=known@keratin.tech")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Get("/email/verification?username=unknown@keratin.tech")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+)
+
+type readiness struct {
+	Db    bool `json:"db"`
+	Redis bool `json:"redis"`
+	Keys  bool `json:"keys"`
+}
+
+// GetHealthReady reports whether the server is able to handle requests. Unlike GetHealth, it
+// responds with 503 when a dependency is unavailable, so that an orchestrator may route traffic
+// elsewhere without restarting the process.
+func GetHealthReady(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		rd := readiness{
+			Db: app.DbCheck(),
+			// redis is only a dependency when it has been configured
+			Redis: cfg.RedisURL == nil || app.RedisCheck(),
+			Keys:  app.KeyCheck(),
+		}
+
+		status := http.StatusOK
+		if !rd.Db || !rd.Redis || !rd.Keys {
+			status = http.StatusServiceUnavailable
+		}
+
+		WriteJSON(w, status, rd)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHealthReady(t *testing.T) {
+	up := func() bool { return true }
+	down := func() bool { return false }
+	redisURL := &url.URL{Scheme: "redis", Host: "localhost:6379"}
+
+	testCases := []struct {
+		name   string
+		app    *app.App
+		config *app.Config
+		status int
+		body   string
+	}{
+		{"ready", &app.App{DbCheck: up, RedisCheck: up, KeyCheck: up}, &app.Config{RedisURL: redisURL},
+			http.StatusOK, `{"db":true,"redis":true,"keys":true}`},
+		{"without redis", &app.App{DbCheck: up, RedisCheck: down, KeyCheck: up}, &app.Config{},
+			http.StatusOK, `{"db":true,"redis":true,"keys":true}`},
+		{"redis down", &app.App{DbCheck: up, RedisCheck: down, KeyCheck: up}, &app.Config{RedisURL: redisURL},
+			http.StatusServiceUnavailable, `{"db":true,"redis":false,"keys":true}`},
+		{"db down", &app.App{DbCheck: down, RedisCheck: up, KeyCheck: up}, &app.Config{RedisURL: redisURL},
+			http.StatusServiceUnavailable, `{"db":false,"redis":true,"keys":true}`},
+		{"no keys", &app.App{DbCheck: up, RedisCheck: up, KeyCheck: down}, &app.Config{RedisURL: redisURL},
+			http.StatusServiceUnavailable, `{"db":true,"redis":true,"keys":false}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.app.Logger = logrus.New()
+			tc.app.SetConfig(tc.config)
+			server := test.Server(tc.app)
+			defer server.Close()
+
+			res, err := http.Get(fmt.Sprintf("%s/health/ready", server.URL))
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, []string{"application/json"}, res.Header["Content-Type"])
+			assert.Equal(t, tc.body, string(test.ReadBody(res)))
+		})
+	}
+}
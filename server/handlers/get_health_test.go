@@ -13,12 +13,13 @@ import (
 )
 
 func TestGetHealth(t *testing.T) {
+	cfg := &app.Config{}
 	app := &app.App{
 		DbCheck:    func() bool { return true },
 		RedisCheck: func() bool { return true },
-		Config:     &app.Config{},
 		Logger:     logrus.New(),
 	}
+	app.SetConfig(cfg)
 	server := test.Server(app)
 	defer server.Close()
 
@@ -7,6 +7,8 @@ import (
 	"gopkg.in/square/go-jose.v2"
 )
 
+// GetJWKs publishes the public half of every signing key, including previous keys that may have
+// signed tokens that are still valid. Each key has a kid, alg, and use.
 func GetJWKs(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var keys []jose.JSONWebKey
@@ -1,12 +1,15 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
 
+	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/data/mock"
@@ -18,11 +21,12 @@ import (
 func TestGetJWKs(t *testing.T) {
 	rsaKey, err := private.GenerateKey(512)
 	require.NoError(t, err)
+	cfg := &app.Config{}
 	app := &app.App{
 		KeyStore: mock.NewKeyStore(rsaKey),
-		Config:   &app.Config{},
 		Logger:   logrus.New(),
 	}
+	app.SetConfig(cfg)
 
 	server := test.Server(app)
 	defer server.Close()
@@ -36,12 +40,46 @@ func TestGetJWKs(t *testing.T) {
 	assert.NotEmpty(t, body)
 }
 
+func TestGetJWKsWithPreviousKeys(t *testing.T) {
+	previous, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	current, err := private.GenerateKey(512)
+	require.NoError(t, err)
+	cfg := &app.Config{}
+	app := &app.App{
+		KeyStore: data.NewStaticKeyStore(previous, current),
+		Logger:   logrus.New(),
+	}
+	app.SetConfig(cfg)
+
+	server := test.Server(app)
+	defer server.Close()
+
+	for _, path := range []string{"/jwks", "/.well-known/jwks.json"} {
+		res, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var set jose.JSONWebKeySet
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &set))
+		if assert.Len(t, set.Keys, 2) {
+			for i, key := range []*private.Key{previous, current} {
+				assert.Equal(t, key.JWK.KeyID, set.Keys[i].KeyID)
+				assert.Equal(t, "RS256", set.Keys[i].Algorithm)
+				assert.Equal(t, "sig", set.Keys[i].Use)
+				assert.True(t, set.Keys[i].IsPublic())
+			}
+		}
+	}
+}
+
 func BenchmarkGetJWKs(b *testing.B) {
 	rsaKey, _ := private.GenerateKey(2048)
+	cfg := &app.Config{}
 	app := &app.App{
 		KeyStore: mock.NewKeyStore(rsaKey),
-		Config:   &app.Config{},
 	}
+	app.SetConfig(cfg)
 
 	server := test.Server(app)
 	defer server.Close()
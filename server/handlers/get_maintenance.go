@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/pkg/errors"
+)
+
+func GetMaintenance(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeMaintenance(app, app.ConfigFor(r.Context()), w)
+	}
+}
+
+// writeMaintenance describes whether the server is in maintenance, and when it is expected to end.
+// MAINTENANCE_MODE has no expected end.
+func writeMaintenance(app *app.App, cfg *app.Config, w http.ResponseWriter) {
+	until, err := app.Maintenance.Until()
+	if err != nil {
+		panic(errors.Wrap(err, "Until"))
+	}
+
+	var end *time.Time
+	if !until.IsZero() {
+		end = &until
+	}
+	WriteData(w, http.StatusOK, map[string]interface{}{
+		"enabled": cfg.MaintenanceMode || end != nil,
+		"until":   end,
+	})
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maintenanceStatus struct {
+	Enabled bool       `json:"enabled"`
+	Until   *time.Time `json:"until"`
+}
+
+func TestGetMaintenance(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+	get := func() maintenanceStatus {
+		res, err := client.Get("/maintenance")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		status := maintenanceStatus{}
+		require.NoError(t, test.ExtractResult(res, &status))
+		return status
+	}
+
+	status := get()
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.Until)
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, app.Maintenance.Enable(until))
+	status = get()
+	assert.True(t, status.Enabled)
+	if assert.NotNil(t, status.Until) {
+		assert.True(t, until.Equal(*status.Until))
+	}
+
+	require.NoError(t, app.Maintenance.Disable())
+	app.Config().MaintenanceMode = true
+	status = get()
+	assert.True(t, status.Enabled)
+	assert.Nil(t, status.Until)
+}
@@ -14,13 +14,14 @@ import (
 
 func GetOauth(app *app.App, providerName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		provider := app.OauthProviders[providerName]
 
 		// require and validate a redirect URI
 		redirectURI := r.FormValue("redirect_uri")
-		if route.FindDomain(redirectURI, app.Config.ApplicationDomains) == nil {
+		if route.FindDomain(redirectURI, cfg.ApplicationDomains) == nil {
 			app.Reporter.ReportRequestError(errors.New("unknown redirect domain"), r)
-			failsafe := app.Config.ApplicationDomains[0].URL()
+			failsafe := cfg.ApplicationDomains[0].URL()
 			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
 			return
 		}
@@ -38,17 +39,22 @@ func GetOauth(app *app.App, providerName string) http.HandlerFunc {
 			return
 		}
 		nonce := base64.StdEncoding.EncodeToString(bytes)
-		http.SetCookie(w, nonceCookie(app.Config, string(nonce)))
+		http.SetCookie(w, nonceCookie(cfg, string(nonce)))
 
 		// save nonce and return URL into state param
-		stateToken, err := oauth.New(app.Config, string(nonce), redirectURI)
+		stateToken, err := oauth.New(cfg, string(nonce), redirectURI)
 		if err != nil {
 			fail(err)
 			return
 		}
-		state, err := stateToken.Sign(app.Config.OAuthSigningKey)
+		state, err := stateToken.Sign(cfg.OAuthSigningKey)
 
-		returnURL := app.Config.AuthNURL.String() + "/oauth/" + providerName + "/return"
-		http.Redirect(w, r, provider.Config(returnURL).AuthCodeURL(state), http.StatusSeeOther)
+		returnURL := cfg.AuthNURL.String() + "/oauth/" + providerName + "/return"
+		config, err := provider.Config(returnURL)
+		if err != nil {
+			fail(err)
+			return
+		}
+		http.Redirect(w, r, config.AuthCodeURL(state, provider.AuthCodeOptions...), http.StatusSeeOther)
 	}
 }
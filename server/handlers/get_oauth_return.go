@@ -7,23 +7,25 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/server/sessions"
 )
 
 func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		provider := app.OauthProviders[providerName]
 
 		// verify the state and nonce
-		state, err := getState(app.Config, r)
+		state, err := getState(cfg, r)
 		if err != nil {
 			app.Reporter.ReportRequestError(errors.Wrap(err, "getState"), r)
-			failsafe := app.Config.ApplicationDomains[0].URL()
+			failsafe := cfg.ApplicationDomains[0].URL()
 			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
 			return
 		}
-		http.SetCookie(w, nonceCookie(app.Config, ""))
+		http.SetCookie(w, nonceCookie(cfg, ""))
 
 		// fail handler
 		fail := func(err error) {
@@ -32,8 +34,13 @@ func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 		}
 
 		// exchange code for tokens and user info
-		returnURL := app.Config.AuthNURL.String() + "/oauth/" + providerName + "/return"
-		tok, err := provider.Config(returnURL).Exchange(context.TODO(), r.FormValue("code"))
+		returnURL := cfg.AuthNURL.String() + "/oauth/" + providerName + "/return"
+		config, err := provider.Config(returnURL)
+		if err != nil {
+			fail(errors.Wrap(err, "Config"))
+			return
+		}
+		tok, err := config.Exchange(context.TODO(), r.FormValue("code"))
 		if err != nil {
 			fail(errors.Wrap(err, "Exchange"))
 			return
@@ -46,24 +53,42 @@ func GetOauthReturn(app *app.App, providerName string) http.HandlerFunc {
 
 		// attempt to reconcile oauth identity information into an authn account
 		sessionAccountID := sessions.GetAccountID(r)
-		account, err := services.IdentityReconciler(app.AccountStore, app.Config, providerName, providerUser, tok, sessionAccountID)
+		account, err := services.IdentityReconciler(app.AccountStoreFor(r.Context()), cfg, providerName, providerUser, tok, sessionAccountID)
 		if err != nil {
 			fail(err)
 			return
 		}
 
+		// an account with a second factor finishes logging in with its code, by redeeming a handoff
+		if services.SecondFactorRequired(app.OTPStore, cfg, account) {
+			err = redirectSecondFactor(w, r, cfg, account.ID, state.Destination)
+			if err != nil {
+				fail(errors.Wrap(err, "redirectSecondFactor"))
+			}
+			return
+		}
+
+		domain := destinationDomain(cfg, state.Destination)
+		remember := sessions.RememberMe(cfg, nil)
+
 		// identityToken is not returned in this flow. it must be imported by the frontend like a SSO session.
-		sessionToken, _, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			account.ID, &app.Config.ApplicationDomains[0], sessions.GetRefreshToken(r),
-		)
+		sessionToken, _, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     account.ID,
+			Audience:      domain,
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+		})
 		if err != nil {
 			fail(errors.Wrap(err, "NewSession"))
 			return
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, account.ID)
+		audit(app, r, models.AuditEntry{Action: services.AuditLogin, AccountID: account.ID, Username: account.Username, Actor: models.ActorAccount})
 
 		// Return the signed session in a cookie
-		sessions.Set(app.Config, w, sessionToken)
+		sessions.Set(cfg, w, sessionToken, domain, remember)
 
 		// redirect back to frontend (success or failure)
 		http.Redirect(w, r, state.Destination, http.StatusSeeOther)
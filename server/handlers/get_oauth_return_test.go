@@ -3,13 +3,19 @@ package handlers_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/keratin/authn-server/server/test"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/totp"
 	oauthlib "github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
 	oauthtoken "github.com/keratin/authn-server/app/tokens/oauth"
@@ -32,16 +38,16 @@ func TestGetOauthReturn(t *testing.T) {
 	// configure a client for the authn test server
 	nonce := "rand123"
 	client := route.NewClient(server.URL).WithCookie(&http.Cookie{
-		Name:  app.Config.OAuthCookieName,
+		Name:  app.Config().OAuthCookieName,
 		Value: nonce,
 	})
 	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
 		return http.ErrUseLastResponse
 	}
 
-	token, err := oauthtoken.New(app.Config, nonce, "https://localhost:9999/return")
+	token, err := oauthtoken.New(app.Config(), nonce, "https://localhost:9999/return")
 	require.NoError(t, err)
-	state, err := token.Sign(app.Config.OAuthSigningKey)
+	state, err := token.Sign(app.Config().OAuthSigningKey)
 	require.NoError(t, err)
 
 	t.Run("sign up new identity with new email", func(t *testing.T) {
@@ -50,7 +56,7 @@ func TestGetOauthReturn(t *testing.T) {
 		if !test.AssertRedirect(t, res, "https://localhost:9999/return") {
 			return
 		}
-		test.AssertSession(t, app.Config, res.Cookies())
+		test.AssertSession(t, app.Config(), res.Cookies())
 
 		// creates an account
 		account, err := app.AccountStore.FindByOauthAccount("test", "something")
@@ -59,15 +65,28 @@ func TestGetOauthReturn(t *testing.T) {
 		assert.Equal(t, "something", account.Username)
 	})
 
+	t.Run("sign up new identity with a form_post return", func(t *testing.T) {
+		res, err := client.PostForm("/oauth/test/return", url.Values{"code": {"posted"}, "state": {state}})
+		require.NoError(t, err)
+		if !test.AssertRedirect(t, res, "https://localhost:9999/return") {
+			return
+		}
+		test.AssertSession(t, app.Config(), res.Cookies())
+
+		account, err := app.AccountStore.FindByOauthAccount("test", "posted")
+		require.NoError(t, err)
+		assert.NotNil(t, account)
+	})
+
 	t.Run("connect new identity with current session", func(t *testing.T) {
 		account, err := app.AccountStore.Create("existing@keratin.tech", []byte("password"))
 		require.NoError(t, err)
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		res, err := client.WithCookie(session).Get("/oauth/test/return?code=existing@keratin.tech&state=" + state)
 		require.NoError(t, err)
 		if test.AssertRedirect(t, res, "https://localhost:9999/return") {
-			test.AssertSession(t, app.Config, res.Cookies())
+			test.AssertSession(t, app.Config(), res.Cookies())
 		}
 	})
 
@@ -75,7 +94,7 @@ func TestGetOauthReturn(t *testing.T) {
 		account, err := app.AccountStore.Create("linked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
 		app.AccountStore.AddOauthAccount(account.ID, "test", "PREVIOUSID", "TOKEN")
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		res, err := client.WithCookie(session).Get("/oauth/test/return?code=linked+alias@keratin.tech&state=" + state)
 		require.NoError(t, err)
@@ -93,10 +112,39 @@ func TestGetOauthReturn(t *testing.T) {
 		res, err := client.Get("/oauth/test/return?code=REGISTEREDID&state=" + state)
 		require.NoError(t, err)
 		if test.AssertRedirect(t, res, "https://localhost:9999/return") {
-			test.AssertSession(t, app.Config, res.Cookies())
+			test.AssertSession(t, app.Config(), res.Cookies())
 		}
 	})
 
+	t.Run("log in to identity with an authenticator", func(t *testing.T) {
+		account, err := app.AccountStore.Create("totp@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		err = app.AccountStore.AddOauthAccount(account.ID, "test", "TOTPID", "TOKEN")
+		require.NoError(t, err)
+		secret, _, err := services.TOTPEnroller(app.AccountStore, app.Config(), account.ID, "test.com")
+		require.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, services.TOTPConfirmer(app.AccountStore, app.Config(), account.ID, code))
+
+		token, err := oauthtoken.New(app.Config(), nonce, "https://test.com/return")
+		require.NoError(t, err)
+		state, err := token.Sign(app.Config().OAuthSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.Get("/oauth/test/return?code=TOTPID&state=" + state)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+		assert.Nil(t, test.ReadCookie(res.Cookies(), app.Config().SessionCookieName))
+
+		// returns a handoff to redeem with the code
+		loc, err := res.Location()
+		require.NoError(t, err)
+		assert.Equal(t, "otp_required", loc.Query().Get("status"))
+		_, err = handoffs.Parse(loc.Query().Get("handoff_token"), app.Config(), "test.com")
+		assert.NoError(t, err)
+	})
+
 	t.Run("log in to locked identity", func(t *testing.T) {
 		account, err := app.AccountStore.Create("locked@keratin.tech", []byte("password"))
 		require.NoError(t, err)
@@ -117,6 +165,24 @@ func TestGetOauthReturn(t *testing.T) {
 		test.AssertRedirect(t, res, "https://localhost:9999/return?status=failed")
 	})
 
+	t.Run("log in returning to another application domain", func(t *testing.T) {
+		app.Config().ApplicationDomains = append(app.Config().ApplicationDomains, route.Domain{Hostname: "other.com"})
+		token, err := oauthtoken.New(app.Config(), nonce, "https://other.com/return")
+		require.NoError(t, err)
+		state, err := token.Sign(app.Config().OAuthSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.Get("/oauth/test/return?code=other@keratin.tech&state=" + state)
+		require.NoError(t, err)
+		if test.AssertRedirect(t, res, "https://other.com/return") {
+			session := test.ReadCookie(res.Cookies(), app.Config().SessionCookieName)
+			require.NotNil(t, session)
+			claims, err := sessions.Parse(session.Value, app.Config())
+			require.NoError(t, err)
+			assert.Equal(t, "other.com", claims.Azp)
+		}
+	})
+
 	t.Run("without nonce cookie", func(t *testing.T) {
 		client := route.NewClient(server.URL)
 		res, err := client.Get("/oauth/test/return?code=something&state=" + state)
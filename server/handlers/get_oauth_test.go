@@ -27,13 +27,13 @@ func TestGetOauth(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 
 	t.Run("when provider is configured", func(t *testing.T) {
 		res, err := client.Get("/oauth/test?redirect_uri=http://test.com/finish")
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
-		assert.NotNil(t, test.ReadCookie(res.Cookies(), app.Config.OAuthCookieName))
+		assert.NotNil(t, test.ReadCookie(res.Cookies(), app.Config().OAuthCookieName))
 
 		location, err := res.Location()
 		require.NoError(t, err)
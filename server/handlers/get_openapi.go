@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"net/http"
+)
+
+func GetOpenAPI(doc interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		WriteJSON(w, http.StatusOK, doc)
+	}
+}
@@ -4,19 +4,28 @@ import (
 	"net/http"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
 )
 
 func GetPasswordReset(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		account, err := app.AccountStore.FindByUsername(r.FormValue("username"))
+		cfg := app.ConfigFor(r.Context())
+		account, err := app.AccountStoreFor(r.Context()).FindByUsername(r.FormValue("username"))
 		if err != nil {
 			panic(err)
 		}
 
+		entry := models.AuditEntry{Action: services.AuditPasswordResetRequested, Username: r.FormValue("username"), Actor: models.ActorAnonymous}
+		if account != nil {
+			entry.AccountID = account.ID
+		}
+		audit(app, r, entry)
+
 		// run in the background so that a timing attack can't enumerate usernames
 		go func() {
-			err := services.PasswordResetSender(app.Config, account, app.Logger)
+			err := services.PasswordResetSender(r.Context(), cfg, account, requestLogger(app, r), ops.RequestID(r))
 			if err != nil {
 				app.Reporter.ReportRequestError(err, r)
 			}
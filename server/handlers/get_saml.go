@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/oauth"
+	"github.com/keratin/authn-server/lib"
+	"github.com/keratin/authn-server/lib/route"
+)
+
+func GetSaml(app *app.App, providerName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		provider := app.SAMLProviders[providerName]
+
+		// require and validate a redirect URI
+		redirectURI := r.FormValue("redirect_uri")
+		if route.FindDomain(redirectURI, cfg.ApplicationDomains) == nil {
+			app.Reporter.ReportRequestError(errors.New("unknown redirect domain"), r)
+			failsafe := cfg.ApplicationDomains[0].URL()
+			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
+			return
+		}
+
+		// fail handler
+		fail := func(err error) {
+			app.Reporter.ReportRequestError(err, r)
+			redirectFailure(w, r, redirectURI)
+		}
+
+		// the nonce is the AuthnRequest ID, so that the response must be in reply to this browser
+		bytes, err := lib.GenerateToken()
+		if err != nil {
+			fail(err)
+			return
+		}
+		nonce := hex.EncodeToString(bytes)
+		authURL, err := provider.AuthURL(samlRequestID(nonce))
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		// save nonce and return URL into a secured cookie, since RelayState is limited to 80 bytes
+		stateToken, err := oauth.New(cfg, nonce, redirectURI)
+		if err != nil {
+			fail(err)
+			return
+		}
+		state, err := stateToken.Sign(cfg.OAuthSigningKey)
+		if err != nil {
+			fail(err)
+			return
+		}
+		http.SetCookie(w, nonceCookie(cfg, state))
+
+		http.Redirect(w, r, authURL, http.StatusSeeOther)
+	}
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+)
+
+func GetSamlMetadata(app *app.App, providerName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metadata, err := app.SAMLProviders[providerName].Metadata()
+		if err != nil {
+			panic(err)
+		}
+
+		w.Header().Set("Content-Type", "application/samlmetadata+xml")
+		w.WriteHeader(http.StatusOK)
+		w.Write(metadata)
+	}
+}
@@ -0,0 +1,62 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/lib/saml"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSaml(t *testing.T) {
+	// start a fake identity provider
+	idp := saml.NewTestIdP()
+	defer idp.Close()
+
+	// configure and start the authn test server
+	app := test.App()
+	base := app.Config().AuthNURL.String() + "/saml/acme"
+	app.SAMLProviders["acme"] = saml.NewProvider(idp.MetadataURL(), base+"/metadata", base+"/acs", "")
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	t.Run("when provider is configured", func(t *testing.T) {
+		res, err := client.Get("/saml/acme?redirect_uri=http://test.com/finish")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+		assert.NotNil(t, test.ReadCookie(res.Cookies(), app.Config().OAuthCookieName))
+
+		location, err := res.Location()
+		require.NoError(t, err)
+		assert.Equal(t, idp.Server.URL+"/sso", location.Scheme+"://"+location.Host+location.Path)
+		assert.NotEmpty(t, location.Query().Get("SAMLRequest"))
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		res, err := client.Get("/saml/unknown")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("unknown redirect domain", func(t *testing.T) {
+		res, err := client.Get("/saml/acme?redirect_uri=http://evil.com")
+		require.NoError(t, err)
+		test.AssertRedirect(t, res, "http://test.com")
+	})
+
+	t.Run("metadata", func(t *testing.T) {
+		res, err := client.Get("/saml/acme/metadata")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "application/samlmetadata+xml", res.Header.Get("Content-Type"))
+		assert.Contains(t, string(test.ReadBody(res)), `Location="`+base+`/acs"`)
+	})
+}
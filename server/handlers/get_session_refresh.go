@@ -12,6 +12,7 @@ import (
 
 func GetSessionRefresh(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		// check for valid session with live token
 		accountID := sessions.GetAccountID(r)
 		if accountID == 0 {
@@ -19,14 +20,30 @@ func GetSessionRefresh(app *app.App) http.HandlerFunc {
 			return
 		}
 
+		// check that the session is refreshed by the client that created it
+		if !sessions.Get(r).Client.Matches(sessions.Fingerprint(cfg, r)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
 		identityToken, err := services.SessionRefresher(
-			app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			sessions.Get(r), accountID, route.MatchedDomain(r),
+			app.AccountStoreFor(r.Context()), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, app.AccessTokenStore, app.Actives, cfg, app.Reporter,
+			sessions.Get(r), accountID, route.MatchedDomain(r), r.URL.Query().Get("nonce"),
 		)
 		if err != nil {
 			panic(errors.Wrap(err, "IdentityForSession"))
 		}
 
+		if cookie, err := r.Cookie(cfg.SessionCookieName); err == nil {
+			// extend the persistent cookie along with the session
+			if sessions.Get(r).Remember {
+				sessions.Set(cfg, w, cookie.Value, route.MatchedDomain(r), true)
+			} else if cfg.CSRFProtection == "double_submit" {
+				// clients that can not read the CSRF cookie learn the token on each refresh
+				w.Header().Set(sessions.CSRFHeader, sessions.CSRFToken(cfg, cookie.Value))
+			}
+		}
+
 		WriteData(w, http.StatusCreated, map[string]string{
 			"id_token": identityToken,
 		})
@@ -10,6 +10,7 @@ import (
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/redis"
 	"github.com/keratin/authn-server/app/data/sqlite3"
+	"github.com/keratin/authn-server/app/tokens/sessions"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server/test"
@@ -34,8 +35,8 @@ func BenchmarkGetSessionRefresh(b *testing.B) {
 		defer server.Close()
 		testApp.RefreshTokenStore = mock.NewRefreshTokenStore()
 		client := route.NewClient(server.URL).
-			Referred(&testApp.Config.ApplicationDomains[0]).
-			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config, 12345))
+			Referred(&testApp.Config().ApplicationDomains[0]).
+			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 12345))
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
@@ -53,10 +54,10 @@ func BenchmarkGetSessionRefresh(b *testing.B) {
 		testApp := test.App()
 		server := test.Server(testApp)
 		defer server.Close()
-		testApp.RefreshTokenStore = &sqlite3.RefreshTokenStore{sqliteDB, time.Hour}
+		testApp.RefreshTokenStore = &sqlite3.RefreshTokenStore{Ext: sqliteDB, TTL: time.Hour}
 		client := route.NewClient(server.URL).
-			Referred(&testApp.Config.ApplicationDomains[0]).
-			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config, 12345))
+			Referred(&testApp.Config().ApplicationDomains[0]).
+			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 12345))
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
@@ -73,10 +74,10 @@ func BenchmarkGetSessionRefresh(b *testing.B) {
 		testApp := test.App()
 		server := test.Server(testApp)
 		defer server.Close()
-		testApp.RefreshTokenStore = &redis.RefreshTokenStore{redisDB, time.Hour}
+		testApp.RefreshTokenStore = &redis.RefreshTokenStore{Client: redisDB, TTL: time.Hour}
 		client := route.NewClient(server.URL).
-			Referred(&testApp.Config.ApplicationDomains[0]).
-			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config, 12345))
+			Referred(&testApp.Config().ApplicationDomains[0]).
+			WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 12345))
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
@@ -91,29 +92,29 @@ func TestGetSessionRefreshSuccess(t *testing.T) {
 	defer server.Close()
 
 	accountID := 82594
-	existingSession := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+	existingSession := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
 
-	client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0]).WithCookie(existingSession)
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(existingSession)
 	res, err := client.Get("/session/refresh")
 	require.NoError(t, err)
 
 	if assert.Equal(t, http.StatusCreated, res.StatusCode) {
-		test.AssertIDTokenResponse(t, res, testApp.KeyStore, testApp.Config)
+		test.AssertIDTokenResponse(t, res, testApp.KeyStore, testApp.Config())
 	}
 }
 
 func TestGetSessionRefreshFailure(t *testing.T) {
 	testApp := &app.App{
-		Config: &app.Config{
-			AuthNURL:           &url.URL{Scheme: "https", Path: "www.example.com"},
-			SessionCookieName:  "authn-test",
-			SessionSigningKey:  []byte("good"),
-			ApplicationDomains: []route.Domain{{Hostname: "test.com"}},
-		},
 		RefreshTokenStore: mock.NewRefreshTokenStore(),
 		Reporter:          &ops.LogReporter{logrus.New()},
 		Logger:            logrus.New(),
 	}
+	testApp.SetConfig(&app.Config{
+		AuthNURL:           &url.URL{Scheme: "https", Path: "www.example.com"},
+		SessionCookieName:  "authn-test",
+		SessionSigningKey:  []byte("good"),
+		ApplicationDomains: []route.Domain{{Hostname: "test.com"}},
+	})
 	server := test.Server(testApp)
 	defer server.Close()
 
@@ -124,25 +125,92 @@ func TestGetSessionRefreshFailure(t *testing.T) {
 		// cookie with the wrong signature
 		{[]byte("wrong"), true},
 		// cookie with a revoked refresh token
-		{testApp.Config.SessionSigningKey, false},
+		{testApp.Config().SessionSigningKey, false},
 	}
 
 	for idx, tc := range testCases {
 		tcCfg := &app.Config{
-			AuthNURL:           testApp.Config.AuthNURL,
-			SessionCookieName:  testApp.Config.SessionCookieName,
+			AuthNURL:           testApp.Config().AuthNURL,
+			SessionCookieName:  testApp.Config().SessionCookieName,
 			SessionSigningKey:  tc.signingKey,
 			ApplicationDomains: []route.Domain{{Hostname: "test.com"}},
 		}
 		existingSession := test.CreateSession(testApp.RefreshTokenStore, tcCfg, idx+100)
 		if !tc.liveToken {
-			test.RevokeSession(testApp.RefreshTokenStore, testApp.Config, existingSession)
+			test.RevokeSession(testApp.RefreshTokenStore, testApp.Config(), existingSession)
 		}
 
-		client := route.NewClient(server.URL).Referred(&testApp.Config.ApplicationDomains[0]).WithCookie(existingSession)
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(existingSession)
 		res, err := client.Get("/session/refresh")
 		require.NoError(t, err)
 
 		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
 	}
 }
+
+func TestGetSessionRefreshBinding(t *testing.T) {
+	testApp := test.App()
+	testApp.Config().SessionBinding = "lax"
+	server := test.Server(testApp)
+	defer server.Close()
+
+	boundSession := func(userAgent string) *http.Cookie {
+		session, err := sessions.New(testApp.RefreshTokenStore, testApp.Config(), 123, "test.com")
+		require.NoError(t, err)
+		session.Client = sessions.NewFingerprint(userAgent, nil)
+		sessionStr, err := session.Sign(testApp.Config().SessionSigningKey)
+		require.NoError(t, err)
+		return &http.Cookie{Name: testApp.Config().SessionCookieName, Value: sessionStr}
+	}
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0])
+
+	t.Run("same client", func(t *testing.T) {
+		res, err := client.WithCookie(boundSession("Go-http-client/1.1")).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+
+	t.Run("different client", func(t *testing.T) {
+		res, err := client.WithCookie(boundSession("Mozilla/5.0")).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("unbound session", func(t *testing.T) {
+		res, err := client.WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 123)).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+	})
+}
+
+func TestGetSessionRefreshRememberMe(t *testing.T) {
+	testApp := test.App()
+	testApp.Config().RefreshTokenTTL = time.Hour
+	server := test.Server(testApp)
+	defer server.Close()
+
+	session, err := sessions.New(testApp.RefreshTokenStore, testApp.Config(), 123, "test.com")
+	require.NoError(t, err)
+	session.Remember = true
+	sessionStr, err := session.Sign(testApp.Config().SessionSigningKey)
+	require.NoError(t, err)
+	client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0])
+
+	t.Run("remembered session", func(t *testing.T) {
+		res, err := client.WithCookie(&http.Cookie{Name: testApp.Config().SessionCookieName, Value: sessionStr}).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		cookie := test.ReadCookie(res.Cookies(), testApp.Config().SessionCookieName)
+		require.NotNil(t, cookie)
+		assert.Equal(t, sessionStr, cookie.Value)
+		assert.Equal(t, 3600, cookie.MaxAge)
+	})
+
+	t.Run("session only", func(t *testing.T) {
+		res, err := client.WithCookie(test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 123)).Get("/session/refresh")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		assert.Nil(t, test.ReadCookie(res.Cookies(), testApp.Config().SessionCookieName))
+	})
+}
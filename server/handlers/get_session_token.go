@@ -9,14 +9,15 @@ import (
 
 func GetSessionToken(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		account, err := app.AccountStore.FindByUsername(r.FormValue("username"))
+		cfg := app.ConfigFor(r.Context())
+		account, err := app.AccountStoreFor(r.Context()).FindByUsername(r.FormValue("username"))
 		if err != nil {
 			panic(err)
 		}
 
 		// run in the background so that a timing attack can't enumerate usernames
 		go func() {
-			err := services.PasswordlessTokenSender(app.Config, account, app.Logger)
+			err := services.PasswordlessTokenSender(r.Context(), cfg, account, requestLogger(app, r))
 			if err != nil {
 				app.Reporter.ReportRequestError(err, r)
 			}
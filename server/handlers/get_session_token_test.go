@@ -15,7 +15,7 @@ func TestGetSessionToken(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 
 	t.Run("known account", func(t *testing.T) {
 		_, err := app.AccountStore.Create("known@keratin.tech", []byte("pwd"))
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/pkg/errors"
+)
+
+func GetSessions(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// check for valid session with live token
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		found, err := app.RefreshTokenStoreFor(r.Context()).FindSessions(accountID)
+		if err != nil {
+			panic(errors.Wrap(err, "FindSessions"))
+		}
+
+		current := sessions.GetRefreshToken(r)
+		data := []map[string]interface{}{}
+		for _, session := range found {
+			data = append(data, map[string]interface{}{
+				"id":           session.Token.ID(),
+				"created_at":   session.CreatedAt,
+				"last_seen_at": session.LastSeenAt,
+				"user_agent":   session.UserAgent,
+				"current":      session.Token == *current,
+			})
+		}
+
+		WriteData(w, http.StatusOK, data)
+	}
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSessions(t *testing.T) {
+	testApp := test.App()
+	server := test.Server(testApp)
+	defer server.Close()
+
+	accountID := 8642
+	other := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
+	session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
+	test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), 9753)
+
+	t.Run("with session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0]).WithCookie(session)
+		res, err := client.Get("/sessions")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var body struct {
+			Result []struct {
+				ID         string  `json:"id"`
+				CreatedAt  *string `json:"created_at"`
+				LastSeenAt *string `json:"last_seen_at"`
+				Current    bool    `json:"current"`
+			} `json:"result"`
+		}
+		err = json.Unmarshal(test.ReadBody(res), &body)
+		require.NoError(t, err)
+		require.Len(t, body.Result, 2)
+
+		assert.Equal(t, refreshTokenOf(t, testApp.Config(), other).ID(), body.Result[0].ID)
+		assert.False(t, body.Result[0].Current)
+		assert.Equal(t, refreshTokenOf(t, testApp.Config(), session).ID(), body.Result[1].ID)
+		assert.True(t, body.Result[1].Current)
+		assert.NotNil(t, body.Result[1].CreatedAt)
+		assert.NotNil(t, body.Result[1].LastSeenAt)
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		client := route.NewClient(server.URL).Referred(&testApp.Config().ApplicationDomains[0])
+		res, err := client.Get("/sessions")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
+
+func refreshTokenOf(t *testing.T, cfg *app.Config, cookie *http.Cookie) models.RefreshToken {
+	claims, err := sessions.Parse(cookie.Value, cfg)
+	require.NoError(t, err)
+	return models.RefreshToken(claims.Subject)
+}
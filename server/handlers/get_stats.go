@@ -2,12 +2,25 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
 )
 
+// statsDate is the format of the from and to params, as dates in the STATISTICS_TIME_ZONE
+const statsDate = "2006-01-02"
+
 func GetStats(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("from") != "" || r.FormValue("to") != "" {
+			getStatsBetween(app, app.ConfigFor(r.Context()), w, r)
+			return
+		}
+
 		daily, err := app.Actives.ActivesByDay()
 		if err != nil {
 			panic(err)
@@ -38,3 +51,85 @@ func GetStats(app *app.App) http.HandlerFunc {
 		})
 	}
 }
+
+// getStatsBetween counts actives and signups in each period of the requested granularity from one
+// date through another. Periods without any accounts are included.
+func getStatsBetween(app *app.App, cfg *app.Config, w http.ResponseWriter, r *http.Request) {
+	tz := cfg.StatisticsTimeZone
+	if tz == nil {
+		tz = time.UTC
+	}
+
+	var fe services.FieldErrors
+	from, err := time.ParseInLocation(statsDate, r.FormValue("from"), tz)
+	if r.FormValue("from") == "" {
+		fe = append(fe, services.FieldError{Field: "from", Message: services.ErrMissing})
+	} else if err != nil {
+		fe = append(fe, services.FieldError{Field: "from", Message: services.ErrFormatInvalid})
+	}
+	to := time.Now().In(tz)
+	if r.FormValue("to") != "" {
+		to, err = time.ParseInLocation(statsDate, r.FormValue("to"), tz)
+		if err != nil || to.Before(from) {
+			fe = append(fe, services.FieldError{Field: "to", Message: services.ErrFormatInvalid})
+		}
+	}
+	periods := []models.Period{models.Daily, models.Weekly}
+	if r.FormValue("granularity") != "" {
+		periods = nil
+		for _, p := range strings.Split(r.FormValue("granularity"), ",") {
+			switch period := models.Period(strings.TrimSpace(p)); period {
+			case models.Daily, models.Weekly, models.Monthly:
+				periods = append(periods, period)
+			default:
+				fe = append(fe, services.FieldError{Field: "granularity", Message: services.ErrFormatInvalid})
+			}
+		}
+	}
+	if fe != nil {
+		WriteErrors(w, r, fe)
+		return
+	}
+
+	// periods outside of retention have no records, so they are not counted
+	now := time.Now().In(tz)
+	if to.After(now) {
+		to = now
+	}
+
+	actives := map[models.Period]map[string]int{}
+	signups := map[models.Period]map[string]int{}
+	for _, period := range periods {
+		from := from
+		if oldest := statsRetained(cfg, period, now); from.Before(oldest) {
+			from = oldest
+		}
+
+		actives[period], err = app.Actives.ActivesBetween(period, from, to)
+		if err != nil {
+			panic(errors.Wrap(err, "ActivesBetween"))
+		}
+		signups[period], err = app.Actives.SignupsBetween(period, from, to)
+		if err != nil {
+			panic(errors.Wrap(err, "SignupsBetween"))
+		}
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"actives": actives,
+		"signups": signups,
+	})
+}
+
+// statsRetained returns the start of the oldest period that is kept for the granularity
+func statsRetained(cfg *app.Config, period models.Period, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch period {
+	case models.Weekly:
+		return today.AddDate(0, 0, -7*(cfg.WeeklyActivesRetention-1))
+	case models.Monthly:
+		return time.Date(now.Year(), now.Month()+1-app.MonthlyActivesRetention, 1, 0, 0, 0, 0, now.Location())
+	default:
+		return today.AddDate(0, 0, 1-cfg.DailyActivesRetention)
+	}
+}
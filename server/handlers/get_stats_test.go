@@ -1,9 +1,12 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/server/test"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +20,7 @@ func TestGetStats(t *testing.T) {
 
 	app.Actives.Track(1)
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	res, err := client.Get("/stats")
 	require.NoError(t, err)
@@ -28,13 +31,70 @@ func TestGetStats(t *testing.T) {
 	assert.NotEmpty(t, body)
 }
 
+func TestGetStatsBetween(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	app.Actives.Track(1)
+	app.Actives.TrackSignup(1)
+	today := time.Now().In(time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("daily and monthly", func(t *testing.T) {
+		res, err := client.Get("/stats?from=" + yesterday.Format("2006-01-02") + "&to=" + today.Format("2006-01-02") + "&granularity=daily,monthly")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var stats struct {
+			Actives map[string]map[string]int
+			Signups map[string]map[string]int
+		}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &stats))
+		days := map[string]int{yesterday.Format("2006-01-02"): 0, today.Format("2006-01-02"): 1}
+		assert.Equal(t, days, stats.Actives["daily"])
+		assert.Equal(t, days, stats.Signups["daily"])
+		assert.Equal(t, 1, stats.Actives["monthly"][today.Format("2006-01")])
+		assert.NotContains(t, stats.Actives, "weekly")
+	})
+
+	t.Run("beyond retention", func(t *testing.T) {
+		res, err := client.Get("/stats?from=1900-01-01&to=9999-12-31&granularity=daily,weekly,monthly")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		var stats struct {
+			Actives map[string]map[string]int
+		}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &stats))
+		assert.Len(t, stats.Actives["daily"], app.Config().DailyActivesRetention)
+		assert.Len(t, stats.Actives["weekly"], app.Config().WeeklyActivesRetention)
+		assert.Len(t, stats.Actives["monthly"], 60)
+		assert.Equal(t, 1, stats.Actives["daily"][today.Format("2006-01-02")])
+		assert.NotContains(t, stats.Actives["daily"], today.AddDate(0, 0, 1).Format("2006-01-02"))
+	})
+
+	t.Run("invalid params", func(t *testing.T) {
+		res, err := client.Get("/stats?to=yesterday&granularity=hourly")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{
+			{"from", services.ErrMissing},
+			{"to", services.ErrFormatInvalid},
+			{"granularity", services.ErrFormatInvalid},
+		})
+	})
+}
+
 func TestGetStatsWithoutRedis(t *testing.T) {
 	app := test.App()
 	app.Actives = nil
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	res, err := client.Get("/stats")
 	require.NoError(t, err)
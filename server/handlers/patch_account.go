@@ -12,24 +12,25 @@ import (
 
 func PatchAccount(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		var user struct{ Username string }
 		if err := parse.Payload(r, &user); err != nil {
-			WriteErrors(w, err)
+			WriteErrors(w, r, err)
 			return
 		}
 		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
-			WriteNotFound(w, "account")
+			WriteNotFound(w, r, "account")
 			return
 		}
 
-		err = services.AccountUpdater(app.AccountStore, app.Config, id, user.Username)
+		err = services.AccountUpdater(app.AccountStoreFor(r.Context()), cfg, id, user.Username)
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
 				if fe[0].Message == services.ErrNotFound {
-					WriteNotFound(w, "account")
+					WriteNotFound(w, r, "account")
 				} else {
-					WriteErrors(w, fe)
+					WriteErrors(w, r, fe)
 				}
 				return
 			}
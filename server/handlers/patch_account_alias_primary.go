@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PatchAccountAliasPrimary(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct{ Username string }
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, r, "account")
+			return
+		}
+
+		err = services.AccountAliasPromoter(app.AccountStoreFor(r.Context()), id, params.Username)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteNotFound(w, r, fe[0].Field)
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func PatchAccountEmail(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		// check for valid session with live token
+		accountID := sessions.GetAccountID(r)
+		if accountID == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var params struct {
+			Email    string
+			Password string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+
+		err := services.EmailChangeSender(r.Context(), app.AccountStoreFor(r.Context()), cfg, accountID, params.Email, params.Password, requestLogger(app, r))
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, r, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPatchAccountEmail(t *testing.T) {
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+
+	app := test.App()
+	app.Config().AppEmailChangeURL, _ = url.Parse(remoteApp.URL)
+	server := test.Server(app)
+	defer server.Close()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), 4)
+	require.NoError(t, err)
+	account, err := app.AccountStore.Create("old@keratin.tech", hash)
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.Patch("/account/email", url.Values{"email": []string{"new@keratin.tech"}, "password": []string{"secret"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("with a new email", func(t *testing.T) {
+		res, err := client.WithCookie(session).Patch("/account/email", url.Values{"email": []string{"new@keratin.tech"}, "password": []string{"secret"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusAccepted, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "old@keratin.tech", found.Username)
+	})
+
+	t.Run("with the wrong password", func(t *testing.T) {
+		res, err := client.WithCookie(session).Patch("/account/email", url.Values{"email": []string{"new@keratin.tech"}, "password": []string{"wrong"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"credentials", "FAILED"}})
+	})
+
+	t.Run("with a taken email", func(t *testing.T) {
+		res, err := client.WithCookie(session).Patch("/account/email", url.Values{"email": []string{"old@keratin.tech"}, "password": []string{"secret"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"username", "TAKEN"}})
+	})
+}
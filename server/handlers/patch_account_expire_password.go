@@ -11,16 +11,17 @@ import (
 
 func PatchAccountExpirePassword(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
-			WriteNotFound(w, "account")
+			WriteNotFound(w, r, "account")
 			return
 		}
 
-		err = services.PasswordExpirer(app.AccountStore, app.RefreshTokenStore, id)
+		err = services.PasswordExpirer(r.Context(), app.AccountStoreFor(r.Context()), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, cfg, app.Reporter, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
-				WriteNotFound(w, "account")
+				WriteNotFound(w, r, "account")
 				return
 			}
 
@@ -17,7 +17,7 @@ func TestPatchAccountExpirePassword(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("unknown account", func(t *testing.T) {
 		res, err := client.Patch("/accounts/999999/expire_password", url.Values{})
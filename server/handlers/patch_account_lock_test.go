@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/server/test"
 	"github.com/keratin/authn-server/lib/route"
@@ -17,7 +18,7 @@ func TestPatchAccountLock(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("unknown account", func(t *testing.T) {
 		res, err := client.Patch("/accounts/999999/lock", url.Values{})
@@ -51,4 +52,21 @@ func TestPatchAccountLock(t *testing.T) {
 		require.NoError(t, err)
 		assert.True(t, account.Locked)
 	})
+
+	t.Run("with APP_EVENTS_URLS", func(t *testing.T) {
+		app.Config().AppEventsURLs = []*url.URL{{Scheme: "https", Host: "app.example.com", Path: "/events"}}
+		defer func() { app.Config().AppEventsURLs = nil }()
+		account, err := app.AccountStore.Create("evented@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Patch(fmt.Sprintf("/accounts/%v/lock", account.ID), url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		delivery, err := app.EventQueue.Claim(time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, delivery)
+		assert.Equal(t, "account.locked", delivery.Event.Type)
+		assert.Equal(t, account.ID, delivery.Event.AccountID)
+	})
 }
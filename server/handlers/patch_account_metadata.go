@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PatchAccountMetadata(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct{ Metadata map[string]interface{} }
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, r, "account")
+			return
+		}
+
+		err = services.AccountMetadataUpdater(app.AccountStoreFor(r.Context()), id, params.Metadata)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, r, "account")
+				} else {
+					WriteErrors(w, r, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountMetadata(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.PatchJSON("/accounts/999999/metadata", `{"metadata": {"tenant": "acme"}}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid metadata", func(t *testing.T) {
+		account, err := app.AccountStore.Create("metadata@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.PatchJSON(fmt.Sprintf("/accounts/%v/metadata", account.ID), `{"metadata": {"tenant": "acme", "beta": true}}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Metadata{"tenant": "acme", "beta": true}, account.Metadata)
+	})
+
+	t.Run("oversized metadata", func(t *testing.T) {
+		account, err := app.AccountStore.Create("oversized@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.PatchJSON(fmt.Sprintf("/accounts/%v/metadata", account.ID), `{"metadata": {"notes": "`+strings.Repeat("a", 5000)+`"}}`)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"metadata", services.ErrFormatInvalid}})
+	})
+}
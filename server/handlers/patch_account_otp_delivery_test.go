@@ -0,0 +1,47 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountOTPDelivery(t *testing.T) {
+	app := test.App()
+	app.Config().AppOTPDeliveryURL = &url.URL{Scheme: "https", Host: "app.example.com"}
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Patch("/accounts/999999/otp_delivery", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("enabling and disabling", func(t *testing.T) {
+		account, err := app.AccountStore.Create("sms@keratin.tech", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Patch("/accounts/"+strconv.Itoa(account.ID)+"/otp_delivery", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.OTPDeliveryEnabled())
+
+		res, err = client.Delete("/accounts/" + strconv.Itoa(account.ID) + "/otp_delivery")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		found, err = app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.False(t, found.OTPDeliveryEnabled())
+	})
+}
@@ -0,0 +1,65 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountRoles(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.Patch("/accounts/999999/roles", url.Values{"roles": []string{"admin"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("valid roles", func(t *testing.T) {
+		account, err := app.AccountStore.Create("roles@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Patch(fmt.Sprintf("/accounts/%v/roles", account.ID), url.Values{"roles": []string{"admin", "billing"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Roles{"admin", "billing"}, account.Roles)
+	})
+
+	t.Run("invalid roles", func(t *testing.T) {
+		account, err := app.AccountStore.Create("invalid@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Patch(fmt.Sprintf("/accounts/%v/roles", account.ID), url.Values{"roles": []string{"super admin"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"roles", services.ErrFormatInvalid}})
+	})
+
+	t.Run("with PUT", func(t *testing.T) {
+		account, err := app.AccountStore.Create("put@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.Put(fmt.Sprintf("/accounts/%v/roles", account.ID), url.Values{"roles": []string{"support"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		account, err = app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.Roles{"support"}, account.Roles)
+	})
+}
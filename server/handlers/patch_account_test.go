@@ -19,7 +19,7 @@ func TestPatchAccount(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("unknown account", func(t *testing.T) {
 		res, err := client.Patch("/accounts/999999", url.Values{"username": []string{"irrelevant"}})
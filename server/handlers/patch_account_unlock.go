@@ -11,21 +11,23 @@ import (
 
 func PatchAccountUnlock(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		id, err := strconv.Atoi(mux.Vars(r)["id"])
 		if err != nil {
-			WriteNotFound(w, "account")
+			WriteNotFound(w, r, "account")
 			return
 		}
 
-		err = services.AccountUnlocker(app.AccountStore, id)
+		err = services.AccountUnlocker(app.AccountStoreFor(r.Context()), app.FailedLogins, id)
 		if err != nil {
 			if _, ok := err.(services.FieldErrors); ok {
-				WriteNotFound(w, "account")
+				WriteNotFound(w, r, "account")
 				return
 			}
 
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountUnlocked, id)
 
 		w.WriteHeader(http.StatusOK)
 	}
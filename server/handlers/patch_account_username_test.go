@@ -0,0 +1,49 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchAccountUsername(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("original", []byte("password"))
+	require.NoError(t, err)
+	_, err = app.AccountStore.Create("taken", []byte("password"))
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.Patch("/account/username", url.Values{"username": []string{"renamed"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("with a new username", func(t *testing.T) {
+		res, err := client.WithCookie(session).Patch("/account/username", url.Values{"username": []string{"renamed"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "renamed", found.Username)
+	})
+
+	t.Run("with a taken username", func(t *testing.T) {
+		res, err := client.WithCookie(session).Patch("/account/username", url.Values{"username": []string{"taken"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"username", services.ErrTaken}})
+	})
+}
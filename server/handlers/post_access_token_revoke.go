@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
+)
+
+// PostAccessTokenRevoke adds an identity token (or its jti) to the denylist, so that it may not be
+// used until it would have expired.
+func PostAccessTokenRevoke(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		err := services.AccessTokenRevoker(
+			app.TokenDenylist, app.KeyStore, app.AccessTokenStore, cfg,
+			r.FormValue("token"), r.FormValue("jti"),
+		)
+		if err != nil {
+			if _, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, r, err)
+				return
+			}
+
+			panic(errors.Wrap(err, "AccessTokenRevoker"))
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccessTokenRevoke(t *testing.T) {
+	app := test.App()
+	app.Config().AccessTokenTTL = time.Hour
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	session, err := sessions.New(app.RefreshTokenStore, app.Config(), 123, "test.com")
+	require.NoError(t, err)
+	identity := identities.New(app.Config(), session, 123, "test.com")
+	identityToken, err := identity.Sign(app.KeyStore.Key())
+	require.NoError(t, err)
+
+	t.Run("revoking a token", func(t *testing.T) {
+		res, err := client.PostForm("/access_tokens/revoke", url.Values{"token": []string{identityToken}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		res, err = route.NewClient(server.URL).Get("/access_tokens/revoked?jti=" + identity.ID)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		test.AssertData(t, res, map[string]bool{"revoked": true})
+	})
+
+	t.Run("checking an unrevoked jti", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).Get("/access_tokens/revoked?jti=unknown")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		test.AssertData(t, res, map[string]bool{"revoked": false})
+	})
+
+	t.Run("revoking an invalid token", func(t *testing.T) {
+		res, err := client.PostForm("/access_tokens/revoke", url.Values{"token": []string{"invalid"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	})
+}
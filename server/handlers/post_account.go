@@ -6,46 +6,100 @@ import (
 
 	"github.com/keratin/authn-server/server/sessions"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/pkg/errors"
 )
 
 func PostAccount(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		var credentials struct {
-			Username string
-			Password string
+			Username   string
+			Password   string
+			Nonce      string
+			Invitation string
 		}
 		if err := parse.Payload(r, &credentials); err != nil {
-			WriteErrors(w, err)
+			WriteErrors(w, r, err)
 			return
 		}
+		var invitation *invitations.Claims
+		if !cfg.EnableSignup {
+			var err error
+			invitation, err = services.InvitationVerifier(app.TokenDenylist, cfg, credentials.Invitation, credentials.Username)
+			if err != nil {
+				WriteErrors(w, r, err)
+				return
+			}
+		}
+		// a failed signup may try its invitation again
+		allowInvitation := func() {
+			if invitation != nil && app.TokenDenylist != nil {
+				if err := app.TokenDenylist.Allow(invitation.ID); err != nil {
+					panic(err)
+				}
+			}
+		}
+		if fe := services.PasswordBreachValidator(app.PwnedPasswords, app.Reporter, credentials.Password); fe != nil {
+			allowInvitation()
+			WriteErrors(w, r, services.FieldErrors{*fe})
+			return
+		}
+
 		// Create the account
 		account, err := services.AccountCreator(
-			app.AccountStore,
-			app.Config,
+			app.AccountStoreFor(r.Context()),
+			cfg,
 			credentials.Username,
 			credentials.Password,
 		)
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
-				WriteErrors(w, fe)
+				allowInvitation()
+				WriteErrors(w, r, fe)
 				return
 			}
 
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountCreated, account.ID)
+		if app.Actives != nil {
+			if err := app.Actives.TrackSignup(account.ID); err != nil {
+				app.Reporter.ReportRequestError(errors.Wrap(err, "TrackSignup"), r)
+			}
+		}
+		audit(app, r, models.AuditEntry{Action: services.AuditSignup, AccountID: account.ID, Username: account.Username, Actor: models.ActorAccount})
 
-		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			account.ID, route.MatchedDomain(r), sessions.GetRefreshToken(r),
-		)
+		if cfg.AppEmailVerificationURL != nil {
+			// run in the background so that signup does not wait for the app
+			go func() {
+				err := services.EmailVerificationSender(r.Context(), cfg, account, requestLogger(app, r))
+				if err != nil {
+					app.Reporter.ReportRequestError(err, r)
+				}
+			}()
+		}
+
+		remember := sessions.RememberMe(cfg, nil)
+		sessionToken, identityToken, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     account.ID,
+			Audience:      route.MatchedDomain(r),
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+			Nonce:         credentials.Nonce,
+		})
 		if err != nil {
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, account.ID)
 
 		// Return the signed session in a cookie
-		sessions.Set(app.Config, w, sessionToken)
+		sessions.Set(cfg, w, sessionToken, route.MatchedDomain(r), remember)
 
 		// Return the signed identity token in the body
 		WriteData(w, http.StatusCreated, map[string]string{
@@ -0,0 +1,52 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccountAlias(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.PostForm("/accounts/999999/aliases", url.Values{"username": []string{"unknown@test.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("new alias", func(t *testing.T) {
+		account, err := app.AccountStore.Create("primary@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%v/aliases", account.ID), url.Values{"username": []string{"alias@test.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		found, err := app.AccountStore.FindByUsername("alias@test.com")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, account.ID, found.ID)
+	})
+
+	t.Run("taken alias", func(t *testing.T) {
+		account, err := app.AccountStore.Create("second@test.com", []byte("bar"))
+		require.NoError(t, err)
+
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%v/aliases", account.ID), url.Values{"username": []string{"primary@test.com"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"username", services.ErrTaken}})
+	})
+}
@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/verifications"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccountEmailConfirm(t *testing.T) {
+	app := test.App()
+	app.Config().AppEmailChangeURL = &url.URL{Scheme: "https", Host: "app.example.com"}
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("valid token", func(t *testing.T) {
+		account, err := app.AccountStore.Create("old@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		claims, err := verifications.NewChange(app.Config(), account.ID, account.Username, "new@keratin.tech")
+		require.NoError(t, err)
+		token, err := claims.Sign(app.Config().EmailVerificationSigningKey)
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/account/email/confirm", url.Values{
+			"token": []string{token},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "new@keratin.tech", found.Username)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		res, err := client.PostForm("/account/email/confirm", url.Values{
+			"token": []string{"invalid"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"token", "INVALID_OR_EXPIRED"}})
+	})
+}
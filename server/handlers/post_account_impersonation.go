@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+)
+
+func PostAccountImpersonation(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		var params struct {
+			ImpersonatedBy string `json:"impersonated_by" schema:"impersonated_by"`
+			Origin         string
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(mux.Vars(r)["id"])
+		if err != nil {
+			WriteNotFound(w, r, "account")
+			return
+		}
+
+		sessionToken, identityToken, err := services.ImpersonationSessionCreator(
+			app.AccountStoreFor(r.Context()), app.RefreshTokenStoreFor(r.Context()), app.KeyStore, app.AccessTokenStore, cfg, requestLogger(app, r),
+			id, params.ImpersonatedBy, params.Origin,
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				if fe[0].Field == "account" && fe[0].Message == services.ErrNotFound {
+					WriteNotFound(w, r, "account")
+				} else {
+					WriteErrors(w, r, fe)
+				}
+				return
+			}
+
+			panic(err)
+		}
+
+		WriteData(w, http.StatusCreated, map[string]string{
+			"session":  sessionToken,
+			"id_token": identityToken,
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccountImpersonation(t *testing.T) {
+	app := test.App()
+	app.Config().AccessTokenTTL = time.Hour
+	app.Config().ImpersonationTTL = 15 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+	account, err := app.AccountStore.Create("impersonated@test.com", []byte("bar"))
+	require.NoError(t, err)
+
+	t.Run("unknown account", func(t *testing.T) {
+		res, err := client.PostForm("/accounts/999999/impersonation", url.Values{
+			"impersonated_by": []string{"support@test.com"},
+			"origin":          []string{"https://test.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	})
+
+	t.Run("missing staff", func(t *testing.T) {
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%v/impersonation", account.ID), url.Values{
+			"origin": []string{"https://test.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"impersonated_by", services.ErrMissing}})
+	})
+
+	t.Run("valid account", func(t *testing.T) {
+		res, err := client.PostForm(fmt.Sprintf("/accounts/%v/impersonation", account.ID), url.Values{
+			"impersonated_by": []string{"support@test.com"},
+			"origin":          []string{"https://test.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		result := struct {
+			Session string `json:"session"`
+			IDToken string `json:"id_token"`
+		}{}
+		require.NoError(t, test.ExtractResult(res, &result))
+		identity, err := identities.Parse(result.IDToken, app.Config(), app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "support@test.com", identity.ImpersonatedBy)
+
+		// the session refreshes with the same marking
+		refresher := route.NewClient(server.URL).
+			Referred(&app.Config().ApplicationDomains[0]).
+			WithCookie(&http.Cookie{Name: app.Config().SessionCookieName, Value: result.Session})
+		res, err = refresher.Get("/session/refresh")
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NoError(t, test.ExtractResult(res, &result))
+		identity, err = identities.Parse(result.IDToken, app.Config(), app.KeyStore.Keys())
+		require.NoError(t, err)
+		assert.Equal(t, "support@test.com", identity.ImpersonatedBy)
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/keratin/authn-server/server/test"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/hibp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,7 +19,7 @@ func TestPostAccountSuccess(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 	res, err := client.PostForm("/accounts", url.Values{
 		"username": []string{"foo"},
 		"password": []string{"0a0b0c0"},
@@ -25,8 +27,8 @@ func TestPostAccountSuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, http.StatusCreated, res.StatusCode)
-	test.AssertSession(t, app.Config, res.Cookies())
-	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+	test.AssertSession(t, app.Config(), res.Cookies())
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
 }
 
 func TestPostJSONAccountSuccess(t *testing.T) {
@@ -34,13 +36,13 @@ func TestPostJSONAccountSuccess(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 	res, err := client.PostJSON("/accounts", "{\"username\": \"bar\", \"password\": \"0a0b0c0\"}")
 	require.NoError(t, err)
 
 	assert.Equal(t, http.StatusCreated, res.StatusCode)
-	test.AssertSession(t, app.Config, res.Cookies())
-	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+	test.AssertSession(t, app.Config(), res.Cookies())
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
 }
 
 func TestPostAccountSuccessWithSession(t *testing.T) {
@@ -49,14 +51,14 @@ func TestPostAccountSuccessWithSession(t *testing.T) {
 	defer server.Close()
 
 	accountID := 8642
-	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), accountID)
 
 	// before
 	refreshTokens, err := app.RefreshTokenStore.FindAll(accountID)
 	require.NoError(t, err)
 	refreshToken := refreshTokens[0]
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0]).WithCookie(session)
 	_, err = client.PostForm("/accounts", url.Values{
 		"username": []string{"foo"},
 		"password": []string{"0a0b0c0"},
@@ -83,7 +85,7 @@ func TestPostAccountFailure(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 		res, err := client.PostForm("/accounts", url.Values{
 			"username": []string{tc.username},
 			"password": []string{tc.password},
@@ -94,3 +96,101 @@ func TestPostAccountFailure(t *testing.T) {
 		test.AssertErrors(t, res, tc.errors)
 	}
 }
+
+func TestPostAccountBreachedPassword(t *testing.T) {
+	app := test.App()
+	app.PwnedPasswords = hibp.TestChecker{"0a0b0c0"}
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	res, err := client.PostForm("/accounts", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"0a0b0c0"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{"password", "INSECURE"}})
+}
+
+func TestPostAccountWithInvitation(t *testing.T) {
+	app := test.App()
+	app.Config().EnableSignup = false
+	app.Config().InvitationSigningKey = []byte("TestKey")
+	app.Config().InvitationTokenTTL = time.Hour
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without an invitation", func(t *testing.T) {
+		res, err := client.PostForm("/accounts", url.Values{
+			"username": []string{"uninvited"},
+			"password": []string{"0a0b0c0"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"invitation", "MISSING"}})
+	})
+
+	t.Run("with an invitation for another email", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(app.Config(), "invited")
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/accounts", url.Values{
+			"username":   []string{"uninvited"},
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"invitation", "INVALID_OR_EXPIRED"}})
+	})
+
+	t.Run("with an invitation", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(app.Config(), "invited")
+		require.NoError(t, err)
+
+		res, err := client.PostForm("/accounts", url.Values{
+			"username":   []string{"invited"},
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config(), res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
+	})
+
+	t.Run("with a redeemed invitation", func(t *testing.T) {
+		invitation, err := services.InvitationCreator(app.Config(), "")
+		require.NoError(t, err)
+
+		// a failed signup does not spend the invitation
+		res, err := client.PostForm("/accounts", url.Values{
+			"username":   []string{"first"},
+			"password":   []string{""},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+		res, err = client.PostForm("/accounts", url.Values{
+			"username":   []string{"first"},
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+
+		res, err = client.PostForm("/accounts", url.Values{
+			"username":   []string{"second"},
+			"password":   []string{"0a0b0c0"},
+			"invitation": []string{invitation},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"invitation", "INVALID_OR_EXPIRED"}})
+	})
+}
@@ -11,13 +11,14 @@ import (
 
 func PostAccountsImport(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		var user struct {
 			Username string
 			Password string
 			Locked string
 		}
 		if err := parse.Payload(r, &user); err != nil {
-			WriteErrors(w, err)
+			WriteErrors(w, r, err)
 			return
 		}
 		locked, err := regexp.MatchString("^(?i:t|true|yes)$", user.Locked)
@@ -26,20 +27,21 @@ func PostAccountsImport(app *app.App) http.HandlerFunc {
 		}
 
 		account, err := services.AccountImporter(
-			app.AccountStore,
-			app.Config,
+			app.AccountStoreFor(r.Context()),
+			cfg,
 			user.Username,
 			user.Password,
 			locked,
 		)
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
-				WriteErrors(w, fe)
+				WriteErrors(w, r, fe)
 				return
 			}
 
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountCreated, account.ID)
 
 		WriteData(w, http.StatusCreated, map[string]int{
 			"id": account.ID,
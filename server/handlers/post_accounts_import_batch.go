@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+)
+
+// PostAccountsImportBatch imports newline-delimited JSON records from the request body, and
+// reports the outcome of each.
+func PostAccountsImportBatch(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		results, err := services.AccountBatchImporter(app.AccountStoreFor(r.Context()), cfg, r.Body)
+		if err != nil {
+			panic(err)
+		}
+
+		imported := 0
+		for _, result := range results {
+			if result.Errors == nil {
+				imported++
+				services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventAccountCreated, result.ID)
+			}
+		}
+
+		WriteData(w, http.StatusOK, map[string]interface{}{
+			"imported": imported,
+			"failed":   len(results) - imported,
+			"results":  results,
+		})
+	}
+}
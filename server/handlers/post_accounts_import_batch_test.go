@@ -0,0 +1,48 @@
+package handlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostAccountsImportBatch(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	hash := "$2a$04$lzQPXlov4RFLxps1uUGq4e4wmVjLYz3WrqQw4bSdfIiJRyo3/fk3C"
+	res, err := client.PostJSON("/accounts/import/batch",
+		`{"username": "first@app.com", "password": "`+hash+`"}`+"\n"+
+			`{"username": "second@app.com", "password": "`+hash+`", "locked": true}`+"\n"+
+			`{"username": "first@app.com", "password": "`+hash+`"}`+"\n")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	summary := struct {
+		Imported int `json:"imported"`
+		Failed   int `json:"failed"`
+		Results  []struct {
+			Line     int    `json:"line"`
+			Username string `json:"username"`
+			ID       int    `json:"id"`
+		} `json:"results"`
+	}{}
+	err = test.ExtractResult(res, &summary)
+	require.NoError(t, err)
+	assert.Equal(t, 2, summary.Imported)
+	assert.Equal(t, 1, summary.Failed)
+	require.Len(t, summary.Results, 3)
+
+	account, err := app.AccountStore.FindByUsername("second@app.com")
+	require.NoError(t, err)
+	assert.Equal(t, account.ID, summary.Results[1].ID)
+	assert.True(t, account.Locked)
+	assert.Equal(t, hash, string(account.Password))
+}
@@ -17,7 +17,7 @@ func TestPostAccountsImport(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Authenticated(app.Config.AuthUsername, app.Config.AuthPassword)
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
 
 	t.Run("importing someone", func(t *testing.T) {
 		res, err := client.PostForm("/accounts/import", url.Values{
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/server/graph"
+)
+
+// PostGraphQL answers GraphQL queries about accounts, sessions, and stats. As the GraphQL spec
+// describes, query errors are described in a 200 response rather than with the JSON envelope.
+func PostGraphQL(app *app.App) http.HandlerFunc {
+	schema := graph.NewSchema(app)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			Query         string                 `json:"query"`
+			OperationName string                 `json:"operationName" schema:"operationName"`
+			Variables     map[string]interface{} `json:"variables" schema:"-"`
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+
+		WriteJSON(w, http.StatusOK, schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables))
+	}
+}
@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostGraphQL(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("graphql@test.com", []byte("bar"))
+	require.NoError(t, err)
+	_, err = app.AccountStore.Create("other@test.com", []byte("bar"))
+	require.NoError(t, err)
+	token, err := app.RefreshTokenStore.Create(account.ID)
+	require.NoError(t, err)
+	require.NoError(t, app.Actives.Track(account.ID))
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	type response struct {
+		Data   map[string]interface{} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	query := func(q string, variables map[string]interface{}) response {
+		body, err := json.Marshal(map[string]interface{}{"query": q, "variables": variables})
+		require.NoError(t, err)
+		res, err := client.PostJSON("/graphql", string(body))
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		found := response{}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &found))
+		return found
+	}
+
+	t.Run("account with sessions", func(t *testing.T) {
+		found := query(
+			`query($id: Int!) { account(id: $id) { id username locked sessions { id } } }`,
+			map[string]interface{}{"id": account.ID},
+		)
+		require.Empty(t, found.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"id":       float64(account.ID),
+			"username": "graphql@test.com",
+			"locked":   false,
+			"sessions": []interface{}{map[string]interface{}{"id": token.ID()}},
+		}, found.Data["account"])
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		found := query(`{ account(id: 999999) { id } }`, nil)
+		require.Empty(t, found.Errors)
+		assert.Nil(t, found.Data["account"])
+	})
+
+	t.Run("accounts and stats in one query", func(t *testing.T) {
+		found := query(`{ accounts(perPage: 1, username: "graphql") { total accounts { username } } stats { daily { period count } } }`, nil)
+		require.Empty(t, found.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"total":    float64(1),
+			"accounts": []interface{}{map[string]interface{}{"username": "graphql@test.com"}},
+		}, found.Data["accounts"])
+		daily := found.Data["stats"].(map[string]interface{})["daily"].([]interface{})
+		require.Len(t, daily, 1)
+		assert.Equal(t, float64(1), daily[0].(map[string]interface{})["count"])
+	})
+
+	t.Run("audit log", func(t *testing.T) {
+		require.NoError(t, app.AuditLog.Record(&models.AuditEntry{Action: "account.locked", AccountID: account.ID, Actor: models.ActorAdmin}))
+		require.NoError(t, app.AuditLog.Record(&models.AuditEntry{Action: "login", AccountID: 999999, Actor: models.ActorAccount}))
+
+		found := query(
+			`query($id: Int!) { audit(accountId: $id) { total entries { action accountId actor } } }`,
+			map[string]interface{}{"id": account.ID},
+		)
+		require.Empty(t, found.Errors)
+		assert.Equal(t, map[string]interface{}{
+			"total": float64(1),
+			"entries": []interface{}{map[string]interface{}{
+				"action":    "account.locked",
+				"accountId": float64(account.ID),
+				"actor":     "admin",
+			}},
+		}, found.Data["audit"])
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		found := query(`{ account(id: 1) { password } }`, nil)
+		require.Len(t, found.Errors, 1)
+		assert.Contains(t, found.Errors[0].Message, "password")
+	})
+
+	t.Run("without credentials", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostJSON("/graphql", fmt.Sprintf(`{"query": "{ account(id: %d) { id } }"}`, account.ID))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/pkg/errors"
+)
+
+// PostIntrospect implements RFC 7662 token introspection for backend services that can not verify
+// tokens locally. The response is not enveloped, as the RFC describes.
+func PostIntrospect(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		introspection, err := services.TokenIntrospector(
+			app.RefreshTokenStoreFor(r.Context()), app.KeyStore, app.TokenDenylist, app.AccessTokenStore, cfg,
+			r.FormValue("token"), r.FormValue("token_type_hint"),
+		)
+		if err != nil {
+			panic(errors.Wrap(err, "TokenIntrospector"))
+		}
+
+		WriteJSON(w, http.StatusOK, introspection)
+	}
+}
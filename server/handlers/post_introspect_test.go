@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostIntrospect(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	introspect := func(t *testing.T, token string) map[string]interface{} {
+		res, err := client.PostForm("/introspect", url.Values{"token": []string{token}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, []string{"application/json"}, res.Header["Content-Type"])
+
+		body, err := ioutil.ReadAll(res.Body)
+		require.NoError(t, err)
+		data := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal(body, &data))
+		return data
+	}
+
+	t.Run("refresh token", func(t *testing.T) {
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), 123)
+		data := introspect(t, session.Value)
+		assert.Equal(t, true, data["active"])
+		assert.Equal(t, "123", data["sub"])
+		assert.NotNil(t, data["iat"])
+
+		test.RevokeSession(app.RefreshTokenStore, app.Config(), session)
+		data = introspect(t, session.Value)
+		assert.Equal(t, map[string]interface{}{"active": false}, data)
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		data := introspect(t, "invalid")
+		assert.Equal(t, map[string]interface{}{"active": false}, data)
+	})
+
+	t.Run("without authentication", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).PostForm("/introspect", url.Values{"token": []string{"invalid"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
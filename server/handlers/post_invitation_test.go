@@ -0,0 +1,51 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/invitations"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostInvitation(t *testing.T) {
+	app := test.App()
+	app.Config().UsernameIsEmail = true
+	app.Config().InvitationSigningKey = []byte("TestKey")
+	app.Config().InvitationTokenTTL = time.Hour
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("for an email", func(t *testing.T) {
+		res, err := client.PostForm("/invitations", url.Values{
+			"email": []string{"invited@test.com"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		result := struct {
+			Token string `json:"token"`
+		}{}
+		require.NoError(t, test.ExtractResult(res, &result))
+		claims, err := invitations.Parse(result.Token, app.Config())
+		require.NoError(t, err)
+		assert.Equal(t, "invited@test.com", claims.Email)
+	})
+
+	t.Run("for an invalid email", func(t *testing.T) {
+		res, err := client.PostForm("/invitations", url.Values{
+			"email": []string{"invalid"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"email", services.ErrFormatInvalid}})
+	})
+}
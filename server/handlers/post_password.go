@@ -6,42 +6,54 @@ import (
 
 	"github.com/keratin/authn-server/server/sessions"
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/app/services"
 )
 
 func PostPassword(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		var credentials struct {
 			Token string
 			Password string
 			CurrentPassword string
+			Nonce string
+			OTP string
 		}
 		if err := parse.Payload(r, &credentials); err != nil {
-			WriteErrors(w, err)
+			WriteErrors(w, r, err)
 			return
 		}
 
 		var err error
 		var accountID int
+		if credentials.Token == "" {
+			accountID = sessions.GetAccountID(r)
+			if accountID == 0 {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
 		if credentials.Token != "" {
 			accountID, err = services.PasswordResetter(
-				app.AccountStore,
+				r.Context(), app.AccountStoreFor(r.Context()),
+				app.OTPStore,
+				app.PwnedPasswords,
 				app.Reporter,
-				app.Config,
+				cfg,
+				requestLogger(app, r),
 				credentials.Token,
 				credentials.Password,
+				credentials.OTP,
 			)
 		} else {
-			accountID = sessions.GetAccountID(r)
-			if accountID == 0 {
-				w.WriteHeader(http.StatusUnauthorized)
-				return
-			}
 			err = services.PasswordChanger(
-				app.AccountStore,
+				r.Context(), app.AccountStoreFor(r.Context()),
+				app.PwnedPasswords,
 				app.Reporter,
-				app.Config,
+				cfg,
 				accountID,
 				credentials.CurrentPassword,
 				credentials.Password,
@@ -50,23 +62,40 @@ func PostPassword(app *app.App) http.HandlerFunc {
 
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
-				WriteErrors(w, fe)
+				WriteErrors(w, r, fe)
 				return
 			}
 
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventPasswordChanged, accountID)
+		if credentials.Token != "" {
+			audit(app, r, models.AuditEntry{Action: services.AuditPasswordReset, AccountID: accountID, Actor: models.ActorAccount})
+		} else {
+			audit(app, r, models.AuditEntry{Action: services.AuditPasswordChanged, AccountID: accountID, Actor: models.ActorAccount})
+		}
 
-		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			accountID, route.MatchedDomain(r), sessions.GetRefreshToken(r),
-		)
+		// a logged in user keeps the current session's choice
+		remember := sessions.RememberMe(cfg, nil)
+		if session := sessions.Get(r); session != nil {
+			remember = session.Remember
+		}
+		sessionToken, identityToken, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     accountID,
+			Audience:      route.MatchedDomain(r),
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+			Nonce:         credentials.Nonce,
+		})
 		if err != nil {
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, accountID)
 
 		// Return the signed session in a cookie
-		sessions.Set(app.Config, w, sessionToken)
+		sessions.Set(cfg, w, sessionToken, route.MatchedDomain(r), remember)
 
 		// Return the signed identity token in the body
 		WriteData(w, http.StatusCreated, map[string]string{
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
@@ -13,6 +14,8 @@ import (
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/app/tokens/resets"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/hibp"
+	"github.com/keratin/authn-server/lib/totp"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -23,19 +26,19 @@ func TestPostPassword(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 
 	assertSuccess := func(t *testing.T, res *http.Response, account *models.Account) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
-		test.AssertSession(t, app.Config, res.Cookies())
-		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+		test.AssertSession(t, app.Config(), res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
 		found, err := app.AccountStore.Find(account.ID)
 		require.NoError(t, err)
 		assert.NotEqual(t, found.Password, account.Password)
 	}
 
 	factory := func(username string, password string) (*models.Account, error) {
-		hash, err := bcrypt.GenerateFromPassword([]byte(password), app.Config.BcryptCost)
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), app.Config().BcryptCost)
 		if err != nil {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
@@ -49,9 +52,9 @@ func TestPostPassword(t *testing.T) {
 		require.NoError(t, err)
 
 		// given a reset token
-		token, err := resets.New(app.Config, account.ID, account.PasswordChangedAt)
+		token, err := resets.New(app.Config(), account.ID, account.PasswordChangedAt)
 		require.NoError(t, err)
-		tokenStr, err := token.Sign(app.Config.ResetSigningKey)
+		tokenStr, err := token.Sign(app.Config().ResetSigningKey)
 		require.NoError(t, err)
 
 		// invoking the endpoint
@@ -78,13 +81,53 @@ func TestPostPassword(t *testing.T) {
 		test.AssertErrors(t, res, services.FieldErrors{{"token", "INVALID_OR_EXPIRED"}})
 	})
 
+	t.Run("reset token for an account with an authenticator", func(t *testing.T) {
+		// given an account with an authenticator
+		account, err := factory("totp.token@authn.tech", "oldpwd")
+		require.NoError(t, err)
+		secret, _, err := services.TOTPEnroller(app.AccountStore, app.Config(), account.ID, "test.com")
+		require.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, services.TOTPConfirmer(app.AccountStore, app.Config(), account.ID, code))
+
+		// given a reset token
+		token, err := resets.New(app.Config(), account.ID, account.PasswordChangedAt)
+		require.NoError(t, err)
+		tokenStr, err := token.Sign(app.Config().ResetSigningKey)
+		require.NoError(t, err)
+
+		// invoking the endpoint without a code
+		res, err := client.PostForm("/password", url.Values{
+			"token":    []string{tokenStr},
+			"password": []string{"0a0b0c0d0"},
+		})
+		require.NoError(t, err)
+
+		// does not work
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"otp", "MISSING"}})
+		assert.Nil(t, test.ReadCookie(res.Cookies(), app.Config().SessionCookieName))
+
+		// invoking the endpoint with a code
+		res, err = client.PostForm("/password", url.Values{
+			"token":    []string{tokenStr},
+			"password": []string{"0a0b0c0d0"},
+			"otp":      []string{code},
+		})
+		require.NoError(t, err)
+
+		// works
+		assertSuccess(t, res, account)
+	})
+
 	t.Run("valid session", func(t *testing.T) {
 		// given an account
 		account, err := factory("valid.session@authn.tech", "oldpwd")
 		require.NoError(t, err)
 
 		// given a session
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		// invoking the endpoint
 		res, err := client.WithCookie(session).PostForm("/password", url.Values{
@@ -97,7 +140,7 @@ func TestPostPassword(t *testing.T) {
 		assertSuccess(t, res, account)
 
 		// invalidates old session
-		claims, err := sessions.Parse(session.Value, app.Config)
+		claims, err := sessions.Parse(session.Value, app.Config())
 		require.NoError(t, err)
 		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
@@ -110,7 +153,7 @@ func TestPostPassword(t *testing.T) {
 		require.NoError(t, err)
 
 		// given a session
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		// invoking the endpoint
 		res, err := client.WithCookie(session).PostForm("/password", url.Values{
@@ -123,13 +166,50 @@ func TestPostPassword(t *testing.T) {
 		test.AssertErrors(t, res, services.FieldErrors{{"password", "INSECURE"}})
 	})
 
+	t.Run("valid session and breached password", func(t *testing.T) {
+		app.PwnedPasswords = hibp.TestChecker{"0a0b0c0d0"}
+		defer func() { app.PwnedPasswords = nil }()
+
+		// given an account
+		account, err := factory("breached.password@authn.tech", "oldpwd")
+		require.NoError(t, err)
+
+		// given a session
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+
+		// invoking the endpoint
+		res, err := client.WithCookie(session).PostForm("/password", url.Values{
+			"currentPassword": []string{"oldpwd"},
+			"password":        []string{"0a0b0c0d0"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"password", "INSECURE"}})
+	})
+
+	t.Run("invalid token and breached password", func(t *testing.T) {
+		app.PwnedPasswords = hibp.TestChecker{"0a0b0c0d0"}
+		defer func() { app.PwnedPasswords = nil }()
+
+		// invoking the endpoint
+		res, err := client.PostForm("/password", url.Values{
+			"token":    []string{"invalid"},
+			"password": []string{"0a0b0c0d0"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"token", "INVALID_OR_EXPIRED"}})
+	})
+
 	t.Run("valid session and bad currentPassword", func(t *testing.T) {
 		// given an account
 		account, err := factory("bad.currentPassword@authn.tech", "oldpwd")
 		require.NoError(t, err)
 
 		// given a session
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		// invoking the endpoint
 		res, err := client.WithCookie(session).PostForm("/password", url.Values{
@@ -144,7 +224,7 @@ func TestPostPassword(t *testing.T) {
 
 	t.Run("invalid session", func(t *testing.T) {
 		session := &http.Cookie{
-			Name:  app.Config.SessionCookieName,
+			Name:  app.Config().SessionCookieName,
 			Value: "invalid",
 		}
 
@@ -162,16 +242,16 @@ func TestPostPassword(t *testing.T) {
 		tokenAccount, err := factory("token@authn.tech", "oldpwd")
 		require.NoError(t, err)
 		// with a reset token
-		token, err := resets.New(app.Config, tokenAccount.ID, tokenAccount.PasswordChangedAt)
+		token, err := resets.New(app.Config(), tokenAccount.ID, tokenAccount.PasswordChangedAt)
 		require.NoError(t, err)
-		tokenStr, err := token.Sign(app.Config.ResetSigningKey)
+		tokenStr, err := token.Sign(app.Config().ResetSigningKey)
 		require.NoError(t, err)
 
 		// given another account
 		sessionAccount, err := factory("session@authn.tech", "oldpwd")
 		require.NoError(t, err)
 		// with a session
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, sessionAccount.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), sessionAccount.ID)
 
 		// invoking the endpoint
 		res, err := client.WithCookie(session).PostForm("/password", url.Values{
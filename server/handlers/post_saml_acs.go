@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	oauthlib "github.com/keratin/authn-server/lib/oauth"
+	"github.com/keratin/authn-server/lib/saml"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func PostSamlACS(app *app.App, providerName string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		provider := app.SAMLProviders[providerName]
+		samlResponse := r.FormValue("SAMLResponse")
+
+		// verify the state and nonce
+		requestID, err := saml.InResponseTo(samlResponse)
+		if err != nil {
+			app.Reporter.ReportRequestError(errors.Wrap(err, "InResponseTo"), r)
+			failsafe := cfg.ApplicationDomains[0].URL()
+			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
+			return
+		}
+		state, err := getSamlState(cfg, r, requestID)
+		if err != nil {
+			app.Reporter.ReportRequestError(errors.Wrap(err, "getSamlState"), r)
+			failsafe := cfg.ApplicationDomains[0].URL()
+			http.Redirect(w, r, failsafe.String(), http.StatusSeeOther)
+			return
+		}
+		http.SetCookie(w, nonceCookie(cfg, ""))
+
+		// fail handler
+		fail := func(err error) {
+			app.Reporter.ReportRequestError(err, r)
+			redirectFailure(w, r, state.Destination)
+		}
+
+		// verify the assertion
+		user, err := provider.User(samlResponse, requestID)
+		if err != nil {
+			fail(errors.Wrap(err, "User"))
+			return
+		}
+
+		// attempt to reconcile the asserted identity into an authn account
+		sessionAccountID := sessions.GetAccountID(r)
+		account, err := services.IdentityReconciler(
+			app.AccountStoreFor(r.Context()), cfg, providerName,
+			&oauthlib.UserInfo{ID: user.ID, Email: user.Username}, &oauth2.Token{}, sessionAccountID,
+		)
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		// an account with a second factor finishes logging in with its code, by redeeming a handoff
+		if services.SecondFactorRequired(app.OTPStore, cfg, account) {
+			err = redirectSecondFactor(w, r, cfg, account.ID, state.Destination)
+			if err != nil {
+				fail(errors.Wrap(err, "redirectSecondFactor"))
+			}
+			return
+		}
+
+		domain := destinationDomain(cfg, state.Destination)
+		remember := sessions.RememberMe(cfg, nil)
+
+		// identityToken is not returned in this flow. it must be imported by the frontend like a SSO session.
+		sessionToken, _, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     account.ID,
+			Audience:      domain,
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+		})
+		if err != nil {
+			fail(errors.Wrap(err, "NewSession"))
+			return
+		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, account.ID)
+		audit(app, r, models.AuditEntry{Action: services.AuditLogin, AccountID: account.ID, Username: account.Username, Actor: models.ActorAccount})
+
+		// Return the signed session in a cookie
+		sessions.Set(cfg, w, sessionToken, domain, remember)
+
+		// redirect back to frontend (success or failure)
+		http.Redirect(w, r, state.Destination, http.StatusSeeOther)
+	}
+}
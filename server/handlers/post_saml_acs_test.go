@@ -0,0 +1,139 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	oauthtoken "github.com/keratin/authn-server/app/tokens/oauth"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/lib/saml"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostSamlACS(t *testing.T) {
+	// start a fake identity provider
+	idp := saml.NewTestIdP()
+	defer idp.Close()
+
+	// configure and start the authn test server
+	app := test.App()
+	base := app.Config().AuthNURL.String() + "/saml/acme"
+	app.SAMLProviders["acme"] = saml.NewProvider(idp.MetadataURL(), base+"/metadata", base+"/acs", "")
+	server := test.Server(app)
+	defer server.Close()
+
+	http.DefaultClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	// configure a client with the state cookie from beginning the flow
+	nonce := "abc123"
+	token, err := oauthtoken.New(app.Config(), nonce, "https://localhost:9999/return")
+	require.NoError(t, err)
+	state, err := token.Sign(app.Config().OAuthSigningKey)
+	require.NoError(t, err)
+	client := route.NewClient(server.URL).WithCookie(&http.Cookie{
+		Name:  app.Config().OAuthCookieName,
+		Value: state,
+	})
+
+	post := func(client *route.Client, requestID string, nameID string) *http.Response {
+		response, err := idp.Response(requestID, base+"/acs", base+"/metadata", nameID, nil)
+		require.NoError(t, err)
+		res, err := client.PostForm("/saml/acme/acs", url.Values{"SAMLResponse": {response}})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("sign up new identity", func(t *testing.T) {
+		res := post(client, "_"+nonce, "user@acme.com")
+		if !test.AssertRedirect(t, res, "https://localhost:9999/return") {
+			return
+		}
+		test.AssertSession(t, app.Config(), res.Cookies())
+
+		account, err := app.AccountStore.FindByOauthAccount("acme", "user@acme.com")
+		require.NoError(t, err)
+		require.NotNil(t, account)
+		assert.Equal(t, "user@acme.com", account.Username)
+	})
+
+	t.Run("log in to existing identity", func(t *testing.T) {
+		res := post(client, "_"+nonce, "user@acme.com")
+		if test.AssertRedirect(t, res, "https://localhost:9999/return") {
+			test.AssertSession(t, app.Config(), res.Cookies())
+		}
+	})
+
+	t.Run("connect new identity with current session", func(t *testing.T) {
+		account, err := app.AccountStore.Create("existing@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+
+		res := post(client.WithCookie(session), "_"+nonce, "existing-id")
+		if test.AssertRedirect(t, res, "https://localhost:9999/return") {
+			found, err := app.AccountStore.FindByOauthAccount("acme", "existing-id")
+			require.NoError(t, err)
+			assert.Equal(t, account.ID, found.ID)
+		}
+	})
+
+	t.Run("log in to identity with an authenticator", func(t *testing.T) {
+		account, err := app.AccountStore.Create("totp@acme.com", []byte("password"))
+		require.NoError(t, err)
+		err = app.AccountStore.AddOauthAccount(account.ID, "acme", "totp@acme.com", "")
+		require.NoError(t, err)
+		secret, _, err := services.TOTPEnroller(app.AccountStore, app.Config(), account.ID, "test.com")
+		require.NoError(t, err)
+		code, err := totp.Code(secret, time.Now())
+		require.NoError(t, err)
+		require.NoError(t, services.TOTPConfirmer(app.AccountStore, app.Config(), account.ID, code))
+
+		token, err := oauthtoken.New(app.Config(), nonce, "https://test.com/return")
+		require.NoError(t, err)
+		state, err := token.Sign(app.Config().OAuthSigningKey)
+		require.NoError(t, err)
+		client := route.NewClient(server.URL).WithCookie(&http.Cookie{
+			Name:  app.Config().OAuthCookieName,
+			Value: state,
+		})
+
+		res := post(client, "_"+nonce, "totp@acme.com")
+		assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+		assert.Nil(t, test.ReadCookie(res.Cookies(), app.Config().SessionCookieName))
+
+		// returns a handoff to redeem with the code
+		loc, err := res.Location()
+		require.NoError(t, err)
+		assert.Equal(t, "otp_required", loc.Query().Get("status"))
+		_, err = handoffs.Parse(loc.Query().Get("handoff_token"), app.Config(), "test.com")
+		assert.NoError(t, err)
+	})
+
+	t.Run("response to another request", func(t *testing.T) {
+		res := post(client, "_other", "user@acme.com")
+		test.AssertRedirect(t, res, "http://test.com")
+	})
+
+	t.Run("without state cookie", func(t *testing.T) {
+		res := post(route.NewClient(server.URL), "_"+nonce, "user@acme.com")
+		test.AssertRedirect(t, res, "http://test.com")
+	})
+
+	t.Run("with an invalid assertion", func(t *testing.T) {
+		other := saml.NewTestIdP()
+		defer other.Close()
+		response, err := other.Response("_"+nonce, base+"/acs", base+"/metadata", "user@acme.com", nil)
+		require.NoError(t, err)
+		res, err := client.PostForm("/saml/acme/acs", url.Values{"SAMLResponse": {response}})
+		require.NoError(t, err)
+		test.AssertRedirect(t, res, "https://localhost:9999/return?status=failed")
+	})
+}
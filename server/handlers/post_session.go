@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"github.com/keratin/authn-server/lib/parse"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server/sessions"
@@ -12,41 +15,104 @@ import (
 
 func PostSession(app *app.App) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
 		var credentials struct {
-			Username string
-			Password string
+			Username   string
+			Password   string
+			RememberMe *bool `json:"remember_me" schema:"remember_me"`
+			Nonce      string
+			OTP        string
 		}
 		if err := parse.Payload(r, &credentials); err != nil {
-			WriteErrors(w, err)
+			WriteErrors(w, r, err)
 			return
 		}
 
-		// Check the password
-		account, err := services.CredentialsVerifier(
-			app.AccountStore,
-			app.Config,
-			credentials.Username,
-			credentials.Password,
-		)
+		// Refuse clients with too many failed logins
+		ip := route.ClientIP(r)
+		delay, err := services.LoginThrottler(app.FailedLogins, cfg, ip)
+		if err != nil {
+			panic(err)
+		}
+
+		// Delay clients that keep failing to log in to the username
+		if delay <= 0 {
+			delay, err = services.LoginDelayer(app.FailedLogins, app.AccountStoreFor(r.Context()), cfg, credentials.Username, ip)
+			if err != nil {
+				panic(err)
+			}
+		}
+		if delay > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			WriteFieldErrors(w, r, http.StatusTooManyRequests, services.FieldErrors{{"credentials", services.ErrThrottled}})
+			return
+		}
+
+		// Check the password, with the directory if configured
+		var account *models.Account
+		if app.LDAP != nil {
+			account, err = services.LDAPCredentialsVerifier(
+				app.AccountStoreFor(r.Context()),
+				cfg,
+				app.LDAP,
+				credentials.Username,
+				credentials.Password,
+			)
+		} else {
+			account, err = services.CredentialsVerifier(
+				app.AccountStoreFor(r.Context()),
+				cfg,
+				credentials.Username,
+				credentials.Password,
+			)
+		}
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				recordFailedLogin(app, cfg, r, credentials.Username, ip, account, fe)
+				WriteErrors(w, r, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		// Check the second factor, if enabled
+		err = services.OTPVerifier(r.Context(), app.OTPStore, cfg, account, credentials.OTP, requestLogger(app, r))
 		if err != nil {
 			if fe, ok := err.(services.FieldErrors); ok {
-				WriteErrors(w, fe)
+				recordFailedLogin(app, cfg, r, credentials.Username, ip, account, fe)
+				WriteErrors(w, r, fe)
 				return
 			}
 
 			panic(err)
 		}
 
-		sessionToken, identityToken, err := services.SessionCreator(
-			app.AccountStore, app.RefreshTokenStore, app.KeyStore, app.Actives, app.Config, app.Reporter,
-			account.ID, route.MatchedDomain(r), sessions.GetRefreshToken(r),
-		)
+		if app.FailedLogins != nil {
+			err = app.FailedLogins.Reset(credentials.Username)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		remember := sessions.RememberMe(cfg, credentials.RememberMe)
+		sessionToken, identityToken, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     account.ID,
+			Audience:      route.MatchedDomain(r),
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+			Nonce:         credentials.Nonce,
+		})
 		if err != nil {
 			panic(err)
 		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, account.ID)
+		audit(app, r, models.AuditEntry{Action: services.AuditLogin, AccountID: account.ID, Username: credentials.Username, Actor: models.ActorAccount})
 
 		// Return the signed session in a cookie
-		sessions.Set(app.Config, w, sessionToken)
+		sessions.Set(cfg, w, sessionToken, route.MatchedDomain(r), remember)
 
 		// Return the signed identity token in the body
 		WriteData(w, http.StatusCreated, map[string]string{
@@ -54,3 +120,30 @@ func PostSession(app *app.App) http.HandlerFunc {
 		})
 	}
 }
+
+// recordFailedLogin counts a wrong password or code towards LOGIN_LOCKOUT_THRESHOLD and
+// LOGIN_IP_THRESHOLD. A missing code is not a failure, since it only asks for the second factor.
+func recordFailedLogin(app *app.App, cfg *app.Config, r *http.Request, username string, ip string, account *models.Account, fe services.FieldErrors) {
+	if account == nil {
+		var err error
+		account, err = app.AccountStoreFor(r.Context()).FindByUsername(username)
+		if err != nil {
+			panic(err)
+		}
+	}
+	entry := models.AuditEntry{Action: services.AuditLoginFailed, Username: username, Actor: models.ActorAnonymous}
+	if account != nil {
+		entry.AccountID = account.ID
+	}
+	audit(app, r, entry)
+
+	for _, e := range fe {
+		if e.Message == services.ErrFailed || e.Message == services.ErrInvalidOrExpired {
+			err := services.FailedLoginRecorder(app.FailedLogins, app.AccountStoreFor(r.Context()), cfg, username, ip)
+			if err != nil {
+				panic(err)
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/sessions"
+)
+
+func PostSessionHandoffRedeem(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := app.ConfigFor(r.Context())
+		var credentials struct {
+			Token      string
+			RememberMe *bool `json:"remember_me" schema:"remember_me"`
+			Nonce      string
+			OTP        string
+		}
+		if err := parse.Payload(r, &credentials); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+
+		// the token must have been minted for the domain that redeems it
+		accountID, err := services.HandoffTokenVerifier(
+			app.AccountStoreFor(r.Context()), cfg, credentials.Token, route.MatchedDomain(r),
+		)
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, r, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		// Check the second factor, if enabled
+		account, err := app.AccountStoreFor(r.Context()).Find(accountID)
+		if err != nil {
+			panic(err)
+		}
+		err = services.OTPVerifier(r.Context(), app.OTPStore, cfg, account, credentials.OTP, requestLogger(app, r))
+		if err != nil {
+			if fe, ok := err.(services.FieldErrors); ok {
+				WriteErrors(w, r, fe)
+				return
+			}
+
+			panic(err)
+		}
+
+		remember := sessions.RememberMe(cfg, credentials.RememberMe)
+		sessionToken, identityToken, err := services.SessionCreator(r.Context(), app, cfg, services.SessionParams{
+			AccountID:     accountID,
+			Audience:      route.MatchedDomain(r),
+			ExistingToken: sessions.GetRefreshToken(r),
+			Client:        sessions.Fingerprint(cfg, r),
+			UserAgent:     r.UserAgent(),
+			Remember:      remember,
+			Nonce:         credentials.Nonce,
+		})
+		if err != nil {
+			panic(err)
+		}
+		services.EventPublisher(app.EventQueue, cfg, app.Reporter, services.EventSessionCreated, accountID)
+		audit(app, r, models.AuditEntry{Action: services.AuditLogin, AccountID: accountID, Username: "", Actor: models.ActorAccount})
+
+		// Return the signed session in a cookie
+		sessions.Set(cfg, w, sessionToken, route.MatchedDomain(r), remember)
+
+		// Return the signed identity token in the body
+		WriteData(w, http.StatusCreated, map[string]string{
+			"id_token": identityToken,
+		})
+	}
+}
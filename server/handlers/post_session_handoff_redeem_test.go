@@ -0,0 +1,99 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostSessionHandoffRedeem(t *testing.T) {
+	app := test.App()
+	app.Config().ApplicationDomains = append(app.Config().ApplicationDomains, route.Domain{Hostname: "other.com"})
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("foo", []byte("bar"))
+	require.NoError(t, err)
+	claims, err := handoffs.New(app.Config(), account.ID, "other.com", handoffs.TTL)
+	require.NoError(t, err)
+	token, err := claims.Sign(app.Config().HandoffTokenSigningKey)
+	require.NoError(t, err)
+
+	redeem := func(domain *route.Domain) *http.Response {
+		client := route.NewClient(server.URL).Referred(domain)
+		res, err := client.PostForm("/session/handoff/redeem", url.Values{
+			"token": []string{token},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	t.Run("from another domain", func(t *testing.T) {
+		res := redeem(&app.Config().ApplicationDomains[0])
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"token", services.ErrInvalidOrExpired}})
+	})
+
+	t.Run("from the audience", func(t *testing.T) {
+		res := redeem(&app.Config().ApplicationDomains[1])
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config(), res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
+	})
+
+	t.Run("a second time", func(t *testing.T) {
+		res := redeem(&app.Config().ApplicationDomains[1])
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"token", services.ErrInvalidOrExpired}})
+	})
+}
+
+func TestPostSessionHandoffRedeemWithTOTP(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("foo", []byte("bar"))
+	require.NoError(t, err)
+	secret, _, err := services.TOTPEnroller(app.AccountStore, app.Config(), account.ID, "test.com")
+	require.NoError(t, err)
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, services.TOTPConfirmer(app.AccountStore, app.Config(), account.ID, code))
+
+	claims, err := handoffs.New(app.Config(), account.ID, "test.com", handoffs.SecondFactorTTL)
+	require.NoError(t, err)
+	token, err := claims.Sign(app.Config().HandoffTokenSigningKey)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a code", func(t *testing.T) {
+		res, err := client.PostForm("/session/handoff/redeem", url.Values{
+			"token": []string{token},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"otp", "MISSING"}})
+		assert.Nil(t, test.ReadCookie(res.Cookies(), app.Config().SessionCookieName))
+	})
+
+	t.Run("with a valid code", func(t *testing.T) {
+		res, err := client.PostForm("/session/handoff/redeem", url.Values{
+			"token": []string{token},
+			"otp":   []string{code},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config(), res.Cookies())
+	})
+}
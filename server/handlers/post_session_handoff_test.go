@@ -0,0 +1,60 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostSessionHandoff(t *testing.T) {
+	app := test.App()
+	app.Config().ApplicationDomains = append(app.Config().ApplicationDomains, route.Domain{Hostname: "other.com"})
+	server := test.Server(app)
+	defer server.Close()
+
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), 123)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("to an application domain", func(t *testing.T) {
+		res, err := client.WithCookie(session).PostForm("/session/handoff", url.Values{
+			"origin": []string{"http://other.com"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var body struct {
+			Result struct {
+				Token string `json:"token"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		claims, err := handoffs.Parse(body.Result.Token, app.Config(), "other.com")
+		require.NoError(t, err)
+		assert.Equal(t, "123", claims.Subject)
+	})
+
+	t.Run("to an unknown domain", func(t *testing.T) {
+		res, err := client.WithCookie(session).PostForm("/session/handoff", url.Values{
+			"origin": []string{"http://evil.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"origin", services.ErrNotFound}})
+	})
+
+	t.Run("without session", func(t *testing.T) {
+		res, err := client.PostForm("/session/handoff", url.Values{
+			"origin": []string{"http://other.com"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+}
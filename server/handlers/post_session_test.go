@@ -1,15 +1,22 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/keratin/authn-server/server/test"
 	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/identities"
+	"github.com/keratin/authn-server/lib/ldap"
+	"github.com/keratin/authn-server/lib/totp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,7 +29,7 @@ func TestPostSessionSuccess(t *testing.T) {
 	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
 	app.AccountStore.Create("foo", b)
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 	res, err := client.PostForm("/session", url.Values{
 		"username": []string{"foo"},
 		"password": []string{"bar"},
@@ -30,8 +37,215 @@ func TestPostSessionSuccess(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, http.StatusCreated, res.StatusCode)
-	test.AssertSession(t, app.Config, res.Cookies())
-	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+	test.AssertSession(t, app.Config(), res.Cookies())
+	test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
+
+	entries, err := app.AuditLog.List(models.AuditFilter{Action: services.AuditLogin}, 10, 0)
+	require.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "foo", entries[0].Username)
+		assert.Equal(t, models.ActorAccount, entries[0].Actor)
+		assert.Equal(t, "Go-http-client/1.1", entries[0].UserAgent)
+	}
+}
+
+func TestPostSessionRememberMe(t *testing.T) {
+	app := test.App()
+	app.Config().RefreshTokenTTL = time.Hour
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	app.AccountStore.Create("foo", b)
+
+	login := func(params url.Values) *http.Cookie {
+		params.Set("username", "foo")
+		params.Set("password", "bar")
+		client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+		res, err := client.PostForm("/session", params)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		return test.ReadCookie(res.Cookies(), app.Config().SessionCookieName)
+	}
+
+	assert.Equal(t, 0, login(url.Values{}).MaxAge)
+	assert.Equal(t, 3600, login(url.Values{"remember_me": []string{"true"}}).MaxAge)
+
+	app.Config().RememberMeDefault = true
+	assert.Equal(t, 3600, login(url.Values{}).MaxAge)
+	assert.Equal(t, 0, login(url.Values{"remember_me": []string{"false"}}).MaxAge)
+}
+
+func TestPostSessionNonce(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	app.AccountStore.Create("foo", b)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	res, err := client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+		"nonce":    []string{"n-0S6_WzA2Mj"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+
+	var body struct {
+		Result struct {
+			IDToken string `json:"id_token"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+	claims, err := identities.Parse(body.Result.IDToken, app.Config(), app.KeyStore.Keys())
+	require.NoError(t, err)
+	assert.Equal(t, "n-0S6_WzA2Mj", claims.Nonce)
+}
+
+func TestPostSessionLockout(t *testing.T) {
+	app := test.App()
+	app.Config().LoginLockoutThreshold = 3
+	app.Config().LoginFailureWindow = 15 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, _ := app.AccountStore.Create("foo", b)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	login := func(password string) *http.Response {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{password},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	// a successful login forgives earlier failures
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, http.StatusUnprocessableEntity, login("wrong").StatusCode)
+	}
+	assert.Equal(t, http.StatusCreated, login("bar").StatusCode)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusUnprocessableEntity, login("wrong").StatusCode)
+	}
+	res := login("bar")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "900", res.Header.Get("Retry-After"))
+	test.AssertErrors(t, res, services.FieldErrors{{"credentials", "THROTTLED"}})
+
+	// the lock expires with LOGIN_FAILURE_WINDOW
+	found, err := app.AccountStore.Find(account.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Locked)
+}
+
+func TestPostSessionIPThrottle(t *testing.T) {
+	app := test.App()
+	app.Config().LoginIPThreshold = 2
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	app.AccountStore.Create("foo", b)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	login := func(username string, password string) *http.Response {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{username},
+			"password": []string{password},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, login("alice", "wrong").StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, login("bob", "wrong").StatusCode)
+
+	res := login("foo", "bar")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.NotEmpty(t, res.Header.Get("Retry-After"))
+	test.AssertErrors(t, res, services.FieldErrors{{"credentials", "THROTTLED"}})
+}
+
+func TestPostSessionDelay(t *testing.T) {
+	app := test.App()
+	app.Config().LoginLockoutThreshold = 2
+	app.Config().LoginLockoutDelay = true
+	app.Config().LoginFailureWindow = 15 * time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, _ := app.AccountStore.Create("foo", b)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+	login := func(username string, password string) *http.Response {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{username},
+			"password": []string{password},
+		})
+		require.NoError(t, err)
+		return res
+	}
+
+	assert.Equal(t, http.StatusUnprocessableEntity, login("foo", "wrong").StatusCode)
+	assert.Equal(t, http.StatusUnprocessableEntity, login("foo", "wrong").StatusCode)
+
+	res := login("foo", "bar")
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "1", res.Header.Get("Retry-After"))
+	test.AssertErrors(t, res, services.FieldErrors{{"credentials", "THROTTLED"}})
+
+	found, err := app.AccountStore.Find(account.ID)
+	require.NoError(t, err)
+	assert.False(t, found.Locked)
+
+	// other usernames are not delayed
+	assert.Equal(t, http.StatusUnprocessableEntity, login("bar", "wrong").StatusCode)
+}
+
+func TestPostSessionWithLDAP(t *testing.T) {
+	app := test.App()
+	app.LDAP = ldap.TestAuthenticator{"foo": "bar"}
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("directory password", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config(), res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
+
+		account, err := app.AccountStore.FindByUsername("foo")
+		require.NoError(t, err)
+		assert.NotNil(t, account)
+	})
+
+	t.Run("local password", func(t *testing.T) {
+		b, _ := bcrypt.GenerateFromPassword([]byte("local"), 4)
+		app.AccountStore.Create("baz", b)
+
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"baz"},
+			"password": []string{"local"},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"credentials", "FAILED"}})
+	})
 }
 
 func TestPostSessionSuccessWithSession(t *testing.T) {
@@ -43,14 +257,14 @@ func TestPostSessionSuccessWithSession(t *testing.T) {
 	app.AccountStore.Create("foo", b)
 
 	accountID := 8642
-	session := test.CreateSession(app.RefreshTokenStore, app.Config, accountID)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), accountID)
 
 	// before
 	refreshTokens, err := app.RefreshTokenStore.FindAll(accountID)
 	require.NoError(t, err)
 	refreshToken := refreshTokens[0]
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0]).WithCookie(session)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0]).WithCookie(session)
 	_, err = client.PostForm("/session", url.Values{
 		"username": []string{"foo"},
 		"password": []string{"bar"},
@@ -77,7 +291,7 @@ func TestPostSessionFailure(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+		client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 		res, err := client.PostForm("/session", url.Values{
 			"username": []string{tc.username},
 			"password": []string{tc.password},
@@ -87,4 +301,114 @@ func TestPostSessionFailure(t *testing.T) {
 		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
 		test.AssertErrors(t, res, tc.errors)
 	}
+
+	t.Run("known account", func(t *testing.T) {
+		account, err := app.AccountStore.Create("foo", []byte("bar"))
+		require.NoError(t, err)
+
+		client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"wrong"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+
+		entries, err := app.AuditLog.List(models.AuditFilter{AccountID: account.ID}, 10, 0)
+		require.NoError(t, err)
+		if assert.Len(t, entries, 1) {
+			assert.Equal(t, services.AuditLoginFailed, entries[0].Action)
+			assert.Equal(t, models.ActorAnonymous, entries[0].Actor)
+			assert.Equal(t, "foo", entries[0].Username)
+		}
+	})
+}
+
+func TestPostSessionWithTOTP(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, err := app.AccountStore.Create("foo", b)
+	require.NoError(t, err)
+	secret, _, err := services.TOTPEnroller(app.AccountStore, app.Config(), account.ID, "test.com")
+	require.NoError(t, err)
+	code, err := totp.Code(secret, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, services.TOTPConfirmer(app.AccountStore, app.Config(), account.ID, code))
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a code", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"otp", "MISSING"}})
+	})
+
+	t.Run("with an invalid code", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+			"otp":      []string{"000000"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}})
+	})
+
+	t.Run("with a valid code", func(t *testing.T) {
+		res, err := client.PostForm("/session", url.Values{
+			"username": []string{"foo"},
+			"password": []string{"bar"},
+			"otp":      []string{code},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, res.StatusCode)
+		test.AssertSession(t, app.Config(), res.Cookies())
+	})
+}
+
+func TestPostSessionWithDeliveredOTP(t *testing.T) {
+	var delivered string
+	remoteApp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered = r.FormValue("otp")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer remoteApp.Close()
+
+	app := test.App()
+	app.Config().AppOTPDeliveryURL, _ = url.Parse(remoteApp.URL)
+	app.Config().OTPDeliveryTTL = time.Minute
+	server := test.Server(app)
+	defer server.Close()
+
+	b, _ := bcrypt.GenerateFromPassword([]byte("bar"), 4)
+	account, err := app.AccountStore.Create("foo", b)
+	require.NoError(t, err)
+	_, err = app.AccountStore.SetOTPDelivery(account.ID, true)
+	require.NoError(t, err)
+
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	res, err := client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	test.AssertErrors(t, res, services.FieldErrors{{"otp", "MISSING"}})
+	require.NotEmpty(t, delivered)
+
+	res, err = client.PostForm("/session", url.Values{
+		"username": []string{"foo"},
+		"password": []string{"bar"},
+		"otp":      []string{delivered},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
 }
@@ -23,19 +23,19 @@ func TestPostSessionToken(t *testing.T) {
 	server := test.Server(app)
 	defer server.Close()
 
-	client := route.NewClient(server.URL).Referred(&app.Config.ApplicationDomains[0])
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
 
 	assertSuccess := func(t *testing.T, res *http.Response, account *models.Account) {
 		assert.Equal(t, http.StatusCreated, res.StatusCode)
-		test.AssertSession(t, app.Config, res.Cookies())
-		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config)
+		test.AssertSession(t, app.Config(), res.Cookies())
+		test.AssertIDTokenResponse(t, res, app.KeyStore, app.Config())
 		found, err := app.AccountStore.Find(account.ID)
 		require.NoError(t, err)
 		assert.Equal(t, found.Password, account.Password)
 	}
 
 	factory := func(username string, password string) (*models.Account, error) {
-		hash, err := bcrypt.GenerateFromPassword([]byte(password), app.Config.BcryptCost)
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), app.Config().BcryptCost)
 		if err != nil {
 			return nil, errors.Wrap(err, "bcrypt")
 		}
@@ -49,9 +49,9 @@ func TestPostSessionToken(t *testing.T) {
 		require.NoError(t, err)
 
 		// given a passwordless token
-		token, err := passwordless.New(app.Config, account.ID)
+		token, err := passwordless.New(app.Config(), account.ID)
 		require.NoError(t, err)
-		tokenStr, err := token.Sign(app.Config.PasswordlessTokenSigningKey)
+		tokenStr, err := token.Sign(app.Config().PasswordlessTokenSigningKey)
 		require.NoError(t, err)
 
 		// invoking the endpoint
@@ -82,12 +82,12 @@ func TestPostSessionToken(t *testing.T) {
 		require.NoError(t, err)
 
 		// given a session
-		session := test.CreateSession(app.RefreshTokenStore, app.Config, account.ID)
+		session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
 
 		// given a passwordless token
-		token, err := passwordless.New(app.Config, account.ID)
+		token, err := passwordless.New(app.Config(), account.ID)
 		require.NoError(t, err)
-		tokenStr, err := token.Sign(app.Config.PasswordlessTokenSigningKey)
+		tokenStr, err := token.Sign(app.Config().PasswordlessTokenSigningKey)
 		require.NoError(t, err)
 
 		// invoking the endpoint
@@ -100,7 +100,7 @@ func TestPostSessionToken(t *testing.T) {
 		assertSuccess(t, res, account)
 
 		// invalidates old session
-		claims, err := sessions.Parse(session.Value, app.Config)
+		claims, err := sessions.Parse(session.Value, app.Config())
 		require.NoError(t, err)
 		id, err := app.RefreshTokenStore.Find(models.RefreshToken(claims.Subject))
 		require.NoError(t, err)
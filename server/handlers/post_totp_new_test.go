@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/lib/totp"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostTOTPNew(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	account, err := app.AccountStore.Create("totp@keratin.tech", []byte("password"))
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+	client := route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0])
+
+	t.Run("without a session", func(t *testing.T) {
+		res, err := client.PostForm("/totp/new", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	})
+
+	t.Run("enrolling and confirming", func(t *testing.T) {
+		res, err := client.WithCookie(session).PostForm("/totp/new", url.Values{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+
+		var body struct {
+			Result struct {
+				Secret string `json:"secret"`
+				URL    string `json:"url"`
+			} `json:"result"`
+		}
+		require.NoError(t, json.Unmarshal(test.ReadBody(res), &body))
+		assert.Contains(t, body.Result.URL, "otpauth://totp/test.com:totp@keratin.tech")
+
+		res, err = client.WithCookie(session).PostForm("/totp/confirm", url.Values{"otp": []string{"000000"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"otp", "INVALID_OR_EXPIRED"}})
+
+		code, err := totp.Code(body.Result.Secret, time.Now())
+		require.NoError(t, err)
+		res, err = client.WithCookie(session).PostForm("/totp/confirm", url.Values{"otp": []string{code}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+
+		found, err := app.AccountStore.Find(account.ID)
+		require.NoError(t, err)
+		assert.True(t, found.TOTPEnabled())
+	})
+
+	t.Run("enrolling again", func(t *testing.T) {
+		res, err := client.WithCookie(session).PostForm("/totp/new", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"totp", "TAKEN"}})
+	})
+}
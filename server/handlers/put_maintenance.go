@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/pkg/errors"
+)
+
+// defaultMaintenanceWindow is how long maintenance is expected to take, unless the request says
+const defaultMaintenanceWindow = time.Hour
+
+func PutMaintenance(app *app.App) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var params struct {
+			RetryAfter *int `json:"retry_after" schema:"retry_after"`
+		}
+		if err := parse.Payload(r, &params); err != nil {
+			WriteErrors(w, r, err)
+			return
+		}
+
+		window := defaultMaintenanceWindow
+		if params.RetryAfter != nil {
+			if *params.RetryAfter <= 0 {
+				WriteErrors(w, r, services.FieldErrors{{"retry_after", services.ErrFormatInvalid}})
+				return
+			}
+			window = time.Duration(*params.RetryAfter) * time.Second
+		}
+
+		err := app.Maintenance.Enable(time.Now().Add(window).Truncate(time.Second))
+		if err != nil {
+			panic(errors.Wrap(err, "Enable"))
+		}
+
+		writeMaintenance(app, app.ConfigFor(r.Context()), w)
+	}
+}
@@ -0,0 +1,61 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPutMaintenance(t *testing.T) {
+	app := test.App()
+	server := test.Server(app)
+	defer server.Close()
+
+	client := route.NewClient(server.URL).Authenticated(app.Config().AuthUsername, app.Config().AuthPassword)
+
+	t.Run("with default window", func(t *testing.T) {
+		res, err := client.Put("/maintenance", url.Values{})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		status := maintenanceStatus{}
+		require.NoError(t, test.ExtractResult(res, &status))
+		assert.True(t, status.Enabled)
+		require.NotNil(t, status.Until)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), *status.Until, 2*time.Second)
+
+		res, err = route.NewClient(server.URL).Referred(&app.Config().ApplicationDomains[0]).PostForm("/session", url.Values{})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, res.StatusCode)
+		assert.NotEmpty(t, res.Header.Get("Retry-After"))
+
+		entries, err := app.AuditLog.List(models.AuditFilter{Action: "maintenance.enabled"}, 10, 0)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("with retry_after", func(t *testing.T) {
+		res, err := client.Put("/maintenance", url.Values{"retry_after": []string{"600"}})
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		until, err := app.Maintenance.Until()
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), until, 2*time.Second)
+	})
+
+	t.Run("with invalid retry_after", func(t *testing.T) {
+		res, err := client.Put("/maintenance", url.Values{"retry_after": []string{"-1"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		test.AssertErrors(t, res, services.FieldErrors{{"retry_after", services.ErrFormatInvalid}})
+	})
+}
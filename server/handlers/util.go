@@ -3,14 +3,21 @@ package handlers
 import (
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/handoffs"
 	"github.com/keratin/authn-server/app/tokens/oauth"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/ops"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
-// nonceCookie creates or deletes a cookie containing val (the nonce)
+// nonceCookie creates or deletes a cookie containing val (the nonce, or the state for SAML)
 func nonceCookie(cfg *app.Config, val string) *http.Cookie {
 	var maxAge int
 	if val == "" {
@@ -19,14 +26,15 @@ func nonceCookie(cfg *app.Config, val string) *http.Cookie {
 		maxAge = int(time.Hour.Seconds())
 	}
 
+	sameSite := cfg.NonceSameSite()
 	return &http.Cookie{
 		Name:     cfg.OAuthCookieName,
 		Value:    val,
 		Path:     cfg.MountedPath,
-		Secure:   cfg.ForceSSL,
+		Secure:   cfg.SecureCookies() || sameSite == http.SameSiteNoneMode,
 		HttpOnly: true,
 		MaxAge:   maxAge,
-		SameSite: cfg.SameSiteComputed(),
+		SameSite: sameSite,
 	}
 }
 
@@ -43,6 +51,28 @@ func getState(cfg *app.Config, r *http.Request) (*oauth.Claims, error) {
 	return state, err
 }
 
+// samlRequestID converts a nonce into an XML ID for an AuthnRequest
+func samlRequestID(nonce string) string {
+	return "_" + nonce
+}
+
+// getSamlState returns a verified state token from the nonce cookie, for the AuthnRequest that a
+// SAML response is in reply to
+func getSamlState(cfg *app.Config, r *http.Request, requestID string) (*oauth.Claims, error) {
+	cookie, err := r.Cookie(cfg.OAuthCookieName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cookie")
+	}
+	if !strings.HasPrefix(requestID, "_") {
+		return nil, errors.New("unknown request")
+	}
+	state, err := oauth.Parse(cookie.Value, cfg, strings.TrimPrefix(requestID, "_"))
+	if err != nil {
+		return nil, errors.Wrap(err, "Parse")
+	}
+	return state, nil
+}
+
 // redirectFailure is a redirect with status=failed added to the destination
 func redirectFailure(w http.ResponseWriter, r *http.Request, destination string) {
 	url, _ := url.Parse(destination)
@@ -51,3 +81,42 @@ func redirectFailure(w http.ResponseWriter, r *http.Request, destination string)
 	url.RawQuery = query.Encode()
 	http.Redirect(w, r, url.String(), http.StatusSeeOther)
 }
+
+// destinationDomain finds the application domain that an OAuth or SAML login will return to, so that
+// the session is issued for that domain. It falls back to the first domain if nothing matches.
+func destinationDomain(cfg *app.Config, destination string) *route.Domain {
+	if domain := route.FindDomain(destination, cfg.ApplicationDomains); domain != nil {
+		return domain
+	}
+	return &cfg.ApplicationDomains[0]
+}
+
+// redirectSecondFactor finishes an OAuth or SAML login to an account with a second factor without
+// a session. The destination receives status=otp_required and a handoff_token instead, which it
+// may redeem with the account's code.
+func redirectSecondFactor(w http.ResponseWriter, r *http.Request, cfg *app.Config, accountID int, destination string) error {
+	token, err := services.HandoffTokenCreator(cfg, accountID, destination, handoffs.SecondFactorTTL)
+	if err != nil {
+		return err
+	}
+
+	url, _ := url.Parse(destination)
+	query := url.Query()
+	query.Add("status", "otp_required")
+	query.Add("handoff_token", token)
+	url.RawQuery = query.Encode()
+	http.Redirect(w, r, url.String(), http.StatusSeeOther)
+	return nil
+}
+
+// audit records an action by the client in the audit log
+func audit(app *app.App, r *http.Request, entry models.AuditEntry) {
+	entry.IP = route.ClientIP(r)
+	entry.UserAgent = r.UserAgent()
+	services.AuditRecorder(app.AuditLog, app.Reporter, &entry)
+}
+
+// requestLogger returns the app's logger with the request's ID, for services that log
+func requestLogger(app *app.App, r *http.Request) logrus.FieldLogger {
+	return app.Logger.WithField("requestID", ops.RequestID(r))
+}
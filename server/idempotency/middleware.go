@@ -0,0 +1,160 @@
+// Package idempotency replays responses to retries of a request with the same Idempotency-Key.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/ops"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/pkg/errors"
+)
+
+// Header is sent by clients to identify retries of a request
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks a response that was replayed
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength limits the keys that clients may choose
+const maxKeyLength = 255
+
+// maxBody limits how much of a request is read to fingerprint it
+const maxBody = 1 << 20
+
+// Middleware replays the response to an action, like "signup", when the request is retried with
+// the same Idempotency-Key within IDEMPOTENCY_KEY_TTL. A key may only be used for one request, as
+// identified by its method, path, query, and body, so that a key can not replay the response to
+// someone else's request. Unexpected errors are not saved, so that the request may be retried.
+func Middleware(app *app.App, action string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if app.Idempotency == nil {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
+			key := r.Header.Get(Header)
+			if key == "" {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				handlers.WriteErrors(w, r, services.FieldErrors{{"idempotency_key", services.ErrFormatInvalid}})
+				return
+			}
+			key = action + ":" + key
+
+			fingerprint, err := fingerprint(cfg, r)
+			if err != nil {
+				handlers.WriteErrors(w, r, services.FieldErrors{{"idempotency_key", services.ErrFormatInvalid}})
+				return
+			}
+
+			saved, ok, err := app.Idempotency.Claim(key)
+			if err != nil {
+				panic(errors.Wrap(err, "Claim"))
+			}
+			if !ok {
+				handlers.WriteFieldErrors(w, r, http.StatusConflict,
+					services.FieldErrors{{"idempotency_key", services.ErrInProgress}})
+				return
+			}
+			if saved != nil {
+				if saved.Fingerprint != fingerprint {
+					handlers.WriteErrors(w, r, services.FieldErrors{{"idempotency_key", services.ErrTaken}})
+					return
+				}
+				replay(w, saved)
+				return
+			}
+
+			release := func() {
+				err := app.Idempotency.Release(key)
+				if err != nil {
+					app.Reporter.ReportRequestError(errors.Wrap(err, "Release"), r)
+				}
+			}
+			defer func() {
+				if p := recover(); p != nil {
+					release()
+					panic(p)
+				}
+			}()
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			h.ServeHTTP(rec, r)
+			if rec.status >= 500 {
+				release()
+				return
+			}
+
+			err = app.Idempotency.Save(key, &models.IdempotentResponse{
+				Fingerprint: fingerprint,
+				Status:      rec.status,
+				Header:      rec.Header().Clone(),
+				Body:        rec.body.Bytes(),
+			}, cfg.IdempotencyKeyTTL)
+			if err != nil {
+				app.Reporter.ReportRequestError(errors.Wrap(err, "Save"), r)
+			}
+		})
+	}
+}
+
+// fingerprint identifies a request with an HMAC, since the body may contain a password. The
+// body is left for the handler.
+func fingerprint(cfg *app.Config, r *http.Request) (string, error) {
+	mac := hmac.New(sha256.New, cfg.IdempotencySigningKey)
+	mac.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			return "", err
+		}
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		mac.Write(body)
+	}
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// replay writes a saved response. The request keeps its own ID.
+func replay(w http.ResponseWriter, saved *models.IdempotentResponse) {
+	for name, values := range saved.Header {
+		if name != http.CanonicalHeaderKey(ops.RequestIDHeader) {
+			w.Header()[name] = values
+		}
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(saved.Status)
+	w.Write(saved.Body)
+}
+
+// recorder keeps a copy of the response while it is written
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,107 @@
+package idempotency_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/ops"
+	"github.com/keratin/authn-server/server/idempotency"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	var calls int
+	created := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	})
+	send := func(app *app.App, h http.Handler, key string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/accounts", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		res := httptest.NewRecorder()
+		ops.PanicHandler(app.Reporter, idempotency.Middleware(app, "signup")(h)).ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("without a key", func(t *testing.T) {
+		app := test.App()
+		calls = 0
+		send(app, created, "", `{"username":"alice"}`)
+		send(app, created, "", `{"username":"alice"}`)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("retried", func(t *testing.T) {
+		app := test.App()
+		calls = 0
+		res := send(app, created, "abc", `{"username":"alice"}`)
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Empty(t, res.Header().Get("Idempotent-Replayed"))
+
+		res = send(app, created, "abc", `{"username":"alice"}`)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, res.Code)
+		assert.Equal(t, "application/json", res.Header().Get("Content-Type"))
+		assert.Equal(t, "true", res.Header().Get("Idempotent-Replayed"))
+		assert.Equal(t, `{"username":"alice"}`, res.Body.String())
+
+		res = send(app, created, "def", `{"username":"alice"}`)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("reused for another request", func(t *testing.T) {
+		app := test.App()
+		calls = 0
+		send(app, created, "abc", `{"username":"alice"}`)
+
+		res := send(app, created, "abc", `{"username":"bob"}`)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"idempotency_key","message":"TAKEN"}]}`, res.Body.String())
+	})
+
+	t.Run("in progress", func(t *testing.T) {
+		app := test.App()
+		app.Idempotency.Claim("signup:abc")
+
+		res := send(app, created, "abc", `{"username":"alice"}`)
+		assert.Equal(t, http.StatusConflict, res.Code)
+		assert.Equal(t, `{"errors":[{"field":"idempotency_key","message":"IN_PROGRESS"}]}`, res.Body.String())
+	})
+
+	t.Run("unexpected error", func(t *testing.T) {
+		app := test.App()
+		failing := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(errors.New("connection refused"))
+		})
+
+		res := send(app, failing, "abc", `{"username":"alice"}`)
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+		calls = 0
+		res = send(app, created, "abc", `{"username":"alice"}`)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, res.Code)
+	})
+
+	t.Run("without redis", func(t *testing.T) {
+		app := test.App()
+		app.Idempotency = nil
+		calls = 0
+		send(app, created, "abc", `{"username":"alice"}`)
+		send(app, created, "abc", `{"username":"alice"}`)
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"net/http"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/ops"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/sirupsen/logrus"
+)
+
+// Middleware logs each request with its route, status, and latency. The account ID is included
+// when the handler has looked up the session, so that logging never costs a query. Query strings
+// are not logged, since they may contain tokens.
+//
+// This must be wrapped by the sessions middleware, and is given the router to name routes.
+func Middleware(app *app.App, router *mux.Router) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			metrics := httpsnoop.CaptureMetrics(h, w, r)
+
+			fields := logrus.Fields{
+				"method":     r.Method,
+				"route":      routeName(router, r),
+				"path":       r.URL.Path,
+				"status":     metrics.Code,
+				"latencyMS":  float64(metrics.Duration.Nanoseconds()) / 1e6,
+				"remoteAddr": r.RemoteAddr,
+				"userAgent":  r.UserAgent(),
+				"requestID":  ops.RequestID(r),
+			}
+			if id := sessions.GetResolvedAccountID(r); id != 0 {
+				fields["accountID"] = id
+			}
+
+			entry := app.Logger.WithFields(fields)
+			if metrics.Code >= 500 {
+				entry.Error("request")
+			} else {
+				entry.Info("request")
+			}
+		})
+	}
+}
+
+// routeName describes the matched route like the metrics do, e.g. "GET /accounts/{id}". Requests
+// that match no route are named by their method alone.
+func routeName(router *mux.Router, r *http.Request) string {
+	var match mux.RouteMatch
+	if router.Match(r, &match) && match.Route != nil {
+		if tpl, err := match.Route.GetPathTemplate(); err == nil {
+			return r.Method + " " + tpl
+		}
+	}
+	return r.Method
+}
@@ -0,0 +1,75 @@
+package logging_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/keratin/authn-server/server/logging"
+	"github.com/keratin/authn-server/server/sessions"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	app := test.App()
+	logger, hook := logtest.NewNullLogger()
+	app.Logger = logger
+	account, err := app.AccountStore.Create("logged", []byte("password"))
+	require.NoError(t, err)
+	session := test.CreateSession(app.RefreshTokenStore, app.Config(), account.ID)
+
+	router := mux.NewRouter()
+	router.Methods("GET").Path("/accounts/{id}").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	router.Methods("GET").Path("/session").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessions.GetAccountID(r)
+	})
+	router.Methods("GET").Path("/broken").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	handler := sessions.Middleware(app)(logging.Middleware(app, router)(router))
+
+	serve := func(path string) *logrus.Entry {
+		hook.Reset()
+		req := httptest.NewRequest("GET", path, nil)
+		req.AddCookie(session)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		require.Len(t, hook.Entries, 1)
+		return hook.LastEntry()
+	}
+
+	t.Run("matched route", func(t *testing.T) {
+		entry := serve("/accounts/123?token=secret")
+		assert.Equal(t, logrus.InfoLevel, entry.Level)
+		assert.Equal(t, "request", entry.Message)
+		assert.Equal(t, "GET", entry.Data["method"])
+		assert.Equal(t, "GET /accounts/{id}", entry.Data["route"])
+		assert.Equal(t, "/accounts/123", entry.Data["path"])
+		assert.Equal(t, http.StatusTeapot, entry.Data["status"])
+		assert.Contains(t, entry.Data, "latencyMS")
+		// the session was not needed
+		assert.NotContains(t, entry.Data, "accountID")
+	})
+
+	t.Run("with a session", func(t *testing.T) {
+		entry := serve("/session")
+		assert.Equal(t, account.ID, entry.Data["accountID"])
+	})
+
+	t.Run("unmatched route", func(t *testing.T) {
+		entry := serve("/unknown")
+		assert.Equal(t, "GET", entry.Data["route"])
+		assert.Equal(t, http.StatusNotFound, entry.Data["status"])
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		entry := serve("/broken")
+		assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	})
+}
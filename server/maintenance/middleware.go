@@ -0,0 +1,71 @@
+package maintenance
+
+import (
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/pkg/errors"
+)
+
+// defaultRetryAfter is suggested to clients during MAINTENANCE_MODE, which has no expected end
+const defaultRetryAfter = 5 * time.Minute
+
+// minRetryAfter is suggested to clients when maintenance is running long
+const minRetryAfter = time.Minute
+
+// reads are POST routes that only query
+var reads = regexp.MustCompile(`/(graphql|introspect|maintenance)$`)
+
+// writes are GET routes that create accounts or sessions, or send tokens
+var writes = regexp.MustCompile(`/(password/reset|session/token|email/verification|oauth/[^/]+/return)$`)
+
+// Middleware refuses mutating requests during maintenance with 503 Service Unavailable, so that the
+// database may be maintained. Requests that only read, like refreshing a session or fetching keys,
+// are still served, as is the /maintenance switch. When the switch can not be read, requests are
+// served as usual.
+func Middleware(app *app.App) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
+			if !mutating(r) {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			until, err := app.Maintenance.Until()
+			if err != nil {
+				app.Reporter.ReportRequestError(errors.Wrap(err, "Until"), r)
+			}
+			if !cfg.MaintenanceMode && until.IsZero() {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			retryAfter := defaultRetryAfter
+			if !until.IsZero() {
+				retryAfter = time.Until(until)
+				if retryAfter < minRetryAfter {
+					retryAfter = minRetryAfter
+				}
+			}
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			handlers.WriteFieldErrors(w, r, http.StatusServiceUnavailable,
+				services.FieldErrors{{"request", services.ErrMaintenance}})
+		})
+	}
+}
+
+func mutating(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return writes.MatchString(r.URL.Path)
+	default:
+		return !reads.MatchString(r.URL.Path)
+	}
+}
@@ -0,0 +1,75 @@
+package maintenance_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/maintenance"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	send := func(app *app.App, method string, path string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		maintenance.Middleware(app)(ok).ServeHTTP(res, httptest.NewRequest(method, path, nil))
+		return res
+	}
+
+	t.Run("without maintenance", func(t *testing.T) {
+		app := test.App()
+		res := send(app, "POST", "/session")
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("with maintenance", func(t *testing.T) {
+		app := test.App()
+		require.NoError(t, app.Maintenance.Enable(time.Now().Add(time.Hour)))
+
+		res := send(app, "POST", "/session")
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+		assert.Equal(t, "3600", res.Header().Get("Retry-After"))
+		assert.Equal(t, `{"errors":[{"field":"request","message":"MAINTENANCE"}]}`, res.Body.String())
+
+		for _, path := range []string{"/session/refresh", "/jwks", "/accounts/1", "/health"} {
+			res = send(app, "GET", path)
+			assert.Equal(t, http.StatusOK, res.Code, path)
+		}
+		for _, path := range []string{"/graphql", "/introspect"} {
+			res = send(app, "POST", path)
+			assert.Equal(t, http.StatusOK, res.Code, path)
+		}
+		res = send(app, "DELETE", "/maintenance")
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		for _, path := range []string{"/password/reset", "/oauth/google/return"} {
+			res = send(app, "GET", path)
+			assert.Equal(t, http.StatusServiceUnavailable, res.Code, path)
+		}
+		res = send(app, "PATCH", "/accounts/1/lock")
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	})
+
+	t.Run("running long", func(t *testing.T) {
+		app := test.App()
+		require.NoError(t, app.Maintenance.Enable(time.Now().Add(-time.Hour)))
+
+		res := send(app, "POST", "/session")
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+		assert.Equal(t, "60", res.Header().Get("Retry-After"))
+	})
+
+	t.Run("with MAINTENANCE_MODE", func(t *testing.T) {
+		app := test.App()
+		app.Config().MaintenanceMode = true
+
+		res := send(app, "POST", "/session")
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+		assert.Equal(t, "300", res.Header().Get("Retry-After"))
+	})
+}
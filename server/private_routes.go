@@ -3,79 +3,283 @@ package server
 import (
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/audit"
 	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/idempotency"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 func PrivateRoutes(app *app.App) []*route.HandledRoute {
 	var routes []*route.HandledRoute
-	authentication := route.BasicAuthSecurity(app.Config.AuthUsername, app.Config.AuthPassword, "Private AuthN Realm")
+	authentication := route.BasicAuthSecurity(app.Config().AuthUsername, app.Config().AuthPassword, "Private AuthN Realm")
 
 	routes = append(routes,
 		route.Get("/").
+			Describe("Root").
 			SecuredWith(route.Unsecured()).
 			Handle(handlers.GetRoot(app)),
 
 		route.Get("/jwks").
+			Describe("JSON Web Keys").
+			SecuredWith(route.Unsecured()).
+			Handle(handlers.GetJWKs(app)),
+
+		route.Get("/.well-known/jwks.json").
+			Describe("JSON Web Keys").
 			SecuredWith(route.Unsecured()).
 			Handle(handlers.GetJWKs(app)),
 
 		route.Get("/configuration").
+			Describe("Service Configuration").
 			SecuredWith(route.Unsecured()).
 			Handle(handlers.GetConfiguration(app)),
 
+		route.Get("/admin").
+			Describe("Dashboard").
+			SecuredWith(authentication).
+			Handle(handlers.GetAdmin(app)),
+
 		route.Get("/metrics").
+			Describe("Server Stats").
 			SecuredWith(authentication).
 			Handle(promhttp.Handler()),
 
+		route.Post("/introspect").
+			Describe("Introspect Token",
+				route.Required("token", "string"),
+				route.Optional("token_type_hint", "string")).
+			SecuredWith(authentication).
+			Handle(handlers.PostIntrospect(app)),
+
 		route.Post("/accounts/import").
+			Describe("Import Account",
+				route.Required("username", "string"),
+				route.Required("password", "string"),
+				route.Optional("locked", "boolean")).
+			SecuredWith(authentication).
+			Handle(idempotency.Middleware(app, "import")(audit.Middleware(app, "account.imported")(handlers.PostAccountsImport(app)))),
+
+		route.Post("/accounts/import/batch").
+			Describe("Import Accounts in Batch").
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "accounts.imported")(handlers.PostAccountsImportBatch(app))),
+
+		route.Post("/invitations").
+			Describe("Create Invitation", route.Optional("email", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "invitation.created")(handlers.PostInvitation(app))),
+
+		route.Get("/accounts").
+			Describe("List Accounts",
+				route.Optional("page", "integer"),
+				route.Optional("per_page", "integer"),
+				route.Optional("username", "string")).
+			SecuredWith(authentication).
+			Handle(handlers.GetAccounts(app)),
+
+		route.Get("/audit").
+			Describe("List Audit Log",
+				route.Optional("page", "integer"),
+				route.Optional("per_page", "integer"),
+				route.Optional("account_id", "integer"),
+				route.Optional("action", "string")).
 			SecuredWith(authentication).
-			Handle(handlers.PostAccountsImport(app)),
+			Handle(handlers.GetAudit(app)),
+
+		route.Get("/maintenance").
+			Describe("Get Maintenance").
+			SecuredWith(authentication).
+			Handle(handlers.GetMaintenance(app)),
+
+		route.Put("/maintenance").
+			Describe("Begin Maintenance", route.Optional("retry_after", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "maintenance.enabled")(handlers.PutMaintenance(app))),
+
+		route.Delete("/maintenance").
+			Describe("End Maintenance").
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "maintenance.disabled")(handlers.DeleteMaintenance(app))),
+
+		route.Post("/graphql").
+			Describe("GraphQL Query",
+				route.Required("query", "string"),
+				route.Optional("operationName", "string"),
+				route.Optional("variables", "object")).
+			SecuredWith(authentication).
+			Handle(handlers.PostGraphQL(app)),
 
 		route.Get("/accounts/{id:[0-9]+}").
+			Describe("Get Account", route.Required("id", "integer")).
 			SecuredWith(authentication).
 			Handle(handlers.GetAccount(app)),
 
+		route.Get("/accounts/{id:[0-9]+}/export").
+			Describe("Export Account", route.Required("id", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.exported")(handlers.GetAccountExport(app))),
+
 		route.Patch("/accounts/{id:[0-9]+}").
+			Describe("Update",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccount(app)),
+			Handle(audit.Middleware(app, "account.updated")(handlers.PatchAccount(app))),
 
 		route.Patch("/accounts/{id:[0-9]+}/lock").
+			Describe("Lock Account", route.Required("id", "integer")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountLock(app)),
+			Handle(audit.Middleware(app, "account.locked")(handlers.PatchAccountLock(app))),
 
 		route.Patch("/accounts/{id:[0-9]+}/unlock").
+			Describe("Unlock Account", route.Required("id", "integer")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountUnlock(app)),
+			Handle(audit.Middleware(app, "account.unlocked")(handlers.PatchAccountUnlock(app))),
 
 		route.Patch("/accounts/{id:[0-9]+}/expire_password").
+			Describe("Expire Password", route.Required("id", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.password_expired")(handlers.PatchAccountExpirePassword(app))),
+
+		route.Patch("/accounts/{id:[0-9]+}/roles").
+			Describe("Set Account Roles",
+				route.Required("id", "integer"),
+				route.Required("roles", "array")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountExpirePassword(app)),
+			Handle(audit.Middleware(app, "account.roles_updated")(handlers.PatchAccountRoles(app))),
+
+		route.Patch("/accounts/{id:[0-9]+}/metadata").
+			Describe("Set Account Metadata",
+				route.Required("id", "integer"),
+				route.Required("metadata", "object")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.metadata_updated")(handlers.PatchAccountMetadata(app))),
 
 		route.Put("/accounts/{id:[0-9]+}").
+			Describe("Update",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccount(app)),
+			Handle(audit.Middleware(app, "account.updated")(handlers.PatchAccount(app))),
 
 		route.Put("/accounts/{id:[0-9]+}/lock").
+			Describe("Lock Account", route.Required("id", "integer")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountLock(app)),
+			Handle(audit.Middleware(app, "account.locked")(handlers.PatchAccountLock(app))),
 
 		route.Put("/accounts/{id:[0-9]+}/unlock").
+			Describe("Unlock Account", route.Required("id", "integer")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountUnlock(app)),
+			Handle(audit.Middleware(app, "account.unlocked")(handlers.PatchAccountUnlock(app))),
 
 		route.Put("/accounts/{id:[0-9]+}/expire_password").
+			Describe("Expire Password", route.Required("id", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.password_expired")(handlers.PatchAccountExpirePassword(app))),
+
+		route.Put("/accounts/{id:[0-9]+}/roles").
+			Describe("Set Account Roles",
+				route.Required("id", "integer"),
+				route.Required("roles", "array")).
 			SecuredWith(authentication).
-			Handle(handlers.PatchAccountExpirePassword(app)),
+			Handle(audit.Middleware(app, "account.roles_updated")(handlers.PatchAccountRoles(app))),
+
+		route.Put("/accounts/{id:[0-9]+}/metadata").
+			Describe("Set Account Metadata",
+				route.Required("id", "integer"),
+				route.Required("metadata", "object")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.metadata_updated")(handlers.PatchAccountMetadata(app))),
+
+		route.Post("/accounts/{id:[0-9]+}/aliases").
+			Describe("Add Alias",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "alias.created")(handlers.PostAccountAlias(app))),
+
+		route.Patch("/accounts/{id:[0-9]+}/aliases/primary").
+			Describe("Promote Alias",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "alias.promoted")(handlers.PatchAccountAliasPrimary(app))),
+
+		route.Put("/accounts/{id:[0-9]+}/aliases/primary").
+			Describe("Promote Alias",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "alias.promoted")(handlers.PatchAccountAliasPrimary(app))),
+
+		route.Delete("/accounts/{id:[0-9]+}/aliases").
+			Describe("Remove Alias",
+				route.Required("id", "integer"),
+				route.Required("username", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "alias.deleted")(handlers.DeleteAccountAlias(app))),
+
+		route.Post("/accounts/{id:[0-9]+}/impersonation").
+			Describe("Impersonate Account",
+				route.Required("id", "integer"),
+				route.Required("impersonated_by", "string"),
+				route.Optional("origin", "string")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.impersonated")(handlers.PostAccountImpersonation(app))),
 
 		route.Delete("/accounts/{id:[0-9]+}").
+			Describe("Archive Account", route.Required("id", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "account.archived")(handlers.DeleteAccount(app))),
+
+		route.Delete("/accounts/{id:[0-9]+}/sessions").
+			Describe("Revoke Account Sessions", route.Required("id", "integer")).
+			SecuredWith(authentication).
+			Handle(audit.Middleware(app, "sessions.revoked")(handlers.DeleteAccountSessions(app))),
+
+		route.Delete("/accounts/{id:[0-9]+}/totp").
+			Describe("Reset Authenticator", route.Required("id", "integer")).
 			SecuredWith(authentication).
-			Handle(handlers.DeleteAccount(app)),
+			Handle(audit.Middleware(app, "totp.deleted")(handlers.DeleteAccountTOTP(app))),
 	)
 
+	if app.Config().AppOTPDeliveryURL != nil {
+		routes = append(routes,
+			route.Patch("/accounts/{id:[0-9]+}/otp_delivery").
+				Describe("Enable OTP Delivery", route.Required("id", "integer")).
+				SecuredWith(authentication).
+				Handle(audit.Middleware(app, "otp_delivery.updated")(handlers.PatchAccountOTPDelivery(app))),
+
+			route.Put("/accounts/{id:[0-9]+}/otp_delivery").
+				Describe("Enable OTP Delivery", route.Required("id", "integer")).
+				SecuredWith(authentication).
+				Handle(audit.Middleware(app, "otp_delivery.updated")(handlers.PatchAccountOTPDelivery(app))),
+
+			route.Delete("/accounts/{id:[0-9]+}/otp_delivery").
+				Describe("Disable OTP Delivery", route.Required("id", "integer")).
+				SecuredWith(authentication).
+				Handle(audit.Middleware(app, "otp_delivery.deleted")(handlers.DeleteAccountOTPDelivery(app))),
+		)
+	}
+
+	if app.TokenDenylist != nil {
+		routes = append(routes,
+			route.Post("/access_tokens/revoke").
+				Describe("Revoke Access Token",
+					route.Optional("token", "string"),
+					route.Optional("jti", "string")).
+				SecuredWith(authentication).
+				Handle(audit.Middleware(app, "access_token.revoked")(handlers.PostAccessTokenRevoke(app))),
+		)
+	}
+
 	if app.Actives != nil {
 		routes = append(routes,
 			route.Get("/stats").
+				Describe("Service Stats",
+					route.Optional("granularity", "string"),
+					route.Optional("from", "string"),
+					route.Optional("to", "string")).
 				SecuredWith(authentication).
 				Handle(handlers.GetStats(app)),
 		)
@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/handlers"
+	"github.com/keratin/authn-server/app"
+)
+
+// Middleware reads the client IP, scheme, and host from proxy headers. With TrustedProxies, the
+// headers are only read from trusted peers, and the client IP is the last address in
+// X-Forwarded-For that is not a trusted proxy. Otherwise, the headers are read from every peer.
+// Peers on a Unix socket are always trusted, since they share the host.
+func Middleware(app *app.App) func(http.Handler) http.Handler {
+	trusted := app.Config().TrustedProxies
+	return func(h http.Handler) http.Handler {
+		proxied := handlers.ProxyHeaders(h)
+		if len(trusted) == 0 {
+			return proxied
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isUnixPeer(r.RemoteAddr) && !isTrusted(trusted, peerIP(r.RemoteAddr)) {
+				h.ServeHTTP(w, r)
+				return
+			}
+			// a proxy may append its own X-Forwarded-For line instead of extending the first
+			if fwd := strings.Join(r.Header.Values("X-Forwarded-For"), ","); fwd != "" {
+				r.Header.Set("X-Forwarded-For", clientIP(trusted, fwd))
+			}
+			proxied.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP walks X-Forwarded-For from the nearest proxy back toward the client, and returns the
+// first address that was not added by a trusted proxy.
+func clientIP(trusted []*net.IPNet, fwd string) string {
+	ips := strings.Split(fwd, ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if i == 0 || !isTrusted(trusted, net.ParseIP(ip)) {
+			return ip
+		}
+	}
+	return ""
+}
+
+// isUnixPeer recognizes the RemoteAddr of a Unix socket connection, which has no IP
+func isUnixPeer(remoteAddr string) bool {
+	return remoteAddr == "@" || remoteAddr == ""
+}
+
+func peerIP(remoteAddr string) net.IP {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return net.ParseIP(host)
+}
+
+func isTrusted(trusted []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
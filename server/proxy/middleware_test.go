@@ -0,0 +1,60 @@
+package proxy_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/server/proxy"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	serve := func(trusted []*net.IPNet, peer string, fwd ...string) string {
+		app := test.App()
+		app.Config().Proxied = true
+		app.Config().TrustedProxies = trusted
+
+		var seen string
+		h := proxy.Middleware(app)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			seen = r.RemoteAddr
+		}))
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = peer
+		for _, line := range fwd {
+			req.Header.Add("X-Forwarded-For", line)
+		}
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return seen
+	}
+
+	_, lan, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{lan}
+
+	t.Run("without trusted proxies", func(t *testing.T) {
+		assert.Equal(t, "1.1.1.1", serve(nil, "203.0.113.9:1234", "1.1.1.1, 10.0.0.2"))
+	})
+
+	t.Run("from a trusted proxy", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", serve(trusted, "10.0.0.1:1234", "1.1.1.1, 198.51.100.7, 10.0.0.2"))
+	})
+
+	t.Run("with several header lines", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", serve(trusted, "10.0.0.1:1234", "1.1.1.1", "198.51.100.7, 10.0.0.2"))
+	})
+
+	t.Run("when every hop is trusted", func(t *testing.T) {
+		assert.Equal(t, "10.0.0.3", serve(trusted, "10.0.0.1:1234", "10.0.0.3, 10.0.0.2"))
+	})
+
+	t.Run("from an untrusted peer", func(t *testing.T) {
+		assert.Equal(t, "203.0.113.9:1234", serve(trusted, "203.0.113.9:1234", "1.1.1.1"))
+	})
+
+	t.Run("from a Unix socket peer", func(t *testing.T) {
+		assert.Equal(t, "198.51.100.7", serve(trusted, "@", "198.51.100.7, 10.0.0.2"))
+		assert.Equal(t, "198.51.100.7", serve(trusted, "", "198.51.100.7"))
+	})
+}
@@ -3,76 +3,239 @@ package server
 import (
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/csrf"
 	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/idempotency"
+	"github.com/keratin/authn-server/server/ratelimit"
 )
 
 func PublicRoutes(app *app.App) []*route.HandledRoute {
 	var routes []*route.HandledRoute
-	originSecurity := route.OriginSecurity(app.Config.ApplicationDomains, app.Logger)
+	originSecurity := route.OriginSecurity(app.Config().ApplicationDomains, app.Logger)
 
 	routes = append(routes,
 		route.Get("/health").
+			Describe("Health Check").
 			SecuredWith(route.Unsecured()).
 			Handle(handlers.GetHealth(app)),
 
-		route.Post("/password").
-			SecuredWith(originSecurity).
-			Handle(handlers.PostPassword(app)),
-
-		route.Post("/session").
-			SecuredWith(originSecurity).
-			Handle(handlers.PostSession(app)),
+		route.Get("/health/ready").
+			Describe("Readiness Check").
+			SecuredWith(route.Unsecured()).
+			Handle(handlers.GetHealthReady(app)),
 
 		route.Delete("/session").
+			Describe("Logout").
 			SecuredWith(originSecurity).
-			Handle(handlers.DeleteSession(app)),
+			Handle(csrf.Middleware(app)(handlers.DeleteSession(app))),
 
 		route.Get("/session/refresh").
+			Describe("Refresh Session", route.Optional("nonce", "string")).
 			SecuredWith(originSecurity).
 			Handle(handlers.GetSessionRefresh(app)),
+
+		route.Get("/sessions").
+			Describe("List Sessions").
+			SecuredWith(originSecurity).
+			Handle(handlers.GetSessions(app)),
+
+		route.Delete("/sessions/{id}").
+			Describe("Revoke Session").
+			SecuredWith(originSecurity).
+			Handle(csrf.Middleware(app)(handlers.DeleteSessionByID(app))),
+
+		route.Post("/session/handoff").
+			Describe("Hand Off Session", route.Required("origin", "string")).
+			SecuredWith(originSecurity).
+			Handle(csrf.Middleware(app)(handlers.PostSessionHandoff(app))),
+
+		route.Post("/session/handoff/redeem").
+			Describe("Redeem Session Handoff",
+				route.Required("token", "string"),
+				route.Optional("remember_me", "boolean"),
+				route.Optional("nonce", "string"),
+				route.Optional("otp", "string")).
+			SecuredWith(originSecurity).
+			Handle(handlers.PostSessionHandoffRedeem(app)),
+
+		route.Post("/totp/new").
+			Describe("Enroll Authenticator").
+			SecuredWith(originSecurity).
+			Handle(csrf.Middleware(app)(handlers.PostTOTPNew(app))),
+
+		route.Post("/totp/confirm").
+			Describe("Confirm Authenticator", route.Required("otp", "string")).
+			SecuredWith(originSecurity).
+			Handle(csrf.Middleware(app)(handlers.PostTOTPConfirm(app))),
 	)
 
-	if app.Config.EnableSignup {
+	if app.TokenDenylist != nil {
+		routes = append(routes,
+			route.Get("/access_tokens/revoked").
+				Describe("Check Access Token", route.Required("jti", "string")).
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.GetAccessTokenRevoked(app)),
+		)
+	}
+
+	if app.Config().EnablePasswordLogin {
+		routes = append(routes,
+			route.Post("/session").
+				Describe("Login",
+					route.Required("username", "string"),
+					route.Required("password", "string"),
+					route.Optional("remember_me", "boolean"),
+					route.Optional("nonce", "string"),
+					route.Optional("otp", "string")).
+				SecuredWith(originSecurity).
+				Handle(ratelimit.Middleware(app, "login")(handlers.PostSession(app))),
+		)
+	}
+
+	// passwords are managed by the directory when LDAP is configured
+	if app.Config().EnablePasswordLogin && app.LDAP == nil {
+		routes = append(routes,
+			route.Post("/password").
+				Describe("Change Password",
+					route.Required("password", "string"),
+					route.Optional("token", "string"),
+					route.Optional("currentPassword", "string"),
+					route.Optional("nonce", "string"),
+					route.Optional("otp", "string")).
+				SecuredWith(originSecurity).
+				Handle(ratelimit.ResetMiddleware(app, "password")(csrf.Middleware(app)(idempotency.Middleware(app, "password")(handlers.PostPassword(app))))),
+
+			route.Delete("/account").
+				Describe("Delete Own Account", route.Required("password", "string")).
+				SecuredWith(originSecurity).
+				Handle(csrf.Middleware(app)(handlers.DeleteOwnAccount(app))),
+		)
+	}
+
+	// while signup is disabled, accounts may still be created with an invitation. with LDAP, accounts
+	// are created by the first login instead, since a local password would never be used.
+	if app.LDAP == nil {
 		routes = append(routes,
 			route.Post("/accounts").
+				Describe("Signup",
+					route.Required("username", "string"),
+					route.Required("password", "string"),
+					route.Optional("nonce", "string"),
+					route.Optional("invitation", "string")).
 				SecuredWith(originSecurity).
-				Handle(handlers.PostAccount(app)),
+				Handle(ratelimit.Middleware(app, "signup")(idempotency.Middleware(app, "signup")(handlers.PostAccount(app)))),
+		)
+	}
+
+	if app.Config().EnableSignup && app.LDAP == nil {
+		routes = append(routes,
 			route.Get("/accounts/available").
+				Describe("Username Availability", route.Required("username", "string")).
 				SecuredWith(originSecurity).
 				Handle(handlers.GetAccountsAvailable(app)),
 		)
 	}
 
-	if app.Config.AppPasswordResetURL != nil {
+	if app.Config().AppPasswordResetURL != nil && app.Config().EnablePasswordLogin && app.Config().EnablePasswordReset && app.LDAP == nil {
 		routes = append(routes,
 			route.Get("/password/reset").
+				Describe("Request Password Reset", route.Required("username", "string")).
 				SecuredWith(originSecurity).
-				Handle(handlers.GetPasswordReset(app)),
+				Handle(ratelimit.Middleware(app, "password-reset")(idempotency.Middleware(app, "password-reset")(handlers.GetPasswordReset(app)))),
 		)
 	}
 
-	if app.Config.AppPasswordlessTokenURL != nil {
+	if app.Config().AppPasswordlessTokenURL != nil {
 		routes = append(routes,
 			route.Get("/session/token").
+				Describe("Request Passwordless Login", route.Required("username", "string")).
 				SecuredWith(originSecurity).
 				Handle(handlers.GetSessionToken(app)),
 
 			route.Post("/session/token").
+				Describe("Submit Passwordless Login",
+					route.Required("token", "string"),
+					route.Optional("remember_me", "boolean"),
+					route.Optional("nonce", "string"),
+					route.Optional("otp", "string")).
 				SecuredWith(originSecurity).
 				Handle(handlers.PostSessionToken(app)),
 		)
 	}
 
+	if app.Config().AppEmailVerificationURL != nil {
+		routes = append(routes,
+			route.Get("/email/verification").
+				Describe("Request Email Verification", route.Required("username", "string")).
+				SecuredWith(originSecurity).
+				Handle(handlers.GetEmailVerification(app)),
+
+			route.Post("/email/verification").
+				Describe("Verify Email", route.Required("token", "string")).
+				SecuredWith(originSecurity).
+				Handle(handlers.PostEmailVerification(app)),
+		)
+	}
+
+	// email usernames are changed with a confirmation, as below, and LDAP usernames belong to the
+	// directory
+	if !app.Config().UsernameIsEmail && app.LDAP == nil {
+		routes = append(routes,
+			route.Patch("/account/username").
+				Describe("Change Username", route.Required("username", "string")).
+				SecuredWith(originSecurity).
+				Handle(csrf.Middleware(app)(handlers.PatchAccountUsername(app))),
+		)
+	}
+
+	if app.Config().AppEmailChangeURL != nil {
+		routes = append(routes,
+			route.Patch("/account/email").
+				Describe("Change Email", route.Required("email", "string"), route.Required("password", "string")).
+				SecuredWith(originSecurity).
+				Handle(csrf.Middleware(app)(handlers.PatchAccountEmail(app))),
+
+			route.Post("/account/email/confirm").
+				Describe("Confirm Email Change", route.Required("token", "string")).
+				SecuredWith(originSecurity).
+				Handle(handlers.PostAccountEmailConfirm(app)),
+		)
+	}
+
 	for providerName := range app.OauthProviders {
 		routes = append(routes,
 			route.Get("/oauth/"+providerName).
+				Describe("Begin OAuth", route.Required("redirect_uri", "string")).
 				SecuredWith(route.Unsecured()).
 				Handle(handlers.GetOauth(app, providerName)),
 			route.Get("/oauth/"+providerName+"/return").
+				Describe("OAuth Return", route.Required("code", "string"), route.Required("state", "string")).
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.GetOauthReturn(app, providerName)),
+			// some providers return with a form_post
+			route.Post("/oauth/"+providerName+"/return").
+				Describe("OAuth Return", route.Required("code", "string"), route.Required("state", "string")).
 				SecuredWith(route.Unsecured()).
 				Handle(handlers.GetOauthReturn(app, providerName)),
 		)
 	}
 
+	for providerName := range app.SAMLProviders {
+		routes = append(routes,
+			route.Get("/saml/"+providerName).
+				Describe("Begin SAML", route.Required("redirect_uri", "string")).
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.GetSaml(app, providerName)),
+			route.Get("/saml/"+providerName+"/metadata").
+				Describe("SAML Metadata").
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.GetSamlMetadata(app, providerName)),
+			route.Post("/saml/"+providerName+"/acs").
+				Describe("SAML Assertion Consumer Service", route.Required("SAMLResponse", "string")).
+				SecuredWith(route.Unsecured()).
+				Handle(handlers.PostSamlACS(app, providerName)),
+		)
+	}
+
 	return routes
 }
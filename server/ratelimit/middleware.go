@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/app/tokens/resets"
+	"github.com/keratin/authn-server/lib/parse"
+	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/pkg/errors"
+)
+
+// Headers describe the tightest limit on a response, and are exposed to cross-origin scripts.
+var Headers = []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"}
+
+// maxBody limits how much of a request is read to find the username
+const maxBody = 1 << 20
+
+// Middleware limits requests for an action, like "login", by client IP (RATE_LIMIT_IP) and by
+// requested username (RATE_LIMIT_ACCOUNT). Each action has its own buckets. Requests over a limit
+// are refused with 429 Too Many Requests.
+func Middleware(app *app.App, action string) func(http.Handler) http.Handler {
+	return middleware(app, action, func(r *http.Request, params requestParams) (string, bool) {
+		return normalizeUsername(params.Username), true
+	})
+}
+
+// ResetMiddleware limits requests that redeem a password reset token for an action, by client IP
+// and by the token's account. Requests without a token, like a password change with a session,
+// are not limited.
+func ResetMiddleware(app *app.App, action string) func(http.Handler) http.Handler {
+	return middleware(app, action, func(r *http.Request, params requestParams) (string, bool) {
+		if params.Token == "" {
+			return "", false
+		}
+		claims, err := resets.Parse(params.Token, app.ConfigFor(r.Context()))
+		if err != nil {
+			return "", true
+		}
+		return "id:" + claims.Subject, true
+	})
+}
+
+// middleware limits the requests that the account func says to, by client IP and by the account
+// that it returns (when not empty)
+func middleware(app *app.App, action string, account func(*http.Request, requestParams) (string, bool)) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		if app.RateLimiter == nil || (app.Config().RateLimitIP <= 0 && app.Config().RateLimitAccount <= 0) {
+			return h
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
+			accountKey, limited := account(r, readParams(r))
+			if !limited {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			var tightest *models.RateLimit
+			var limit int
+			take := func(key string, max int) bool {
+				rl, err := app.RateLimiter.Take(action+":"+key, max, cfg.RateLimitWindow)
+				if err != nil {
+					panic(errors.Wrap(err, "Take"))
+				}
+				if tightest == nil || !rl.Allowed || rl.Remaining < tightest.Remaining {
+					tightest, limit = rl, max
+				}
+				return rl.Allowed
+			}
+
+			allowed := true
+			if cfg.RateLimitIP > 0 {
+				allowed = take("ip:"+route.ClientIP(r), cfg.RateLimitIP)
+			}
+			if allowed && cfg.RateLimitAccount > 0 && accountKey != "" {
+				allowed = take("account:"+accountKey, cfg.RateLimitAccount)
+			}
+
+			if tightest != nil {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+				w.Header().Set("X-RateLimit-Reset", seconds(tightest.ResetAfter))
+			}
+			if !allowed {
+				w.Header().Set("Retry-After", seconds(tightest.RetryAfter))
+				handlers.WriteFieldErrors(w, r, http.StatusTooManyRequests,
+					services.FieldErrors{{"request", services.ErrThrottled}})
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+type requestParams struct {
+	Username string
+	Token    string
+}
+
+// readParams reads the params that identify an account from a copy of the request, leaving the
+// body for the handler
+func readParams(r *http.Request) requestParams {
+	var params requestParams
+	copied := r.Clone(r.Context())
+	if r.Body != nil {
+		body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxBody))
+		if err != nil {
+			return params
+		}
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		copied.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if err := parse.Payload(copied, &params); err != nil {
+		return requestParams{}
+	}
+	return params
+}
+
+// normalizeUsername folds usernames that an account store may find as the same account into one
+// bucket. Services trim usernames, and MySQL compares them without case.
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// seconds rounds a duration up to whole seconds, as Retry-After expects
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,147 @@
+package ratelimit_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/tokens/resets"
+	"github.com/keratin/authn-server/server/ratelimit"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})
+	send := func(app *app.App, action string, ip string, contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/session", strings.NewReader(body))
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("Content-Type", contentType)
+		res := httptest.NewRecorder()
+		ratelimit.Middleware(app, action)(echo).ServeHTTP(res, req)
+		return res
+	}
+	form := "application/x-www-form-urlencoded"
+
+	t.Run("without limits", func(t *testing.T) {
+		app := test.App()
+		res := send(app, "login", "127.0.0.1", form, "username=alice")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Empty(t, res.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("by IP", func(t *testing.T) {
+		app := test.App()
+		app.Config().RateLimitIP = 2
+		app.Config().RateLimitWindow = time.Minute
+
+		res := send(app, "login", "127.0.0.1", form, "username=alice")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "2", res.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "1", res.Header().Get("X-RateLimit-Remaining"))
+		assert.Equal(t, "30", res.Header().Get("X-RateLimit-Reset"))
+
+		res = send(app, "login", "127.0.0.1", form, "username=bob")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "0", res.Header().Get("X-RateLimit-Remaining"))
+
+		res = send(app, "login", "127.0.0.1", form, "username=carol")
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "30", res.Header().Get("Retry-After"))
+		assert.Equal(t, `{"errors":[{"field":"request","message":"THROTTLED"}]}`, res.Body.String())
+
+		res = send(app, "login", "10.0.0.1", form, "username=carol")
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "signup", "127.0.0.1", form, "username=carol")
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("by account", func(t *testing.T) {
+		app := test.App()
+		app.Config().RateLimitIP = 10
+		app.Config().RateLimitAccount = 1
+		app.Config().RateLimitWindow = time.Minute
+
+		res := send(app, "login", "127.0.0.1", "application/json", `{"username": "alice"}`)
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `{"username": "alice"}`, res.Body.String())
+		assert.Equal(t, "1", res.Header().Get("X-RateLimit-Limit"))
+		assert.Equal(t, "0", res.Header().Get("X-RateLimit-Remaining"))
+
+		res = send(app, "login", "10.0.0.1", form, "username=alice")
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+		assert.Equal(t, "60", res.Header().Get("Retry-After"))
+
+		res = send(app, "login", "10.0.0.1", form, "username=bob")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "username=bob", res.Body.String())
+	})
+
+	t.Run("by normalized account", func(t *testing.T) {
+		app := test.App()
+		app.Config().RateLimitAccount = 1
+		app.Config().RateLimitWindow = time.Minute
+
+		res := send(app, "login", "127.0.0.1", form, "username=alice")
+		require.Equal(t, http.StatusOK, res.Code)
+
+		res = send(app, "login", "10.0.0.1", form, "username=+ALICE+")
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	})
+}
+
+func TestResetMiddleware(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Write(body)
+	})
+	send := func(app *app.App, ip string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/password", strings.NewReader(body))
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res := httptest.NewRecorder()
+		ratelimit.ResetMiddleware(app, "password")(echo).ServeHTTP(res, req)
+		return res
+	}
+
+	app := test.App()
+	app.Config().RateLimitIP = 10
+	app.Config().RateLimitAccount = 1
+	app.Config().RateLimitWindow = time.Minute
+	claims, err := resets.New(app.Config(), 123, time.Now())
+	require.NoError(t, err)
+	token, err := claims.Sign(app.Config().ResetSigningKey)
+	require.NoError(t, err)
+
+	t.Run("without a token", func(t *testing.T) {
+		res := send(app, "127.0.0.1", "currentPassword=old&password=new")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Empty(t, res.Header().Get("X-RateLimit-Limit"))
+	})
+
+	t.Run("with a token", func(t *testing.T) {
+		res := send(app, "127.0.0.1", "token="+token+"&password=new")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "token="+token+"&password=new", res.Body.String())
+		assert.Equal(t, "0", res.Header().Get("X-RateLimit-Remaining"))
+
+		// the token's account is limited from any IP
+		res = send(app, "10.0.0.1", "token="+token+"&password=other")
+		assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	})
+
+	t.Run("with an invalid token", func(t *testing.T) {
+		res := send(app, "10.0.0.2", "token=invalid&password=new")
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "10", res.Header().Get("X-RateLimit-Limit"))
+	})
+}
@@ -2,40 +2,111 @@ package server
 
 import (
 	"net/http"
-	"os"
+	"net/url"
+	"strings"
 
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/ops"
 	"github.com/keratin/authn-server/server/cors"
+	"github.com/keratin/authn-server/server/handlers"
+	"github.com/keratin/authn-server/server/logging"
+	"github.com/keratin/authn-server/server/maintenance"
+	"github.com/keratin/authn-server/server/proxy"
+	"github.com/keratin/authn-server/server/security"
 	"github.com/keratin/authn-server/server/sessions"
 )
 
 func Router(app *app.App) http.Handler {
-	r := mux.NewRouter()
-	route.Attach(r, app.Config.MountedPath, PrivateRoutes(app)...)
-	route.Attach(r, app.Config.MountedPath, PublicRoutes(app)...)
-
-	return wrapRouter(r, app)
+	return wrapRouter(app, privateMux)
 }
 
 func PublicRouter(app *app.App) http.Handler {
+	return wrapRouter(app, publicMux)
+}
+
+// privateMux attaches the routes at the MountedPath of the AuthNURL that cfg describes
+func privateMux(app *app.App, cfg *app.Config) *mux.Router {
 	r := mux.NewRouter()
-	route.Attach(r, app.Config.MountedPath, PublicRoutes(app)...)
+	private := PrivateRoutes(app)
+	public := PublicRoutes(app)
+	route.Attach(r, cfg.MountedPath, private...)
+	route.Attach(r, cfg.MountedPath, public...)
+	route.Attach(r, cfg.MountedPath, openAPIRoute(app, cfg, private, public))
+	return r
+}
 
-	return wrapRouter(r, app)
+func publicMux(app *app.App, cfg *app.Config) *mux.Router {
+	r := mux.NewRouter()
+	public := PublicRoutes(app)
+	route.Attach(r, cfg.MountedPath, public...)
+	route.Attach(r, cfg.MountedPath, openAPIRoute(app, cfg, nil, public))
+	return r
 }
 
-func wrapRouter(r *mux.Router, app *app.App) http.Handler {
-	stack := handlers.CombinedLoggingHandler(os.Stdout, r)
-	stack = sessions.Middleware(app)(stack)
-	stack = cors.Middleware(app)(stack)
+// openAPIRoute serves an OpenAPI document for the routes that are attached alongside it
+func openAPIRoute(app *app.App, cfg *app.Config, private []*route.HandledRoute, public []*route.HandledRoute) *route.HandledRoute {
+	version := app.Version
+	if version == "" {
+		version = "dev"
+	}
+	var baseURL string
+	if cfg.AuthNURL != nil {
+		baseURL = cfg.AuthNURL.String()
+	}
+	doc := route.OpenAPI("Keratin AuthN", version, baseURL, private, public)
+
+	return route.Get("/openapi.json").
+		Describe("OpenAPI Document").
+		SecuredWith(route.Unsecured()).
+		Handle(handlers.GetOpenAPI(doc))
+}
 
-	if app.Config.Proxied {
-		stack = handlers.ProxyHeaders(stack)
+func wrapRouter(app *app.App, routes func(*app.App, *app.Config) *mux.Router) http.Handler {
+	stack := byHost(app, routes)
+
+	if app.Config().Proxied {
+		stack = proxy.Middleware(app)(stack)
+	}
+
+	return ops.RequestIDHandler(ops.PanicHandler(app.Reporter, stack))
+}
+
+// byHost builds the routes for each of the AuthNURLs, and serves requests with the one that
+// matches the Host. Requests for other hosts are served as the first AuthNURL.
+//
+// Every host shares the App. Requests for the other AuthNURLs are marked with WithAuthNURL, so
+// that handlers find its settings with ConfigFor, and still follow reloads.
+func byHost(app *app.App, routes func(*app.App, *app.Config) *mux.Router) http.Handler {
+	primary := withMiddleware(app, routes(app, app.Config()))
+	if len(app.Config().AuthNURLs) < 2 {
+		return primary
+	}
+
+	hosts := map[string]http.Handler{}
+	for _, u := range app.Config().AuthNURLs[1:] {
+		hosts[strings.ToLower(u.Host)] = forAuthNURL(u, withMiddleware(app, routes(app, app.Config().ForAuthNURL(u))))
 	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h, ok := hosts[strings.ToLower(r.Host)]; ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+		primary.ServeHTTP(w, r)
+	})
+}
+
+func forAuthNURL(u *url.URL, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(app.WithAuthNURL(r.Context(), u)))
+	})
+}
 
-	return ops.PanicHandler(app.Reporter, stack)
+// withMiddleware wraps the router with the middleware that depends on the AuthNURL
+func withMiddleware(app *app.App, r *mux.Router) http.Handler {
+	stack := logging.Middleware(app, r)(maintenance.Middleware(app)(r))
+	stack = sessions.Middleware(app)(stack)
+	stack = cors.Middleware(app)(stack)
+	return security.Middleware(app)(stack)
 }
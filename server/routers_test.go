@@ -1,10 +1,22 @@
 package server_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/lib/ldap"
 	"github.com/keratin/authn-server/lib/route"
 	"github.com/keratin/authn-server/server"
 	"github.com/keratin/authn-server/server/test"
@@ -14,7 +26,7 @@ import (
 
 func TestCORS(t *testing.T) {
 	app := test.App()
-	domain := app.Config.ApplicationDomains[0]
+	domain := app.Config().ApplicationDomains[0]
 	server := httptest.NewServer(server.Router(app))
 	defer server.Close()
 
@@ -34,3 +46,371 @@ func TestCORS(t *testing.T) {
 	assert.Equal(t, "PATCH", res.Header.Get("Access-Control-Allow-Methods"))
 	assert.Equal(t, origin, res.Header.Get("Access-Control-Allow-Origin"))
 }
+
+func TestRequestID(t *testing.T) {
+	app := test.App()
+	server := httptest.NewServer(server.Router(app))
+	defer server.Close()
+
+	t.Run("generated", func(t *testing.T) {
+		res, err := route.NewClient(server.URL).Get("/health")
+		require.NoError(t, err)
+		assert.Len(t, res.Header.Get("X-Request-ID"), 32)
+	})
+
+	t.Run("from the client", func(t *testing.T) {
+		client := route.NewClient(server.URL).With(func(r *http.Request) *http.Request {
+			r.Header.Set("X-Request-ID", "client-1234")
+			return r
+		})
+		res, err := client.Get("/unknown")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "client-1234", res.Header.Get("X-Request-ID"))
+	})
+
+	t.Run("invalid from the client", func(t *testing.T) {
+		client := route.NewClient(server.URL).With(func(r *http.Request) *http.Request {
+			r.Header.Set("X-Request-ID", "not valid\tid")
+			return r
+		})
+		res, err := client.Get("/health")
+		require.NoError(t, err)
+		assert.Len(t, res.Header.Get("X-Request-ID"), 32)
+	})
+}
+
+func TestAuthNURLs(t *testing.T) {
+	app := test.App()
+	app.Config().ForceSSL = true
+	internal, err := url.Parse("http://authn.internal/auth")
+	require.NoError(t, err)
+	app.Config().AuthNURLs = append(app.Config().AuthNURLs, internal)
+	router := server.Router(app)
+
+	configuration := func(target string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("GET", target, nil))
+		var body map[string]interface{}
+		json.Unmarshal(res.Body.Bytes(), &body)
+		return res, body
+	}
+
+	t.Run("primary", func(t *testing.T) {
+		res, body := configuration("https://authn.example.com/configuration")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "https://authn.example.com", body["issuer"])
+		assert.NotEmpty(t, res.Header().Get("Strict-Transport-Security"))
+	})
+
+	t.Run("alternate", func(t *testing.T) {
+		res, body := configuration("http://AUTHN.internal/auth/configuration")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "http://authn.internal/auth", body["issuer"])
+		assert.Empty(t, res.Header().Get("Strict-Transport-Security"))
+
+		res, _ = configuration("http://authn.internal/configuration")
+		assert.Equal(t, http.StatusNotFound, res.Code)
+	})
+
+	t.Run("unknown host", func(t *testing.T) {
+		res, body := configuration("http://10.0.0.1/configuration")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "https://authn.example.com", body["issuer"])
+	})
+
+	t.Run("alternate after a reload", func(t *testing.T) {
+		logout, err := url.Parse("https://app.example.com/logout")
+		require.NoError(t, err)
+		next := *app.Config()
+		next.AppBackchannelLogoutURLs = []*url.URL{logout}
+		app.SetConfig(&next)
+
+		res, body := configuration("http://authn.internal/auth/configuration")
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "http://authn.internal/auth", body["issuer"])
+		assert.Equal(t, true, body["backchannel_logout_supported"])
+	})
+}
+
+func TestPasswordFeatureFlags(t *testing.T) {
+	status := func(app *app.App, method string, path string) int {
+		res := httptest.NewRecorder()
+		server.PublicRouter(app).ServeHTTP(res, httptest.NewRequest(method, path, nil))
+		return res.Code
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		app := test.App()
+		assert.NotEqual(t, http.StatusNotFound, status(app, "POST", "/session"))
+		assert.NotEqual(t, http.StatusNotFound, status(app, "POST", "/password"))
+		assert.NotEqual(t, http.StatusNotFound, status(app, "GET", "/password/reset"))
+	})
+
+	t.Run("without password resets", func(t *testing.T) {
+		app := test.App()
+		app.Config().EnablePasswordReset = false
+		assert.NotEqual(t, http.StatusNotFound, status(app, "POST", "/session"))
+		assert.NotEqual(t, http.StatusNotFound, status(app, "POST", "/password"))
+		assert.Equal(t, http.StatusNotFound, status(app, "GET", "/password/reset"))
+	})
+
+	t.Run("without password logins", func(t *testing.T) {
+		app := test.App()
+		app.Config().EnablePasswordLogin = false
+		assert.Equal(t, http.StatusMethodNotAllowed, status(app, "POST", "/session"))
+		assert.Equal(t, http.StatusNotFound, status(app, "POST", "/password"))
+		assert.Equal(t, http.StatusNotFound, status(app, "GET", "/password/reset"))
+		assert.NotEqual(t, http.StatusNotFound, status(app, "DELETE", "/session"))
+	})
+
+	t.Run("with LDAP", func(t *testing.T) {
+		app := test.App()
+		app.LDAP = ldap.TestAuthenticator{}
+		assert.NotEqual(t, http.StatusNotFound, status(app, "POST", "/session"))
+		assert.Equal(t, http.StatusNotFound, status(app, "POST", "/password"))
+		assert.Equal(t, http.StatusNotFound, status(app, "GET", "/password/reset"))
+		assert.Equal(t, http.StatusNotFound, status(app, "POST", "/accounts"))
+		assert.Equal(t, http.StatusNotFound, status(app, "GET", "/accounts/available"))
+	})
+}
+
+func TestUsernameChangeRoute(t *testing.T) {
+	status := func(app *app.App) int {
+		res := httptest.NewRecorder()
+		server.PublicRouter(app).ServeHTTP(res, httptest.NewRequest("PATCH", "/account/username", nil))
+		return res.Code
+	}
+
+	app := test.App()
+	assert.NotEqual(t, http.StatusNotFound, status(app))
+
+	app = test.App()
+	app.Config().UsernameIsEmail = true
+	assert.Equal(t, http.StatusNotFound, status(app))
+
+	app = test.App()
+	app.LDAP = ldap.TestAuthenticator{}
+	assert.Equal(t, http.StatusNotFound, status(app))
+}
+
+func TestOpenAPI(t *testing.T) {
+	type operation struct {
+		OperationID string                `json:"operationId"`
+		Security    []map[string][]string `json:"security"`
+		Parameters  []struct {
+			Name     string `json:"name"`
+			In       string `json:"in"`
+			Required bool   `json:"required"`
+		} `json:"parameters"`
+		RequestBody *struct {
+			Content map[string]struct {
+				Schema struct {
+					Required []string `json:"required"`
+				} `json:"schema"`
+			} `json:"content"`
+		} `json:"requestBody"`
+	}
+	type document struct {
+		OpenAPI string                          `json:"openapi"`
+		Paths   map[string]map[string]operation `json:"paths"`
+	}
+	fetch := func(t *testing.T, router http.Handler) document {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest("GET", "/openapi.json", nil))
+		require.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, []string{"application/json"}, res.Header()["Content-Type"])
+
+		var doc document
+		require.NoError(t, json.Unmarshal(res.Body.Bytes(), &doc))
+		assert.Equal(t, "3.0.3", doc.OpenAPI)
+		return doc
+	}
+
+	t.Run("private router", func(t *testing.T) {
+		doc := fetch(t, server.Router(test.App()))
+
+		lock := doc.Paths["/accounts/{id}/lock"]["patch"]
+		assert.Equal(t, "patchAccountsIdLock", lock.OperationID)
+		assert.Equal(t, []map[string][]string{{"basicAuth": {}}}, lock.Security)
+		require.Len(t, lock.Parameters, 1)
+		assert.Equal(t, "id", lock.Parameters[0].Name)
+		assert.Equal(t, "path", lock.Parameters[0].In)
+		assert.True(t, lock.Parameters[0].Required)
+
+		assert.Empty(t, doc.Paths["/jwks"]["get"].Security)
+
+		signup := doc.Paths["/accounts"]["post"]
+		assert.Empty(t, signup.Security)
+		require.NotNil(t, signup.RequestBody)
+		assert.Equal(t, []string{"username", "password"}, signup.RequestBody.Content["application/json"].Schema.Required)
+	})
+
+	t.Run("public router", func(t *testing.T) {
+		doc := fetch(t, server.PublicRouter(test.App()))
+
+		assert.Contains(t, doc.Paths, "/session")
+		assert.NotContains(t, doc.Paths, "/accounts/{id}/lock")
+	})
+}
+
+// TestRouteDescriptions checks that the parameters described for the OpenAPI document match the
+// parameters that each route's handler reads, from a payload struct or with FormValue and friends.
+func TestRouteDescriptions(t *testing.T) {
+	fset := token.NewFileSet()
+	funcs := map[string]*ast.FuncDecl{}
+	structs := map[string]*ast.StructType{}
+	files, err := filepath.Glob("handlers/*.go")
+	require.NoError(t, err)
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					funcs[decl.Name.Name] = decl
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok {
+						if st, ok := ts.Type.(*ast.StructType); ok {
+							structs[ts.Name.Name] = st
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// reads collects the parameter names read by a handler and the helpers that it calls
+	reads := func(handler string) map[string]bool {
+		names := map[string]bool{}
+		visited := map[string]bool{}
+		var visit func(fn string)
+		visit = func(fn string) {
+			decl := funcs[fn]
+			if decl == nil || visited[fn] {
+				return
+			}
+			visited[fn] = true
+			ast.Inspect(decl, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.CallExpr:
+					if id, ok := n.Fun.(*ast.Ident); ok {
+						visit(id.Name)
+					}
+					if sel, ok := n.Fun.(*ast.SelectorExpr); ok && len(n.Args) == 1 {
+						switch sel.Sel.Name {
+						case "FormValue", "PostFormValue", "Get":
+							if lit, ok := n.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+								name, _ := strconv.Unquote(lit.Value)
+								names[strings.ToLower(name)] = true
+							}
+						}
+					}
+				case *ast.ValueSpec:
+					var st *ast.StructType
+					switch typ := n.Type.(type) {
+					case *ast.StructType:
+						st = typ
+					case *ast.Ident:
+						st = structs[typ.Name]
+					}
+					if st == nil {
+						return true
+					}
+					for _, field := range st.Fields.List {
+						for _, id := range field.Names {
+							name := id.Name
+							if field.Tag != nil {
+								tag, _ := strconv.Unquote(field.Tag.Value)
+								if json := reflect.StructTag(tag).Get("json"); json != "" {
+									name = strings.Split(json, ",")[0]
+								}
+							}
+							names[strings.ToLower(name)] = true
+						}
+					}
+				}
+				return true
+			})
+		}
+		visit(handler)
+		return names
+	}
+
+	for _, name := range []string{"public_routes.go", "private_routes.go", "routers.go"} {
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		require.NoError(t, err)
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "Handle" {
+				return true
+			}
+
+			// walk back along route.Verb(tpl).Describe(...).SecuredWith(...)
+			var verb, tpl string
+			described := map[string]bool{}
+			for expr := sel.X; expr != nil; {
+				c, ok := expr.(*ast.CallExpr)
+				if !ok {
+					break
+				}
+				s := c.Fun.(*ast.SelectorExpr)
+				if s.Sel.Name == "Describe" {
+					for _, arg := range c.Args[1:] {
+						param := arg.(*ast.CallExpr).Args[0].(*ast.BasicLit)
+						name, _ := strconv.Unquote(param.Value)
+						described[strings.ToLower(name)] = true
+					}
+				}
+				if id, ok := s.X.(*ast.Ident); ok && id.Name == "route" {
+					verb = strings.ToUpper(s.Sel.Name)
+					if lit, ok := c.Args[0].(*ast.BasicLit); ok {
+						tpl, _ = strconv.Unquote(lit.Value)
+					}
+					break
+				}
+				expr = s.X
+			}
+
+			var handler string
+			ast.Inspect(call.Args[0], func(n ast.Node) bool {
+				if s, ok := n.(*ast.SelectorExpr); ok {
+					if id, ok := s.X.(*ast.Ident); ok && id.Name == "handlers" {
+						handler = s.Sel.Name
+					}
+				}
+				return true
+			})
+			if handler == "" {
+				return true
+			}
+
+			// parameters in the path template are documented automatically
+			inPath := func(param string) bool {
+				return strings.Contains(strings.ToLower(tpl), "{"+param)
+			}
+			read := reads(handler)
+			for param := range described {
+				if !read[param] && !inPath(param) {
+					t.Errorf("%s %s describes %q, which %s does not read", verb, tpl, param, handler)
+				}
+			}
+			for param := range read {
+				if !described[param] && !inPath(param) {
+					t.Errorf("%s %s does not describe %q, which %s reads", verb, tpl, param, handler)
+				}
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,223 @@
+// Package rpc serves private AuthN operations over gRPC, as described by lib/authnpb.
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/lib/authnpb"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// NewServer returns a gRPC server for the AuthN service. Production servers should be given
+// Credentials.
+func NewServer(app *app.App, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append(opts, grpc.UnaryInterceptor(interceptor(app)))...)
+	authnpb.RegisterAuthNServer(s, &server{app: app})
+	return s
+}
+
+// Credentials require TLS with a client certificate signed by GRPC_CLIENT_CA.
+func Credentials(cfg *app.Config) credentials.TransportCredentials {
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{*cfg.GRPCCertificate},
+		ClientCAs:    cfg.GRPCClientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+}
+
+// interceptor logs each call and recovers from panics, reporting them as internal errors.
+func interceptor(app *app.App) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res interface{}, err error) {
+		start := time.Now()
+		defer func() {
+			if p := recover(); p != nil {
+				e, ok := p.(error)
+				if !ok {
+					e = fmt.Errorf("%v", p)
+				}
+				res, err = nil, internal(app, e)
+			}
+
+			code := status.Code(err)
+			entry := app.Logger.WithFields(logrus.Fields{
+				"method":    info.FullMethod,
+				"code":      code.String(),
+				"latencyMS": time.Since(start).Milliseconds(),
+			})
+			if client := clientName(ctx); client != "" {
+				entry = entry.WithField("client", client)
+			}
+			if code == codes.Internal {
+				entry.Error("rpc")
+			} else {
+				entry.Info("rpc")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// clientName is the subject of the client's certificate, if any
+func clientName(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.PeerCertificates) == 0 {
+		return ""
+	}
+	return info.State.PeerCertificates[0].Subject.String()
+}
+
+// audit records an action by the client in the audit log
+func (s *server) audit(ctx context.Context, action string, accountID int) {
+	entry := &models.AuditEntry{Action: action, AccountID: accountID, Actor: models.ActorRPC}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(entry.IP); err == nil {
+			entry.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		entry.UserAgent = strings.Join(md.Get("user-agent"), " ")
+	}
+	services.AuditRecorder(s.app.AuditLog, s.app.Reporter, entry)
+}
+
+// internal reports an unexpected error and hides its details from the client
+func internal(app *app.App, err error) error {
+	app.Reporter.ReportError(err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+// notFound describes FieldErrors from a service, which all mean that the resource is missing
+func notFound(app *app.App, err error, resource string) error {
+	if _, ok := err.(services.FieldErrors); ok {
+		return status.Errorf(codes.NotFound, "%s not found", resource)
+	}
+	return internal(app, err)
+}
+
+type server struct {
+	authnpb.UnimplementedAuthNServer
+	app *app.App
+}
+
+func (s *server) GetAccount(ctx context.Context, req *authnpb.GetAccountRequest) (*authnpb.Account, error) {
+	account, err := services.AccountGetter(s.app.AccountStoreFor(ctx), int(req.Id))
+	if err != nil {
+		return nil, notFound(s.app, err, "account")
+	}
+
+	found, err := s.app.AccountStoreFor(ctx).GetAliases(account.ID)
+	if err != nil {
+		return nil, internal(s.app, errors.Wrap(err, "GetAliases"))
+	}
+	aliases := []string{}
+	for _, alias := range found {
+		aliases = append(aliases, alias.Username)
+	}
+
+	metadata, err := structpb.NewStruct(account.Metadata)
+	if err != nil {
+		return nil, internal(s.app, errors.Wrap(err, "NewStruct"))
+	}
+
+	return &authnpb.Account{
+		Id:          int64(account.ID),
+		Username:    account.Username,
+		Aliases:     aliases,
+		Locked:      account.Locked,
+		Deleted:     account.DeletedAt != nil,
+		Roles:       account.Roles,
+		Metadata:    metadata,
+		Verified:    account.Verified(),
+		Totp:        account.TOTPEnabled(),
+		OtpDelivery: account.OTPDeliveryEnabled(),
+	}, nil
+}
+
+func (s *server) LockAccount(ctx context.Context, req *authnpb.LockAccountRequest) (*authnpb.LockAccountResponse, error) {
+	cfg := s.app.Config()
+	err := services.AccountLocker(ctx, s.app.AccountStoreFor(ctx), s.app.RefreshTokenStoreFor(ctx), s.app.KeyStore, cfg, s.app.Reporter, int(req.Id))
+	if err != nil {
+		return nil, notFound(s.app, err, "account")
+	}
+	services.EventPublisher(s.app.EventQueue, cfg, s.app.Reporter, services.EventAccountLocked, int(req.Id))
+	s.audit(ctx, "account.locked", int(req.Id))
+	return &authnpb.LockAccountResponse{}, nil
+}
+
+func (s *server) UnlockAccount(ctx context.Context, req *authnpb.UnlockAccountRequest) (*authnpb.UnlockAccountResponse, error) {
+	err := services.AccountUnlocker(s.app.AccountStoreFor(ctx), s.app.FailedLogins, int(req.Id))
+	if err != nil {
+		return nil, notFound(s.app, err, "account")
+	}
+	services.EventPublisher(s.app.EventQueue, s.app.Config(), s.app.Reporter, services.EventAccountUnlocked, int(req.Id))
+	s.audit(ctx, "account.unlocked", int(req.Id))
+	return &authnpb.UnlockAccountResponse{}, nil
+}
+
+func (s *server) ArchiveAccount(ctx context.Context, req *authnpb.ArchiveAccountRequest) (*authnpb.ArchiveAccountResponse, error) {
+	cfg := s.app.Config()
+	err := services.AccountArchiver(ctx, s.app.AccountStoreFor(ctx), s.app.RefreshTokenStoreFor(ctx), s.app.KeyStore, cfg, s.app.Reporter, int(req.Id))
+	if err != nil {
+		return nil, notFound(s.app, err, "account")
+	}
+	services.EventPublisher(s.app.EventQueue, cfg, s.app.Reporter, services.EventAccountArchived, int(req.Id))
+	s.audit(ctx, "account.archived", int(req.Id))
+	return &authnpb.ArchiveAccountResponse{}, nil
+}
+
+func (s *server) RevokeSession(ctx context.Context, req *authnpb.RevokeSessionRequest) (*authnpb.RevokeSessionResponse, error) {
+	err := services.SessionRevoker(ctx, s.app.RefreshTokenStoreFor(ctx), s.app.KeyStore, s.app.Config(), s.app.Reporter, int(req.AccountId), req.SessionId)
+	if err != nil {
+		return nil, notFound(s.app, err, "session")
+	}
+	s.audit(ctx, services.AuditSessionRevoked, int(req.AccountId))
+	return &authnpb.RevokeSessionResponse{}, nil
+}
+
+func (s *server) IntrospectToken(ctx context.Context, req *authnpb.IntrospectTokenRequest) (*authnpb.Introspection, error) {
+	introspection, err := services.TokenIntrospector(
+		s.app.RefreshTokenStoreFor(ctx), s.app.KeyStore, s.app.TokenDenylist, s.app.AccessTokenStore, s.app.Config(),
+		req.Token, req.TokenTypeHint,
+	)
+	if err != nil {
+		return nil, internal(s.app, errors.Wrap(err, "TokenIntrospector"))
+	}
+
+	res := &authnpb.Introspection{
+		Active:    introspection.Active,
+		TokenType: introspection.TokenType,
+		Sub:       introspection.Subject,
+		Aud:       introspection.Audience,
+		Iss:       introspection.Issuer,
+	}
+	if introspection.IssuedAt != nil {
+		res.Iat = int64(*introspection.IssuedAt)
+	}
+	if introspection.Expiry != nil {
+		res.Exp = int64(*introspection.Expiry)
+	}
+	return res, nil
+}
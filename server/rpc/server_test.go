@@ -0,0 +1,186 @@
+package rpc_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app/models"
+	"github.com/keratin/authn-server/lib/authnpb"
+	"github.com/keratin/authn-server/server/rpc"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newAuthority(t *testing.T) *authority {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{cert: cert, key: key}
+}
+
+func (a *authority) pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(a.cert)
+	return pool
+}
+
+func (a *authority) issue(t *testing.T, name string, usage x509.ExtKeyUsage) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestServer(t *testing.T) {
+	ca := newAuthority(t)
+	serverCert := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+
+	app := test.App()
+	app.Config().GRPCCertificate = &serverCert
+	app.Config().GRPCClientCAs = ca.pool()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := rpc.NewServer(app, grpc.Creds(rpc.Credentials(app.Config())))
+	go s.Serve(l)
+	defer s.Stop()
+
+	dial := func(t *testing.T, certs ...tls.Certificate) authnpb.AuthNClient {
+		creds := credentials.NewTLS(&tls.Config{
+			RootCAs:      ca.pool(),
+			Certificates: certs,
+			ServerName:   "localhost",
+		})
+		conn, err := grpc.Dial(l.Addr().String(), grpc.WithTransportCredentials(creds))
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		return authnpb.NewAuthNClient(conn)
+	}
+	ctx := context.Background()
+
+	t.Run("without a client certificate", func(t *testing.T) {
+		client := dial(t)
+		_, err := client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: 1})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	t.Run("with an untrusted client certificate", func(t *testing.T) {
+		client := dial(t, newAuthority(t).issue(t, "stranger", x509.ExtKeyUsageClientAuth))
+		_, err := client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: 1})
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+
+	client := dial(t, ca.issue(t, "backend", x509.ExtKeyUsageClientAuth))
+
+	t.Run("unknown account", func(t *testing.T) {
+		_, err := client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: 999999})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.LockAccount(ctx, &authnpb.LockAccountRequest{Id: 999999})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.ArchiveAccount(ctx, &authnpb.ArchiveAccountRequest{Id: 999999})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("get, lock, unlock, and archive", func(t *testing.T) {
+		account, err := app.AccountStore.Create("grpc@keratin.tech", []byte("password"))
+		require.NoError(t, err)
+		_, err = app.AccountStore.SetMetadata(account.ID, map[string]interface{}{"plan": "pro"})
+		require.NoError(t, err)
+
+		found, err := client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(account.ID), found.Id)
+		assert.Equal(t, "grpc@keratin.tech", found.Username)
+		assert.False(t, found.Locked)
+		assert.Equal(t, "pro", found.Metadata.AsMap()["plan"])
+
+		_, err = client.LockAccount(ctx, &authnpb.LockAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		found, err = client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		assert.True(t, found.Locked)
+
+		_, err = client.UnlockAccount(ctx, &authnpb.UnlockAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		found, err = client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		assert.False(t, found.Locked)
+
+		_, err = client.ArchiveAccount(ctx, &authnpb.ArchiveAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		found, err = client.GetAccount(ctx, &authnpb.GetAccountRequest{Id: int64(account.ID)})
+		require.NoError(t, err)
+		assert.True(t, found.Deleted)
+
+		entries, err := app.AuditLog.List(models.AuditFilter{AccountID: account.ID}, 10, 0)
+		require.NoError(t, err)
+		if assert.Len(t, entries, 3) {
+			assert.Equal(t, "account.archived", entries[0].Action)
+			assert.Equal(t, models.ActorRPC, entries[0].Actor)
+			assert.Equal(t, "127.0.0.1", entries[0].IP)
+			assert.Contains(t, entries[0].UserAgent, "grpc-go")
+		}
+	})
+
+	t.Run("revoke and introspect a session", func(t *testing.T) {
+		token, err := app.RefreshTokenStore.Create(8642)
+		require.NoError(t, err)
+
+		_, err = client.RevokeSession(ctx, &authnpb.RevokeSessionRequest{AccountId: 9753, SessionId: token.ID()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		_, err = client.RevokeSession(ctx, &authnpb.RevokeSessionRequest{AccountId: 8642, SessionId: token.ID()})
+		require.NoError(t, err)
+
+		id, err := app.RefreshTokenStore.Find(token)
+		require.NoError(t, err)
+		assert.Empty(t, id)
+
+		introspection, err := client.IntrospectToken(ctx, &authnpb.IntrospectTokenRequest{Token: "unknown"})
+		require.NoError(t, err)
+		assert.False(t, introspection.Active)
+	})
+}
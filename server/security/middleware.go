@@ -0,0 +1,49 @@
+package security
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/keratin/authn-server/app"
+)
+
+// hstsMaxAge asks browsers to remember for a year that AuthN requires https
+const hstsMaxAge = "max-age=31536000"
+
+// Middleware sets security headers on every response. HSTS is only sent when AUTHN_URL uses
+// https, and FRAME_ANCESTORS decides which sites may embed a response. Handlers may replace the
+// Content-Security-Policy with a fuller one.
+func Middleware(app *app.App) func(http.Handler) http.Handler {
+	ancestors := "'none'"
+	if len(app.Config().FrameAncestors) > 0 {
+		ancestors = strings.Join(app.Config().FrameAncestors, " ")
+	}
+
+	// X-Frame-Options is understood by browsers that predate frame-ancestors, but can only
+	// describe the simple policies
+	var frameOptions string
+	switch ancestors {
+	case "'none'":
+		frameOptions = "DENY"
+	case "'self'":
+		frameOptions = "SAMEORIGIN"
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
+			headers := w.Header()
+			if cfg.ForceSSL {
+				headers.Set("Strict-Transport-Security", hstsMaxAge)
+			}
+			headers.Set("X-Content-Type-Options", "nosniff")
+			headers.Set("Referrer-Policy", "no-referrer")
+			headers.Set("Content-Security-Policy", "frame-ancestors "+ancestors)
+			if frameOptions != "" {
+				headers.Set("X-Frame-Options", frameOptions)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
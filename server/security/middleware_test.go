@@ -0,0 +1,51 @@
+package security_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/server/security"
+	"github.com/keratin/authn-server/server/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	send := func(app *app.App) http.Header {
+		h := security.Middleware(app)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, httptest.NewRequest("GET", "/health", nil))
+		return res.Header()
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		app := test.App()
+		headers := send(app)
+		assert.Empty(t, headers.Get("Strict-Transport-Security"))
+		assert.Equal(t, "nosniff", headers.Get("X-Content-Type-Options"))
+		assert.Equal(t, "no-referrer", headers.Get("Referrer-Policy"))
+		assert.Equal(t, "frame-ancestors 'none'", headers.Get("Content-Security-Policy"))
+		assert.Equal(t, "DENY", headers.Get("X-Frame-Options"))
+	})
+
+	t.Run("with https", func(t *testing.T) {
+		app := test.App()
+		app.Config().ForceSSL = true
+		headers := send(app)
+		assert.Equal(t, "max-age=31536000", headers.Get("Strict-Transport-Security"))
+	})
+
+	t.Run("with frame ancestors", func(t *testing.T) {
+		app := test.App()
+		app.Config().FrameAncestors = []string{"'self'"}
+		headers := send(app)
+		assert.Equal(t, "frame-ancestors 'self'", headers.Get("Content-Security-Policy"))
+		assert.Equal(t, "SAMEORIGIN", headers.Get("X-Frame-Options"))
+
+		app.Config().FrameAncestors = []string{"'self'", "https://app.example.com"}
+		headers = send(app)
+		assert.Equal(t, "frame-ancestors 'self' https://app.example.com", headers.Get("Content-Security-Policy"))
+		assert.Empty(t, headers.Get("X-Frame-Options"))
+	})
+}
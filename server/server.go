@@ -1,19 +1,90 @@
 package server
 
 import (
-	"fmt"
-	"github.com/keratin/authn-server/app"
-	"log"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/services"
+	"github.com/keratin/authn-server/server/rpc"
+	"google.golang.org/grpc"
 )
 
 func Server(app *app.App) {
-	if app.Config.PublicPort != 0 {
+	listeners, err := activatedListeners()
+	if err != nil {
+		app.Logger.WithError(err).Fatal("activating listeners failed")
+	}
+
+	tlsConfig := newTLSConfig(app.Config())
+
+	if len(app.Config().AppEventsURLs) > 0 {
+		services.DeliverEvents(app.EventQueue, app.Config(), app.Reporter)
+	}
+
+	if len(listeners) > 1 || app.Config().PublicPort != 0 {
+		var public net.Listener
+		if len(listeners) > 1 {
+			public = listeners[1]
+		} else {
+			public = listen(app, app.Config().PublicPort)
+		}
+		go func() {
+			app.Logger.WithError(httpServer(app.Config(), PublicRouter(app)).Serve(secure(public, tlsConfig))).Fatal("public server stopped")
+		}()
+	}
+
+	if app.Config().GRPCPort != 0 {
+		rpcServer := rpc.NewServer(app, grpc.Creds(rpc.Credentials(app.Config())))
+		l := listen(app, app.Config().GRPCPort)
 		go func() {
-			fmt.Println(fmt.Sprintf("PUBLIC_PORT: %d", app.Config.PublicPort))
-			log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", app.Config.PublicPort), PublicRouter(app)))
+			app.Logger.WithError(rpcServer.Serve(l)).Fatal("gRPC server stopped")
 		}()
 	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", app.Config.ServerPort), Router(app)))
+	var private net.Listener
+	if len(listeners) > 0 {
+		private = listeners[0]
+	} else if app.Config().ListenSocket != "" {
+		private = listenUnix(app, app.Config().ListenSocket)
+	} else {
+		private = listen(app, app.Config().ServerPort)
+	}
+	app.Logger.WithError(httpServer(app.Config(), Router(app)).Serve(secure(private, tlsConfig))).Fatal("server stopped")
+}
+
+// httpServer serves the handler with the HTTP_* timeouts and limits
+func httpServer(cfg *app.Config, h http.Handler) *http.Server {
+	return &http.Server{
+		Handler:        h,
+		ReadTimeout:    cfg.HTTPReadTimeout,
+		WriteTimeout:   cfg.HTTPWriteTimeout,
+		IdleTimeout:    cfg.HTTPIdleTimeout,
+		MaxHeaderBytes: cfg.HTTPMaxHeaderBytes,
+	}
+}
+
+func listen(app *app.App, port int) net.Listener {
+	l, err := net.Listen("tcp", net.JoinHostPort(app.Config().ListenAddress, strconv.Itoa(port)))
+	if err != nil {
+		app.Logger.WithError(err).WithField("port", port).Fatal("listening failed")
+	}
+	return l
+}
+
+// listenUnix binds a Unix domain socket, replacing a socket that was left by a previous server. The
+// socket's permissions follow the process umask.
+func listenUnix(app *app.App, path string) net.Listener {
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			app.Logger.WithError(err).WithField("socket", path).Fatal("removing stale socket failed")
+		}
+	}
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		app.Logger.WithError(err).WithField("socket", path).Fatal("listening failed")
+	}
+	return l
 }
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPServer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	cfg := &app.Config{HTTPReadTimeout: 100 * time.Millisecond, HTTPMaxHeaderBytes: 1024}
+	go httpServer(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).Serve(l)
+
+	t.Run("slow client", func(t *testing.T) {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// the request is never finished
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n"))
+		require.NoError(t, err)
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, err = ioutil.ReadAll(conn)
+		assert.NoError(t, err, "server closes the connection")
+	})
+
+	t.Run("large headers", func(t *testing.T) {
+		req, err := http.NewRequest("GET", "http://"+l.Addr().String(), nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Padding", strings.Repeat("x", 8192))
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, res.StatusCode)
+	})
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authn.sock")
+
+	// a previous server that did not clean up
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	l := listenUnix(&app.App{Logger: logrus.New()}, path)
+	defer l.Close()
+	go http.Serve(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial("unix", path)
+		},
+	}}
+	res, err := client.Get("http://authn/health")
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+}
@@ -0,0 +1,39 @@
+package sessions
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+
+	"github.com/keratin/authn-server/app"
+)
+
+// CSRFHeader carries the CSRF token on requests, and on responses that set a session cookie.
+const CSRFHeader = "X-CSRF-Token"
+
+// CSRFToken derives the CSRF token for a session token. Since it is a signature of the session, a
+// token cannot be planted by a sibling subdomain that is able to write cookies.
+func CSRFToken(cfg *app.Config, session string) string {
+	mac := hmac.New(sha256.New, cfg.CSRFSigningKey)
+	mac.Write([]byte(session))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// setCSRFCookie pairs a readable CSRF cookie with the session cookie, when CSRF_PROTECTION is
+// double_submit. The token is also sent in the CSRFHeader, for clients on domains that can not read
+// the cookie.
+func setCSRFCookie(cfg *app.Config, w http.ResponseWriter, session *http.Cookie) {
+	if cfg.CSRFProtection != "double_submit" {
+		return
+	}
+
+	cookie := *session
+	cookie.Name = cfg.CSRFCookieName()
+	cookie.HttpOnly = false
+	if session.Value != "" {
+		cookie.Value = CSRFToken(cfg, session.Value)
+		w.Header().Set(CSRFHeader, cookie.Value)
+	}
+	http.SetCookie(w, &cookie)
+}
@@ -13,15 +13,17 @@ import (
 
 type sessionKey int
 type accountIDKey int
+type resolvedAccountIDKey int
 
 func Middleware(app *app.App) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := app.ConfigFor(r.Context())
 			var session *sessions.Claims
 			var parseOnce sync.Once
 			parse := func() *sessions.Claims {
 				parseOnce.Do(func() {
-					cookie, err := r.Cookie(app.Config.SessionCookieName)
+					cookie, err := r.Cookie(cfg.SessionCookieName)
 					if err == http.ErrNoCookie {
 						return
 					} else if err != nil {
@@ -29,7 +31,7 @@ func Middleware(app *app.App) func(http.Handler) http.Handler {
 						return
 					}
 
-					session, err = sessions.Parse(cookie.Value, app.Config)
+					session, err = sessions.Parse(cookie.Value, cfg)
 					if err != nil {
 						app.Reporter.ReportRequestError(errors.Wrap(err, "Parse"), r)
 					}
@@ -39,16 +41,18 @@ func Middleware(app *app.App) func(http.Handler) http.Handler {
 			}
 
 			var accountID int
+			var resolved bool
 			var lookupOnce sync.Once
 			lookup := func() int {
 				lookupOnce.Do(func() {
 					var err error
+					resolved = true
 					session := parse()
 					if session == nil {
 						return
 					}
 
-					accountID, err = app.RefreshTokenStore.Find(models.RefreshToken(session.Subject))
+					accountID, err = app.RefreshTokenStoreFor(r.Context()).Find(models.RefreshToken(session.Subject))
 					if err != nil {
 						app.Reporter.ReportRequestError(errors.Wrap(err, "Find"), r)
 					}
@@ -60,6 +64,12 @@ func Middleware(app *app.App) func(http.Handler) http.Handler {
 			ctx := r.Context()
 			ctx = context.WithValue(ctx, sessionKey(0), parse)
 			ctx = context.WithValue(ctx, accountIDKey(0), lookup)
+			ctx = context.WithValue(ctx, resolvedAccountIDKey(0), func() int {
+				if resolved {
+					return accountID
+				}
+				return 0
+			})
 
 			h.ServeHTTP(w, r.WithContext(ctx))
 		})
@@ -19,23 +19,25 @@ import (
 
 func TestSession(t *testing.T) {
 	testApp := &app.App{
-		Config: &app.Config{
-			SessionCookieName:  "authn-test",
-			SessionSigningKey:  []byte("drinkme"),
-			AuthNURL:           &url.URL{Scheme: "http", Host: "authn.example.com"},
-			ApplicationDomains: []route.Domain{{Hostname: "example.com"}},
-		},
 		RefreshTokenStore: mock.NewRefreshTokenStore(),
 		Reporter:          &ops.LogReporter{logrus.New()},
 	}
+	testApp.SetConfig(&app.Config{
+		SessionCookieName:  "authn-test",
+		SessionSigningKey:  []byte("drinkme"),
+		AuthNURL:           &url.URL{Scheme: "http", Host: "authn.example.com"},
+		ApplicationDomains: []route.Domain{{Hostname: "example.com"}},
+	})
 
 	t.Run("valid session", func(t *testing.T) {
 		accountID := 60090
-		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
+		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
 
 		handler := func(w http.ResponseWriter, r *http.Request) {
 			assert.NotEmpty(t, sessions.Get(r))
+			assert.Equal(t, 0, sessions.GetResolvedAccountID(r))
 			assert.Equal(t, accountID, sessions.GetAccountID(r))
+			assert.Equal(t, accountID, sessions.GetResolvedAccountID(r))
 
 			w.WriteHeader(http.StatusOK)
 		}
@@ -50,10 +52,10 @@ func TestSession(t *testing.T) {
 
 	t.Run("invalid session", func(t *testing.T) {
 		oldConfig := &app.Config{
-			SessionCookieName:  testApp.Config.SessionCookieName,
+			SessionCookieName:  testApp.Config().SessionCookieName,
 			SessionSigningKey:  []byte("previouskey"),
-			AuthNURL:           testApp.Config.AuthNURL,
-			ApplicationDomains: testApp.Config.ApplicationDomains,
+			AuthNURL:           testApp.Config().AuthNURL,
+			ApplicationDomains: testApp.Config().ApplicationDomains,
 		}
 		accountID := 52444
 		session := test.CreateSession(testApp.RefreshTokenStore, oldConfig, accountID)
@@ -75,8 +77,8 @@ func TestSession(t *testing.T) {
 
 	t.Run("revoked session", func(t *testing.T) {
 		accountID := 10001
-		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config, accountID)
-		test.RevokeSession(testApp.RefreshTokenStore, testApp.Config, session)
+		session := test.CreateSession(testApp.RefreshTokenStore, testApp.Config(), accountID)
+		test.RevokeSession(testApp.RefreshTokenStore, testApp.Config(), session)
 
 		handler := func(w http.ResponseWriter, r *http.Request) {
 			assert.NotEmpty(t, sessions.Get(r))
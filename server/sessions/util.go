@@ -1,11 +1,13 @@
 package sessions
 
 import (
+	"net"
 	"net/http"
 
 	"github.com/keratin/authn-server/app"
 	"github.com/keratin/authn-server/app/models"
 	"github.com/keratin/authn-server/app/tokens/sessions"
+	"github.com/keratin/authn-server/lib/route"
 )
 
 func Get(r *http.Request) *sessions.Claims {
@@ -24,19 +26,59 @@ func GetAccountID(r *http.Request) int {
 	return 0
 }
 
-func Set(cfg *app.Config, w http.ResponseWriter, val string) {
+// GetResolvedAccountID returns the account ID if it has already been looked up for the request.
+// Unlike GetAccountID, it will not look up the session's token.
+func GetResolvedAccountID(r *http.Request) int {
+	fn, ok := r.Context().Value(resolvedAccountIDKey(0)).(func() int)
+	if ok {
+		return fn()
+	}
+	return 0
+}
+
+// Set writes the session cookie, scoped by the cookie domain and path configured for the application
+// domain. A remembered session is kept in a persistent cookie that lasts as long as REFRESH_TOKEN_TTL.
+// The CSRF cookie, if any, is set alongside.
+func Set(cfg *app.Config, w http.ResponseWriter, val string, domain *route.Domain, remember bool) {
+	settings := cfg.SettingsFor(domain)
 	cookie := &http.Cookie{
 		Name:     cfg.SessionCookieName,
 		Value:    val,
-		Path:     cfg.MountedPath,
-		Secure:   cfg.ForceSSL,
+		Domain:   settings.CookieDomain,
+		Path:     settings.CookiePath,
+		Secure:   cfg.SecureCookies(),
 		HttpOnly: true,
 		SameSite: cfg.SameSiteComputed(),
 	}
 	if val == "" {
 		cookie.MaxAge = -1
+	} else if remember {
+		cookie.MaxAge = int(cfg.RefreshTokenTTL.Seconds())
 	}
 	http.SetCookie(w, cookie)
+	setCSRFCookie(cfg, w, cookie)
+}
+
+// RememberMe decides whether to remember a session, from the login's remember_me param if given.
+func RememberMe(cfg *app.Config, param *bool) bool {
+	if param != nil {
+		return *param
+	}
+	return cfg.RememberMeDefault
+}
+
+// Fingerprint describes the client for SESSION_BINDING, or is nil when sessions are not bound. The
+// strict binding includes the client IP, which is only meaningful when PROXIED is configured
+// correctly.
+func Fingerprint(cfg *app.Config, r *http.Request) *sessions.Fingerprint {
+	switch cfg.SessionBinding {
+	case "lax":
+		return sessions.NewFingerprint(r.UserAgent(), nil)
+	case "strict":
+		return sessions.NewFingerprint(r.UserAgent(), net.ParseIP(route.ClientIP(r)))
+	default:
+		return nil
+	}
 }
 
 func GetRefreshToken(r *http.Request) *models.RefreshToken {
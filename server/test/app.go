@@ -3,12 +3,15 @@ package test
 import (
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/keratin/authn-server/app"
+	"github.com/keratin/authn-server/app/data"
 	"github.com/keratin/authn-server/app/data/mock"
 	"github.com/keratin/authn-server/app/data/private"
 	"github.com/keratin/authn-server/lib/oauth"
 	"github.com/keratin/authn-server/lib/route"
+	"github.com/keratin/authn-server/lib/saml"
 	"github.com/keratin/authn-server/ops"
 	"github.com/sirupsen/logrus"
 )
@@ -27,26 +30,52 @@ func App() *app.App {
 	cfg := app.Config{
 		BcryptCost:              4,
 		SessionSigningKey:       []byte("TestKey"),
+		HandoffTokenSigningKey:  []byte("TestKey"),
+		CSRFSigningKey:          []byte("TestKey"),
+		IdempotencySigningKey:   []byte("TestKey"),
+		DBEncryptionKey:         []byte("DLz2TNDRdWWA5w8YNeCJ7uzcS4WDzQmB"),
 		AuthNURL:                authnURL,
+		AuthNURLs:               []*url.URL{authnURL},
 		SessionCookieName:       "authn",
 		OAuthCookieName:         "authn-oauth-nonce",
 		ApplicationDomains:      []route.Domain{{Hostname: "test.com"}},
 		PasswordMinComplexity:   2,
 		AppPasswordResetURL:     &url.URL{Scheme: "https", Host: "app.example.com"},
 		AppPasswordlessTokenURL: &url.URL{Scheme: "https", Host: "app.example.com"},
+		AppEmailVerificationURL: &url.URL{Scheme: "https", Host: "app.example.com"},
 		EnableSignup:            true,
+		EnablePasswordLogin:     true,
+		EnablePasswordReset:     true,
 		SameSite:                http.SameSiteDefaultMode,
+		JWTLeeway:               time.Minute,
+		CORSAllowedHeaders:      []string{"Content-Type"},
+		CORSAllowedMethods:      []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		CORSAllowCredentials:    true,
+		IdempotencyKeyTTL:       time.Hour,
+		DailyActivesRetention:   365,
+		WeeklyActivesRetention:  104,
 	}
 
 	logger := logrus.New()
-	return &app.App{
-		Config:            &cfg,
+	testApp := &app.App{
 		KeyStore:          mock.NewKeyStore(weakKey),
 		AccountStore:      mock.NewAccountStore(),
+		AuditLog:          mock.NewAuditLog(),
+		Maintenance:       data.NewLocalMaintenance(),
 		RefreshTokenStore: mock.NewRefreshTokenStore(),
 		Actives:           mock.NewActives(),
+		TokenDenylist:     mock.NewTokenDenylist(),
+		AccessTokenStore:  mock.NewAccessTokenStore(),
+		OTPStore:          mock.NewOTPStore(data.MaxOTPSends, data.OTPSendWindow, data.MaxOTPAttempts),
+		FailedLogins:      mock.NewFailedLogins(15 * time.Minute),
+		RateLimiter:       mock.NewRateLimiter(),
+		Idempotency:       mock.NewIdempotencyStore(),
+		EventQueue:        mock.NewEventQueue(),
 		Reporter:          &ops.LogReporter{logger},
 		OauthProviders:    map[string]oauth.Provider{},
+		SAMLProviders:     map[string]*saml.Provider{},
 		Logger:            logger,
 	}
+	testApp.SetConfig(&cfg)
+	return testApp
 }
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"crypto/tls"
+	"net"
+
+	"github.com/keratin/authn-server/app"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// newTLSConfig describes how PORT and PUBLIC_PORT serve https, from TLS_CERT or TLS_AUTOCERT. It is
+// nil when AuthN serves plain http, as behind a proxy.
+func newTLSConfig(cfg *app.Config) *tls.Config {
+	if cfg.TLSCertificate != nil {
+		return &tls.Config{
+			Certificates: []tls.Certificate{*cfg.TLSCertificate},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
+	if cfg.TLSAutocertDir != "" {
+		var hosts []string
+		for _, u := range cfg.AuthNURLs {
+			hosts = append(hosts, u.Hostname())
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			Cache:      autocert.DirCache(cfg.TLSAutocertDir),
+			HostPolicy: autocert.HostWhitelist(hosts...),
+			Email:      cfg.TLSAutocertEmail,
+		}
+		// answers the TLS-ALPN-01 challenge, so that port 80 is not needed
+		config := manager.TLSConfig()
+		config.MinVersion = tls.VersionTLS12
+		return config
+	}
+
+	return nil
+}
+
+// secure wraps a listener with TLS when it is configured
+func secure(l net.Listener, config *tls.Config) net.Listener {
+	if config == nil {
+		return l
+	}
+	return tls.NewListener(l, config)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keratin/authn-server/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	t.Run("without TLS", func(t *testing.T) {
+		assert.Nil(t, newTLSConfig(&app.Config{}))
+	})
+
+	t.Run("with TLS_CERT", func(t *testing.T) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+		require.NoError(t, err)
+		config := newTLSConfig(&app.Config{
+			TLSCertificate: &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		})
+		require.NotNil(t, config)
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer l.Close()
+		go http.Serve(secure(l, config), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		cert, err := x509.ParseCertificate(der)
+		require.NoError(t, err)
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+		res, err := client.Get("https://" + l.Addr().String())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	})
+
+	t.Run("with TLS_AUTOCERT", func(t *testing.T) {
+		config := newTLSConfig(&app.Config{
+			AuthNURLs: []*url.URL{
+				{Scheme: "https", Host: "authn.example.com"},
+				{Scheme: "https", Host: "authn.example.net"},
+			},
+			TLSAutocertDir: t.TempDir(),
+		})
+		require.NotNil(t, config)
+		assert.NotNil(t, config.GetCertificate)
+		assert.Contains(t, config.NextProtos, acme.ALPNProto)
+
+		_, err := config.GetCertificate(&tls.ClientHelloInfo{ServerName: "evil.example.com"})
+		assert.Error(t, err)
+	})
+}
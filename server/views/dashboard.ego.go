@@ -0,0 +1,22 @@
+// Generated by ego.
+// DO NOT EDIT
+
+//line server/views/dashboard.ego:1
+
+package views
+
+import "fmt"
+import "html"
+import "io"
+import "context"
+
+func Dashboard(w io.Writer) {
+//line server/views/dashboard.ego:6
+	_, _ = io.WriteString(w, "\n<!DOCTYPE html>\n<html>\n  <head>\n    <meta charset=\"utf-8\">\n    <title>AuthN Dashboard</title>\n    <style>\n      body { font-family: sans-serif; margin: 2em; color: #222; }\n      section { margin-bottom: 2em; }\n      table { border-collapse: collapse; }\n      th, td { border-bottom: 1px solid #ddd; padding: 0.3em 0.8em; text-align: left; }\n      .charts { display: flex; flex-wrap: wrap; }\n      .chart { margin-right: 2em; }\n      .chart rect { fill: #4a7bb7; }\n      .error { color: #b00; }\n      .muted { color: #888; }\n    </style>\n  </head>\n  <body>\n    <h1>AuthN Dashboard</h1>\n    <p id=\"error\" class=\"error\"></p>\n\n    <section>\n      <h2>Active Accounts</h2>\n      <div id=\"stats\" class=\"charts\"></div>\n    </section>\n\n    <section>\n      <h2>Accounts</h2>\n      <form id=\"search\">\n        <input id=\"username\" type=\"search\" placeholder=\"Username\">\n        <button type=\"submit\">Search</button>\n      </form>\n      <table>\n        <thead><tr><th>ID</th><th>Username</th><th>Locked</th><th>Created</th><th>Last login</th><th></th></tr></thead>\n        <tbody id=\"accounts\"></tbody>\n      </table>\n      <p>\n        <button id=\"prev\">Previous</button>\n        <span id=\"pagination\" class=\"muted\"></span>\n        <button id=\"next\">Next</button>\n      </p>\n    </section>\n\n    <section id=\"account\" hidden>\n      <h2 id=\"account-title\"></h2>\n      <p id=\"account-status\"></p>\n      <button id=\"lock\"></button>\n      <h3>Sessions</h3>\n      <table>\n        <thead><tr><th>User agent</th><th>Created</th><th>Last seen</th></tr></thead>\n        <tbody id=\"sessions\"></tbody>\n      </table>\n    </section>\n\n    <script>\n      var perPage = 25;\n      var state = { username: \"\", page: 1, account: null };\n\n      function $(id) { return document.getElementById(id); }\n\n      function showError(err) { $(\"error\").textContent = err ? String(err) : \"\"; }\n\n      function row(cells) {\n        var tr = document.createElement(\"tr\");\n        cells.forEach(function (cell) {\n          var td = document.createElement(\"td\");\n          if (cell instanceof Node) {\n            td.appendChild(cell);\n          } else {\n            td.textContent = cell === null || cell === undefined ? \"\" : String(cell);\n          }\n          tr.appendChild(td);\n        });\n        return tr;\n      }\n\n      function replaceRows(tbody, rows) {\n        while (tbody.firstChild) tbody.removeChild(tbody.firstChild);\n        rows.forEach(function (tr) { tbody.appendChild(tr); });\n      }\n\n      function graphql(query, variables) {\n        return fetch(\"graphql\", {\n          method: \"POST\",\n          headers: { \"Content-Type\": \"application/json\" },\n          body: JSON.stringify({ query: query, variables: variables || {} })\n        }).then(function (res) {\n          return res.json();\n        }).then(function (res) {\n          if (res.errors && res.errors.length) throw new Error(res.errors[0].message);\n          return res.data;\n        });\n      }\n\n      function chart(title, actives) {\n        var width = 320, height = 120;\n        var svgNS = \"http://www.w3.org/2000/svg\";\n        var div = document.createElement(\"div\");\n        div.className = \"chart\";\n        var h3 = document.createElement(\"h3\");\n        h3.textContent = title;\n        div.appendChild(h3);\n\n        actives.sort(function (a, b) { return a.period < b.period ? -1 : 1; });\n        var max = Math.max.apply(null, actives.map(function (a) { return a.count; }).concat([1]));\n        var svg = document.createElementNS(svgNS, \"svg\");\n        svg.setAttribute(\"width\", width);\n        svg.setAttribute(\"height\", height);\n        var barWidth = actives.length ? width / actives.length : width;\n        actives.forEach(function (a, i) {\n          var barHeight = Math.round(height * a.count / max);\n          var rect = document.createElementNS(svgNS, \"rect\");\n          rect.setAttribute(\"x\", i * barWidth + 1);\n          rect.setAttribute(\"y\", height - barHeight);\n          rect.setAttribute(\"width\", Math.max(barWidth - 2, 1));\n          rect.setAttribute(\"height\", barHeight);\n          var tooltip = document.createElementNS(svgNS, \"title\");\n          tooltip.textContent = a.period + \": \" + a.count;\n          rect.appendChild(tooltip);\n          svg.appendChild(rect);\n        });\n        div.appendChild(svg);\n        return div;\n      }\n\n      function loadStats() {\n        return graphql(\"{ stats { daily { period count } weekly { period count } monthly { period count } } }\").then(function (data) {\n          var stats = $(\"stats\");\n          if (!data.stats) {\n            stats.textContent = \"Stats require REDIS_URL.\";\n            stats.className = \"muted\";\n            return;\n          }\n          stats.appendChild(chart(\"Daily\", data.stats.daily));\n          stats.appendChild(chart(\"Weekly\", data.stats.weekly));\n          stats.appendChild(chart(\"Monthly\", data.stats.monthly));\n        });\n      }\n\n      function loadAccounts() {\n        var query = \"query($page: Int, $perPage: Int, $username: String) {\" +\n          \" accounts(page: $page, perPage: $perPage, username: $username) {\" +\n          \" total accounts { id username locked createdAt lastLoginAt } } }\";\n        return graphql(query, { page: state.page, perPage: perPage, username: state.username }).then(function (data) {\n          var page = data.accounts;\n          replaceRows($(\"accounts\"), page.accounts.map(function (account) {\n            var view = document.createElement(\"button\");\n            view.textContent = \"View\";\n            view.onclick = function () { loadAccount(account.id).catch(showError); };\n            return row([account.id, account.username, account.locked ? \"yes\" : \"no\", account.createdAt, account.lastLoginAt, view]);\n          }));\n          var pages = Math.max(Math.ceil(page.total / perPage), 1);\n          $(\"pagination\").textContent = \"Page \" + state.page + \" of \" + pages + \" (\" + page.total + \" accounts)\";\n          $(\"prev\").disabled = state.page <= 1;\n          $(\"next\").disabled = state.page >= pages;\n        });\n      }\n\n      function loadAccount(id) {\n        var query = \"query($id: Int!) { account(id: $id) {\" +\n          \" id username locked deleted sessions { userAgent createdAt lastSeenAt } } }\";\n        return graphql(query, { id: id }).then(function (data) {\n          var account = data.account;\n          if (!account) throw new Error(\"Account \" + id + \" was not found.\");\n          state.account = account;\n          $(\"account\").hidden = false;\n          $(\"account-title\").textContent = account.username + \" (#\" + account.id + \")\";\n          $(\"account-status\").textContent = account.deleted ? \"Archived\" : (account.locked ? \"Locked\" : \"Active\");\n          $(\"lock\").textContent = account.locked ? \"Unlock\" : \"Lock\";\n          $(\"lock\").hidden = account.deleted;\n          replaceRows($(\"sessions\"), account.sessions.map(function (session) {\n            return row([session.userAgent, session.createdAt, session.lastSeenAt]);\n          }));\n        });\n      }\n\n      $(\"lock\").onclick = function () {\n        var account = state.account;\n        var action = account.locked ? \"unlock\" : \"lock\";\n        fetch(\"accounts/\" + account.id + \"/\" + action, { method: \"PATCH\" }).then(function (res) {\n          if (!res.ok) throw new Error(\"Could not \" + action + \" \" + account.username + \" (\" + res.status + \").\");\n          return Promise.all([loadAccount(account.id), loadAccounts()]);\n        }).then(function () { showError(); }).catch(showError);\n      };\n\n      $(\"search\").onsubmit = function (e) {\n        e.preventDefault();\n        state.username = $(\"username\").value;\n        state.page = 1;\n        loadAccounts().then(function () { showError(); }).catch(showError);\n      };\n      $(\"prev\").onclick = function () { state.page--; loadAccounts().catch(showError); };\n      $(\"next\").onclick = function () { state.page++; loadAccounts().catch(showError); };\n\n      loadStats().catch(showError);\n      loadAccounts().catch(showError);\n    </script>\n  </body>\n</html>\n\n")
+//line server/views/dashboard.ego:206
+}
+
+var _ fmt.Stringer
+var _ io.Reader
+var _ context.Context
+var _ = html.EscapeString
@@ -18,8 +18,8 @@ func Root(w io.Writer) {
 //line server/views/root.ego:13
 	_, _ = io.WriteString(w, html.EscapeString(fmt.Sprint(logo)))
 //line server/views/root.ego:13
-	_, _ = io.WriteString(w, "\" /></div>\n      <div style=\"flex: 1 0 auto;\">\n        <ul>\n          <li><a href=\"health\">Health</a></li>\n          <li><a href=\"stats\">Active Users</a></li>\n          <li><a href=\"metrics\">Server Metrics</a></li>\n          <li><a href=\"jwks\">Keys</a></li>\n          <li><a href=\"https://github.com/keratin/authn-server\">GitHub</a></li>\n          <li><a href=\"https://github.com/keratin/authn-server/blob/master/docs/README.md\">Docs</a></li>\n        </ul>\n      </div>\n    </div>\n  </body>\n</html>\n\n")
-//line server/views/root.ego:28
+	_, _ = io.WriteString(w, "\" /></div>\n      <div style=\"flex: 1 0 auto;\">\n        <ul>\n          <li><a href=\"health\">Health</a></li>\n          <li><a href=\"stats\">Active Users</a></li>\n          <li><a href=\"admin\">Dashboard</a></li>\n          <li><a href=\"metrics\">Server Metrics</a></li>\n          <li><a href=\"jwks\">Keys</a></li>\n          <li><a href=\"https://github.com/keratin/authn-server\">GitHub</a></li>\n          <li><a href=\"https://github.com/keratin/authn-server/blob/master/docs/README.md\">Docs</a></li>\n        </ul>\n      </div>\n    </div>\n  </body>\n</html>\n\n")
+//line server/views/root.ego:29
 }
 
 var _ fmt.Stringer